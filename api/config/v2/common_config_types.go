@@ -7,6 +7,19 @@ type UsernamePasswordAuthentication struct {
 
 	// +kubebuilder:validation:MinLength=0
 	UserName string `json:"userName"`
+
+	// Namespace of the Secret, defaults to the namespace of the instance. Required by ClusterElasticsearchInstances
+	// and ClusterKibanaInstances, ElasticsearchInstances and KibanaInstances may only use their own namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// SecretNamespace returns the namespace of the Secret, defaultNamespace unless set
+func (a *UsernamePasswordAuthentication) SecretNamespace(defaultNamespace string) string {
+	if a.Namespace != "" {
+		return a.Namespace
+	}
+	return defaultNamespace
 }
 
 // PublicCertificate Configuration for public certificate used for communication with target
@@ -18,6 +31,19 @@ type PublicCertificate struct {
 	// +reqired
 	// +kubebuilder:validation:MinLength=0
 	CertificateKey string `json:"certificateKey"`
+
+	// Namespace of the Secret, defaults to the namespace of the instance. Required by ClusterElasticsearchInstances
+	// and ClusterKibanaInstances, ElasticsearchInstances and KibanaInstances may only use their own namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// SecretNamespace returns the namespace of the Secret, defaultNamespace unless set
+func (c *PublicCertificate) SecretNamespace(defaultNamespace string) string {
+	if c.Namespace != "" {
+		return c.Namespace
+	}
+	return defaultNamespace
 }
 
 // APIKey Definition of APIKey authentication
@@ -25,3 +51,24 @@ type APIKeyAuthentication struct {
 	// +kubebuilder:validation:MinLength=0
 	APIKey string `json:"apiKey"`
 }
+
+// ProxyConfig Definition of the proxy requests to the target instance are sent through. Proxy urls may use the
+// http, https, socks5 and socks5h schemes.
+type ProxyConfig struct {
+	// HttpProxy is the proxy for http:// target urls
+	// +optional
+	HttpProxy string `json:"httpProxy,omitempty"`
+	// HttpsProxy is the proxy for https:// target urls
+	// +optional
+	HttpsProxy string `json:"httpsProxy,omitempty"`
+	// NoProxy lists hosts, domains (".example.com"), IP addresses and CIDR ranges reached without the proxy
+	// +optional
+	NoProxy []string `json:"noProxy,omitempty"`
+}
+
+// InstanceOverride overrides settings of an ElasticsearchInstance or KibanaInstance in the operator configuration
+type InstanceOverride struct {
+	// Enabled set to false stops reconciling the resources targeting the instance, e.g. during a change freeze
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+}
@@ -29,6 +29,29 @@ type ElasticsearchSpec struct {
 
 	// +optional
 	Authentication *ElasticsearchAuthentication `json:"authentication,omitempty"`
+	// Proxy the requests to the instance are sent through. Without it, the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	// environment variables of the operator are used.
+	// +optional
+	Proxy *ProxyConfig `json:"proxy,omitempty"`
+	// StartupHealth is the cluster health the instance has to report before resources targeting it
+	// are reconciled after the operator started. Defaults to yellow.
+	// +optional
+	// +kubebuilder:validation:Enum=green;yellow;red
+	StartupHealth string `json:"startupHealth,omitempty"`
+	// EckRef references the ECK Elasticsearch resource running the instance. Until its status reports startupHealth,
+	// e.g. while a new cluster bootstraps, resources targeting the instance are held back with the Pending condition
+	// instead of querying the instance.
+	// +optional
+	EckRef *EckElasticsearchReference `json:"eckRef,omitempty"`
+}
+
+// EckElasticsearchReference references an Elasticsearch resource of ECK, elasticsearch.k8s.elastic.co/v1
+type EckElasticsearchReference struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Namespace of the Elasticsearch resource, defaults to the namespace of the resource targeting the instance
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // ElasticsearchAuthentication Definition of Elasticsearch authentication
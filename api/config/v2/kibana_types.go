@@ -29,6 +29,10 @@ type KibanaSpec struct {
 
 	// +optional
 	Authentication *KibanaAuthentication `json:"authentication,omitempty"`
+	// Proxy the requests to the instance are sent through. Without it, the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	// environment variables of the operator are used.
+	// +optional
+	Proxy *ProxyConfig `json:"proxy,omitempty"`
 }
 
 // KibanaAuthentication Definition of Kibana authentication
@@ -0,0 +1,65 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+// LintOperator compares the values found at the path of a LintRule
+// +kubebuilder:validation:Enum=Exists;NotExists;Equals;NotEquals;GreaterOrEqual;LessOrEqual;Matches
+type LintOperator string
+
+const (
+	// LintOperatorExists requires the path to be set
+	LintOperatorExists LintOperator = "Exists"
+	// LintOperatorNotExists requires the path not to be set
+	LintOperatorNotExists LintOperator = "NotExists"
+	// LintOperatorEquals requires the values to equal the value of the rule
+	LintOperatorEquals LintOperator = "Equals"
+	// LintOperatorNotEquals requires the values to differ from the value of the rule
+	LintOperatorNotEquals LintOperator = "NotEquals"
+	// LintOperatorGreaterOrEqual requires the values to be numbers or time values of at least the value of the rule
+	LintOperatorGreaterOrEqual LintOperator = "GreaterOrEqual"
+	// LintOperatorLessOrEqual requires the values to be numbers or time values of at most the value of the rule
+	LintOperatorLessOrEqual LintOperator = "LessOrEqual"
+	// LintOperatorMatches requires the values to match the regular expression given as value of the rule
+	LintOperatorMatches LintOperator = "Matches"
+)
+
+// LintRule is an assertion on the rendered bodies of resources, checked before they are sent to Elasticsearch or
+// Kibana. Resources violating a rule are not reconciled and report a PolicyViolation condition.
+type LintRule struct {
+	// Name of the rule, reported with its violations
+	Name string `json:"name"`
+	// Message explains the rule to the owners of violating resources
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Kinds the rule applies to, e.g. Index or IndexLifecyclePolicy. Defaults to all kinds.
+	// +optional
+	Kinds []string `json:"kinds,omitempty"`
+	// Namespaces the rule applies to, e.g. the production namespaces. Defaults to all namespaces.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Path of the values in the body, a JSONPath like $.settings.index.number_of_replicas. Keys containing dots are
+	// quoted, like $.settings['index.number_of_replicas'], and * selects all keys or items.
+	Path string `json:"path"`
+	// Operator the values found at the path are checked with
+	Operator LintOperator `json:"operator"`
+	// Value the values are compared to, unused by Exists and NotExists
+	// +optional
+	Value string `json:"value,omitempty"`
+	// Optional skips the rule for bodies the path is not set in, instead of reporting a violation
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+}
@@ -0,0 +1,36 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+// NamingPolicy Definition of the naming convention for the objects namespaced resources create in Elasticsearch
+type NamingPolicy struct {
+	// Pattern of the name of the object in Elasticsearch, {namespace} and {name} are replaced by the namespace and
+	// the name of the resource, e.g. "{namespace}-{name}". Without a pattern the name of the resource is used.
+	// +optional
+	Pattern string `json:"pattern,omitempty"`
+	// Kinds the pattern is applied to, out of IngestPipeline, IndexTemplate, ComponentTemplate, Index and
+	// ElasticsearchRole. Defaults to all of them.
+	// +optional
+	Kinds []string `json:"kinds,omitempty"`
+	// ExcludedNamespaces keep the name of the resource as name of the object, e.g. for the namespace of the
+	// platform team
+	// +optional
+	ExcludedNamespaces []string `json:"excludedNamespaces,omitempty"`
+	// Migrate renames the objects created under the name of the resource before the pattern applied
+	// +optional
+	Migrate bool `json:"migrate,omitempty"`
+}
@@ -34,6 +34,23 @@ type ProjectConfigSpec struct {
 	// +optional
 	Elasticsearch ElasticsearchSpec `json:"elasticsearch,omitempty"`
 	Kibana        KibanaSpec        `json:"kibana,omitempty"`
+	// Naming enforces a naming convention for the objects created in Elasticsearch
+	// +optional
+	Naming *NamingPolicy `json:"naming,omitempty"`
+	// Lint rules are checked against the rendered bodies of all resources before they are sent to Elasticsearch or
+	// Kibana
+	// +optional
+	Lint []LintRule `json:"lint,omitempty"`
+	// ElasticsearchInstances overrides settings of ElasticsearchInstances, keyed by namespace/name, or by name for the
+	// instances of the name in all namespaces
+	// +optional
+	ElasticsearchInstances map[string]InstanceOverride `json:"elasticsearchInstances,omitempty"`
+	// KibanaInstances overrides settings of KibanaInstances, keyed like ElasticsearchInstances
+	// +optional
+	KibanaInstances map[string]InstanceOverride `json:"kibanaInstances,omitempty"`
+	// RawRequests allow-lists the API paths of ElasticsearchRawRequests and KibanaRawRequests
+	// +optional
+	RawRequests *RawRequestPolicy `json:"rawRequests,omitempty"`
 }
 
 // ProjectConfigStatus defines the observed state of ProjectConfig.
@@ -0,0 +1,29 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+// RawRequestPolicy Definition of the API paths ElasticsearchRawRequests and KibanaRawRequests may send requests to
+type RawRequestPolicy struct {
+	// Elasticsearch lists the path prefixes ElasticsearchRawRequests may send requests to, e.g.
+	// "/_security/role_mapping/". Without prefixes no ElasticsearchRawRequest is reconciled.
+	// +optional
+	Elasticsearch []string `json:"elasticsearch,omitempty"`
+	// Kibana lists the path prefixes KibanaRawRequests may send requests to, e.g. "/api/streams/". Without
+	// prefixes no KibanaRawRequest is reconciled.
+	// +optional
+	Kibana []string `json:"kibana,omitempty"`
+}
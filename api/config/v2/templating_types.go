@@ -26,6 +26,11 @@ type CommonTemplatingSpec struct {
 	// References to ResourceTemplateData objects
 	// +optional
 	References []CommonTemplatingSpecReference `json:"references,omitempty"`
+
+	// Builtins renders the body even without references, so the built-in variables .Namespace, .Name,
+	// .TargetInstance and .Target can be used on their own
+	// +optional
+	Builtins bool `json:"builtins,omitempty"`
 }
 
 // CommonTemplatingSpecReference defines a reference to a ResourceTemplateData object
@@ -88,6 +88,21 @@ func (in *CommonTemplatingSpecReference) DeepCopy() *CommonTemplatingSpecReferen
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EckElasticsearchReference) DeepCopyInto(out *EckElasticsearchReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EckElasticsearchReference.
+func (in *EckElasticsearchReference) DeepCopy() *EckElasticsearchReference {
+	if in == nil {
+		return nil
+	}
+	out := new(EckElasticsearchReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElasticsearchAuthentication) DeepCopyInto(out *ElasticsearchAuthentication) {
 	*out = *in
@@ -126,6 +141,16 @@ func (in *ElasticsearchSpec) DeepCopyInto(out *ElasticsearchSpec) {
 		*out = new(ElasticsearchAuthentication)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EckRef != nil {
+		in, out := &in.EckRef, &out.EckRef
+		*out = new(EckElasticsearchReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchSpec.
@@ -138,6 +163,26 @@ func (in *ElasticsearchSpec) DeepCopy() *ElasticsearchSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceOverride) DeepCopyInto(out *InstanceOverride) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceOverride.
+func (in *InstanceOverride) DeepCopy() *InstanceOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KibanaAuthentication) DeepCopyInto(out *KibanaAuthentication) {
 	*out = *in
@@ -176,6 +221,11 @@ func (in *KibanaSpec) DeepCopyInto(out *KibanaSpec) {
 		*out = new(KibanaAuthentication)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxyConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaSpec.
@@ -188,6 +238,56 @@ func (in *KibanaSpec) DeepCopy() *KibanaSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LintRule) DeepCopyInto(out *LintRule) {
+	*out = *in
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LintRule.
+func (in *LintRule) DeepCopy() *LintRule {
+	if in == nil {
+		return nil
+	}
+	out := new(LintRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamingPolicy) DeepCopyInto(out *NamingPolicy) {
+	*out = *in
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedNamespaces != nil {
+		in, out := &in.ExcludedNamespaces, &out.ExcludedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamingPolicy.
+func (in *NamingPolicy) DeepCopy() *NamingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NamingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProjectConfig) DeepCopyInto(out *ProjectConfig) {
 	*out = *in
@@ -252,6 +352,37 @@ func (in *ProjectConfigSpec) DeepCopyInto(out *ProjectConfigSpec) {
 	*out = *in
 	in.Elasticsearch.DeepCopyInto(&out.Elasticsearch)
 	in.Kibana.DeepCopyInto(&out.Kibana)
+	if in.Naming != nil {
+		in, out := &in.Naming, &out.Naming
+		*out = new(NamingPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Lint != nil {
+		in, out := &in.Lint, &out.Lint
+		*out = make([]LintRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ElasticsearchInstances != nil {
+		in, out := &in.ElasticsearchInstances, &out.ElasticsearchInstances
+		*out = make(map[string]InstanceOverride, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.KibanaInstances != nil {
+		in, out := &in.KibanaInstances, &out.KibanaInstances
+		*out = make(map[string]InstanceOverride, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.RawRequests != nil {
+		in, out := &in.RawRequests, &out.RawRequests
+		*out = new(RawRequestPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectConfigSpec.
@@ -279,6 +410,26 @@ func (in *ProjectConfigStatus) DeepCopy() *ProjectConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyConfig) DeepCopyInto(out *ProxyConfig) {
+	*out = *in
+	if in.NoProxy != nil {
+		in, out := &in.NoProxy, &out.NoProxy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyConfig.
+func (in *ProxyConfig) DeepCopy() *ProxyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PublicCertificate) DeepCopyInto(out *PublicCertificate) {
 	*out = *in
@@ -294,6 +445,31 @@ func (in *PublicCertificate) DeepCopy() *PublicCertificate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RawRequestPolicy) DeepCopyInto(out *RawRequestPolicy) {
+	*out = *in
+	if in.Elasticsearch != nil {
+		in, out := &in.Elasticsearch, &out.Elasticsearch
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Kibana != nil {
+		in, out := &in.Kibana, &out.Kibana
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RawRequestPolicy.
+func (in *RawRequestPolicy) DeepCopy() *RawRequestPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RawRequestPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UsernamePasswordAuthentication) DeepCopyInto(out *UsernamePasswordAuthentication) {
 	*out = *in
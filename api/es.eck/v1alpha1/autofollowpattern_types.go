@@ -0,0 +1,109 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AutoFollowPatternSpec defines the desired state of AutoFollowPattern
+type AutoFollowPatternSpec struct {
+	// +optional
+	TargetConfig CommonElasticsearchConfig `json:"targetInstance,omitempty"`
+
+	// RemoteCluster is the alias of the remote cluster whose new indices are followed, as configured in the cluster
+	// settings of the target instance
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	RemoteCluster string `json:"remoteCluster"`
+
+	// LeaderIndexPatterns select the indices of the remote cluster which are followed once created
+	// +kubebuilder:validation:MinItems=1
+	// +required
+	LeaderIndexPatterns []string `json:"leaderIndexPatterns"`
+
+	// LeaderIndexExclusionPatterns exclude indices matching LeaderIndexPatterns from being followed
+	// +optional
+	LeaderIndexExclusionPatterns []string `json:"leaderIndexExclusionPatterns,omitempty"`
+
+	// FollowIndexPattern names the follower indices, {{leader_index}} is replaced by the name of the leader index.
+	// Defaults to the name of the leader index.
+	// +optional
+	FollowIndexPattern string `json:"followIndexPattern,omitempty"`
+
+	// Paused pauses the auto-follow pattern, new indices are not followed until it is unset. Follower indices
+	// already created keep replicating.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Settings override the settings of the follower indices taken over from the leader indices,
+	// e.g. index.number_of_replicas
+	// +optional
+	Settings map[string]string `json:"settings,omitempty"`
+
+	// Parameters tune the replication of the follower indices, e.g. max_read_request_operation_count
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// AutoFollowPatternStatus defines the observed state of AutoFollowPattern
+type AutoFollowPatternStatus struct {
+	// ReplicationStatus of the auto-follow pattern, active or paused
+	// +optional
+	ReplicationStatus string `json:"replicationStatus,omitempty"`
+	// RemoteClusterConnected reports whether the remote cluster was connected on the last reconcile
+	// +optional
+	RemoteClusterConnected bool `json:"remoteClusterConnected,omitempty"`
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// Failover records the instance of each failover pair holding the object
+	// +listType=map
+	// +listMapKey=primary
+	// +optional
+	Failover []FailoverStatus `json:"failover,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// AutoFollowPattern is the Schema for the autofollowpatterns API
+type AutoFollowPattern struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AutoFollowPatternSpec   `json:"spec,omitempty"`
+	Status AutoFollowPatternStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// AutoFollowPatternList contains a list of AutoFollowPattern
+type AutoFollowPatternList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AutoFollowPattern `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AutoFollowPattern{}, &AutoFollowPatternList{})
+}
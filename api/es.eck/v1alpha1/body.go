@@ -0,0 +1,149 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// specBody returns the body given as string, or as structured JSON if the string is not set
+func specBody(body string, bodyJSON *apiextensionsv1.JSON) string {
+	if body != "" || bodyJSON == nil {
+		return body
+	}
+	return string(bodyJSON.Raw)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *ElasticsearchApikeySpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *ElasticsearchRoleSpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *IngestPipelineSpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *IndexLifecyclePolicySpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *SnapshotLifecyclePolicySpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *ComponentTemplateSpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *IndexSpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *ElasticsearchUserSpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *IndexTemplateSpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *SnapshotRepositorySpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *ElasticsearchApikey) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *ElasticsearchRole) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *IngestPipeline) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *IndexLifecyclePolicy) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *SnapshotLifecyclePolicy) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *ComponentTemplate) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *Index) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *ElasticsearchUser) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *IndexTemplate) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *SnapshotRepository) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *QueryRulesetSpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *QueryRuleset) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *SynonymSetSpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *SynonymSet) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestGetBody(t *testing.T) {
+	tests := []struct {
+		name string
+		spec IngestPipelineSpec
+		want string
+	}{
+		{name: "body", spec: IngestPipelineSpec{Body: `{"processors":[]}`}, want: `{"processors":[]}`},
+		{name: "bodyJson", spec: IngestPipelineSpec{BodyJSON: &apiextensionsv1.JSON{Raw: []byte(`{"processors":[]}`)}}, want: `{"processors":[]}`},
+		{name: "body takes precedence", spec: IngestPipelineSpec{Body: `{}`, BodyJSON: &apiextensionsv1.JSON{Raw: []byte(`{"processors":[]}`)}}, want: `{}`},
+		{name: "none", spec: IngestPipelineSpec{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.spec.GetBody(); got != tt.want {
+				t.Errorf("GetBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndex_DeepCopyBodyJSON(t *testing.T) {
+	index := &Index{Spec: IndexSpec{BodyJSON: &apiextensionsv1.JSON{Raw: []byte(`{"settings":{}}`)}}}
+	copied := index.DeepCopy()
+	copied.Spec.BodyJSON.Raw[2] = 'X'
+
+	if index.Spec.GetBody() != `{"settings":{}}` {
+		t.Errorf("Expected the copy not to share bodyJson, got %s", index.Spec.GetBody())
+	}
+	if body, bodyJSON := copied.BodyFields(); body != "" || bodyJSON == nil {
+		t.Errorf("Expected BodyFields() to return the bodyJson of the copy, got %q, %v", body, bodyJSON)
+	}
+}
+
+func TestSearchResources_DeepCopyBodyJSON(t *testing.T) {
+	queryRuleset := &QueryRuleset{Spec: QueryRulesetSpec{BodyJSON: &apiextensionsv1.JSON{Raw: []byte(`{"rules":[]}`)}}}
+	copiedRuleset := queryRuleset.DeepCopy()
+	copiedRuleset.Spec.BodyJSON.Raw[2] = 'X'
+	if queryRuleset.Spec.GetBody() != `{"rules":[]}` {
+		t.Errorf("Expected the copy not to share bodyJson, got %s", queryRuleset.Spec.GetBody())
+	}
+
+	synonymSet := &SynonymSet{Spec: SynonymSetSpec{BodyJSON: &apiextensionsv1.JSON{Raw: []byte(`{"synonyms_set":[]}`)}}}
+	copiedSet := synonymSet.DeepCopy()
+	copiedSet.Spec.BodyJSON.Raw[2] = 'X'
+	if synonymSet.Spec.GetBody() != `{"synonyms_set":[]}` {
+		t.Errorf("Expected the copy not to share bodyJson, got %s", synonymSet.Spec.GetBody())
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	configv2 "eck-custom-resources/api/config/v2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterElasticsearchInstanceKind is the kind targetInstance.kind selects ClusterElasticsearchInstances with
+const ClusterElasticsearchInstanceKind = "ClusterElasticsearchInstance"
+
+// ClusterElasticsearchInstanceStatus defines the observed state of ClusterElasticsearchInstance
+type ClusterElasticsearchInstanceStatus struct {
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+
+// ClusterElasticsearchInstance is the Schema for the clusterelasticsearchinstances API. It is an ElasticsearchInstance
+// shared by all namespaces, the namespaces of its Secrets have to be set.
+type ClusterElasticsearchInstance struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   configv2.ElasticsearchSpec         `json:"spec,omitempty"`
+	Status ClusterElasticsearchInstanceStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterElasticsearchInstanceList contains a list of ClusterElasticsearchInstance
+type ClusterElasticsearchInstanceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterElasticsearchInstance `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterElasticsearchInstance{}, &ClusterElasticsearchInstanceList{})
+}
@@ -1,14 +1,97 @@
 package v1alpha1
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 type CommonElasticsearchConfig struct {
 	// +optional
 	ElasticsearchInstance string `json:"name,omitempty"`
 	// +optional
 	ElasticsearchInstanceNamespace string `json:"namespace,omitempty"`
+	// Kind of the instance, ElasticsearchInstance or ClusterElasticsearchInstance. Defaults to ElasticsearchInstance.
+	// The namespace is ignored for ClusterElasticsearchInstances.
+	// +kubebuilder:validation:Enum=ElasticsearchInstance;ClusterElasticsearchInstance
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// Selector selects the ElasticsearchInstance by its labels instead of by name, exactly one ElasticsearchInstance in the namespace
+	// has to match. Ignored if name is set.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Failover names a secondary instance the resource is applied to while the instance above, the primary, is
+	// unreachable for longer than failover.after, for active/passive disaster recovery setups
+	// +optional
+	Failover *ElasticsearchFailover `json:"failover,omitempty"`
+}
+
+// ElasticsearchFailover configures the secondary instance of a failover pair
+type ElasticsearchFailover struct {
+	// Secondary is the instance the resource is applied to while the primary is unreachable
+	// +required
+	Secondary ElasticsearchFailoverInstance `json:"secondary"`
+	// After is how long the primary has to be unreachable before the resource is applied to the secondary. Defaults
+	// to 5m.
+	// +kubebuilder:default="5m"
+	// +optional
+	After metav1.Duration `json:"after,omitempty"`
+}
+
+// ElasticsearchFailoverInstance references the ElasticsearchInstance or ClusterElasticsearchInstance of a failover pair
+type ElasticsearchFailoverInstance struct {
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Name string `json:"name"`
+	// Namespace of the ElasticsearchInstance, defaults to the namespace of the resource
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Kind of the instance, ElasticsearchInstance or ClusterElasticsearchInstance. Defaults to ElasticsearchInstance.
+	// +kubebuilder:validation:Enum=ElasticsearchInstance;ClusterElasticsearchInstance
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// FailoverStatus records which instance of a failover pair holds the object of a resource
+type FailoverStatus struct {
+	// Primary is the URL of the primary instance
+	// +required
+	Primary string `json:"primary"`
+	// Active is the URL of the instance the object is applied to, the primary or the secondary
+	// +required
+	Active string `json:"active"`
+	// Secondary is the secondary instance while it holds the object, so that the object can be deleted from it once
+	// the failover pair is removed from the resource
+	// +optional
+	Secondary *ElasticsearchFailoverInstance `json:"secondary,omitempty"`
+}
+
+// FailoverStatusHolder is implemented by the resources recording the instance of their failover pairs holding their
+// object in status.failover
+type FailoverStatusHolder interface {
+	FailoverStatus() *[]FailoverStatus
+}
+
+// TargetConfig returns the secondary as the target of a resource
+func (i ElasticsearchFailoverInstance) TargetConfig() CommonElasticsearchConfig {
+	return CommonElasticsearchConfig{ElasticsearchInstance: i.Name, ElasticsearchInstanceNamespace: i.Namespace, Kind: i.Kind}
+}
+
+// IsClusterInstance reports whether the target is a ClusterElasticsearchInstance
+func (c CommonElasticsearchConfig) IsClusterInstance() bool {
+	return c.Kind == ClusterElasticsearchInstanceKind
+}
+
+// IndexLifecyclePolicyReference references an IndexLifecyclePolicy resource
+type IndexLifecyclePolicyReference struct {
+	// Name of the IndexLifecyclePolicy, which is also the name of the policy in Elasticsearch
+	// +required
+	Name string `json:"name"`
+	// Namespace of the IndexLifecyclePolicy, defaults to the namespace of the referencing resource
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // UpdateMode defines how updates to the resource should be handled
-// +kubebuilder:validation:Enum=Overwrite;Block
+// +kubebuilder:validation:Enum=Overwrite;Block;BlueGreen
 type UpdateMode string
 
 const (
@@ -16,6 +99,9 @@ const (
 	UpdateModeOverwrite UpdateMode = "Overwrite"
 	// UpdateModeBlock blocks updates to the resource after initial creation
 	UpdateModeBlock UpdateMode = "Block"
+	// UpdateModeBlueGreen stages the new version of an IngestPipeline as <name>-next, tests it and switches the
+	// indices using the pipeline over to it while <name> is updated
+	UpdateModeBlueGreen UpdateMode = "BlueGreen"
 )
 
 // UpdatePolicySpec defines the policy for handling updates to the resource
@@ -24,4 +110,55 @@ type UpdatePolicySpec struct {
 	// +kubebuilder:default=Overwrite
 	// +optional
 	UpdateMode UpdateMode `json:"updateMode,omitempty"`
+
+	// RequirePassingTests keeps the deployed resource unchanged while any of its spec.tests fail.
+	// +optional
+	RequirePassingTests bool `json:"requirePassingTests,omitempty"`
+
+	// PreserveMeta merges the _meta of the deployed resource into the _meta of the body on update instead of
+	// replacing it, keeping keys set by other tools. Keys of the body take precedence.
+	// +optional
+	PreserveMeta bool `json:"preserveMeta,omitempty"`
+}
+
+// SecretTemplate shapes the Secret the credentials are written to, so workloads can mount them as they are
+type SecretTemplate struct {
+	// Labels are added to the Secret
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to the Secret
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Data maps keys of the Secret to Helm templates rendered with the credentials as .Values, e.g. an
+	// elasticsearch.yml snippet or a Beats output configuration
+	// +optional
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// SecurityRefresh is the refresh strategy of writes to users and roles, the refresh parameter of the security APIs
+// +kubebuilder:validation:Enum="true";wait_for;"false"
+type SecurityRefresh string
+
+const (
+	// SecurityRefreshTrue refreshes the security index right after the write
+	SecurityRefreshTrue SecurityRefresh = "true"
+	// SecurityRefreshWaitFor waits for the next refresh of the security index
+	SecurityRefreshWaitFor SecurityRefresh = "wait_for"
+	// SecurityRefreshFalse returns without waiting, the write becomes visible with the next periodic refresh
+	SecurityRefreshFalse SecurityRefresh = "false"
+)
+
+// SecurityAdoption records a role or user adopted from Elasticsearch, see the eck.github.com/adopt annotation
+type SecurityAdoption struct {
+	// Hash is the sha256 hash of the definition of the role or user in Elasticsearch when it was adopted
+	// +optional
+	Hash string `json:"hash,omitempty"`
+	// Generation of the resource when the role or user was adopted, the body is applied from later generations on
+	// +kubebuilder:validation:Format=int64
+	Generation int64 `json:"generation"`
+	// Time the role or user was adopted
+	// +optional
+	Time metav1.Time `json:"time,omitempty"`
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -30,11 +31,32 @@ type ComponentTemplateSpec struct {
 	// +optional
 	Dependencies Dependencies `json:"dependencies,omitempty"`
 
-	Body string `json:"body"`
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// BodyJSON is the body as structured JSON instead of a string. It is stored as it is and printed readable by
+	// kubectl. Only one of body and bodyJson may be set.
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	BodyJSON *apiextensionsv1.JSON `json:"bodyJson,omitempty"`
 }
 
 // ComponentTemplateStatus defines the observed state of ComponentTemplate
 type ComponentTemplateStatus struct {
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// Failover records the instance of each failover pair holding the object
+	// +listType=map
+	// +listMapKey=primary
+	// +optional
+	Failover []FailoverStatus `json:"failover,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -0,0 +1,143 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConnectionTest targets
+const (
+	ConnectionTestTargetElasticsearch = "Elasticsearch"
+	ConnectionTestTargetKibana        = "Kibana"
+)
+
+// ConnectionTest phases
+const (
+	ConnectionTestPhaseSucceeded = "Succeeded"
+	ConnectionTestPhaseFailed    = "Failed"
+)
+
+// ConnectionTest checks, run in this order
+const (
+	ConnectionTestCheckConnectivity   = "Connectivity"
+	ConnectionTestCheckAuthentication = "Authentication"
+	ConnectionTestCheckPermissions    = "Permissions"
+)
+
+// ConnectionTestSpec defines the desired state of ConnectionTest
+type ConnectionTestSpec struct {
+	// Target is the kind of instance tested, Elasticsearch or Kibana. Defaults to Elasticsearch.
+	// +kubebuilder:validation:Enum=Elasticsearch;Kibana
+	// +optional
+	Target string `json:"target,omitempty"`
+
+	// TargetInstance is the instance tested, resolved like the targetInstance of other resources: by name, by the
+	// default instance annotation of the namespace, or the instance of the operator configuration
+	// +optional
+	TargetInstance ConnectionTestTargetInstance `json:"targetInstance,omitempty"`
+
+	// ClusterPrivileges the Elasticsearch user needs, e.g. manage_index_templates or manage_security. Defaults to
+	// monitor, used by the health checks of the operator.
+	// +optional
+	ClusterPrivileges []string `json:"clusterPrivileges,omitempty"`
+
+	// Space of Kibana in which read access to saved objects is checked, the default space if empty
+	// +optional
+	Space string `json:"space,omitempty"`
+
+	// Interval repeats the test, if not set it is only run when the ConnectionTest changes or is triggered
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// ConnectionTestTargetInstance references the ElasticsearchInstance, ClusterElasticsearchInstance, KibanaInstance or
+// ClusterKibanaInstance tested
+type ConnectionTestTargetInstance struct {
+	// +optional
+	Name string `json:"name,omitempty"`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Kind of the instance, ElasticsearchInstance or ClusterElasticsearchInstance for the Elasticsearch target,
+	// KibanaInstance or ClusterKibanaInstance for the Kibana target. Defaults to the namespaced kind of the target.
+	// +kubebuilder:validation:Enum=ElasticsearchInstance;ClusterElasticsearchInstance;KibanaInstance;ClusterKibanaInstance
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// ConnectionTestCheck is the result of a single check of a ConnectionTest
+type ConnectionTestCheck struct {
+	// Name of the check, one of Connectivity, Authentication or Permissions
+	Name string `json:"name"`
+	// Passed is whether the check passed
+	Passed bool `json:"passed"`
+	// Message describes the result
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ConnectionTestStatus defines the observed state of ConnectionTest
+type ConnectionTestStatus struct {
+	// Phase is Succeeded if all checks passed, Failed otherwise
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// Url of the tested instance
+	// +optional
+	Url string `json:"url,omitempty"`
+	// Version of the tested instance
+	// +optional
+	Version string `json:"version,omitempty"`
+	// Username the operator is authenticated as in Elasticsearch
+	// +optional
+	Username string `json:"username,omitempty"`
+	// Checks are the results of the checks, a check is left out if an earlier one failed
+	// +optional
+	Checks []ConnectionTestCheck `json:"checks,omitempty"`
+	// LastTestTime is when the test was run the last time
+	// +optional
+	LastTestTime *metav1.Time `json:"lastTestTime,omitempty"`
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// ConnectionTest is the Schema for the connectiontests API
+type ConnectionTest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ConnectionTestSpec   `json:"spec,omitempty"`
+	Status ConnectionTestStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ConnectionTestList contains a list of ConnectionTest
+type ConnectionTestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ConnectionTest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ConnectionTest{}, &ConnectionTestList{})
+}
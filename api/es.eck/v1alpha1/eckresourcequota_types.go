@@ -0,0 +1,98 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EckResourceQuotaSpec defines the desired state of EckResourceQuota. Unset limits do not limit the resources, with
+// several quotas in a namespace the lowest limit applies.
+type EckResourceQuotaSpec struct {
+	// MaxIndices limits the Index resources of the namespace
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxIndices *int32 `json:"maxIndices,omitempty"`
+
+	// MaxIngestPipelines limits the IngestPipeline resources of the namespace
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxIngestPipelines *int32 `json:"maxIngestPipelines,omitempty"`
+
+	// MaxAPIKeys limits the ElasticsearchApikey resources of the namespace
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxAPIKeys *int32 `json:"maxApiKeys,omitempty"`
+
+	// MaxSavedObjects limits the Dashboard, Visualization, Lens, SavedSearch, IndexPattern, CanvasWorkpad and
+	// DataView resources of the namespace together
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxSavedObjects *int32 `json:"maxSavedObjects,omitempty"`
+}
+
+// EckResourceQuotaUsage counts the resources of the namespace limited by a quota
+type EckResourceQuotaUsage struct {
+	// +optional
+	Indices int32 `json:"indices,omitempty"`
+	// +optional
+	IngestPipelines int32 `json:"ingestPipelines,omitempty"`
+	// +optional
+	APIKeys int32 `json:"apiKeys,omitempty"`
+	// +optional
+	SavedObjects int32 `json:"savedObjects,omitempty"`
+}
+
+// EckResourceQuotaStatus defines the observed state of EckResourceQuota
+type EckResourceQuotaStatus struct {
+	// Used counts the resources of the namespace when the quota was last reconciled
+	// +optional
+	Used EckResourceQuotaUsage `json:"used,omitempty"`
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// EckResourceQuota is the Schema for the eckresourcequotas API
+type EckResourceQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EckResourceQuotaSpec   `json:"spec,omitempty"`
+	Status EckResourceQuotaStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// EckResourceQuotaList contains a list of EckResourceQuota
+type EckResourceQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EckResourceQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EckResourceQuota{}, &EckResourceQuotaList{})
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -25,18 +26,101 @@ type ElasticsearchApikeySpec struct {
 	// +optional
 	TargetConfig CommonElasticsearchConfig `json:"targetInstance,omitempty"`
 
-	Body string `json:"body"`
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// BodyJSON is the body as structured JSON instead of a string. It is stored as it is and printed readable by
+	// kubectl. Only one of body and bodyJson may be set.
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	BodyJSON *apiextensionsv1.JSON `json:"bodyJson,omitempty"`
+
+	// SecretName is the name of the Secret the API key is stored in, the Secret is owned by the ElasticsearchApikey
+	// and garbage collected with it. Defaults to the name of the ElasticsearchApikey.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// ExpiryWarningDays is how many days before the expiration of the API key the Expiring condition is set and a
+	// Warning event is emitted. 0 disables the warning.
+	// +kubebuilder:default=14
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ExpiryWarningDays int32 `json:"expiryWarningDays,omitempty"`
+
+	// SecretTemplate adds labels, annotations and rendered keys to the Secret of the API key. The templates can use
+	// .Values.id, .Values.name, .Values.apiKey, .Values.encoded and .Values.url.
+	// +optional
+	SecretTemplate *SecretTemplate `json:"secretTemplate,omitempty"`
+
+	// SecretFormats adds the API key to the Secret in built-in formats Elastic Agent, Beats and HTTP clients consume,
+	// see ApikeySecretFormat. Defaults to the formats set by --apikey-secret-formats of the operator.
+	// +listType=set
+	// +optional
+	SecretFormats []ApikeySecretFormat `json:"secretFormats,omitempty"`
+
+	// Consumers selects the workloads using the API key. The Secret of the API key is injected into them, and they
+	// are restarted when the API key is rotated.
+	// +optional
+	Consumers *ApikeyConsumers `json:"consumers,omitempty"`
+}
+
+// ApikeySecretFormat is a built-in format the API key is added to its Secret in
+// +kubebuilder:validation:Enum=Env;BeatsOutput;AuthorizationHeader
+type ApikeySecretFormat string
+
+const (
+	// ApikeySecretFormatEnv adds the encoded API key as ES_API_KEY, to be used with envFrom
+	ApikeySecretFormatEnv ApikeySecretFormat = "Env"
+	// ApikeySecretFormatBeatsOutput adds elasticsearch-output.yml, a YAML snippet setting output.elasticsearch.api_key
+	// to <id>:<api key>
+	ApikeySecretFormatBeatsOutput ApikeySecretFormat = "BeatsOutput"
+	// ApikeySecretFormatAuthorizationHeader adds authorization, the value "ApiKey <encoded>" of the Authorization
+	// header
+	ApikeySecretFormatAuthorizationHeader ApikeySecretFormat = "AuthorizationHeader"
+)
+
+// ApikeyConsumers selects the workloads the Secret of an API key is injected into
+type ApikeyConsumers struct {
+	// Selector selects the Deployments and StatefulSets in the namespace of the ElasticsearchApikey by their labels
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// MountPath mounts the Secret into all containers of the workloads at this path. The Secret is not mounted if not
+	// set, the workloads are only restarted when the API key is rotated.
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
 }
 
 // ElasticsearchApikeyStatus defines the observed state of ElasticsearchApikey
 type ElasticsearchApikeyStatus struct {
 	// +optional
 	APIKeyID string `json:"apiKeyID,omitempty"`
+	// PendingAPIKeyID is the ID of an API key created in Elasticsearch whose Secret is not written yet. The creation
+	// interrupted at this checkpoint is resumed by the next reconcile.
+	// +optional
+	PendingAPIKeyID string `json:"pendingAPIKeyID,omitempty"`
+	// Expiration of the API key, not set if the key does not expire
+	// +optional
+	Expiration *metav1.Time `json:"expiration,omitempty"`
+	// Invalidated is true if the API key was invalidated in Elasticsearch
+	// +optional
+	Invalidated bool `json:"invalidated,omitempty"`
 	// +kubebuilder:validation:Format=int64
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// Consumers are the workloads the Secret of the API key is injected into, as <kind>/<name>
+	// +optional
+	Consumers []string `json:"consumers,omitempty"`
+	// Failover records the instance of each failover pair holding the object
+	// +listType=map
+	// +listMapKey=primary
+	// +optional
+	Failover []FailoverStatus `json:"failover,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -60,6 +144,14 @@ type ElasticsearchApikeyList struct {
 	Items           []ElasticsearchApikey `json:"items"`
 }
 
+// GetSecretName returns the name of the Secret the API key is stored in
+func (in *ElasticsearchApikey) GetSecretName() string {
+	if in.Spec.SecretName != "" {
+		return in.Spec.SecretName
+	}
+	return in.Name
+}
+
 func init() {
 	SchemeBuilder.Register(&ElasticsearchApikey{}, &ElasticsearchApikeyList{})
 }
@@ -0,0 +1,108 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ElasticsearchRawRequestSpec defines the desired state of ElasticsearchRawRequest
+type ElasticsearchRawRequestSpec struct {
+	// +optional
+	TargetConfig CommonElasticsearchConfig `json:"targetInstance,omitempty"`
+
+	// Path of the PUT request sent to Elasticsearch on every reconcile, including the query string, e.g.
+	// /_security/role_mapping/admins. It has to start with one of the rawRequests.elasticsearch prefixes of the operator configuration.
+	// +kubebuilder:validation:Pattern=`^/`
+	// +required
+	Path string `json:"path"`
+
+	// Body of the PUT request
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// DeletePath is the path of the DELETE request sent when the resource is deleted, path if not set. An empty
+	// string leaves the object in Elasticsearch in place.
+	// +optional
+	DeletePath *string `json:"deletePath,omitempty"`
+}
+
+// GetDeletePath returns the path of the DELETE request sent when the resource is deleted, empty if none is sent
+func (in *ElasticsearchRawRequestSpec) GetDeletePath() string {
+	if in.DeletePath == nil {
+		return in.Path
+	}
+	return *in.DeletePath
+}
+
+// ElasticsearchRawRequestStatus defines the observed state of ElasticsearchRawRequest
+type ElasticsearchRawRequestStatus struct {
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// AppliedPath is the path of the last successful PUT request
+	// +optional
+	AppliedPath string `json:"appliedPath,omitempty"`
+	// AppliedDeletePath is the path of the DELETE request removing the object of the last successful PUT request,
+	// sent when the resource is deleted or its path changes. Empty if the object is left in place.
+	// +optional
+	AppliedDeletePath string `json:"appliedDeletePath,omitempty"`
+	// Failover records the instance of each failover pair holding the object
+	// +listType=map
+	// +listMapKey=primary
+	// +optional
+	Failover []FailoverStatus `json:"failover,omitempty"`
+}
+
+// GetAppliedDeletePath returns the path of the DELETE request removing the object of the resource, the one recorded
+// with the last successful PUT request, or the one of the spec if none was recorded yet
+func (in *ElasticsearchRawRequest) GetAppliedDeletePath() string {
+	if in.Status.AppliedPath == "" {
+		return in.Spec.GetDeletePath()
+	}
+	return in.Status.AppliedDeletePath
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// ElasticsearchRawRequest is the Schema for the elasticsearchrawrequests API
+type ElasticsearchRawRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticsearchRawRequestSpec   `json:"spec,omitempty"`
+	Status ElasticsearchRawRequestStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ElasticsearchRawRequestList contains a list of ElasticsearchRawRequest
+type ElasticsearchRawRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticsearchRawRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticsearchRawRequest{}, &ElasticsearchRawRequestList{})
+}
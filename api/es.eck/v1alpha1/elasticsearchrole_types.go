@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -26,8 +27,42 @@ type ElasticsearchRoleSpec struct {
 	TargetConfig CommonElasticsearchConfig `json:"targetInstance,omitempty"`
 
 	// +kubebuilder:validation:MinLength=0
-	// +required
-	Body string `json:"body"`
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// BodyJSON is the body as structured JSON instead of a string. It is stored as it is and printed readable by
+	// kubectl. Only one of body and bodyJson may be set.
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	BodyJSON *apiextensionsv1.JSON `json:"bodyJson,omitempty"`
+
+	// +optional
+	Template CommonTemplatingSpec `json:"template,omitempty"`
+
+	// KibanaPrivileges grant Kibana privileges, they are added to the applications of the role body
+	// +optional
+	KibanaPrivileges []KibanaPrivilege `json:"kibanaPrivileges,omitempty"`
+
+	// Refresh is the refresh strategy of writes to the role, defaults to --security-refresh of the operator
+	// +optional
+	Refresh SecurityRefresh `json:"refresh,omitempty"`
+}
+
+// KibanaPrivilege grants either a base privilege or feature privileges in Kibana spaces
+type KibanaPrivilege struct {
+	// Spaces the privileges are granted in, all spaces if not set
+	// +optional
+	Spaces []string `json:"spaces,omitempty"`
+
+	// Base privilege granted for all features
+	// +kubebuilder:validation:Enum=all;read
+	// +optional
+	Base string `json:"base,omitempty"`
+
+	// Features maps Kibana feature ids to the granted privilege, e.g. discover: read or dashboard: all
+	// +optional
+	Features map[string]string `json:"features,omitempty"`
 }
 
 // ElasticsearchRoleStatus defines the observed state of ElasticsearchRole
@@ -37,6 +72,17 @@ type ElasticsearchRoleStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// Adoption records the role adopted from Elasticsearch, see the eck.github.com/adopt annotation
+	// +optional
+	Adoption *SecurityAdoption `json:"adoption,omitempty"`
+	// Failover records the instance of each failover pair holding the object
+	// +listType=map
+	// +listMapKey=primary
+	// +optional
+	Failover []FailoverStatus `json:"failover,omitempty"`
 }
 
 //+kubebuilder:object:root=true
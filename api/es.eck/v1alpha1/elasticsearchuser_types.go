@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -26,7 +27,56 @@ type ElasticsearchUserSpec struct {
 	TargetConfig CommonElasticsearchConfig `json:"targetInstance,omitempty"`
 
 	SecretName string `json:"secretName"`
-	Body       string `json:"body"`
+
+	// PasswordHashKey is the key of the Secret holding a hash of the password, it is sent to Elasticsearch as
+	// password_hash instead of the cleartext password under the name of the resource. The hash has to use the
+	// algorithm of xpack.security.authc.password_hashing.algorithm, bcrypt by default.
+	// +optional
+	PasswordHashKey string `json:"passwordHashKey,omitempty"`
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// BodyJSON is the body as structured JSON instead of a string. It is stored as it is and printed readable by
+	// kubectl. Only one of body and bodyJson may be set.
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	BodyJSON *apiextensionsv1.JSON `json:"bodyJson,omitempty"`
+
+	// Enabled enables or disables the user via the enable/disable user APIs. Unset leaves the user as it is.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// FullName overrides full_name of the body
+	// +optional
+	FullName string `json:"fullName,omitempty"`
+
+	// Email overrides email of the body
+	// +optional
+	Email string `json:"email,omitempty"`
+
+	// Metadata is merged over metadata of the body
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Metadata map[string]apiextensionsv1.JSON `json:"metadata,omitempty"`
+
+	// SecretTemplate generates a Secret with the credentials of the user, owned by the ElasticsearchUser. The
+	// templates can use .Values.username, .Values.password and .Values.url.
+	// +optional
+	SecretTemplate *UserSecretTemplate `json:"secretTemplate,omitempty"`
+
+	// Refresh is the refresh strategy of writes to the user, defaults to --security-refresh of the operator
+	// +optional
+	Refresh SecurityRefresh `json:"refresh,omitempty"`
+}
+
+// UserSecretTemplate is the Secret generated with the credentials of an ElasticsearchUser
+type UserSecretTemplate struct {
+	// Name of the generated Secret, defaults to the name of the ElasticsearchUser suffixed with -credentials
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	SecretTemplate `json:",inline"`
 }
 
 // ElasticsearchUserStatus defines the observed state of ElasticsearchUser
@@ -36,6 +86,17 @@ type ElasticsearchUserStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// Adoption records the user adopted from Elasticsearch, see the eck.github.com/adopt annotation
+	// +optional
+	Adoption *SecurityAdoption `json:"adoption,omitempty"`
+	// Failover records the instance of each failover pair holding the object
+	// +listType=map
+	// +listMapKey=primary
+	// +optional
+	Failover []FailoverStatus `json:"failover,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -59,6 +120,14 @@ type ElasticsearchUserList struct {
 	Items           []ElasticsearchUser `json:"items"`
 }
 
+// GetGeneratedSecretName returns the name of the Secret generated from spec.secretTemplate
+func (in *ElasticsearchUser) GetGeneratedSecretName() string {
+	if in.Spec.SecretTemplate != nil && in.Spec.SecretTemplate.Name != "" {
+		return in.Spec.SecretTemplate.Name
+	}
+	return in.Name + "-credentials"
+}
+
 func init() {
 	SchemeBuilder.Register(&ElasticsearchUser{}, &ElasticsearchUserList{})
 }
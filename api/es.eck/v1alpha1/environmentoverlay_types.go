@@ -0,0 +1,82 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EnvironmentOverlaySpec defines the desired state of EnvironmentOverlay
+type EnvironmentOverlaySpec struct {
+	// Selector selects the resources in the namespace of the overlay whose bodies are patched
+	// +required
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// Kinds limits the overlay to resources of the given kinds, e.g. Index or IndexTemplate. All supported kinds
+	// are patched if empty.
+	// +optional
+	Kinds []string `json:"kinds,omitempty"`
+
+	// MergePatch is merged into the body. As bodies have no schema, the strategic merge follows the JSON merge patch
+	// semantics (RFC 7386): objects are merged recursively, null removes a key and all other values replace.
+	// +optional
+	MergePatch string `json:"mergePatch,omitempty"`
+
+	// JSONPatch is a JSON patch (RFC 6902) applied to the body after the MergePatch
+	// +optional
+	JSONPatch string `json:"jsonPatch,omitempty"`
+}
+
+// EnvironmentOverlayStatus defines the observed state of EnvironmentOverlay
+type EnvironmentOverlayStatus struct {
+	// Selected are the resources, as Kind/name, selected by the overlay when it was last reconciled
+	// +optional
+	Selected []string `json:"selected,omitempty"`
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// EnvironmentOverlay is the Schema for the environmentoverlays API
+type EnvironmentOverlay struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EnvironmentOverlaySpec   `json:"spec,omitempty"`
+	Status EnvironmentOverlayStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// EnvironmentOverlayList contains a list of EnvironmentOverlay
+type EnvironmentOverlayList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EnvironmentOverlay `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EnvironmentOverlay{}, &EnvironmentOverlayList{})
+}
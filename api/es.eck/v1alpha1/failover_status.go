@@ -0,0 +1,102 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// FailoverStatus returns status.failover
+func (in *AutoFollowPattern) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *ComponentTemplate) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *ElasticsearchApikey) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *ElasticsearchRawRequest) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *ElasticsearchRole) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *ElasticsearchUser) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *FollowerIndex) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *Index) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *IndexLifecyclePolicy) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *IndexOperation) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *IndexTemplate) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *IngestPipeline) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *QueryRuleset) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *SnapshotLifecyclePolicy) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *SnapshotRepository) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *SnapshotRestore) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
+
+// FailoverStatus returns status.failover
+func (in *SynonymSet) FailoverStatus() *[]FailoverStatus {
+	return &in.Status.Failover
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Replication statuses as reported by Elasticsearch
+const (
+	ReplicationStatusActive = "active"
+	ReplicationStatusPaused = "paused"
+)
+
+// FollowerIndexSpec defines the desired state of FollowerIndex
+type FollowerIndexSpec struct {
+	// +optional
+	TargetConfig CommonElasticsearchConfig `json:"targetInstance,omitempty"`
+
+	// RemoteCluster is the alias of the remote cluster holding the leader index, as configured in the cluster
+	// settings of the target instance
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	RemoteCluster string `json:"remoteCluster"`
+
+	// LeaderIndex is the index of the remote cluster which is replicated
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	LeaderIndex string `json:"leaderIndex"`
+
+	// Paused pauses the replication, it is resumed once unset
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Settings override the settings of the follower index taken over from the leader index on creation,
+	// e.g. index.number_of_replicas
+	// +optional
+	Settings map[string]string `json:"settings,omitempty"`
+
+	// Parameters tune the replication, e.g. max_read_request_operation_count or read_poll_timeout. Changed
+	// parameters are applied by pausing and resuming the replication.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// FollowerIndexStatus defines the observed state of FollowerIndex
+type FollowerIndexStatus struct {
+	// ReplicationStatus of the follower index as reported by Elasticsearch, active or paused
+	// +optional
+	ReplicationStatus string `json:"replicationStatus,omitempty"`
+	// RemoteClusterConnected reports whether the remote cluster was connected on the last reconcile
+	// +optional
+	RemoteClusterConnected bool `json:"remoteClusterConnected,omitempty"`
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// Failover records the instance of each failover pair holding the object
+	// +listType=map
+	// +listMapKey=primary
+	// +optional
+	Failover []FailoverStatus `json:"failover,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// FollowerIndex is the Schema for the followerindices API
+type FollowerIndex struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FollowerIndexSpec   `json:"spec,omitempty"`
+	Status FollowerIndexStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FollowerIndexList contains a list of FollowerIndex
+type FollowerIndexList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FollowerIndex `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FollowerIndex{}, &FollowerIndexList{})
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -27,12 +28,125 @@ type IndexSpec struct {
 
 	// +optional
 	Dependencies Dependencies `json:"dependencies,omitempty"`
-	// +required
-	Body string `json:"body"`
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// BodyJSON is the body as structured JSON instead of a string. It is stored as it is and printed readable by
+	// kubectl. Only one of body and bodyJson may be set.
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	BodyJSON *apiextensionsv1.JSON `json:"bodyJson,omitempty"`
+
+	// ILMPolicyRef references the IndexLifecyclePolicy attached via the index.lifecycle.name setting. The resource is
+	// only applied once the policy is Ready.
+	// +optional
+	ILMPolicyRef *IndexLifecyclePolicyReference `json:"ilmPolicyRef,omitempty"`
+
+	// Force allows managing hidden and system indices, e.g. ones starting with a dot
+	// +optional
+	Force bool `json:"force,omitempty"`
+
+	// RuntimeMappings is a JSON object of runtime fields, added to the mappings on creation and applied via the
+	// _mapping API on updates, removing runtime fields which are no longer declared
+	// +optional
+	RuntimeMappings string `json:"runtimeMappings,omitempty"`
+
+	// FieldAliases maps alias field names to the path of the field they point to
+	// +optional
+	FieldAliases map[string]string `json:"fieldAliases,omitempty"`
+
+	// Blocks are the index blocks set via the index.blocks settings. If not set, the blocks of the index are not
+	// managed.
+	// +optional
+	Blocks *IndexBlocks `json:"blocks,omitempty"`
+
+	// Aliases are created with the index and kept in sync on updates, aliases of the index which are neither declared
+	// here nor in the body are removed. If not set, the aliases of the index are not managed.
+	// +optional
+	Aliases []IndexAlias `json:"aliases,omitempty"`
+}
+
+// IndexAlias is an alias of an index
+type IndexAlias struct {
+	// Name of the alias
+	Name string `json:"name"`
+
+	// Filter is a JSON query limiting the documents accessible via the alias
+	// +optional
+	Filter string `json:"filter,omitempty"`
+
+	// Routing is used for both indexing and search operations via the alias
+	// +optional
+	Routing string `json:"routing,omitempty"`
+
+	// IndexRouting is used for indexing operations via the alias, it overrides routing
+	// +optional
+	IndexRouting string `json:"indexRouting,omitempty"`
+
+	// SearchRouting is used for search operations via the alias, it overrides routing
+	// +optional
+	SearchRouting string `json:"searchRouting,omitempty"`
+
+	// IsWriteIndex makes the index the write index of an alias pointing to several indices
+	// +optional
+	IsWriteIndex *bool `json:"isWriteIndex,omitempty"`
+
+	// IsHidden hides the alias from wildcard expressions
+	// +optional
+	IsHidden bool `json:"isHidden,omitempty"`
+
+	// MoveFrom lists the indices, wildcards allowed, the alias is removed from in the same request it is added to this
+	// index, so that it is moved atomically, e.g. when the index replaces an index of another name
+	// +optional
+	MoveFrom []string `json:"moveFrom,omitempty"`
+}
+
+// IndexBlocks are the blocks of an index. Only the most restrictive block is set, in the order readOnly,
+// readOnlyAllowDelete, write; the others are cleared.
+type IndexBlocks struct {
+	// ReadOnly makes the index and its metadata read-only
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
+
+	// ReadOnlyAllowDelete makes the index and its metadata read-only, but allows deleting documents and the index.
+	// Elasticsearch sets it when a node exceeds the flood stage disk watermark. If not set here, the block is only
+	// cleared once the disk usage of all nodes dropped below the high disk watermark.
+	// +optional
+	ReadOnlyAllowDelete bool `json:"readOnlyAllowDelete,omitempty"`
+
+	// Write blocks writing documents, the metadata can still be changed
+	// +optional
+	Write bool `json:"write,omitempty"`
 }
 
 // IndexStatus defines the observed state of Index
 type IndexStatus struct {
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// MigrationTask is the reindex task copying the documents of the index named before the naming policy applied,
+	// set while it runs
+	// +optional
+	MigrationTask string `json:"migrationTask,omitempty"`
+	// ManagedAliases are the aliases of spec.aliases last applied to the index. Aliases removed from spec.aliases are
+	// removed from the index.
+	// +optional
+	ManagedAliases []string `json:"managedAliases,omitempty"`
+	// ManagedRuntimeFields are the runtime fields of spec.runtimeMappings last applied to the index. Runtime fields
+	// removed from spec.runtimeMappings are removed from the index.
+	// +optional
+	ManagedRuntimeFields []string `json:"managedRuntimeFields,omitempty"`
+	// Failover records the instance of each failover pair holding the object
+	// +listType=map
+	// +listMapKey=primary
+	// +optional
+	Failover []FailoverStatus `json:"failover,omitempty"`
 }
 
 //+kubebuilder:object:root=true
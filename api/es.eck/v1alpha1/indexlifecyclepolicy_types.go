@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -26,8 +27,34 @@ type IndexLifecyclePolicySpec struct {
 	TargetConfig CommonElasticsearchConfig `json:"targetInstance,omitempty"`
 
 	// +kubebuilder:validation:MinLength=0
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// BodyJSON is the body as structured JSON instead of a string. It is stored as it is and printed readable by
+	// kubectl. Only one of body and bodyJson may be set.
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	BodyJSON *apiextensionsv1.JSON `json:"bodyJson,omitempty"`
+
+	// SnapshotBeforeDelete holds back changes which add a delete phase to the deployed policy until a
+	// SnapshotLifecyclePolicy completed a recent successful snapshot
+	// +optional
+	SnapshotBeforeDelete *SnapshotBeforeDeleteSpec `json:"snapshotBeforeDelete,omitempty"`
+}
+
+// SnapshotBeforeDeleteSpec references the SnapshotLifecyclePolicy whose last successful snapshot is checked
+type SnapshotBeforeDeleteSpec struct {
+	// SnapshotLifecyclePolicy is the name of the snapshot lifecycle policy in Elasticsearch, which is the name of the
+	// SnapshotLifecyclePolicy resource managing it
+	// +kubebuilder:validation:MinLength=1
 	// +required
-	Body string `json:"body"`
+	SnapshotLifecyclePolicy string `json:"snapshotLifecyclePolicy"`
+
+	// MaxAge is how old the last successful snapshot may be. Defaults to 24h.
+	// +kubebuilder:default="24h"
+	// +optional
+	MaxAge metav1.Duration `json:"maxAge,omitempty"`
 }
 
 // IndexLifecyclePolicyStatus defines the observed state of IndexLifecyclePolicy
@@ -37,6 +64,33 @@ type IndexLifecyclePolicyStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// Rollout counts the indices managed by the policy by the version of the policy they execute, updated whenever
+	// the policy is applied and at the periodic resync
+	// +optional
+	Rollout *IndexLifecyclePolicyRollout `json:"rollout,omitempty"`
+	// Failover records the instance of each failover pair holding the object
+	// +listType=map
+	// +listMapKey=primary
+	// +optional
+	Failover []FailoverStatus `json:"failover,omitempty"`
+}
+
+// IndexLifecyclePolicyRollout is the progress of the deployed version of an index lifecycle policy. Indices execute the
+// phase definition of the version current when they entered the phase, they move to a new version once they enter
+// their next phase.
+type IndexLifecyclePolicyRollout struct {
+	// PolicyVersion is the version of the deployed policy, incremented by Elasticsearch on every update
+	// +kubebuilder:validation:Format=int64
+	PolicyVersion int64 `json:"policyVersion"`
+	// Indices is the number of indices managed by the policy
+	Indices int32 `json:"indices"`
+	// UpdatedIndices is the number of indices executing the deployed version of the policy
+	UpdatedIndices int32 `json:"updatedIndices"`
+	// OutdatedIndices is the number of indices still executing an older version of the policy
+	OutdatedIndices int32 `json:"outdatedIndices"`
 }
 
 //+kubebuilder:object:root=true
@@ -0,0 +1,133 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IndexOperation operations
+const (
+	IndexOperationShrink = "Shrink"
+	IndexOperationSplit  = "Split"
+	IndexOperationClone  = "Clone"
+)
+
+// IndexOperation phases
+const (
+	IndexOperationPhasePreparing = "Preparing"
+	IndexOperationPhaseRunning   = "Running"
+	IndexOperationPhaseCompleted = "Completed"
+	IndexOperationPhaseFailed    = "Failed"
+)
+
+// IndexOperationSpec defines the desired state of IndexOperation
+type IndexOperationSpec struct {
+	// Index is the name of the Index resource in the namespace whose index is resized. The operation is run against
+	// the target instance of the Index.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Index string `json:"index"`
+
+	// Operation is one of Shrink, Split or Clone
+	// +kubebuilder:validation:Enum=Shrink;Split;Clone
+	// +required
+	Operation string `json:"operation"`
+
+	// Target is the name of the index created by the operation
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Target string `json:"target"`
+
+	// NumberOfShards of the target index, required for Shrink and Split. It has to be a factor of the number of
+	// shards of the source for Shrink and a multiple of it for Split.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	NumberOfShards *int32 `json:"numberOfShards,omitempty"`
+
+	// Settings of the target index, e.g. index.number_of_replicas
+	// +optional
+	Settings map[string]string `json:"settings,omitempty"`
+
+	// ShrinkNode is the node a copy of every shard of the source is moved to before a Shrink, via the
+	// index.routing.allocation.require._name setting. Elasticsearch refuses to shrink indices whose shards are spread
+	// over several nodes. The requirement is removed from both indices once the operation is done.
+	// +optional
+	ShrinkNode string `json:"shrinkNode,omitempty"`
+
+	// ReleaseWriteBlock removes the write block from the source index once the operation completed. The block is kept
+	// by default, e.g. to delete the source once the target is verified.
+	// +optional
+	ReleaseWriteBlock bool `json:"releaseWriteBlock,omitempty"`
+}
+
+// IndexOperationStatus defines the observed state of IndexOperation
+type IndexOperationStatus struct {
+	// Phase of the operation, one of Preparing, Running, Completed or Failed
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// Source is the name of the index the operation is run against
+	// +optional
+	Source string `json:"source,omitempty"`
+	// StartTime is when the preparation of the source was started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is when all primary shards of the target were allocated
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Message is why the operation failed or what it is waiting for
+	// +optional
+	Message string `json:"message,omitempty"`
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// Failover records the instance of each failover pair holding the object
+	// +listType=map
+	// +listMapKey=primary
+	// +optional
+	Failover []FailoverStatus `json:"failover,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// IndexOperation is the Schema for the indexoperations API
+type IndexOperation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IndexOperationSpec   `json:"spec,omitempty"`
+	Status IndexOperationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// IndexOperationList contains a list of IndexOperation
+type IndexOperationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IndexOperation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IndexOperation{}, &IndexOperationList{})
+}
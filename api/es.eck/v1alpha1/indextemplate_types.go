@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -28,8 +29,107 @@ type IndexTemplateSpec struct {
 	// +optional
 	Dependencies Dependencies `json:"dependencies,omitempty"`
 	// +kubebuilder:validation:MinLength=0
-	// +required
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// BodyJSON is the body as structured JSON instead of a string. It is stored as it is and printed readable by
+	// kubectl. Only one of body and bodyJson may be set.
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	BodyJSON *apiextensionsv1.JSON `json:"bodyJson,omitempty"`
+
+	// ILMPolicyRef references the IndexLifecyclePolicy attached via the index.lifecycle.name setting. The resource is
+	// only applied once the policy is Ready.
+	// +optional
+	ILMPolicyRef *IndexLifecyclePolicyReference `json:"ilmPolicyRef,omitempty"`
+
+	// ReportDeletionImpact reports in status.deletionImpact what deleting the index template affects, and records it
+	// with a DeletionImpact event when the IndexTemplate is deleted
+	// +optional
+	ReportDeletionImpact bool `json:"reportDeletionImpact,omitempty"`
+
+	// UpdatePolicy defines how changes of the template are applied
+	// +optional
+	UpdatePolicy IndexTemplateUpdatePolicy `json:"updatePolicy,omitempty"`
+}
+
+// IndexTemplateUpdateMode defines how changes of an IndexTemplate are applied
+// +kubebuilder:validation:Enum=Overwrite;Canary
+type IndexTemplateUpdateMode string
+
+const (
+	// IndexTemplateUpdateModeOverwrite applies changes to the template directly
+	IndexTemplateUpdateModeOverwrite IndexTemplateUpdateMode = "Overwrite"
+	// IndexTemplateUpdateModeCanary applies changes under a temporary name first, creates a canary index from it and
+	// runs the canary queries, and only replaces the template once they passed
+	IndexTemplateUpdateModeCanary IndexTemplateUpdateMode = "Canary"
+)
+
+// IndexTemplateUpdatePolicy defines how changes of an IndexTemplate are applied
+type IndexTemplateUpdatePolicy struct {
+	// UpdateMode defines how changes are applied. Defaults to Overwrite.
+	// +kubebuilder:default=Overwrite
+	// +optional
+	UpdateMode IndexTemplateUpdateMode `json:"updateMode,omitempty"`
+
+	// Canary configures the canary index of the Canary update mode
+	// +optional
+	Canary *IndexTemplateCanary `json:"canary,omitempty"`
+}
+
+// IndexTemplateCanary is the canary index created from a changed template before it replaces the template
+type IndexTemplateCanary struct {
+	// Index is the name of the canary index, or data stream if the template declares data_stream. It must not exist
+	// otherwise, as it is deleted after the canary. Defaults to eck-canary-<template name>.
+	// +optional
+	Index string `json:"index,omitempty"`
+
+	// Documents are indexed into the canary index before the queries are run
+	// +optional
+	Documents []string `json:"documents,omitempty"`
+
+	// Queries are run against the canary index once the documents are indexed
+	// +optional
+	Queries []IndexTemplateCanaryQuery `json:"queries,omitempty"`
+}
+
+// IndexTemplateCanaryQuery is a search run against the canary index
+type IndexTemplateCanaryQuery struct {
+	Name string `json:"name"`
+	// Body is the body of the search request
 	Body string `json:"body"`
+	// ExpectedHits is the total number of hits the search has to return, any number if not set
+	// +optional
+	ExpectedHits *int64 `json:"expectedHits,omitempty"`
+}
+
+// IndexTemplateCanaryResult is the outcome of a step of the canary
+type IndexTemplateCanaryResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// IndexTemplateCanaryStatus is the outcome of the canary of the last changed template
+type IndexTemplateCanaryStatus struct {
+	// BodyHash is the hash of the template body and spec.updatePolicy.canary the canary ran for
+	BodyHash string `json:"bodyHash"`
+	Passed   bool   `json:"passed"`
+	// Results lists the failed setup steps and the outcome of each query
+	// +optional
+	Results []IndexTemplateCanaryResult `json:"results,omitempty"`
+}
+
+// IndexTemplateDeletionImpact is what deleting an index template affects in Elasticsearch
+type IndexTemplateDeletionImpact struct {
+	// MatchingIndices is the number of existing indices and data streams matching the index patterns
+	MatchingIndices int32 `json:"matchingIndices"`
+	// UncoveredPatterns are the index patterns no other index template matches. Indices of these patterns created
+	// after the deletion get neither the mappings nor the settings of a template.
+	// +optional
+	UncoveredPatterns []string `json:"uncoveredPatterns,omitempty"`
 }
 
 // IndexTemplateStatus defines the observed state of IndexTemplate
@@ -39,6 +139,20 @@ type IndexTemplateStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// DeletionImpact is what deleting the index template affects, reported if spec.reportDeletionImpact is set
+	// +optional
+	DeletionImpact *IndexTemplateDeletionImpact `json:"deletionImpact,omitempty"`
+	// Canary is the outcome of the canary of the last changed template, reported in the Canary update mode
+	// +optional
+	Canary *IndexTemplateCanaryStatus `json:"canary,omitempty"`
+	// Failover records the instance of each failover pair holding the object
+	// +listType=map
+	// +listMapKey=primary
+	// +optional
+	Failover []FailoverStatus `json:"failover,omitempty"`
 }
 
 //+kubebuilder:object:root=true
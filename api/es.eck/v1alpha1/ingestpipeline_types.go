@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -25,7 +26,15 @@ type IngestPipelineSpec struct {
 	// +optional
 	TargetConfig CommonElasticsearchConfig `json:"targetInstance,omitempty"`
 
-	Body string `json:"body"`
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// BodyJSON is the body as structured JSON instead of a string. It is stored as it is and printed readable by
+	// kubectl. Only one of body and bodyJson may be set.
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	BodyJSON *apiextensionsv1.JSON `json:"bodyJson,omitempty"`
 
 	// +optional
 	Template CommonTemplatingSpec `json:"template,omitempty"`
@@ -33,6 +42,31 @@ type IngestPipelineSpec struct {
 	// UpdatePolicy defines how updates should be handled.
 	// +optional
 	UpdatePolicy UpdatePolicySpec `json:"updatePolicy,omitempty"`
+
+	// Tests are run against the pipeline using the simulate API on every reconcile.
+	// +optional
+	Tests []IngestPipelineTest `json:"tests,omitempty"`
+}
+
+// IngestPipelineTest is a sample document together with the fields the pipeline is expected to produce from it
+type IngestPipelineTest struct {
+	// Name identifies the test in the status
+	Name string `json:"name"`
+
+	// Document is the JSON _source of the sample document, e.g. {"message": "<sample log line>"}
+	Document string `json:"document"`
+
+	// ExpectedFields maps field names (dot notation for nested fields) to their expected values
+	// +kubebuilder:pruning:PreserveUnknownFields
+	ExpectedFields map[string]apiextensionsv1.JSON `json:"expectedFields"`
+}
+
+// IngestPipelineTestResult is the outcome of a single IngestPipelineTest
+type IngestPipelineTestResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // IngestPipelineStatus defines the observed state of IngestPipeline
@@ -42,6 +76,42 @@ type IngestPipelineStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// +optional
+	Tests []IngestPipelineTestResult `json:"tests,omitempty"`
+	// Stats are the ingest statistics of the pipeline as of the last reconcile
+	// +optional
+	Stats *IngestPipelineStats `json:"stats,omitempty"`
+	// Failover records the instance of each failover pair holding the object
+	// +listType=map
+	// +listMapKey=primary
+	// +optional
+	Failover []FailoverStatus `json:"failover,omitempty"`
+}
+
+// IngestPipelineStats are the ingest statistics of a pipeline summed over the nodes of the cluster. Nodes count from
+// their start, the numbers drop when a node restarts.
+type IngestPipelineStats struct {
+	// Count of the documents the pipeline processed
+	Count int64 `json:"count"`
+	// Failed is the count of the documents the pipeline failed to process
+	Failed int64 `json:"failed"`
+	// TimeInMillis the pipeline spent processing documents
+	TimeInMillis int64 `json:"timeInMillis"`
+	// FailedProcessors are the processors of the pipeline which failed documents, e.g. a grok processor whose
+	// patterns do not match
+	// +optional
+	FailedProcessors []IngestProcessorFailures `json:"failedProcessors,omitempty"`
+}
+
+// IngestProcessorFailures counts the documents a processor of a pipeline failed to process
+type IngestProcessorFailures struct {
+	// Processor is the tag of the processor, or its type if it has no tag
+	Processor string `json:"processor"`
+	Type      string `json:"type"`
+	Failed    int64  `json:"failed"`
 }
 
 // Condition types for IngestPipeline
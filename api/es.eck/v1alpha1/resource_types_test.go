@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"testing"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -246,3 +247,128 @@ func TestIndexLifecyclePolicyList(t *testing.T) {
 		t.Errorf("Expected 1 item, got %d", len(list.Items))
 	}
 }
+
+func TestEnvironmentOverlayDeepCopy(t *testing.T) {
+	original := &EnvironmentOverlay{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev-replicas"},
+		Spec: EnvironmentOverlaySpec{
+			Selector:   metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+			Kinds:      []string{"Index"},
+			MergePatch: `{"settings": {"number_of_replicas": 0}}`,
+		},
+		Status: EnvironmentOverlayStatus{Selected: []string{"Index/logs"}},
+	}
+
+	copied := original.DeepCopy()
+	copied.Spec.Selector.MatchLabels["env"] = "prod"
+	copied.Spec.Kinds[0] = "IndexTemplate"
+	copied.Status.Selected[0] = "Index/metrics"
+
+	if original.Spec.Selector.MatchLabels["env"] != "dev" {
+		t.Errorf("Expected the copy not to share the Selector, got %v", original.Spec.Selector.MatchLabels)
+	}
+	if original.Spec.Kinds[0] != "Index" {
+		t.Errorf("Expected the copy not to share Kinds, got %v", original.Spec.Kinds)
+	}
+	if original.Status.Selected[0] != "Index/logs" {
+		t.Errorf("Expected the copy not to share Selected, got %v", original.Status.Selected)
+	}
+}
+
+func TestSnapshotRestoreDeepCopy(t *testing.T) {
+	startTime := metav1.Now()
+	original := &SnapshotRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+		Spec: SnapshotRestoreSpec{
+			Repository:    "backups",
+			Snapshot:      "nightly",
+			Indices:       []string{"logs-*"},
+			IndexSettings: map[string]string{"index.number_of_replicas": "0"},
+		},
+		Status: SnapshotRestoreStatus{StartTime: &startTime, Indices: []string{"logs-000001"}},
+	}
+
+	copied := original.DeepCopy()
+	copied.Spec.Indices[0] = "metrics-*"
+	copied.Spec.IndexSettings["index.number_of_replicas"] = "1"
+	copied.Status.StartTime.Time = startTime.Add(time.Hour)
+	copied.Status.Indices[0] = "metrics-000001"
+
+	if original.Spec.Indices[0] != "logs-*" {
+		t.Errorf("Expected the copy not to share Indices, got %v", original.Spec.Indices)
+	}
+	if original.Spec.IndexSettings["index.number_of_replicas"] != "0" {
+		t.Errorf("Expected the copy not to share IndexSettings, got %v", original.Spec.IndexSettings)
+	}
+	if !original.Status.StartTime.Equal(&startTime) {
+		t.Errorf("Expected the copy not to share StartTime, got %v", original.Status.StartTime)
+	}
+	if original.Status.Indices[0] != "logs-000001" {
+		t.Errorf("Expected the copy not to share the restored Indices, got %v", original.Status.Indices)
+	}
+}
+
+func TestFollowerIndexDeepCopy(t *testing.T) {
+	original := &FollowerIndex{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+		Spec: FollowerIndexSpec{
+			RemoteCluster: "leader",
+			LeaderIndex:   "logs",
+			Settings:      map[string]string{"index.number_of_replicas": "0"},
+			Parameters:    map[string]string{"read_poll_timeout": "1m"},
+		},
+	}
+
+	copied := original.DeepCopy()
+	copied.Spec.Settings["index.number_of_replicas"] = "1"
+	copied.Spec.Parameters["read_poll_timeout"] = "5m"
+
+	if original.Spec.Settings["index.number_of_replicas"] != "0" {
+		t.Errorf("Expected the copy not to share Settings, got %v", original.Spec.Settings)
+	}
+	if original.Spec.Parameters["read_poll_timeout"] != "1m" {
+		t.Errorf("Expected the copy not to share Parameters, got %v", original.Spec.Parameters)
+	}
+}
+
+func TestAutoFollowPatternDeepCopy(t *testing.T) {
+	original := &AutoFollowPattern{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+		Spec: AutoFollowPatternSpec{
+			RemoteCluster:                "leader",
+			LeaderIndexPatterns:          []string{"logs-*"},
+			LeaderIndexExclusionPatterns: []string{"logs-debug-*"},
+		},
+	}
+
+	copied := original.DeepCopy()
+	copied.Spec.LeaderIndexPatterns[0] = "metrics-*"
+	copied.Spec.LeaderIndexExclusionPatterns[0] = "metrics-debug-*"
+
+	if original.Spec.LeaderIndexPatterns[0] != "logs-*" {
+		t.Errorf("Expected the copy not to share LeaderIndexPatterns, got %v", original.Spec.LeaderIndexPatterns)
+	}
+	if original.Spec.LeaderIndexExclusionPatterns[0] != "logs-debug-*" {
+		t.Errorf("Expected the copy not to share LeaderIndexExclusionPatterns, got %v", original.Spec.LeaderIndexExclusionPatterns)
+	}
+}
+
+func TestElasticsearchRawRequestDeletePath(t *testing.T) {
+	none := ""
+	rawRequest := &ElasticsearchRawRequest{
+		Spec: ElasticsearchRawRequestSpec{Path: "/_security/role_mapping/admins?refresh=true"},
+	}
+
+	if got := rawRequest.Spec.GetDeletePath(); got != rawRequest.Spec.Path {
+		t.Errorf("Expected the DELETE request to default to the path, got %q", got)
+	}
+
+	copied := rawRequest.DeepCopy()
+	copied.Spec.DeletePath = &none
+	if got := copied.Spec.GetDeletePath(); got != "" {
+		t.Errorf("Expected an empty deletePath to send no DELETE request, got %q", got)
+	}
+	if rawRequest.Spec.DeletePath != nil {
+		t.Errorf("Expected the copy not to share DeletePath, got %q", *rawRequest.Spec.DeletePath)
+	}
+}
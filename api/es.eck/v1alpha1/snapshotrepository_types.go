@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -25,7 +26,24 @@ type SnapshotRepositorySpec struct {
 	// +optional
 	TargetConfig CommonElasticsearchConfig `json:"targetInstance,omitempty"`
 
-	Body string `json:"body"`
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// BodyJSON is the body as structured JSON instead of a string. It is stored as it is and printed readable by
+	// kubectl. Only one of body and bodyJson may be set.
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	BodyJSON *apiextensionsv1.JSON `json:"bodyJson,omitempty"`
+
+	// Readonly registers the repository with settings.readonly set to true on the target instance
+	// +optional
+	Readonly bool `json:"readonly,omitempty"`
+
+	// ReadonlyReplicas are additional Elasticsearch instances on which the same repository is
+	// registered as readonly, e.g. to restore snapshots written by the target instance
+	// +optional
+	ReadonlyReplicas []CommonElasticsearchConfig `json:"readonlyReplicas,omitempty"`
 }
 
 // SnapshotRepositoryStatus defines the observed state of SnapshotRepository
@@ -35,6 +53,17 @@ type SnapshotRepositoryStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// ReadonlyReplicas the repository is currently registered on
+	// +optional
+	ReadonlyReplicas []CommonElasticsearchConfig `json:"readonlyReplicas,omitempty"`
+	// Failover records the instance of each failover pair holding the object
+	// +listType=map
+	// +listMapKey=primary
+	// +optional
+	Failover []FailoverStatus `json:"failover,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -0,0 +1,145 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SnapshotRestore phases
+const (
+	SnapshotRestorePhaseRunning   = "Running"
+	SnapshotRestorePhaseCompleted = "Completed"
+	SnapshotRestorePhaseFailed    = "Failed"
+)
+
+// SnapshotRestoreSpec defines the desired state of SnapshotRestore
+type SnapshotRestoreSpec struct {
+	// +optional
+	TargetConfig CommonElasticsearchConfig `json:"targetInstance,omitempty"`
+
+	// Repository the snapshot is restored from
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Repository string `json:"repository"`
+
+	// Snapshot to restore
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Snapshot string `json:"snapshot"`
+
+	// Indices and data streams to restore, supporting wildcards. All of the snapshot are restored if not set.
+	// +optional
+	Indices []string `json:"indices,omitempty"`
+
+	// RenamePattern is a regular expression matched against the names of the restored indices, e.g. "(.+)"
+	// +optional
+	RenamePattern string `json:"renamePattern,omitempty"`
+
+	// RenameReplacement of the names matching RenamePattern, referring to its groups, e.g. "restored-$1"
+	// +optional
+	RenameReplacement string `json:"renameReplacement,omitempty"`
+
+	// IndexSettings override the settings of the restored indices, e.g. index.number_of_replicas
+	// +optional
+	IndexSettings map[string]string `json:"indexSettings,omitempty"`
+
+	// IgnoreIndexSettings are settings of the snapshot which are not restored
+	// +optional
+	IgnoreIndexSettings []string `json:"ignoreIndexSettings,omitempty"`
+
+	// IncludeGlobalState restores the cluster state of the snapshot, e.g. templates and persistent settings
+	// +optional
+	IncludeGlobalState bool `json:"includeGlobalState,omitempty"`
+
+	// Partial restores the available shards of indices with unavailable shards in the snapshot
+	// +optional
+	Partial bool `json:"partial,omitempty"`
+
+	// RestoreGeneration requests another restore when it is increased above the restoreGeneration of the status.
+	// A snapshot is restored only once per generation, other changes of the spec do not restore it again.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RestoreGeneration int64 `json:"restoreGeneration,omitempty"`
+}
+
+// SnapshotRestoreStatus defines the observed state of SnapshotRestore
+type SnapshotRestoreStatus struct {
+	// RestoreGeneration of the last restore
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	RestoreGeneration int64 `json:"restoreGeneration,omitempty"`
+	// Phase of the last restore, one of Running, Completed or Failed
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// StartTime is when the last restore was started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is when the recovery of all shards of the last restore was done
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Indices restored by the last restore, as reported by the recovery API
+	// +optional
+	Indices []string `json:"indices,omitempty"`
+	// TotalShards being recovered from the snapshot
+	// +optional
+	TotalShards int32 `json:"totalShards,omitempty"`
+	// RecoveredShards of TotalShards whose recovery is done
+	// +optional
+	RecoveredShards int32 `json:"recoveredShards,omitempty"`
+	// Message is why the last restore failed
+	// +optional
+	Message string `json:"message,omitempty"`
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// Failover records the instance of each failover pair holding the object
+	// +listType=map
+	// +listMapKey=primary
+	// +optional
+	Failover []FailoverStatus `json:"failover,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// SnapshotRestore is the Schema for the snapshotrestores API
+type SnapshotRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SnapshotRestoreSpec   `json:"spec,omitempty"`
+	Status SnapshotRestoreStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SnapshotRestoreList contains a list of SnapshotRestore
+type SnapshotRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SnapshotRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SnapshotRestore{}, &SnapshotRestoreList{})
+}
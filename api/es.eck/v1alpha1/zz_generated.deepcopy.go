@@ -27,41 +27,42 @@ import (
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CommonElasticsearchConfig) DeepCopyInto(out *CommonElasticsearchConfig) {
+func (in *ApikeyConsumers) DeepCopyInto(out *ApikeyConsumers) {
 	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonElasticsearchConfig.
-func (in *CommonElasticsearchConfig) DeepCopy() *CommonElasticsearchConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApikeyConsumers.
+func (in *ApikeyConsumers) DeepCopy() *ApikeyConsumers {
 	if in == nil {
 		return nil
 	}
-	out := new(CommonElasticsearchConfig)
+	out := new(ApikeyConsumers)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ComponentTemplate) DeepCopyInto(out *ComponentTemplate) {
+func (in *AutoFollowPattern) DeepCopyInto(out *AutoFollowPattern) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentTemplate.
-func (in *ComponentTemplate) DeepCopy() *ComponentTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoFollowPattern.
+func (in *AutoFollowPattern) DeepCopy() *AutoFollowPattern {
 	if in == nil {
 		return nil
 	}
-	out := new(ComponentTemplate)
+	out := new(AutoFollowPattern)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ComponentTemplate) DeepCopyObject() runtime.Object {
+func (in *AutoFollowPattern) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -69,31 +70,31 @@ func (in *ComponentTemplate) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ComponentTemplateList) DeepCopyInto(out *ComponentTemplateList) {
+func (in *AutoFollowPatternList) DeepCopyInto(out *AutoFollowPatternList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ComponentTemplate, len(*in))
+		*out = make([]AutoFollowPattern, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentTemplateList.
-func (in *ComponentTemplateList) DeepCopy() *ComponentTemplateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoFollowPatternList.
+func (in *AutoFollowPatternList) DeepCopy() *AutoFollowPatternList {
 	if in == nil {
 		return nil
 	}
-	out := new(ComponentTemplateList)
+	out := new(AutoFollowPatternList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ComponentTemplateList) DeepCopyObject() runtime.Object {
+func (in *AutoFollowPatternList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -101,88 +102,95 @@ func (in *ComponentTemplateList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ComponentTemplateSpec) DeepCopyInto(out *ComponentTemplateSpec) {
+func (in *AutoFollowPatternSpec) DeepCopyInto(out *AutoFollowPatternSpec) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
-	in.Dependencies.DeepCopyInto(&out.Dependencies)
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentTemplateSpec.
-func (in *ComponentTemplateSpec) DeepCopy() *ComponentTemplateSpec {
-	if in == nil {
-		return nil
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.LeaderIndexPatterns != nil {
+		in, out := &in.LeaderIndexPatterns, &out.LeaderIndexPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LeaderIndexExclusionPatterns != nil {
+		in, out := &in.LeaderIndexExclusionPatterns, &out.LeaderIndexExclusionPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	out := new(ComponentTemplateSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ComponentTemplateStatus) DeepCopyInto(out *ComponentTemplateStatus) {
-	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentTemplateStatus.
-func (in *ComponentTemplateStatus) DeepCopy() *ComponentTemplateStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoFollowPatternSpec.
+func (in *AutoFollowPatternSpec) DeepCopy() *AutoFollowPatternSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ComponentTemplateStatus)
+	out := new(AutoFollowPatternSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Dependencies) DeepCopyInto(out *Dependencies) {
+func (in *AutoFollowPatternStatus) DeepCopyInto(out *AutoFollowPatternStatus) {
 	*out = *in
-	if in.IndexTemplates != nil {
-		in, out := &in.IndexTemplates, &out.IndexTemplates
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.ComponentTemplates != nil {
-		in, out := &in.ComponentTemplates, &out.ComponentTemplates
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.Indices != nil {
-		in, out := &in.Indices, &out.Indices
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dependencies.
-func (in *Dependencies) DeepCopy() *Dependencies {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoFollowPatternStatus.
+func (in *AutoFollowPatternStatus) DeepCopy() *AutoFollowPatternStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Dependencies)
+	out := new(AutoFollowPatternStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchApikey) DeepCopyInto(out *ElasticsearchApikey) {
+func (in *ClusterElasticsearchInstance) DeepCopyInto(out *ClusterElasticsearchInstance) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	in.Status.DeepCopyInto(&out.Status)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchApikey.
-func (in *ElasticsearchApikey) DeepCopy() *ElasticsearchApikey {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterElasticsearchInstance.
+func (in *ClusterElasticsearchInstance) DeepCopy() *ClusterElasticsearchInstance {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchApikey)
+	out := new(ClusterElasticsearchInstance)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ElasticsearchApikey) DeepCopyObject() runtime.Object {
+func (in *ClusterElasticsearchInstance) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -190,31 +198,31 @@ func (in *ElasticsearchApikey) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchApikeyList) DeepCopyInto(out *ElasticsearchApikeyList) {
+func (in *ClusterElasticsearchInstanceList) DeepCopyInto(out *ClusterElasticsearchInstanceList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ElasticsearchApikey, len(*in))
+		*out = make([]ClusterElasticsearchInstance, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchApikeyList.
-func (in *ElasticsearchApikeyList) DeepCopy() *ElasticsearchApikeyList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterElasticsearchInstanceList.
+func (in *ClusterElasticsearchInstanceList) DeepCopy() *ClusterElasticsearchInstanceList {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchApikeyList)
+	out := new(ClusterElasticsearchInstanceList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ElasticsearchApikeyList) DeepCopyObject() runtime.Object {
+func (in *ClusterElasticsearchInstanceList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -222,64 +230,66 @@ func (in *ElasticsearchApikeyList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchApikeySpec) DeepCopyInto(out *ElasticsearchApikeySpec) {
+func (in *ClusterElasticsearchInstanceStatus) DeepCopyInto(out *ClusterElasticsearchInstanceStatus) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchApikeySpec.
-func (in *ElasticsearchApikeySpec) DeepCopy() *ElasticsearchApikeySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterElasticsearchInstanceStatus.
+func (in *ClusterElasticsearchInstanceStatus) DeepCopy() *ClusterElasticsearchInstanceStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchApikeySpec)
+	out := new(ClusterElasticsearchInstanceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchApikeyStatus) DeepCopyInto(out *ElasticsearchApikeyStatus) {
+func (in *CommonElasticsearchConfig) DeepCopyInto(out *CommonElasticsearchConfig) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = new(ElasticsearchFailover)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchApikeyStatus.
-func (in *ElasticsearchApikeyStatus) DeepCopy() *ElasticsearchApikeyStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonElasticsearchConfig.
+func (in *CommonElasticsearchConfig) DeepCopy() *CommonElasticsearchConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchApikeyStatus)
+	out := new(CommonElasticsearchConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchInstance) DeepCopyInto(out *ElasticsearchInstance) {
+func (in *ComponentTemplate) DeepCopyInto(out *ComponentTemplate) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchInstance.
-func (in *ElasticsearchInstance) DeepCopy() *ElasticsearchInstance {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentTemplate.
+func (in *ComponentTemplate) DeepCopy() *ComponentTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchInstance)
+	out := new(ComponentTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ElasticsearchInstance) DeepCopyObject() runtime.Object {
+func (in *ComponentTemplate) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -287,31 +297,31 @@ func (in *ElasticsearchInstance) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchInstanceList) DeepCopyInto(out *ElasticsearchInstanceList) {
+func (in *ComponentTemplateList) DeepCopyInto(out *ComponentTemplateList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ElasticsearchInstance, len(*in))
+		*out = make([]ComponentTemplate, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchInstanceList.
-func (in *ElasticsearchInstanceList) DeepCopy() *ElasticsearchInstanceList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentTemplateList.
+func (in *ComponentTemplateList) DeepCopy() *ComponentTemplateList {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchInstanceList)
+	out := new(ComponentTemplateList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ElasticsearchInstanceList) DeepCopyObject() runtime.Object {
+func (in *ComponentTemplateList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -319,41 +329,77 @@ func (in *ElasticsearchInstanceList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchInstanceStatus) DeepCopyInto(out *ElasticsearchInstanceStatus) {
+func (in *ComponentTemplateSpec) DeepCopyInto(out *ComponentTemplateSpec) {
 	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	in.Dependencies.DeepCopyInto(&out.Dependencies)
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchInstanceStatus.
-func (in *ElasticsearchInstanceStatus) DeepCopy() *ElasticsearchInstanceStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentTemplateSpec.
+func (in *ComponentTemplateSpec) DeepCopy() *ComponentTemplateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchInstanceStatus)
+	out := new(ComponentTemplateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchRole) DeepCopyInto(out *ElasticsearchRole) {
+func (in *ComponentTemplateStatus) DeepCopyInto(out *ComponentTemplateStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentTemplateStatus.
+func (in *ComponentTemplateStatus) DeepCopy() *ComponentTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionTest) DeepCopyInto(out *ConnectionTest) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRole.
-func (in *ElasticsearchRole) DeepCopy() *ElasticsearchRole {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionTest.
+func (in *ConnectionTest) DeepCopy() *ConnectionTest {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchRole)
+	out := new(ConnectionTest)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ElasticsearchRole) DeepCopyObject() runtime.Object {
+func (in *ConnectionTest) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -361,31 +407,46 @@ func (in *ElasticsearchRole) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchRoleList) DeepCopyInto(out *ElasticsearchRoleList) {
+func (in *ConnectionTestCheck) DeepCopyInto(out *ConnectionTestCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionTestCheck.
+func (in *ConnectionTestCheck) DeepCopy() *ConnectionTestCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionTestCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionTestList) DeepCopyInto(out *ConnectionTestList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ElasticsearchRole, len(*in))
+		*out = make([]ConnectionTest, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRoleList.
-func (in *ElasticsearchRoleList) DeepCopy() *ElasticsearchRoleList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionTestList.
+func (in *ConnectionTestList) DeepCopy() *ConnectionTestList {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchRoleList)
+	out := new(ConnectionTestList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ElasticsearchRoleList) DeepCopyObject() runtime.Object {
+func (in *ConnectionTestList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -393,24 +454,43 @@ func (in *ElasticsearchRoleList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchRoleSpec) DeepCopyInto(out *ElasticsearchRoleSpec) {
+func (in *ConnectionTestSpec) DeepCopyInto(out *ConnectionTestSpec) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
+	out.TargetInstance = in.TargetInstance
+	if in.ClusterPrivileges != nil {
+		in, out := &in.ClusterPrivileges, &out.ClusterPrivileges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRoleSpec.
-func (in *ElasticsearchRoleSpec) DeepCopy() *ElasticsearchRoleSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionTestSpec.
+func (in *ConnectionTestSpec) DeepCopy() *ConnectionTestSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchRoleSpec)
+	out := new(ConnectionTestSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchRoleStatus) DeepCopyInto(out *ElasticsearchRoleStatus) {
+func (in *ConnectionTestStatus) DeepCopyInto(out *ConnectionTestStatus) {
 	*out = *in
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make([]ConnectionTestCheck, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastTestTime != nil {
+		in, out := &in.LastTestTime, &out.LastTestTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -420,37 +500,82 @@ func (in *ElasticsearchRoleStatus) DeepCopyInto(out *ElasticsearchRoleStatus) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRoleStatus.
-func (in *ElasticsearchRoleStatus) DeepCopy() *ElasticsearchRoleStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionTestStatus.
+func (in *ConnectionTestStatus) DeepCopy() *ConnectionTestStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchRoleStatus)
+	out := new(ConnectionTestStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchUser) DeepCopyInto(out *ElasticsearchUser) {
+func (in *ConnectionTestTargetInstance) DeepCopyInto(out *ConnectionTestTargetInstance) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionTestTargetInstance.
+func (in *ConnectionTestTargetInstance) DeepCopy() *ConnectionTestTargetInstance {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionTestTargetInstance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Dependencies) DeepCopyInto(out *Dependencies) {
+	*out = *in
+	if in.IndexTemplates != nil {
+		in, out := &in.IndexTemplates, &out.IndexTemplates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ComponentTemplates != nil {
+		in, out := &in.ComponentTemplates, &out.ComponentTemplates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Indices != nil {
+		in, out := &in.Indices, &out.Indices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dependencies.
+func (in *Dependencies) DeepCopy() *Dependencies {
+	if in == nil {
+		return nil
+	}
+	out := new(Dependencies)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EckResourceQuota) DeepCopyInto(out *EckResourceQuota) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchUser.
-func (in *ElasticsearchUser) DeepCopy() *ElasticsearchUser {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EckResourceQuota.
+func (in *EckResourceQuota) DeepCopy() *EckResourceQuota {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchUser)
+	out := new(EckResourceQuota)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ElasticsearchUser) DeepCopyObject() runtime.Object {
+func (in *EckResourceQuota) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -458,31 +583,31 @@ func (in *ElasticsearchUser) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchUserList) DeepCopyInto(out *ElasticsearchUserList) {
+func (in *EckResourceQuotaList) DeepCopyInto(out *EckResourceQuotaList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ElasticsearchUser, len(*in))
+		*out = make([]EckResourceQuota, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchUserList.
-func (in *ElasticsearchUserList) DeepCopy() *ElasticsearchUserList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EckResourceQuotaList.
+func (in *EckResourceQuotaList) DeepCopy() *EckResourceQuotaList {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchUserList)
+	out := new(EckResourceQuotaList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ElasticsearchUserList) DeepCopyObject() runtime.Object {
+func (in *EckResourceQuotaList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -490,24 +615,44 @@ func (in *ElasticsearchUserList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchUserSpec) DeepCopyInto(out *ElasticsearchUserSpec) {
+func (in *EckResourceQuotaSpec) DeepCopyInto(out *EckResourceQuotaSpec) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
+	if in.MaxIndices != nil {
+		in, out := &in.MaxIndices, &out.MaxIndices
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxIngestPipelines != nil {
+		in, out := &in.MaxIngestPipelines, &out.MaxIngestPipelines
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxAPIKeys != nil {
+		in, out := &in.MaxAPIKeys, &out.MaxAPIKeys
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxSavedObjects != nil {
+		in, out := &in.MaxSavedObjects, &out.MaxSavedObjects
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchUserSpec.
-func (in *ElasticsearchUserSpec) DeepCopy() *ElasticsearchUserSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EckResourceQuotaSpec.
+func (in *EckResourceQuotaSpec) DeepCopy() *EckResourceQuotaSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchUserSpec)
+	out := new(EckResourceQuotaSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchUserStatus) DeepCopyInto(out *ElasticsearchUserStatus) {
+func (in *EckResourceQuotaStatus) DeepCopyInto(out *EckResourceQuotaStatus) {
 	*out = *in
+	out.Used = in.Used
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -517,37 +662,52 @@ func (in *ElasticsearchUserStatus) DeepCopyInto(out *ElasticsearchUserStatus) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchUserStatus.
-func (in *ElasticsearchUserStatus) DeepCopy() *ElasticsearchUserStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EckResourceQuotaStatus.
+func (in *EckResourceQuotaStatus) DeepCopy() *EckResourceQuotaStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchUserStatus)
+	out := new(EckResourceQuotaStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Index) DeepCopyInto(out *Index) {
+func (in *EckResourceQuotaUsage) DeepCopyInto(out *EckResourceQuotaUsage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EckResourceQuotaUsage.
+func (in *EckResourceQuotaUsage) DeepCopy() *EckResourceQuotaUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(EckResourceQuotaUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchApikey) DeepCopyInto(out *ElasticsearchApikey) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Index.
-func (in *Index) DeepCopy() *Index {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchApikey.
+func (in *ElasticsearchApikey) DeepCopy() *ElasticsearchApikey {
 	if in == nil {
 		return nil
 	}
-	out := new(Index)
+	out := new(ElasticsearchApikey)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Index) DeepCopyObject() runtime.Object {
+func (in *ElasticsearchApikey) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -555,26 +715,164 @@ func (in *Index) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IndexLifecyclePolicy) DeepCopyInto(out *IndexLifecyclePolicy) {
+func (in *ElasticsearchApikeyList) DeepCopyInto(out *ElasticsearchApikeyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ElasticsearchApikey, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchApikeyList.
+func (in *ElasticsearchApikeyList) DeepCopy() *ElasticsearchApikeyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchApikeyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticsearchApikeyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchApikeySpec) DeepCopyInto(out *ElasticsearchApikeySpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretTemplate != nil {
+		in, out := &in.SecretTemplate, &out.SecretTemplate
+		*out = new(SecretTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretFormats != nil {
+		in, out := &in.SecretFormats, &out.SecretFormats
+		*out = make([]ApikeySecretFormat, len(*in))
+		copy(*out, *in)
+	}
+	if in.Consumers != nil {
+		in, out := &in.Consumers, &out.Consumers
+		*out = new(ApikeyConsumers)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchApikeySpec.
+func (in *ElasticsearchApikeySpec) DeepCopy() *ElasticsearchApikeySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchApikeySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchApikeyStatus) DeepCopyInto(out *ElasticsearchApikeyStatus) {
+	*out = *in
+	if in.Expiration != nil {
+		in, out := &in.Expiration, &out.Expiration
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Consumers != nil {
+		in, out := &in.Consumers, &out.Consumers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchApikeyStatus.
+func (in *ElasticsearchApikeyStatus) DeepCopy() *ElasticsearchApikeyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchApikeyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchFailover) DeepCopyInto(out *ElasticsearchFailover) {
+	*out = *in
+	out.Secondary = in.Secondary
+	out.After = in.After
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchFailover.
+func (in *ElasticsearchFailover) DeepCopy() *ElasticsearchFailover {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchFailover)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchFailoverInstance) DeepCopyInto(out *ElasticsearchFailoverInstance) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchFailoverInstance.
+func (in *ElasticsearchFailoverInstance) DeepCopy() *ElasticsearchFailoverInstance {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchFailoverInstance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchInstance) DeepCopyInto(out *ElasticsearchInstance) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	in.Status.DeepCopyInto(&out.Status)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexLifecyclePolicy.
-func (in *IndexLifecyclePolicy) DeepCopy() *IndexLifecyclePolicy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchInstance.
+func (in *ElasticsearchInstance) DeepCopy() *ElasticsearchInstance {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexLifecyclePolicy)
+	out := new(ElasticsearchInstance)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IndexLifecyclePolicy) DeepCopyObject() runtime.Object {
+func (in *ElasticsearchInstance) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -582,31 +880,31 @@ func (in *IndexLifecyclePolicy) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IndexLifecyclePolicyList) DeepCopyInto(out *IndexLifecyclePolicyList) {
+func (in *ElasticsearchInstanceList) DeepCopyInto(out *ElasticsearchInstanceList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]IndexLifecyclePolicy, len(*in))
+		*out = make([]ElasticsearchInstance, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexLifecyclePolicyList.
-func (in *IndexLifecyclePolicyList) DeepCopy() *IndexLifecyclePolicyList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchInstanceList.
+func (in *ElasticsearchInstanceList) DeepCopy() *ElasticsearchInstanceList {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexLifecyclePolicyList)
+	out := new(ElasticsearchInstanceList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IndexLifecyclePolicyList) DeepCopyObject() runtime.Object {
+func (in *ElasticsearchInstanceList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -614,69 +912,73 @@ func (in *IndexLifecyclePolicyList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IndexLifecyclePolicySpec) DeepCopyInto(out *IndexLifecyclePolicySpec) {
+func (in *ElasticsearchInstanceStatus) DeepCopyInto(out *ElasticsearchInstanceStatus) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexLifecyclePolicySpec.
-func (in *IndexLifecyclePolicySpec) DeepCopy() *IndexLifecyclePolicySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchInstanceStatus.
+func (in *ElasticsearchInstanceStatus) DeepCopy() *ElasticsearchInstanceStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexLifecyclePolicySpec)
+	out := new(ElasticsearchInstanceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IndexLifecyclePolicyStatus) DeepCopyInto(out *IndexLifecyclePolicyStatus) {
+func (in *ElasticsearchRawRequest) DeepCopyInto(out *ElasticsearchRawRequest) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexLifecyclePolicyStatus.
-func (in *IndexLifecyclePolicyStatus) DeepCopy() *IndexLifecyclePolicyStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRawRequest.
+func (in *ElasticsearchRawRequest) DeepCopy() *ElasticsearchRawRequest {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexLifecyclePolicyStatus)
+	out := new(ElasticsearchRawRequest)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticsearchRawRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IndexList) DeepCopyInto(out *IndexList) {
+func (in *ElasticsearchRawRequestList) DeepCopyInto(out *ElasticsearchRawRequestList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Index, len(*in))
+		*out = make([]ElasticsearchRawRequest, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexList.
-func (in *IndexList) DeepCopy() *IndexList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRawRequestList.
+func (in *ElasticsearchRawRequestList) DeepCopy() *ElasticsearchRawRequestList {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexList)
+	out := new(ElasticsearchRawRequestList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IndexList) DeepCopyObject() runtime.Object {
+func (in *ElasticsearchRawRequestList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -684,39 +986,57 @@ func (in *IndexList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IndexSpec) DeepCopyInto(out *IndexSpec) {
+func (in *ElasticsearchRawRequestSpec) DeepCopyInto(out *ElasticsearchRawRequestSpec) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
-	in.Dependencies.DeepCopyInto(&out.Dependencies)
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.DeletePath != nil {
+		in, out := &in.DeletePath, &out.DeletePath
+		*out = new(string)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexSpec.
-func (in *IndexSpec) DeepCopy() *IndexSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRawRequestSpec.
+func (in *ElasticsearchRawRequestSpec) DeepCopy() *ElasticsearchRawRequestSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexSpec)
+	out := new(ElasticsearchRawRequestSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IndexStatus) DeepCopyInto(out *IndexStatus) {
+func (in *ElasticsearchRawRequestStatus) DeepCopyInto(out *ElasticsearchRawRequestStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexStatus.
-func (in *IndexStatus) DeepCopy() *IndexStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRawRequestStatus.
+func (in *ElasticsearchRawRequestStatus) DeepCopy() *ElasticsearchRawRequestStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexStatus)
+	out := new(ElasticsearchRawRequestStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IndexTemplate) DeepCopyInto(out *IndexTemplate) {
+func (in *ElasticsearchRole) DeepCopyInto(out *ElasticsearchRole) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -724,18 +1044,1556 @@ func (in *IndexTemplate) DeepCopyInto(out *IndexTemplate) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexTemplate.
-func (in *IndexTemplate) DeepCopy() *IndexTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRole.
+func (in *ElasticsearchRole) DeepCopy() *ElasticsearchRole {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexTemplate)
+	out := new(ElasticsearchRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticsearchRole) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchRoleList) DeepCopyInto(out *ElasticsearchRoleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ElasticsearchRole, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRoleList.
+func (in *ElasticsearchRoleList) DeepCopy() *ElasticsearchRoleList {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchRoleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticsearchRoleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchRoleSpec) DeepCopyInto(out *ElasticsearchRoleSpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Template.DeepCopyInto(&out.Template)
+	if in.KibanaPrivileges != nil {
+		in, out := &in.KibanaPrivileges, &out.KibanaPrivileges
+		*out = make([]KibanaPrivilege, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRoleSpec.
+func (in *ElasticsearchRoleSpec) DeepCopy() *ElasticsearchRoleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchRoleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchRoleStatus) DeepCopyInto(out *ElasticsearchRoleStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		*out = new(SecurityAdoption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRoleStatus.
+func (in *ElasticsearchRoleStatus) DeepCopy() *ElasticsearchRoleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchRoleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchUser) DeepCopyInto(out *ElasticsearchUser) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchUser.
+func (in *ElasticsearchUser) DeepCopy() *ElasticsearchUser {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticsearchUser) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchUserList) DeepCopyInto(out *ElasticsearchUserList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ElasticsearchUser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchUserList.
+func (in *ElasticsearchUserList) DeepCopy() *ElasticsearchUserList {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchUserList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticsearchUserList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchUserSpec) DeepCopyInto(out *ElasticsearchUserSpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]apiextensionsv1.JSON, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.SecretTemplate != nil {
+		in, out := &in.SecretTemplate, &out.SecretTemplate
+		*out = new(UserSecretTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchUserSpec.
+func (in *ElasticsearchUserSpec) DeepCopy() *ElasticsearchUserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchUserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchUserStatus) DeepCopyInto(out *ElasticsearchUserStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		*out = new(SecurityAdoption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchUserStatus.
+func (in *ElasticsearchUserStatus) DeepCopy() *ElasticsearchUserStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchUserStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvironmentOverlay) DeepCopyInto(out *EnvironmentOverlay) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvironmentOverlay.
+func (in *EnvironmentOverlay) DeepCopy() *EnvironmentOverlay {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvironmentOverlay)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EnvironmentOverlay) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvironmentOverlayList) DeepCopyInto(out *EnvironmentOverlayList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EnvironmentOverlay, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvironmentOverlayList.
+func (in *EnvironmentOverlayList) DeepCopy() *EnvironmentOverlayList {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvironmentOverlayList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EnvironmentOverlayList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvironmentOverlaySpec) DeepCopyInto(out *EnvironmentOverlaySpec) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvironmentOverlaySpec.
+func (in *EnvironmentOverlaySpec) DeepCopy() *EnvironmentOverlaySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvironmentOverlaySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvironmentOverlayStatus) DeepCopyInto(out *EnvironmentOverlayStatus) {
+	*out = *in
+	if in.Selected != nil {
+		in, out := &in.Selected, &out.Selected
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvironmentOverlayStatus.
+func (in *EnvironmentOverlayStatus) DeepCopy() *EnvironmentOverlayStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvironmentOverlayStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailoverStatus) DeepCopyInto(out *FailoverStatus) {
+	*out = *in
+	if in.Secondary != nil {
+		in, out := &in.Secondary, &out.Secondary
+		*out = new(ElasticsearchFailoverInstance)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailoverStatus.
+func (in *FailoverStatus) DeepCopy() *FailoverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FailoverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FollowerIndex) DeepCopyInto(out *FollowerIndex) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FollowerIndex.
+func (in *FollowerIndex) DeepCopy() *FollowerIndex {
+	if in == nil {
+		return nil
+	}
+	out := new(FollowerIndex)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FollowerIndex) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FollowerIndexList) DeepCopyInto(out *FollowerIndexList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FollowerIndex, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FollowerIndexList.
+func (in *FollowerIndexList) DeepCopy() *FollowerIndexList {
+	if in == nil {
+		return nil
+	}
+	out := new(FollowerIndexList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FollowerIndexList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FollowerIndexSpec) DeepCopyInto(out *FollowerIndexSpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FollowerIndexSpec.
+func (in *FollowerIndexSpec) DeepCopy() *FollowerIndexSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FollowerIndexSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FollowerIndexStatus) DeepCopyInto(out *FollowerIndexStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FollowerIndexStatus.
+func (in *FollowerIndexStatus) DeepCopy() *FollowerIndexStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FollowerIndexStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Index) DeepCopyInto(out *Index) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Index.
+func (in *Index) DeepCopy() *Index {
+	if in == nil {
+		return nil
+	}
+	out := new(Index)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Index) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexAlias) DeepCopyInto(out *IndexAlias) {
+	*out = *in
+	if in.IsWriteIndex != nil {
+		in, out := &in.IsWriteIndex, &out.IsWriteIndex
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MoveFrom != nil {
+		in, out := &in.MoveFrom, &out.MoveFrom
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexAlias.
+func (in *IndexAlias) DeepCopy() *IndexAlias {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexAlias)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexBlocks) DeepCopyInto(out *IndexBlocks) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexBlocks.
+func (in *IndexBlocks) DeepCopy() *IndexBlocks {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexBlocks)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexLifecyclePolicy) DeepCopyInto(out *IndexLifecyclePolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexLifecyclePolicy.
+func (in *IndexLifecyclePolicy) DeepCopy() *IndexLifecyclePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexLifecyclePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IndexLifecyclePolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexLifecyclePolicyList) DeepCopyInto(out *IndexLifecyclePolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IndexLifecyclePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexLifecyclePolicyList.
+func (in *IndexLifecyclePolicyList) DeepCopy() *IndexLifecyclePolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexLifecyclePolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IndexLifecyclePolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexLifecyclePolicyReference) DeepCopyInto(out *IndexLifecyclePolicyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexLifecyclePolicyReference.
+func (in *IndexLifecyclePolicyReference) DeepCopy() *IndexLifecyclePolicyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexLifecyclePolicyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexLifecyclePolicyRollout) DeepCopyInto(out *IndexLifecyclePolicyRollout) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexLifecyclePolicyRollout.
+func (in *IndexLifecyclePolicyRollout) DeepCopy() *IndexLifecyclePolicyRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexLifecyclePolicyRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexLifecyclePolicySpec) DeepCopyInto(out *IndexLifecyclePolicySpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SnapshotBeforeDelete != nil {
+		in, out := &in.SnapshotBeforeDelete, &out.SnapshotBeforeDelete
+		*out = new(SnapshotBeforeDeleteSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexLifecyclePolicySpec.
+func (in *IndexLifecyclePolicySpec) DeepCopy() *IndexLifecyclePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexLifecyclePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexLifecyclePolicyStatus) DeepCopyInto(out *IndexLifecyclePolicyStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(IndexLifecyclePolicyRollout)
+		**out = **in
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexLifecyclePolicyStatus.
+func (in *IndexLifecyclePolicyStatus) DeepCopy() *IndexLifecyclePolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexLifecyclePolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexList) DeepCopyInto(out *IndexList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Index, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexList.
+func (in *IndexList) DeepCopy() *IndexList {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IndexList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexOperation) DeepCopyInto(out *IndexOperation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexOperation.
+func (in *IndexOperation) DeepCopy() *IndexOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IndexOperation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexOperationList) DeepCopyInto(out *IndexOperationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IndexOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexOperationList.
+func (in *IndexOperationList) DeepCopy() *IndexOperationList {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexOperationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IndexOperationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexOperationSpec) DeepCopyInto(out *IndexOperationSpec) {
+	*out = *in
+	if in.NumberOfShards != nil {
+		in, out := &in.NumberOfShards, &out.NumberOfShards
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexOperationSpec.
+func (in *IndexOperationSpec) DeepCopy() *IndexOperationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexOperationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexOperationStatus) DeepCopyInto(out *IndexOperationStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexOperationStatus.
+func (in *IndexOperationStatus) DeepCopy() *IndexOperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexOperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexSpec) DeepCopyInto(out *IndexSpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	in.Dependencies.DeepCopyInto(&out.Dependencies)
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ILMPolicyRef != nil {
+		in, out := &in.ILMPolicyRef, &out.ILMPolicyRef
+		*out = new(IndexLifecyclePolicyReference)
+		**out = **in
+	}
+	if in.FieldAliases != nil {
+		in, out := &in.FieldAliases, &out.FieldAliases
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Blocks != nil {
+		in, out := &in.Blocks, &out.Blocks
+		*out = new(IndexBlocks)
+		**out = **in
+	}
+	if in.Aliases != nil {
+		in, out := &in.Aliases, &out.Aliases
+		*out = make([]IndexAlias, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexSpec.
+func (in *IndexSpec) DeepCopy() *IndexSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexStatus) DeepCopyInto(out *IndexStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ManagedAliases != nil {
+		in, out := &in.ManagedAliases, &out.ManagedAliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedRuntimeFields != nil {
+		in, out := &in.ManagedRuntimeFields, &out.ManagedRuntimeFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexStatus.
+func (in *IndexStatus) DeepCopy() *IndexStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexTemplate) DeepCopyInto(out *IndexTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexTemplate.
+func (in *IndexTemplate) DeepCopy() *IndexTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IndexTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexTemplateCanary) DeepCopyInto(out *IndexTemplateCanary) {
+	*out = *in
+	if in.Documents != nil {
+		in, out := &in.Documents, &out.Documents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Queries != nil {
+		in, out := &in.Queries, &out.Queries
+		*out = make([]IndexTemplateCanaryQuery, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexTemplateCanary.
+func (in *IndexTemplateCanary) DeepCopy() *IndexTemplateCanary {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexTemplateCanary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexTemplateCanaryQuery) DeepCopyInto(out *IndexTemplateCanaryQuery) {
+	*out = *in
+	if in.ExpectedHits != nil {
+		in, out := &in.ExpectedHits, &out.ExpectedHits
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexTemplateCanaryQuery.
+func (in *IndexTemplateCanaryQuery) DeepCopy() *IndexTemplateCanaryQuery {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexTemplateCanaryQuery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexTemplateCanaryResult) DeepCopyInto(out *IndexTemplateCanaryResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexTemplateCanaryResult.
+func (in *IndexTemplateCanaryResult) DeepCopy() *IndexTemplateCanaryResult {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexTemplateCanaryResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexTemplateCanaryStatus) DeepCopyInto(out *IndexTemplateCanaryStatus) {
+	*out = *in
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]IndexTemplateCanaryResult, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexTemplateCanaryStatus.
+func (in *IndexTemplateCanaryStatus) DeepCopy() *IndexTemplateCanaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexTemplateCanaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexTemplateDeletionImpact) DeepCopyInto(out *IndexTemplateDeletionImpact) {
+	*out = *in
+	if in.UncoveredPatterns != nil {
+		in, out := &in.UncoveredPatterns, &out.UncoveredPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexTemplateDeletionImpact.
+func (in *IndexTemplateDeletionImpact) DeepCopy() *IndexTemplateDeletionImpact {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexTemplateDeletionImpact)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexTemplateList) DeepCopyInto(out *IndexTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IndexTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexTemplateList.
+func (in *IndexTemplateList) DeepCopy() *IndexTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IndexTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexTemplateSpec) DeepCopyInto(out *IndexTemplateSpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	in.Dependencies.DeepCopyInto(&out.Dependencies)
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ILMPolicyRef != nil {
+		in, out := &in.ILMPolicyRef, &out.ILMPolicyRef
+		*out = new(IndexLifecyclePolicyReference)
+		**out = **in
+	}
+	in.UpdatePolicy.DeepCopyInto(&out.UpdatePolicy)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexTemplateSpec.
+func (in *IndexTemplateSpec) DeepCopy() *IndexTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexTemplateStatus) DeepCopyInto(out *IndexTemplateStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DeletionImpact != nil {
+		in, out := &in.DeletionImpact, &out.DeletionImpact
+		*out = new(IndexTemplateDeletionImpact)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(IndexTemplateCanaryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexTemplateStatus.
+func (in *IndexTemplateStatus) DeepCopy() *IndexTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexTemplateUpdatePolicy) DeepCopyInto(out *IndexTemplateUpdatePolicy) {
+	*out = *in
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(IndexTemplateCanary)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexTemplateUpdatePolicy.
+func (in *IndexTemplateUpdatePolicy) DeepCopy() *IndexTemplateUpdatePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexTemplateUpdatePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngestPipeline) DeepCopyInto(out *IngestPipeline) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestPipeline.
+func (in *IngestPipeline) DeepCopy() *IngestPipeline {
+	if in == nil {
+		return nil
+	}
+	out := new(IngestPipeline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IngestPipeline) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngestPipelineList) DeepCopyInto(out *IngestPipelineList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IngestPipeline, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestPipelineList.
+func (in *IngestPipelineList) DeepCopy() *IngestPipelineList {
+	if in == nil {
+		return nil
+	}
+	out := new(IngestPipelineList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IngestPipelineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngestPipelineSpec) DeepCopyInto(out *IngestPipelineSpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Template.DeepCopyInto(&out.Template)
+	out.UpdatePolicy = in.UpdatePolicy
+	if in.Tests != nil {
+		in, out := &in.Tests, &out.Tests
+		*out = make([]IngestPipelineTest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestPipelineSpec.
+func (in *IngestPipelineSpec) DeepCopy() *IngestPipelineSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IngestPipelineSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngestPipelineStats) DeepCopyInto(out *IngestPipelineStats) {
+	*out = *in
+	if in.FailedProcessors != nil {
+		in, out := &in.FailedProcessors, &out.FailedProcessors
+		*out = make([]IngestProcessorFailures, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestPipelineStats.
+func (in *IngestPipelineStats) DeepCopy() *IngestPipelineStats {
+	if in == nil {
+		return nil
+	}
+	out := new(IngestPipelineStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngestPipelineStatus) DeepCopyInto(out *IngestPipelineStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Tests != nil {
+		in, out := &in.Tests, &out.Tests
+		*out = make([]IngestPipelineTestResult, len(*in))
+		copy(*out, *in)
+	}
+	if in.Stats != nil {
+		in, out := &in.Stats, &out.Stats
+		*out = new(IngestPipelineStats)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestPipelineStatus.
+func (in *IngestPipelineStatus) DeepCopy() *IngestPipelineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IngestPipelineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngestPipelineTest) DeepCopyInto(out *IngestPipelineTest) {
+	*out = *in
+	if in.ExpectedFields != nil {
+		in, out := &in.ExpectedFields, &out.ExpectedFields
+		*out = make(map[string]apiextensionsv1.JSON, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestPipelineTest.
+func (in *IngestPipelineTest) DeepCopy() *IngestPipelineTest {
+	if in == nil {
+		return nil
+	}
+	out := new(IngestPipelineTest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngestPipelineTestResult) DeepCopyInto(out *IngestPipelineTestResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestPipelineTestResult.
+func (in *IngestPipelineTestResult) DeepCopy() *IngestPipelineTestResult {
+	if in == nil {
+		return nil
+	}
+	out := new(IngestPipelineTestResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngestProcessorFailures) DeepCopyInto(out *IngestProcessorFailures) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestProcessorFailures.
+func (in *IngestProcessorFailures) DeepCopy() *IngestProcessorFailures {
+	if in == nil {
+		return nil
+	}
+	out := new(IngestProcessorFailures)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaPrivilege) DeepCopyInto(out *KibanaPrivilege) {
+	*out = *in
+	if in.Spaces != nil {
+		in, out := &in.Spaces, &out.Spaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaPrivilege.
+func (in *KibanaPrivilege) DeepCopy() *KibanaPrivilege {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaPrivilege)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryRuleset) DeepCopyInto(out *QueryRuleset) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryRuleset.
+func (in *QueryRuleset) DeepCopy() *QueryRuleset {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryRuleset)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QueryRuleset) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryRulesetList) DeepCopyInto(out *QueryRulesetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]QueryRuleset, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryRulesetList.
+func (in *QueryRulesetList) DeepCopy() *QueryRulesetList {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryRulesetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QueryRulesetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryRulesetSpec) DeepCopyInto(out *QueryRulesetSpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryRulesetSpec.
+func (in *QueryRulesetSpec) DeepCopy() *QueryRulesetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryRulesetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryRulesetStatus) DeepCopyInto(out *QueryRulesetStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryRulesetStatus.
+func (in *QueryRulesetStatus) DeepCopy() *QueryRulesetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryRulesetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTemplateData) DeepCopyInto(out *ResourceTemplateData) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateData.
+func (in *ResourceTemplateData) DeepCopy() *ResourceTemplateData {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceTemplateData)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IndexTemplate) DeepCopyObject() runtime.Object {
+func (in *ResourceTemplateData) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -743,31 +2601,31 @@ func (in *IndexTemplate) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IndexTemplateList) DeepCopyInto(out *IndexTemplateList) {
+func (in *ResourceTemplateDataList) DeepCopyInto(out *ResourceTemplateDataList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]IndexTemplate, len(*in))
+		*out = make([]ResourceTemplateData, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexTemplateList.
-func (in *IndexTemplateList) DeepCopy() *IndexTemplateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateDataList.
+func (in *ResourceTemplateDataList) DeepCopy() *ResourceTemplateDataList {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexTemplateList)
+	out := new(ResourceTemplateDataList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IndexTemplateList) DeepCopyObject() runtime.Object {
+func (in *ResourceTemplateDataList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -775,24 +2633,30 @@ func (in *IndexTemplateList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IndexTemplateSpec) DeepCopyInto(out *IndexTemplateSpec) {
+func (in *ResourceTemplateDataSpec) DeepCopyInto(out *ResourceTemplateDataSpec) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
-	in.Dependencies.DeepCopyInto(&out.Dependencies)
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make(map[string]apiextensionsv1.JSON, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexTemplateSpec.
-func (in *IndexTemplateSpec) DeepCopy() *IndexTemplateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateDataSpec.
+func (in *ResourceTemplateDataSpec) DeepCopy() *ResourceTemplateDataSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexTemplateSpec)
+	out := new(ResourceTemplateDataSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IndexTemplateStatus) DeepCopyInto(out *IndexTemplateStatus) {
+func (in *ResourceTemplateDataStatus) DeepCopyInto(out *ResourceTemplateDataStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -803,18 +2667,86 @@ func (in *IndexTemplateStatus) DeepCopyInto(out *IndexTemplateStatus) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexTemplateStatus.
-func (in *IndexTemplateStatus) DeepCopy() *IndexTemplateStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateDataStatus.
+func (in *ResourceTemplateDataStatus) DeepCopy() *ResourceTemplateDataStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexTemplateStatus)
+	out := new(ResourceTemplateDataStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IngestPipeline) DeepCopyInto(out *IngestPipeline) {
+func (in *SecretTemplate) DeepCopyInto(out *SecretTemplate) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretTemplate.
+func (in *SecretTemplate) DeepCopy() *SecretTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityAdoption) DeepCopyInto(out *SecurityAdoption) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityAdoption.
+func (in *SecurityAdoption) DeepCopy() *SecurityAdoption {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityAdoption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotBeforeDeleteSpec) DeepCopyInto(out *SnapshotBeforeDeleteSpec) {
+	*out = *in
+	out.MaxAge = in.MaxAge
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotBeforeDeleteSpec.
+func (in *SnapshotBeforeDeleteSpec) DeepCopy() *SnapshotBeforeDeleteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotBeforeDeleteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotLifecyclePolicy) DeepCopyInto(out *SnapshotLifecyclePolicy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -822,18 +2754,18 @@ func (in *IngestPipeline) DeepCopyInto(out *IngestPipeline) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestPipeline.
-func (in *IngestPipeline) DeepCopy() *IngestPipeline {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotLifecyclePolicy.
+func (in *SnapshotLifecyclePolicy) DeepCopy() *SnapshotLifecyclePolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(IngestPipeline)
+	out := new(SnapshotLifecyclePolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IngestPipeline) DeepCopyObject() runtime.Object {
+func (in *SnapshotLifecyclePolicy) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -841,31 +2773,31 @@ func (in *IngestPipeline) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IngestPipelineList) DeepCopyInto(out *IngestPipelineList) {
+func (in *SnapshotLifecyclePolicyList) DeepCopyInto(out *SnapshotLifecyclePolicyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]IngestPipeline, len(*in))
+		*out = make([]SnapshotLifecyclePolicy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestPipelineList.
-func (in *IngestPipelineList) DeepCopy() *IngestPipelineList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotLifecyclePolicyList.
+func (in *SnapshotLifecyclePolicyList) DeepCopy() *SnapshotLifecyclePolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(IngestPipelineList)
+	out := new(SnapshotLifecyclePolicyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IngestPipelineList) DeepCopyObject() runtime.Object {
+func (in *SnapshotLifecyclePolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -873,25 +2805,28 @@ func (in *IngestPipelineList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IngestPipelineSpec) DeepCopyInto(out *IngestPipelineSpec) {
+func (in *SnapshotLifecyclePolicySpec) DeepCopyInto(out *SnapshotLifecyclePolicySpec) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
-	in.Template.DeepCopyInto(&out.Template)
-	out.UpdatePolicy = in.UpdatePolicy
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestPipelineSpec.
-func (in *IngestPipelineSpec) DeepCopy() *IngestPipelineSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotLifecyclePolicySpec.
+func (in *SnapshotLifecyclePolicySpec) DeepCopy() *SnapshotLifecyclePolicySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(IngestPipelineSpec)
+	out := new(SnapshotLifecyclePolicySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IngestPipelineStatus) DeepCopyInto(out *IngestPipelineStatus) {
+func (in *SnapshotLifecyclePolicyStatus) DeepCopyInto(out *SnapshotLifecyclePolicyStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -900,20 +2835,27 @@ func (in *IngestPipelineStatus) DeepCopyInto(out *IngestPipelineStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestPipelineStatus.
-func (in *IngestPipelineStatus) DeepCopy() *IngestPipelineStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotLifecyclePolicyStatus.
+func (in *SnapshotLifecyclePolicyStatus) DeepCopy() *SnapshotLifecyclePolicyStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(IngestPipelineStatus)
+	out := new(SnapshotLifecyclePolicyStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceTemplateData) DeepCopyInto(out *ResourceTemplateData) {
+func (in *SnapshotRepository) DeepCopyInto(out *SnapshotRepository) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -921,18 +2863,18 @@ func (in *ResourceTemplateData) DeepCopyInto(out *ResourceTemplateData) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateData.
-func (in *ResourceTemplateData) DeepCopy() *ResourceTemplateData {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRepository.
+func (in *SnapshotRepository) DeepCopy() *SnapshotRepository {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceTemplateData)
+	out := new(SnapshotRepository)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ResourceTemplateData) DeepCopyObject() runtime.Object {
+func (in *SnapshotRepository) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -940,31 +2882,31 @@ func (in *ResourceTemplateData) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceTemplateDataList) DeepCopyInto(out *ResourceTemplateDataList) {
+func (in *SnapshotRepositoryList) DeepCopyInto(out *SnapshotRepositoryList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ResourceTemplateData, len(*in))
+		*out = make([]SnapshotRepository, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateDataList.
-func (in *ResourceTemplateDataList) DeepCopy() *ResourceTemplateDataList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRepositoryList.
+func (in *SnapshotRepositoryList) DeepCopy() *SnapshotRepositoryList {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceTemplateDataList)
+	out := new(SnapshotRepositoryList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ResourceTemplateDataList) DeepCopyObject() runtime.Object {
+func (in *SnapshotRepositoryList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -972,30 +2914,35 @@ func (in *ResourceTemplateDataList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceTemplateDataSpec) DeepCopyInto(out *ResourceTemplateDataSpec) {
+func (in *SnapshotRepositorySpec) DeepCopyInto(out *SnapshotRepositorySpec) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
-	if in.Values != nil {
-		in, out := &in.Values, &out.Values
-		*out = make(map[string]apiextensionsv1.JSON, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadonlyReplicas != nil {
+		in, out := &in.ReadonlyReplicas, &out.ReadonlyReplicas
+		*out = make([]CommonElasticsearchConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateDataSpec.
-func (in *ResourceTemplateDataSpec) DeepCopy() *ResourceTemplateDataSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRepositorySpec.
+func (in *SnapshotRepositorySpec) DeepCopy() *SnapshotRepositorySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceTemplateDataSpec)
+	out := new(SnapshotRepositorySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceTemplateDataStatus) DeepCopyInto(out *ResourceTemplateDataStatus) {
+func (in *SnapshotRepositoryStatus) DeepCopyInto(out *SnapshotRepositoryStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -1004,39 +2951,53 @@ func (in *ResourceTemplateDataStatus) DeepCopyInto(out *ResourceTemplateDataStat
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ReadonlyReplicas != nil {
+		in, out := &in.ReadonlyReplicas, &out.ReadonlyReplicas
+		*out = make([]CommonElasticsearchConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTemplateDataStatus.
-func (in *ResourceTemplateDataStatus) DeepCopy() *ResourceTemplateDataStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRepositoryStatus.
+func (in *SnapshotRepositoryStatus) DeepCopy() *SnapshotRepositoryStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceTemplateDataStatus)
+	out := new(SnapshotRepositoryStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SnapshotLifecyclePolicy) DeepCopyInto(out *SnapshotLifecyclePolicy) {
+func (in *SnapshotRestore) DeepCopyInto(out *SnapshotRestore) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotLifecyclePolicy.
-func (in *SnapshotLifecyclePolicy) DeepCopy() *SnapshotLifecyclePolicy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRestore.
+func (in *SnapshotRestore) DeepCopy() *SnapshotRestore {
 	if in == nil {
 		return nil
 	}
-	out := new(SnapshotLifecyclePolicy)
+	out := new(SnapshotRestore)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SnapshotLifecyclePolicy) DeepCopyObject() runtime.Object {
+func (in *SnapshotRestore) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1044,31 +3005,31 @@ func (in *SnapshotLifecyclePolicy) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SnapshotLifecyclePolicyList) DeepCopyInto(out *SnapshotLifecyclePolicyList) {
+func (in *SnapshotRestoreList) DeepCopyInto(out *SnapshotRestoreList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]SnapshotLifecyclePolicy, len(*in))
+		*out = make([]SnapshotRestore, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotLifecyclePolicyList.
-func (in *SnapshotLifecyclePolicyList) DeepCopy() *SnapshotLifecyclePolicyList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRestoreList.
+func (in *SnapshotRestoreList) DeepCopy() *SnapshotRestoreList {
 	if in == nil {
 		return nil
 	}
-	out := new(SnapshotLifecyclePolicyList)
+	out := new(SnapshotRestoreList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SnapshotLifecyclePolicyList) DeepCopyObject() runtime.Object {
+func (in *SnapshotRestoreList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1076,24 +3037,54 @@ func (in *SnapshotLifecyclePolicyList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SnapshotLifecyclePolicySpec) DeepCopyInto(out *SnapshotLifecyclePolicySpec) {
+func (in *SnapshotRestoreSpec) DeepCopyInto(out *SnapshotRestoreSpec) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.Indices != nil {
+		in, out := &in.Indices, &out.Indices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IndexSettings != nil {
+		in, out := &in.IndexSettings, &out.IndexSettings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.IgnoreIndexSettings != nil {
+		in, out := &in.IgnoreIndexSettings, &out.IgnoreIndexSettings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotLifecyclePolicySpec.
-func (in *SnapshotLifecyclePolicySpec) DeepCopy() *SnapshotLifecyclePolicySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRestoreSpec.
+func (in *SnapshotRestoreSpec) DeepCopy() *SnapshotRestoreSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SnapshotLifecyclePolicySpec)
+	out := new(SnapshotRestoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SnapshotLifecyclePolicyStatus) DeepCopyInto(out *SnapshotLifecyclePolicyStatus) {
+func (in *SnapshotRestoreStatus) DeepCopyInto(out *SnapshotRestoreStatus) {
 	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Indices != nil {
+		in, out := &in.Indices, &out.Indices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -1101,39 +3092,46 @@ func (in *SnapshotLifecyclePolicyStatus) DeepCopyInto(out *SnapshotLifecyclePoli
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotLifecyclePolicyStatus.
-func (in *SnapshotLifecyclePolicyStatus) DeepCopy() *SnapshotLifecyclePolicyStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRestoreStatus.
+func (in *SnapshotRestoreStatus) DeepCopy() *SnapshotRestoreStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SnapshotLifecyclePolicyStatus)
+	out := new(SnapshotRestoreStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SnapshotRepository) DeepCopyInto(out *SnapshotRepository) {
+func (in *SynonymSet) DeepCopyInto(out *SynonymSet) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRepository.
-func (in *SnapshotRepository) DeepCopy() *SnapshotRepository {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SynonymSet.
+func (in *SynonymSet) DeepCopy() *SynonymSet {
 	if in == nil {
 		return nil
 	}
-	out := new(SnapshotRepository)
+	out := new(SynonymSet)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SnapshotRepository) DeepCopyObject() runtime.Object {
+func (in *SynonymSet) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1141,31 +3139,31 @@ func (in *SnapshotRepository) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SnapshotRepositoryList) DeepCopyInto(out *SnapshotRepositoryList) {
+func (in *SynonymSetList) DeepCopyInto(out *SynonymSetList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]SnapshotRepository, len(*in))
+		*out = make([]SynonymSet, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRepositoryList.
-func (in *SnapshotRepositoryList) DeepCopy() *SnapshotRepositoryList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SynonymSetList.
+func (in *SynonymSetList) DeepCopy() *SynonymSetList {
 	if in == nil {
 		return nil
 	}
-	out := new(SnapshotRepositoryList)
+	out := new(SynonymSetList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SnapshotRepositoryList) DeepCopyObject() runtime.Object {
+func (in *SynonymSetList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1173,23 +3171,28 @@ func (in *SnapshotRepositoryList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SnapshotRepositorySpec) DeepCopyInto(out *SnapshotRepositorySpec) {
+func (in *SynonymSetSpec) DeepCopyInto(out *SynonymSetSpec) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRepositorySpec.
-func (in *SnapshotRepositorySpec) DeepCopy() *SnapshotRepositorySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SynonymSetSpec.
+func (in *SynonymSetSpec) DeepCopy() *SynonymSetSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SnapshotRepositorySpec)
+	out := new(SynonymSetSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SnapshotRepositoryStatus) DeepCopyInto(out *SnapshotRepositoryStatus) {
+func (in *SynonymSetStatus) DeepCopyInto(out *SynonymSetStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -1198,14 +3201,21 @@ func (in *SnapshotRepositoryStatus) DeepCopyInto(out *SnapshotRepositoryStatus)
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = make([]FailoverStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRepositoryStatus.
-func (in *SnapshotRepositoryStatus) DeepCopy() *SnapshotRepositoryStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SynonymSetStatus.
+func (in *SynonymSetStatus) DeepCopy() *SynonymSetStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SnapshotRepositoryStatus)
+	out := new(SynonymSetStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1224,3 +3234,19 @@ func (in *UpdatePolicySpec) DeepCopy() *UpdatePolicySpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserSecretTemplate) DeepCopyInto(out *UserSecretTemplate) {
+	*out = *in
+	in.SecretTemplate.DeepCopyInto(&out.SecretTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserSecretTemplate.
+func (in *UserSecretTemplate) DeepCopy() *UserSecretTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(UserSecretTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AdvancedSettingsSpec defines the desired state of AdvancedSettings
+type AdvancedSettingsSpec struct {
+	// +optional
+	TargetConfig CommonKibanaConfig `json:"targetInstance,omitempty"`
+
+	// Space whose advanced settings are managed, the default space if not set
+	// +optional
+	Space *string `json:"space,omitempty"`
+
+	// Body is a JSON object of the advanced settings managed by this resource, like defaultRoute, dateFormat or
+	// theme:darkMode. Settings which are not declared are left untouched.
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// BodyJSON is the body as structured JSON instead of a string. It is stored as it is and printed readable by
+	// kubectl. Only one of body and bodyJson may be set.
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	BodyJSON *apiextensionsv1.JSON `json:"bodyJson,omitempty"`
+}
+
+// AdvancedSettingsStatus defines the observed state of AdvancedSettings
+type AdvancedSettingsStatus struct {
+	// ManagedKeys are the settings last applied by this resource, they are reset once no longer declared
+	// +optional
+	ManagedKeys []string `json:"managedKeys,omitempty"`
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// AdvancedSettings is the Schema for the advancedsettings API
+type AdvancedSettings struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AdvancedSettingsSpec   `json:"spec,omitempty"`
+	Status AdvancedSettingsStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// AdvancedSettingsList contains a list of AdvancedSettings
+type AdvancedSettingsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AdvancedSettings `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AdvancedSettings{}, &AdvancedSettingsList{})
+}
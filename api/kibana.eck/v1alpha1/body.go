@@ -0,0 +1,154 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// specBody returns the body given as string, or as structured JSON if the string is not set
+func specBody(body string, bodyJSON *apiextensionsv1.JSON) string {
+	if body != "" || bodyJSON == nil {
+		return body
+	}
+	return string(bodyJSON.Raw)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *AgentPolicySpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *PackagePolicySpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *MaintenanceWindowSpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *SpaceSpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *SavedObject) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// GetBody returns spec.body, or spec.bodyJson if spec.body is not set
+func (in *AdvancedSettingsSpec) GetBody() string {
+	return specBody(in.Body, in.BodyJSON)
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *AgentPolicy) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *PackagePolicy) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *MaintenanceWindow) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *Space) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *AdvancedSettings) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *Dashboard) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *Visualization) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *Lens) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *SavedSearch) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *IndexPattern) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *CanvasWorkpad) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFields returns spec.body and spec.bodyJson
+func (in *DataView) BodyFields() (string, *apiextensionsv1.JSON) {
+	return in.Spec.Body, in.Spec.BodyJSON
+}
+
+// BodyFromRef returns spec.bodyFrom
+func (in *Dashboard) BodyFromRef() *BodyFrom {
+	return in.Spec.BodyFrom
+}
+
+// BodyFromRef returns spec.bodyFrom
+func (in *Visualization) BodyFromRef() *BodyFrom {
+	return in.Spec.BodyFrom
+}
+
+// BodyFromRef returns spec.bodyFrom
+func (in *Lens) BodyFromRef() *BodyFrom {
+	return in.Spec.BodyFrom
+}
+
+// BodyFromRef returns spec.bodyFrom
+func (in *SavedSearch) BodyFromRef() *BodyFrom {
+	return in.Spec.BodyFrom
+}
+
+// BodyFromRef returns spec.bodyFrom
+func (in *IndexPattern) BodyFromRef() *BodyFrom {
+	return in.Spec.BodyFrom
+}
+
+// BodyFromRef returns spec.bodyFrom
+func (in *CanvasWorkpad) BodyFromRef() *BodyFrom {
+	return in.Spec.BodyFrom
+}
+
+// BodyFromRef returns spec.bodyFrom
+func (in *DataView) BodyFromRef() *BodyFrom {
+	return in.Spec.BodyFrom
+}
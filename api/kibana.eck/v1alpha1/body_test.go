@@ -0,0 +1,44 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestDashboard_GetSavedObjectBodyJSON(t *testing.T) {
+	dashboard := Dashboard{Spec: DashboardSpec{SavedObject: SavedObject{BodyJSON: &apiextensionsv1.JSON{Raw: []byte(`{"attributes":{"title":"KPIs"}}`)}}}}
+
+	savedObject := dashboard.Spec.GetSavedObject()
+	if savedObject.Body != `{"attributes":{"title":"KPIs"}}` {
+		t.Errorf("Expected the saved object body to be taken from bodyJson, got %s", savedObject.Body)
+	}
+	if savedObject.BodyJSON != nil {
+		t.Error("Expected the saved object to carry the body only once")
+	}
+}
+
+func TestSpaceSpec_DeepCopyBodyJSON(t *testing.T) {
+	space := &Space{Spec: SpaceSpec{BodyJSON: &apiextensionsv1.JSON{Raw: []byte(`{"name":"ops"}`)}}}
+	copied := space.DeepCopy()
+	copied.Spec.BodyJSON.Raw[2] = 'X'
+
+	if space.Spec.GetBody() != `{"name":"ops"}` {
+		t.Errorf("Expected the copy not to share bodyJson, got %s", space.Spec.GetBody())
+	}
+}
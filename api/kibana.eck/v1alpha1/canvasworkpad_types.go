@@ -0,0 +1,80 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CanvasWorkpadSpec defines the desired state of CanvasWorkpad
+type CanvasWorkpadSpec struct {
+	// +optional
+	TargetConfig CommonKibanaConfig `json:"targetInstance,omitempty"`
+
+	SavedObject `json:",inline"`
+}
+
+// CanvasWorkpadStatus defines the observed state of CanvasWorkpad
+type CanvasWorkpadStatus struct {
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// Adoption records the saved object adopted from Kibana, see spec.conflictPolicy
+	// +optional
+	Adoption *SavedObjectAdoption `json:"adoption,omitempty"`
+	// DeployedTo records the target instance and space the saved object was last deployed to, see
+	// spec.deletionPolicy
+	// +optional
+	DeployedTo *SavedObjectLocation `json:"deployedTo,omitempty"`
+	// SavedObjectVersion is the version of the saved object in Kibana after the operator wrote it last, updates
+	// are conditional on it, see spec.updatePolicy
+	// +optional
+	SavedObjectVersion string `json:"savedObjectVersion,omitempty"`
+	// SharedToSpaces are the spaces the operator shared the saved object to, see spec.shareToSpaces
+	// +optional
+	SharedToSpaces []string `json:"sharedToSpaces,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// CanvasWorkpad is the Schema for the canvasworkpads API
+type CanvasWorkpad struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CanvasWorkpadSpec   `json:"spec,omitempty"`
+	Status CanvasWorkpadStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CanvasWorkpadList contains a list of CanvasWorkpad
+type CanvasWorkpadList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CanvasWorkpad `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CanvasWorkpad{}, &CanvasWorkpadList{})
+}
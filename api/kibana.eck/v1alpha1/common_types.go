@@ -1,8 +1,34 @@
 package v1alpha1
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 type CommonKibanaConfig struct {
 	// +optional
 	KibanaInstance string `json:"name,omitempty"`
 	// +optional
 	KibanaInstanceNamespace string `json:"namespace,omitempty"`
+	// Kind of the instance, KibanaInstance or ClusterKibanaInstance. Defaults to KibanaInstance.
+	// The namespace is ignored for ClusterKibanaInstances.
+	// +kubebuilder:validation:Enum=KibanaInstance;ClusterKibanaInstance
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// Selector selects the KibanaInstance by its labels instead of by name, exactly one KibanaInstance in the namespace
+	// has to match. Ignored if name is set.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// IsClusterInstance reports whether the target is a ClusterKibanaInstance
+func (c CommonKibanaConfig) IsClusterInstance() bool {
+	return c.Kind == ClusterKibanaInstanceKind
+}
+
+// ElasticsearchInstanceReference references an ElasticsearchInstance, the namespace defaults to the namespace of
+// the referencing resource
+type ElasticsearchInstanceReference struct {
+	Name string `json:"name"`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
@@ -26,10 +26,37 @@ type DataViewSpec struct {
 	TargetConfig CommonKibanaConfig `json:"targetInstance,omitempty"`
 
 	SavedObject `json:",inline"`
+
+	// Default makes the data view the default data view of its space, via the defaultIndex advanced setting. The
+	// setting is reset when the DataView is deleted while it is still the default.
+	// +optional
+	Default bool `json:"default,omitempty"`
 }
 
 // DataViewStatus defines the observed state of DataView
 type DataViewStatus struct {
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// Adoption records the saved object adopted from Kibana, see spec.conflictPolicy
+	// +optional
+	Adoption *SavedObjectAdoption `json:"adoption,omitempty"`
+	// DeployedTo records the target instance and space the saved object was last deployed to, see
+	// spec.deletionPolicy
+	// +optional
+	DeployedTo *SavedObjectLocation `json:"deployedTo,omitempty"`
+	// SavedObjectVersion is the version of the saved object in Kibana after the operator wrote it last, updates
+	// are conditional on it, see spec.updatePolicy
+	// +optional
+	SavedObjectVersion string `json:"savedObjectVersion,omitempty"`
+	// SharedToSpaces are the spaces the operator shared the saved object to, see spec.shareToSpaces
+	// +optional
+	SharedToSpaces []string `json:"sharedToSpaces,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -0,0 +1,103 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KibanaRawRequestSpec defines the desired state of KibanaRawRequest
+type KibanaRawRequestSpec struct {
+	// +optional
+	TargetConfig CommonKibanaConfig `json:"targetInstance,omitempty"`
+
+	// Path of the PUT request sent to Kibana on every reconcile, including the query string, e.g.
+	// /api/streams/logs.nginx. It has to start with one of the rawRequests.kibana prefixes of the operator configuration.
+	// +kubebuilder:validation:Pattern=`^/`
+	// +required
+	Path string `json:"path"`
+
+	// Body of the PUT request
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// DeletePath is the path of the DELETE request sent when the resource is deleted, path if not set. An empty
+	// string leaves the object in Kibana in place.
+	// +optional
+	DeletePath *string `json:"deletePath,omitempty"`
+}
+
+// GetDeletePath returns the path of the DELETE request sent when the resource is deleted, empty if none is sent
+func (in *KibanaRawRequestSpec) GetDeletePath() string {
+	if in.DeletePath == nil {
+		return in.Path
+	}
+	return *in.DeletePath
+}
+
+// KibanaRawRequestStatus defines the observed state of KibanaRawRequest
+type KibanaRawRequestStatus struct {
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// AppliedPath is the path of the last successful PUT request
+	// +optional
+	AppliedPath string `json:"appliedPath,omitempty"`
+	// AppliedDeletePath is the path of the DELETE request removing the object of the last successful PUT request,
+	// sent when the resource is deleted or its path changes. Empty if the object is left in place.
+	// +optional
+	AppliedDeletePath string `json:"appliedDeletePath,omitempty"`
+}
+
+// GetAppliedDeletePath returns the path of the DELETE request removing the object of the resource, the one recorded
+// with the last successful PUT request, or the one of the spec if none was recorded yet
+func (in *KibanaRawRequest) GetAppliedDeletePath() string {
+	if in.Status.AppliedPath == "" {
+		return in.Spec.GetDeletePath()
+	}
+	return in.Status.AppliedDeletePath
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// KibanaRawRequest is the Schema for the kibanarawrequests API
+type KibanaRawRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KibanaRawRequestSpec   `json:"spec,omitempty"`
+	Status KibanaRawRequestStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// KibanaRawRequestList contains a list of KibanaRawRequest
+type KibanaRawRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KibanaRawRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KibanaRawRequest{}, &KibanaRawRequestList{})
+}
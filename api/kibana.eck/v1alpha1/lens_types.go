@@ -30,6 +30,28 @@ type LensSpec struct {
 
 // LensStatus defines the observed state of Lens
 type LensStatus struct {
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// Adoption records the saved object adopted from Kibana, see spec.conflictPolicy
+	// +optional
+	Adoption *SavedObjectAdoption `json:"adoption,omitempty"`
+	// DeployedTo records the target instance and space the saved object was last deployed to, see
+	// spec.deletionPolicy
+	// +optional
+	DeployedTo *SavedObjectLocation `json:"deployedTo,omitempty"`
+	// SavedObjectVersion is the version of the saved object in Kibana after the operator wrote it last, updates
+	// are conditional on it, see spec.updatePolicy
+	// +optional
+	SavedObjectVersion string `json:"savedObjectVersion,omitempty"`
+	// SharedToSpaces are the spaces the operator shared the saved object to, see spec.shareToSpaces
+	// +optional
+	SharedToSpaces []string `json:"sharedToSpaces,omitempty"`
 }
 
 //+kubebuilder:object:root=true
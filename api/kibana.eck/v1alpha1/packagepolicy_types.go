@@ -0,0 +1,87 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PackagePolicySpec defines the desired state of PackagePolicy
+type PackagePolicySpec struct {
+	// +optional
+	TargetConfig CommonKibanaConfig `json:"targetInstance,omitempty"`
+
+	// Space in which the package policy is created, the default space if not set
+	// +optional
+	Space *string `json:"space,omitempty"`
+
+	// AgentPolicies are the ids of the agent policies the integration is added to, the names of AgentPolicy
+	// resources. They replace policy_id and policy_ids of the body, the package policy is only created once all of
+	// them exist in Fleet.
+	// +optional
+	AgentPolicies []string `json:"agentPolicies,omitempty"`
+
+	// Body of the package policy as accepted by the Kibana /api/fleet/package_policies API. The id of the package
+	// policy is the name of the resource.
+	// +optional
+	Body string `json:"body,omitempty"`
+
+	// BodyJSON is the body as structured JSON instead of a string. It is stored as it is and printed readable by
+	// kubectl. Only one of body and bodyJson may be set.
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	BodyJSON *apiextensionsv1.JSON `json:"bodyJson,omitempty"`
+}
+
+// PackagePolicyStatus defines the observed state of PackagePolicy
+type PackagePolicyStatus struct {
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PackagePolicy is the Schema for the packagepolicies API
+type PackagePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PackagePolicySpec   `json:"spec,omitempty"`
+	Status PackagePolicyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PackagePolicyList contains a list of PackagePolicy
+type PackagePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PackagePolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PackagePolicy{}, &PackagePolicyList{})
+}
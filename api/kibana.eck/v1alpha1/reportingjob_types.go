@@ -0,0 +1,148 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReportingFormat is the format of the generated report
+// +kubebuilder:validation:Enum=PDF;PNG;CSV
+type ReportingFormat string
+
+const (
+	ReportingFormatPDF ReportingFormat = "PDF"
+	ReportingFormatPNG ReportingFormat = "PNG"
+	ReportingFormatCSV ReportingFormat = "CSV"
+)
+
+// ReportingJobStatus values, as derived from the download of the report
+const (
+	ReportingJobStatusPending   = "pending"
+	ReportingJobStatusCompleted = "completed"
+	ReportingJobStatusFailed    = "failed"
+)
+
+// ReportingJobSpec defines the desired state of ReportingJob
+type ReportingJobSpec struct {
+	// +optional
+	TargetConfig CommonKibanaConfig `json:"targetInstance,omitempty"`
+
+	// Space of the reported saved object, the default space if not set
+	// +optional
+	Space *string `json:"space,omitempty"`
+
+	// ObjectType of the reported saved object, dashboard for PDF and PNG reports or search for CSV reports
+	// +kubebuilder:validation:Enum=dashboard;search
+	// +required
+	ObjectType string `json:"type"`
+
+	// ObjectID is the id of the reported saved object, which is the name of the Dashboard or SavedSearch resource
+	// managing it
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	ObjectID string `json:"objectId"`
+
+	// Format of the report
+	// +required
+	Format ReportingFormat `json:"format"`
+
+	// Schedule in cron format, e.g. "0 7 * * 1" for every Monday at 7:00
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Schedule string `json:"schedule"`
+
+	// TimeZone of the schedule and of the report as IANA name, e.g. Europe/Berlin. Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// TimeRange of the report, the time range saved with the object if not set
+	// +optional
+	TimeRange *ReportingTimeRange `json:"timeRange,omitempty"`
+
+	// JobParams are the rison encoded job parameters used instead of the generated ones, as contained in the POST URL
+	// copied from the Kibana share menu
+	// +optional
+	JobParams string `json:"jobParams,omitempty"`
+
+	// Suspend stops scheduling new reports
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// ReportingTimeRange is the time range of a report in Kibana date math, e.g. now-7d
+type ReportingTimeRange struct {
+	// +required
+	From string `json:"from"`
+	// +required
+	To string `json:"to"`
+}
+
+// ReportingJobStatus defines the observed state of ReportingJob
+type ReportingJobStatus struct {
+	// LastScheduleTime is when the last report was triggered
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// NextScheduleTime is when the next report is triggered
+	// +optional
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+	// LastSuccessfulTime is when the last report completed successfully
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+	// JobID is the id Kibana assigned to the last report job
+	// +optional
+	JobID string `json:"jobID,omitempty"`
+	// JobStatus of the last report job, one of pending, completed or failed
+	// +optional
+	JobStatus string `json:"jobStatus,omitempty"`
+	// ReportURL is where the last report is downloaded from
+	// +optional
+	ReportURL string `json:"reportURL,omitempty"`
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// ReportingJob is the Schema for the reportingjobs API
+type ReportingJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReportingJobSpec   `json:"spec,omitempty"`
+	Status ReportingJobStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ReportingJobList contains a list of ReportingJob
+type ReportingJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReportingJob `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ReportingJob{}, &ReportingJobList{})
+}
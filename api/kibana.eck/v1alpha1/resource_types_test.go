@@ -425,3 +425,87 @@ func TestKibanaInstanceList(t *testing.T) {
 		t.Errorf("Expected 2 items, got %d", len(list.Items))
 	}
 }
+
+// Tests for MaintenanceWindow
+func TestMaintenanceWindowDeepCopy(t *testing.T) {
+	space := "ops"
+	original := &MaintenanceWindow{
+		ObjectMeta: metav1.ObjectMeta{Name: "deployment"},
+		Spec: MaintenanceWindowSpec{
+			Space: &space,
+			Body:  `{"title": "Deployment"}`,
+		},
+		Status: MaintenanceWindowStatus{MaintenanceWindowID: "abc"},
+	}
+
+	copied := original.DeepCopy()
+	*copied.Spec.Space = "other"
+
+	if *original.Spec.Space != "ops" {
+		t.Errorf("Expected the copy not to share Space, got %q", *original.Spec.Space)
+	}
+	if copied.Status.MaintenanceWindowID != "abc" {
+		t.Errorf("Expected MaintenanceWindowID to be copied, got %q", copied.Status.MaintenanceWindowID)
+	}
+}
+
+// Tests for AdvancedSettings
+func TestAdvancedSettingsDeepCopy(t *testing.T) {
+	space := "ops"
+	original := &AdvancedSettings{
+		ObjectMeta: metav1.ObjectMeta{Name: "ops-settings"},
+		Spec: AdvancedSettingsSpec{
+			Space: &space,
+			Body:  `{"defaultRoute": "/app/dashboards"}`,
+		},
+		Status: AdvancedSettingsStatus{ManagedKeys: []string{"defaultRoute"}},
+	}
+
+	copied := original.DeepCopy()
+	*copied.Spec.Space = "other"
+	copied.Status.ManagedKeys[0] = "dateFormat"
+
+	if *original.Spec.Space != "ops" {
+		t.Errorf("Expected the copy not to share Space, got %q", *original.Spec.Space)
+	}
+	if original.Status.ManagedKeys[0] != "defaultRoute" {
+		t.Errorf("Expected the copy not to share ManagedKeys, got %v", original.Status.ManagedKeys)
+	}
+}
+
+// Tests for PackagePolicy
+func TestPackagePolicyDeepCopy(t *testing.T) {
+	original := &PackagePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "system"},
+		Spec: PackagePolicySpec{
+			AgentPolicies: []string{"fleet-server"},
+			Body:          `{"package": {"name": "system", "version": "1.60.0"}}`,
+		},
+	}
+
+	copied := original.DeepCopy()
+	copied.Spec.AgentPolicies[0] = "other"
+
+	if original.Spec.AgentPolicies[0] != "fleet-server" {
+		t.Errorf("Expected the copy not to share AgentPolicies, got %v", original.Spec.AgentPolicies)
+	}
+}
+
+// Tests for KibanaRawRequest
+func TestKibanaRawRequestDeepCopy(t *testing.T) {
+	deletePath := "/api/streams/logs.nginx"
+	original := &KibanaRawRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+		Spec: KibanaRawRequestSpec{
+			Path:       "/api/streams/logs.nginx",
+			DeletePath: &deletePath,
+		},
+	}
+
+	copied := original.DeepCopy()
+	*copied.Spec.DeletePath = "/api/streams/logs.other"
+
+	if original.Spec.GetDeletePath() != "/api/streams/logs.nginx" {
+		t.Errorf("Expected the copy not to share DeletePath, got %q", original.Spec.GetDeletePath())
+	}
+}
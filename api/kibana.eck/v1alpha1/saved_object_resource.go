@@ -0,0 +1,575 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SavedObjectResource is a resource deployed to Kibana as a saved object, giving access to the fields its
+// reconciler needs independent of the kind
+// +kubebuilder:object:generate=false
+type SavedObjectResource interface {
+	client.Object
+	// GetTargetConfig returns spec.targetInstance
+	GetTargetConfig() CommonKibanaConfig
+	// GetSpec returns the spec
+	GetSpec() any
+	// GetSavedObjectSpec returns the saved object of the spec, see SavedObject.GetSavedObject
+	GetSavedObjectSpec() SavedObject
+	// GetConditions returns status.conditions
+	GetConditions() *[]metav1.Condition
+	// GetObservedGeneration returns status.observedGeneration
+	GetObservedGeneration() *int64
+	// GetAdoption returns status.adoption
+	GetAdoption() *SavedObjectAdoption
+	// SetAdoption sets status.adoption
+	SetAdoption(adoption *SavedObjectAdoption)
+	// SetLocation sets spec.targetInstance and spec.space
+	SetLocation(location SavedObjectLocation)
+	// GetDeployedTo returns status.deployedTo
+	GetDeployedTo() *SavedObjectLocation
+	// SetDeployedTo sets status.deployedTo
+	SetDeployedTo(location *SavedObjectLocation)
+	// GetSavedObjectVersion returns status.savedObjectVersion
+	GetSavedObjectVersion() string
+	// SetSavedObjectVersion sets status.savedObjectVersion
+	SetSavedObjectVersion(version string)
+	// GetSharedToSpaces returns status.sharedToSpaces
+	GetSharedToSpaces() []string
+	// SetSharedToSpaces sets status.sharedToSpaces
+	SetSharedToSpaces(spaces []string)
+}
+
+var (
+	_ SavedObjectResource = &Dashboard{}
+	_ SavedObjectResource = &Visualization{}
+	_ SavedObjectResource = &Lens{}
+	_ SavedObjectResource = &SavedSearch{}
+	_ SavedObjectResource = &IndexPattern{}
+	_ SavedObjectResource = &CanvasWorkpad{}
+	_ SavedObjectResource = &DataView{}
+)
+
+// GetTargetConfig returns spec.targetInstance
+func (in *Dashboard) GetTargetConfig() CommonKibanaConfig {
+	return in.Spec.TargetConfig
+}
+
+// GetTargetConfig returns spec.targetInstance
+func (in *Visualization) GetTargetConfig() CommonKibanaConfig {
+	return in.Spec.TargetConfig
+}
+
+// GetTargetConfig returns spec.targetInstance
+func (in *Lens) GetTargetConfig() CommonKibanaConfig {
+	return in.Spec.TargetConfig
+}
+
+// GetTargetConfig returns spec.targetInstance
+func (in *SavedSearch) GetTargetConfig() CommonKibanaConfig {
+	return in.Spec.TargetConfig
+}
+
+// GetTargetConfig returns spec.targetInstance
+func (in *IndexPattern) GetTargetConfig() CommonKibanaConfig {
+	return in.Spec.TargetConfig
+}
+
+// GetTargetConfig returns spec.targetInstance
+func (in *CanvasWorkpad) GetTargetConfig() CommonKibanaConfig {
+	return in.Spec.TargetConfig
+}
+
+// GetTargetConfig returns spec.targetInstance
+func (in *DataView) GetTargetConfig() CommonKibanaConfig {
+	return in.Spec.TargetConfig
+}
+
+// GetSpec returns the spec
+func (in *Dashboard) GetSpec() any {
+	return in.Spec
+}
+
+// GetSpec returns the spec
+func (in *Visualization) GetSpec() any {
+	return in.Spec
+}
+
+// GetSpec returns the spec
+func (in *Lens) GetSpec() any {
+	return in.Spec
+}
+
+// GetSpec returns the spec
+func (in *SavedSearch) GetSpec() any {
+	return in.Spec
+}
+
+// GetSpec returns the spec
+func (in *IndexPattern) GetSpec() any {
+	return in.Spec
+}
+
+// GetSpec returns the spec
+func (in *CanvasWorkpad) GetSpec() any {
+	return in.Spec
+}
+
+// GetSpec returns the spec
+func (in *DataView) GetSpec() any {
+	return in.Spec
+}
+
+// GetSavedObjectSpec returns the saved object of the spec
+func (in *Dashboard) GetSavedObjectSpec() SavedObject {
+	return in.Spec.GetSavedObject()
+}
+
+// GetSavedObjectSpec returns the saved object of the spec
+func (in *Visualization) GetSavedObjectSpec() SavedObject {
+	return in.Spec.GetSavedObject()
+}
+
+// GetSavedObjectSpec returns the saved object of the spec
+func (in *Lens) GetSavedObjectSpec() SavedObject {
+	return in.Spec.GetSavedObject()
+}
+
+// GetSavedObjectSpec returns the saved object of the spec
+func (in *SavedSearch) GetSavedObjectSpec() SavedObject {
+	return in.Spec.GetSavedObject()
+}
+
+// GetSavedObjectSpec returns the saved object of the spec
+func (in *IndexPattern) GetSavedObjectSpec() SavedObject {
+	return in.Spec.GetSavedObject()
+}
+
+// GetSavedObjectSpec returns the saved object of the spec
+func (in *CanvasWorkpad) GetSavedObjectSpec() SavedObject {
+	return in.Spec.GetSavedObject()
+}
+
+// GetSavedObjectSpec returns the saved object of the spec
+func (in *DataView) GetSavedObjectSpec() SavedObject {
+	return in.Spec.GetSavedObject()
+}
+
+// GetConditions returns status.conditions
+func (in *Dashboard) GetConditions() *[]metav1.Condition {
+	return &in.Status.Conditions
+}
+
+// GetConditions returns status.conditions
+func (in *Visualization) GetConditions() *[]metav1.Condition {
+	return &in.Status.Conditions
+}
+
+// GetConditions returns status.conditions
+func (in *Lens) GetConditions() *[]metav1.Condition {
+	return &in.Status.Conditions
+}
+
+// GetConditions returns status.conditions
+func (in *SavedSearch) GetConditions() *[]metav1.Condition {
+	return &in.Status.Conditions
+}
+
+// GetConditions returns status.conditions
+func (in *IndexPattern) GetConditions() *[]metav1.Condition {
+	return &in.Status.Conditions
+}
+
+// GetConditions returns status.conditions
+func (in *CanvasWorkpad) GetConditions() *[]metav1.Condition {
+	return &in.Status.Conditions
+}
+
+// GetConditions returns status.conditions
+func (in *DataView) GetConditions() *[]metav1.Condition {
+	return &in.Status.Conditions
+}
+
+// GetObservedGeneration returns status.observedGeneration
+func (in *Dashboard) GetObservedGeneration() *int64 {
+	return &in.Status.ObservedGeneration
+}
+
+// GetObservedGeneration returns status.observedGeneration
+func (in *Visualization) GetObservedGeneration() *int64 {
+	return &in.Status.ObservedGeneration
+}
+
+// GetObservedGeneration returns status.observedGeneration
+func (in *Lens) GetObservedGeneration() *int64 {
+	return &in.Status.ObservedGeneration
+}
+
+// GetObservedGeneration returns status.observedGeneration
+func (in *SavedSearch) GetObservedGeneration() *int64 {
+	return &in.Status.ObservedGeneration
+}
+
+// GetObservedGeneration returns status.observedGeneration
+func (in *IndexPattern) GetObservedGeneration() *int64 {
+	return &in.Status.ObservedGeneration
+}
+
+// GetObservedGeneration returns status.observedGeneration
+func (in *CanvasWorkpad) GetObservedGeneration() *int64 {
+	return &in.Status.ObservedGeneration
+}
+
+// GetObservedGeneration returns status.observedGeneration
+func (in *DataView) GetObservedGeneration() *int64 {
+	return &in.Status.ObservedGeneration
+}
+
+// GetAdoption returns status.adoption
+func (in *Dashboard) GetAdoption() *SavedObjectAdoption {
+	return in.Status.Adoption
+}
+
+// GetAdoption returns status.adoption
+func (in *Visualization) GetAdoption() *SavedObjectAdoption {
+	return in.Status.Adoption
+}
+
+// GetAdoption returns status.adoption
+func (in *Lens) GetAdoption() *SavedObjectAdoption {
+	return in.Status.Adoption
+}
+
+// GetAdoption returns status.adoption
+func (in *SavedSearch) GetAdoption() *SavedObjectAdoption {
+	return in.Status.Adoption
+}
+
+// GetAdoption returns status.adoption
+func (in *IndexPattern) GetAdoption() *SavedObjectAdoption {
+	return in.Status.Adoption
+}
+
+// GetAdoption returns status.adoption
+func (in *CanvasWorkpad) GetAdoption() *SavedObjectAdoption {
+	return in.Status.Adoption
+}
+
+// GetAdoption returns status.adoption
+func (in *DataView) GetAdoption() *SavedObjectAdoption {
+	return in.Status.Adoption
+}
+
+// SetAdoption sets status.adoption
+func (in *Dashboard) SetAdoption(adoption *SavedObjectAdoption) {
+	in.Status.Adoption = adoption
+}
+
+// SetAdoption sets status.adoption
+func (in *Visualization) SetAdoption(adoption *SavedObjectAdoption) {
+	in.Status.Adoption = adoption
+}
+
+// SetAdoption sets status.adoption
+func (in *Lens) SetAdoption(adoption *SavedObjectAdoption) {
+	in.Status.Adoption = adoption
+}
+
+// SetAdoption sets status.adoption
+func (in *SavedSearch) SetAdoption(adoption *SavedObjectAdoption) {
+	in.Status.Adoption = adoption
+}
+
+// SetAdoption sets status.adoption
+func (in *IndexPattern) SetAdoption(adoption *SavedObjectAdoption) {
+	in.Status.Adoption = adoption
+}
+
+// SetAdoption sets status.adoption
+func (in *CanvasWorkpad) SetAdoption(adoption *SavedObjectAdoption) {
+	in.Status.Adoption = adoption
+}
+
+// SetAdoption sets status.adoption
+func (in *DataView) SetAdoption(adoption *SavedObjectAdoption) {
+	in.Status.Adoption = adoption
+}
+
+// SetLocation sets spec.targetInstance and spec.space
+func (in *Dashboard) SetLocation(location SavedObjectLocation) {
+	in.Spec.TargetConfig = location.TargetInstance
+	in.Spec.Space = location.Space
+}
+
+// SetLocation sets spec.targetInstance and spec.space
+func (in *Visualization) SetLocation(location SavedObjectLocation) {
+	in.Spec.TargetConfig = location.TargetInstance
+	in.Spec.Space = location.Space
+}
+
+// SetLocation sets spec.targetInstance and spec.space
+func (in *Lens) SetLocation(location SavedObjectLocation) {
+	in.Spec.TargetConfig = location.TargetInstance
+	in.Spec.Space = location.Space
+}
+
+// SetLocation sets spec.targetInstance and spec.space
+func (in *SavedSearch) SetLocation(location SavedObjectLocation) {
+	in.Spec.TargetConfig = location.TargetInstance
+	in.Spec.Space = location.Space
+}
+
+// SetLocation sets spec.targetInstance and spec.space
+func (in *IndexPattern) SetLocation(location SavedObjectLocation) {
+	in.Spec.TargetConfig = location.TargetInstance
+	in.Spec.Space = location.Space
+}
+
+// SetLocation sets spec.targetInstance and spec.space
+func (in *CanvasWorkpad) SetLocation(location SavedObjectLocation) {
+	in.Spec.TargetConfig = location.TargetInstance
+	in.Spec.Space = location.Space
+}
+
+// SetLocation sets spec.targetInstance and spec.space
+func (in *DataView) SetLocation(location SavedObjectLocation) {
+	in.Spec.TargetConfig = location.TargetInstance
+	in.Spec.Space = location.Space
+}
+
+// GetDeployedTo returns status.deployedTo
+func (in *Dashboard) GetDeployedTo() *SavedObjectLocation {
+	return in.Status.DeployedTo
+}
+
+// GetDeployedTo returns status.deployedTo
+func (in *Visualization) GetDeployedTo() *SavedObjectLocation {
+	return in.Status.DeployedTo
+}
+
+// GetDeployedTo returns status.deployedTo
+func (in *Lens) GetDeployedTo() *SavedObjectLocation {
+	return in.Status.DeployedTo
+}
+
+// GetDeployedTo returns status.deployedTo
+func (in *SavedSearch) GetDeployedTo() *SavedObjectLocation {
+	return in.Status.DeployedTo
+}
+
+// GetDeployedTo returns status.deployedTo
+func (in *IndexPattern) GetDeployedTo() *SavedObjectLocation {
+	return in.Status.DeployedTo
+}
+
+// GetDeployedTo returns status.deployedTo
+func (in *CanvasWorkpad) GetDeployedTo() *SavedObjectLocation {
+	return in.Status.DeployedTo
+}
+
+// GetDeployedTo returns status.deployedTo
+func (in *DataView) GetDeployedTo() *SavedObjectLocation {
+	return in.Status.DeployedTo
+}
+
+// SetDeployedTo sets status.deployedTo
+func (in *Dashboard) SetDeployedTo(location *SavedObjectLocation) {
+	in.Status.DeployedTo = location
+}
+
+// SetDeployedTo sets status.deployedTo
+func (in *Visualization) SetDeployedTo(location *SavedObjectLocation) {
+	in.Status.DeployedTo = location
+}
+
+// SetDeployedTo sets status.deployedTo
+func (in *Lens) SetDeployedTo(location *SavedObjectLocation) {
+	in.Status.DeployedTo = location
+}
+
+// SetDeployedTo sets status.deployedTo
+func (in *SavedSearch) SetDeployedTo(location *SavedObjectLocation) {
+	in.Status.DeployedTo = location
+}
+
+// SetDeployedTo sets status.deployedTo
+func (in *IndexPattern) SetDeployedTo(location *SavedObjectLocation) {
+	in.Status.DeployedTo = location
+}
+
+// SetDeployedTo sets status.deployedTo
+func (in *CanvasWorkpad) SetDeployedTo(location *SavedObjectLocation) {
+	in.Status.DeployedTo = location
+}
+
+// SetDeployedTo sets status.deployedTo
+func (in *DataView) SetDeployedTo(location *SavedObjectLocation) {
+	in.Status.DeployedTo = location
+}
+
+// GetSavedObjectVersion returns status.savedObjectVersion
+func (in *Dashboard) GetSavedObjectVersion() string {
+	return in.Status.SavedObjectVersion
+}
+
+// GetSavedObjectVersion returns status.savedObjectVersion
+func (in *Visualization) GetSavedObjectVersion() string {
+	return in.Status.SavedObjectVersion
+}
+
+// GetSavedObjectVersion returns status.savedObjectVersion
+func (in *Lens) GetSavedObjectVersion() string {
+	return in.Status.SavedObjectVersion
+}
+
+// GetSavedObjectVersion returns status.savedObjectVersion
+func (in *SavedSearch) GetSavedObjectVersion() string {
+	return in.Status.SavedObjectVersion
+}
+
+// GetSavedObjectVersion returns status.savedObjectVersion
+func (in *IndexPattern) GetSavedObjectVersion() string {
+	return in.Status.SavedObjectVersion
+}
+
+// GetSavedObjectVersion returns status.savedObjectVersion
+func (in *CanvasWorkpad) GetSavedObjectVersion() string {
+	return in.Status.SavedObjectVersion
+}
+
+// GetSavedObjectVersion returns status.savedObjectVersion
+func (in *DataView) GetSavedObjectVersion() string {
+	return in.Status.SavedObjectVersion
+}
+
+// SetSavedObjectVersion sets status.savedObjectVersion
+func (in *Dashboard) SetSavedObjectVersion(version string) {
+	in.Status.SavedObjectVersion = version
+}
+
+// SetSavedObjectVersion sets status.savedObjectVersion
+func (in *Visualization) SetSavedObjectVersion(version string) {
+	in.Status.SavedObjectVersion = version
+}
+
+// SetSavedObjectVersion sets status.savedObjectVersion
+func (in *Lens) SetSavedObjectVersion(version string) {
+	in.Status.SavedObjectVersion = version
+}
+
+// SetSavedObjectVersion sets status.savedObjectVersion
+func (in *SavedSearch) SetSavedObjectVersion(version string) {
+	in.Status.SavedObjectVersion = version
+}
+
+// SetSavedObjectVersion sets status.savedObjectVersion
+func (in *IndexPattern) SetSavedObjectVersion(version string) {
+	in.Status.SavedObjectVersion = version
+}
+
+// SetSavedObjectVersion sets status.savedObjectVersion
+func (in *CanvasWorkpad) SetSavedObjectVersion(version string) {
+	in.Status.SavedObjectVersion = version
+}
+
+// SetSavedObjectVersion sets status.savedObjectVersion
+func (in *DataView) SetSavedObjectVersion(version string) {
+	in.Status.SavedObjectVersion = version
+}
+
+// LocationOf returns the target instance and space obj is deployed to as given by its spec
+func LocationOf(obj SavedObjectResource) SavedObjectLocation {
+	return SavedObjectLocation{TargetInstance: obj.GetTargetConfig(), Space: obj.GetSavedObjectSpec().Space}
+}
+
+// SavedObjectIDOf returns the id of the saved object of obj in Kibana, see SavedObject.ObjectIDFor
+func SavedObjectIDOf(obj SavedObjectResource) string {
+	savedObject := obj.GetSavedObjectSpec()
+	return savedObject.ObjectIDFor(obj.GetNamespace(), obj.GetName())
+}
+
+// GetSharedToSpaces returns status.sharedToSpaces
+func (in *Dashboard) GetSharedToSpaces() []string {
+	return in.Status.SharedToSpaces
+}
+
+// GetSharedToSpaces returns status.sharedToSpaces
+func (in *Visualization) GetSharedToSpaces() []string {
+	return in.Status.SharedToSpaces
+}
+
+// GetSharedToSpaces returns status.sharedToSpaces
+func (in *Lens) GetSharedToSpaces() []string {
+	return in.Status.SharedToSpaces
+}
+
+// GetSharedToSpaces returns status.sharedToSpaces
+func (in *SavedSearch) GetSharedToSpaces() []string {
+	return in.Status.SharedToSpaces
+}
+
+// GetSharedToSpaces returns status.sharedToSpaces
+func (in *IndexPattern) GetSharedToSpaces() []string {
+	return in.Status.SharedToSpaces
+}
+
+// GetSharedToSpaces returns status.sharedToSpaces
+func (in *CanvasWorkpad) GetSharedToSpaces() []string {
+	return in.Status.SharedToSpaces
+}
+
+// GetSharedToSpaces returns status.sharedToSpaces
+func (in *DataView) GetSharedToSpaces() []string {
+	return in.Status.SharedToSpaces
+}
+
+// SetSharedToSpaces sets status.sharedToSpaces
+func (in *Dashboard) SetSharedToSpaces(spaces []string) {
+	in.Status.SharedToSpaces = spaces
+}
+
+// SetSharedToSpaces sets status.sharedToSpaces
+func (in *Visualization) SetSharedToSpaces(spaces []string) {
+	in.Status.SharedToSpaces = spaces
+}
+
+// SetSharedToSpaces sets status.sharedToSpaces
+func (in *Lens) SetSharedToSpaces(spaces []string) {
+	in.Status.SharedToSpaces = spaces
+}
+
+// SetSharedToSpaces sets status.sharedToSpaces
+func (in *SavedSearch) SetSharedToSpaces(spaces []string) {
+	in.Status.SharedToSpaces = spaces
+}
+
+// SetSharedToSpaces sets status.sharedToSpaces
+func (in *IndexPattern) SetSharedToSpaces(spaces []string) {
+	in.Status.SharedToSpaces = spaces
+}
+
+// SetSharedToSpaces sets status.sharedToSpaces
+func (in *CanvasWorkpad) SetSharedToSpaces(spaces []string) {
+	in.Status.SharedToSpaces = spaces
+}
+
+// SetSharedToSpaces sets status.sharedToSpaces
+func (in *DataView) SetSharedToSpaces(spaces []string) {
+	in.Status.SharedToSpaces = spaces
+}
@@ -1,24 +1,269 @@
 package v1alpha1
 
+import (
+	"github.com/google/uuid"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 type SavedObject struct {
-	Space        *string      `json:"space,omitempty"`
-	Body         string       `json:"body"`
+	Space *string `json:"space,omitempty"`
+
+	// ObjectID is the id of the saved object in Kibana, e.g. the UUID of a saved object created in Kibana which is
+	// to be managed without renaming it. Takes precedence over idStrategy.
+	// +kubebuilder:validation:MaxLength=250
+	// +optional
+	ObjectID string `json:"objectId,omitempty"`
+
+	// IDStrategy derives the id of the saved object in Kibana from the resource if objectId is not set. Defaults to
+	// Name.
+	// +optional
+	IDStrategy IDStrategy `json:"idStrategy,omitempty"`
+	// +optional
+	Body string `json:"body,omitempty"`
+	// BodyJSON is the body as structured JSON instead of a string. It is stored as it is and printed readable by
+	// kubectl. Only one of body and bodyJson may be set.
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	BodyJSON *apiextensionsv1.JSON `json:"bodyJson,omitempty"`
+	// BodyFrom reads the body from a ConfigMap or Secret, for bodies too large to be stored in the resource. Only one
+	// of body, bodyJson and bodyFrom may be set.
+	// +optional
+	BodyFrom *BodyFrom `json:"bodyFrom,omitempty"`
+
 	Dependencies []Dependency `json:"dependencies,omitempty"`
+
+	// ConflictPolicy decides what happens if the saved object already exists in Kibana before the resource manages
+	// it. Defaults to Overwrite.
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+
+	// Managed sets the managed flag of the saved object (Kibana 8.10+), the Kibana UI shows it as managed and users
+	// have to save a copy to change it. The saved object is created and updated through the import API then. Ignored
+	// by DataViews.
+	// +optional
+	Managed bool `json:"managed,omitempty"`
+
+	// ManagedNotice appends a notice to the description of the saved object, telling users that it is managed by
+	// the operator and changes made in Kibana are reverted. Ignored by DataViews.
+	// +optional
+	ManagedNotice bool `json:"managedNotice,omitempty"`
+
+	// DeletionPolicy decides whether the operator may delete the saved object to move it when spec.space or
+	// spec.targetInstance changes. Defaults to Retain, such changes are rejected then as they would orphan the saved
+	// object.
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// UpdatePolicy decides what happens if the saved object was changed in Kibana since the operator wrote it last.
+	// Defaults to Overwrite. Ignored by DataViews and managed saved objects.
+	// +optional
+	UpdatePolicy UpdatePolicy `json:"updatePolicy,omitempty"`
+
+	// ShareToSpaces shares the saved object to further spaces, keeping one object visible in all of them instead of
+	// copies. The operator adds the saved object to the listed spaces and removes it from the spaces it shared it to
+	// before and which are no longer listed, spaces it was shared to in Kibana are left untouched. "*" shares it to
+	// all spaces.
+	// +listType=set
+	// +optional
+	ShareToSpaces []string `json:"shareToSpaces,omitempty"`
+
+	// Backup saves the attributes of the saved object in Kibana before the operator overwrites them, so changes made
+	// in Kibana can be recovered. A backup is only taken if the attributes changed since the last one.
+	// +optional
+	Backup *SavedObjectBackup `json:"backup,omitempty"`
+}
+
+// IDStrategy derives the id of a saved object in Kibana from its resource
+// +kubebuilder:validation:Enum=Name;NamespacedName;UUID
+type IDStrategy string
+
+const (
+	// IDStrategyName uses the name of the resource as id
+	IDStrategyName IDStrategy = "Name"
+	// IDStrategyNamespacedName uses <namespace>_<name> as id, so resources of the same name in different namespaces
+	// do not collide in a space. Neither namespaces nor names contain underscores.
+	IDStrategyNamespacedName IDStrategy = "NamespacedName"
+	// IDStrategyUUID uses a UUID derived from the namespace and name of the resource as id, looking like the ids
+	// Kibana generates. The same resource always gets the same UUID.
+	IDStrategyUUID IDStrategy = "UUID"
+)
+
+// savedObjectIDNamespace is the UUID namespace the UUIDs of IDStrategyUUID are derived in
+var savedObjectIDNamespace = uuid.NewSHA1(uuid.NameSpaceDNS, []byte("kibana.eck.github.com"))
+
+// ObjectIDFor returns the id of the saved object of the resource with the given namespace and name in Kibana,
+// spec.objectId if set and derived according to spec.idStrategy otherwise
+func (in *SavedObject) ObjectIDFor(namespace string, name string) string {
+	if in.ObjectID != "" {
+		return in.ObjectID
+	}
+	switch in.IDStrategy {
+	case IDStrategyNamespacedName:
+		return namespace + "_" + name
+	case IDStrategyUUID:
+		return uuid.NewSHA1(savedObjectIDNamespace, []byte(namespace+"/"+name)).String()
+	default:
+		return name
+	}
+}
+
+// SavedObjectBackup configures where backups of a saved object are stored and how many of them are kept
+type SavedObjectBackup struct {
+	// Sink the backups are written to. Defaults to ConfigMap.
+	// +optional
+	Sink BackupSink `json:"sink,omitempty"`
+	// Retention is the number of backups kept, the oldest backup is removed when another one is taken. Defaults to 3.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=3
+	// +optional
+	Retention int32 `json:"retention,omitempty"`
+}
+
+// BackupSink stores backups of saved objects
+// +kubebuilder:validation:Enum=ConfigMap;Annotation
+type BackupSink string
+
+const (
+	// BackupSinkConfigMap writes each backup to a key of the ConfigMap <name>-backup in the namespace of the
+	// resource, which is owned by the resource
+	BackupSinkConfigMap BackupSink = "ConfigMap"
+	// BackupSinkAnnotation keeps the backups in the kibana.eck.github.com/backups annotation of the resource, which
+	// is limited in size, for small saved objects
+	BackupSinkAnnotation BackupSink = "Annotation"
+)
+
+// BodyFrom references a body stored in a ConfigMap or Secret in the namespace of the resource. Exactly one of
+// configMapKeyRef and secretKeyRef has to be set.
+type BodyFrom struct {
+	// ConfigMapKeyRef selects a key of a ConfigMap. Compressed bodies are stored in binaryData.
+	// +optional
+	ConfigMapKeyRef *BodyKeyReference `json:"configMapKeyRef,omitempty"`
+	// SecretKeyRef selects a key of a Secret
+	// +optional
+	SecretKeyRef *BodyKeyReference `json:"secretKeyRef,omitempty"`
+	// Encoding of the stored body, gzip for a gzip compressed body. Defaults to none.
+	// +optional
+	Encoding BodyEncoding `json:"encoding,omitempty"`
+}
+
+// BodyKeyReference selects a key of a ConfigMap or Secret
+type BodyKeyReference struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// BodyEncoding of a body read with bodyFrom
+// +kubebuilder:validation:Enum=none;gzip
+type BodyEncoding string
+
+const (
+	BodyEncodingNone BodyEncoding = "none"
+	BodyEncodingGzip BodyEncoding = "gzip"
+)
+
+// ConflictPolicy for saved objects existing in Kibana before the resource manages them
+// +kubebuilder:validation:Enum=Overwrite;Adopt;Fail
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyOverwrite replaces the existing saved object with the body of the resource
+	ConflictPolicyOverwrite ConflictPolicy = "Overwrite"
+	// ConflictPolicyAdopt keeps the existing saved object, records its attributes in status.adoption and applies the
+	// body of the resource from its next change on
+	ConflictPolicyAdopt ConflictPolicy = "Adopt"
+	// ConflictPolicyFail leaves the existing saved object untouched and reports the conflict in the Ready condition
+	ConflictPolicyFail ConflictPolicy = "Fail"
+)
+
+// DeletionPolicy for saved objects whose space or target instance changes
+// +kubebuilder:validation:Enum=Retain;Delete
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyRetain never deletes the saved object to move it, changes of spec.space and spec.targetInstance
+	// are rejected
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyDelete deletes the saved object from its old space or target instance before it is created in
+	// the new one
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+)
+
+// UpdatePolicy for saved objects changed in Kibana since the operator wrote them last, detected by their version
+// +kubebuilder:validation:Enum=Overwrite;Fail
+type UpdatePolicy string
+
+const (
+	// UpdatePolicyOverwrite reads the current version of the saved object and overwrites the changes with the body
+	// of the resource
+	UpdatePolicyOverwrite UpdatePolicy = "Overwrite"
+	// UpdatePolicyFail leaves the changed saved object untouched and reports the change in the ExternallyModified
+	// condition
+	UpdatePolicyFail UpdatePolicy = "Fail"
+)
+
+// SavedObjectLocation is the target instance and space of a saved object
+type SavedObjectLocation struct {
+	// TargetInstance as given by spec.targetInstance
+	// +optional
+	TargetInstance CommonKibanaConfig `json:"targetInstance,omitempty"`
+	// Space as given by spec.space
+	// +optional
+	Space *string `json:"space,omitempty"`
+}
+
+// SavedObjectAdoption records a saved object adopted from Kibana
+type SavedObjectAdoption struct {
+	// Attributes of the saved object in Kibana when it was adopted, as JSON, for review
+	// +optional
+	Attributes string `json:"attributes,omitempty"`
+	// Generation of the resource when the saved object was adopted, the body is applied from later generations on
+	// +kubebuilder:validation:Format=int64
+	Generation int64 `json:"generation"`
+	// Time the saved object was adopted
+	// +optional
+	Time metav1.Time `json:"time,omitempty"`
 }
 
 type Dependency struct {
 	ObjectType SavedObjectType `json:"type"`
 	Name       string          `json:"name"`
 	Space      *string         `json:"space,omitempty"`
+	// ElasticsearchInstance an elasticsearchIndex dependency is looked up in, defaults to the Elasticsearch
+	// of the operator configuration
+	// +optional
+	ElasticsearchInstance *ElasticsearchInstanceReference `json:"elasticsearchInstance,omitempty"`
+	// ReferenceName adds the dependency to the references of the body under this name, e.g. the panelRefName of a
+	// dashboard panel, replacing a reference of the same name. Ignored for elasticsearchIndex dependencies and
+	// by DataViews.
+	// +optional
+	ReferenceName string `json:"referenceName,omitempty"`
 }
 
-// +kubebuilder:validation:Enum=visualization;dashboard;search;index-pattern;lens
+// +kubebuilder:validation:Enum=visualization;dashboard;search;index-pattern;lens;canvas-workpad;elasticsearchIndex
 type SavedObjectType string
 
+// SavedObjectTypeElasticsearchIndex is a dependency on an index, alias or data stream in Elasticsearch
+// instead of a saved object in Kibana
+const SavedObjectTypeElasticsearchIndex SavedObjectType = "elasticsearchIndex"
+
 func (in *SavedObject) GetSavedObject() SavedObject {
 	return SavedObject{
-		Space:        in.Space,
-		Body:         in.Body,
-		Dependencies: in.Dependencies,
+		Space:          in.Space,
+		ObjectID:       in.ObjectID,
+		IDStrategy:     in.IDStrategy,
+		Body:           in.GetBody(),
+		BodyFrom:       in.BodyFrom,
+		Dependencies:   in.Dependencies,
+		ConflictPolicy: in.ConflictPolicy,
+		Managed:        in.Managed,
+		ManagedNotice:  in.ManagedNotice,
+		DeletionPolicy: in.DeletionPolicy,
+		UpdatePolicy:   in.UpdatePolicy,
+		Backup:         in.Backup,
+		ShareToSpaces:  in.ShareToSpaces,
 	}
 }
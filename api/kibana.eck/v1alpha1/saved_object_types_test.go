@@ -70,6 +70,8 @@ func TestSavedObject_GetSavedObject(t *testing.T) {
 		Dependencies: []Dependency{
 			{ObjectType: "dashboard", Name: "dash-1"},
 		},
+		Managed:       true,
+		ManagedNotice: true,
 	}
 
 	result := original.GetSavedObject()
@@ -85,6 +87,39 @@ func TestSavedObject_GetSavedObject(t *testing.T) {
 	if len(result.Dependencies) != len(original.Dependencies) {
 		t.Error("GetSavedObject should return same Dependencies")
 	}
+
+	if !result.Managed || !result.ManagedNotice {
+		t.Error("GetSavedObject should return same Managed and ManagedNotice")
+	}
+}
+
+func TestSavedObject_ObjectIDFor(t *testing.T) {
+	tests := []struct {
+		name        string
+		savedObject SavedObject
+		want        string
+	}{
+		{name: "default", savedObject: SavedObject{}, want: "overview"},
+		{name: "name", savedObject: SavedObject{IDStrategy: IDStrategyName}, want: "overview"},
+		{name: "namespaced name", savedObject: SavedObject{IDStrategy: IDStrategyNamespacedName}, want: "team-a_overview"},
+		{name: "explicit", savedObject: SavedObject{ObjectID: "7adfa750-4c81-11e8-b3d7-01146121b73d", IDStrategy: IDStrategyUUID}, want: "7adfa750-4c81-11e8-b3d7-01146121b73d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.savedObject.ObjectIDFor("team-a", "overview"); got != tt.want {
+				t.Errorf("ObjectIDFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	uuidStrategy := SavedObject{IDStrategy: IDStrategyUUID}
+	id := uuidStrategy.ObjectIDFor("team-a", "overview")
+	if len(id) != 36 || id != uuidStrategy.ObjectIDFor("team-a", "overview") {
+		t.Errorf("ObjectIDFor() = %q, want a stable UUID", id)
+	}
+	if id == uuidStrategy.ObjectIDFor("team-b", "overview") {
+		t.Error("ObjectIDFor() should derive different UUIDs for different namespaces")
+	}
 }
 
 func TestDependency(t *testing.T) {
@@ -170,3 +205,18 @@ func TestSavedObject_MultipleDependencies(t *testing.T) {
 		}
 	}
 }
+
+func TestDependency_ElasticsearchIndexDeepCopy(t *testing.T) {
+	dep := Dependency{
+		ObjectType:            SavedObjectTypeElasticsearchIndex,
+		Name:                  "logs-onboarding",
+		ElasticsearchInstance: &ElasticsearchInstanceReference{Name: "elasticsearch-quickstart"},
+	}
+
+	copied := dep.DeepCopy()
+	copied.ElasticsearchInstance.Name = "other"
+
+	if dep.ElasticsearchInstance.Name != "elasticsearch-quickstart" {
+		t.Error("DeepCopy should not share the ElasticsearchInstance reference")
+	}
+}
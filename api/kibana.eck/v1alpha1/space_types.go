@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -25,11 +26,44 @@ type SpaceSpec struct {
 	// +optional
 	TargetConfig CommonKibanaConfig `json:"targetInstance,omitempty"`
 
+	// +optional
 	Body string `json:"body,omitempty"`
+
+	// BodyJSON is the body as structured JSON instead of a string. It is stored as it is and printed readable by
+	// kubectl. Only one of body and bodyJson may be set.
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	BodyJSON *apiextensionsv1.JSON `json:"bodyJson,omitempty"`
+
+	// Template copies baseline saved objects into the space once it was created, e.g. for onboarding tenants
+	// +optional
+	Template *SpaceTemplate `json:"template,omitempty"`
+}
+
+// SpaceTemplate selects the saved object resources copied into a new space
+type SpaceTemplate struct {
+	// Selector selects the Dashboards, Visualizations, Lens, SavedSearches, IndexPatterns, CanvasWorkpads and
+	// DataViews in the namespace of the Space whose saved objects are copied, together with the saved objects they
+	// reference. Only resources deployed to the Kibana instance of the Space are copied.
+	// +required
+	Selector metav1.LabelSelector `json:"selector"`
 }
 
 // SpaceStatus defines the observed state of Space
 type SpaceStatus struct {
+	// +kubebuilder:validation:Format=int64
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ConsecutiveFailures counts the reconciles which failed since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// TemplateCopied is set once the saved objects of spec.template were copied into the space, they are not copied
+	// again
+	// +optional
+	TemplateCopied bool `json:"templateCopied,omitempty"`
 }
 
 //+kubebuilder:object:root=true
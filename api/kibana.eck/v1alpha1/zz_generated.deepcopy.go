@@ -21,26 +21,858 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdvancedSettings) DeepCopyInto(out *AdvancedSettings) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvancedSettings.
+func (in *AdvancedSettings) DeepCopy() *AdvancedSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(AdvancedSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AdvancedSettings) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdvancedSettingsList) DeepCopyInto(out *AdvancedSettingsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AdvancedSettings, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvancedSettingsList.
+func (in *AdvancedSettingsList) DeepCopy() *AdvancedSettingsList {
+	if in == nil {
+		return nil
+	}
+	out := new(AdvancedSettingsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AdvancedSettingsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdvancedSettingsSpec) DeepCopyInto(out *AdvancedSettingsSpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.Space != nil {
+		in, out := &in.Space, &out.Space
+		*out = new(string)
+		**out = **in
+	}
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvancedSettingsSpec.
+func (in *AdvancedSettingsSpec) DeepCopy() *AdvancedSettingsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AdvancedSettingsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdvancedSettingsStatus) DeepCopyInto(out *AdvancedSettingsStatus) {
+	*out = *in
+	if in.ManagedKeys != nil {
+		in, out := &in.ManagedKeys, &out.ManagedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvancedSettingsStatus.
+func (in *AdvancedSettingsStatus) DeepCopy() *AdvancedSettingsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AdvancedSettingsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentPolicy) DeepCopyInto(out *AgentPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentPolicy.
+func (in *AgentPolicy) DeepCopy() *AgentPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AgentPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentPolicyList) DeepCopyInto(out *AgentPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AgentPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentPolicyList.
+func (in *AgentPolicyList) DeepCopy() *AgentPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AgentPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentPolicySpec) DeepCopyInto(out *AgentPolicySpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.Space != nil {
+		in, out := &in.Space, &out.Space
+		*out = new(string)
+		**out = **in
+	}
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentPolicySpec.
+func (in *AgentPolicySpec) DeepCopy() *AgentPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentPolicyStatus) DeepCopyInto(out *AgentPolicyStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentPolicyStatus.
+func (in *AgentPolicyStatus) DeepCopy() *AgentPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BodyFrom) DeepCopyInto(out *BodyFrom) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(BodyKeyReference)
+		**out = **in
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(BodyKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BodyFrom.
+func (in *BodyFrom) DeepCopy() *BodyFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(BodyFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BodyKeyReference) DeepCopyInto(out *BodyKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BodyKeyReference.
+func (in *BodyKeyReference) DeepCopy() *BodyKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(BodyKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanvasWorkpad) DeepCopyInto(out *CanvasWorkpad) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanvasWorkpad.
+func (in *CanvasWorkpad) DeepCopy() *CanvasWorkpad {
+	if in == nil {
+		return nil
+	}
+	out := new(CanvasWorkpad)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanvasWorkpad) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanvasWorkpadList) DeepCopyInto(out *CanvasWorkpadList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CanvasWorkpad, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanvasWorkpadList.
+func (in *CanvasWorkpadList) DeepCopy() *CanvasWorkpadList {
+	if in == nil {
+		return nil
+	}
+	out := new(CanvasWorkpadList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CanvasWorkpadList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanvasWorkpadSpec) DeepCopyInto(out *CanvasWorkpadSpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	in.SavedObject.DeepCopyInto(&out.SavedObject)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanvasWorkpadSpec.
+func (in *CanvasWorkpadSpec) DeepCopy() *CanvasWorkpadSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanvasWorkpadSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanvasWorkpadStatus) DeepCopyInto(out *CanvasWorkpadStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		*out = new(SavedObjectAdoption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeployedTo != nil {
+		in, out := &in.DeployedTo, &out.DeployedTo
+		*out = new(SavedObjectLocation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SharedToSpaces != nil {
+		in, out := &in.SharedToSpaces, &out.SharedToSpaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanvasWorkpadStatus.
+func (in *CanvasWorkpadStatus) DeepCopy() *CanvasWorkpadStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanvasWorkpadStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterKibanaInstance) DeepCopyInto(out *ClusterKibanaInstance) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterKibanaInstance.
+func (in *ClusterKibanaInstance) DeepCopy() *ClusterKibanaInstance {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterKibanaInstance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterKibanaInstance) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterKibanaInstanceList) DeepCopyInto(out *ClusterKibanaInstanceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterKibanaInstance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterKibanaInstanceList.
+func (in *ClusterKibanaInstanceList) DeepCopy() *ClusterKibanaInstanceList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterKibanaInstanceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterKibanaInstanceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterKibanaInstanceStatus) DeepCopyInto(out *ClusterKibanaInstanceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterKibanaInstanceStatus.
+func (in *ClusterKibanaInstanceStatus) DeepCopy() *ClusterKibanaInstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterKibanaInstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CommonKibanaConfig) DeepCopyInto(out *CommonKibanaConfig) {
 	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonKibanaConfig.
+func (in *CommonKibanaConfig) DeepCopy() *CommonKibanaConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CommonKibanaConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Dashboard) DeepCopyInto(out *Dashboard) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dashboard.
+func (in *Dashboard) DeepCopy() *Dashboard {
+	if in == nil {
+		return nil
+	}
+	out := new(Dashboard)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Dashboard) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardList) DeepCopyInto(out *DashboardList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Dashboard, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardList.
+func (in *DashboardList) DeepCopy() *DashboardList {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DashboardList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardSpec) DeepCopyInto(out *DashboardSpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	in.SavedObject.DeepCopyInto(&out.SavedObject)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardSpec.
+func (in *DashboardSpec) DeepCopy() *DashboardSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardStatus) DeepCopyInto(out *DashboardStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		*out = new(SavedObjectAdoption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeployedTo != nil {
+		in, out := &in.DeployedTo, &out.DeployedTo
+		*out = new(SavedObjectLocation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SharedToSpaces != nil {
+		in, out := &in.SharedToSpaces, &out.SharedToSpaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardStatus.
+func (in *DashboardStatus) DeepCopy() *DashboardStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataView) DeepCopyInto(out *DataView) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataView.
+func (in *DataView) DeepCopy() *DataView {
+	if in == nil {
+		return nil
+	}
+	out := new(DataView)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataView) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataViewList) DeepCopyInto(out *DataViewList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DataView, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataViewList.
+func (in *DataViewList) DeepCopy() *DataViewList {
+	if in == nil {
+		return nil
+	}
+	out := new(DataViewList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DataViewList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataViewSpec) DeepCopyInto(out *DataViewSpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	in.SavedObject.DeepCopyInto(&out.SavedObject)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataViewSpec.
+func (in *DataViewSpec) DeepCopy() *DataViewSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataViewSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataViewStatus) DeepCopyInto(out *DataViewStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		*out = new(SavedObjectAdoption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeployedTo != nil {
+		in, out := &in.DeployedTo, &out.DeployedTo
+		*out = new(SavedObjectLocation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SharedToSpaces != nil {
+		in, out := &in.SharedToSpaces, &out.SharedToSpaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataViewStatus.
+func (in *DataViewStatus) DeepCopy() *DataViewStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataViewStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Dependency) DeepCopyInto(out *Dependency) {
+	*out = *in
+	if in.Space != nil {
+		in, out := &in.Space, &out.Space
+		*out = new(string)
+		**out = **in
+	}
+	if in.ElasticsearchInstance != nil {
+		in, out := &in.ElasticsearchInstance, &out.ElasticsearchInstance
+		*out = new(ElasticsearchInstanceReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dependency.
+func (in *Dependency) DeepCopy() *Dependency {
+	if in == nil {
+		return nil
+	}
+	out := new(Dependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchInstanceReference) DeepCopyInto(out *ElasticsearchInstanceReference) {
+	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonKibanaConfig.
-func (in *CommonKibanaConfig) DeepCopy() *CommonKibanaConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchInstanceReference.
+func (in *ElasticsearchInstanceReference) DeepCopy() *ElasticsearchInstanceReference {
 	if in == nil {
 		return nil
 	}
-	out := new(CommonKibanaConfig)
+	out := new(ElasticsearchInstanceReference)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Dashboard) DeepCopyInto(out *Dashboard) {
+func (in *IndexPattern) DeepCopyInto(out *IndexPattern) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexPattern.
+func (in *IndexPattern) DeepCopy() *IndexPattern {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexPattern)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IndexPattern) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexPatternList) DeepCopyInto(out *IndexPatternList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IndexPattern, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexPatternList.
+func (in *IndexPatternList) DeepCopy() *IndexPatternList {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexPatternList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IndexPatternList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexPatternSpec) DeepCopyInto(out *IndexPatternSpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	in.SavedObject.DeepCopyInto(&out.SavedObject)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexPatternSpec.
+func (in *IndexPatternSpec) DeepCopy() *IndexPatternSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexPatternSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexPatternStatus) DeepCopyInto(out *IndexPatternStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		*out = new(SavedObjectAdoption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeployedTo != nil {
+		in, out := &in.DeployedTo, &out.DeployedTo
+		*out = new(SavedObjectLocation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SharedToSpaces != nil {
+		in, out := &in.SharedToSpaces, &out.SharedToSpaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexPatternStatus.
+func (in *IndexPatternStatus) DeepCopy() *IndexPatternStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexPatternStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaInstance) DeepCopyInto(out *KibanaInstance) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -48,18 +880,194 @@ func (in *Dashboard) DeepCopyInto(out *Dashboard) {
 	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dashboard.
-func (in *Dashboard) DeepCopy() *Dashboard {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaInstance.
+func (in *KibanaInstance) DeepCopy() *KibanaInstance {
 	if in == nil {
 		return nil
 	}
-	out := new(Dashboard)
+	out := new(KibanaInstance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KibanaInstance) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaInstanceList) DeepCopyInto(out *KibanaInstanceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KibanaInstance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaInstanceList.
+func (in *KibanaInstanceList) DeepCopy() *KibanaInstanceList {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaInstanceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KibanaInstanceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaInstanceStatus) DeepCopyInto(out *KibanaInstanceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaInstanceStatus.
+func (in *KibanaInstanceStatus) DeepCopy() *KibanaInstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaInstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaRawRequest) DeepCopyInto(out *KibanaRawRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaRawRequest.
+func (in *KibanaRawRequest) DeepCopy() *KibanaRawRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaRawRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KibanaRawRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaRawRequestList) DeepCopyInto(out *KibanaRawRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KibanaRawRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaRawRequestList.
+func (in *KibanaRawRequestList) DeepCopy() *KibanaRawRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaRawRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KibanaRawRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaRawRequestSpec) DeepCopyInto(out *KibanaRawRequestSpec) {
+	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.DeletePath != nil {
+		in, out := &in.DeletePath, &out.DeletePath
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaRawRequestSpec.
+func (in *KibanaRawRequestSpec) DeepCopy() *KibanaRawRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaRawRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaRawRequestStatus) DeepCopyInto(out *KibanaRawRequestStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaRawRequestStatus.
+func (in *KibanaRawRequestStatus) DeepCopy() *KibanaRawRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaRawRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Lens) DeepCopyInto(out *Lens) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Lens.
+func (in *Lens) DeepCopy() *Lens {
+	if in == nil {
+		return nil
+	}
+	out := new(Lens)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Dashboard) DeepCopyObject() runtime.Object {
+func (in *Lens) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -67,31 +1075,31 @@ func (in *Dashboard) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DashboardList) DeepCopyInto(out *DashboardList) {
+func (in *LensList) DeepCopyInto(out *LensList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Dashboard, len(*in))
+		*out = make([]Lens, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardList.
-func (in *DashboardList) DeepCopy() *DashboardList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LensList.
+func (in *LensList) DeepCopy() *LensList {
 	if in == nil {
 		return nil
 	}
-	out := new(DashboardList)
+	out := new(LensList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DashboardList) DeepCopyObject() runtime.Object {
+func (in *LensList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -99,58 +1107,80 @@ func (in *DashboardList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DashboardSpec) DeepCopyInto(out *DashboardSpec) {
+func (in *LensSpec) DeepCopyInto(out *LensSpec) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
 	in.SavedObject.DeepCopyInto(&out.SavedObject)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardSpec.
-func (in *DashboardSpec) DeepCopy() *DashboardSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LensSpec.
+func (in *LensSpec) DeepCopy() *LensSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DashboardSpec)
+	out := new(LensSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DashboardStatus) DeepCopyInto(out *DashboardStatus) {
+func (in *LensStatus) DeepCopyInto(out *LensStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		*out = new(SavedObjectAdoption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeployedTo != nil {
+		in, out := &in.DeployedTo, &out.DeployedTo
+		*out = new(SavedObjectLocation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SharedToSpaces != nil {
+		in, out := &in.SharedToSpaces, &out.SharedToSpaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardStatus.
-func (in *DashboardStatus) DeepCopy() *DashboardStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LensStatus.
+func (in *LensStatus) DeepCopy() *LensStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(DashboardStatus)
+	out := new(LensStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataView) DeepCopyInto(out *DataView) {
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataView.
-func (in *DataView) DeepCopy() *DataView {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
 	if in == nil {
 		return nil
 	}
-	out := new(DataView)
+	out := new(MaintenanceWindow)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DataView) DeepCopyObject() runtime.Object {
+func (in *MaintenanceWindow) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -158,31 +1188,31 @@ func (in *DataView) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataViewList) DeepCopyInto(out *DataViewList) {
+func (in *MaintenanceWindowList) DeepCopyInto(out *MaintenanceWindowList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]DataView, len(*in))
+		*out = make([]MaintenanceWindow, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataViewList.
-func (in *DataViewList) DeepCopy() *DataViewList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindowList.
+func (in *MaintenanceWindowList) DeepCopy() *MaintenanceWindowList {
 	if in == nil {
 		return nil
 	}
-	out := new(DataViewList)
+	out := new(MaintenanceWindowList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DataViewList) DeepCopyObject() runtime.Object {
+func (in *MaintenanceWindowList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -190,78 +1220,74 @@ func (in *DataViewList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataViewSpec) DeepCopyInto(out *DataViewSpec) {
+func (in *MaintenanceWindowSpec) DeepCopyInto(out *MaintenanceWindowSpec) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
-	in.SavedObject.DeepCopyInto(&out.SavedObject)
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataViewSpec.
-func (in *DataViewSpec) DeepCopy() *DataViewSpec {
-	if in == nil {
-		return nil
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.Space != nil {
+		in, out := &in.Space, &out.Space
+		*out = new(string)
+		**out = **in
+	}
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
 	}
-	out := new(DataViewSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DataViewStatus) DeepCopyInto(out *DataViewStatus) {
-	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataViewStatus.
-func (in *DataViewStatus) DeepCopy() *DataViewStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindowSpec.
+func (in *MaintenanceWindowSpec) DeepCopy() *MaintenanceWindowSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(DataViewStatus)
+	out := new(MaintenanceWindowSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Dependency) DeepCopyInto(out *Dependency) {
+func (in *MaintenanceWindowStatus) DeepCopyInto(out *MaintenanceWindowStatus) {
 	*out = *in
-	if in.Space != nil {
-		in, out := &in.Space, &out.Space
-		*out = new(string)
-		**out = **in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dependency.
-func (in *Dependency) DeepCopy() *Dependency {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindowStatus.
+func (in *MaintenanceWindowStatus) DeepCopy() *MaintenanceWindowStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Dependency)
+	out := new(MaintenanceWindowStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IndexPattern) DeepCopyInto(out *IndexPattern) {
+func (in *PackagePolicy) DeepCopyInto(out *PackagePolicy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexPattern.
-func (in *IndexPattern) DeepCopy() *IndexPattern {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackagePolicy.
+func (in *PackagePolicy) DeepCopy() *PackagePolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexPattern)
+	out := new(PackagePolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IndexPattern) DeepCopyObject() runtime.Object {
+func (in *PackagePolicy) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -269,31 +1295,31 @@ func (in *IndexPattern) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IndexPatternList) DeepCopyInto(out *IndexPatternList) {
+func (in *PackagePolicyList) DeepCopyInto(out *PackagePolicyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]IndexPattern, len(*in))
+		*out = make([]PackagePolicy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexPatternList.
-func (in *IndexPatternList) DeepCopy() *IndexPatternList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackagePolicyList.
+func (in *PackagePolicyList) DeepCopy() *PackagePolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexPatternList)
+	out := new(PackagePolicyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IndexPatternList) DeepCopyObject() runtime.Object {
+func (in *PackagePolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -301,58 +1327,79 @@ func (in *IndexPatternList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IndexPatternSpec) DeepCopyInto(out *IndexPatternSpec) {
+func (in *PackagePolicySpec) DeepCopyInto(out *PackagePolicySpec) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
-	in.SavedObject.DeepCopyInto(&out.SavedObject)
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.Space != nil {
+		in, out := &in.Space, &out.Space
+		*out = new(string)
+		**out = **in
+	}
+	if in.AgentPolicies != nil {
+		in, out := &in.AgentPolicies, &out.AgentPolicies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexPatternSpec.
-func (in *IndexPatternSpec) DeepCopy() *IndexPatternSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackagePolicySpec.
+func (in *PackagePolicySpec) DeepCopy() *PackagePolicySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexPatternSpec)
+	out := new(PackagePolicySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IndexPatternStatus) DeepCopyInto(out *IndexPatternStatus) {
+func (in *PackagePolicyStatus) DeepCopyInto(out *PackagePolicyStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexPatternStatus.
-func (in *IndexPatternStatus) DeepCopy() *IndexPatternStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackagePolicyStatus.
+func (in *PackagePolicyStatus) DeepCopy() *PackagePolicyStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexPatternStatus)
+	out := new(PackagePolicyStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KibanaInstance) DeepCopyInto(out *KibanaInstance) {
+func (in *ReportingJob) DeepCopyInto(out *ReportingJob) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaInstance.
-func (in *KibanaInstance) DeepCopy() *KibanaInstance {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportingJob.
+func (in *ReportingJob) DeepCopy() *ReportingJob {
 	if in == nil {
 		return nil
 	}
-	out := new(KibanaInstance)
+	out := new(ReportingJob)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KibanaInstance) DeepCopyObject() runtime.Object {
+func (in *ReportingJob) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -360,31 +1407,31 @@ func (in *KibanaInstance) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KibanaInstanceList) DeepCopyInto(out *KibanaInstanceList) {
+func (in *ReportingJobList) DeepCopyInto(out *ReportingJobList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]KibanaInstance, len(*in))
+		*out = make([]ReportingJob, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaInstanceList.
-func (in *KibanaInstanceList) DeepCopy() *KibanaInstanceList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportingJobList.
+func (in *ReportingJobList) DeepCopy() *ReportingJobList {
 	if in == nil {
 		return nil
 	}
-	out := new(KibanaInstanceList)
+	out := new(ReportingJobList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KibanaInstanceList) DeepCopyObject() runtime.Object {
+func (in *ReportingJobList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -392,134 +1439,175 @@ func (in *KibanaInstanceList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KibanaInstanceStatus) DeepCopyInto(out *KibanaInstanceStatus) {
+func (in *ReportingJobSpec) DeepCopyInto(out *ReportingJobSpec) {
 	*out = *in
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.Space != nil {
+		in, out := &in.Space, &out.Space
+		*out = new(string)
+		**out = **in
+	}
+	if in.TimeRange != nil {
+		in, out := &in.TimeRange, &out.TimeRange
+		*out = new(ReportingTimeRange)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaInstanceStatus.
-func (in *KibanaInstanceStatus) DeepCopy() *KibanaInstanceStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportingJobSpec.
+func (in *ReportingJobSpec) DeepCopy() *ReportingJobSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(KibanaInstanceStatus)
+	out := new(ReportingJobSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Lens) DeepCopyInto(out *Lens) {
+func (in *ReportingJobStatus) DeepCopyInto(out *ReportingJobStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Lens.
-func (in *Lens) DeepCopy() *Lens {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportingJobStatus.
+func (in *ReportingJobStatus) DeepCopy() *ReportingJobStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Lens)
+	out := new(ReportingJobStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Lens) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReportingTimeRange) DeepCopyInto(out *ReportingTimeRange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReportingTimeRange.
+func (in *ReportingTimeRange) DeepCopy() *ReportingTimeRange {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(ReportingTimeRange)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LensList) DeepCopyInto(out *LensList) {
+func (in *SavedObject) DeepCopyInto(out *SavedObject) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Lens, len(*in))
+	if in.Space != nil {
+		in, out := &in.Space, &out.Space
+		*out = new(string)
+		**out = **in
+	}
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BodyFrom != nil {
+		in, out := &in.BodyFrom, &out.BodyFrom
+		*out = new(BodyFrom)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Dependencies != nil {
+		in, out := &in.Dependencies, &out.Dependencies
+		*out = make([]Dependency, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(SavedObjectBackup)
+		**out = **in
+	}
+	if in.ShareToSpaces != nil {
+		in, out := &in.ShareToSpaces, &out.ShareToSpaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LensList.
-func (in *LensList) DeepCopy() *LensList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavedObject.
+func (in *SavedObject) DeepCopy() *SavedObject {
 	if in == nil {
 		return nil
 	}
-	out := new(LensList)
+	out := new(SavedObject)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LensList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LensSpec) DeepCopyInto(out *LensSpec) {
+func (in *SavedObjectAdoption) DeepCopyInto(out *SavedObjectAdoption) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
-	in.SavedObject.DeepCopyInto(&out.SavedObject)
+	in.Time.DeepCopyInto(&out.Time)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LensSpec.
-func (in *LensSpec) DeepCopy() *LensSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavedObjectAdoption.
+func (in *SavedObjectAdoption) DeepCopy() *SavedObjectAdoption {
 	if in == nil {
 		return nil
 	}
-	out := new(LensSpec)
+	out := new(SavedObjectAdoption)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LensStatus) DeepCopyInto(out *LensStatus) {
+func (in *SavedObjectBackup) DeepCopyInto(out *SavedObjectBackup) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LensStatus.
-func (in *LensStatus) DeepCopy() *LensStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavedObjectBackup.
+func (in *SavedObjectBackup) DeepCopy() *SavedObjectBackup {
 	if in == nil {
 		return nil
 	}
-	out := new(LensStatus)
+	out := new(SavedObjectBackup)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SavedObject) DeepCopyInto(out *SavedObject) {
+func (in *SavedObjectLocation) DeepCopyInto(out *SavedObjectLocation) {
 	*out = *in
+	in.TargetInstance.DeepCopyInto(&out.TargetInstance)
 	if in.Space != nil {
 		in, out := &in.Space, &out.Space
 		*out = new(string)
 		**out = **in
 	}
-	if in.Dependencies != nil {
-		in, out := &in.Dependencies, &out.Dependencies
-		*out = make([]Dependency, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavedObject.
-func (in *SavedObject) DeepCopy() *SavedObject {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavedObjectLocation.
+func (in *SavedObjectLocation) DeepCopy() *SavedObjectLocation {
 	if in == nil {
 		return nil
 	}
-	out := new(SavedObject)
+	out := new(SavedObjectLocation)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -530,7 +1618,7 @@ func (in *SavedSearch) DeepCopyInto(out *SavedSearch) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavedSearch.
@@ -586,7 +1674,7 @@ func (in *SavedSearchList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SavedSearchSpec) DeepCopyInto(out *SavedSearchSpec) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
 	in.SavedObject.DeepCopyInto(&out.SavedObject)
 }
 
@@ -603,6 +1691,28 @@ func (in *SavedSearchSpec) DeepCopy() *SavedSearchSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SavedSearchStatus) DeepCopyInto(out *SavedSearchStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		*out = new(SavedObjectAdoption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeployedTo != nil {
+		in, out := &in.DeployedTo, &out.DeployedTo
+		*out = new(SavedObjectLocation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SharedToSpaces != nil {
+		in, out := &in.SharedToSpaces, &out.SharedToSpaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavedSearchStatus.
@@ -620,8 +1730,8 @@ func (in *Space) DeepCopyInto(out *Space) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Space.
@@ -677,7 +1787,17 @@ func (in *SpaceList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SpaceSpec) DeepCopyInto(out *SpaceSpec) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
+	if in.BodyJSON != nil {
+		in, out := &in.BodyJSON, &out.BodyJSON
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(SpaceTemplate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpaceSpec.
@@ -693,6 +1813,13 @@ func (in *SpaceSpec) DeepCopy() *SpaceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SpaceStatus) DeepCopyInto(out *SpaceStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpaceStatus.
@@ -705,13 +1832,29 @@ func (in *SpaceStatus) DeepCopy() *SpaceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpaceTemplate) DeepCopyInto(out *SpaceTemplate) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpaceTemplate.
+func (in *SpaceTemplate) DeepCopy() *SpaceTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(SpaceTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Visualization) DeepCopyInto(out *Visualization) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Visualization.
@@ -767,7 +1910,7 @@ func (in *VisualizationList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VisualizationSpec) DeepCopyInto(out *VisualizationSpec) {
 	*out = *in
-	out.TargetConfig = in.TargetConfig
+	in.TargetConfig.DeepCopyInto(&out.TargetConfig)
 	in.SavedObject.DeepCopyInto(&out.SavedObject)
 }
 
@@ -784,6 +1927,28 @@ func (in *VisualizationSpec) DeepCopy() *VisualizationSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VisualizationStatus) DeepCopyInto(out *VisualizationStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		*out = new(SavedObjectAdoption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeployedTo != nil {
+		in, out := &in.DeployedTo, &out.DeployedTo
+		*out = new(SavedObjectLocation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SharedToSpaces != nil {
+		in, out := &in.SharedToSpaces, &out.SharedToSpaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VisualizationStatus.
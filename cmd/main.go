@@ -17,9 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"slices"
@@ -36,6 +38,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -44,11 +47,16 @@ import (
 
 	configv2 "eck-custom-resources/api/config/v2"
 	"eck-custom-resources/internal/config"
+	"eck-custom-resources/internal/diff"
+	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
 
 	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
 	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
 	eseckcontroller "eck-custom-resources/internal/controller/es.eck"
 	kibanaeckcontroller "eck-custom-resources/internal/controller/kibana.eck"
+	webhookeseckv1alpha1 "eck-custom-resources/internal/webhook/es.eck/v1alpha1"
+	webhookkibanaeckv1alpha1 "eck-custom-resources/internal/webhook/kibana.eck/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -96,18 +104,55 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var enableWebhooks bool
 	var tlsOpts []func(*tls.Config)
 	var configFile string
+	var configReloadInterval time.Duration
 	var syncPeriod int
+	var controllerLogLevels string
 	var namespaces = Namespaces{}
+	var diffMode bool
+	var diffConfigMap string
+	var apikeySecretFormats string
 	flag.StringVar(&configFile, "config", "",
 		"The controller will load its initial configuration from this file. "+
 			"Omit this flag to use the default configuration values. "+
 			"Command-line flags override configuration from this file.")
+	flag.DurationVar(&configReloadInterval, "config-reload-interval", 10*time.Second,
+		"How often the --config file is checked for changes, which are applied without restarting. 0 disables reloading.")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.IntVar(&syncPeriod, "sync-period", 10, "The period between reconciles.")
+	flag.Float64Var(&utils.RequeueJitterFactor, "requeue-jitter", utils.RequeueJitterFactor,
+		"Maximum fraction by which requeue intervals are extended, spreading the retries of resources failing at the same time.")
+	flag.Int64Var(&utils.ListPageSize, "list-page-size", utils.ListPageSize,
+		"Number of items requested per page when listing resources from the API server.")
+	flag.DurationVar(&utils.ReconcileStalledAfter, "reconcile-stalled-after", utils.ReconcileStalledAfter,
+		"How long a resource may go without converging before it is reported by the eck_cr_reconcile_stalled metric.")
+	flag.IntVar(&utils.CircuitBreakerFailureThreshold, "circuit-breaker-failure-threshold", utils.CircuitBreakerFailureThreshold,
+		"Number of consecutive failed requests after which reconciles against a target instance are paused.")
+	flag.DurationVar(&utils.CircuitBreakerProbeInterval, "circuit-breaker-probe-interval", utils.CircuitBreakerProbeInterval,
+		"How often an unavailable target instance is probed for recovery.")
+	flag.IntVar(&utils.PersistentFailureThreshold, "persistent-failure-threshold", utils.PersistentFailureThreshold,
+		"Number of consecutive failed reconciles after which a resource is Degraded and only retried at its resync. 0 disables it.")
+	flag.DurationVar(&utils.ShutdownGracePeriod, "shutdown-grace-period", utils.ShutdownGracePeriod,
+		"How long reconciles in flight when the operator is stopped may continue before they are cancelled.")
+	flag.StringVar((*string)(&esutils.SecurityRefresh), "security-refresh", "",
+		"Refresh strategy of writes to Elasticsearch users and roles not setting spec.refresh: true, wait_for or false. "+
+			"Defaults to the one of Elasticsearch, true.")
+	flag.IntVar(&esutils.SecurityRefreshBatchSize, "security-refresh-batch-size", esutils.SecurityRefreshBatchSize,
+		"Only every n-th write to users and roles of an instance refreshes the security index, the others are sent with "+
+			"refresh=false. 0 and 1 refresh on every write.")
+	flag.StringVar(&apikeySecretFormats, "apikey-secret-formats", "",
+		"Comma separated secret formats the API key is added to the Secrets of ElasticsearchApikeys not setting "+
+			"spec.secretFormats in: Env, BeatsOutput or AuthorizationHeader.")
+	flag.StringVar(&controllerLogLevels, "controller-log-levels", "",
+		"Log levels of the controllers of single kinds, e.g. Index=debug,Dashboard=2. Others log at --zap-log-level.")
+	flag.StringVar(&utils.OperatorID, "operator-id", "",
+		"ID of the operator installation. It only reconciles the custom resources labeled "+utils.OperatorIDLabel+
+			" with the ID and claims unlabeled ones, and elects its leader independently of other installations. "+
+			"Without ID only unlabeled custom resources are reconciled.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -123,13 +168,45 @@ func main() {
 	flag.Var(&namespaces, "watch-namespaces", "Namespaces the operator should watch.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"If set, the validating admission webhooks are served. "+
+			"Requires a webhook certificate, see --webhook-cert-path.")
+	flag.BoolVar(&diffMode, "diff", false,
+		"If set, the operator does not reconcile but writes a JSON report of the changes it would make to Elasticsearch "+
+			"and Kibana to stdout, then exits.")
+	flag.StringVar(&diffConfigMap, "diff-configmap", "",
+		"Namespace and name of a ConfigMap the --diff report is written to instead of stdout, e.g. eck/diff-report.")
 	opts := zap.Options{
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
-	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	logLevelsErr := utils.ConfigureLogLevels(&opts, controllerLogLevels)
+	ctrl.SetLogger(utils.FilterLogLevel(zap.New(zap.UseFlagOptions(&opts))))
+	if logLevelsErr != nil {
+		setupLog.Error(logLevelsErr, "invalid --controller-log-levels")
+		os.Exit(1)
+	}
+	if err := esutils.ValidateSecurityRefresh(esutils.SecurityRefresh); err != nil {
+		setupLog.Error(err, "invalid --security-refresh")
+		os.Exit(1)
+	}
+	if formats, err := esutils.ParseApikeySecretFormats(apikeySecretFormats); err != nil {
+		setupLog.Error(err, "invalid --apikey-secret-formats")
+		os.Exit(1)
+	} else {
+		esutils.ApikeySecretFormats = formats
+	}
+	if err := utils.ValidateOperatorID(utils.OperatorID); err != nil {
+		setupLog.Error(err, "invalid --operator-id")
+		os.Exit(1)
+	}
+	shutdownTracing, err := utils.SetupTracing(context.Background())
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
 
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
@@ -150,6 +227,8 @@ func main() {
 	if err != nil {
 		setupLog.Error(err, "Failed to load ProjectConfigSpec")
 	}
+	applyProjectConfig(ctrlConfig)
+	configStore := config.NewStore(ctrlConfig)
 
 	if len(namespaces.value) == 0 {
 		// read namespace from service account
@@ -168,6 +247,15 @@ func main() {
 		setupLog.Info(fmt.Sprintf("Watch namespaces: %v", namespaces))
 	}
 
+	if utils.OperatorID != "" {
+		setupLog.Info(fmt.Sprintf("Operator ID: %s", utils.OperatorID))
+	}
+
+	if diffMode {
+		runDiff(ctrlConfig, namespaces.value, diffConfigMap)
+		return
+	}
+
 	cacheNamespace := map[string]cache.Config{}
 	for _, ns := range namespaces.value {
 		cacheNamespace[ns] = cache.Config{}
@@ -210,6 +298,8 @@ func main() {
 		BindAddress:   metricsAddr,
 		SecureServing: secureMetrics,
 		TLSOpts:       tlsOpts,
+		// the log levels of the controllers can be changed at runtime, see docs/logging.md
+		ExtraHandlers: map[string]http.Handler{"/log-levels": utils.LogLevelsHandler()},
 	}
 
 	if secureMetrics {
@@ -248,17 +338,22 @@ func main() {
 	}
 
 	d := time.Duration(syncPeriod) * time.Hour
+	// converged resources are resynced at their own offset within the sync window instead of all at once
+	utils.ResyncPeriod = d
+	// the manager waits for the reconciles drained during the shutdown grace period before it returns
+	gracefulShutdownTimeout := utils.ShutdownGracePeriod + 5*time.Second
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "5da2fcc2.github.com",
+		LeaderElectionID:       utils.LeaderElectionID("5da2fcc2.github.com"),
 		Cache: cache.Options{
 			SyncPeriod:        &d, // periodic resync for all watched kinds
 			DefaultNamespaces: cacheNamespace,
 		},
+		GracefulShutdownTimeout: &gracefulShutdownTimeout,
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -277,168 +372,376 @@ func main() {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
-	if err = (&eseckcontroller.IndexReconciler{
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.Index{}, (&eseckcontroller.IndexReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("index_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Index")
 		os.Exit(1)
 	}
-	if err = (&eseckcontroller.IndexTemplateReconciler{
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.IndexTemplate{}, (&eseckcontroller.IndexTemplateReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("indextemplate_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "IndexTemplate")
 		os.Exit(1)
 	}
-	if err = (&eseckcontroller.IndexLifecyclePolicyReconciler{
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.IndexLifecyclePolicy{}, (&eseckcontroller.IndexLifecyclePolicyReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("indexlifecyclepolicy_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "IndexLifecyclePolicy")
 		os.Exit(1)
 	}
-	if err = (&eseckcontroller.SnapshotLifecyclePolicyReconciler{
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.SnapshotLifecyclePolicy{}, (&eseckcontroller.SnapshotLifecyclePolicyReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("snapshotlifecyclepolicy_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SnapshotLifecyclePolicy")
 		os.Exit(1)
 	}
-	if err = (&eseckcontroller.IngestPipelineReconciler{
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.IngestPipeline{}, (&eseckcontroller.IngestPipelineReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("ingestpipeline_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "IngestPipeline")
 		os.Exit(1)
 	}
-	if err = (&eseckcontroller.SnapshotRepositoryReconciler{
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.SnapshotRepository{}, (&eseckcontroller.SnapshotRepositoryReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("snapshotrepository_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SnapshotRepository")
 		os.Exit(1)
 	}
-	if err = (&kibanaeckcontroller.SavedSearchReconciler{
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.SnapshotRestore{}, (&eseckcontroller.SnapshotRestoreReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("snapshotrestore_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SnapshotRestore")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.IndexOperation{}, (&eseckcontroller.IndexOperationReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("indexoperation_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "IndexOperation")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.ConnectionTest{}, (&eseckcontroller.ConnectionTestReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("connectiontest_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ConnectionTest")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.FollowerIndex{}, (&eseckcontroller.FollowerIndexReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("followerindex_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "FollowerIndex")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.AutoFollowPattern{}, (&eseckcontroller.AutoFollowPatternReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("autofollowpattern_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "AutoFollowPattern")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.EckResourceQuota{}, (&eseckcontroller.EckResourceQuotaReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("eckresourcequota_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "EckResourceQuota")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &kibanaeckv1alpha1.SavedSearch{}, (&kibanaeckcontroller.SavedSearchReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("savedsearch_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SavedSearch")
 		os.Exit(1)
 	}
-	if err = (&kibanaeckcontroller.IndexPatternReconciler{
+	if err = utils.SetupIfInstalled(mgr, &kibanaeckv1alpha1.IndexPattern{}, (&kibanaeckcontroller.IndexPatternReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("indexpattern_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "IndexPattern")
 		os.Exit(1)
 	}
-	if err = (&kibanaeckcontroller.VisualizationReconciler{
+	if err = utils.SetupIfInstalled(mgr, &kibanaeckv1alpha1.Visualization{}, (&kibanaeckcontroller.VisualizationReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("visualization_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Visualization")
 		os.Exit(1)
 	}
-	if err = (&kibanaeckcontroller.DashboardReconciler{
+	if err = utils.SetupIfInstalled(mgr, &kibanaeckv1alpha1.Dashboard{}, (&kibanaeckcontroller.DashboardReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("dashboard_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Dashboard")
 		os.Exit(1)
 	}
-	if err = (&eseckcontroller.ElasticsearchRoleReconciler{
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.ElasticsearchRole{}, (&eseckcontroller.ElasticsearchRoleReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("elasticsearchrole_controller"),
-	}).SetupWithManager(mgr); err != nil {
+		RestConfig:    mgr.GetConfig(),
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ElasticsearchRole")
 		os.Exit(1)
 	}
-	if err = (&eseckcontroller.ElasticsearchUserReconciler{
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.ElasticsearchUser{}, (&eseckcontroller.ElasticsearchUserReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("elasticsearchuser_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ElasticsearchUser")
 		os.Exit(1)
 	}
-	if err = (&eseckcontroller.ElasticsearchApikeyReconciler{
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.ElasticsearchApikey{}, (&eseckcontroller.ElasticsearchApikeyReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("elasticsearchapikey_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ElasticsearchApikey")
 		os.Exit(1)
 	}
-	if err = (&kibanaeckcontroller.SpaceReconciler{
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.ElasticsearchApikey{}, (&eseckcontroller.ElasticsearchApikeyConsumerReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("elasticsearchapikeyconsumer_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ElasticsearchApikeyConsumer")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &kibanaeckv1alpha1.Space{}, (&kibanaeckcontroller.SpaceReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("kibanaspace_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Space")
 		os.Exit(1)
 	}
-	if err = (&kibanaeckcontroller.LensReconciler{
+	if err = utils.SetupIfInstalled(mgr, &kibanaeckv1alpha1.Lens{}, (&kibanaeckcontroller.LensReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("kibanalens_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Lens")
 		os.Exit(1)
 	}
-	if err = (&kibanaeckcontroller.DataViewReconciler{
+	if err = utils.SetupIfInstalled(mgr, &kibanaeckv1alpha1.DataView{}, (&kibanaeckcontroller.DataViewReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("kibanadataview_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DataView")
 		os.Exit(1)
 	}
-	if err = (&eseckcontroller.ComponentTemplateReconciler{
+	if err = utils.SetupIfInstalled(mgr, &kibanaeckv1alpha1.CanvasWorkpad{}, (&kibanaeckcontroller.CanvasWorkpadReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("kibanacanvasworkpad_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CanvasWorkpad")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &kibanaeckv1alpha1.MaintenanceWindow{}, (&kibanaeckcontroller.MaintenanceWindowReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("kibanamaintenancewindow_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MaintenanceWindow")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &kibanaeckv1alpha1.AgentPolicy{}, (&kibanaeckcontroller.AgentPolicyReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("kibanaagentpolicy_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "AgentPolicy")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &kibanaeckv1alpha1.PackagePolicy{}, (&kibanaeckcontroller.PackagePolicyReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("kibanapackagepolicy_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PackagePolicy")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &kibanaeckv1alpha1.AdvancedSettings{}, (&kibanaeckcontroller.AdvancedSettingsReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("kibanaadvancedsettings_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "AdvancedSettings")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &kibanaeckv1alpha1.ReportingJob{}, (&kibanaeckcontroller.ReportingJobReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("kibanareportingjob_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ReportingJob")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.ComponentTemplate{}, (&eseckcontroller.ComponentTemplateReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("componenttemplate_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ComponentTemplate")
 		os.Exit(1)
 	}
-	if err := (&eseckcontroller.ResourceTemplateDataReconciler{
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.ResourceTemplateData{}, (&eseckcontroller.ResourceTemplateDataReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		ProjectConfig: ctrlConfig,
+		ProjectConfig: configStore,
 		Recorder:      mgr.GetEventRecorderFor("resourcetemplatedata_controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ResourceTemplateData")
 		os.Exit(1)
 	}
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.EnvironmentOverlay{}, (&eseckcontroller.EnvironmentOverlayReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("environmentoverlay_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "EnvironmentOverlay")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.ElasticsearchRawRequest{}, (&eseckcontroller.ElasticsearchRawRequestReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("elasticsearchrawrequest_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ElasticsearchRawRequest")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &kibanaeckv1alpha1.KibanaRawRequest{}, (&kibanaeckcontroller.KibanaRawRequestReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("kibanarawrequest_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KibanaRawRequest")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.QueryRuleset{}, (&eseckcontroller.QueryRulesetReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("queryruleset_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "QueryRuleset")
+		os.Exit(1)
+	}
+	if err = utils.SetupIfInstalled(mgr, &eseckv1alpha1.SynonymSet{}, (&eseckcontroller.SynonymSetReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ProjectConfig: configStore,
+		Recorder:      mgr.GetEventRecorderFor("synonymset_controller"),
+	}).SetupWithManager); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SynonymSet")
+		os.Exit(1)
+	}
+	if err = (&eseckcontroller.NamespaceCleanupReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("namespacecleanup_controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NamespaceCleanup")
+		os.Exit(1)
+	}
+	if enableWebhooks {
+		if err := webhookeseckv1alpha1.SetupIndexWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Index")
+			os.Exit(1)
+		}
+		if err := webhookeseckv1alpha1.SetupElasticsearchApikeyWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ElasticsearchApikey")
+			os.Exit(1)
+		}
+		if err := webhookkibanaeckv1alpha1.SetupDashboardWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Dashboard")
+			os.Exit(1)
+		}
+		if err := webhookkibanaeckv1alpha1.SetupLensWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Lens")
+			os.Exit(1)
+		}
+		if err := webhookkibanaeckv1alpha1.SetupDataViewWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DataView")
+			os.Exit(1)
+		}
+		if err := webhookkibanaeckv1alpha1.SetupSpaceWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Space")
+			os.Exit(1)
+		}
+		if err := webhookeseckv1alpha1.SetupEckResourceQuotaWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "EckResourceQuota")
+			os.Exit(1)
+		}
+		if err := webhookeseckv1alpha1.SetupBodyWebhooksWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "es.eck body")
+			os.Exit(1)
+		}
+		if err := webhookkibanaeckv1alpha1.SetupBodyWebhooksWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "kibana.eck body")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -457,6 +760,38 @@ func main() {
 		}
 	}
 
+	if err := (&utils.CertificateReloader{}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CertificateReloader")
+		os.Exit(1)
+	}
+
+	if err := mgr.Add(&esutils.ApikeySecretOwnerMigration{Client: mgr.GetClient()}); err != nil {
+		setupLog.Error(err, "unable to add the API key Secret owner migration to manager")
+		os.Exit(1)
+	}
+
+	if configFile != "" && configReloadInterval > 0 {
+		// Events about reloads are recorded on the Pod of the operator, they are dropped outside of a cluster. The Pod
+		// name is given by the downward API, the hostname of the Pod is its name unless set otherwise.
+		operatorNamespace, _ := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
+		operatorPod := os.Getenv("POD_NAME")
+		if operatorPod == "" {
+			operatorPod, _ = os.Hostname()
+		}
+		if err := mgr.Add(&config.Reloader{
+			Path:      configFile,
+			Interval:  configReloadInterval,
+			Store:     configStore,
+			Apply:     applyProjectConfig,
+			Recorder:  mgr.GetEventRecorderFor("config_reloader"),
+			Namespace: string(operatorNamespace),
+			Pod:       operatorPod,
+		}); err != nil {
+			setupLog.Error(err, "unable to add the configuration reloader to manager")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -471,6 +806,55 @@ func main() {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+	if err := shutdownTracing(context.Background()); err != nil {
+		setupLog.Error(err, "unable to flush the pending spans")
+	}
+}
+
+// applyProjectConfig applies the settings of the ProjectConfigSpec which are kept outside of the reconcilers
+func applyProjectConfig(ctrlConfig configv2.ProjectConfigSpec) {
+	utils.SetNamingPolicy(ctrlConfig.Naming)
+	utils.SetLintRules(ctrlConfig.Lint)
+	utils.SetInstanceOverrides(ctrlConfig.ElasticsearchInstances, ctrlConfig.KibanaInstances)
+}
+
+// runDiff writes the report of the changes the operator would make to stdout or the ConfigMap given as
+// <namespace>/<name>
+func runDiff(ctrlConfig configv2.ProjectConfigSpec, namespaces []string, configMap string) {
+	restConfig := ctrl.GetConfigOrDie()
+	cli, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		os.Exit(1)
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+	report, err := (&diff.Reporter{
+		Client:        cli,
+		RestConfig:    restConfig,
+		ProjectConfig: ctrlConfig,
+		Namespaces:    namespaces,
+	}).Run(ctx)
+	if err != nil {
+		setupLog.Error(err, "unable to build the diff report")
+		os.Exit(1)
+	}
+
+	if configMap == "" {
+		err = report.Write(os.Stdout)
+	} else {
+		namespace, name, found := strings.Cut(configMap, "/")
+		if !found {
+			setupLog.Error(nil, "--diff-configmap has to be given as <namespace>/<name>", "diff-configmap", configMap)
+			os.Exit(1)
+		}
+		err = report.WriteConfigMap(ctx, cli, client.ObjectKey{Namespace: namespace, Name: name})
+	}
+	if err != nil {
+		setupLog.Error(err, "unable to write the diff report")
+		os.Exit(1)
+	}
+	setupLog.Info("Wrote the diff report", "summary", report.Summary)
 }
 
 func fatal(err error, debug bool) {
@@ -9,6 +9,7 @@ import (
 	"sigs.k8s.io/yaml"
 	//"gopkg.in/yaml.v3"
 	appv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/utils"
 )
 
 func defaultSpec() appv2.ProjectConfigSpec {
@@ -49,7 +50,13 @@ func Validate(spec *appv2.ProjectConfigSpec) error {
 	if spec.Kibana.Url == "" {
 		return errors.New("kibana.url is required")
 	}
-	return nil
+	if err := utils.ValidateNamingPolicy(spec.Naming); err != nil {
+		return err
+	}
+	if err := utils.ValidateLintRules(spec.Lint); err != nil {
+		return err
+	}
+	return utils.ValidateRawRequestPolicy(spec.RawRequests)
 }
 
 func LoadProjectConfigSpec(path string) (appv2.ProjectConfigSpec, error) {
@@ -98,6 +98,48 @@ func TestValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "elasticsearch.endpoint is required", // First error returned
 		},
+		{
+			name: "naming pattern without name",
+			spec: appv2.ProjectConfigSpec{
+				Elasticsearch: appv2.ElasticsearchSpec{
+					Url: "https://elasticsearch.example.com",
+				},
+				Kibana: appv2.KibanaSpec{
+					Url: "https://kibana.example.com",
+				},
+				Naming: &appv2.NamingPolicy{Pattern: "{namespace}"},
+			},
+			wantErr: true,
+			errMsg:  "naming.pattern has to contain {name}",
+		},
+		{
+			name: "lint rule without name",
+			spec: appv2.ProjectConfigSpec{
+				Elasticsearch: appv2.ElasticsearchSpec{
+					Url: "https://elasticsearch.example.com",
+				},
+				Kibana: appv2.KibanaSpec{
+					Url: "https://kibana.example.com",
+				},
+				Lint: []appv2.LintRule{{Path: "$.settings", Operator: appv2.LintOperatorExists}},
+			},
+			wantErr: true,
+			errMsg:  "lint[0].name is required",
+		},
+		{
+			name: "relative raw request prefix",
+			spec: appv2.ProjectConfigSpec{
+				Elasticsearch: appv2.ElasticsearchSpec{
+					Url: "https://elasticsearch.example.com",
+				},
+				Kibana: appv2.KibanaSpec{
+					Url: "https://kibana.example.com",
+				},
+				RawRequests: &appv2.RawRequestPolicy{Kibana: []string{"api/streams/"}},
+			},
+			wantErr: true,
+			errMsg:  `rawRequests.kibana: prefix "api/streams/" has to start with /`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -376,6 +418,36 @@ kibana:
 	}
 }
 
+func TestLoadProjectConfigSpec_InstanceOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "overrides-config.yaml")
+
+	yamlContent := `
+elasticsearchInstances:
+  production/elasticsearch:
+    enabled: false
+kibanaInstances:
+  kibana: {}
+`
+
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+
+	spec, err := LoadProjectConfigSpec(configPath)
+	if err != nil {
+		t.Fatalf("LoadProjectConfigSpec() unexpected error: %v", err)
+	}
+
+	override, ok := spec.ElasticsearchInstances["production/elasticsearch"]
+	if !ok || override.Enabled == nil || *override.Enabled {
+		t.Errorf("ElasticsearchInstances[production/elasticsearch] = %+v, want enabled false", override)
+	}
+	if override, ok := spec.KibanaInstances["kibana"]; !ok || override.Enabled != nil {
+		t.Errorf("KibanaInstances[kibana] = %+v, want an override without enabled", override)
+	}
+}
+
 func TestLoadProjectConfigSpec_RelativePath(t *testing.T) {
 	// Create a config in current working directory
 	tmpDir := t.TempDir()
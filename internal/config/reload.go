@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	appv2 "eck-custom-resources/api/config/v2"
+
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Reloader polls the configuration file and swaps the ProjectConfigSpec of the Store when its content changes. An
+// invalid configuration is rejected and the previous one is kept. Polling the content also notices the symlink swap of
+// ConfigMap volumes, which file system notifications on the file itself miss.
+type Reloader struct {
+	Path     string
+	Interval time.Duration
+	Store    *Store
+	// Apply is called with the reloaded ProjectConfigSpec, for the settings kept outside the Store
+	Apply func(spec appv2.ProjectConfigSpec)
+	// Recorder, Namespace and Pod are where the events about reloads are reported, on the Pod of the operator in its
+	// Namespace
+	Recorder  record.EventRecorder
+	Namespace string
+	Pod       string
+
+	content []byte
+}
+
+// NeedLeaderElection returns false, every replica reloads its own configuration
+func (r *Reloader) NeedLeaderElection() bool {
+	return false
+}
+
+// Start polls the configuration file until the context is cancelled
+func (r *Reloader) Start(ctx context.Context) error {
+	r.content, _ = os.ReadFile(r.Path)
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.poll(ctx)
+		}
+	}
+}
+
+// poll reloads the configuration if the content of the file changed since the last poll
+func (r *Reloader) poll(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("config-reloader")
+	content, err := os.ReadFile(r.Path)
+	if err != nil {
+		// The file is briefly missing while a ConfigMap volume is updated
+		logger.V(1).Info("Failed to read the configuration file", "path", r.Path, "error", err.Error())
+		return
+	}
+	if bytes.Equal(content, r.content) {
+		return
+	}
+	r.content = content
+
+	spec, err := LoadProjectConfigSpec(r.Path)
+	if err != nil {
+		logger.Error(err, "Rejected the reloaded configuration, keeping the previous one", "path", r.Path)
+		r.event(k8sv1.EventTypeWarning, "ConfigReloadFailed", fmt.Sprintf("Rejected the reloaded configuration, keeping the previous one: %s", err.Error()))
+		return
+	}
+	changed := ChangedFields(r.Store.Swap(spec), spec)
+	if len(changed) == 0 {
+		return
+	}
+	if r.Apply != nil {
+		r.Apply(spec)
+	}
+	logger.Info("Reloaded the configuration", "path", r.Path, "changed", changed)
+	r.event(k8sv1.EventTypeNormal, "ConfigReloaded", fmt.Sprintf("Reloaded the configuration, changed: %s", strings.Join(changed, ", ")))
+}
+
+func (r *Reloader) event(eventType string, reason string, message string) {
+	if r.Recorder == nil || r.Namespace == "" || r.Pod == "" {
+		return
+	}
+	r.Recorder.Event(&k8sv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: r.Pod, Namespace: r.Namespace}}, eventType, reason, message)
+}
+
+// ChangedFields returns the sorted top level fields of the configuration file which differ between old and new
+func ChangedFields(old appv2.ProjectConfigSpec, new appv2.ProjectConfigSpec) []string {
+	oldFields, newFields := topLevelFields(old), topLevelFields(new)
+	var changed []string
+	for field, value := range newFields {
+		if !reflect.DeepEqual(oldFields[field], value) {
+			changed = append(changed, field)
+		}
+	}
+	for field := range oldFields {
+		if _, ok := newFields[field]; !ok {
+			changed = append(changed, field)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func topLevelFields(spec appv2.ProjectConfigSpec) map[string]any {
+	fields := map[string]any{}
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(b, &fields)
+	return fields
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	appv2 "eck-custom-resources/api/config/v2"
+
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestStore(t *testing.T) {
+	store := NewStore(appv2.ProjectConfigSpec{Kibana: appv2.KibanaSpec{Url: "https://old"}})
+	previous := store.Swap(appv2.ProjectConfigSpec{Kibana: appv2.KibanaSpec{Url: "https://new"}})
+	if previous.Kibana.Url != "https://old" {
+		t.Errorf("Swap() = %q, want https://old", previous.Kibana.Url)
+	}
+	if got := store.Load().Kibana.Url; got != "https://new" {
+		t.Errorf("Load() = %q, want https://new", got)
+	}
+}
+
+func TestChangedFields(t *testing.T) {
+	old := appv2.ProjectConfigSpec{
+		Elasticsearch: appv2.ElasticsearchSpec{Url: "https://es"},
+		Kibana:        appv2.KibanaSpec{Url: "https://kb"},
+		Naming:        &appv2.NamingPolicy{Pattern: "{namespace}-{name}"},
+	}
+	new := old
+	new.Elasticsearch.Url = "https://es2"
+	new.Naming = nil
+	new.Lint = []appv2.LintRule{{Name: "replicas"}}
+
+	if got, want := ChangedFields(old, new), []string{"elasticsearch", "lint", "naming"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedFields() = %v, want %v", got, want)
+	}
+	if got := ChangedFields(old, old); len(got) != 0 {
+		t.Errorf("ChangedFields() of equal specs = %v, want none", got)
+	}
+}
+
+func TestReloader_Poll(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test config file: %v", err)
+		}
+	}
+	write("elasticsearch:\n  url: https://es\nkibana:\n  url: https://kb\n")
+	spec, err := LoadProjectConfigSpec(configPath)
+	if err != nil {
+		t.Fatalf("LoadProjectConfigSpec() error = %v", err)
+	}
+
+	recorder := &objectRecorder{FakeRecorder: record.NewFakeRecorder(10)}
+	var applied []appv2.ProjectConfigSpec
+	reloader := &Reloader{
+		Path:      configPath,
+		Store:     NewStore(spec),
+		Apply:     func(spec appv2.ProjectConfigSpec) { applied = append(applied, spec) },
+		Recorder:  recorder,
+		Namespace: "eck",
+		Pod:       "eck-operator-7d9f8",
+	}
+	ctx := context.Background()
+
+	// Unchanged content is not reloaded
+	reloader.poll(ctx)
+	if len(applied) != 0 || len(recorder.Events) != 0 {
+		t.Fatalf("Expected no reload of an unchanged configuration, applied %d", len(applied))
+	}
+
+	write("elasticsearch:\n  url: https://es2\nkibana:\n  url: https://kb\n")
+	reloader.poll(ctx)
+	if got := reloader.Store.Load().Elasticsearch.Url; got != "https://es2" {
+		t.Errorf("Expected the reloaded Elasticsearch url, got %q", got)
+	}
+	if len(applied) != 1 {
+		t.Errorf("Expected the reloaded configuration to be applied once, got %d", len(applied))
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "ConfigReloaded") || !strings.HasSuffix(event, "changed: elasticsearch") {
+		t.Errorf("Unexpected event %q", event)
+	}
+	if pod, ok := recorder.objects[0].(*k8sv1.Pod); !ok || pod.Namespace != "eck" || pod.Name != "eck-operator-7d9f8" {
+		t.Errorf("Expected the event on the operator Pod in its namespace, got %v", recorder.objects[0])
+	}
+
+	// An invalid configuration is rejected
+	write("elasticsearch:\n  url: \"\"\nkibana:\n  url: https://kb\n")
+	reloader.poll(ctx)
+	if got := reloader.Store.Load().Elasticsearch.Url; got != "https://es2" {
+		t.Errorf("Expected the previous configuration to be kept, got %q", got)
+	}
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Warning ConfigReloadFailed") {
+		t.Errorf("Unexpected event %q", event)
+	}
+}
+
+// objectRecorder records the objects events are reported on
+type objectRecorder struct {
+	*record.FakeRecorder
+	objects []runtime.Object
+}
+
+func (r *objectRecorder) Event(object runtime.Object, eventType string, reason string, message string) {
+	r.objects = append(r.objects, object)
+	r.FakeRecorder.Event(object, eventType, reason, message)
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"sync/atomic"
+
+	appv2 "eck-custom-resources/api/config/v2"
+)
+
+// Store holds the ProjectConfigSpec used by the controllers, it is swapped atomically when the configuration file is
+// reloaded
+type Store struct {
+	spec atomic.Pointer[appv2.ProjectConfigSpec]
+}
+
+// NewStore returns a Store holding spec
+func NewStore(spec appv2.ProjectConfigSpec) *Store {
+	store := &Store{}
+	store.spec.Store(&spec)
+	return store
+}
+
+// Load returns the current ProjectConfigSpec
+func (s *Store) Load() appv2.ProjectConfigSpec {
+	return *s.spec.Load()
+}
+
+// Swap replaces the ProjectConfigSpec and returns the previous one
+func (s *Store) Swap(spec appv2.ProjectConfigSpec) appv2.ProjectConfigSpec {
+	return *s.spec.Swap(&spec)
+}
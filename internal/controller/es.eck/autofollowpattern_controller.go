@@ -0,0 +1,170 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eseck
+
+import (
+	"context"
+	"fmt"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
+
+	"k8s.io/client-go/tools/record"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+)
+
+// AutoFollowPatternReconciler reconciles a AutoFollowPattern object
+type AutoFollowPatternReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=autofollowpatterns,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=autofollowpatterns/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=autofollowpatterns/finalizers,verbs=update
+
+func (r *AutoFollowPatternReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	finalizer := "autofollowpatterns.es.eck.github.com/finalizer"
+
+	var autoFollowPattern eseckv1alpha1.AutoFollowPattern
+	if err := r.Get(ctx, req.NamespacedName, &autoFollowPattern); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &autoFollowPattern, r.ProjectConfig.Load().Elasticsearch, autoFollowPattern.Spec.TargetConfig, req.Namespace)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
+
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &autoFollowPattern, &autoFollowPattern.Status.Conditions, targetInstance.Enabled) {
+		return ctrl.Result{}, nil
+	}
+
+	targetInstanceNamespace := req.Namespace
+	if autoFollowPattern.Spec.TargetConfig.ElasticsearchInstanceNamespace != "" {
+		targetInstanceNamespace = autoFollowPattern.Spec.TargetConfig.ElasticsearchInstanceNamespace
+	}
+
+	esClient, createClientErr := esutils.GetElasticsearchClient(r.Client, ctx, *targetInstance, req, targetInstanceNamespace)
+	if createClientErr != nil {
+		logger.Error(createClientErr, "Failed to create Elasticsearch client")
+		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
+	}
+
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &autoFollowPattern, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &autoFollowPattern, &autoFollowPattern.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
+	if !autoFollowPattern.DeletionTimestamp.IsZero() {
+		if controllerutil.ContainsFinalizer(&autoFollowPattern, finalizer) {
+			logger.Info("Deleting object", "autoFollowPattern", autoFollowPattern.Name)
+			if err := esutils.DeleteAutoFollowPattern(esClient, autoFollowPattern.Name); err != nil {
+				return utils.GetRequeueResult(), err
+			}
+
+			controllerutil.RemoveFinalizer(&autoFollowPattern, finalizer)
+			if err := r.Update(ctx, &autoFollowPattern); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Replication needs a connection to the remote cluster, its state is reported even while the pattern is paused
+	remoteErr := esutils.CheckRemoteCluster(esClient, autoFollowPattern.Spec.RemoteCluster)
+	autoFollowPattern.Status.RemoteClusterConnected = remoteErr == nil
+	if remoteErr != nil && !autoFollowPattern.Spec.Paused {
+		r.Recorder.Event(&autoFollowPattern, "Warning", "RemoteClusterUnavailable", remoteErr.Error())
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &autoFollowPattern, autoFollowPattern.Spec, &autoFollowPattern.Status.Conditions, &autoFollowPattern.Status.ObservedGeneration, remoteErr); statusErr != nil {
+			logger.Error(statusErr, "Failed to update AutoFollowPattern sync status")
+		}
+		return utils.GetRequeueResult(), nil
+	}
+
+	logger.Info("Creating/Updating auto-follow pattern", "autoFollowPattern", req.Name)
+	status, err := esutils.UpsertAutoFollowPattern(esClient, autoFollowPattern)
+	if err == nil {
+		r.Recorder.Event(&autoFollowPattern, "Normal", "Created",
+			fmt.Sprintf("Created/Updated %s/%s %s, replication is %s", autoFollowPattern.APIVersion, autoFollowPattern.Kind, autoFollowPattern.Name, status))
+	} else {
+		r.Recorder.Event(&autoFollowPattern, "Warning", "Failed to create/update",
+			fmt.Sprintf("Failed to create/update %s/%s %s: %s", autoFollowPattern.APIVersion, autoFollowPattern.Kind, autoFollowPattern.Name, err.Error()))
+	}
+	if status != "" {
+		autoFollowPattern.Status.ReplicationStatus = status
+	}
+
+	if err := r.addFinalizer(&autoFollowPattern, finalizer, ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &autoFollowPattern, autoFollowPattern.Spec, &autoFollowPattern.Status.Conditions, &autoFollowPattern.Status.ObservedGeneration, err); statusErr != nil {
+		logger.Error(statusErr, "Failed to update AutoFollowPattern sync status")
+	}
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AutoFollowPatternReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.AutoFollowPattern{}, utils.PriorityHigh)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.AutoFollowPattern{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.AutoFollowPattern{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.AutoFollowPattern{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.AutoFollowPattern{}, r.Recorder)
+	esutils.RegisterFailoverCleanup(&eseckv1alpha1.AutoFollowPattern{}, func(esClient *elasticsearch.Client, _ string, obj client.Object) error {
+		return esutils.DeleteAutoFollowPattern(esClient, obj.GetName())
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&eseckv1alpha1.AutoFollowPattern{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
+
+func (r *AutoFollowPatternReconciler) addFinalizer(o client.Object, finalizer string, ctx context.Context) error {
+	if !controllerutil.ContainsFinalizer(o, finalizer) {
+		controllerutil.AddFinalizer(o, finalizer)
+		if err := r.Update(ctx, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/elastic/go-elasticsearch/v8"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -27,9 +28,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
 	esutils "eck-custom-resources/utils/elasticsearch"
+	"eck-custom-resources/utils/overlay"
 
 	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
 )
@@ -38,7 +40,7 @@ import (
 type ComponentTemplateReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 }
 
@@ -55,12 +57,12 @@ func (r *ComponentTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	if err := r.Get(ctx, req.NamespacedName, &comTem); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
-	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &comTem, r.ProjectConfig.Elasticsearch, comTem.Spec.TargetConfig, req.Namespace)
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &comTem, r.ProjectConfig.Load().Elasticsearch, comTem.Spec.TargetConfig, req.Namespace)
 	if err != nil {
 		return utils.GetRequeueResult(), err
 	}
-	if !targetInstance.Enabled {
-		logger.Info("Elasticsearch reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &comTem, &comTem.Status.Conditions, targetInstance.Enabled) {
 		return ctrl.Result{}, nil
 	}
 
@@ -74,12 +76,45 @@ func (r *ComponentTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		logger.Error(createClientErr, "Failed to create Elasticsearch client")
 		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
 	}
+
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &comTem, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &comTem, &comTem.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
+	if owned, res, err := esutils.CheckOwnership(ctx, r.Client, r.Recorder, &comTem, &comTem.Status.Conditions, comTem.Spec.TargetConfig); err != nil || !owned {
+		if err == nil && !comTem.DeletionTimestamp.IsZero() && controllerutil.ContainsFinalizer(&comTem, finalizer) {
+			// The object belongs to the resource owning it, it is left in place
+			controllerutil.RemoveFinalizer(&comTem, finalizer)
+			return ctrl.Result{}, r.Update(ctx, &comTem)
+		}
+		return res, err
+	}
 	if comTem.DeletionTimestamp.IsZero() {
 		logger.Info("Creating/Updating component template", "componentTemplate", req.Name)
-		res, err := esutils.UpsertComponentTemplate(esClient, comTem)
+		body, err := overlay.Apply(r.Client, ctx, &comTem, comTem.Spec.GetBody())
+		if err != nil {
+			r.Recorder.Event(&comTem, "Warning", "OverlayError",
+				fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+		if err := utils.LintBody(&comTem, body); err != nil {
+			r.Recorder.Event(&comTem, "Warning", utils.PolicyViolationReason, err.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &comTem, comTem.Spec, &comTem.Status.Conditions, &comTem.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update ComponentTemplate sync status")
+			}
+			// The spec or the lint rules have to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+		patched := comTem.DeepCopy()
+		patched.Spec.Body = body
+		res, err := esutils.UpsertComponentTemplate(esClient, *patched)
 		if err == nil {
 			r.Recorder.Event(&comTem, "Normal", "Created",
-				fmt.Sprintf("Created/Updated %s/%s %s", comTem.APIVersion, comTem.Kind, comTem.Name))
+				fmt.Sprintf("Created/Updated %s/%s %s%s", comTem.APIVersion, comTem.Kind, comTem.Name, utils.AppliedBodyChanges(ctx, &comTem, targetInstance.Url, body)))
 		} else {
 			r.Recorder.Event(&comTem, "Warning", "Failed to create/update",
 				fmt.Sprintf("Failed to create/update %s/%s %s: %s", comTem.APIVersion, comTem.Kind, comTem.Name, err.Error()))
@@ -88,15 +123,20 @@ func (r *ComponentTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		if err := r.addFinalizer(&comTem, finalizer, ctx); err != nil {
 			return ctrl.Result{}, err
 		}
+
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &comTem, comTem.Spec, &comTem.Status.Conditions, &comTem.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update ComponentTemplate sync status")
+		}
 		return res, err
 	} else {
 		if controllerutil.ContainsFinalizer(&comTem, finalizer) {
 			logger.Info("Deleting object", "componentTemplate", comTem.Name)
-			if _, err := esutils.DeleteComponentTemplate(esClient, comTem.Name); err != nil {
+			if _, err := esutils.DeleteComponentTemplate(esClient, utils.RemoteName(&comTem)); err != nil {
 				return ctrl.Result{}, err
 			}
 
 			controllerutil.RemoveFinalizer(&comTem, finalizer)
+			utils.ForgetAppliedBody(&comTem)
 			if err := r.Update(ctx, &comTem); err != nil {
 				return ctrl.Result{}, err
 			}
@@ -118,8 +158,21 @@ func (r *ComponentTemplateReconciler) addFinalizer(o client.Object, finalizer st
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ComponentTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.ComponentTemplate{}, utils.PriorityHigh)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.ComponentTemplate{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.ComponentTemplate{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.ComponentTemplate{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.ComponentTemplate{}, r.Recorder)
+	esutils.RegisterFailoverCleanup(&eseckv1alpha1.ComponentTemplate{}, func(esClient *elasticsearch.Client, _ string, obj client.Object) error {
+		_, err := esutils.DeleteComponentTemplate(esClient, utils.RemoteName(obj))
+		return err
+	})
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&eseckv1alpha1.ComponentTemplate{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
 		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
 }
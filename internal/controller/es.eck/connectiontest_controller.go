@@ -0,0 +1,179 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eseck
+
+import (
+	"context"
+	"fmt"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
+	kibanaUtils "eck-custom-resources/utils/kibana"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+)
+
+// ConnectionTestReconciler reconciles a ConnectionTest object
+type ConnectionTestReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=connectiontests,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=connectiontests/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=connectiontests/finalizers,verbs=update
+
+// Reconcile runs the test on every reconcile: when the ConnectionTest is created or changed, its
+// last-update-triggered-at annotation is updated, at the periodic resync and every spec.interval
+func (r *ConnectionTestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var connectionTest eseckv1alpha1.ConnectionTest
+	if err := r.Get(ctx, req.NamespacedName, &connectionTest); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	// Nothing is created by the test, there is nothing to clean up
+	if !connectionTest.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	status := &connectionTest.Status
+	var passed bool
+	if connectionTest.Spec.Target == eseckv1alpha1.ConnectionTestTargetKibana {
+		passed = r.testKibana(ctx, req, &connectionTest)
+	} else {
+		passed = r.testElasticsearch(ctx, req, &connectionTest)
+	}
+	status.LastTestTime = &metav1.Time{Time: metav1.Now().Time}
+
+	var testErr error
+	if passed {
+		status.Phase = eseckv1alpha1.ConnectionTestPhaseSucceeded
+		r.Recorder.Event(&connectionTest, "Normal", "ConnectionTestSucceeded", fmt.Sprintf("All checks against %s passed", status.Url))
+	} else {
+		status.Phase = eseckv1alpha1.ConnectionTestPhaseFailed
+		failed := status.Checks[len(status.Checks)-1]
+		testErr = fmt.Errorf("%s check failed: %s", failed.Name, failed.Message)
+		r.Recorder.Event(&connectionTest, "Warning", "ConnectionTestFailed", testErr.Error())
+	}
+	logger.Info("Ran connection test", "target", connectionTest.Spec.Target, "url", status.Url, "phase", status.Phase)
+
+	if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &connectionTest, connectionTest.Spec, &status.Conditions, &status.ObservedGeneration, testErr); statusErr != nil {
+		logger.Error(statusErr, "Failed to update ConnectionTest sync status")
+	}
+
+	if connectionTest.Spec.Interval != nil && connectionTest.Spec.Interval.Duration > 0 {
+		return utils.RequeueScheduled(ctx, connectionTest.Spec.Interval.Duration), nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// testElasticsearch resolves the Elasticsearch instance and runs the checks against it
+func (r *ConnectionTestReconciler) testElasticsearch(ctx context.Context, req ctrl.Request, connectionTest *eseckv1alpha1.ConnectionTest) bool {
+	status := &connectionTest.Status
+	status.Url = ""
+	targetInstance := connectionTest.Spec.TargetInstance
+	if targetInstance.Kind != "" && targetInstance.Kind != "ElasticsearchInstance" && targetInstance.Kind != eseckv1alpha1.ClusterElasticsearchInstanceKind {
+		return failConnectionTest(status, fmt.Errorf("a %s can not be the target instance of an Elasticsearch connection test", targetInstance.Kind))
+	}
+	targetConfig := eseckv1alpha1.CommonElasticsearchConfig{
+		ElasticsearchInstance:          targetInstance.Name,
+		ElasticsearchInstanceNamespace: targetInstance.Namespace,
+		Kind:                           targetInstance.Kind,
+	}
+
+	esSpec, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, connectionTest, r.ProjectConfig.Load().Elasticsearch, targetConfig, req.Namespace)
+	if err != nil {
+		return failConnectionTest(status, fmt.Errorf("failed to resolve the target instance: %w", err))
+	}
+	status.Url = esSpec.Url
+
+	targetInstanceNamespace := req.Namespace
+	if targetInstance.Namespace != "" {
+		targetInstanceNamespace = targetInstance.Namespace
+	}
+	esClient, err := esutils.GetElasticsearchClient(r.Client, ctx, *esSpec, req, targetInstanceNamespace)
+	if err != nil {
+		return failConnectionTest(status, fmt.Errorf("failed to create the client: %w", err))
+	}
+	return esutils.TestElasticsearchConnection(esClient, connectionTest.Spec.ClusterPrivileges, status)
+}
+
+// testKibana resolves the Kibana instance and runs the checks against it
+func (r *ConnectionTestReconciler) testKibana(ctx context.Context, req ctrl.Request, connectionTest *eseckv1alpha1.ConnectionTest) bool {
+	status := &connectionTest.Status
+	status.Url = ""
+	targetInstance := connectionTest.Spec.TargetInstance
+	if targetInstance.Kind != "" && targetInstance.Kind != "KibanaInstance" && targetInstance.Kind != kibanaeckv1alpha1.ClusterKibanaInstanceKind {
+		return failConnectionTest(status, fmt.Errorf("a %s can not be the target instance of a Kibana connection test", targetInstance.Kind))
+	}
+	targetConfig := kibanaeckv1alpha1.CommonKibanaConfig{
+		KibanaInstance:          targetInstance.Name,
+		KibanaInstanceNamespace: targetInstance.Namespace,
+		Kind:                    targetInstance.Kind,
+	}
+
+	kibanaSpec, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, connectionTest, r.ProjectConfig.Load().Kibana, targetConfig, req.Namespace)
+	if err != nil {
+		return failConnectionTest(status, fmt.Errorf("failed to resolve the target instance: %w", err))
+	}
+	status.Url = kibanaSpec.Url
+
+	targetInstanceNamespace := req.Namespace
+	if targetInstance.Namespace != "" {
+		targetInstanceNamespace = targetInstance.Namespace
+	}
+	kClient := kibanaUtils.Client{
+		Cli:             r.Client,
+		Ctx:             ctx,
+		KibanaSpec:      *kibanaSpec,
+		KibanaNamespace: targetInstanceNamespace,
+		Req:             req,
+	}
+	return kibanaUtils.TestKibanaConnection(kClient, connectionTest.Spec.Space, status)
+}
+
+// failConnectionTest records err as the failed Connectivity check, for errors before the instance is reached
+func failConnectionTest(status *eseckv1alpha1.ConnectionTestStatus, err error) bool {
+	status.Version = ""
+	status.Username = ""
+	status.Checks = []eseckv1alpha1.ConnectionTestCheck{{Name: eseckv1alpha1.ConnectionTestCheckConnectivity, Message: err.Error()}}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ConnectionTestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.ConnectionTest{})
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.ConnectionTest{}, r.Recorder)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&eseckv1alpha1.ConnectionTest{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(utils.CommonEventFilter()).
+		Complete(metrics.Reconciler(ownership.Reconciler(mgr.GetClient(), r)))
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eseck
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	"eck-custom-resources/utils/quota"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+)
+
+// EckResourceQuotaReconciler reconciles a EckResourceQuota object
+type EckResourceQuotaReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=es.eck.github.com,resources=eckresourcequotas,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=es.eck.github.com,resources=eckresourcequotas/status,verbs=get;update;patch
+
+// Reconcile counts the resources of the namespace limited by the quota into its status. The quota itself is enforced
+// by the webhook and by the controllers of the limited kinds.
+func (r *EckResourceQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var eckResourceQuota eseckv1alpha1.EckResourceQuota
+	if err := r.Get(ctx, req.NamespacedName, &eckResourceQuota); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !eckResourceQuota.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	used := map[quota.Resource]int32{}
+	for _, resource := range quota.Resources {
+		resources, err := quota.List(ctx, r.Client, req.Namespace, resource)
+		if err != nil {
+			return utils.GetRequeueResult(), err
+		}
+		used[resource] = int32(len(resources))
+	}
+	eckResourceQuota.Status.Used = eseckv1alpha1.EckResourceQuotaUsage{
+		Indices:         used[quota.Indices],
+		IngestPipelines: used[quota.IngestPipelines],
+		APIKeys:         used[quota.APIKeys],
+		SavedObjects:    used[quota.SavedObjects],
+	}
+
+	if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &eckResourceQuota, eckResourceQuota.Spec, &eckResourceQuota.Status.Conditions, &eckResourceQuota.Status.ObservedGeneration, nil); statusErr != nil {
+		logger.Error(statusErr, "Failed to update EckResourceQuota sync status")
+		return ctrl.Result{}, statusErr
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *EckResourceQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.EckResourceQuota{})
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.EckResourceQuota{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.EckResourceQuota{}, r.Recorder)
+	controller := ctrl.NewControllerManagedBy(mgr).
+		For(&eseckv1alpha1.EckResourceQuota{}).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithOptions(metrics.Options())
+	for _, resource := range quota.Resources {
+		for _, gvk := range quota.Kinds[resource] {
+			// Kinds whose CRDs are not installed are not counted
+			if installed, err := utils.KindInstalled(mgr.GetRESTMapper(), gvk); err != nil {
+				return err
+			} else if !installed {
+				continue
+			}
+			limited := &unstructured.Unstructured{}
+			limited.SetGroupVersionKind(gvk)
+			controller = controller.Watches(limited, handler.EnqueueRequestsFromMapFunc(r.requestsForNamespace))
+		}
+	}
+	return controller.Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), r))))
+}
+
+// requestsForNamespace returns the quotas of the namespace of the limited resource, so their usage is counted again
+func (r *EckResourceQuotaReconciler) requestsForNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	var quotas eseckv1alpha1.EckResourceQuotaList
+	if err := r.List(ctx, &quotas, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list EckResourceQuotas", "namespace", obj.GetNamespace())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(quotas.Items))
+	for _, eckResourceQuota := range quotas.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&eckResourceQuota)})
+	}
+	return requests
+}
@@ -18,12 +18,16 @@ package eseck
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
 	esutils "eck-custom-resources/utils/elasticsearch"
+	"eck-custom-resources/utils/quota"
+
+	"github.com/elastic/go-elasticsearch/v8"
 
 	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
 
@@ -41,7 +45,7 @@ import (
 type ElasticsearchApikeyReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 }
 
@@ -65,13 +69,13 @@ func (r *ElasticsearchApikeyReconciler) Reconcile(ctx context.Context, req ctrl.
 	// Convenience locals
 	desiredGen := apikey.GetGeneration()
 
-	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &apikey, r.ProjectConfig.Elasticsearch, apikey.Spec.TargetConfig, req.Namespace)
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &apikey, r.ProjectConfig.Load().Elasticsearch, apikey.Spec.TargetConfig, req.Namespace)
 	if err != nil {
 		return utils.GetRequeueResult(), err
 	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
 
-	if !targetInstance.Enabled {
-		logger.Info("Elasticsearch reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &apikey, &apikey.Status.Conditions, targetInstance.Enabled) {
 		return ctrl.Result{}, nil
 	}
 
@@ -86,9 +90,31 @@ func (r *ElasticsearchApikeyReconciler) Reconcile(ctx context.Context, req ctrl.
 		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
 	}
 
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &apikey, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &apikey, &apikey.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
+	if permitted, res := esutils.CheckSecurityPrivileges(ctx, r.Client, r.Recorder, &apikey, &apikey.Status.Conditions, esClient, *targetInstance); !permitted {
+		return res, nil
+	}
+
 	if apikey.DeletionTimestamp.IsZero() {
 		// --- Not being deleted: ensure finalizer, then reconcile normally
 		if !controllerutil.ContainsFinalizer(&apikey, finalizer) {
+			if reason, err := quota.Admit(ctx, r.Client, &apikey); err != nil {
+				return utils.GetRequeueResult(), err
+			} else if reason != "" {
+				r.Recorder.Event(&apikey, "Warning", "QuotaExceeded", fmt.Sprintf("Not creating %s: %s", apikey.GetName(), reason))
+				if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &apikey, apikey.Spec, &apikey.Status.Conditions, &apikey.Status.ObservedGeneration, errors.New(reason)); statusErr != nil {
+					logger.Error(statusErr, "Failed to update ElasticsearchApikey sync status")
+				}
+				// Requeued until the quota allows the resource
+				return utils.GetRequeueResult(), nil
+			}
 			// Use Patch to avoid update conflicts
 			patch := client.MergeFrom(apikey.DeepCopy())
 			controllerutil.AddFinalizer(&apikey, finalizer)
@@ -134,23 +160,37 @@ func (r *ElasticsearchApikeyReconciler) Reconcile(ctx context.Context, req ctrl.
 							fmt.Sprintf("patching status after error %v", err))
 						return ctrl.Result{}, err
 					}
-					return ctrl.Result{}, err
+					return r.applySecretTemplate(ctx, &apikey, targetInstance.Url)
 				}
 				if apikey.Status.ObservedGeneration == desiredGen {
+					r.updateExpiryStatus(ctx, esClient, &apikey, req)
+					if res, err := r.applySecretTemplate(ctx, &apikey, targetInstance.Url); err != nil {
+						return res, err
+					}
+
 					var needReconcile = false
 					var msg string
-					if _, err := esutils.GetAPIKeySecret(r.Client, ctx, req.Namespace, req.Name); err != nil {
-						msg = fmt.Sprintf("Secret %s not found", req.Name)
+					_, secretErr := esutils.GetAPIKeySecret(r.Client, ctx, req.Namespace, apikey.GetSecretName())
+					if secretErr != nil {
+						msg = fmt.Sprintf("Secret %s not found", apikey.GetSecretName())
 						needReconcile = true
 					}
-					if !esutils.ApiKeyIDExist(r.Client, ctx, esClient, req, apikey) || needReconcile {
-
-						if esutils.ApiKeyNameExist(r.Client, ctx, esClient, req.Name) {
-							for _, apikey := range esutils.GetApiKeyWithName(r.Client, ctx, esClient, req.Name) {
-								esutils.UpdateExpirationApikey(r.Client, ctx, esClient, apikey, "1d")
-							}
-							msg = fmt.Sprintf("ApiKey with ID: %s not found. Expiring all keys with name: %s ", apikey.Status.APIKeyID, req.Name)
+					if apikeyID, idErr := esutils.GetAPIKeyID(r.Client, ctx, req, apikey); idErr == nil {
+						lost, lostErr := esutils.GetApikeyLostReason(ctx, esClient, apikeyID, time.Now())
+						if lostErr != nil {
+							return utils.GetRequeueResult(), lostErr
+						}
+						if lost != "" {
+							esutils.RecordApikeyLost(&apikey)
+							msg = fmt.Sprintf("API key %s %s in Elasticsearch, creating a new one", apikeyID, lost)
+							r.Recorder.Event(&apikey, "Warning", "ApiKeyLost", msg)
 							needReconcile = true
+						} else if secretErr != nil {
+							// Only the lost Secret held the key, retire it before a new one is created
+							if _, err := esutils.UpdateExpirationApikey(r.Client, ctx, esClient, esutils.APIKey{ID: apikeyID}, "1d"); err != nil {
+								logger.Error(err, "Failed to expire API key", "id", apikeyID)
+							}
+							msg = fmt.Sprintf("Secret %s not found, expiring API key %s", apikey.GetSecretName(), apikeyID)
 						}
 					}
 
@@ -169,17 +209,17 @@ func (r *ElasticsearchApikeyReconciler) Reconcile(ctx context.Context, req ctrl.
 						}
 						return ctrl.Result{RequeueAfter: 10 * time.Second}, err
 					}
+					if perr := r.Status().Patch(ctx, &apikey, client.MergeFrom(&eseckv1alpha1.ElasticsearchApikey{Status: *oldStatus})); perr != nil {
+						r.Recorder.Event(&apikey, "Warning", "patching",
+							fmt.Sprintf("patching status after error %v", perr))
+					}
 					return ctrl.Result{}, err
 				}
 				return ctrl.Result{}, err
 			} else {
 				logger.Info("Recreating API key", "name", req.NamespacedName)
 
-				res, errs := esutils.CreateApikey(r.Client, ctx, esClient, &apikey, req)
-				if errs != nil {
-					logger.Info("Recreating error")
-				}
-
+				res, err := esutils.CreateApikey(r.Client, ctx, esClient, &apikey, req)
 				if err != nil {
 					r.Recorder.Event(&apikey, "Warning", "ReconcileError",
 						fmt.Sprintf("Failed to create/update %s/%s %q: %v", apikey.APIVersion, apikey.Kind, apikey.Name, err))
@@ -196,8 +236,8 @@ func (r *ElasticsearchApikeyReconciler) Reconcile(ctx context.Context, req ctrl.
 					if perr := r.Status().Patch(ctx, &apikey, client.MergeFrom(&eseckv1alpha1.ElasticsearchApikey{Status: *oldStatus})); perr != nil {
 						r.Recorder.Event(&apikey, "Warning", "patching",
 							fmt.Sprintf("patching status after error %v", perr))
-						return ctrl.Result{RequeueAfter: 10 * time.Second}, fmt.Errorf("Recreating API key and Secret - Retrying: %v", &err)
 					}
+					return ctrl.Result{RequeueAfter: 10 * time.Second}, fmt.Errorf("Recreating API key and Secret - Retrying: %w", err)
 				}
 				apikeySetCondition(&apikey, metav1.Condition{
 					Type:               "Ready",
@@ -224,11 +264,7 @@ func (r *ElasticsearchApikeyReconciler) Reconcile(ctx context.Context, req ctrl.
 				// Normal reconcile path
 				logger.Info("Creating API key", "name", req.NamespacedName)
 
-				res, errs := esutils.CreateApikey(r.Client, ctx, esClient, &apikey, req)
-				if errs != nil {
-					logger.Info("CreateApikey error")
-				}
-
+				res, err := esutils.CreateApikey(r.Client, ctx, esClient, &apikey, req)
 				if err != nil {
 					r.Recorder.Event(&apikey, "Warning", "ReconcileError",
 						fmt.Sprintf("Failed to create/update %s/%s %q: %v", apikey.APIVersion, apikey.Kind, apikey.Name, err))
@@ -249,7 +285,7 @@ func (r *ElasticsearchApikeyReconciler) Reconcile(ctx context.Context, req ctrl.
 						r.Recorder.Event(&apikey, "Warning", "patching",
 							fmt.Sprintf("patching status after error %v", perr))
 					}
-					return ctrl.Result{RequeueAfter: 10 * time.Second}, fmt.Errorf("error creating API key Secret - Retrying: %v", &err)
+					return ctrl.Result{RequeueAfter: 10 * time.Second}, fmt.Errorf("error creating API key Secret - Retrying: %w", err)
 
 				}
 				logger.Info("Successfully created API key", "name", req.NamespacedName)
@@ -275,6 +311,10 @@ func (r *ElasticsearchApikeyReconciler) Reconcile(ctx context.Context, req ctrl.
 				r.Recorder.Event(&apikey, "Normal", "Reconciled",
 					fmt.Sprintf("Created/Updated %s/%s %q", apikey.APIVersion, apikey.Kind, apikey.Name))
 
+				if annotationErr := utils.UpdateSyncAnnotations(r.Client, ctx, &apikey, apikey.Spec); annotationErr != nil {
+					logger.Error(annotationErr, "Failed to update ElasticsearchApikey sync annotations")
+				}
+
 				return res, nil
 			}
 
@@ -323,6 +363,38 @@ func (r *ElasticsearchApikeyReconciler) Reconcile(ctx context.Context, req ctrl.
 	}
 }
 
+// applySecretTemplate applies spec.secretTemplate to the Secret of the API key, emitting an event if it changed
+func (r *ElasticsearchApikeyReconciler) applySecretTemplate(ctx context.Context, apikey *eseckv1alpha1.ElasticsearchApikey, url string) (ctrl.Result, error) {
+	changed, err := esutils.ApplyApikeySecretTemplate(r.Client, ctx, apikey, url)
+	if err != nil {
+		r.Recorder.Event(apikey, "Warning", "SecretTemplateError",
+			fmt.Sprintf("Failed to apply the secret template to Secret %s: %s", apikey.GetSecretName(), err.Error()))
+		return utils.GetRequeueResult(), err
+	}
+	if changed {
+		r.Recorder.Event(apikey, "Normal", "SecretTemplateApplied",
+			fmt.Sprintf("Applied the secret template to Secret %s", apikey.GetSecretName()))
+	}
+	return ctrl.Result{}, nil
+}
+
+// updateExpiryStatus polls the expiration and invalidation state of the API key into the status, and emits a
+// Warning event once the key is invalidated or about to expire
+func (r *ElasticsearchApikeyReconciler) updateExpiryStatus(ctx context.Context, esClient *elasticsearch.Client, apikey *eseckv1alpha1.ElasticsearchApikey, req ctrl.Request) {
+	apikeyID, err := esutils.GetAPIKeyID(r.Client, ctx, req, *apikey)
+	if err != nil {
+		return
+	}
+	info, err := esutils.GetApikeyInfo(ctx, esClient, apikeyID)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to get API key expiration", "name", req.NamespacedName)
+		return
+	}
+	if reason, message := esutils.SetApikeyExpiryStatus(apikey, info, time.Now()); reason != "" {
+		r.Recorder.Event(apikey, "Warning", reason, message)
+	}
+}
+
 func apikeySetCondition(obj *eseckv1alpha1.ElasticsearchApikey, c metav1.Condition) {
 	// Update or add by Type
 	conds := obj.Status.Conditions
@@ -332,8 +404,16 @@ func apikeySetCondition(obj *eseckv1alpha1.ElasticsearchApikey, c metav1.Conditi
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ElasticsearchApikeyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.ElasticsearchApikey{}, utils.PriorityCritical)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.ElasticsearchApikey{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.ElasticsearchApikey{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.ElasticsearchApikey{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.ElasticsearchApikey{}, r.Recorder)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&eseckv1alpha1.ElasticsearchApikey{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
 		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
 }
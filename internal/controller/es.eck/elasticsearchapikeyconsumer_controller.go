@@ -0,0 +1,192 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eseck
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
+)
+
+// ElasticsearchApikeyConsumerReconciler injects the Secret of an ElasticsearchApikey into the Deployments and
+// StatefulSets selected by its spec.consumers, and removes it from the workloads no longer selected
+type ElasticsearchApikeyConsumerReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// apikeyConsumer is a workload the Secret of an API key can be injected into
+type apikeyConsumer struct {
+	object   client.Object
+	kind     string
+	template *corev1.PodTemplateSpec
+}
+
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;update;patch
+
+func (r *ElasticsearchApikeyConsumerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Without a live ElasticsearchApikey selecting consumers, its Secret is removed from all workloads
+	var apikey *eseckv1alpha1.ElasticsearchApikey
+	selector := labels.Nothing()
+	var found eseckv1alpha1.ElasticsearchApikey
+	if err := r.Get(ctx, req.NamespacedName, &found); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return utils.GetRequeueResult(), err
+		}
+	} else if found.DeletionTimestamp.IsZero() {
+		apikey = &found
+	}
+
+	var hash string
+	if apikey != nil && apikey.Spec.Consumers != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(&apikey.Spec.Consumers.Selector); err != nil {
+			r.Recorder.Event(apikey, "Warning", "InvalidSelector",
+				fmt.Sprintf("Invalid spec.consumers.selector: %s", err.Error()))
+			return ctrl.Result{}, nil
+		}
+		var secret corev1.Secret
+		if err := r.Get(ctx, client.ObjectKey{Namespace: req.Namespace, Name: apikey.GetSecretName()}, &secret); err != nil {
+			// Injected once the Secret is created, which triggers a reconcile
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		if hash, err = esutils.ApikeySecretHash(secret); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	consumers, err := r.listConsumers(ctx, req.Namespace)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	var injected []string
+	for _, consumer := range consumers {
+		var changed bool
+		if selector.Matches(labels.Set(consumer.object.GetLabels())) {
+			changed = esutils.InjectApikeySecret(consumer.template, req.Name, apikey.GetSecretName(), apikey.Spec.Consumers.MountPath, hash)
+			injected = append(injected, consumer.kind+"/"+consumer.object.GetName())
+		} else {
+			changed = esutils.RemoveApikeySecret(consumer.template, req.Name)
+		}
+		if !changed {
+			continue
+		}
+		if err := r.Update(ctx, consumer.object); err != nil {
+			if apikey != nil {
+				r.Recorder.Event(apikey, "Warning", "ConsumerUpdateFailed",
+					fmt.Sprintf("Failed to update %s %s: %s", consumer.kind, consumer.object.GetName(), err.Error()))
+			}
+			return utils.GetRequeueResult(), err
+		}
+		logger.Info("Updated API key consumer", "kind", consumer.kind, "name", consumer.object.GetName())
+		if apikey != nil {
+			r.Recorder.Event(apikey, "Normal", "ConsumerUpdated",
+				fmt.Sprintf("Updated the Secret %s in %s %s", apikey.GetSecretName(), consumer.kind, consumer.object.GetName()))
+		}
+	}
+
+	if apikey == nil || slices.Equal(apikey.Status.Consumers, injected) {
+		return ctrl.Result{}, nil
+	}
+	patch := client.MergeFrom(apikey.DeepCopy())
+	apikey.Status.Consumers = injected
+	return ctrl.Result{}, r.Status().Patch(ctx, apikey, patch)
+}
+
+// listConsumers returns the Deployments and StatefulSets of the namespace, sorted by kind and name
+func (r *ElasticsearchApikeyConsumerReconciler) listConsumers(ctx context.Context, namespace string) ([]apikeyConsumer, error) {
+	var deployments appsv1.DeploymentList
+	if err := r.List(ctx, &deployments, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := r.List(ctx, &statefulSets, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	consumers := make([]apikeyConsumer, 0, len(deployments.Items)+len(statefulSets.Items))
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		consumers = append(consumers, apikeyConsumer{object: deployment, kind: "Deployment", template: &deployment.Spec.Template})
+	}
+	for i := range statefulSets.Items {
+		statefulSet := &statefulSets.Items[i]
+		consumers = append(consumers, apikeyConsumer{object: statefulSet, kind: "StatefulSet", template: &statefulSet.Spec.Template})
+	}
+	slices.SortFunc(consumers, func(a, b apikeyConsumer) int {
+		return cmp.Or(cmp.Compare(a.kind, b.kind), cmp.Compare(a.object.GetName(), b.object.GetName()))
+	})
+	return consumers, nil
+}
+
+// requestsForConsumer returns the ElasticsearchApikeys of the namespace of the workload selecting workloads, and
+// those injected into the workload
+func (r *ElasticsearchApikeyConsumerReconciler) requestsForConsumer(ctx context.Context, workload client.Object) []reconcile.Request {
+	var apikeys eseckv1alpha1.ElasticsearchApikeyList
+	if err := r.List(ctx, &apikeys, client.InNamespace(workload.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list ElasticsearchApikeys", "namespace", workload.GetNamespace())
+		return nil
+	}
+	var template corev1.PodTemplateSpec
+	switch workload := workload.(type) {
+	case *appsv1.Deployment:
+		template = workload.Spec.Template
+	case *appsv1.StatefulSet:
+		template = workload.Spec.Template
+	}
+
+	var requests []reconcile.Request
+	for _, apikey := range apikeys.Items {
+		if apikey.Spec.Consumers != nil || esutils.IsApikeyConsumer(template, apikey.Name) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&apikey)})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ElasticsearchApikeyConsumerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.ElasticsearchApikey{}, r.Recorder)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("elasticsearchapikeyconsumer").
+		For(&eseckv1alpha1.ElasticsearchApikey{}).
+		Owns(&corev1.Secret{}).
+		Watches(&appsv1.Deployment{}, handler.EnqueueRequestsFromMapFunc(r.requestsForConsumer)).
+		Watches(&appsv1.StatefulSet{}, handler.EnqueueRequestsFromMapFunc(r.requestsForConsumer)).
+		Complete(ownership.Reconciler(mgr.GetClient(), r))
+}
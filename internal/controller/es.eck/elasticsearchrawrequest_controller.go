@@ -0,0 +1,214 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eseck
+
+import (
+	"context"
+	"fmt"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"k8s.io/client-go/tools/record"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+)
+
+// ElasticsearchRawRequestReconciler reconciles a ElasticsearchRawRequest object
+type ElasticsearchRawRequestReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=elasticsearchrawrequests,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=elasticsearchrawrequests/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=elasticsearchrawrequests/finalizers,verbs=update
+
+func (r *ElasticsearchRawRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	finalizer := "elasticsearchrawrequests.es.eck.github.com/finalizer"
+
+	var rawRequest eseckv1alpha1.ElasticsearchRawRequest
+	if err := r.Get(ctx, req.NamespacedName, &rawRequest); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	projectConfig := r.ProjectConfig.Load()
+	var allowedPrefixes []string
+	if projectConfig.RawRequests != nil {
+		allowedPrefixes = projectConfig.RawRequests.Elasticsearch
+	}
+
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &rawRequest, projectConfig.Elasticsearch, rawRequest.Spec.TargetConfig, req.Namespace)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
+
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &rawRequest, &rawRequest.Status.Conditions, targetInstance.Enabled) {
+		return ctrl.Result{}, nil
+	}
+
+	targetInstanceNamespace := req.Namespace
+	if rawRequest.Spec.TargetConfig.ElasticsearchInstanceNamespace != "" {
+		targetInstanceNamespace = rawRequest.Spec.TargetConfig.ElasticsearchInstanceNamespace
+	}
+
+	esClient, createClientErr := esutils.GetElasticsearchClient(r.Client, ctx, *targetInstance, req, targetInstanceNamespace)
+	if createClientErr != nil {
+		logger.Error(createClientErr, "Failed to create Elasticsearch client")
+		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
+	}
+
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &rawRequest, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &rawRequest, &rawRequest.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
+	if !rawRequest.DeletionTimestamp.IsZero() {
+		if controllerutil.ContainsFinalizer(&rawRequest, finalizer) {
+			if deletePath := rawRequest.GetAppliedDeletePath(); deletePath != "" {
+				// A path removed from the allowlist must not block the deletion of the resource
+				if err := utils.CheckRawRequestPath(allowedPrefixes, deletePath); err != nil {
+					r.Recorder.Event(&rawRequest, "Warning", utils.RawRequestNotAllowedReason,
+						fmt.Sprintf("Not sending the DELETE request: %s", err.Error()))
+				} else {
+					logger.Info("Deleting object", "path", deletePath)
+					if res, err := esutils.DeleteRawRequest(esClient, deletePath); err != nil {
+						return res, err
+					}
+				}
+			}
+
+			controllerutil.RemoveFinalizer(&rawRequest, finalizer)
+			utils.ForgetAppliedBody(&rawRequest)
+			if err := r.Update(ctx, &rawRequest); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if err := utils.CheckRawRequestPath(allowedPrefixes, rawRequest.Spec.Path); err != nil {
+		r.Recorder.Event(&rawRequest, "Warning", utils.RawRequestNotAllowedReason, err.Error())
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &rawRequest, rawRequest.Spec, &rawRequest.Status.Conditions, &rawRequest.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update ElasticsearchRawRequest sync status")
+		}
+		// The spec or the operator configuration have to be changed, retrying does not help
+		return ctrl.Result{}, nil
+	}
+	if err := utils.LintBody(&rawRequest, rawRequest.Spec.Body); err != nil {
+		r.Recorder.Event(&rawRequest, "Warning", utils.PolicyViolationReason, err.Error())
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &rawRequest, rawRequest.Spec, &rawRequest.Status.Conditions, &rawRequest.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update ElasticsearchRawRequest sync status")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("Sending raw request", "path", rawRequest.Spec.Path)
+	res, err := esutils.PutRawRequest(esClient, rawRequest.Spec.Path, rawRequest.Spec.Body)
+	if err == nil {
+		if err = r.deleteReplacedObject(ctx, esClient, &rawRequest, allowedPrefixes); err != nil {
+			res = utils.GetRequeueResult()
+		}
+	}
+	if err == nil {
+		r.Recorder.Event(&rawRequest, "Normal", "Created",
+			fmt.Sprintf("Created/Updated %s/%s %s%s", rawRequest.APIVersion, rawRequest.Kind, rawRequest.Name, utils.AppliedBodyChanges(ctx, &rawRequest, targetInstance.Url, rawRequest.Spec.Body)))
+	} else {
+		r.Recorder.Event(&rawRequest, "Warning", "Failed to create/update",
+			fmt.Sprintf("Failed to create/update %s/%s %s: %s", rawRequest.APIVersion, rawRequest.Kind, rawRequest.Name, err.Error()))
+	}
+
+	if !controllerutil.ContainsFinalizer(&rawRequest, finalizer) {
+		controllerutil.AddFinalizer(&rawRequest, finalizer)
+		if err := r.Update(ctx, &rawRequest); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err == nil {
+		rawRequest.Status.AppliedPath = rawRequest.Spec.Path
+		rawRequest.Status.AppliedDeletePath = rawRequest.Spec.GetDeletePath()
+	}
+	if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &rawRequest, rawRequest.Spec, &rawRequest.Status.Conditions, &rawRequest.Status.ObservedGeneration, err); statusErr != nil {
+		logger.Error(statusErr, "Failed to update ElasticsearchRawRequest sync status")
+	}
+	return res, err
+}
+
+// deleteReplacedObject deletes the object of the previously applied path once spec.path changed, unless the DELETE
+// path stayed the same
+func (r *ElasticsearchRawRequestReconciler) deleteReplacedObject(ctx context.Context, esClient *elasticsearch.Client,
+	rawRequest *eseckv1alpha1.ElasticsearchRawRequest, allowedPrefixes []string) error {
+	previous := rawRequest.Status.AppliedDeletePath
+	if rawRequest.Status.AppliedPath == "" || rawRequest.Status.AppliedPath == rawRequest.Spec.Path ||
+		previous == "" || previous == rawRequest.Spec.GetDeletePath() {
+		return nil
+	}
+	if err := utils.CheckRawRequestPath(allowedPrefixes, previous); err != nil {
+		r.Recorder.Event(rawRequest, "Warning", utils.RawRequestNotAllowedReason,
+			fmt.Sprintf("Not deleting the object of the previous path: %s", err.Error()))
+		return nil
+	}
+	log.FromContext(ctx).Info("Deleting object of the previous path", "path", previous)
+	_, err := esutils.DeleteRawRequest(esClient, previous)
+	return err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ElasticsearchRawRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.ElasticsearchRawRequest{}, utils.PriorityNormal)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.ElasticsearchRawRequest{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.ElasticsearchRawRequest{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.ElasticsearchRawRequest{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.ElasticsearchRawRequest{}, r.Recorder)
+	esutils.RegisterFailoverCleanup(&eseckv1alpha1.ElasticsearchRawRequest{}, func(esClient *elasticsearch.Client, _ string, obj client.Object) error {
+		deletePath := obj.(*eseckv1alpha1.ElasticsearchRawRequest).GetAppliedDeletePath()
+		var allowedPrefixes []string
+		if rawRequests := r.ProjectConfig.Load().RawRequests; rawRequests != nil {
+			allowedPrefixes = rawRequests.Elasticsearch
+		}
+		if deletePath == "" || utils.CheckRawRequestPath(allowedPrefixes, deletePath) != nil {
+			return nil
+		}
+		_, err := esutils.DeleteRawRequest(esClient, deletePath)
+		return err
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&eseckv1alpha1.ElasticsearchRawRequest{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
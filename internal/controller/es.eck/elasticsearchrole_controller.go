@@ -20,13 +20,17 @@ import (
 	"context"
 	"fmt"
 
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
 	esutils "eck-custom-resources/utils/elasticsearch"
+	"eck-custom-resources/utils/overlay"
+	"eck-custom-resources/utils/template"
 
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	"github.com/elastic/go-elasticsearch/v8"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -39,8 +43,9 @@ import (
 type ElasticsearchRoleReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
+	RestConfig    *rest.Config
 }
 
 //+kubebuilder:rbac:groups=es.eck.github.com,resources=elasticsearchroles,verbs=get;list;watch;create;update;patch;delete
@@ -57,13 +62,13 @@ func (r *ElasticsearchRoleReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &role, r.ProjectConfig.Elasticsearch, role.Spec.TargetConfig, req.Namespace)
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &role, r.ProjectConfig.Load().Elasticsearch, role.Spec.TargetConfig, req.Namespace)
 	if err != nil {
 		return utils.GetRequeueResult(), err
 	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
 
-	if !targetInstance.Enabled {
-		logger.Info("Elasticsearch reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &role, &role.Status.Conditions, targetInstance.Enabled) {
 		return ctrl.Result{}, nil
 	}
 
@@ -78,16 +83,86 @@ func (r *ElasticsearchRoleReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
 	}
 
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &role, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &role, &role.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
+	if permitted, res := esutils.CheckSecurityPrivileges(ctx, r.Client, r.Recorder, &role, &role.Status.Conditions, esClient, *targetInstance); !permitted {
+		return res, nil
+	}
+
 	if role.DeletionTimestamp.IsZero() {
 		logger.Info("Creating/Updating Role", "role", req.Name)
-		res, err := esutils.UpsertRole(esClient, role)
-
-		if err == nil {
-			r.Recorder.Event(&role, "Normal", "Created",
-				fmt.Sprintf("Created/Updated %s/%s %s", role.APIVersion, role.Kind, role.Name))
-		} else {
-			r.Recorder.Event(&role, "Warning", "Failed to create/update",
-				fmt.Sprintf("Failed to create/update %s/%s %s: %s", role.APIVersion, role.Kind, role.Name, err.Error()))
+
+		body, err := template.FetchAndRenderTemplate(
+			r.Client,
+			ctx,
+			role.Spec.Template,
+			role.Spec.GetBody(),
+			template.Builtins{
+				Namespace:      req.Namespace,
+				Name:           req.Name,
+				TargetInstance: role.Spec.TargetConfig.ElasticsearchInstance,
+				Target:         esutils.TemplateTarget(esClient, role.Spec.Template, role.Spec.TargetConfig, *targetInstance, req.Namespace),
+			},
+			r.RestConfig,
+		)
+		if err != nil {
+			r.Recorder.Event(&role, "Warning", "TemplateRenderError",
+				fmt.Sprintf("Failed to render template: %s", err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+
+		if body, err = overlay.Apply(r.Client, ctx, &role, body); err != nil {
+			r.Recorder.Event(&role, "Warning", "OverlayError",
+				fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+
+		if body, err = esutils.InjectKibanaPrivileges(body, role.Spec.KibanaPrivileges); err != nil {
+			r.Recorder.Event(&role, "Warning", "InvalidKibanaPrivileges",
+				fmt.Sprintf("Failed to add Kibana privileges: %s", err.Error()))
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &role, role.Spec, &role.Status.Conditions, &role.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update ElasticsearchRole sync status")
+			}
+			// The spec has to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+
+		if err := utils.LintBody(&role, body); err != nil {
+			r.Recorder.Event(&role, "Warning", utils.PolicyViolationReason, err.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &role, role.Spec, &role.Status.Conditions, &role.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update ElasticsearchRole sync status")
+			}
+			// The spec or the lint rules have to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+
+		upsert, adoption, err := esutils.ResolveSecurityAdoption(&role, controllerutil.ContainsFinalizer(&role, finalizer), role.Status.Adoption, func() (map[string]interface{}, error) {
+			return esutils.GetRoleDefinition(esClient, utils.RemoteName(&role))
+		})
+		if err != nil {
+			return utils.GetRequeueResult(), err
+		}
+
+		res := ctrl.Result{}
+		if upsert {
+			res, err = esutils.UpsertRole(esClient, role, body, esutils.SecurityRefreshFor(targetInstance.Url, role.Spec.Refresh))
+
+			if err == nil {
+				r.Recorder.Event(&role, "Normal", "Created",
+					fmt.Sprintf("Created/Updated %s/%s %s%s", role.APIVersion, role.Kind, role.Name, utils.AppliedBodyChanges(ctx, &role, targetInstance.Url, body)))
+			} else {
+				r.Recorder.Event(&role, "Warning", "Failed to create/update",
+					fmt.Sprintf("Failed to create/update %s/%s %s: %s", role.APIVersion, role.Kind, role.Name, err.Error()))
+			}
+		} else if adoption != role.Status.Adoption {
+			r.Recorder.Event(&role, "Normal", "Adopted",
+				fmt.Sprintf("Adopted the existing role %s, the body is applied from its next change on", utils.RemoteName(&role)))
 		}
 
 		if !controllerutil.ContainsFinalizer(&role, finalizer) {
@@ -96,16 +171,22 @@ func (r *ElasticsearchRoleReconciler) Reconcile(ctx context.Context, req ctrl.Re
 				return ctrl.Result{}, err
 			}
 		}
+
+		role.Status.Adoption = adoption
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &role, role.Spec, &role.Status.Conditions, &role.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update ElasticsearchRole sync status")
+		}
 		return res, err
 	} else {
 		// The object is being deleted
 		if controllerutil.ContainsFinalizer(&role, finalizer) {
 			logger.Info("Deleting object", "role", role.Name)
-			if _, err := esutils.DeleteRole(esClient, req.Name); err != nil {
+			if _, err := esutils.DeleteRole(esClient, utils.RemoteName(&role), esutils.SecurityRefreshFor(targetInstance.Url, role.Spec.Refresh)); err != nil {
 				return ctrl.Result{}, err
 			}
 
 			controllerutil.RemoveFinalizer(&role, finalizer)
+			utils.ForgetAppliedBody(&role)
 			if err := r.Update(ctx, &role); err != nil {
 				return ctrl.Result{}, err
 			}
@@ -117,8 +198,22 @@ func (r *ElasticsearchRoleReconciler) Reconcile(ctx context.Context, req ctrl.Re
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ElasticsearchRoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.ElasticsearchRole{}, utils.PriorityCritical)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.ElasticsearchRole{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.ElasticsearchRole{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.ElasticsearchRole{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.ElasticsearchRole{}, r.Recorder)
+	esutils.RegisterFailoverCleanup(&eseckv1alpha1.ElasticsearchRole{}, func(esClient *elasticsearch.Client, url string, obj client.Object) error {
+		role := obj.(*eseckv1alpha1.ElasticsearchRole)
+		_, err := esutils.DeleteRole(esClient, utils.RemoteName(role), esutils.SecurityRefreshFor(url, role.Spec.Refresh))
+		return err
+	})
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&eseckv1alpha1.ElasticsearchRole{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
 		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
 }
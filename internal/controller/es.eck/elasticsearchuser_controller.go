@@ -19,9 +19,10 @@ package eseck
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
 	esutils "eck-custom-resources/utils/elasticsearch"
 
@@ -29,6 +30,7 @@ import (
 
 	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
 
+	"github.com/elastic/go-elasticsearch/v8"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -43,10 +45,11 @@ import (
 type ElasticsearchUserReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 }
 
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=es.eck.github.com,resources=elasticsearchusers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=es.eck.github.com,resources=elasticsearchusers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=es.eck.github.com,resources=elasticsearchusers/finalizers,verbs=update
@@ -66,13 +69,13 @@ func (r *ElasticsearchUserReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	// Convenience locals
 	desiredGen := user.GetGeneration()
 
-	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &user, r.ProjectConfig.Elasticsearch, user.Spec.TargetConfig, req.Namespace)
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &user, r.ProjectConfig.Load().Elasticsearch, user.Spec.TargetConfig, req.Namespace)
 	if err != nil {
 		return utils.GetRequeueResult(), err
 	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
 
-	if !targetInstance.Enabled {
-		logger.Info("Elasticsearch reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &user, &user.Status.Conditions, targetInstance.Enabled) {
 		return ctrl.Result{}, nil
 	}
 
@@ -87,6 +90,18 @@ func (r *ElasticsearchUserReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
 	}
 
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &user, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &user, &user.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
+	if permitted, res := esutils.CheckSecurityPrivileges(ctx, r.Client, r.Recorder, &user, &user.Status.Conditions, esClient, *targetInstance); !permitted {
+		return res, nil
+	}
+
 	if user.DeletionTimestamp.IsZero() {
 		if condition := apimeta.FindStatusCondition(user.Status.Conditions, "Ready"); condition != nil {
 			if condition.Status == metav1.ConditionTrue {
@@ -120,8 +135,78 @@ func (r *ElasticsearchUserReconciler) Reconcile(ctx context.Context, req ctrl.Re
 
 			}
 		}
+		upsert, adoption, err := esutils.ResolveSecurityAdoption(&user, controllerutil.ContainsFinalizer(&user, finalizer), user.Status.Adoption, func() (map[string]interface{}, error) {
+			return esutils.GetUserDefinition(esClient, user.Name)
+		})
+		if err != nil {
+			return utils.GetRequeueResult(), err
+		}
+		if !upsert {
+			if adoption != user.Status.Adoption {
+				r.Recorder.Event(&user, "Normal", "Adopted",
+					fmt.Sprintf("Adopted the existing user %s, the body is applied from its next change on", user.Name))
+			}
+			if err := r.addFinalizer(&user, finalizer, ctx); err != nil {
+				return ctrl.Result{}, err
+			}
+			user.Status.Adoption = adoption
+			user.Status.ObservedGeneration = desiredGen
+			userSetCondition(&user, metav1.Condition{
+				Type:               "Ready",
+				Status:             metav1.ConditionTrue,
+				Reason:             "Adopted",
+				Message:            "Adopted the existing user",
+				ObservedGeneration: desiredGen,
+				LastTransitionTime: metav1.Now(),
+			})
+			if perr := r.Status().Patch(ctx, &user, client.MergeFrom(&eseckv1alpha1.ElasticsearchUser{Status: *oldStatus})); perr != nil {
+				r.Recorder.Event(&user, "Warning", "patching",
+					fmt.Sprintf("patching status after error %v", perr))
+			}
+			return ctrl.Result{}, nil
+		}
+
+		missingRoles, err := esutils.MissingUserRoles(esClient, user.Spec.GetBody())
+		if err != nil {
+			return utils.GetRequeueResult(), err
+		}
+		if len(missingRoles) > 0 {
+			// Elasticsearch would accept the user with dangling roles, wait for them to be created instead
+			message := fmt.Sprintf("Waiting for the roles %s to exist", strings.Join(missingRoles, ", "))
+			if pending := apimeta.FindStatusCondition(user.Status.Conditions, esutils.PendingConditionType); pending == nil || pending.Message != message {
+				r.Recorder.Event(&user, "Warning", esutils.MissingRolesReason, message)
+			}
+			userSetCondition(&user, metav1.Condition{
+				Type:               esutils.PendingConditionType,
+				Status:             metav1.ConditionTrue,
+				Reason:             esutils.MissingRolesReason,
+				Message:            message,
+				ObservedGeneration: desiredGen,
+				LastTransitionTime: metav1.Now(),
+			})
+			userSetCondition(&user, metav1.Condition{
+				Type:               "Ready",
+				Status:             metav1.ConditionFalse,
+				Reason:             esutils.MissingRolesReason,
+				Message:            message,
+				ObservedGeneration: desiredGen,
+				LastTransitionTime: metav1.Now(),
+			})
+			if perr := r.Status().Patch(ctx, &user, client.MergeFrom(&eseckv1alpha1.ElasticsearchUser{Status: *oldStatus})); perr != nil {
+				r.Recorder.Event(&user, "Warning", "patching",
+					fmt.Sprintf("patching status after error %v", perr))
+			}
+			return utils.GetRequeueResult(), nil
+		}
+		apimeta.RemoveStatusCondition(&user.Status.Conditions, esutils.PendingConditionType)
+
 		logger.Info("Creating/Updating User", "user", req.Name)
-		res, err := esutils.UpsertUser(esClient, r.Client, ctx, user)
+		res, err := esutils.UpsertUser(esClient, r.Client, ctx, user, esutils.SecurityRefreshFor(targetInstance.Url, user.Spec.Refresh))
+		if err == nil {
+			if err = esutils.ReconcileUserSecret(r.Client, ctx, &user, targetInstance.Url); err != nil {
+				err = fmt.Errorf("failed to generate Secret %s: %w", user.GetGeneratedSecretName(), err)
+			}
+		}
 
 		if err != nil {
 			r.Recorder.Event(&user, "Warning", "Failed to create/update",
@@ -135,6 +220,7 @@ func (r *ElasticsearchUserReconciler) Reconcile(ctx context.Context, req ctrl.Re
 				ObservedGeneration: desiredGen,
 				LastTransitionTime: metav1.Now(),
 			})
+			utils.SetReadyCondition(&user.Status.Conditions, desiredGen, err)
 			if perr := r.Status().Patch(ctx, &user, client.MergeFrom(&eseckv1alpha1.ElasticsearchUser{Status: *oldStatus})); perr != nil {
 				r.Recorder.Event(&user, "Warning", "patching",
 					fmt.Sprintf("patching status after error %v", perr))
@@ -159,13 +245,16 @@ func (r *ElasticsearchUserReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		if err := r.addFinalizer(&user, finalizer, ctx); err != nil {
 			return ctrl.Result{}, err
 		}
+		if annotationErr := utils.UpdateSyncAnnotations(r.Client, ctx, &user, user.Spec); annotationErr != nil {
+			logger.Error(annotationErr, "Failed to update ElasticsearchUser sync annotations")
+		}
 		return res, err
 
 	} else {
 		// The object is being deleted
 		if controllerutil.ContainsFinalizer(&user, finalizer) {
 			logger.Info("Deleting object", "user", user.Name)
-			if _, err := esutils.DeleteUser(esClient, req.Name); err != nil {
+			if _, err := esutils.DeleteUser(esClient, req.Name, esutils.SecurityRefreshFor(targetInstance.Url, user.Spec.Refresh)); err != nil {
 				return ctrl.Result{}, err
 			}
 
@@ -189,10 +278,24 @@ func userSetCondition(obj *eseckv1alpha1.ElasticsearchUser, c metav1.Condition)
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ElasticsearchUserReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.ElasticsearchUser{}, utils.PriorityCritical)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.ElasticsearchUser{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.ElasticsearchUser{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.ElasticsearchUser{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.ElasticsearchUser{}, r.Recorder)
+	esutils.RegisterFailoverCleanup(&eseckv1alpha1.ElasticsearchUser{}, func(esClient *elasticsearch.Client, url string, obj client.Object) error {
+		user := obj.(*eseckv1alpha1.ElasticsearchUser)
+		_, err := esutils.DeleteUser(esClient, user.Name, esutils.SecurityRefreshFor(url, user.Spec.Refresh))
+		return err
+	})
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&eseckv1alpha1.ElasticsearchUser{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
 		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
 }
 
 func (r *ElasticsearchUserReconciler) addFinalizer(o client.Object, finalizer string, ctx context.Context) error {
@@ -0,0 +1,213 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eseck
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	"eck-custom-resources/utils/overlay"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+)
+
+// EnvironmentOverlayReconciler reconciles a EnvironmentOverlay object
+type EnvironmentOverlayReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=es.eck.github.com,resources=environmentoverlays,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=es.eck.github.com,resources=environmentoverlays/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=es.eck.github.com,resources=environmentoverlays/finalizers,verbs=update
+
+// Reconcile validates the overlay and triggers a reconcile of the resources it selects, and of the resources it
+// selected before, so that their bodies are sent again with the overlay applied or removed.
+func (r *EnvironmentOverlayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	finalizer := "environmentoverlays.es.eck.github.com/finalizer"
+
+	var environmentOverlay eseckv1alpha1.EnvironmentOverlay
+	if err := r.Get(ctx, req.NamespacedName, &environmentOverlay); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !environmentOverlay.DeletionTimestamp.IsZero() {
+		// The object is being deleted
+		if controllerutil.ContainsFinalizer(&environmentOverlay, finalizer) {
+			logger.Info("Deleting object", "environmentOverlay", environmentOverlay.Name)
+			if err := r.triggerResourcesReconcile(ctx, req.Namespace, environmentOverlay.Status.Selected, nil); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			controllerutil.RemoveFinalizer(&environmentOverlay, finalizer)
+			if err := r.Update(ctx, &environmentOverlay); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	selectedKeys := environmentOverlay.Status.Selected
+	err := overlay.Validate(environmentOverlay.Spec)
+	if err == nil {
+		var selected []unstructured.Unstructured
+		if selected, err = r.selectedResources(ctx, &environmentOverlay); err != nil {
+			return utils.GetRequeueResult(), err
+		}
+		if err := r.triggerResourcesReconcile(ctx, req.Namespace, environmentOverlay.Status.Selected, selected); err != nil {
+			return ctrl.Result{}, err
+		}
+		selectedKeys = resourceKeys(selected)
+
+		r.Recorder.Event(&environmentOverlay, "Normal", "Created",
+			fmt.Sprintf("Created/Updated %s/%s %s, selecting %d resources", environmentOverlay.APIVersion, environmentOverlay.Kind, environmentOverlay.Name, len(selected)))
+	} else {
+		r.Recorder.Event(&environmentOverlay, "Warning", "Invalid",
+			fmt.Sprintf("Invalid %s/%s %s: %s", environmentOverlay.APIVersion, environmentOverlay.Kind, environmentOverlay.Name, err.Error()))
+	}
+
+	if !controllerutil.ContainsFinalizer(&environmentOverlay, finalizer) {
+		controllerutil.AddFinalizer(&environmentOverlay, finalizer)
+		if err := r.Update(ctx, &environmentOverlay); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	environmentOverlay.Status.Selected = selectedKeys
+	if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &environmentOverlay, environmentOverlay.Spec, &environmentOverlay.Status.Conditions, &environmentOverlay.Status.ObservedGeneration, err); statusErr != nil {
+		logger.Error(statusErr, "Failed to update EnvironmentOverlay sync status")
+	}
+	// An invalid overlay is not retried, it is reconciled again once its spec changes
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *EnvironmentOverlayReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.EnvironmentOverlay{})
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.EnvironmentOverlay{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.EnvironmentOverlay{}, r.Recorder)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&eseckv1alpha1.EnvironmentOverlay{}).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithOptions(metrics.Options()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), r))))
+}
+
+// selectedResources lists the resources of all supported kinds in the namespace of the overlay which it selects
+func (r *EnvironmentOverlayReconciler) selectedResources(ctx context.Context, environmentOverlay *eseckv1alpha1.EnvironmentOverlay) ([]unstructured.Unstructured, error) {
+	var selected []unstructured.Unstructured
+	for _, gvk := range overlay.SupportedKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := utils.ListPaged(ctx, r.Client, list, client.InNamespace(environmentOverlay.Namespace)); err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			ok, err := overlay.Selects(*environmentOverlay, gvk.Kind, item.GetLabels())
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				item.SetGroupVersionKind(gvk)
+				selected = append(selected, item)
+			}
+		}
+	}
+	return selected, nil
+}
+
+// triggerResourcesReconcile triggers a reconcile of the selected resources and of the resources, given as
+// Kind/name, selected before which are no longer selected
+func (r *EnvironmentOverlayReconciler) triggerResourcesReconcile(ctx context.Context, namespace string, previouslySelected []string, selected []unstructured.Unstructured) error {
+	logger := log.FromContext(ctx)
+
+	stillSelected := make(map[string]bool, len(selected))
+	for _, key := range resourceKeys(selected) {
+		stillSelected[key] = true
+	}
+	for _, key := range previouslySelected {
+		if stillSelected[key] {
+			continue
+		}
+		resource, err := r.getSupportedResource(ctx, namespace, key)
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		if err == nil && resource != nil {
+			selected = append(selected, *resource)
+		}
+	}
+
+	for _, resource := range selected {
+		logger.V(6).Info("Triggering reconcile for selected resource", "GVK", resource.GroupVersionKind(), "Name", resource.GetName(), "Namespace", resource.GetNamespace())
+
+		annotations := resource.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[utils.LastUpdateTriggeredAtAnnotation] = fmt.Sprintf("%d", time.Now().UnixMilli())
+		resource.SetAnnotations(annotations)
+
+		if err := r.Update(ctx, &resource); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getSupportedResource returns the resource identified by its Kind/name key, nil if the kind is not supported
+func (r *EnvironmentOverlayReconciler) getSupportedResource(ctx context.Context, namespace string, key string) (*unstructured.Unstructured, error) {
+	for _, gvk := range overlay.SupportedKinds {
+		name, ok := strings.CutPrefix(key, gvk.Kind+"/")
+		if !ok {
+			continue
+		}
+		resource := &unstructured.Unstructured{}
+		resource.SetGroupVersionKind(gvk)
+		if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, resource); err != nil {
+			return nil, err
+		}
+		return resource, nil
+	}
+	return nil, nil
+}
+
+func resourceKeys(resources []unstructured.Unstructured) []string {
+	keys := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		keys = append(keys, resource.GetKind()+"/"+resource.GetName())
+	}
+	sort.Strings(keys)
+	return keys
+}
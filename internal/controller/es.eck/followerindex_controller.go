@@ -0,0 +1,170 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eseck
+
+import (
+	"context"
+	"fmt"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
+
+	"k8s.io/client-go/tools/record"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+)
+
+// FollowerIndexReconciler reconciles a FollowerIndex object
+type FollowerIndexReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=followerindices,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=followerindices/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=followerindices/finalizers,verbs=update
+
+func (r *FollowerIndexReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	finalizer := "followerindices.es.eck.github.com/finalizer"
+
+	var followerIndex eseckv1alpha1.FollowerIndex
+	if err := r.Get(ctx, req.NamespacedName, &followerIndex); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &followerIndex, r.ProjectConfig.Load().Elasticsearch, followerIndex.Spec.TargetConfig, req.Namespace)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
+
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &followerIndex, &followerIndex.Status.Conditions, targetInstance.Enabled) {
+		return ctrl.Result{}, nil
+	}
+
+	targetInstanceNamespace := req.Namespace
+	if followerIndex.Spec.TargetConfig.ElasticsearchInstanceNamespace != "" {
+		targetInstanceNamespace = followerIndex.Spec.TargetConfig.ElasticsearchInstanceNamespace
+	}
+
+	esClient, createClientErr := esutils.GetElasticsearchClient(r.Client, ctx, *targetInstance, req, targetInstanceNamespace)
+	if createClientErr != nil {
+		logger.Error(createClientErr, "Failed to create Elasticsearch client")
+		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
+	}
+
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &followerIndex, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &followerIndex, &followerIndex.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
+	if !followerIndex.DeletionTimestamp.IsZero() {
+		if controllerutil.ContainsFinalizer(&followerIndex, finalizer) {
+			logger.Info("Deleting object", "followerIndex", followerIndex.Name)
+			if err := esutils.UnfollowIndex(esClient, followerIndex.Name); err != nil {
+				return utils.GetRequeueResult(), err
+			}
+
+			controllerutil.RemoveFinalizer(&followerIndex, finalizer)
+			if err := r.Update(ctx, &followerIndex); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Replication needs a connection to the remote cluster, its state is reported even while the replication is paused
+	remoteErr := esutils.CheckRemoteCluster(esClient, followerIndex.Spec.RemoteCluster)
+	followerIndex.Status.RemoteClusterConnected = remoteErr == nil
+	if remoteErr != nil && !followerIndex.Spec.Paused {
+		r.Recorder.Event(&followerIndex, "Warning", "RemoteClusterUnavailable", remoteErr.Error())
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &followerIndex, followerIndex.Spec, &followerIndex.Status.Conditions, &followerIndex.Status.ObservedGeneration, remoteErr); statusErr != nil {
+			logger.Error(statusErr, "Failed to update FollowerIndex sync status")
+		}
+		return utils.GetRequeueResult(), nil
+	}
+
+	logger.Info("Creating/Updating follower index", "followerIndex", req.Name)
+	status, err := esutils.ReconcileFollowerIndex(esClient, followerIndex)
+	if err == nil {
+		r.Recorder.Event(&followerIndex, "Normal", "Created",
+			fmt.Sprintf("Created/Updated %s/%s %s, replication is %s", followerIndex.APIVersion, followerIndex.Kind, followerIndex.Name, status))
+	} else {
+		r.Recorder.Event(&followerIndex, "Warning", "Failed to create/update",
+			fmt.Sprintf("Failed to create/update %s/%s %s: %s", followerIndex.APIVersion, followerIndex.Kind, followerIndex.Name, err.Error()))
+	}
+	if status != "" {
+		followerIndex.Status.ReplicationStatus = status
+	}
+
+	if err := r.addFinalizer(&followerIndex, finalizer, ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &followerIndex, followerIndex.Spec, &followerIndex.Status.Conditions, &followerIndex.Status.ObservedGeneration, err); statusErr != nil {
+		logger.Error(statusErr, "Failed to update FollowerIndex sync status")
+	}
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *FollowerIndexReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.FollowerIndex{}, utils.PriorityNormal)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.FollowerIndex{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.FollowerIndex{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.FollowerIndex{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.FollowerIndex{}, r.Recorder)
+	esutils.RegisterFailoverCleanup(&eseckv1alpha1.FollowerIndex{}, func(esClient *elasticsearch.Client, _ string, obj client.Object) error {
+		return esutils.UnfollowIndex(esClient, obj.GetName())
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&eseckv1alpha1.FollowerIndex{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
+
+func (r *FollowerIndexReconciler) addFinalizer(o client.Object, finalizer string, ctx context.Context) error {
+	if !controllerutil.ContainsFinalizer(o, finalizer) {
+		controllerutil.AddFinalizer(o, finalizer)
+		if err := r.Update(ctx, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eseck
+
+import (
+	"context"
+	"fmt"
+
+	kibanaUtils "eck-custom-resources/utils/kibana"
+
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileGeneratedDataView creates, updates or deletes the DataView generated for the Ready resource of the kind
+// from its annotations, see kibanaUtils.DataViewSpaceAnnotation
+func reconcileGeneratedDataView(ctx context.Context, cli client.Client, recorder record.EventRecorder, owner client.Object, kind string, patterns []string) error {
+	changed, err := kibanaUtils.ReconcileGeneratedDataView(cli, ctx, owner, kind, patterns)
+	if err != nil {
+		recorder.Event(owner, "Warning", "DataViewError",
+			fmt.Sprintf("Failed to generate DataView %s: %s", kibanaUtils.GeneratedDataViewName(kind, owner.GetName()), err.Error()))
+		return err
+	}
+	if changed {
+		recorder.Event(owner, "Normal", "DataViewGenerated",
+			fmt.Sprintf("Reconciled generated DataView %s", kibanaUtils.GeneratedDataViewName(kind, owner.GetName())))
+	}
+	return nil
+}
@@ -18,11 +18,16 @@ package eseck
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
 	esutils "eck-custom-resources/utils/elasticsearch"
+	kibanaUtils "eck-custom-resources/utils/kibana"
+	"eck-custom-resources/utils/overlay"
+	"eck-custom-resources/utils/quota"
 
 	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
 
@@ -30,23 +35,35 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// indexMigrationPollInterval is how often the reindex task of a running migration to the naming policy is checked
+const indexMigrationPollInterval = 10 * time.Second
+
 // IndexReconciler reconciles a Index object
 type IndexReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=elasticsearch.k8s.elastic.co,resources=elasticsearches,verbs=get
 //+kubebuilder:rbac:groups=es.eck.github.com,resources=indices,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=es.eck.github.com,resources=indices/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=es.eck.github.com,resources=indices/finalizers,verbs=update
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=dataviews,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=indexlifecyclepolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=indextemplates,verbs=get;list;watch
 
 func (r *IndexReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -58,13 +75,13 @@ func (r *IndexReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &index, r.ProjectConfig.Elasticsearch, index.Spec.TargetConfig, req.Namespace)
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &index, r.ProjectConfig.Load().Elasticsearch, index.Spec.TargetConfig, req.Namespace)
 	if err != nil {
 		return utils.GetRequeueResult(), err
 	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
 
-	if !targetInstance.Enabled {
-		logger.Info("Elasticsearch reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &index, &index.Status.Conditions, targetInstance.Enabled) {
 		return ctrl.Result{}, nil
 	}
 
@@ -79,13 +96,78 @@ func (r *IndexReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
 	}
 
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &index, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &index, &index.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
+	if owned, res, err := esutils.CheckOwnership(ctx, r.Client, r.Recorder, &index, &index.Status.Conditions, index.Spec.TargetConfig); err != nil || !owned {
+		if err == nil && !index.DeletionTimestamp.IsZero() && controllerutil.ContainsFinalizer(&index, finalizer) {
+			// The object belongs to the resource owning it, it is left in place
+			controllerutil.RemoveFinalizer(&index, finalizer)
+			return ctrl.Result{}, r.Update(ctx, &index)
+		}
+		return res, err
+	}
+
 	if index.DeletionTimestamp.IsZero() {
-		res, err := r.createUpdate(ctx, req, esClient, index)
+		if protectedErr := esutils.VerifyIndexNotProtected(utils.RemoteName(&index), index.Spec.Force); protectedErr != nil {
+			r.Recorder.Event(&index, "Warning", "Protected index", protectedErr.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &index, index.Spec, &index.Status.Conditions, &index.Status.ObservedGeneration, protectedErr); statusErr != nil {
+				logger.Error(statusErr, "Failed to update Index sync status")
+			}
+			return ctrl.Result{}, nil
+		}
+
+		if mismatch, err := r.detectDataStreamMismatch(ctx, &index); err != nil {
+			return utils.GetRequeueResult(), err
+		} else if mismatch != "" {
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &index, index.Spec, &index.Status.Conditions, &index.Status.ObservedGeneration, errors.New(mismatch)); statusErr != nil {
+				logger.Error(statusErr, "Failed to update Index sync status")
+			}
+			// Requeued until the index templates change
+			return utils.GetRequeueResult(), nil
+		}
+
+		if reason, err := quota.Admit(ctx, r.Client, &index); err != nil {
+			return utils.GetRequeueResult(), err
+		} else if reason != "" {
+			r.Recorder.Event(&index, "Warning", "QuotaExceeded", fmt.Sprintf("Not creating %s: %s", index.GetName(), reason))
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &index, index.Spec, &index.Status.Conditions, &index.Status.ObservedGeneration, errors.New(reason)); statusErr != nil {
+				logger.Error(statusErr, "Failed to update Index sync status")
+			}
+			// Requeued until the quota allows the resource
+			return utils.GetRequeueResult(), nil
+		}
+
+		res, err := r.createUpdate(ctx, esClient, index)
 
 		if err := r.addFinalizer(&index, finalizer, ctx); err != nil {
 			return ctrl.Result{}, err
 		}
 
+		if err == nil {
+			index.Status.ManagedAliases = esutils.IndexAliasNames(index.Spec.Aliases)
+			index.Status.ManagedRuntimeFields = esutils.RuntimeFieldNames(index)
+			res, err = r.migrateLegacyIndex(esClient, &index, res)
+		}
+		if err == nil {
+			res = r.detectUnassignedShards(ctx, esClient, &index, res)
+		}
+
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &index, index.Spec, &index.Status.Conditions, &index.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update Index sync status")
+		}
+
+		if err == nil {
+			if err := reconcileGeneratedDataView(ctx, r.Client, r.Recorder, &index, "Index", []string{utils.RemoteName(&index)}); err != nil {
+				return utils.GetRequeueResult(), err
+			}
+		}
+
 		return utils.RecordEventAndReturn(res, err, r.Recorder, utils.Event{
 			Object:  &index,
 			Name:    req.Name,
@@ -95,9 +177,13 @@ func (r *IndexReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	} else {
 		// The object is being deleted
 		if controllerutil.ContainsFinalizer(&index, finalizer) {
-			logger.Info("Deleting object", "index", index.Name)
-			if _, err := esutils.DeleteIndexIfEmpty(esClient, req.Name); err != nil {
-				return ctrl.Result{}, err
+			if esutils.VerifyIndexNotProtected(utils.RemoteName(&index), index.Spec.Force) != nil {
+				logger.Info("Not deleting protected index", "index", index.Name)
+			} else {
+				logger.Info("Deleting object", "index", index.Name)
+				if _, err := esutils.DeleteIndexIfEmpty(esClient, utils.RemoteName(&index)); err != nil {
+					return ctrl.Result{}, err
+				}
 			}
 
 			controllerutil.RemoveFinalizer(&index, finalizer)
@@ -110,30 +196,95 @@ func (r *IndexReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	}
 }
 
-func (r *IndexReconciler) createUpdate(ctx context.Context, req ctrl.Request, esClient *elasticsearch.Client, index eseckv1alpha1.Index) (ctrl.Result, error) {
-	logger := log.FromContext(ctx)
-
+func (r *IndexReconciler) createUpdate(ctx context.Context, esClient *elasticsearch.Client, index eseckv1alpha1.Index) (ctrl.Result, error) {
 	if err := esutils.DependenciesFulfilled(esClient, index.Spec.Dependencies); err != nil {
 		r.Recorder.Event(&index, "Warning", "Missing dependencies",
 			fmt.Sprintf("Some of declared dependencies are not present yet: %s", err.Error()))
 		return utils.GetRequeueResult(), err
 	}
 
-	indexExists, indexExistsErr := esutils.VerifyIndexExists(esClient, req.Name)
+	body, err := overlay.Apply(r.Client, ctx, &index, index.Spec.GetBody())
+	if err != nil {
+		r.Recorder.Event(&index, "Warning", "OverlayError",
+			fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+		return utils.GetRequeueResult(), err
+	}
+	index.Spec.Body = body
+
+	if index.Spec.ILMPolicyRef != nil {
+		if err := esutils.VerifyIndexLifecyclePolicyReady(r.Client, ctx, index.Spec.ILMPolicyRef, index.Namespace); err != nil {
+			r.Recorder.Event(&index, "Warning", "ILM policy not ready",
+				fmt.Sprintf("Waiting for the referenced IndexLifecyclePolicy: %s", err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+		body, err := esutils.InjectIndexLifecyclePolicy(index.Spec.GetBody(), index.Spec.ILMPolicyRef.Name, "settings")
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		index.Spec.Body = body
+	}
+
+	if err := utils.LintBody(&index, index.Spec.GetBody()); err != nil {
+		r.Recorder.Event(&index, "Warning", utils.PolicyViolationReason, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	res, err := r.createUpdateIndex(ctx, esClient, index)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// migrateLegacyIndex renames the index named before the naming policy applied, tracking the reindex task in the
+// status. The resource is polled while the task runs.
+func (r *IndexReconciler) migrateLegacyIndex(esClient *elasticsearch.Client, index *eseckv1alpha1.Index, res ctrl.Result) (ctrl.Result, error) {
+	legacyName, ok := utils.LegacyName(index)
+	if !ok {
+		index.Status.MigrationTask = ""
+		return res, nil
+	}
+	started := index.Status.MigrationTask == ""
+	migrated, running, err := esutils.MigrateIndex(esClient, legacyName, utils.RemoteName(index), &index.Status.MigrationTask)
+	if err != nil {
+		r.Recorder.Event(index, "Warning", "MigrationFailed",
+			fmt.Sprintf("Failed to rename index %s to %s: %s", legacyName, utils.RemoteName(index), err.Error()))
+		return utils.GetRequeueResult(), err
+	}
+	if running {
+		if started {
+			r.Recorder.Event(index, "Normal", "MigrationStarted",
+				fmt.Sprintf("Blocked writes to index %s and started reindexing it into %s", legacyName, utils.RemoteName(index)))
+		}
+		return ctrl.Result{RequeueAfter: indexMigrationPollInterval}, nil
+	}
+	if migrated {
+		r.Recorder.Event(index, "Normal", "Migrated",
+			fmt.Sprintf("Renamed index %s to %s, the old name is kept as alias", legacyName, utils.RemoteName(index)))
+	}
+	return res, nil
+}
+
+func (r *IndexReconciler) createUpdateIndex(ctx context.Context, esClient *elasticsearch.Client, index eseckv1alpha1.Index) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	indexName := utils.RemoteName(&index)
+
+	indexExists, indexExistsErr := esutils.VerifyIndexExists(esClient, indexName)
 	if indexExistsErr != nil {
 		logger.Error(indexExistsErr, "Failed to verify if index exists")
 		return ctrl.Result{}, indexExistsErr
 	}
 
 	if indexExists {
-		isEmpty, indexEmptyErr := esutils.VerifyIndexEmpty(esClient, req.Name)
+		isEmpty, indexEmptyErr := esutils.VerifyIndexEmpty(esClient, indexName)
 		if indexEmptyErr != nil {
 			logger.Error(indexExistsErr, "Failed to verify if index is empty")
 			return utils.GetRequeueResult(), client.IgnoreNotFound(indexEmptyErr)
 		}
 
 		if isEmpty {
-			_, deleteErr := esutils.DeleteIndex(esClient, req.Name)
+			_, deleteErr := esutils.DeleteIndex(esClient, indexName)
 			if deleteErr != nil {
 				logger.Error(deleteErr, "Failed to delete index")
 				return utils.GetRequeueResult(), client.IgnoreNotFound(deleteErr)
@@ -148,10 +299,88 @@ func (r *IndexReconciler) createUpdate(ctx context.Context, req ctrl.Request, es
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *IndexReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.Index{}, utils.PriorityNormal)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.Index{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.Index{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.Index{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.Index{}, r.Recorder)
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &eseckv1alpha1.Index{}, esutils.ILMPolicyRefIndexField, func(obj client.Object) []string {
+		index := obj.(*eseckv1alpha1.Index)
+		if index.Spec.ILMPolicyRef == nil {
+			return nil
+		}
+		return []string{esutils.ILMPolicyRefKey(index.Spec.ILMPolicyRef, index.Namespace)}
+	}); err != nil {
+		return err
+	}
+
+	esutils.RegisterFailoverCleanup(&eseckv1alpha1.Index{}, func(esClient *elasticsearch.Client, _ string, obj client.Object) error {
+		index := obj.(*eseckv1alpha1.Index)
+		if esutils.VerifyIndexNotProtected(utils.RemoteName(index), index.Spec.Force) != nil {
+			return nil
+		}
+		_, err := esutils.DeleteIndexIfEmpty(esClient, utils.RemoteName(index))
+		return err
+	})
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&eseckv1alpha1.Index{}).
-		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+		For(&eseckv1alpha1.Index{}, builder.WithPredicates(
+			syncWave.Filter(), predicate.Or(utils.CommonEventFilter(), kibanaUtils.DataViewAnnotationsChangedFilter()), priority.Filter())).
+		Watches(&eseckv1alpha1.IndexLifecyclePolicy{}, handler.EnqueueRequestsFromMapFunc(r.requestsForIndexLifecyclePolicy),
+			builder.WithPredicates(esutils.IndexLifecyclePolicyReadyChangedFilter())).
+		WithOptions(metrics.Options()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
+
+// detectDataStreamMismatch sets the DataStreamMismatch condition of the index, recording an event when the data stream
+// templates applying to it change. It returns the message of the condition, empty if there is no mismatch.
+func (r *IndexReconciler) detectDataStreamMismatch(ctx context.Context, index *eseckv1alpha1.Index) (string, error) {
+	var indexTemplates eseckv1alpha1.IndexTemplateList
+	if err := r.List(ctx, &indexTemplates); err != nil {
+		return "", err
+	}
+	templates := esutils.FindDataStreamTemplatesOfIndex(*index, indexTemplates.Items)
+	message, changed := esutils.SetIndexDataStreamMismatchCondition(&index.Status.Conditions, index.Generation, utils.RemoteName(index), templates)
+	if changed && message != "" {
+		r.Recorder.Event(index, "Warning", "DataStreamMismatch", message)
+	}
+	return message, nil
+}
+
+// detectUnassignedShards sets the ShardsUnassigned condition of the index, recording an event when the unassigned
+// shards change, and has the index checked again after ShardAllocationRecheckInterval while there are any. A failure to
+// explain the allocation is logged, it does not fail the reconcile.
+func (r *IndexReconciler) detectUnassignedShards(ctx context.Context, esClient *elasticsearch.Client, index *eseckv1alpha1.Index, res ctrl.Result) ctrl.Result {
+	shards, err := esutils.GetUnassignedShards(esClient, utils.RemoteName(index))
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to explain the shard allocation of the index")
+		return res
+	}
+	message, changed := esutils.SetShardsUnassignedCondition(&index.Status.Conditions, index.Generation, utils.RemoteName(index), shards)
+	if message == "" {
+		return res
+	}
+	if changed {
+		r.Recorder.Event(index, "Warning", esutils.ShardsUnassignedReason, message)
+	}
+	if recheck := utils.Jitter(esutils.ShardAllocationRecheckInterval); res.RequeueAfter == 0 || recheck < res.RequeueAfter {
+		res.RequeueAfter = recheck
+	}
+	return res
+}
+
+// requestsForIndexLifecyclePolicy returns the indices referencing the IndexLifecyclePolicy
+func (r *IndexReconciler) requestsForIndexLifecyclePolicy(ctx context.Context, policy client.Object) []reconcile.Request {
+	var indices eseckv1alpha1.IndexList
+	if err := r.List(ctx, &indices, client.MatchingFields{esutils.ILMPolicyRefIndexField: client.ObjectKeyFromObject(policy).String()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list indices referencing IndexLifecyclePolicy", "policy", policy.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(indices.Items))
+	for _, index := range indices.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&index)})
+	}
+	return requests
 }
 
 func (r *IndexReconciler) addFinalizer(o client.Object, finalizer string, ctx context.Context) error {
@@ -22,10 +22,12 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
 )
 
 var _ = Describe("Index Controller", func() {
@@ -241,4 +243,77 @@ var _ = Describe("Index Controller", func() {
 			}, timeout, interval).Should(BeTrue())
 		})
 	})
+
+	Context("When reconciling an Index", func() {
+		It("Should create the index in Elasticsearch and delete it with the resource", func() {
+			ctx := context.Background()
+
+			indexName := "test-index-reconcile"
+			index := &eseckv1alpha1.Index{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      indexName,
+					Namespace: IndexNamespace,
+				},
+				Spec: eseckv1alpha1.IndexSpec{
+					Body: `{"settings": {"number_of_shards": 1}}`,
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, index)).Should(Succeed())
+
+			indexLookupKey := types.NamespacedName{Name: indexName, Namespace: IndexNamespace}
+			Eventually(func() bool {
+				return fakeES.Exists(testutils.ESIndex, indexName)
+			}, timeout, interval).Should(BeTrue())
+
+			reconciledIndex := &eseckv1alpha1.Index{}
+			Eventually(func() []string {
+				_ = k8sClient.Get(ctx, indexLookupKey, reconciledIndex)
+				return reconciledIndex.Finalizers
+			}, timeout, interval).Should(ContainElement("indices.es.eck.github.com/finalizer"))
+
+			Expect(k8sClient.Delete(ctx, reconciledIndex)).Should(Succeed())
+
+			Eventually(func() bool {
+				return fakeES.Exists(testutils.ESIndex, indexName)
+			}, timeout, interval).Should(BeFalse())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, indexLookupKey, &eseckv1alpha1.Index{})
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+		})
+
+		It("Should keep a non-empty index in Elasticsearch", func() {
+			ctx := context.Background()
+
+			indexName := "test-index-reconcile-non-empty"
+			index := &eseckv1alpha1.Index{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      indexName,
+					Namespace: IndexNamespace,
+				},
+				Spec: eseckv1alpha1.IndexSpec{
+					Body: `{"settings": {"number_of_shards": 1}}`,
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, index)).Should(Succeed())
+
+			indexLookupKey := types.NamespacedName{Name: indexName, Namespace: IndexNamespace}
+			reconciledIndex := &eseckv1alpha1.Index{}
+			Eventually(func() []string {
+				_ = k8sClient.Get(ctx, indexLookupKey, reconciledIndex)
+				return reconciledIndex.Finalizers
+			}, timeout, interval).Should(ContainElement("indices.es.eck.github.com/finalizer"))
+
+			fakeES.SetDocumentCount(indexName, 10)
+			Expect(k8sClient.Delete(ctx, reconciledIndex)).Should(Succeed())
+
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, indexLookupKey, &eseckv1alpha1.Index{})
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+			Expect(fakeES.Exists(testutils.ESIndex, indexName)).Should(BeTrue())
+		})
+	})
 })
@@ -19,14 +19,18 @@ package eseck
 import (
 	"context"
 	"fmt"
+	"time"
 
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
 	esutils "eck-custom-resources/utils/elasticsearch"
+	"eck-custom-resources/utils/overlay"
 
 	"k8s.io/client-go/tools/record"
 
+	"github.com/elastic/go-elasticsearch/v8"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -39,7 +43,7 @@ import (
 type IndexLifecyclePolicyReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 }
 
@@ -57,13 +61,13 @@ func (r *IndexLifecyclePolicyReconciler) Reconcile(ctx context.Context, req ctrl
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &indexLifecyclePolicy, r.ProjectConfig.Elasticsearch, indexLifecyclePolicy.Spec.TargetConfig, req.Namespace)
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &indexLifecyclePolicy, r.ProjectConfig.Load().Elasticsearch, indexLifecyclePolicy.Spec.TargetConfig, req.Namespace)
 	if err != nil {
 		return utils.GetRequeueResult(), err
 	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
 
-	if !targetInstance.Enabled {
-		logger.Info("Elasticsearch reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &indexLifecyclePolicy, &indexLifecyclePolicy.Status.Conditions, targetInstance.Enabled) {
 		return ctrl.Result{}, nil
 	}
 
@@ -78,13 +82,66 @@ func (r *IndexLifecyclePolicyReconciler) Reconcile(ctx context.Context, req ctrl
 		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
 	}
 
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &indexLifecyclePolicy, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &indexLifecyclePolicy, &indexLifecyclePolicy.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
 	if indexLifecyclePolicy.DeletionTimestamp.IsZero() {
 		logger.Info("Creating/Updating index lifecycle policy", "index lifecycle policy", req.Name)
-		res, err := esutils.UpsertIndexLifecyclePolicy(esClient, indexLifecyclePolicy)
+		body, err := overlay.Apply(r.Client, ctx, &indexLifecyclePolicy, indexLifecyclePolicy.Spec.GetBody())
+		if err != nil {
+			r.Recorder.Event(&indexLifecyclePolicy, "Warning", "OverlayError",
+				fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+		if err := utils.LintBody(&indexLifecyclePolicy, body); err != nil {
+			r.Recorder.Event(&indexLifecyclePolicy, "Warning", utils.PolicyViolationReason, err.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &indexLifecyclePolicy, indexLifecyclePolicy.Spec, &indexLifecyclePolicy.Status.Conditions, &indexLifecyclePolicy.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update IndexLifecyclePolicy sync status")
+			}
+			// The spec or the lint rules have to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+		if errs := esutils.ValidateIndexLifecyclePolicyBody(body, field.NewPath("spec").Child("body")); len(errs) > 0 {
+			err := errs.ToAggregate()
+			r.Recorder.Event(&indexLifecyclePolicy, "Warning", "InvalidBody",
+				fmt.Sprintf("Invalid body of %s: %s", indexLifecyclePolicy.Name, err.Error()))
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &indexLifecyclePolicy, indexLifecyclePolicy.Spec, &indexLifecyclePolicy.Status.Conditions, &indexLifecyclePolicy.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update IndexLifecyclePolicy sync status")
+			}
+			// The spec has to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+		if indexLifecyclePolicy.Spec.SnapshotBeforeDelete != nil {
+			reason, err := esutils.CheckSnapshotBeforeDelete(esClient, req.Name, body, *indexLifecyclePolicy.Spec.SnapshotBeforeDelete, time.Now())
+			if err != nil {
+				r.Recorder.Event(&indexLifecyclePolicy, "Warning", "SnapshotCheckError",
+					fmt.Sprintf("Failed to check the last snapshot for %s: %s", indexLifecyclePolicy.Name, err.Error()))
+				return utils.GetRequeueResult(), err
+			}
+			if reason != "" {
+				logger.Info("Holding back index lifecycle policy update", "indexLifecyclePolicy", indexLifecyclePolicy.Name, "reason", reason)
+				r.Recorder.Event(&indexLifecyclePolicy, "Warning", "SnapshotRequired",
+					fmt.Sprintf("Holding back update of %s: %s", indexLifecyclePolicy.Name, reason))
+				if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &indexLifecyclePolicy, indexLifecyclePolicy.Spec, &indexLifecyclePolicy.Status.Conditions, &indexLifecyclePolicy.Status.ObservedGeneration, fmt.Errorf("update blocked, %s", reason)); statusErr != nil {
+					logger.Error(statusErr, "Failed to update IndexLifecyclePolicy sync status")
+				}
+				// Requeued until the snapshot lifecycle policy completed a snapshot
+				return utils.GetRequeueResult(), nil
+			}
+		}
+
+		patched := indexLifecyclePolicy.DeepCopy()
+		patched.Spec.Body = body
+		res, err := esutils.UpsertIndexLifecyclePolicy(esClient, *patched)
 
 		if err == nil {
 			r.Recorder.Event(&indexLifecyclePolicy, "Normal", "Created",
-				fmt.Sprintf("Created/Updated %s/%s %s", indexLifecyclePolicy.APIVersion, indexLifecyclePolicy.Kind, indexLifecyclePolicy.Name))
+				fmt.Sprintf("Created/Updated %s/%s %s%s", indexLifecyclePolicy.APIVersion, indexLifecyclePolicy.Kind, indexLifecyclePolicy.Name, utils.AppliedBodyChanges(ctx, &indexLifecyclePolicy, targetInstance.Url, body)))
 		} else {
 			r.Recorder.Event(&indexLifecyclePolicy, "Warning", "Failed to create/update",
 				fmt.Sprintf("Failed to create/update %s/%s %s: %s", indexLifecyclePolicy.APIVersion, indexLifecyclePolicy.Kind, indexLifecyclePolicy.Name, err.Error()))
@@ -94,6 +151,18 @@ func (r *IndexLifecyclePolicyReconciler) Reconcile(ctx context.Context, req ctrl
 			return ctrl.Result{}, err
 		}
 
+		if err == nil {
+			// The rollout is informational, failing to count the indices does not fail the reconcile
+			if rollout, rolloutErr := esutils.GetIndexLifecyclePolicyRollout(esClient, req.Name); rolloutErr != nil {
+				logger.Error(rolloutErr, "Failed to get the rollout of the index lifecycle policy")
+			} else {
+				indexLifecyclePolicy.Status.Rollout = rollout
+			}
+		}
+
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &indexLifecyclePolicy, indexLifecyclePolicy.Spec, &indexLifecyclePolicy.Status.Conditions, &indexLifecyclePolicy.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update IndexLifecyclePolicy sync status")
+		}
 		return res, err
 	} else {
 		// The object is being deleted
@@ -104,6 +173,7 @@ func (r *IndexLifecyclePolicyReconciler) Reconcile(ctx context.Context, req ctrl
 			}
 
 			controllerutil.RemoveFinalizer(&indexLifecyclePolicy, finalizer)
+			utils.ForgetAppliedBody(&indexLifecyclePolicy)
 			if err := r.Update(ctx, &indexLifecyclePolicy); err != nil {
 				return ctrl.Result{}, err
 			}
@@ -115,10 +185,23 @@ func (r *IndexLifecyclePolicyReconciler) Reconcile(ctx context.Context, req ctrl
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *IndexLifecyclePolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.IndexLifecyclePolicy{}, utils.PriorityCritical)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.IndexLifecyclePolicy{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.IndexLifecyclePolicy{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.IndexLifecyclePolicy{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.IndexLifecyclePolicy{}, r.Recorder)
+	esutils.RegisterFailoverCleanup(&eseckv1alpha1.IndexLifecyclePolicy{}, func(esClient *elasticsearch.Client, _ string, obj client.Object) error {
+		_, err := esutils.DeleteIndexLifecyclePolicy(esClient, obj.GetName())
+		return err
+	})
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&eseckv1alpha1.IndexLifecyclePolicy{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
 		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
 }
 
 func (r *IndexLifecyclePolicyReconciler) addFinalizer(o client.Object, finalizer string, ctx context.Context) error {
@@ -0,0 +1,205 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eseck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+)
+
+// indexOperationPollInterval is how often the shard allocation of a preparing or running operation is checked
+const indexOperationPollInterval = 10 * time.Second
+
+// IndexOperationReconciler reconciles a IndexOperation object
+type IndexOperationReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=indexoperations,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=indexoperations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=indexoperations/finalizers,verbs=update
+
+func (r *IndexOperationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var operation eseckv1alpha1.IndexOperation
+	if err := r.Get(ctx, req.NamespacedName, &operation); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	// The target index is kept, there is nothing to clean up
+	if !operation.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	spec := operation.Spec
+	status := &operation.Status
+	switch status.Phase {
+	case eseckv1alpha1.IndexOperationPhaseCompleted:
+		return ctrl.Result{}, nil
+	case eseckv1alpha1.IndexOperationPhaseFailed:
+		// A failed operation is not retried, it has to be recreated
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &operation, operation.Spec, &operation.Status.Conditions, &operation.Status.ObservedGeneration, errors.New(status.Message)); statusErr != nil {
+			logger.Error(statusErr, "Failed to update IndexOperation sync status")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	var index eseckv1alpha1.Index
+	if err := r.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: spec.Index}, &index); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return utils.GetRequeueResult(), err
+		}
+		r.Recorder.Event(&operation, "Warning", "IndexNotFound", fmt.Sprintf("Index %s does not exist in namespace %s", spec.Index, req.Namespace))
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &operation, operation.Spec, &operation.Status.Conditions, &operation.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update IndexOperation sync status")
+		}
+		return utils.GetRequeueResult(), nil
+	}
+
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &operation, r.ProjectConfig.Load().Elasticsearch, index.Spec.TargetConfig, req.Namespace)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
+
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &operation, &operation.Status.Conditions, targetInstance.Enabled) {
+		return ctrl.Result{}, nil
+	}
+
+	targetInstanceNamespace := req.Namespace
+	if index.Spec.TargetConfig.ElasticsearchInstanceNamespace != "" {
+		targetInstanceNamespace = index.Spec.TargetConfig.ElasticsearchInstanceNamespace
+	}
+
+	esClient, createClientErr := esutils.GetElasticsearchClient(r.Client, ctx, *targetInstance, req, targetInstanceNamespace)
+	if createClientErr != nil {
+		logger.Error(createClientErr, "Failed to create Elasticsearch client")
+		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
+	}
+
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &operation, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &operation, &operation.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
+	var operationErr error
+	if status.Phase == "" {
+		startTime := metav1.Now()
+		status.Phase = eseckv1alpha1.IndexOperationPhasePreparing
+		status.Source = utils.RemoteName(&index)
+		status.StartTime = &startTime
+		if err := esutils.ValidateIndexOperation(operation); err != nil {
+			status.Phase = eseckv1alpha1.IndexOperationPhaseFailed
+			status.Message = err.Error()
+			operationErr = err
+			r.Recorder.Event(&operation, "Warning", "OperationFailed", err.Error())
+		}
+	}
+
+	switch status.Phase {
+	case eseckv1alpha1.IndexOperationPhasePreparing:
+		ready, err := esutils.PrepareIndexOperationSource(esClient, operation, status.Source)
+		if err != nil {
+			return utils.GetRequeueResult(), err
+		}
+		if !ready {
+			status.Message = fmt.Sprintf("Waiting for a copy of every shard of %s to be allocated to node %s", status.Source, spec.ShrinkNode)
+			break
+		}
+		logger.Info("Starting index operation", "operation", spec.Operation, "source", status.Source, "target", spec.Target)
+		rejected, err := esutils.StartIndexOperation(esClient, operation, status.Source)
+		if err != nil && !rejected {
+			return utils.GetRequeueResult(), err
+		}
+		if err != nil {
+			status.Phase = eseckv1alpha1.IndexOperationPhaseFailed
+			status.Message = err.Error()
+			operationErr = err
+			r.Recorder.Event(&operation, "Warning", "OperationFailed",
+				fmt.Sprintf("Failed to %s index %s to %s: %s", spec.Operation, status.Source, spec.Target, err.Error()))
+			break
+		}
+		status.Phase = eseckv1alpha1.IndexOperationPhaseRunning
+		status.Message = ""
+		r.Recorder.Event(&operation, "Normal", "OperationStarted",
+			fmt.Sprintf("Started %s of index %s to %s", spec.Operation, status.Source, spec.Target))
+	case eseckv1alpha1.IndexOperationPhaseRunning:
+		done, err := esutils.IndexOperationDone(esClient, operation)
+		if err != nil {
+			return utils.GetRequeueResult(), err
+		}
+		if !done {
+			break
+		}
+		if err := esutils.FinishIndexOperation(esClient, operation, status.Source); err != nil {
+			return utils.GetRequeueResult(), err
+		}
+		status.Phase = eseckv1alpha1.IndexOperationPhaseCompleted
+		status.CompletionTime = &metav1.Time{Time: time.Now()}
+		r.Recorder.Event(&operation, "Normal", "OperationCompleted",
+			fmt.Sprintf("Completed %s of index %s to %s", spec.Operation, status.Source, spec.Target))
+	}
+
+	if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &operation, operation.Spec, &operation.Status.Conditions, &operation.Status.ObservedGeneration, operationErr); statusErr != nil {
+		logger.Error(statusErr, "Failed to update IndexOperation sync status")
+	}
+
+	if status.Phase == eseckv1alpha1.IndexOperationPhasePreparing || status.Phase == eseckv1alpha1.IndexOperationPhaseRunning {
+		return utils.RequeueScheduled(ctx, indexOperationPollInterval), nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *IndexOperationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.IndexOperation{}, utils.PriorityNormal)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.IndexOperation{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.IndexOperation{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.IndexOperation{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.IndexOperation{}, r.Recorder)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&eseckv1alpha1.IndexOperation{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
@@ -19,18 +19,26 @@ package eseck
 import (
 	"context"
 	"fmt"
+	"strings"
 
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
 	esutils "eck-custom-resources/utils/elasticsearch"
+	kibanaUtils "eck-custom-resources/utils/kibana"
+	"eck-custom-resources/utils/overlay"
 
 	"k8s.io/client-go/tools/record"
 
+	"github.com/elastic/go-elasticsearch/v8"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
 )
@@ -39,13 +47,16 @@ import (
 type IndexTemplateReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=es.eck.github.com,resources=indextemplates,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=es.eck.github.com,resources=indextemplates/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=es.eck.github.com,resources=indextemplates/finalizers,verbs=update
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=indexlifecyclepolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=indices,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=dataviews,verbs=get;list;watch;create;update;patch;delete
 
 func (r *IndexTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -57,13 +68,13 @@ func (r *IndexTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &indexTemplate, r.ProjectConfig.Elasticsearch, indexTemplate.Spec.TargetConfig, req.Namespace)
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &indexTemplate, r.ProjectConfig.Load().Elasticsearch, indexTemplate.Spec.TargetConfig, req.Namespace)
 	if err != nil {
 		return utils.GetRequeueResult(), err
 	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
 
-	if !targetInstance.Enabled {
-		logger.Info("Elasticsearch reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &indexTemplate, &indexTemplate.Status.Conditions, targetInstance.Enabled) {
 		return ctrl.Result{}, nil
 	}
 
@@ -78,6 +89,23 @@ func (r *IndexTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
 	}
 
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &indexTemplate, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &indexTemplate, &indexTemplate.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
+	if owned, res, err := esutils.CheckOwnership(ctx, r.Client, r.Recorder, &indexTemplate, &indexTemplate.Status.Conditions, indexTemplate.Spec.TargetConfig); err != nil || !owned {
+		if err == nil && !indexTemplate.DeletionTimestamp.IsZero() && controllerutil.ContainsFinalizer(&indexTemplate, finalizer) {
+			// The object belongs to the resource owning it, it is left in place
+			controllerutil.RemoveFinalizer(&indexTemplate, finalizer)
+			return ctrl.Result{}, r.Update(ctx, &indexTemplate)
+		}
+		return res, err
+	}
+
 	if err := esutils.DependenciesFulfilled(esClient, indexTemplate.Spec.Dependencies); err != nil {
 		r.Recorder.Event(&indexTemplate, "Warning", "Missing dependencies",
 			fmt.Sprintf("Some of declared dependencies are not present yet: %s", err.Error()))
@@ -86,11 +114,11 @@ func (r *IndexTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	if indexTemplate.DeletionTimestamp.IsZero() {
 		logger.Info("Creating/Updating index template", "index template", req.Name)
-		res, err := esutils.UpsertIndexTemplate(esClient, indexTemplate)
+		body, res, err := r.createUpdate(ctx, esClient, &indexTemplate)
 
 		if err == nil {
 			r.Recorder.Event(&indexTemplate, "Normal", "Created",
-				fmt.Sprintf("Created/Updated %s/%s %s", indexTemplate.APIVersion, indexTemplate.Kind, indexTemplate.Name))
+				fmt.Sprintf("Created/Updated %s/%s %s%s", indexTemplate.APIVersion, indexTemplate.Kind, indexTemplate.Name, utils.AppliedBodyChanges(ctx, &indexTemplate, targetInstance.Url, body)))
 		} else {
 			r.Recorder.Event(&indexTemplate, "Warning", "Failed to create/update",
 				fmt.Sprintf("Failed to create/update %s/%s %s: %s", indexTemplate.APIVersion, indexTemplate.Kind, indexTemplate.Name, err.Error()))
@@ -99,16 +127,45 @@ func (r *IndexTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		if err := r.addFinalizer(&indexTemplate, finalizer, ctx); err != nil {
 			return ctrl.Result{}, err
 		}
+
+		if err := r.detectConflicts(ctx, &indexTemplate); err != nil {
+			return utils.GetRequeueResult(), err
+		}
+		if impact, err := r.deletionImpact(esClient, indexTemplate); err != nil {
+			logger.Error(err, "Failed to determine the deletion impact of the index template")
+		} else {
+			indexTemplate.Status.DeletionImpact = impact
+		}
+
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &indexTemplate, indexTemplate.Spec, &indexTemplate.Status.Conditions, &indexTemplate.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update IndexTemplate sync status")
+		}
+		if err == nil {
+			if err := r.reconcileGeneratedDataView(ctx, indexTemplate); err != nil {
+				return utils.GetRequeueResult(), err
+			}
+		}
 		return res, err
 	} else {
 		// The object is being deleted
 		if controllerutil.ContainsFinalizer(&indexTemplate, finalizer) {
 			logger.Info("Deleting object", "indexTemplate", indexTemplate.Name)
-			if _, err := esutils.DeleteIndexTemplate(esClient, req.Name); err != nil {
+			if impact, err := r.deletionImpact(esClient, indexTemplate); err != nil {
+				logger.Error(err, "Failed to determine the deletion impact of the index template")
+			} else if impact != nil {
+				eventType := "Normal"
+				if len(impact.UncoveredPatterns) > 0 {
+					eventType = "Warning"
+				}
+				r.Recorder.Event(&indexTemplate, eventType, "DeletionImpact",
+					fmt.Sprintf("Deleting the index template: %s", esutils.FormatIndexTemplateDeletionImpact(*impact)))
+			}
+			if _, err := esutils.DeleteIndexTemplate(esClient, utils.RemoteName(&indexTemplate)); err != nil {
 				return ctrl.Result{}, err
 			}
 
 			controllerutil.RemoveFinalizer(&indexTemplate, finalizer)
+			utils.ForgetAppliedBody(&indexTemplate)
 			if err := r.Update(ctx, &indexTemplate); err != nil {
 				return ctrl.Result{}, err
 			}
@@ -120,10 +177,172 @@ func (r *IndexTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *IndexTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.IndexTemplate{}, utils.PriorityHigh)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.IndexTemplate{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.IndexTemplate{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.IndexTemplate{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.IndexTemplate{}, r.Recorder)
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &eseckv1alpha1.IndexTemplate{}, esutils.ILMPolicyRefIndexField, func(obj client.Object) []string {
+		indexTemplate := obj.(*eseckv1alpha1.IndexTemplate)
+		if indexTemplate.Spec.ILMPolicyRef == nil {
+			return nil
+		}
+		return []string{esutils.ILMPolicyRefKey(indexTemplate.Spec.ILMPolicyRef, indexTemplate.Namespace)}
+	}); err != nil {
+		return err
+	}
+
+	esutils.RegisterFailoverCleanup(&eseckv1alpha1.IndexTemplate{}, func(esClient *elasticsearch.Client, _ string, obj client.Object) error {
+		_, err := esutils.DeleteIndexTemplate(esClient, utils.RemoteName(obj))
+		return err
+	})
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&eseckv1alpha1.IndexTemplate{}).
-		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+		For(&eseckv1alpha1.IndexTemplate{}, builder.WithPredicates(
+			syncWave.Filter(), predicate.Or(utils.CommonEventFilter(), kibanaUtils.DataViewAnnotationsChangedFilter()), priority.Filter())).
+		Watches(&eseckv1alpha1.IndexLifecyclePolicy{}, handler.EnqueueRequestsFromMapFunc(r.requestsForIndexLifecyclePolicy),
+			builder.WithPredicates(esutils.IndexLifecyclePolicyReadyChangedFilter())).
+		WithOptions(metrics.Options()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
+
+// createUpdate upserts the index template, with the policy of spec.ilmPolicyRef attached once it is Ready, and returns
+// the body sent. In the Canary update mode, the outcome of the canary is set in the status of original.
+func (r *IndexTemplateReconciler) createUpdate(ctx context.Context, esClient *elasticsearch.Client, original *eseckv1alpha1.IndexTemplate) (string, ctrl.Result, error) {
+	indexTemplate := *original
+	body, err := overlay.Apply(r.Client, ctx, &indexTemplate, indexTemplate.Spec.GetBody())
+	if err != nil {
+		r.Recorder.Event(&indexTemplate, "Warning", "OverlayError",
+			fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+		return "", utils.GetRequeueResult(), err
+	}
+	indexTemplate.Spec.Body = body
+
+	if indexTemplate.Spec.ILMPolicyRef != nil {
+		if err := esutils.VerifyIndexLifecyclePolicyReady(r.Client, ctx, indexTemplate.Spec.ILMPolicyRef, indexTemplate.Namespace); err != nil {
+			r.Recorder.Event(&indexTemplate, "Warning", "ILM policy not ready",
+				fmt.Sprintf("Waiting for the referenced IndexLifecyclePolicy: %s", err.Error()))
+			return "", utils.GetRequeueResult(), err
+		}
+		body, err := esutils.InjectIndexLifecyclePolicy(indexTemplate.Spec.GetBody(), indexTemplate.Spec.ILMPolicyRef.Name, "template", "settings")
+		if err != nil {
+			return "", ctrl.Result{}, err
+		}
+		indexTemplate.Spec.Body = body
+	}
+
+	if err := utils.LintBody(&indexTemplate, indexTemplate.Spec.GetBody()); err != nil {
+		r.Recorder.Event(&indexTemplate, "Warning", utils.PolicyViolationReason, err.Error())
+		return "", ctrl.Result{}, err
+	}
+	if indexTemplate.Spec.UpdatePolicy.UpdateMode == eseckv1alpha1.IndexTemplateUpdateModeCanary {
+		res, err := r.rolloutCanary(esClient, original, indexTemplate.Spec.GetBody())
+		return indexTemplate.Spec.GetBody(), res, err
+	}
+	res, err := esutils.UpsertIndexTemplate(esClient, indexTemplate)
+	return indexTemplate.Spec.GetBody(), res, err
+}
+
+// rolloutCanary replaces the index template with body once a canary of body passed. The canary is not run again
+// while body and spec.updatePolicy.canary are unchanged, a failed canary keeps blocking the update.
+func (r *IndexTemplateReconciler) rolloutCanary(esClient *elasticsearch.Client, indexTemplate *eseckv1alpha1.IndexTemplate, body string) (ctrl.Result, error) {
+	hash, err := utils.SpecHash([]any{body, indexTemplate.Spec.UpdatePolicy.Canary})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	canary := indexTemplate.Status.Canary
+	if canary != nil && canary.BodyHash == hash && canary.Passed {
+		rendered := *indexTemplate
+		rendered.Spec.Body = body
+		rendered.Spec.BodyJSON = nil
+		return esutils.UpsertIndexTemplate(esClient, rendered)
+	}
+	if canary == nil || canary.BodyHash != hash {
+		results, err := esutils.RolloutIndexTemplate(esClient, *indexTemplate, body)
+		if err != nil {
+			return utils.GetRequeueResult(), err
+		}
+		canary = &eseckv1alpha1.IndexTemplateCanaryStatus{BodyHash: hash, Passed: esutils.IndexTemplateCanaryPassed(results), Results: results}
+		indexTemplate.Status.Canary = canary
+		if !canary.Passed {
+			r.Recorder.Event(indexTemplate, "Warning", "CanaryFailed",
+				fmt.Sprintf("The canary of index template %s failed, see status.canary", indexTemplate.Name))
+		}
+	}
+	if !canary.Passed {
+		return ctrl.Result{}, fmt.Errorf("update blocked, the canary of index template %s failed", indexTemplate.Name)
+	}
+	return ctrl.Result{}, nil
+}
+
+// detectConflicts sets the Conflict and DataStreamMismatch conditions of the index template, recording an event when
+// the IndexTemplates it conflicts with or the Indices it mismatches change
+func (r *IndexTemplateReconciler) detectConflicts(ctx context.Context, indexTemplate *eseckv1alpha1.IndexTemplate) error {
+	var indexTemplates eseckv1alpha1.IndexTemplateList
+	if err := r.List(ctx, &indexTemplates); err != nil {
+		return err
+	}
+	conflicts := esutils.FindIndexTemplateConflicts(*indexTemplate, indexTemplates.Items)
+	if esutils.SetIndexTemplateConflictCondition(&indexTemplate.Status.Conditions, indexTemplate.Generation, conflicts) && len(conflicts) > 0 {
+		r.Recorder.Event(indexTemplate, "Warning", "IndexTemplateConflict",
+			fmt.Sprintf("Index patterns overlap at the same priority with IndexTemplate %s, Elasticsearch only accepts one of them", strings.Join(conflicts, ", ")))
+	}
+
+	var indices eseckv1alpha1.IndexList
+	if err := r.List(ctx, &indices); err != nil {
+		return err
+	}
+	matched := esutils.FindIndicesOfDataStreamTemplate(*indexTemplate, indexTemplates.Items, indices.Items)
+	if esutils.SetIndexTemplateDataStreamMismatchCondition(&indexTemplate.Status.Conditions, indexTemplate.Generation, matched) && len(matched) > 0 {
+		r.Recorder.Event(indexTemplate, "Warning", "DataStreamMismatch",
+			fmt.Sprintf("The template declares data_stream but applies to Index %s, which Elasticsearch refuses to create as an index", strings.Join(matched, ", ")))
+	}
+	return nil
+}
+
+// deletionImpact returns what deleting the index template affects, nil if spec.reportDeletionImpact is not set
+func (r *IndexTemplateReconciler) deletionImpact(esClient *elasticsearch.Client, indexTemplate eseckv1alpha1.IndexTemplate) (*eseckv1alpha1.IndexTemplateDeletionImpact, error) {
+	if !indexTemplate.Spec.ReportDeletionImpact {
+		return nil, nil
+	}
+	patterns, err := esutils.IndexTemplatePatterns(indexTemplate.Spec.GetBody())
+	if err != nil {
+		return nil, err
+	}
+	impact, err := esutils.GetIndexTemplateDeletionImpact(esClient, utils.RemoteName(&indexTemplate), patterns)
+	if err != nil {
+		return nil, err
+	}
+	return &impact, nil
+}
+
+// reconcileGeneratedDataView reconciles the DataView of the index patterns of the index template, if it opts in
+func (r *IndexTemplateReconciler) reconcileGeneratedDataView(ctx context.Context, indexTemplate eseckv1alpha1.IndexTemplate) error {
+	var patterns []string
+	if _, optedIn := indexTemplate.Annotations[kibanaUtils.DataViewSpaceAnnotation]; optedIn {
+		var err error
+		if patterns, err = esutils.IndexTemplatePatterns(indexTemplate.Spec.GetBody()); err != nil {
+			r.Recorder.Event(&indexTemplate, "Warning", "DataViewError",
+				fmt.Sprintf("Failed to read the index patterns for the DataView: %s", err.Error()))
+			return err
+		}
+	}
+	return reconcileGeneratedDataView(ctx, r.Client, r.Recorder, &indexTemplate, "IndexTemplate", patterns)
+}
+
+// requestsForIndexLifecyclePolicy returns the index templates referencing the IndexLifecyclePolicy
+func (r *IndexTemplateReconciler) requestsForIndexLifecyclePolicy(ctx context.Context, policy client.Object) []reconcile.Request {
+	var indexTemplates eseckv1alpha1.IndexTemplateList
+	if err := r.List(ctx, &indexTemplates, client.MatchingFields{esutils.ILMPolicyRefIndexField: client.ObjectKeyFromObject(policy).String()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list index templates referencing IndexLifecyclePolicy", "policy", policy.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(indexTemplates.Items))
+	for _, indexTemplate := range indexTemplates.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&indexTemplate)})
+	}
+	return requests
 }
 
 func (r *IndexTemplateReconciler) addFinalizer(o client.Object, finalizer string, ctx context.Context) error {
@@ -18,14 +18,19 @@ package eseck
 
 import (
 	"context"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils/template"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
-	configv2 "eck-custom-resources/api/config/v2"
 	"eck-custom-resources/utils"
 	esutils "eck-custom-resources/utils/elasticsearch"
+	"eck-custom-resources/utils/overlay"
+	"eck-custom-resources/utils/quota"
 
+	"github.com/elastic/go-elasticsearch/v8"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
@@ -43,7 +48,7 @@ import (
 type IngestPipelineReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 	RestConfig    *rest.Config
 }
@@ -61,13 +66,13 @@ func (r *IngestPipelineReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &ingestPipeline, r.ProjectConfig.Elasticsearch, ingestPipeline.Spec.TargetConfig, req.Namespace)
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &ingestPipeline, r.ProjectConfig.Load().Elasticsearch, ingestPipeline.Spec.TargetConfig, req.Namespace)
 	if err != nil {
 		return utils.GetRequeueResult(), err
 	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
 
-	if !targetInstance.Enabled {
-		logger.Info("Elasticsearch reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &ingestPipeline, &ingestPipeline.Status.Conditions, targetInstance.Enabled) {
 		return ctrl.Result{}, nil
 	}
 
@@ -82,15 +87,25 @@ func (r *IngestPipelineReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
 	}
 
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &ingestPipeline, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &ingestPipeline, &ingestPipeline.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
 	// Handle deletion
 	if !ingestPipeline.DeletionTimestamp.IsZero() {
 		if controllerutil.ContainsFinalizer(&ingestPipeline, finalizer) {
 			logger.Info("Deleting object", "ingestPipeline", ingestPipeline.Name)
-			if _, err := esutils.DeleteIngestPipeline(esClient, req.Name); err != nil {
+			if _, err := esutils.DeleteIngestPipeline(esClient, utils.RemoteName(&ingestPipeline)); err != nil {
 				return ctrl.Result{}, err
 			}
 
 			controllerutil.RemoveFinalizer(&ingestPipeline, finalizer)
+			utils.ForgetAppliedBody(&ingestPipeline)
+			esutils.ForgetIngestPipelineStats(&ingestPipeline)
 			if err := r.Update(ctx, &ingestPipeline); err != nil {
 				return ctrl.Result{}, err
 			}
@@ -98,6 +113,17 @@ func (r *IngestPipelineReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, nil
 	}
 
+	if reason, err := quota.Admit(ctx, r.Client, &ingestPipeline); err != nil {
+		return utils.GetRequeueResult(), err
+	} else if reason != "" {
+		r.Recorder.Event(&ingestPipeline, "Warning", "QuotaExceeded", fmt.Sprintf("Not creating %s: %s", ingestPipeline.GetName(), reason))
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &ingestPipeline, ingestPipeline.Spec, &ingestPipeline.Status.Conditions, &ingestPipeline.Status.ObservedGeneration, errors.New(reason)); statusErr != nil {
+			logger.Error(statusErr, "Failed to update IngestPipeline sync status")
+		}
+		// Requeued until the quota allows the resource
+		return utils.GetRequeueResult(), nil
+	}
+
 	// Handle create/update
 	logger.Info("Creating/Updating object", "ingestPipeline", ingestPipeline.Name)
 
@@ -106,8 +132,13 @@ func (r *IngestPipelineReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		r.Client,
 		ctx,
 		ingestPipeline.Spec.Template,
-		ingestPipeline.Spec.Body,
-		req.Namespace,
+		ingestPipeline.Spec.GetBody(),
+		template.Builtins{
+			Namespace:      req.Namespace,
+			Name:           req.Name,
+			TargetInstance: ingestPipeline.Spec.TargetConfig.ElasticsearchInstance,
+			Target:         esutils.TemplateTarget(esClient, ingestPipeline.Spec.Template, ingestPipeline.Spec.TargetConfig, *targetInstance, req.Namespace),
+		},
 		r.RestConfig,
 	)
 	if err != nil {
@@ -115,6 +146,19 @@ func (r *IngestPipelineReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			fmt.Sprintf("Failed to render template: %s", err.Error()))
 		return utils.GetRequeueResult(), err
 	}
+	if body, err = overlay.Apply(r.Client, ctx, &ingestPipeline, body); err != nil {
+		r.Recorder.Event(&ingestPipeline, "Warning", "OverlayError",
+			fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+		return utils.GetRequeueResult(), err
+	}
+	if err := utils.LintBody(&ingestPipeline, body); err != nil {
+		r.Recorder.Event(&ingestPipeline, "Warning", utils.PolicyViolationReason, err.Error())
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &ingestPipeline, ingestPipeline.Spec, &ingestPipeline.Status.Conditions, &ingestPipeline.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update IngestPipeline sync status")
+		}
+		// The spec or the lint rules have to be changed, retrying does not help
+		return ctrl.Result{}, nil
+	}
 
 	// Create or update the Ingest pipeline in Elasticsearch
 	logger.Info("Creating/Updating Ingest pipeline", "id", req.Name)
@@ -132,9 +176,11 @@ func (r *IngestPipelineReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	// Check if this is the initial deployment
 	isInitialDeployment := esutils.IsInitialDeployment(ingestPipeline.Status.Conditions, conditionTypes)
 
+	blueGreen := ingestPipeline.Spec.UpdatePolicy.UpdateMode == eseckv1alpha1.UpdateModeBlueGreen
+
 	// If not initial deployment and UpdateMode is not Overwrite, check if the pipeline was modified externally in Elasticsearch
-	if !isInitialDeployment && ingestPipeline.Spec.UpdatePolicy.UpdateMode != eseckv1alpha1.UpdateModeOverwrite {
-		pipeline, err := esutils.GetIngestPipeline(esClient, ingestPipeline.Name)
+	if !isInitialDeployment && ingestPipeline.Spec.UpdatePolicy.UpdateMode != eseckv1alpha1.UpdateModeOverwrite && !blueGreen {
+		pipeline, err := esutils.GetIngestPipeline(esClient, utils.RemoteName(&ingestPipeline))
 		if err != nil {
 			logger.Error(err, "Failed to get ingest pipeline from Elasticsearch")
 			// Continue with update if we can't check the timestamp
@@ -147,6 +193,8 @@ func (r *IngestPipelineReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 					fmt.Sprintf("Ingest pipeline %s was modified externally in Elasticsearch, skipping update", ingestPipeline.Name))
 
 				meta.SetStatusCondition(&ingestPipeline.Status.Conditions, *modResult.ConditionToSet)
+				utils.SetReadyCondition(&ingestPipeline.Status.Conditions, ingestPipeline.Generation,
+					fmt.Errorf("%s", modResult.ConditionToSet.Message))
 				ingestPipeline.Status.ObservedGeneration = ingestPipeline.Generation
 				if statusErr := r.Status().Update(ctx, &ingestPipeline); statusErr != nil {
 					logger.Error(statusErr, "Failed to update IngestPipeline status")
@@ -156,19 +204,76 @@ func (r *IngestPipelineReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}
 	}
 
-	result, err := esutils.UpsertIngestPipeline(esClient, ingestPipeline, body)
+	if ingestPipeline.Spec.UpdatePolicy.PreserveMeta {
+		if body, err = esutils.MergeIngestPipelineMeta(esClient, utils.RemoteName(&ingestPipeline), body); err != nil {
+			r.Recorder.Event(&ingestPipeline, "Warning", "PreserveMetaError",
+				fmt.Sprintf("Failed to merge _meta of ingest pipeline %s: %s", ingestPipeline.Name, err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+	}
+
+	// Run the pipeline tests against the rendered body before it is deployed, blue/green rollouts run them against
+	// the staged pipeline instead
+	previousTests := ingestPipeline.Status.Tests
+	ingestPipeline.Status.Tests = nil
+	if len(ingestPipeline.Spec.Tests) > 0 && !blueGreen {
+		testResults, testErr := esutils.SimulateIngestPipeline(esClient, body, ingestPipeline.Spec.Tests)
+		if testErr != nil {
+			r.Recorder.Event(&ingestPipeline, "Warning", "PipelineTestError",
+				fmt.Sprintf("Failed to simulate ingest pipeline %s: %s", ingestPipeline.Name, testErr.Error()))
+			return utils.GetRequeueResult(), testErr
+		}
+		ingestPipeline.Status.Tests = testResults
+
+		if !esutils.IngestPipelineTestsPassed(testResults) {
+			r.Recorder.Event(&ingestPipeline, "Warning", "PipelineTestsFailed",
+				fmt.Sprintf("Some tests of ingest pipeline %s failed, see status.tests", ingestPipeline.Name))
+
+			if ingestPipeline.Spec.UpdatePolicy.RequirePassingTests {
+				r.blockUpdate(ctx, &ingestPipeline)
+				return ctrl.Result{}, nil
+			}
+		}
+	}
+
+	var result ctrl.Result
+	if blueGreen {
+		rollout, rolloutErr := esutils.RolloutIngestPipeline(esClient, ingestPipeline, body)
+		if rollout.Unchanged {
+			// The tests ran when the deployed version was rolled out
+			ingestPipeline.Status.Tests = previousTests
+		} else {
+			ingestPipeline.Status.Tests = rollout.Tests
+		}
+		if rolloutErr == nil && !esutils.IngestPipelineTestsPassed(rollout.Tests) {
+			r.Recorder.Event(&ingestPipeline, "Warning", "PipelineTestsFailed",
+				fmt.Sprintf("Some tests of ingest pipeline %s failed, see status.tests", ingestPipeline.Name))
+			r.blockUpdate(ctx, &ingestPipeline)
+			return ctrl.Result{}, nil
+		}
+		if len(rollout.Indices) > 0 {
+			r.Recorder.Event(&ingestPipeline, "Normal", "TrafficSwitched",
+				fmt.Sprintf("Rolled out ingest pipeline %s to indices %s", ingestPipeline.Name, strings.Join(rollout.Indices, ", ")))
+		}
+		if err = rolloutErr; err != nil {
+			result = utils.GetRequeueResult()
+		}
+	} else {
+		result, err = esutils.UpsertIngestPipeline(esClient, ingestPipeline, body)
+	}
 
 	if err == nil {
 		r.Recorder.Event(&ingestPipeline, "Normal", "Created",
-			fmt.Sprintf("Created/Updated %s/%s %s", ingestPipeline.APIVersion, ingestPipeline.Kind, ingestPipeline.Name))
+			fmt.Sprintf("Created/Updated %s/%s %s%s", ingestPipeline.APIVersion, ingestPipeline.Kind, ingestPipeline.Name, utils.AppliedBodyChanges(ctx, &ingestPipeline, targetInstance.Url, body)))
 
 		// Get the pipeline to extract timestamps and set success conditions
-		pipeline, pipelineErr := esutils.GetIngestPipeline(esClient, ingestPipeline.Name)
+		pipeline, pipelineErr := esutils.GetIngestPipeline(esClient, utils.RemoteName(&ingestPipeline))
 		var esMeta map[string]any
 		if pipelineErr == nil && pipeline != nil {
 			esMeta = pipeline.Meta
 		}
 		esutils.SetSuccessConditions(&ingestPipeline.Status.Conditions, esMeta, isInitialDeployment, conditionTypes)
+		r.recordPipelineStats(ctx, esClient, &ingestPipeline)
 	} else {
 		r.Recorder.Event(&ingestPipeline, "Warning", "Failed to create/update",
 			fmt.Sprintf("Failed to create/update %s/%s %s: %s", ingestPipeline.APIVersion, ingestPipeline.Kind, ingestPipeline.Name, err.Error()))
@@ -177,6 +282,7 @@ func (r *IngestPipelineReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	}
 
 	// Update status with observed generation
+	utils.SetReadyCondition(&ingestPipeline.Status.Conditions, ingestPipeline.Generation, err)
 	ingestPipeline.Status.ObservedGeneration = ingestPipeline.Generation
 	if statusErr := r.Status().Update(ctx, &ingestPipeline); statusErr != nil {
 		logger.Error(statusErr, "Failed to update IngestPipeline status")
@@ -186,15 +292,63 @@ func (r *IngestPipelineReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	if err := r.addFinalizer(&ingestPipeline, finalizer, ctx); err != nil {
 		return ctrl.Result{}, err
 	}
+
+	if err == nil {
+		if annotationErr := utils.UpdateSyncAnnotations(r.Client, ctx, &ingestPipeline, ingestPipeline.Spec); annotationErr != nil {
+			logger.Error(annotationErr, "Failed to update IngestPipeline sync annotations")
+		}
+	}
 	return result, err
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *IngestPipelineReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.IngestPipeline{}, utils.PriorityHigh)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.IngestPipeline{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.IngestPipeline{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.IngestPipeline{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.IngestPipeline{}, r.Recorder)
+	esutils.RegisterFailoverCleanup(&eseckv1alpha1.IngestPipeline{}, func(esClient *elasticsearch.Client, _ string, obj client.Object) error {
+		_, err := esutils.DeleteIngestPipeline(esClient, utils.RemoteName(obj))
+		return err
+	})
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&eseckv1alpha1.IngestPipeline{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
 		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
+
+// blockUpdate keeps the deployed pipeline as tests of the new version failed
+func (r *IngestPipelineReconciler) blockUpdate(ctx context.Context, ingestPipeline *eseckv1alpha1.IngestPipeline) {
+	logger := log.FromContext(ctx)
+	logger.Info("Ingest pipeline tests failed, keeping the deployed pipeline", "ingestPipeline", ingestPipeline.Name)
+	utils.SetReadyCondition(&ingestPipeline.Status.Conditions, ingestPipeline.Generation,
+		fmt.Errorf("update blocked, tests of ingest pipeline %s failed", ingestPipeline.Name))
+	ingestPipeline.Status.ObservedGeneration = ingestPipeline.Generation
+	if statusErr := r.Status().Update(ctx, ingestPipeline); statusErr != nil {
+		logger.Error(statusErr, "Failed to update IngestPipeline status")
+	}
+}
+
+// recordPipelineStats sets status.stats and the metrics of the ingest pipeline to its ingest statistics in
+// Elasticsearch, which are read again at every reconcile. A failure to read them is logged, it does not fail the
+// reconcile.
+func (r *IngestPipelineReconciler) recordPipelineStats(ctx context.Context, esClient *elasticsearch.Client, ingestPipeline *eseckv1alpha1.IngestPipeline) {
+	stats, err := esutils.GetIngestPipelineStats(esClient, utils.RemoteName(ingestPipeline))
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to get the ingest statistics of the pipeline")
+		return
+	}
+	if stats == nil {
+		// No node processed documents with the pipeline since it was started
+		stats = &eseckv1alpha1.IngestPipelineStats{}
+	}
+	ingestPipeline.Status.Stats = stats
+	esutils.RecordIngestPipelineStats(ingestPipeline, *stats)
 }
 
 func (r *IngestPipelineReconciler) addFinalizer(o client.Object, finalizer string, ctx context.Context) error {
@@ -0,0 +1,126 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eseck
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"eck-custom-resources/utils"
+)
+
+// NamespaceCleanupReconciler deletes the resources of terminating namespaces stage by stage in the order of
+// utils.NamespaceCleanupStages, instead of all at once by the namespace controller of Kubernetes
+type NamespaceCleanupReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	mu     sync.Mutex
+	stages map[string]int
+}
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// Reconcile deletes the resources of the first cleanup stage with resources left in the terminating namespace and
+// requeues the namespace until all stages are cleaned up. The controllers of the resources hold back the finalization
+// of later stages until then.
+func (r *NamespaceCleanupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var namespace corev1.Namespace
+	if err := r.Get(ctx, req.NamespacedName, &namespace); err != nil {
+		r.forget(req.Name)
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if namespace.DeletionTimestamp.IsZero() {
+		r.forget(req.Name)
+		return ctrl.Result{}, nil
+	}
+
+	stage, resources, err := utils.NextNamespaceCleanupStage(ctx, r.Client, namespace.Name)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	if len(resources) == 0 {
+		if r.startStage(namespace.Name, stage) {
+			logger.Info("Namespace cleaned up", "namespace", namespace.Name)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	names := make([]string, 0, len(resources))
+	for i := range resources {
+		resource := &resources[i]
+		names = append(names, fmt.Sprintf("%s %s", resource.GetKind(), resource.GetName()))
+		if !resource.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		if err := r.Delete(ctx, resource); client.IgnoreNotFound(err) != nil {
+			return utils.GetRequeueResult(), err
+		}
+	}
+	sort.Strings(names)
+	if r.startStage(namespace.Name, stage) {
+		logger.Info("Deleting resources of cleanup stage", "namespace", namespace.Name, "stage", stage, "resources", names)
+		r.Recorder.Event(&namespace, "Normal", "NamespaceCleanup",
+			fmt.Sprintf("Deleting the resources of cleanup stage %d of %d: %s", stage+1, len(utils.NamespaceCleanupStages), strings.Join(names, ", ")))
+	}
+	return ctrl.Result{RequeueAfter: utils.NamespaceCleanupInterval}, nil
+}
+
+// startStage records the stage the cleanup of the namespace is in and reports whether it changed
+func (r *NamespaceCleanupReconciler) startStage(namespace string, stage int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stages == nil {
+		r.stages = make(map[string]int)
+	}
+	if previous, ok := r.stages[namespace]; ok && previous == stage {
+		return false
+	}
+	r.stages[namespace] = stage
+	return true
+}
+
+func (r *NamespaceCleanupReconciler) forget(namespace string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.stages, namespace)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NamespaceCleanupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("namespacecleanup").
+		For(&corev1.Namespace{}).
+		WithEventFilter(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return !obj.GetDeletionTimestamp().IsZero()
+		})).
+		Complete(r)
+}
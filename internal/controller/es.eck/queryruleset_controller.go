@@ -0,0 +1,181 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eseck
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
+	"eck-custom-resources/utils/overlay"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+)
+
+// QueryRulesetReconciler reconciles a QueryRuleset object
+type QueryRulesetReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=queryrulesets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=queryrulesets/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=queryrulesets/finalizers,verbs=update
+
+func (r *QueryRulesetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	finalizer := "queryruleset.es.eck.github.com/finalizer"
+
+	var queryRuleset eseckv1alpha1.QueryRuleset
+	if err := r.Get(ctx, req.NamespacedName, &queryRuleset); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &queryRuleset, r.ProjectConfig.Load().Elasticsearch, queryRuleset.Spec.TargetConfig, req.Namespace)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &queryRuleset, &queryRuleset.Status.Conditions, targetInstance.Enabled) {
+		return ctrl.Result{}, nil
+	}
+
+	targetInstanceNamespace := req.Namespace
+	if queryRuleset.Spec.TargetConfig.ElasticsearchInstanceNamespace != "" {
+		targetInstanceNamespace = queryRuleset.Spec.TargetConfig.ElasticsearchInstanceNamespace
+	}
+
+	esClient, createClientErr := esutils.GetElasticsearchClient(r.Client, ctx, *targetInstance, req, targetInstanceNamespace)
+	if createClientErr != nil {
+		logger.Error(createClientErr, "Failed to create Elasticsearch client")
+		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
+	}
+
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &queryRuleset, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &queryRuleset, &queryRuleset.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
+	if queryRuleset.DeletionTimestamp.IsZero() {
+		logger.Info("Creating/Updating query ruleset", "queryRuleset", req.Name)
+		body, err := overlay.Apply(r.Client, ctx, &queryRuleset, queryRuleset.Spec.GetBody())
+		if err != nil {
+			r.Recorder.Event(&queryRuleset, "Warning", "OverlayError",
+				fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+		if err := utils.LintBody(&queryRuleset, body); err != nil {
+			r.Recorder.Event(&queryRuleset, "Warning", utils.PolicyViolationReason, err.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &queryRuleset, queryRuleset.Spec, &queryRuleset.Status.Conditions, &queryRuleset.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update QueryRuleset sync status")
+			}
+			// The spec or the lint rules have to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+		if errs := esutils.ValidateQueryRulesetBody(body, field.NewPath("spec").Child("body")); len(errs) > 0 {
+			err := errs.ToAggregate()
+			r.Recorder.Event(&queryRuleset, "Warning", "InvalidBody",
+				fmt.Sprintf("Invalid body of %s: %s", queryRuleset.Name, err.Error()))
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &queryRuleset, queryRuleset.Spec, &queryRuleset.Status.Conditions, &queryRuleset.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update QueryRuleset sync status")
+			}
+			// The spec has to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+		patched := queryRuleset.DeepCopy()
+		patched.Spec.Body = body
+		res, err := esutils.UpsertQueryRuleset(esClient, *patched)
+		if err == nil {
+			r.Recorder.Event(&queryRuleset, "Normal", "Created",
+				fmt.Sprintf("Created/Updated %s/%s %s%s", queryRuleset.APIVersion, queryRuleset.Kind, queryRuleset.Name, utils.AppliedBodyChanges(ctx, &queryRuleset, targetInstance.Url, body)))
+		} else {
+			r.Recorder.Event(&queryRuleset, "Warning", "Failed to create/update",
+				fmt.Sprintf("Failed to create/update %s/%s %s: %s", queryRuleset.APIVersion, queryRuleset.Kind, queryRuleset.Name, err.Error()))
+		}
+
+		if err := r.addFinalizer(&queryRuleset, finalizer, ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &queryRuleset, queryRuleset.Spec, &queryRuleset.Status.Conditions, &queryRuleset.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update QueryRuleset sync status")
+		}
+		return res, err
+	} else {
+		if controllerutil.ContainsFinalizer(&queryRuleset, finalizer) {
+			logger.Info("Deleting object", "queryRuleset", queryRuleset.Name)
+			if _, err := esutils.DeleteQueryRuleset(esClient, utils.RemoteName(&queryRuleset)); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			controllerutil.RemoveFinalizer(&queryRuleset, finalizer)
+			utils.ForgetAppliedBody(&queryRuleset)
+			if err := r.Update(ctx, &queryRuleset); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *QueryRulesetReconciler) addFinalizer(o client.Object, finalizer string, ctx context.Context) error {
+	if !controllerutil.ContainsFinalizer(o, finalizer) {
+		controllerutil.AddFinalizer(o, finalizer)
+		if err := r.Update(ctx, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *QueryRulesetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.QueryRuleset{}, utils.PriorityNormal)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.QueryRuleset{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.QueryRuleset{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.QueryRuleset{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.QueryRuleset{}, r.Recorder)
+	esutils.RegisterFailoverCleanup(&eseckv1alpha1.QueryRuleset{}, func(esClient *elasticsearch.Client, _ string, obj client.Object) error {
+		_, err := esutils.DeleteQueryRuleset(esClient, utils.RemoteName(obj))
+		return err
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&eseckv1alpha1.QueryRuleset{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
@@ -28,7 +28,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
 	esutils "eck-custom-resources/utils/elasticsearch"
 
@@ -39,7 +39,7 @@ import (
 type ResourceTemplateDataReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 }
 
@@ -49,7 +49,8 @@ type ResourceTemplateDataReconciler struct {
 
 // The reconciler must trigger all resources referencing
 func (r *ResourceTemplateDataReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := log.FromContext(ctx)
+	// Not wrapped by the reconcile metrics, the attempt is unknown
+	ctx, logger := utils.ReconcileLogger(ctx, "ResourceTemplateData", 0)
 
 	finalizer := "resourcetemplatedatas.es.eck.github.com/finalizer"
 
@@ -58,13 +59,13 @@ func (r *ResourceTemplateDataReconciler) Reconcile(ctx context.Context, req ctrl
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &resourceTemplateData, r.ProjectConfig.Elasticsearch, resourceTemplateData.Spec.TargetConfig, req.Namespace)
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &resourceTemplateData, r.ProjectConfig.Load().Elasticsearch, resourceTemplateData.Spec.TargetConfig, req.Namespace)
 	if err != nil {
 		return utils.GetRequeueResult(), err
 	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
 
-	if !targetInstance.Enabled {
-		logger.Info("Elasticsearch reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &resourceTemplateData, &resourceTemplateData.Status.Conditions, targetInstance.Enabled) {
 		return ctrl.Result{}, nil
 	}
 
@@ -115,10 +116,11 @@ func (r *ResourceTemplateDataReconciler) addFinalizer(o client.Object, finalizer
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ResourceTemplateDataReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.ResourceTemplateData{}, r.Recorder)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&eseckv1alpha1.ResourceTemplateData{}).
 		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+		Complete(ownership.Reconciler(mgr.GetClient(), r))
 }
 
 // Search for all custom resources which
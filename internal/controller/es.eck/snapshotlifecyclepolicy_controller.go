@@ -20,13 +20,16 @@ import (
 	"context"
 	"fmt"
 
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
 	esutils "eck-custom-resources/utils/elasticsearch"
+	"eck-custom-resources/utils/overlay"
 
 	"k8s.io/client-go/tools/record"
 
+	"github.com/elastic/go-elasticsearch/v8"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -39,7 +42,7 @@ import (
 type SnapshotLifecyclePolicyReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 }
 
@@ -57,13 +60,13 @@ func (r *SnapshotLifecyclePolicyReconciler) Reconcile(ctx context.Context, req c
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &snapshotLifecyclePolicy, r.ProjectConfig.Elasticsearch, snapshotLifecyclePolicy.Spec.TargetConfig, req.Namespace)
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &snapshotLifecyclePolicy, r.ProjectConfig.Load().Elasticsearch, snapshotLifecyclePolicy.Spec.TargetConfig, req.Namespace)
 	if err != nil {
 		return utils.GetRequeueResult(), err
 	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
 
-	if !targetInstance.Enabled {
-		logger.Info("Elasticsearch reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &snapshotLifecyclePolicy, &snapshotLifecyclePolicy.Status.Conditions, targetInstance.Enabled) {
 		return ctrl.Result{}, nil
 	}
 
@@ -78,12 +81,46 @@ func (r *SnapshotLifecyclePolicyReconciler) Reconcile(ctx context.Context, req c
 		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
 	}
 
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &snapshotLifecyclePolicy, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &snapshotLifecyclePolicy, &snapshotLifecyclePolicy.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
 	if snapshotLifecyclePolicy.DeletionTimestamp.IsZero() {
-		res, err := esutils.UpsertSnapshotLifecyclePolicy(esClient, snapshotLifecyclePolicy)
+		body, err := overlay.Apply(r.Client, ctx, &snapshotLifecyclePolicy, snapshotLifecyclePolicy.Spec.GetBody())
+		if err != nil {
+			r.Recorder.Event(&snapshotLifecyclePolicy, "Warning", "OverlayError",
+				fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+		if err := utils.LintBody(&snapshotLifecyclePolicy, body); err != nil {
+			r.Recorder.Event(&snapshotLifecyclePolicy, "Warning", utils.PolicyViolationReason, err.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &snapshotLifecyclePolicy, snapshotLifecyclePolicy.Spec, &snapshotLifecyclePolicy.Status.Conditions, &snapshotLifecyclePolicy.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update SnapshotLifecyclePolicy sync status")
+			}
+			// The spec or the lint rules have to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+		if errs := esutils.ValidateSnapshotLifecyclePolicyBody(body, field.NewPath("spec").Child("body")); len(errs) > 0 {
+			err := errs.ToAggregate()
+			r.Recorder.Event(&snapshotLifecyclePolicy, "Warning", "InvalidBody",
+				fmt.Sprintf("Invalid body of %s: %s", snapshotLifecyclePolicy.Name, err.Error()))
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &snapshotLifecyclePolicy, snapshotLifecyclePolicy.Spec, &snapshotLifecyclePolicy.Status.Conditions, &snapshotLifecyclePolicy.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update SnapshotLifecyclePolicy sync status")
+			}
+			// The spec has to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+		patched := snapshotLifecyclePolicy.DeepCopy()
+		patched.Spec.Body = body
+		res, err := esutils.UpsertSnapshotLifecyclePolicy(esClient, *patched)
 
 		if err == nil {
 			r.Recorder.Event(&snapshotLifecyclePolicy, "Normal", "Created",
-				fmt.Sprintf("Created/Updated %s/%s %s", snapshotLifecyclePolicy.APIVersion, snapshotLifecyclePolicy.Kind, snapshotLifecyclePolicy.Name))
+				fmt.Sprintf("Created/Updated %s/%s %s%s", snapshotLifecyclePolicy.APIVersion, snapshotLifecyclePolicy.Kind, snapshotLifecyclePolicy.Name, utils.AppliedBodyChanges(ctx, &snapshotLifecyclePolicy, targetInstance.Url, body)))
 		} else {
 			r.Recorder.Event(&snapshotLifecyclePolicy, "Warning", "Failed to create/update",
 				fmt.Sprintf("Failed to create/update %s/%s %s: %s", snapshotLifecyclePolicy.APIVersion, snapshotLifecyclePolicy.Kind, snapshotLifecyclePolicy.Name, err.Error()))
@@ -92,6 +129,10 @@ func (r *SnapshotLifecyclePolicyReconciler) Reconcile(ctx context.Context, req c
 		if err := r.addFinalizer(&snapshotLifecyclePolicy, finalizer, ctx); err != nil {
 			return ctrl.Result{}, err
 		}
+
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &snapshotLifecyclePolicy, snapshotLifecyclePolicy.Spec, &snapshotLifecyclePolicy.Status.Conditions, &snapshotLifecyclePolicy.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update SnapshotLifecyclePolicy sync status")
+		}
 		return res, err
 	} else {
 		// The object is being deleted
@@ -102,6 +143,7 @@ func (r *SnapshotLifecyclePolicyReconciler) Reconcile(ctx context.Context, req c
 			}
 
 			controllerutil.RemoveFinalizer(&snapshotLifecyclePolicy, finalizer)
+			utils.ForgetAppliedBody(&snapshotLifecyclePolicy)
 			if err := r.Update(ctx, &snapshotLifecyclePolicy); err != nil {
 				return ctrl.Result{}, err
 			}
@@ -113,10 +155,23 @@ func (r *SnapshotLifecyclePolicyReconciler) Reconcile(ctx context.Context, req c
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *SnapshotLifecyclePolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.SnapshotLifecyclePolicy{}, utils.PriorityHigh)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.SnapshotLifecyclePolicy{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.SnapshotLifecyclePolicy{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.SnapshotLifecyclePolicy{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.SnapshotLifecyclePolicy{}, r.Recorder)
+	esutils.RegisterFailoverCleanup(&eseckv1alpha1.SnapshotLifecyclePolicy{}, func(esClient *elasticsearch.Client, _ string, obj client.Object) error {
+		_, err := esutils.DeleteSnapshotLifecyclePolicy(esClient, obj.GetName())
+		return err
+	})
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&eseckv1alpha1.SnapshotLifecyclePolicy{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
 		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
 }
 
 func (r *SnapshotLifecyclePolicyReconciler) addFinalizer(o client.Object, finalizer string, ctx context.Context) error {
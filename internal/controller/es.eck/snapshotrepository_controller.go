@@ -18,14 +18,20 @@ package eseck
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
 	esutils "eck-custom-resources/utils/elasticsearch"
+	"eck-custom-resources/utils/overlay"
 
+	"github.com/elastic/go-elasticsearch/v8"
 	"k8s.io/client-go/tools/record"
 
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -39,7 +45,7 @@ import (
 type SnapshotRepositoryReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 }
 
@@ -57,13 +63,13 @@ func (r *SnapshotRepositoryReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &snapshotRepository, r.ProjectConfig.Elasticsearch, snapshotRepository.Spec.TargetConfig, req.Namespace)
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &snapshotRepository, r.ProjectConfig.Load().Elasticsearch, snapshotRepository.Spec.TargetConfig, req.Namespace)
 	if err != nil {
 		return utils.GetRequeueResult(), err
 	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
 
-	if !targetInstance.Enabled {
-		logger.Info("Elasticsearch reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &snapshotRepository, &snapshotRepository.Status.Conditions, targetInstance.Enabled) {
 		return ctrl.Result{}, nil
 	}
 
@@ -78,31 +84,72 @@ func (r *SnapshotRepositoryReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
 	}
 
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &snapshotRepository, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &snapshotRepository, &snapshotRepository.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
 	if snapshotRepository.DeletionTimestamp.IsZero() {
 		logger.Info("Creating/Updating Snapshot repository", "snapshot repository", req.Name)
-		res, err := esutils.UpsertSnapshotRepository(esClient, snapshotRepository)
+		body, err := overlay.Apply(r.Client, ctx, &snapshotRepository, snapshotRepository.Spec.GetBody())
+		if err != nil {
+			r.Recorder.Event(&snapshotRepository, "Warning", "OverlayError",
+				fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+		if err := utils.LintBody(&snapshotRepository, body); err != nil {
+			r.Recorder.Event(&snapshotRepository, "Warning", utils.PolicyViolationReason, err.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &snapshotRepository, snapshotRepository.Spec, &snapshotRepository.Status.Conditions, &snapshotRepository.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update SnapshotRepository sync status")
+			}
+			// The spec or the lint rules have to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+		patched := snapshotRepository.DeepCopy()
+		patched.Spec.Body = body
+		res, err := esutils.UpsertSnapshotRepository(esClient, *patched)
 
 		if err == nil {
 			r.Recorder.Event(&snapshotRepository, "Normal", "Created",
-				fmt.Sprintf("Created/Updated %s/%s %s", snapshotRepository.APIVersion, snapshotRepository.Kind, snapshotRepository.Name))
+				fmt.Sprintf("Created/Updated %s/%s %s%s", snapshotRepository.APIVersion, snapshotRepository.Kind, snapshotRepository.Name, utils.AppliedBodyChanges(ctx, &snapshotRepository, targetInstance.Url, body)))
 		} else {
 			r.Recorder.Event(&snapshotRepository, "Warning", "Failed to create/update",
 				fmt.Sprintf("Failed to create/update %s/%s %s: %s", snapshotRepository.APIVersion, snapshotRepository.Kind, snapshotRepository.Name, err.Error()))
 		}
 
+		// The finalizer is added first, its update replaces the status with the one of the server
 		if err := r.addFinalizer(&snapshotRepository, finalizer, ctx); err != nil {
 			return ctrl.Result{}, err
 		}
+
+		// Replicas are only registered once the writable repository exists on the target instance
+		if err == nil {
+			if err = r.reconcileReadonlyReplicas(ctx, req, &snapshotRepository); err != nil {
+				res = utils.GetRequeueResult()
+			}
+		}
+
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &snapshotRepository, snapshotRepository.Spec, &snapshotRepository.Status.Conditions, &snapshotRepository.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update SnapshotRepository sync status")
+		}
 		return res, err
 	} else {
 		// The object is being deleted
 		if controllerutil.ContainsFinalizer(&snapshotRepository, finalizer) {
 			logger.Info("Deleting object", "snapshotRepository", snapshotRepository.Name)
+			replicas := mergeReplicas(snapshotRepository.Spec.ReadonlyReplicas, snapshotRepository.Status.ReadonlyReplicas)
+			if remaining := r.deleteReadonlyReplicas(ctx, req, &snapshotRepository, replicas); len(remaining) > 0 {
+				return utils.GetRequeueResult(), fmt.Errorf("failed to delete snapshot repository from %d readonly replica(s)", len(remaining))
+			}
 			if _, err := esutils.DeleteSnapshotRepository(esClient, req.Name); err != nil {
 				return ctrl.Result{}, err
 			}
 
 			controllerutil.RemoveFinalizer(&snapshotRepository, finalizer)
+			utils.ForgetAppliedBody(&snapshotRepository)
 			if err := r.Update(ctx, &snapshotRepository); err != nil {
 				return ctrl.Result{}, err
 			}
@@ -114,10 +161,23 @@ func (r *SnapshotRepositoryReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *SnapshotRepositoryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.SnapshotRepository{}, utils.PriorityHigh)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.SnapshotRepository{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.SnapshotRepository{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.SnapshotRepository{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.SnapshotRepository{}, r.Recorder)
+	esutils.RegisterFailoverCleanup(&eseckv1alpha1.SnapshotRepository{}, func(esClient *elasticsearch.Client, _ string, obj client.Object) error {
+		_, err := esutils.DeleteSnapshotRepository(esClient, obj.GetName())
+		return err
+	})
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&eseckv1alpha1.SnapshotRepository{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
 		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
 }
 
 func (r *SnapshotRepositoryReconciler) addFinalizer(o client.Object, finalizer string, ctx context.Context) error {
@@ -129,3 +189,129 @@ func (r *SnapshotRepositoryReconciler) addFinalizer(o client.Object, finalizer s
 	}
 	return nil
 }
+
+// reconcileReadonlyReplicas registers the repository as readonly on every replica listed in the spec and
+// removes it from replicas that were dropped from the spec since the last reconcile.
+func (r *SnapshotRepositoryReconciler) reconcileReadonlyReplicas(ctx context.Context, req ctrl.Request, snapshotRepository *eseckv1alpha1.SnapshotRepository) error {
+	var errs []error
+	var registered []eseckv1alpha1.CommonElasticsearchConfig
+
+	for _, replica := range snapshotRepository.Spec.ReadonlyReplicas {
+		esClient, err := r.getReplicaClient(ctx, req, snapshotRepository, replica)
+		if err == nil && esClient != nil {
+			_, err = esutils.UpsertReadonlySnapshotRepository(esClient, *snapshotRepository)
+		}
+		if err != nil {
+			r.Recorder.Event(snapshotRepository, "Warning", "Failed to register readonly replica",
+				fmt.Sprintf("Failed to register %s as readonly on %s: %s", snapshotRepository.Name, replicaName(replica), err.Error()))
+			errs = append(errs, err)
+			if apierrors.IsNotFound(err) {
+				r.recordReplicaLeft(snapshotRepository, replica, "was removed")
+			}
+			continue
+		}
+		if esClient == nil {
+			r.recordReplicaLeft(snapshotRepository, replica, "is disabled")
+			continue
+		}
+		registered = append(registered, replica)
+	}
+
+	var stale []eseckv1alpha1.CommonElasticsearchConfig
+	for _, replica := range snapshotRepository.Status.ReadonlyReplicas {
+		if !containsReplica(snapshotRepository.Spec.ReadonlyReplicas, replica) {
+			stale = append(stale, replica)
+		}
+	}
+	remaining := r.deleteReadonlyReplicas(ctx, req, snapshotRepository, stale)
+	if len(remaining) > 0 {
+		errs = append(errs, fmt.Errorf("failed to delete snapshot repository from %d readonly replica(s)", len(remaining)))
+	}
+
+	snapshotRepository.Status.ReadonlyReplicas = append(registered, remaining...)
+	return errors.Join(errs...)
+}
+
+// deleteReadonlyReplicas deletes the repository from the given replicas and returns those it could not be deleted from.
+// Replicas whose instance is disabled or was removed can not be reached, they are not retried.
+func (r *SnapshotRepositoryReconciler) deleteReadonlyReplicas(ctx context.Context, req ctrl.Request, snapshotRepository *eseckv1alpha1.SnapshotRepository, replicas []eseckv1alpha1.CommonElasticsearchConfig) []eseckv1alpha1.CommonElasticsearchConfig {
+	var remaining []eseckv1alpha1.CommonElasticsearchConfig
+	for _, replica := range replicas {
+		esClient, err := r.getReplicaClient(ctx, req, snapshotRepository, replica)
+		switch {
+		case apierrors.IsNotFound(err):
+			r.recordReplicaLeft(snapshotRepository, replica, "was removed")
+			continue
+		case err == nil && esClient == nil:
+			r.recordReplicaLeft(snapshotRepository, replica, "is disabled")
+			continue
+		case err == nil:
+			_, err = esutils.DeleteSnapshotRepository(esClient, snapshotRepository.Name)
+		}
+		if err != nil {
+			r.Recorder.Event(snapshotRepository, "Warning", "Failed to delete readonly replica",
+				fmt.Sprintf("Failed to delete %s from %s: %s", snapshotRepository.Name, replicaName(replica), err.Error()))
+			remaining = append(remaining, replica)
+		}
+	}
+	return remaining
+}
+
+// recordReplicaLeft records an event if the repository was registered on the replica whose instance can not be reached
+// anymore, the replica is dropped from the status and the repository is left registered on the instance
+func (r *SnapshotRepositoryReconciler) recordReplicaLeft(snapshotRepository *eseckv1alpha1.SnapshotRepository, replica eseckv1alpha1.CommonElasticsearchConfig, reason string) {
+	if !containsReplica(snapshotRepository.Status.ReadonlyReplicas, replica) {
+		return
+	}
+	r.Recorder.Event(snapshotRepository, "Warning", "ReadonlyReplicaLeft",
+		fmt.Sprintf("The instance of readonly replica %s %s, %s is left registered on it", replicaName(replica), reason, snapshotRepository.Name))
+}
+
+// getReplicaClient returns the Elasticsearch client of a replica, or nil if the replica instance is disabled.
+func (r *SnapshotRepositoryReconciler) getReplicaClient(ctx context.Context, req ctrl.Request, snapshotRepository *eseckv1alpha1.SnapshotRepository, replica eseckv1alpha1.CommonElasticsearchConfig) (*elasticsearch.Client, error) {
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, snapshotRepository, r.ProjectConfig.Load().Elasticsearch, replica, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !targetInstance.Enabled {
+		return nil, nil
+	}
+
+	targetInstanceNamespace := req.Namespace
+	if replica.ElasticsearchInstanceNamespace != "" {
+		targetInstanceNamespace = replica.ElasticsearchInstanceNamespace
+	}
+	return esutils.GetElasticsearchClient(r.Client, ctx, *targetInstance, req, targetInstanceNamespace)
+}
+
+func mergeReplicas(replicas ...[]eseckv1alpha1.CommonElasticsearchConfig) []eseckv1alpha1.CommonElasticsearchConfig {
+	var merged []eseckv1alpha1.CommonElasticsearchConfig
+	for _, list := range replicas {
+		for _, replica := range list {
+			if !containsReplica(merged, replica) {
+				merged = append(merged, replica)
+			}
+		}
+	}
+	return merged
+}
+
+func containsReplica(replicas []eseckv1alpha1.CommonElasticsearchConfig, replica eseckv1alpha1.CommonElasticsearchConfig) bool {
+	for _, r := range replicas {
+		if equality.Semantic.DeepEqual(r, replica) {
+			return true
+		}
+	}
+	return false
+}
+
+func replicaName(replica eseckv1alpha1.CommonElasticsearchConfig) string {
+	name := replica.ElasticsearchInstance
+	if name == "" && replica.Selector != nil {
+		name = metav1.FormatLabelSelector(replica.Selector)
+	}
+	if replica.ElasticsearchInstanceNamespace == "" {
+		return name
+	}
+	return replica.ElasticsearchInstanceNamespace + "/" + name
+}
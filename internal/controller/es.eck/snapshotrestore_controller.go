@@ -0,0 +1,168 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eseck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+)
+
+// restorePollInterval is how often the recovery of a running restore is checked
+const restorePollInterval = 10 * time.Second
+
+// SnapshotRestoreReconciler reconciles a SnapshotRestore object
+type SnapshotRestoreReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=snapshotrestores,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=snapshotrestores/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=snapshotrestores/finalizers,verbs=update
+
+func (r *SnapshotRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var snapshotRestore eseckv1alpha1.SnapshotRestore
+	if err := r.Get(ctx, req.NamespacedName, &snapshotRestore); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	// Restored indices are kept, there is nothing to clean up
+	if !snapshotRestore.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &snapshotRestore, r.ProjectConfig.Load().Elasticsearch, snapshotRestore.Spec.TargetConfig, req.Namespace)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
+
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &snapshotRestore, &snapshotRestore.Status.Conditions, targetInstance.Enabled) {
+		return ctrl.Result{}, nil
+	}
+
+	targetInstanceNamespace := req.Namespace
+	if snapshotRestore.Spec.TargetConfig.ElasticsearchInstanceNamespace != "" {
+		targetInstanceNamespace = snapshotRestore.Spec.TargetConfig.ElasticsearchInstanceNamespace
+	}
+
+	esClient, createClientErr := esutils.GetElasticsearchClient(r.Client, ctx, *targetInstance, req, targetInstanceNamespace)
+	if createClientErr != nil {
+		logger.Error(createClientErr, "Failed to create Elasticsearch client")
+		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
+	}
+
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &snapshotRestore, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &snapshotRestore, &snapshotRestore.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
+	var restoreErr error
+	spec := snapshotRestore.Spec
+	status := &snapshotRestore.Status
+	switch {
+	case esutils.RestoreRequested(snapshotRestore):
+		// Taken before the request, recoveries started since belong to this restore
+		startTime := metav1.Now()
+		logger.Info("Restoring snapshot", "repository", spec.Repository, "snapshot", spec.Snapshot, "restoreGeneration", spec.RestoreGeneration)
+		rejected, err := esutils.RestoreSnapshot(esClient, snapshotRestore)
+		if err != nil && !rejected {
+			return utils.GetRequeueResult(), err
+		}
+		*status = eseckv1alpha1.SnapshotRestoreStatus{
+			RestoreGeneration:  spec.RestoreGeneration,
+			Phase:              eseckv1alpha1.SnapshotRestorePhaseRunning,
+			StartTime:          &startTime,
+			ObservedGeneration: status.ObservedGeneration,
+			Conditions:         status.Conditions,
+		}
+		if err != nil {
+			status.Phase = eseckv1alpha1.SnapshotRestorePhaseFailed
+			status.Message = err.Error()
+			restoreErr = err
+			r.Recorder.Event(&snapshotRestore, "Warning", "RestoreFailed",
+				fmt.Sprintf("Failed to restore snapshot %s of repository %s: %s", spec.Snapshot, spec.Repository, err.Error()))
+		} else {
+			r.Recorder.Event(&snapshotRestore, "Normal", "RestoreStarted",
+				fmt.Sprintf("Started restore of snapshot %s of repository %s", spec.Snapshot, spec.Repository))
+		}
+	case status.Phase == eseckv1alpha1.SnapshotRestorePhaseRunning:
+		progress, err := esutils.GetSnapshotRestoreProgress(esClient, snapshotRestore, status.StartTime.Time)
+		if err != nil {
+			return utils.GetRequeueResult(), err
+		}
+		status.Indices = progress.Indices
+		status.TotalShards = progress.TotalShards
+		status.RecoveredShards = progress.RecoveredShards
+		if progress.Done() {
+			status.Phase = eseckv1alpha1.SnapshotRestorePhaseCompleted
+			status.CompletionTime = &metav1.Time{Time: time.Now()}
+			r.Recorder.Event(&snapshotRestore, "Normal", "RestoreCompleted",
+				fmt.Sprintf("Restored %d shards of %d indices from snapshot %s", progress.TotalShards, len(progress.Indices), spec.Snapshot))
+		}
+	case status.Phase == eseckv1alpha1.SnapshotRestorePhaseFailed:
+		// A failed restore is only retried when a new restore generation is requested
+		restoreErr = errors.New(status.Message)
+	}
+
+	if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &snapshotRestore, snapshotRestore.Spec, &snapshotRestore.Status.Conditions, &snapshotRestore.Status.ObservedGeneration, restoreErr); statusErr != nil {
+		logger.Error(statusErr, "Failed to update SnapshotRestore sync status")
+	}
+
+	if status.Phase == eseckv1alpha1.SnapshotRestorePhaseRunning {
+		return utils.RequeueScheduled(ctx, restorePollInterval), nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SnapshotRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.SnapshotRestore{}, utils.PriorityNormal)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.SnapshotRestore{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.SnapshotRestore{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.SnapshotRestore{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.SnapshotRestore{}, r.Recorder)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&eseckv1alpha1.SnapshotRestore{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
@@ -33,7 +33,10 @@ import (
 
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	configv2 "eck-custom-resources/api/config/v2"
 	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/testutils"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -43,6 +46,7 @@ import (
 var cfg *rest.Config
 var k8sClient client.Client
 var testEnv *envtest.Environment
+var fakeES *testutils.FakeElasticsearch
 
 func TestAPIs(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -77,16 +81,31 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).ToNot(HaveOccurred())
 
+	fakeES = testutils.NewFakeElasticsearch()
+	projectConfig := config.NewStore(configv2.ProjectConfigSpec{Elasticsearch: configv2.ElasticsearchSpec{Enabled: true, Url: fakeES.URL()}})
+
+	err = (&IndexReconciler{
+		Client:        k8sManager.GetClient(),
+		Scheme:        k8sManager.GetScheme(),
+		ProjectConfig: projectConfig,
+		Recorder:      k8sManager.GetEventRecorderFor("index"),
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
 	err = (&IndexTemplateReconciler{
-		Client: k8sManager.GetClient(),
-		Scheme: k8sManager.GetScheme(),
+		Client:        k8sManager.GetClient(),
+		Scheme:        k8sManager.GetScheme(),
+		ProjectConfig: projectConfig,
+		Recorder:      k8sManager.GetEventRecorderFor("index-template"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&IngestPipelineReconciler{
-		Client:     k8sManager.GetClient(),
-		Scheme:     k8sManager.GetScheme(),
-		RestConfig: cfg,
+		Client:        k8sManager.GetClient(),
+		Scheme:        k8sManager.GetScheme(),
+		ProjectConfig: projectConfig,
+		Recorder:      k8sManager.GetEventRecorderFor("ingest-pipeline"),
+		RestConfig:    cfg,
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -102,6 +121,9 @@ var _ = BeforeSuite(func() {
 var _ = AfterSuite(func() {
 	By("tearing down the test environment")
 	gexec.KillAndWait(5 * time.Second)
+	if fakeES != nil {
+		fakeES.Close()
+	}
 	if testEnv != nil {
 		_ = testEnv.Stop() // Ignore error as it may timeout
 	}
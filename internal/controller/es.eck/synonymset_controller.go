@@ -0,0 +1,182 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eseck
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
+	"eck-custom-resources/utils/overlay"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+)
+
+// SynonymSetReconciler reconciles a SynonymSet object
+type SynonymSetReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=synonymsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=synonymsets/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=synonymsets/finalizers,verbs=update
+
+func (r *SynonymSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	finalizer := "synonymset.es.eck.github.com/finalizer"
+
+	var synonymSet eseckv1alpha1.SynonymSet
+	if err := r.Get(ctx, req.NamespacedName, &synonymSet); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, r.Recorder, &synonymSet, r.ProjectConfig.Load().Elasticsearch, synonymSet.Spec.TargetConfig, req.Namespace)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &synonymSet, &synonymSet.Status.Conditions, targetInstance.Enabled) {
+		return ctrl.Result{}, nil
+	}
+
+	targetInstanceNamespace := req.Namespace
+	if synonymSet.Spec.TargetConfig.ElasticsearchInstanceNamespace != "" {
+		targetInstanceNamespace = synonymSet.Spec.TargetConfig.ElasticsearchInstanceNamespace
+	}
+
+	esClient, createClientErr := esutils.GetElasticsearchClient(r.Client, ctx, *targetInstance, req, targetInstanceNamespace)
+	if createClientErr != nil {
+		logger.Error(createClientErr, "Failed to create Elasticsearch client")
+		return utils.GetRequeueResult(), client.IgnoreNotFound(createClientErr)
+	}
+
+	if ready, res := esutils.CheckStartupHealth(ctx, r.Recorder, &synonymSet, esClient, *targetInstance); !ready {
+		return res, nil
+	}
+
+	if available, res := esutils.CheckTargetAvailable(ctx, r.Client, r.Recorder, &synonymSet, &synonymSet.Status.Conditions, esClient, *targetInstance); !available {
+		return res, nil
+	}
+
+	if synonymSet.DeletionTimestamp.IsZero() {
+		logger.Info("Creating/Updating synonym set", "synonymSet", req.Name)
+		body, err := overlay.Apply(r.Client, ctx, &synonymSet, synonymSet.Spec.GetBody())
+		if err != nil {
+			r.Recorder.Event(&synonymSet, "Warning", "OverlayError",
+				fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+		if err := utils.LintBody(&synonymSet, body); err != nil {
+			r.Recorder.Event(&synonymSet, "Warning", utils.PolicyViolationReason, err.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &synonymSet, synonymSet.Spec, &synonymSet.Status.Conditions, &synonymSet.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update SynonymSet sync status")
+			}
+			// The spec or the lint rules have to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+		patched := synonymSet.DeepCopy()
+		patched.Spec.Body = body
+		reload, res, err := esutils.UpsertSynonymSet(esClient, *patched)
+		if err == nil {
+			r.Recorder.Event(&synonymSet, "Normal", "Created",
+				fmt.Sprintf("Created/Updated %s/%s %s%s", synonymSet.APIVersion, synonymSet.Kind, synonymSet.Name, utils.AppliedBodyChanges(ctx, &synonymSet, targetInstance.Url, body)))
+			if len(reload.Indices) > 0 {
+				logger.Info("Reloaded search analyzers", "indices", reload.Indices)
+			}
+			if reload.FailedShards > 0 {
+				// Putting the set again reloads the analyzers again
+				err = fmt.Errorf("reloading the search analyzers using the synonym set failed on %d shards of the indices %s", reload.FailedShards, strings.Join(reload.Indices, ", "))
+				res = utils.GetRequeueResult()
+				r.Recorder.Event(&synonymSet, "Warning", "AnalyzerReloadFailed", err.Error())
+			}
+		} else {
+			r.Recorder.Event(&synonymSet, "Warning", "Failed to create/update",
+				fmt.Sprintf("Failed to create/update %s/%s %s: %s", synonymSet.APIVersion, synonymSet.Kind, synonymSet.Name, err.Error()))
+		}
+
+		if err := r.addFinalizer(&synonymSet, finalizer, ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &synonymSet, synonymSet.Spec, &synonymSet.Status.Conditions, &synonymSet.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update SynonymSet sync status")
+		}
+		return res, err
+	} else {
+		if controllerutil.ContainsFinalizer(&synonymSet, finalizer) {
+			logger.Info("Deleting object", "synonymSet", synonymSet.Name)
+			if _, err := esutils.DeleteSynonymSet(esClient, utils.RemoteName(&synonymSet)); err != nil {
+				r.Recorder.Event(&synonymSet, "Warning", "DeleteFailed",
+					fmt.Sprintf("Failed to delete %s/%s %s, it may still be used by the analyzers of an index: %s", synonymSet.APIVersion, synonymSet.Kind, synonymSet.Name, err.Error()))
+				return ctrl.Result{}, err
+			}
+
+			controllerutil.RemoveFinalizer(&synonymSet, finalizer)
+			utils.ForgetAppliedBody(&synonymSet)
+			if err := r.Update(ctx, &synonymSet); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *SynonymSetReconciler) addFinalizer(o client.Object, finalizer string, ctx context.Context) error {
+	if !controllerutil.ContainsFinalizer(o, finalizer) {
+		controllerutil.AddFinalizer(o, finalizer)
+		if err := r.Update(ctx, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SynonymSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&eseckv1alpha1.SynonymSet{}, utils.PriorityHigh)
+	metrics := utils.NewReconcileMetrics(mgr, &eseckv1alpha1.SynonymSet{})
+	syncWave := utils.NewSyncWave(mgr, &eseckv1alpha1.SynonymSet{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &eseckv1alpha1.SynonymSet{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &eseckv1alpha1.SynonymSet{}, r.Recorder)
+	esutils.RegisterFailoverCleanup(&eseckv1alpha1.SynonymSet{}, func(esClient *elasticsearch.Client, _ string, obj client.Object) error {
+		_, err := esutils.DeleteSynonymSet(esClient, utils.RemoteName(obj))
+		return err
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&eseckv1alpha1.SynonymSet{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kibanaeck
+
+import (
+	"context"
+	"fmt"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	kibanaUtils "eck-custom-resources/utils/kibana"
+	"eck-custom-resources/utils/overlay"
+
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+)
+
+// AdvancedSettingsReconciler reconciles a AdvancedSettings object
+type AdvancedSettingsReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=advancedsettings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=advancedsettings/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=advancedsettings/finalizers,verbs=update
+
+func (r *AdvancedSettingsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	advancedSettingsFinalizer := "advancedsettings.kibana.eck.github.com/finalizer"
+
+	var advancedSettings kibanaeckv1alpha1.AdvancedSettings
+	if err := r.Get(ctx, req.NamespacedName, &advancedSettings); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, &advancedSettings, r.ProjectConfig.Load().Kibana, advancedSettings.Spec.TargetConfig, req.Namespace)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
+
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &advancedSettings, &advancedSettings.Status.Conditions, targetInstance.Enabled) {
+		return ctrl.Result{}, nil
+	}
+	targetInstanceNamespace := req.Namespace
+	if advancedSettings.Spec.TargetConfig.KibanaInstanceNamespace != "" {
+		targetInstanceNamespace = advancedSettings.Spec.TargetConfig.KibanaInstanceNamespace
+	}
+
+	kibanaClient := kibanaUtils.Client{
+		Cli:             r.Client,
+		Ctx:             ctx,
+		KibanaSpec:      *targetInstance,
+		KibanaNamespace: targetInstanceNamespace,
+		Req:             req,
+	}
+
+	if available, res := kibanaUtils.CheckTargetAvailable(kibanaClient, r.Recorder, &advancedSettings, &advancedSettings.Status.Conditions); !available {
+		return res, nil
+	}
+
+	if advancedSettings.DeletionTimestamp.IsZero() {
+		logger.Info("Creating/Updating advanced settings", "name", req.Name)
+		body, err := overlay.Apply(r.Client, ctx, &advancedSettings, advancedSettings.Spec.GetBody())
+		if err != nil {
+			r.Recorder.Event(&advancedSettings, "Warning", "OverlayError",
+				fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+		if err := utils.LintBody(&advancedSettings, body); err != nil {
+			r.Recorder.Event(&advancedSettings, "Warning", utils.PolicyViolationReason, err.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &advancedSettings, advancedSettings.Spec, &advancedSettings.Status.Conditions, &advancedSettings.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update AdvancedSettings sync status")
+			}
+			// The spec or the lint rules have to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+		patched := advancedSettings.DeepCopy()
+		patched.Spec.Body = body
+		managedKeys, res, err := kibanaUtils.UpsertAdvancedSettings(kibanaClient, *patched)
+
+		if err == nil {
+			r.Recorder.Event(&advancedSettings, "Normal", "Created",
+				fmt.Sprintf("Created/Updated %s/%s %s%s", advancedSettings.APIVersion, advancedSettings.Kind, advancedSettings.Name, utils.AppliedBodyChanges(ctx, &advancedSettings, targetInstance.Url, body)))
+		} else {
+			r.Recorder.Event(&advancedSettings, "Warning", "Failed to create/update",
+				fmt.Sprintf("Failed to create/update %s/%s %s: %s", advancedSettings.APIVersion, advancedSettings.Kind, advancedSettings.Name, err.Error()))
+		}
+
+		if !controllerutil.ContainsFinalizer(&advancedSettings, advancedSettingsFinalizer) {
+			controllerutil.AddFinalizer(&advancedSettings, advancedSettingsFinalizer)
+			if err := r.Update(ctx, &advancedSettings); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		// The managed keys decide which settings are reset later on, they have to be recorded on every reconcile
+		advancedSettings.Status.ManagedKeys = managedKeys
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &advancedSettings, advancedSettings.Spec, &advancedSettings.Status.Conditions, &advancedSettings.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update AdvancedSettings sync status")
+		}
+		return res, err
+	} else {
+		// The object is being deleted
+		if controllerutil.ContainsFinalizer(&advancedSettings, advancedSettingsFinalizer) {
+			if _, err := kibanaUtils.DeleteAdvancedSettings(kibanaClient, advancedSettings); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			controllerutil.RemoveFinalizer(&advancedSettings, advancedSettingsFinalizer)
+			utils.ForgetAppliedBody(&advancedSettings)
+			if err := r.Update(ctx, &advancedSettings); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		return ctrl.Result{}, nil
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AdvancedSettingsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&kibanaeckv1alpha1.AdvancedSettings{}, utils.PriorityNormal)
+	metrics := utils.NewReconcileMetrics(mgr, &kibanaeckv1alpha1.AdvancedSettings{})
+	syncWave := utils.NewSyncWave(mgr, &kibanaeckv1alpha1.AdvancedSettings{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &kibanaeckv1alpha1.AdvancedSettings{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &kibanaeckv1alpha1.AdvancedSettings{}, r.Recorder)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kibanaeckv1alpha1.AdvancedSettings{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kibanaeck
+
+import (
+	"context"
+	"fmt"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	kibanaUtils "eck-custom-resources/utils/kibana"
+	"eck-custom-resources/utils/overlay"
+
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+)
+
+// AgentPolicyReconciler reconciles a AgentPolicy object
+type AgentPolicyReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=agentpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=agentpolicies/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=agentpolicies/finalizers,verbs=update
+
+func (r *AgentPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	agentPolicyFinalizer := "agentpolicies.kibana.eck.github.com/finalizer"
+
+	var agentPolicy kibanaeckv1alpha1.AgentPolicy
+	if err := r.Get(ctx, req.NamespacedName, &agentPolicy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, &agentPolicy, r.ProjectConfig.Load().Kibana, agentPolicy.Spec.TargetConfig, req.Namespace)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
+
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &agentPolicy, &agentPolicy.Status.Conditions, targetInstance.Enabled) {
+		return ctrl.Result{}, nil
+	}
+	targetInstanceNamespace := req.Namespace
+	if agentPolicy.Spec.TargetConfig.KibanaInstanceNamespace != "" {
+		targetInstanceNamespace = agentPolicy.Spec.TargetConfig.KibanaInstanceNamespace
+	}
+
+	kibanaClient := kibanaUtils.Client{
+		Cli:             r.Client,
+		Ctx:             ctx,
+		KibanaSpec:      *targetInstance,
+		KibanaNamespace: targetInstanceNamespace,
+		Req:             req,
+	}
+
+	if available, res := kibanaUtils.CheckTargetAvailable(kibanaClient, r.Recorder, &agentPolicy, &agentPolicy.Status.Conditions); !available {
+		return res, nil
+	}
+
+	if agentPolicy.DeletionTimestamp.IsZero() {
+		logger.Info("Creating/Updating agent policy", "name", req.Name)
+		body, err := overlay.Apply(r.Client, ctx, &agentPolicy, agentPolicy.Spec.GetBody())
+		if err != nil {
+			r.Recorder.Event(&agentPolicy, "Warning", "OverlayError",
+				fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+		if err := utils.LintBody(&agentPolicy, body); err != nil {
+			r.Recorder.Event(&agentPolicy, "Warning", utils.PolicyViolationReason, err.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &agentPolicy, agentPolicy.Spec, &agentPolicy.Status.Conditions, &agentPolicy.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update AgentPolicy sync status")
+			}
+			// The spec or the lint rules have to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+		patched := agentPolicy.DeepCopy()
+		patched.Spec.Body = body
+		res, err := kibanaUtils.UpsertAgentPolicy(kibanaClient, *patched)
+
+		if err == nil {
+			r.Recorder.Event(&agentPolicy, "Normal", "Created",
+				fmt.Sprintf("Created/Updated %s/%s %s%s", agentPolicy.APIVersion, agentPolicy.Kind, agentPolicy.Name, utils.AppliedBodyChanges(ctx, &agentPolicy, targetInstance.Url, body)))
+		} else {
+			r.Recorder.Event(&agentPolicy, "Warning", "Failed to create/update",
+				fmt.Sprintf("Failed to create/update %s/%s %s: %s", agentPolicy.APIVersion, agentPolicy.Kind, agentPolicy.Name, err.Error()))
+		}
+
+		if !controllerutil.ContainsFinalizer(&agentPolicy, agentPolicyFinalizer) {
+			controllerutil.AddFinalizer(&agentPolicy, agentPolicyFinalizer)
+			if err := r.Update(ctx, &agentPolicy); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &agentPolicy, agentPolicy.Spec, &agentPolicy.Status.Conditions, &agentPolicy.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update AgentPolicy sync status")
+		}
+		return res, err
+	} else {
+		// The object is being deleted
+		if controllerutil.ContainsFinalizer(&agentPolicy, agentPolicyFinalizer) {
+			if _, err := kibanaUtils.DeleteAgentPolicy(kibanaClient, agentPolicy); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			controllerutil.RemoveFinalizer(&agentPolicy, agentPolicyFinalizer)
+			utils.ForgetAppliedBody(&agentPolicy)
+			if err := r.Update(ctx, &agentPolicy); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		return ctrl.Result{}, nil
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AgentPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&kibanaeckv1alpha1.AgentPolicy{}, utils.PriorityNormal)
+	metrics := utils.NewReconcileMetrics(mgr, &kibanaeckv1alpha1.AgentPolicy{})
+	syncWave := utils.NewSyncWave(mgr, &kibanaeckv1alpha1.AgentPolicy{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &kibanaeckv1alpha1.AgentPolicy{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &kibanaeckv1alpha1.AgentPolicy{}, r.Recorder)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kibanaeckv1alpha1.AgentPolicy{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kibanaeck
+
+import (
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	kibanaUtils "eck-custom-resources/utils/kibana"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+)
+
+// CanvasWorkpadReconciler reconciles a CanvasWorkpad object
+type CanvasWorkpadReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=canvasworkpads,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=canvasworkpads/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=canvasworkpads/finalizers,verbs=update
+
+var canvasWorkpadKind = SavedObjectKind{
+	New:       func() kibanaeckv1alpha1.SavedObjectResource { return &kibanaeckv1alpha1.CanvasWorkpad{} },
+	Type:      kibanaUtils.CanvasWorkpadSavedObjectType,
+	Finalizer: "canvasworkpads.kibana.eck.github.com/finalizer",
+	Priority:  utils.PriorityLow,
+	Upsert: func(kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource, savedObject kibanaeckv1alpha1.SavedObject, version *string) (ctrl.Result, error) {
+		return kibanaUtils.UpsertCanvasWorkpad(kClient, objectMeta(obj), savedObject, version)
+	},
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CanvasWorkpadReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return (&SavedObjectReconciler{
+		Client:        r.Client,
+		Scheme:        r.Scheme,
+		ProjectConfig: r.ProjectConfig,
+		Recorder:      r.Recorder,
+		Kind:          canvasWorkpadKind,
+	}).SetupWithManager(mgr)
+}
@@ -18,17 +18,16 @@ package kibanaeck
 
 import (
 	"context"
-	"fmt"
 
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
 	kibanaUtils "eck-custom-resources/utils/kibana"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
@@ -38,96 +37,48 @@ import (
 type DashboardReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=kibana.eck.github.com,resources=dashboards,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kibana.eck.github.com,resources=dashboards/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kibana.eck.github.com,resources=dashboards/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+
+var dashboardKind = SavedObjectKind{
+	New:       func() kibanaeckv1alpha1.SavedObjectResource { return &kibanaeckv1alpha1.Dashboard{} },
+	Type:      "dashboard",
+	Finalizer: "dashboards.kibana.eck.github.com/finalizer",
+	Priority:  utils.PriorityLow,
+	Verify:    verifyDashboardReferences,
+}
 
-func (r *DashboardReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := log.FromContext(ctx)
-
-	dashboardFinalizer := "dashboards.kibana.eck.github.com/finalizer"
-	savedObjectType := "dashboard"
-
-	var dashboard kibanaeckv1alpha1.Dashboard
-	if err := r.Get(ctx, req.NamespacedName, &dashboard); err != nil {
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+// verifyDashboardReferences reports the references of the dashboard which do not resolve in the BrokenReferences
+// condition if spec.validateReferences is set. A failed lookup is logged and leaves the condition as it is.
+func verifyDashboardReferences(ctx context.Context, r *SavedObjectReconciler, kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource) {
+	dashboard := obj.(*kibanaeckv1alpha1.Dashboard)
+	if !dashboard.Spec.ValidateReferences {
+		meta.RemoveStatusCondition(&dashboard.Status.Conditions, kibanaUtils.BrokenReferencesConditionType)
+		return
 	}
-
-	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, &dashboard, r.ProjectConfig.Kibana, dashboard.Spec.TargetConfig, req.Namespace)
+	broken, err := kibanaUtils.FindBrokenDashboardReferences(kClient, kibanaeckv1alpha1.SavedObjectIDOf(dashboard), dashboard.Spec.Space)
 	if err != nil {
-		return utils.GetRequeueResult(), err
-	}
-
-	if !targetInstance.Enabled {
-		logger.Info("Kibana reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
-		return ctrl.Result{}, nil
-	}
-
-	targetInstanceNamespace := req.Namespace
-	if dashboard.Spec.TargetConfig.KibanaInstanceNamespace != "" {
-		targetInstanceNamespace = dashboard.Spec.TargetConfig.KibanaInstanceNamespace
-	}
-
-	kibanaClient := kibanaUtils.Client{
-		Cli:             r.Client,
-		Ctx:             ctx,
-		KibanaSpec:      *targetInstance,
-		KibanaNamespace: targetInstanceNamespace,
-		Req:             req,
+		log.FromContext(ctx).Error(err, "Failed to validate the references of the dashboard")
+		return
 	}
-
-	if dashboard.DeletionTimestamp.IsZero() {
-
-		if err := kibanaUtils.DependenciesFulfilled(kibanaClient, dashboard.Spec.GetSavedObject()); err != nil {
-			r.Recorder.Event(&dashboard, "Warning", "Missing dependencies",
-				fmt.Sprintf("Some of declared dependencies are not present yet: %s", err.Error()))
-			return utils.GetRequeueResult(), err
-		}
-
-		logger.Info("Creating/Updating dashboard", "id", req.Name)
-		res, err := kibanaUtils.UpsertSavedObject(kibanaClient, savedObjectType, dashboard.ObjectMeta, dashboard.Spec.GetSavedObject())
-
-		if err == nil {
-			r.Recorder.Event(&dashboard, "Normal", "Created",
-				fmt.Sprintf("Created/Updated %s/%s %s", dashboard.APIVersion, dashboard.Kind, dashboard.Name))
-		} else {
-			r.Recorder.Event(&dashboard, "Warning", "Failed to create/update",
-				fmt.Sprintf("Failed to create/update %s/%s %s: %s", dashboard.APIVersion, dashboard.Kind, dashboard.Name, err.Error()))
-		}
-
-		if !controllerutil.ContainsFinalizer(&dashboard, dashboardFinalizer) {
-			controllerutil.AddFinalizer(&dashboard, dashboardFinalizer)
-			if err := r.Update(ctx, &dashboard); err != nil {
-				return ctrl.Result{}, err
-			}
-		}
-
-		return res, err
-	} else {
-		// The object is being deleted
-		if controllerutil.ContainsFinalizer(&dashboard, dashboardFinalizer) {
-			if _, err := kibanaUtils.DeleteSavedObject(kibanaClient, savedObjectType, dashboard.ObjectMeta, dashboard.Spec.GetSavedObject()); err != nil {
-				return ctrl.Result{}, err
-			}
-
-			controllerutil.RemoveFinalizer(&dashboard, dashboardFinalizer)
-			if err := r.Update(ctx, &dashboard); err != nil {
-				return ctrl.Result{}, err
-			}
-		}
-
-		return ctrl.Result{}, nil
+	if message, changed := kibanaUtils.SetBrokenReferencesCondition(&dashboard.Status.Conditions, dashboard.Generation, broken); changed && message != "" {
+		r.Recorder.Event(dashboard, "Warning", kibanaUtils.BrokenReferencesReason, message)
 	}
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *DashboardReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&kibanaeckv1alpha1.Dashboard{}).
-		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+	return (&SavedObjectReconciler{
+		Client:        r.Client,
+		Scheme:        r.Scheme,
+		ProjectConfig: r.ProjectConfig,
+		Recorder:      r.Recorder,
+		Kind:          dashboardKind,
+	}).SetupWithManager(mgr)
 }
@@ -22,10 +22,12 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/testutils"
 )
 
 var _ = Describe("Dashboard Controller", func() {
@@ -282,4 +284,56 @@ var _ = Describe("Dashboard Controller", func() {
 			}, timeout, interval).Should(BeTrue())
 		})
 	})
+
+	Context("When reconciling a Dashboard", func() {
+		It("Should upsert the saved object in Kibana and delete it with the resource", func() {
+			ctx := context.Background()
+
+			dashboardName := "test-dashboard-reconcile"
+			dashboard := &kibanaeckv1alpha1.Dashboard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      dashboardName,
+					Namespace: DashboardNamespace,
+				},
+				Spec: kibanaeckv1alpha1.DashboardSpec{
+					SavedObject: kibanaeckv1alpha1.SavedObject{
+						Body: `{"attributes": {"title": "Reconciled Dashboard", "panelsJSON": "[]"}}`,
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, dashboard)).Should(Succeed())
+
+			dashboardLookupKey := types.NamespacedName{Name: dashboardName, Namespace: DashboardNamespace}
+			Eventually(func() string {
+				stored, _ := fakeKibana.SavedObject(testutils.DefaultSpace, "dashboard", dashboardName)
+				return string(stored)
+			}, timeout, interval).Should(ContainSubstring("Reconciled Dashboard"))
+
+			reconciledDashboard := &kibanaeckv1alpha1.Dashboard{}
+			Eventually(func() []string {
+				_ = k8sClient.Get(ctx, dashboardLookupKey, reconciledDashboard)
+				return reconciledDashboard.Finalizers
+			}, timeout, interval).Should(ContainElement("dashboards.kibana.eck.github.com/finalizer"))
+
+			By("updating the saved object when the spec changes")
+			reconciledDashboard.Spec.Body = `{"attributes": {"title": "Updated Dashboard", "panelsJSON": "[]"}}`
+			Expect(k8sClient.Update(ctx, reconciledDashboard)).Should(Succeed())
+			Eventually(func() string {
+				stored, _ := fakeKibana.SavedObject(testutils.DefaultSpace, "dashboard", dashboardName)
+				return string(stored)
+			}, timeout, interval).Should(ContainSubstring("Updated Dashboard"))
+
+			Expect(k8sClient.Delete(ctx, reconciledDashboard)).Should(Succeed())
+
+			Eventually(func() bool {
+				_, ok := fakeKibana.SavedObject(testutils.DefaultSpace, "dashboard", dashboardName)
+				return ok
+			}, timeout, interval).Should(BeFalse())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, dashboardLookupKey, &kibanaeckv1alpha1.Dashboard{})
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
 })
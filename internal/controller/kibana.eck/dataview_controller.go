@@ -17,20 +17,14 @@ limitations under the License.
 package kibanaeck
 
 import (
-	"context"
-	"fmt"
-
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
 	kibanaUtils "eck-custom-resources/utils/kibana"
 
-	"k8s.io/client-go/tools/record"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
-
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
 )
@@ -39,7 +33,7 @@ import (
 type DataViewReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 }
 
@@ -47,87 +41,46 @@ type DataViewReconciler struct {
 //+kubebuilder:rbac:groups=kibana.eck.github.com,resources=dataviews/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kibana.eck.github.com,resources=dataviews/finalizers,verbs=update
 
-func (r *DataViewReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := log.FromContext(ctx)
-
-	dataViewFinalizer := "dataview.kibana.eck.github.com/finalizer"
-
-	var dataView kibanaeckv1alpha1.DataView
-	if err := r.Get(ctx, req.NamespacedName, &dataView); err != nil {
-		return ctrl.Result{}, client.IgnoreNotFound(err)
-	}
-
-	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, &dataView, r.ProjectConfig.Kibana, dataView.Spec.TargetConfig, req.Namespace)
-	if err != nil {
-		return utils.GetRequeueResult(), err
-	}
-
-	if !targetInstance.Enabled {
-		logger.Info("Kibana reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
-		return ctrl.Result{}, nil
-	}
-
-	targetInstanceNamespace := req.Namespace
-	if dataView.Spec.TargetConfig.KibanaInstanceNamespace != "" {
-		targetInstanceNamespace = dataView.Spec.TargetConfig.KibanaInstanceNamespace
-	}
-
-	// Get the ElasticsearchInstance defined in target (if present and pass to the kibanaUtils.Client)
-	kibanaClient := kibanaUtils.Client{
-		Cli:             r.Client,
-		Ctx:             ctx,
-		KibanaSpec:      *targetInstance,
-		KibanaNamespace: targetInstanceNamespace,
-		Req:             req,
-	}
-
-	if dataView.DeletionTimestamp.IsZero() {
-		if err := kibanaUtils.DependenciesFulfilled(kibanaClient, dataView.Spec.GetSavedObject()); err != nil {
-			r.Recorder.Event(&dataView, "Warning", "Missing dependencies",
-				fmt.Sprintf("Some of declared dependencies are not present yet: %s", err.Error()))
-			return utils.GetRequeueResult(), err
-		}
-
-		logger.Info("Creating/Updating data view", "id", req.Name)
-		res, err := kibanaUtils.UpsertDataView(kibanaClient, dataView)
-
-		if err == nil {
-			r.Recorder.Event(&dataView, "Normal", "Created",
-				fmt.Sprintf("Created/Updated %s/%s %s", dataView.APIVersion, dataView.Kind, dataView.Name))
-		} else {
-			r.Recorder.Event(&dataView, "Warning", "Failed to create/update",
-				fmt.Sprintf("Failed to create/update %s/%s %s: %s", dataView.APIVersion, dataView.Kind, dataView.Name, err.Error()))
+var dataViewKind = SavedObjectKind{
+	New:       func() kibanaeckv1alpha1.SavedObjectResource { return &kibanaeckv1alpha1.DataView{} },
+	Type:      "index-pattern",
+	Finalizer: "dataview.kibana.eck.github.com/finalizer",
+	Priority:  utils.PriorityLow,
+	Upsert: func(kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource, savedObject kibanaeckv1alpha1.SavedObject, _ *string) (ctrl.Result, error) {
+		// The data views API takes the resource, the body has spec.bodyFrom and the overlays applied. It has no
+		// conditional updates, the version is not used.
+		patched := obj.(*kibanaeckv1alpha1.DataView).DeepCopy()
+		patched.Spec.Body = savedObject.Body
+		res, err := kibanaUtils.UpsertDataView(kClient, *patched)
+		if err != nil || !patched.Spec.Default {
+			return res, err
 		}
-
-		if !controllerutil.ContainsFinalizer(&dataView, dataViewFinalizer) {
-			controllerutil.AddFinalizer(&dataView, dataViewFinalizer)
-			if err := r.Update(ctx, &dataView); err != nil {
-				return ctrl.Result{}, err
-			}
+		if err := kibanaUtils.SetDefaultDataView(kClient, *patched); err != nil {
+			return utils.GetRequeueResult(), err
 		}
-		return res, err
-
-	} else {
-		// The object is being deleted
-		if controllerutil.ContainsFinalizer(&dataView, dataViewFinalizer) {
-			if _, err := kibanaUtils.DeleteDataView(kibanaClient, dataView); err != nil {
-				return ctrl.Result{}, err
-			}
-
-			controllerutil.RemoveFinalizer(&dataView, dataViewFinalizer)
-			if err := r.Update(ctx, &dataView); err != nil {
-				return ctrl.Result{}, err
+		return res, nil
+	},
+	Delete: func(kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource) (ctrl.Result, error) {
+		dataView := *obj.(*kibanaeckv1alpha1.DataView)
+		if dataView.Spec.Default {
+			if err := kibanaUtils.ResetDefaultDataView(kClient, dataView); err != nil {
+				return utils.GetRequeueResult(), err
 			}
 		}
-
-		return ctrl.Result{}, nil
-	}
+		return kibanaUtils.DeleteDataView(kClient, dataView)
+	},
+	Attributes: func(kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource) (*string, error) {
+		return kibanaUtils.GetDataViewAttributes(kClient, *obj.(*kibanaeckv1alpha1.DataView))
+	},
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *DataViewReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&kibanaeckv1alpha1.DataView{}).
-		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+	return (&SavedObjectReconciler{
+		Client:        r.Client,
+		Scheme:        r.Scheme,
+		ProjectConfig: r.ProjectConfig,
+		Recorder:      r.Recorder,
+		Kind:          dataViewKind,
+	}).SetupWithManager(mgr)
 }
@@ -19,17 +19,19 @@ package kibanaeck
 import (
 	"context"
 	"fmt"
+	"strings"
 
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
 	kibanaUtils "eck-custom-resources/utils/kibana"
 
-	"k8s.io/client-go/tools/record"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
-
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
@@ -39,97 +41,110 @@ import (
 type IndexPatternReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=kibana.eck.github.com,resources=indexpatterns,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kibana.eck.github.com,resources=indexpatterns/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kibana.eck.github.com,resources=indexpatterns/finalizers,verbs=update
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=dataviews,verbs=get;list;watch;create;update;patch;delete
+
+var indexPatternKind = SavedObjectKind{
+	New:       func() kibanaeckv1alpha1.SavedObjectResource { return &kibanaeckv1alpha1.IndexPattern{} },
+	Type:      "index-pattern",
+	Finalizer: "indexpatterns.kibana.eck.github.com/finalizer",
+	Priority:  utils.PriorityLow,
+	Migrate:   migrateIndexPattern,
+}
 
-func (r *IndexPatternReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+// migrateIndexPattern migrates IndexPatterns annotated with MigrateToDataViewAnnotation to a DataView on Kibana 8+
+// targets. If the DataView gets another id, the references of the saved objects to the index pattern are moved to it
+// once it is Ready and the index pattern is deleted. Migrated IndexPatterns get the Deprecated condition and no longer
+// touch Kibana, not even when they are deleted.
+func migrateIndexPattern(ctx context.Context, r *SavedObjectReconciler, kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource) (bool, ctrl.Result, error) {
 	logger := log.FromContext(ctx)
+	indexPattern := obj.(*kibanaeckv1alpha1.IndexPattern)
 
-	indexPatternFinalizer := "indexpatterns.kibana.eck.github.com/finalizer"
-	savedObjectType := "index-pattern"
-
-	var indexPattern kibanaeckv1alpha1.IndexPattern
-	if err := r.Get(ctx, req.NamespacedName, &indexPattern); err != nil {
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+	target, ok := kibanaUtils.IndexPatternMigrationTarget(indexPattern)
+	if !ok {
+		return false, ctrl.Result{}, nil
+	}
+	migrated := meta.IsStatusConditionTrue(indexPattern.Status.Conditions, kibanaUtils.DeprecatedConditionType)
+	if !indexPattern.DeletionTimestamp.IsZero() {
+		if !migrated {
+			return false, ctrl.Result{}, nil
+		}
+		// The saved object belongs to the DataView now
+		controllerutil.RemoveFinalizer(indexPattern, r.Kind.Finalizer)
+		return true, ctrl.Result{}, r.Update(ctx, indexPattern)
+	}
+	if migrated {
+		return true, ctrl.Result{}, nil
 	}
 
-	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, &indexPattern, r.ProjectConfig.Kibana, indexPattern.Spec.TargetConfig, req.Namespace)
+	major, err := kibanaUtils.GetKibanaMajorVersion(kClient)
 	if err != nil {
-		return utils.GetRequeueResult(), err
+		return true, utils.GetRequeueResult(), err
 	}
-
-	if !targetInstance.Enabled {
-		logger.Info("Kibana reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
-		return ctrl.Result{}, nil
+	if major < 8 {
+		r.Recorder.Event(indexPattern, "Warning", "MigrationNotSupported",
+			fmt.Sprintf("Data views require Kibana 8 or later, the target instance runs Kibana %d", major))
+		return false, ctrl.Result{}, nil
 	}
 
-	targetInstanceNamespace := req.Namespace
-	if indexPattern.Spec.TargetConfig.KibanaInstanceNamespace != "" {
-		targetInstanceNamespace = indexPattern.Spec.TargetConfig.KibanaInstanceNamespace
+	savedObject, err := kibanaUtils.ResolveBodyFrom(r.Client, ctx, indexPattern.Namespace, indexPattern.Spec.GetSavedObject())
+	if err != nil {
+		r.Recorder.Event(indexPattern, "Warning", "BodyFromError",
+			fmt.Sprintf("Failed to read spec.bodyFrom: %s", err.Error()))
+		return true, utils.GetRequeueResult(), err
 	}
-
-	// Get the ElasticsearchInstance defined in target (if present and pass to the kibanaUtils.Client)
-	kibanaClient := kibanaUtils.Client{
-		Cli:             r.Client,
-		Ctx:             ctx,
-		KibanaSpec:      *targetInstance,
-		KibanaNamespace: targetInstanceNamespace,
-		Req:             req,
+	dataView, err := kibanaUtils.ReconcileMigratedDataView(r.Client, ctx, indexPattern, target, savedObject.Body)
+	if err != nil {
+		r.Recorder.Event(indexPattern, "Warning", "MigrationFailed",
+			fmt.Sprintf("Failed to migrate to DataView %s: %s", target, err.Error()))
+		return true, utils.GetRequeueResult(), err
 	}
 
-	if indexPattern.DeletionTimestamp.IsZero() {
-
-		if err := kibanaUtils.DependenciesFulfilled(kibanaClient, indexPattern.Spec.GetSavedObject()); err != nil {
-			r.Recorder.Event(&indexPattern, "Warning", "Missing dependencies",
-				fmt.Sprintf("Some of declared dependencies are not present yet: %s", err.Error()))
-			return utils.GetRequeueResult(), err
+	message := fmt.Sprintf("Migrated to DataView %s, the IndexPattern can be deleted", target)
+	if target != indexPattern.Name {
+		// The references can only be moved once the data view exists in Kibana
+		if !meta.IsStatusConditionTrue(dataView.Status.Conditions, utils.ReadyConditionType) {
+			logger.Info("Waiting for the DataView to become Ready before migrating references", "dataView", target)
+			return true, utils.GetRequeueResult(), nil
 		}
-
-		logger.Info("Creating/Updating index pattern", "id", req.Name)
-		res, err := kibanaUtils.UpsertSavedObject(kibanaClient, savedObjectType, indexPattern.ObjectMeta, indexPattern.Spec.GetSavedObject())
-
-		if err == nil {
-			r.Recorder.Event(&indexPattern, "Normal", "Created",
-				fmt.Sprintf("Created/Updated %s/%s %s", indexPattern.APIVersion, indexPattern.Kind, indexPattern.Name))
-		} else {
-			r.Recorder.Event(&indexPattern, "Warning", "Failed to create/update",
-				fmt.Sprintf("Failed to create/update %s/%s %s: %s", indexPattern.APIVersion, indexPattern.Kind, indexPattern.Name, err.Error()))
+		references, err := kibanaUtils.MigrateIndexPatternReferences(kClient, indexPattern.Spec.Space, indexPattern.Name, target)
+		if err != nil {
+			r.Recorder.Event(indexPattern, "Warning", "MigrationFailed",
+				fmt.Sprintf("Failed to migrate the references to DataView %s: %s", target, err.Error()))
+			return true, utils.GetRequeueResult(), err
 		}
-
-		if !controllerutil.ContainsFinalizer(&indexPattern, indexPatternFinalizer) {
-			controllerutil.AddFinalizer(&indexPattern, indexPatternFinalizer)
-			if err := r.Update(ctx, &indexPattern); err != nil {
-				return ctrl.Result{}, err
-			}
+		if _, err := kibanaUtils.DeleteSavedObject(kClient, r.Kind.Type, indexPattern.ObjectMeta, indexPattern.Spec.GetSavedObject()); err != nil {
+			return true, utils.GetRequeueResult(), err
 		}
-
-		return res, err
-	} else {
-		// The object is being deleted
-		if controllerutil.ContainsFinalizer(&indexPattern, indexPatternFinalizer) {
-			if _, err := kibanaUtils.DeleteSavedObject(kibanaClient, savedObjectType, indexPattern.ObjectMeta, indexPattern.Spec.GetSavedObject()); err != nil {
-				return ctrl.Result{}, err
-			}
-
-			controllerutil.RemoveFinalizer(&indexPattern, indexPatternFinalizer)
-			if err := r.Update(ctx, &indexPattern); err != nil {
-				return ctrl.Result{}, err
-			}
+		if len(references) > 0 {
+			message = fmt.Sprintf("%s, references migrated in %s", message, strings.Join(references, ", "))
 		}
-
-		return ctrl.Result{}, nil
 	}
+
+	meta.SetStatusCondition(&indexPattern.Status.Conditions, metav1.Condition{
+		Type:               kibanaUtils.DeprecatedConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: indexPattern.Generation,
+		Reason:             kibanaUtils.DeprecatedReasonDataView,
+		Message:            message,
+	})
+	r.Recorder.Event(indexPattern, "Normal", kibanaUtils.DeprecatedReasonDataView, message)
+	return true, ctrl.Result{}, r.Status().Update(ctx, indexPattern)
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *IndexPatternReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&kibanaeckv1alpha1.IndexPattern{}).
-		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+	return (&SavedObjectReconciler{
+		Client:        r.Client,
+		Scheme:        r.Scheme,
+		ProjectConfig: r.ProjectConfig,
+		Recorder:      r.Recorder,
+		Kind:          indexPatternKind,
+	}).SetupWithManager(mgr)
 }
@@ -0,0 +1,197 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kibanaeck
+
+import (
+	"context"
+	"fmt"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	kibanaUtils "eck-custom-resources/utils/kibana"
+
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+)
+
+// KibanaRawRequestReconciler reconciles a KibanaRawRequest object
+type KibanaRawRequestReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=kibanarawrequests,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=kibanarawrequests/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=kibanarawrequests/finalizers,verbs=update
+
+func (r *KibanaRawRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	rawRequestFinalizer := "kibanarawrequests.kibana.eck.github.com/finalizer"
+
+	var rawRequest kibanaeckv1alpha1.KibanaRawRequest
+	if err := r.Get(ctx, req.NamespacedName, &rawRequest); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	projectConfig := r.ProjectConfig.Load()
+	var allowedPrefixes []string
+	if projectConfig.RawRequests != nil {
+		allowedPrefixes = projectConfig.RawRequests.Kibana
+	}
+
+	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, &rawRequest, projectConfig.Kibana, rawRequest.Spec.TargetConfig, req.Namespace)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
+
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &rawRequest, &rawRequest.Status.Conditions, targetInstance.Enabled) {
+		return ctrl.Result{}, nil
+	}
+	targetInstanceNamespace := req.Namespace
+	if rawRequest.Spec.TargetConfig.KibanaInstanceNamespace != "" {
+		targetInstanceNamespace = rawRequest.Spec.TargetConfig.KibanaInstanceNamespace
+	}
+
+	kibanaClient := kibanaUtils.Client{
+		Cli:             r.Client,
+		Ctx:             ctx,
+		KibanaSpec:      *targetInstance,
+		KibanaNamespace: targetInstanceNamespace,
+		Req:             req,
+	}
+
+	if available, res := kibanaUtils.CheckTargetAvailable(kibanaClient, r.Recorder, &rawRequest, &rawRequest.Status.Conditions); !available {
+		return res, nil
+	}
+
+	if rawRequest.DeletionTimestamp.IsZero() {
+		if err := utils.CheckRawRequestPath(allowedPrefixes, rawRequest.Spec.Path); err != nil {
+			r.Recorder.Event(&rawRequest, "Warning", utils.RawRequestNotAllowedReason, err.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &rawRequest, rawRequest.Spec, &rawRequest.Status.Conditions, &rawRequest.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update KibanaRawRequest sync status")
+			}
+			// The spec or the operator configuration have to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+		if err := utils.LintBody(&rawRequest, rawRequest.Spec.Body); err != nil {
+			r.Recorder.Event(&rawRequest, "Warning", utils.PolicyViolationReason, err.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &rawRequest, rawRequest.Spec, &rawRequest.Status.Conditions, &rawRequest.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update KibanaRawRequest sync status")
+			}
+			return ctrl.Result{}, nil
+		}
+
+		logger.Info("Sending raw request", "path", rawRequest.Spec.Path)
+		res, err := kibanaUtils.PutRawRequest(kibanaClient, rawRequest.Spec.Path, rawRequest.Spec.Body)
+		if err == nil {
+			if err = r.deleteReplacedObject(ctx, kibanaClient, &rawRequest, allowedPrefixes); err != nil {
+				res = utils.GetRequeueResult()
+			}
+		}
+
+		if err == nil {
+			r.Recorder.Event(&rawRequest, "Normal", "Created",
+				fmt.Sprintf("Created/Updated %s/%s %s%s", rawRequest.APIVersion, rawRequest.Kind, rawRequest.Name, utils.AppliedBodyChanges(ctx, &rawRequest, targetInstance.Url, rawRequest.Spec.Body)))
+		} else {
+			r.Recorder.Event(&rawRequest, "Warning", "Failed to create/update",
+				fmt.Sprintf("Failed to create/update %s/%s %s: %s", rawRequest.APIVersion, rawRequest.Kind, rawRequest.Name, err.Error()))
+		}
+
+		if !controllerutil.ContainsFinalizer(&rawRequest, rawRequestFinalizer) {
+			controllerutil.AddFinalizer(&rawRequest, rawRequestFinalizer)
+			if err := r.Update(ctx, &rawRequest); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		if err == nil {
+			rawRequest.Status.AppliedPath = rawRequest.Spec.Path
+			rawRequest.Status.AppliedDeletePath = rawRequest.Spec.GetDeletePath()
+		}
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &rawRequest, rawRequest.Spec, &rawRequest.Status.Conditions, &rawRequest.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update KibanaRawRequest sync status")
+		}
+		return res, err
+	} else {
+		// The object is being deleted
+		if controllerutil.ContainsFinalizer(&rawRequest, rawRequestFinalizer) {
+			if deletePath := rawRequest.GetAppliedDeletePath(); deletePath != "" {
+				// A path removed from the allowlist must not block the deletion of the resource
+				if err := utils.CheckRawRequestPath(allowedPrefixes, deletePath); err != nil {
+					r.Recorder.Event(&rawRequest, "Warning", utils.RawRequestNotAllowedReason,
+						fmt.Sprintf("Not sending the DELETE request: %s", err.Error()))
+				} else if _, err := kibanaUtils.DeleteRawRequest(kibanaClient, deletePath); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+
+			controllerutil.RemoveFinalizer(&rawRequest, rawRequestFinalizer)
+			utils.ForgetAppliedBody(&rawRequest)
+			if err := r.Update(ctx, &rawRequest); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		return ctrl.Result{}, nil
+	}
+}
+
+// deleteReplacedObject deletes the object of the previously applied path once spec.path changed, unless the DELETE
+// path stayed the same
+func (r *KibanaRawRequestReconciler) deleteReplacedObject(ctx context.Context, kibanaClient kibanaUtils.Client,
+	rawRequest *kibanaeckv1alpha1.KibanaRawRequest, allowedPrefixes []string) error {
+	previous := rawRequest.Status.AppliedDeletePath
+	if rawRequest.Status.AppliedPath == "" || rawRequest.Status.AppliedPath == rawRequest.Spec.Path ||
+		previous == "" || previous == rawRequest.Spec.GetDeletePath() {
+		return nil
+	}
+	if err := utils.CheckRawRequestPath(allowedPrefixes, previous); err != nil {
+		r.Recorder.Event(rawRequest, "Warning", utils.RawRequestNotAllowedReason,
+			fmt.Sprintf("Not deleting the object of the previous path: %s", err.Error()))
+		return nil
+	}
+	log.FromContext(ctx).Info("Deleting object of the previous path", "path", previous)
+	_, err := kibanaUtils.DeleteRawRequest(kibanaClient, previous)
+	return err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KibanaRawRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&kibanaeckv1alpha1.KibanaRawRequest{}, utils.PriorityNormal)
+	metrics := utils.NewReconcileMetrics(mgr, &kibanaeckv1alpha1.KibanaRawRequest{})
+	syncWave := utils.NewSyncWave(mgr, &kibanaeckv1alpha1.KibanaRawRequest{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &kibanaeckv1alpha1.KibanaRawRequest{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &kibanaeckv1alpha1.KibanaRawRequest{}, r.Recorder)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kibanaeckv1alpha1.KibanaRawRequest{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
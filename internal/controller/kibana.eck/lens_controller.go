@@ -17,20 +17,13 @@ limitations under the License.
 package kibanaeck
 
 import (
-	"context"
-	"fmt"
-
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
-	kibanaUtils "eck-custom-resources/utils/kibana"
-
-	"k8s.io/client-go/tools/record"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
 )
@@ -39,7 +32,7 @@ import (
 type LensReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 }
 
@@ -47,87 +40,20 @@ type LensReconciler struct {
 //+kubebuilder:rbac:groups=kibana.eck.github.com,resources=lens/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kibana.eck.github.com,resources=lens/finalizers,verbs=update
 
-func (r *LensReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := log.FromContext(ctx)
-
-	lensFinalizer := "lenses.kibana.eck.github.com/finalizer"
-	savedObjectType := "lens"
-
-	var lens kibanaeckv1alpha1.Lens
-	if err := r.Get(ctx, req.NamespacedName, &lens); err != nil {
-		return ctrl.Result{}, client.IgnoreNotFound(err)
-	}
-
-	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, &lens, r.ProjectConfig.Kibana, lens.Spec.TargetConfig, req.Namespace)
-	if err != nil {
-		return utils.GetRequeueResult(), err
-	}
-
-	if !targetInstance.Enabled {
-		logger.Info("Kibana reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
-		return ctrl.Result{}, nil
-	}
-
-	targetInstanceNamespace := req.Namespace
-	if lens.Spec.TargetConfig.KibanaInstanceNamespace != "" {
-		targetInstanceNamespace = lens.Spec.TargetConfig.KibanaInstanceNamespace
-	}
-
-	// Get the ElasticsearchInstance defined in target (if present and pass to the kibanaUtils.Client)
-	kibanaClient := kibanaUtils.Client{
-		Cli:             r.Client,
-		Ctx:             ctx,
-		KibanaSpec:      *targetInstance,
-		KibanaNamespace: targetInstanceNamespace,
-		Req:             req,
-	}
-
-	if lens.DeletionTimestamp.IsZero() {
-		if err := kibanaUtils.DependenciesFulfilled(kibanaClient, lens.Spec.GetSavedObject()); err != nil {
-			r.Recorder.Event(&lens, "Warning", "Missing dependencies",
-				fmt.Sprintf("Some of declared dependencies are not present yet: %s", err.Error()))
-			return utils.GetRequeueResult(), err
-		}
-
-		logger.Info("Creating/Updating lens", "id", req.Name)
-		res, err := kibanaUtils.UpsertSavedObject(kibanaClient, savedObjectType, lens.ObjectMeta, lens.Spec.GetSavedObject())
-
-		if err == nil {
-			r.Recorder.Event(&lens, "Normal", "Created",
-				fmt.Sprintf("Created/Updated %s/%s %s", lens.APIVersion, lens.Kind, lens.Name))
-		} else {
-			r.Recorder.Event(&lens, "Warning", "Failed to create/update",
-				fmt.Sprintf("Failed to create/update %s/%s %s: %s", lens.APIVersion, lens.Kind, lens.Name, err.Error()))
-		}
-
-		if !controllerutil.ContainsFinalizer(&lens, lensFinalizer) {
-			controllerutil.AddFinalizer(&lens, lensFinalizer)
-			if err := r.Update(ctx, &lens); err != nil {
-				return ctrl.Result{}, err
-			}
-		}
-		return res, err
-	} else {
-		// The object is being deleted
-		if controllerutil.ContainsFinalizer(&lens, lensFinalizer) {
-			if _, err := kibanaUtils.DeleteSavedObject(kibanaClient, savedObjectType, lens.ObjectMeta, lens.Spec.GetSavedObject()); err != nil {
-				return ctrl.Result{}, err
-			}
-
-			controllerutil.RemoveFinalizer(&lens, lensFinalizer)
-			if err := r.Update(ctx, &lens); err != nil {
-				return ctrl.Result{}, err
-			}
-		}
-
-		return ctrl.Result{}, nil
-	}
+var lensKind = SavedObjectKind{
+	New:       func() kibanaeckv1alpha1.SavedObjectResource { return &kibanaeckv1alpha1.Lens{} },
+	Type:      "lens",
+	Finalizer: "lenses.kibana.eck.github.com/finalizer",
+	Priority:  utils.PriorityLow,
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *LensReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&kibanaeckv1alpha1.Lens{}).
-		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+	return (&SavedObjectReconciler{
+		Client:        r.Client,
+		Scheme:        r.Scheme,
+		ProjectConfig: r.ProjectConfig,
+		Recorder:      r.Recorder,
+		Kind:          lensKind,
+	}).SetupWithManager(mgr)
 }
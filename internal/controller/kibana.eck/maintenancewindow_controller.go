@@ -0,0 +1,170 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kibanaeck
+
+import (
+	"context"
+	"fmt"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	kibanaUtils "eck-custom-resources/utils/kibana"
+	"eck-custom-resources/utils/overlay"
+
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+)
+
+// MaintenanceWindowReconciler reconciles a MaintenanceWindow object
+type MaintenanceWindowReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=maintenancewindows,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=maintenancewindows/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=maintenancewindows/finalizers,verbs=update
+
+func (r *MaintenanceWindowReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	maintenanceWindowFinalizer := "maintenancewindows.kibana.eck.github.com/finalizer"
+
+	var maintenanceWindow kibanaeckv1alpha1.MaintenanceWindow
+	if err := r.Get(ctx, req.NamespacedName, &maintenanceWindow); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, &maintenanceWindow, r.ProjectConfig.Load().Kibana, maintenanceWindow.Spec.TargetConfig, req.Namespace)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
+
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &maintenanceWindow, &maintenanceWindow.Status.Conditions, targetInstance.Enabled) {
+		return ctrl.Result{}, nil
+	}
+	targetInstanceNamespace := req.Namespace
+	if maintenanceWindow.Spec.TargetConfig.KibanaInstanceNamespace != "" {
+		targetInstanceNamespace = maintenanceWindow.Spec.TargetConfig.KibanaInstanceNamespace
+	}
+
+	kibanaClient := kibanaUtils.Client{
+		Cli:             r.Client,
+		Ctx:             ctx,
+		KibanaSpec:      *targetInstance,
+		KibanaNamespace: targetInstanceNamespace,
+		Req:             req,
+	}
+
+	if available, res := kibanaUtils.CheckTargetAvailable(kibanaClient, r.Recorder, &maintenanceWindow, &maintenanceWindow.Status.Conditions); !available {
+		return res, nil
+	}
+
+	if maintenanceWindow.DeletionTimestamp.IsZero() {
+		logger.Info("Creating/Updating maintenance window", "name", req.Name)
+		body, err := overlay.Apply(r.Client, ctx, &maintenanceWindow, maintenanceWindow.Spec.GetBody())
+		if err != nil {
+			r.Recorder.Event(&maintenanceWindow, "Warning", "OverlayError",
+				fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+		if err := utils.LintBody(&maintenanceWindow, body); err != nil {
+			r.Recorder.Event(&maintenanceWindow, "Warning", utils.PolicyViolationReason, err.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &maintenanceWindow, maintenanceWindow.Spec, &maintenanceWindow.Status.Conditions, &maintenanceWindow.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update MaintenanceWindow sync status")
+			}
+			// The spec or the lint rules have to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+		patched := maintenanceWindow.DeepCopy()
+		patched.Spec.Body = body
+		id, res, err := kibanaUtils.UpsertMaintenanceWindow(kibanaClient, *patched)
+
+		// The id is only known to Kibana, it is persisted right away so that the next reconcile does not create a
+		// duplicate maintenance window
+		space := kibanaUtils.MaintenanceWindowSpace(maintenanceWindow.Spec.Space)
+		if id != "" && (id != maintenanceWindow.Status.MaintenanceWindowID || space != maintenanceWindow.Status.Space) {
+			statusPatch := client.MergeFrom(maintenanceWindow.DeepCopy())
+			maintenanceWindow.Status.MaintenanceWindowID = id
+			maintenanceWindow.Status.Space = space
+			if statusErr := r.Status().Patch(ctx, &maintenanceWindow, statusPatch); statusErr != nil {
+				return utils.GetRequeueResult(), statusErr
+			}
+		}
+
+		if err == nil {
+			r.Recorder.Event(&maintenanceWindow, "Normal", "Created",
+				fmt.Sprintf("Created/Updated %s/%s %s%s", maintenanceWindow.APIVersion, maintenanceWindow.Kind, maintenanceWindow.Name, utils.AppliedBodyChanges(ctx, &maintenanceWindow, targetInstance.Url, body)))
+		} else {
+			r.Recorder.Event(&maintenanceWindow, "Warning", "Failed to create/update",
+				fmt.Sprintf("Failed to create/update %s/%s %s: %s", maintenanceWindow.APIVersion, maintenanceWindow.Kind, maintenanceWindow.Name, err.Error()))
+		}
+
+		if !controllerutil.ContainsFinalizer(&maintenanceWindow, maintenanceWindowFinalizer) {
+			controllerutil.AddFinalizer(&maintenanceWindow, maintenanceWindowFinalizer)
+			if err := r.Update(ctx, &maintenanceWindow); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &maintenanceWindow, maintenanceWindow.Spec, &maintenanceWindow.Status.Conditions, &maintenanceWindow.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update MaintenanceWindow sync status")
+		}
+		return res, err
+	} else {
+		// The object is being deleted
+		if controllerutil.ContainsFinalizer(&maintenanceWindow, maintenanceWindowFinalizer) {
+			if _, err := kibanaUtils.DeleteMaintenanceWindow(kibanaClient, maintenanceWindow); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			controllerutil.RemoveFinalizer(&maintenanceWindow, maintenanceWindowFinalizer)
+			utils.ForgetAppliedBody(&maintenanceWindow)
+			if err := r.Update(ctx, &maintenanceWindow); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		return ctrl.Result{}, nil
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MaintenanceWindowReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&kibanaeckv1alpha1.MaintenanceWindow{}, utils.PriorityHigh)
+	metrics := utils.NewReconcileMetrics(mgr, &kibanaeckv1alpha1.MaintenanceWindow{})
+	syncWave := utils.NewSyncWave(mgr, &kibanaeckv1alpha1.MaintenanceWindow{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &kibanaeckv1alpha1.MaintenanceWindow{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &kibanaeckv1alpha1.MaintenanceWindow{}, r.Recorder)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kibanaeckv1alpha1.MaintenanceWindow{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kibanaeck
+
+import (
+	"context"
+	"fmt"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	kibanaUtils "eck-custom-resources/utils/kibana"
+	"eck-custom-resources/utils/overlay"
+
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+)
+
+// PackagePolicyReconciler reconciles a PackagePolicy object
+type PackagePolicyReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=packagepolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=packagepolicies/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=packagepolicies/finalizers,verbs=update
+
+func (r *PackagePolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	packagePolicyFinalizer := "packagepolicies.kibana.eck.github.com/finalizer"
+
+	var packagePolicy kibanaeckv1alpha1.PackagePolicy
+	if err := r.Get(ctx, req.NamespacedName, &packagePolicy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, &packagePolicy, r.ProjectConfig.Load().Kibana, packagePolicy.Spec.TargetConfig, req.Namespace)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
+
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &packagePolicy, &packagePolicy.Status.Conditions, targetInstance.Enabled) {
+		return ctrl.Result{}, nil
+	}
+	targetInstanceNamespace := req.Namespace
+	if packagePolicy.Spec.TargetConfig.KibanaInstanceNamespace != "" {
+		targetInstanceNamespace = packagePolicy.Spec.TargetConfig.KibanaInstanceNamespace
+	}
+
+	kibanaClient := kibanaUtils.Client{
+		Cli:             r.Client,
+		Ctx:             ctx,
+		KibanaSpec:      *targetInstance,
+		KibanaNamespace: targetInstanceNamespace,
+		Req:             req,
+	}
+
+	if available, res := kibanaUtils.CheckTargetAvailable(kibanaClient, r.Recorder, &packagePolicy, &packagePolicy.Status.Conditions); !available {
+		return res, nil
+	}
+
+	if packagePolicy.DeletionTimestamp.IsZero() {
+		if err := kibanaUtils.AgentPoliciesExist(kibanaClient, packagePolicy); err != nil {
+			r.Recorder.Event(&packagePolicy, "Warning", "Missing dependencies",
+				fmt.Sprintf("Some of the agent policies are not present yet: %s", err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+
+		logger.Info("Creating/Updating package policy", "name", req.Name)
+		body, err := overlay.Apply(r.Client, ctx, &packagePolicy, packagePolicy.Spec.GetBody())
+		if err != nil {
+			r.Recorder.Event(&packagePolicy, "Warning", "OverlayError",
+				fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+		if err := utils.LintBody(&packagePolicy, body); err != nil {
+			r.Recorder.Event(&packagePolicy, "Warning", utils.PolicyViolationReason, err.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &packagePolicy, packagePolicy.Spec, &packagePolicy.Status.Conditions, &packagePolicy.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update PackagePolicy sync status")
+			}
+			// The spec or the lint rules have to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+		patched := packagePolicy.DeepCopy()
+		patched.Spec.Body = body
+		res, err := kibanaUtils.UpsertPackagePolicy(kibanaClient, *patched)
+
+		if err == nil {
+			r.Recorder.Event(&packagePolicy, "Normal", "Created",
+				fmt.Sprintf("Created/Updated %s/%s %s%s", packagePolicy.APIVersion, packagePolicy.Kind, packagePolicy.Name, utils.AppliedBodyChanges(ctx, &packagePolicy, targetInstance.Url, body)))
+		} else {
+			r.Recorder.Event(&packagePolicy, "Warning", "Failed to create/update",
+				fmt.Sprintf("Failed to create/update %s/%s %s: %s", packagePolicy.APIVersion, packagePolicy.Kind, packagePolicy.Name, err.Error()))
+		}
+
+		if !controllerutil.ContainsFinalizer(&packagePolicy, packagePolicyFinalizer) {
+			controllerutil.AddFinalizer(&packagePolicy, packagePolicyFinalizer)
+			if err := r.Update(ctx, &packagePolicy); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &packagePolicy, packagePolicy.Spec, &packagePolicy.Status.Conditions, &packagePolicy.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update PackagePolicy sync status")
+		}
+		return res, err
+	} else {
+		// The object is being deleted
+		if controllerutil.ContainsFinalizer(&packagePolicy, packagePolicyFinalizer) {
+			if _, err := kibanaUtils.DeletePackagePolicy(kibanaClient, packagePolicy); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			controllerutil.RemoveFinalizer(&packagePolicy, packagePolicyFinalizer)
+			utils.ForgetAppliedBody(&packagePolicy)
+			if err := r.Update(ctx, &packagePolicy); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		return ctrl.Result{}, nil
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PackagePolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&kibanaeckv1alpha1.PackagePolicy{}, utils.PriorityLow)
+	metrics := utils.NewReconcileMetrics(mgr, &kibanaeckv1alpha1.PackagePolicy{})
+	syncWave := utils.NewSyncWave(mgr, &kibanaeckv1alpha1.PackagePolicy{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &kibanaeckv1alpha1.PackagePolicy{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &kibanaeckv1alpha1.PackagePolicy{}, r.Recorder)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kibanaeckv1alpha1.PackagePolicy{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
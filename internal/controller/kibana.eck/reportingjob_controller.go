@@ -0,0 +1,212 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kibanaeck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	kibanaUtils "eck-custom-resources/utils/kibana"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+)
+
+// reportPollInterval is how often a pending report job is checked
+const reportPollInterval = 30 * time.Second
+
+// ReportingJobReconciler reconciles a ReportingJob object
+type ReportingJobReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=reportingjobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=reportingjobs/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=reportingjobs/finalizers,verbs=update
+
+func (r *ReportingJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var reportingJob kibanaeckv1alpha1.ReportingJob
+	if err := r.Get(ctx, req.NamespacedName, &reportingJob); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	// Reports are kept by Kibana, there is nothing to clean up
+	if !reportingJob.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	schedule, location, err := parseReportingSchedule(reportingJob)
+	if err == nil {
+		err = kibanaUtils.VerifyReportingJob(reportingJob)
+	}
+	if err != nil {
+		r.Recorder.Event(&reportingJob, "Warning", "InvalidReportingJob", err.Error())
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &reportingJob, reportingJob.Spec, &reportingJob.Status.Conditions, &reportingJob.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update ReportingJob sync status")
+		}
+		// The spec has to be changed, retrying does not help
+		return ctrl.Result{}, nil
+	}
+
+	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, &reportingJob, r.ProjectConfig.Load().Kibana, reportingJob.Spec.TargetConfig, req.Namespace)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
+
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &reportingJob, &reportingJob.Status.Conditions, targetInstance.Enabled) {
+		return ctrl.Result{}, nil
+	}
+	targetInstanceNamespace := req.Namespace
+	if reportingJob.Spec.TargetConfig.KibanaInstanceNamespace != "" {
+		targetInstanceNamespace = reportingJob.Spec.TargetConfig.KibanaInstanceNamespace
+	}
+
+	kibanaClient := kibanaUtils.Client{
+		Cli:             r.Client,
+		Ctx:             ctx,
+		KibanaSpec:      *targetInstance,
+		KibanaNamespace: targetInstanceNamespace,
+		Req:             req,
+	}
+
+	if available, res := kibanaUtils.CheckTargetAvailable(kibanaClient, r.Recorder, &reportingJob, &reportingJob.Status.Conditions); !available {
+		return res, nil
+	}
+
+	var jobErr error
+	status := &reportingJob.Status
+	if status.JobStatus == kibanaeckv1alpha1.ReportingJobStatusPending {
+		jobStatus, message, err := kibanaUtils.GetReportStatus(kibanaClient, strings.TrimPrefix(status.ReportURL, targetInstance.Url))
+		if err != nil {
+			return utils.GetRequeueResult(), err
+		}
+		status.JobStatus = jobStatus
+		switch jobStatus {
+		case kibanaeckv1alpha1.ReportingJobStatusCompleted:
+			status.LastSuccessfulTime = &metav1.Time{Time: time.Now()}
+			r.Recorder.Event(&reportingJob, "Normal", "ReportCompleted",
+				fmt.Sprintf("Report %s completed, available at %s", status.JobID, status.ReportURL))
+		case kibanaeckv1alpha1.ReportingJobStatusFailed:
+			r.Recorder.Event(&reportingJob, "Warning", "ReportFailed",
+				fmt.Sprintf("Report %s failed: %s", status.JobID, message))
+		}
+	}
+	if status.JobStatus == kibanaeckv1alpha1.ReportingJobStatusFailed {
+		jobErr = fmt.Errorf("report %s failed", status.JobID)
+	}
+
+	now := time.Now()
+	last := reportingJob.CreationTimestamp.Time
+	if status.LastScheduleTime != nil {
+		last = status.LastScheduleTime.Time
+	}
+	due := schedule.Next(last.In(location))
+	if !reportingJob.Spec.Suspend && !due.IsZero() && !due.After(now) && status.JobStatus != kibanaeckv1alpha1.ReportingJobStatusPending {
+		logger.Info("Triggering report", "name", req.Name, "scheduled", due)
+		jobID, path, err := kibanaUtils.GenerateReport(kibanaClient, reportingJob)
+		if err != nil {
+			r.Recorder.Event(&reportingJob, "Warning", "Failed to trigger report",
+				fmt.Sprintf("Failed to trigger report %s: %s", reportingJob.Name, err.Error()))
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &reportingJob, reportingJob.Spec, &reportingJob.Status.Conditions, &reportingJob.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update ReportingJob sync status")
+			}
+			return utils.GetRequeueResult(), err
+		}
+		// The job is persisted right away, the next reconcile would otherwise trigger the report again
+		statusPatch := client.MergeFrom(reportingJob.DeepCopy())
+		status.LastScheduleTime = &metav1.Time{Time: now}
+		status.JobID = jobID
+		status.JobStatus = kibanaeckv1alpha1.ReportingJobStatusPending
+		status.ReportURL = targetInstance.Url + path
+		jobErr = nil
+		r.Recorder.Event(&reportingJob, "Normal", "ReportTriggered",
+			fmt.Sprintf("Triggered %s report %s of %s %s", reportingJob.Spec.Format, jobID, reportingJob.Spec.ObjectType, reportingJob.Spec.ObjectID))
+		if err := r.Status().Patch(ctx, &reportingJob, statusPatch); err != nil {
+			return utils.GetRequeueResult(), err
+		}
+	}
+
+	next := schedule.Next(now.In(location))
+	status.NextScheduleTime = nil
+	if !reportingJob.Spec.Suspend && !next.IsZero() {
+		status.NextScheduleTime = &metav1.Time{Time: next}
+	}
+
+	if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &reportingJob, reportingJob.Spec, &reportingJob.Status.Conditions, &reportingJob.Status.ObservedGeneration, jobErr); statusErr != nil {
+		logger.Error(statusErr, "Failed to update ReportingJob sync status")
+	}
+
+	switch {
+	case status.JobStatus == kibanaeckv1alpha1.ReportingJobStatusPending:
+		return utils.RequeueScheduled(ctx, reportPollInterval), nil
+	case status.NextScheduleTime == nil:
+		return ctrl.Result{}, nil
+	default:
+		return utils.RequeueScheduled(ctx, next.Sub(now)), nil
+	}
+}
+
+// parseReportingSchedule returns the parsed schedule of the reporting job and the location it is evaluated in
+func parseReportingSchedule(reportingJob kibanaeckv1alpha1.ReportingJob) (*utils.CronSchedule, *time.Location, error) {
+	schedule, err := utils.ParseCronSchedule(reportingJob.Spec.Schedule)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid schedule: %w", err)
+	}
+	location := time.UTC
+	if reportingJob.Spec.TimeZone != "" {
+		if location, err = time.LoadLocation(reportingJob.Spec.TimeZone); err != nil {
+			return nil, nil, fmt.Errorf("invalid time zone: %w", err)
+		}
+	}
+	if schedule.Next(time.Now().In(location)).IsZero() {
+		return nil, nil, errors.New("invalid schedule: it never matches")
+	}
+	return schedule, location, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ReportingJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&kibanaeckv1alpha1.ReportingJob{}, utils.PriorityLow)
+	metrics := utils.NewReconcileMetrics(mgr, &kibanaeckv1alpha1.ReportingJob{})
+	syncWave := utils.NewSyncWave(mgr, &kibanaeckv1alpha1.ReportingJob{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &kibanaeckv1alpha1.ReportingJob{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &kibanaeckv1alpha1.ReportingJob{}, r.Recorder)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kibanaeckv1alpha1.ReportingJob{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
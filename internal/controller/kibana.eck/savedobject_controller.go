@@ -0,0 +1,399 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kibanaeck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/utils"
+	kibanaUtils "eck-custom-resources/utils/kibana"
+	"eck-custom-resources/utils/overlay"
+	"eck-custom-resources/utils/quota"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ExternallyModified condition, set while a saved object with the update policy Fail was changed in Kibana since the
+// operator wrote it last
+const (
+	ExternallyModifiedConditionType = "ExternallyModified"
+	ExternallyModifiedReason        = "ExternallyModified"
+)
+
+// SavedObjectKind configures the SavedObjectReconciler for a kind of resource deployed to Kibana as a saved object
+type SavedObjectKind struct {
+	// New returns an empty resource of the kind
+	New func() kibanaeckv1alpha1.SavedObjectResource
+	// Type of the saved object in Kibana, e.g. dashboard
+	Type string
+	// Finalizer added to the resources, their saved object is deleted before it is removed
+	Finalizer string
+	// Priority of the resources of the kind after an operator restart
+	Priority utils.Priority
+
+	// Upsert creates or updates the saved object, the body of savedObject has bodyFrom and overlays applied. version
+	// is the version of the saved object written last, see UpsertSavedObject. Defaults to UpsertSavedObject.
+	Upsert func(kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource, savedObject kibanaeckv1alpha1.SavedObject, version *string) (ctrl.Result, error)
+	// Delete deletes the saved object. Defaults to DeleteSavedObject.
+	Delete func(kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource) (ctrl.Result, error)
+	// Attributes returns the attributes of the saved object in Kibana, nil if it does not exist. Defaults to
+	// GetSavedObjectAttributes.
+	Attributes func(kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource) (*string, error)
+	// Migrate reconciles resources migrated to another kind instead of their saved object, and returns true for them.
+	// It runs before the saved object is deleted or upserted. Optional.
+	Migrate func(ctx context.Context, r *SavedObjectReconciler, kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource) (bool, ctrl.Result, error)
+	// Verify checks the saved object after it was upserted, reporting problems in the conditions of obj instead of
+	// failing the reconcile. Optional.
+	Verify func(ctx context.Context, r *SavedObjectReconciler, kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource)
+}
+
+// SavedObjectReconciler reconciles the resources of a SavedObjectKind. It resolves the target instance, dependencies,
+// spec.bodyFrom, environment overlays and the conflict policy, and maintains the finalizer and the sync status.
+type SavedObjectReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ProjectConfig *config.Store
+	Recorder      record.EventRecorder
+	Kind          SavedObjectKind
+}
+
+func (r *SavedObjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	obj := r.Kind.New()
+	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	gvk := obj.GetObjectKind().GroupVersionKind()
+
+	targetConfig := obj.GetTargetConfig()
+	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, obj, r.ProjectConfig.Load().Kibana, targetConfig, req.Namespace)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
+
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, obj, obj.GetConditions(), targetInstance.Enabled) {
+		return ctrl.Result{}, nil
+	}
+
+	targetInstanceNamespace := req.Namespace
+	if targetConfig.KibanaInstanceNamespace != "" {
+		targetInstanceNamespace = targetConfig.KibanaInstanceNamespace
+	}
+
+	kibanaClient := kibanaUtils.Client{
+		Cli:             r.Client,
+		Ctx:             ctx,
+		KibanaSpec:      *targetInstance,
+		KibanaNamespace: targetInstanceNamespace,
+		Req:             req,
+		Elasticsearch:   r.ProjectConfig.Load().Elasticsearch,
+	}
+
+	if available, res := kibanaUtils.CheckTargetAvailable(kibanaClient, r.Recorder, obj, obj.GetConditions()); !available {
+		return res, nil
+	}
+
+	if r.Kind.Migrate != nil {
+		if migrated, res, err := r.Kind.Migrate(ctx, r, kibanaClient, obj); migrated || err != nil {
+			return res, err
+		}
+	}
+
+	if !obj.GetDeletionTimestamp().IsZero() {
+		if controllerutil.ContainsFinalizer(obj, r.Kind.Finalizer) {
+			if _, err := r.delete(kibanaClient, obj); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			controllerutil.RemoveFinalizer(obj, r.Kind.Finalizer)
+			utils.ForgetAppliedBody(obj)
+			if err := r.Update(ctx, obj); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		return ctrl.Result{}, nil
+	}
+
+	if reason, err := quota.Admit(ctx, r.Client, obj); err != nil {
+		return utils.GetRequeueResult(), err
+	} else if reason != "" {
+		r.Recorder.Event(obj, "Warning", "QuotaExceeded", fmt.Sprintf("Not creating %s: %s", obj.GetName(), reason))
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, obj, obj.GetSpec(), obj.GetConditions(), obj.GetObservedGeneration(), errors.New(reason)); statusErr != nil {
+			logger.Error(statusErr, "Failed to update saved object sync status")
+		}
+		// Requeued until the quota allows the resource
+		return utils.GetRequeueResult(), nil
+	}
+
+	if err := kibanaUtils.DependenciesFulfilled(kibanaClient, obj.GetSavedObjectSpec()); err != nil {
+		r.Recorder.Event(obj, "Warning", "Missing dependencies",
+			fmt.Sprintf("Some of declared dependencies are not present yet: %s", err.Error()))
+		return utils.GetRequeueResult(), err
+	}
+
+	if err := r.move(ctx, obj, req.Namespace); err != nil {
+		r.Recorder.Event(obj, "Warning", "MoveFailed",
+			fmt.Sprintf("Failed to delete the saved object from its previous space or target instance: %s", err.Error()))
+		return utils.GetRequeueResult(), err
+	}
+
+	logger.Info("Creating/Updating saved object", "type", r.Kind.Type, "id", kibanaeckv1alpha1.SavedObjectIDOf(obj))
+	savedObject, err := kibanaUtils.ResolveBodyFrom(r.Client, ctx, obj.GetNamespace(), obj.GetSavedObjectSpec())
+	if err != nil {
+		r.Recorder.Event(obj, "Warning", "BodyFromError",
+			fmt.Sprintf("Failed to read spec.bodyFrom: %s", err.Error()))
+		return utils.GetRequeueResult(), err
+	}
+	body, err := overlay.Apply(r.Client, ctx, obj, savedObject.Body)
+	if err != nil {
+		r.Recorder.Event(obj, "Warning", "OverlayError",
+			fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+		return utils.GetRequeueResult(), err
+	}
+	if err := utils.LintBody(obj, body); err != nil {
+		r.Recorder.Event(obj, "Warning", utils.PolicyViolationReason, err.Error())
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, obj, obj.GetSpec(), obj.GetConditions(), obj.GetObservedGeneration(), err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update sync status")
+		}
+		// The spec or the lint rules have to be changed, retrying does not help
+		return ctrl.Result{}, nil
+	}
+	savedObject.Body = body
+	upsert, adoption, err := kibanaUtils.ResolveSavedObjectConflict(obj, controllerutil.ContainsFinalizer(obj, r.Kind.Finalizer), savedObject, obj.GetAdoption(), func() (*string, error) {
+		return r.attributes(kibanaClient, obj)
+	})
+	if errors.Is(err, kibanaUtils.ErrSavedObjectConflict) {
+		r.Recorder.Event(obj, "Warning", "Conflict", err.Error())
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, obj, obj.GetSpec(), obj.GetConditions(), obj.GetObservedGeneration(), err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update sync status")
+		}
+		return utils.GetRequeueResult(), nil
+	}
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+
+	res := ctrl.Result{}
+	version := obj.GetSavedObjectVersion()
+	if upsert {
+		if backup := savedObject.Backup; backup != nil {
+			if err := r.backup(ctx, kibanaClient, obj, *backup); err != nil {
+				r.Recorder.Event(obj, "Warning", "BackupFailed",
+					fmt.Sprintf("Failed to back up the saved object %s, it is not overwritten: %s", obj.GetName(), err.Error()))
+				return utils.GetRequeueResult(), err
+			}
+		}
+		res, err = r.upsert(kibanaClient, obj, savedObject, &version)
+		if errors.Is(err, kibanaUtils.ErrSavedObjectModified) {
+			r.Recorder.Event(obj, "Warning", ExternallyModifiedReason,
+				fmt.Sprintf("%s, it is left untouched, set spec.updatePolicy to Overwrite to replace the changes", err.Error()))
+			setExternallyModified(obj.GetConditions(), obj.GetGeneration(), err.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, obj, obj.GetSpec(), obj.GetConditions(), obj.GetObservedGeneration(), err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update sync status")
+			}
+			return utils.GetRequeueResult(), nil
+		}
+		if err == nil {
+			err = r.share(kibanaClient, obj, savedObject)
+		}
+		if err == nil {
+			meta.RemoveStatusCondition(obj.GetConditions(), ExternallyModifiedConditionType)
+			r.Recorder.Event(obj, "Normal", "Created",
+				fmt.Sprintf("Created/Updated %s/%s %s%s", gvk.GroupVersion().String(), gvk.Kind, obj.GetName(), utils.AppliedBodyChanges(ctx, obj, targetInstance.Url, body)))
+		} else {
+			r.Recorder.Event(obj, "Warning", "Failed to create/update",
+				fmt.Sprintf("Failed to create/update %s/%s %s: %s", gvk.GroupVersion().String(), gvk.Kind, obj.GetName(), err.Error()))
+		}
+	} else if adoption != obj.GetAdoption() {
+		r.Recorder.Event(obj, "Normal", "Adopted",
+			fmt.Sprintf("Adopted the existing saved object %s, the body is applied from its next change on", obj.GetName()))
+	}
+
+	if !controllerutil.ContainsFinalizer(obj, r.Kind.Finalizer) {
+		controllerutil.AddFinalizer(obj, r.Kind.Finalizer)
+		if err := r.Update(ctx, obj); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	obj.SetAdoption(adoption)
+	obj.SetSavedObjectVersion(version)
+	if err == nil {
+		location := kibanaeckv1alpha1.LocationOf(obj)
+		obj.SetDeployedTo(&location)
+		if upsert && r.Kind.Verify != nil {
+			r.Kind.Verify(ctx, r, kibanaClient, obj)
+		}
+	}
+	if statusErr := utils.UpdateSyncStatus(r.Client, ctx, obj, obj.GetSpec(), obj.GetConditions(), obj.GetObservedGeneration(), err); statusErr != nil {
+		logger.Error(statusErr, "Failed to update sync status")
+	}
+	return res, err
+}
+
+// move deletes the saved object from the space and target instance recorded in status.deployedTo if spec.space or
+// spec.targetInstance changed since, so it is not orphaned there. Unless spec.deletionPolicy is Delete the saved
+// object is left in place, the webhook rejects such changes then.
+func (r *SavedObjectReconciler) move(ctx context.Context, obj kibanaeckv1alpha1.SavedObjectResource, namespace string) error {
+	deployedTo := obj.GetDeployedTo()
+	if deployedTo == nil || equality.Semantic.DeepEqual(*deployedTo, kibanaeckv1alpha1.LocationOf(obj)) {
+		return nil
+	}
+	if obj.GetSavedObjectSpec().DeletionPolicy != kibanaeckv1alpha1.DeletionPolicyDelete {
+		r.Recorder.Event(obj, "Warning", "Orphaned",
+			fmt.Sprintf("The saved object %s is left in its previous space or target instance, set spec.deletionPolicy to Delete to move it", obj.GetName()))
+		return nil
+	}
+
+	previous := obj.DeepCopyObject().(kibanaeckv1alpha1.SavedObjectResource)
+	previous.SetLocation(*deployedTo)
+	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, obj, r.ProjectConfig.Load().Kibana, deployedTo.TargetInstance, namespace)
+	if apierrors.IsNotFound(err) {
+		// the saved object went away with its previous target instance
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	targetInstanceNamespace := namespace
+	if deployedTo.TargetInstance.KibanaInstanceNamespace != "" {
+		targetInstanceNamespace = deployedTo.TargetInstance.KibanaInstanceNamespace
+	}
+	kibanaClient := kibanaUtils.Client{
+		Cli:             r.Client,
+		Ctx:             ctx,
+		KibanaSpec:      *targetInstance,
+		KibanaNamespace: targetInstanceNamespace,
+		Req:             ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)},
+		Elasticsearch:   r.ProjectConfig.Load().Elasticsearch,
+	}
+	if _, err := r.delete(kibanaClient, previous); err != nil {
+		return err
+	}
+	r.Recorder.Event(obj, "Normal", "Moved",
+		fmt.Sprintf("Deleted the saved object %s from its previous space or target instance", obj.GetName()))
+	return nil
+}
+
+// backup saves the attributes of the saved object in Kibana to the sink of spec.backup before it is overwritten, there
+// is nothing to back up if it does not exist yet
+func (r *SavedObjectReconciler) backup(ctx context.Context, kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource, backup kibanaeckv1alpha1.SavedObjectBackup) error {
+	attributes, err := r.attributes(kClient, obj)
+	if err != nil || attributes == nil {
+		return err
+	}
+	taken, err := kibanaUtils.BackupSavedObject(r.Client, ctx, obj, backup, *attributes, time.Now())
+	if err != nil {
+		return err
+	}
+	if taken {
+		r.Recorder.Event(obj, "Normal", "BackedUp",
+			fmt.Sprintf("Backed up the saved object %s before overwriting it", obj.GetName()))
+	}
+	return nil
+}
+
+// share updates the spaces the saved object is shared to according to spec.shareToSpaces and records them in
+// status.sharedToSpaces. A failure is reported like a failed upsert.
+func (r *SavedObjectReconciler) share(kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource, savedObject kibanaeckv1alpha1.SavedObject) error {
+	shared, err := kibanaUtils.ShareSavedObject(kClient, r.Kind.Type, kibanaeckv1alpha1.SavedObjectIDOf(obj), savedObject, obj.GetSharedToSpaces())
+	if err != nil {
+		err = fmt.Errorf("failed to share the saved object to spaces: %w", err)
+	} else if !slices.Equal(shared, obj.GetSharedToSpaces()) {
+		r.Recorder.Event(obj, "Normal", "Shared",
+			fmt.Sprintf("Shared the saved object %s to the spaces [%s]", obj.GetName(), strings.Join(shared, ",")))
+	}
+	obj.SetSharedToSpaces(shared)
+	return err
+}
+
+func (r *SavedObjectReconciler) upsert(kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource, savedObject kibanaeckv1alpha1.SavedObject, version *string) (ctrl.Result, error) {
+	if r.Kind.Upsert != nil {
+		return r.Kind.Upsert(kClient, obj, savedObject, version)
+	}
+	return kibanaUtils.UpsertSavedObject(kClient, r.Kind.Type, objectMeta(obj), savedObject, version)
+}
+
+func (r *SavedObjectReconciler) delete(kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource) (ctrl.Result, error) {
+	if r.Kind.Delete != nil {
+		return r.Kind.Delete(kClient, obj)
+	}
+	return kibanaUtils.DeleteSavedObject(kClient, r.Kind.Type, objectMeta(obj), obj.GetSavedObjectSpec())
+}
+
+func (r *SavedObjectReconciler) attributes(kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource) (*string, error) {
+	if r.Kind.Attributes != nil {
+		return r.Kind.Attributes(kClient, obj)
+	}
+	return kibanaUtils.GetSavedObjectAttributes(kClient, r.Kind.Type, kibanaeckv1alpha1.SavedObjectIDOf(obj), obj.GetSavedObjectSpec().Space)
+}
+
+func setExternallyModified(conditions *[]metav1.Condition, generation int64, message string) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ExternallyModifiedConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             ExternallyModifiedReason,
+		Message:            message,
+	})
+}
+
+// objectMeta returns the metadata of obj the saved object utils use
+func objectMeta(obj client.Object) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:              obj.GetName(),
+		Namespace:         obj.GetNamespace(),
+		Labels:            obj.GetLabels(),
+		Annotations:       obj.GetAnnotations(),
+		CreationTimestamp: obj.GetCreationTimestamp(),
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SavedObjectReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(r.Kind.New(), r.Kind.Priority)
+	metrics := utils.NewReconcileMetrics(mgr, r.Kind.New())
+	syncWave := utils.NewSyncWave(mgr, r.Kind.New(), r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, r.Kind.New(), r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, r.Kind.New(), r.Recorder)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(r.Kind.New()).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
+		WithEventFilter(utils.CommonEventFilter()).
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
+}
@@ -20,9 +20,10 @@ import (
 	"context"
 	"fmt"
 
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
 	kibanaUtils "eck-custom-resources/utils/kibana"
+	"eck-custom-resources/utils/overlay"
 
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -39,7 +40,7 @@ import (
 type SpaceReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 }
 
@@ -57,13 +58,13 @@ func (r *SpaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, &space, r.ProjectConfig.Kibana, space.Spec.TargetConfig, req.Namespace)
+	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, &space, r.ProjectConfig.Load().Kibana, space.Spec.TargetConfig, req.Namespace)
 	if err != nil {
 		return utils.GetRequeueResult(), err
 	}
+	ctx, logger = utils.WithTargetInstance(ctx, targetInstance.Url)
 
-	if !targetInstance.Enabled {
-		logger.Info("Kibana reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
+	if !utils.CheckReconciliationEnabled(ctx, r.Client, r.Recorder, &space, &space.Status.Conditions, targetInstance.Enabled) {
 		return ctrl.Result{}, nil
 	}
 	targetInstanceNamespace := req.Namespace
@@ -80,13 +81,41 @@ func (r *SpaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		Req:             req,
 	}
 
+	if available, res := kibanaUtils.CheckTargetAvailable(kibanaClient, r.Recorder, &space, &space.Status.Conditions); !available {
+		return res, nil
+	}
+
 	if space.DeletionTimestamp.IsZero() {
+		if protectedErr := kibanaUtils.VerifySpaceNotProtected(req.Name); protectedErr != nil {
+			r.Recorder.Event(&space, "Warning", "Protected space", protectedErr.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &space, space.Spec, &space.Status.Conditions, &space.Status.ObservedGeneration, protectedErr); statusErr != nil {
+				logger.Error(statusErr, "Failed to update Space sync status")
+			}
+			return ctrl.Result{}, nil
+		}
+
 		logger.Info("Creating/Updating kibana space", "id", req.Name)
-		res, err := kibanaUtils.UpsertSpace(kibanaClient, space)
+		body, err := overlay.Apply(r.Client, ctx, &space, space.Spec.GetBody())
+		if err != nil {
+			r.Recorder.Event(&space, "Warning", "OverlayError",
+				fmt.Sprintf("Failed to apply EnvironmentOverlay: %s", err.Error()))
+			return utils.GetRequeueResult(), err
+		}
+		if err := utils.LintBody(&space, body); err != nil {
+			r.Recorder.Event(&space, "Warning", utils.PolicyViolationReason, err.Error())
+			if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &space, space.Spec, &space.Status.Conditions, &space.Status.ObservedGeneration, err); statusErr != nil {
+				logger.Error(statusErr, "Failed to update Space sync status")
+			}
+			// The spec or the lint rules have to be changed, retrying does not help
+			return ctrl.Result{}, nil
+		}
+		patched := space.DeepCopy()
+		patched.Spec.Body = body
+		res, err := kibanaUtils.UpsertSpace(kibanaClient, *patched)
 
 		if err == nil {
 			r.Recorder.Event(&space, "Normal", "Created",
-				fmt.Sprintf("Created/Updated %s/%s %s", space.APIVersion, space.Kind, space.Name))
+				fmt.Sprintf("Created/Updated %s/%s %s%s", space.APIVersion, space.Kind, space.Name, utils.AppliedBodyChanges(ctx, &space, targetInstance.Url, body)))
 		} else {
 			r.Recorder.Event(&space, "Warning", "Failed to create/update",
 				fmt.Sprintf("Failed to create/update %s/%s %s: %s", space.APIVersion, space.Kind, space.Name, err.Error()))
@@ -98,15 +127,27 @@ func (r *SpaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 				return ctrl.Result{}, err
 			}
 		}
+
+		// Copied after adding the finalizer, as updating the space replaces its status with the one of the API server
+		if err == nil && space.Spec.Template != nil && !space.Status.TemplateCopied {
+			res, err = r.copyTemplate(kibanaClient, &space)
+		}
+
+		if statusErr := utils.UpdateSyncStatus(r.Client, ctx, &space, space.Spec, &space.Status.Conditions, &space.Status.ObservedGeneration, err); statusErr != nil {
+			logger.Error(statusErr, "Failed to update Space sync status")
+		}
 		return res, err
 	} else {
 		// The object is being deleted
 		if controllerutil.ContainsFinalizer(&space, spaceFinalizer) {
-			if _, err := kibanaUtils.DeleteSpace(kibanaClient, space.Name); err != nil {
+			if kibanaUtils.VerifySpaceNotProtected(space.Name) != nil {
+				logger.Info("Not deleting the default space", "space", space.Name)
+			} else if _, err := kibanaUtils.DeleteSpace(kibanaClient, space.Name); err != nil {
 				return ctrl.Result{}, err
 			}
 
 			controllerutil.RemoveFinalizer(&space, spaceFinalizer)
+			utils.ForgetAppliedBody(&space)
 			if err := r.Update(ctx, &space); err != nil {
 				return ctrl.Result{}, err
 			}
@@ -116,10 +157,41 @@ func (r *SpaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	}
 }
 
+// copyTemplate copies the saved objects selected by spec.template into the space. Once all selected resources were
+// deployed and copied status.templateCopied is set, so later changes of the space do not overwrite the copies.
+func (r *SpaceReconciler) copyTemplate(kibanaClient kibanaUtils.Client, space *kibanaeckv1alpha1.Space) (ctrl.Result, error) {
+	objects, complete, err := kibanaUtils.GetSpaceTemplateObjects(kibanaClient, *space)
+	if err == nil && !complete {
+		r.Recorder.Event(space, "Normal", "TemplatePending",
+			"Waiting for the resources selected by spec.template to be deployed before copying them into the space")
+		return utils.GetRequeueResult(), nil
+	}
+	if err == nil {
+		err = kibanaUtils.CopySpaceTemplateObjects(kibanaClient, objects, space.Name)
+	}
+	if err != nil {
+		r.Recorder.Event(space, "Warning", "TemplateCopyFailed",
+			fmt.Sprintf("Failed to copy the saved objects of spec.template into space %s: %s", space.Name, err.Error()))
+		return utils.GetRequeueResult(), err
+	}
+	r.Recorder.Event(space, "Normal", "TemplateCopied",
+		fmt.Sprintf("Copied %d saved objects of spec.template into space %s", len(objects), space.Name))
+	space.Status.TemplateCopied = true
+	return ctrl.Result{}, nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *SpaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	priority := utils.NewStartupPriority(&kibanaeckv1alpha1.Space{}, utils.PriorityNormal)
+	metrics := utils.NewReconcileMetrics(mgr, &kibanaeckv1alpha1.Space{})
+	syncWave := utils.NewSyncWave(mgr, &kibanaeckv1alpha1.Space{}, r.Recorder)
+	retryBudget := utils.NewRetryBudget(mgr, &kibanaeckv1alpha1.Space{}, r.Recorder)
+	ownership := utils.NewOperatorOwnership(mgr, &kibanaeckv1alpha1.Space{}, r.Recorder)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kibanaeckv1alpha1.Space{}).
+		WithOptions(metrics.Options()).
+		WithEventFilter(syncWave.Filter()).
 		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+		WithEventFilter(priority.Filter()).
+		Complete(metrics.Reconciler(retryBudget.Reconciler(mgr.GetClient(), ownership.Reconciler(mgr.GetClient(), priority.Reconciler(mgr.GetClient(), syncWave.Reconciler(mgr.GetClient(), r))))))
 }
@@ -32,6 +32,8 @@ import (
 
 	v2 "eck-custom-resources/api/config/v2"
 	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/internal/config"
+	"eck-custom-resources/testutils"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -42,6 +44,7 @@ var cfg *rest.Config
 var k8sClient client.Client
 var k8sManager ctrl.Manager
 var testEnv *envtest.Environment
+var fakeKibana *testutils.FakeKibana
 
 func TestAPIs(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -77,10 +80,13 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(k8sClient).NotTo(BeNil())
 
+	fakeKibana = testutils.NewFakeKibana()
+	projectConfig := config.NewStore(v2.ProjectConfigSpec{Kibana: v2.KibanaSpec{Enabled: true, Url: fakeKibana.URL()}})
+
 	err = (&DashboardReconciler{
 		Client:        k8sManager.GetClient(),
 		Scheme:        scheme.Scheme,
-		ProjectConfig: v2.ProjectConfigSpec{},
+		ProjectConfig: projectConfig,
 		Recorder:      k8sManager.GetEventRecorderFor("dashboard"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
@@ -88,7 +94,7 @@ var _ = BeforeSuite(func() {
 	err = (&DataViewReconciler{
 		Client:        k8sManager.GetClient(),
 		Scheme:        scheme.Scheme,
-		ProjectConfig: v2.ProjectConfigSpec{},
+		ProjectConfig: projectConfig,
 		Recorder:      k8sManager.GetEventRecorderFor("data-view"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
@@ -96,7 +102,7 @@ var _ = BeforeSuite(func() {
 	err = (&IndexPatternReconciler{
 		Client:        k8sManager.GetClient(),
 		Scheme:        scheme.Scheme,
-		ProjectConfig: v2.ProjectConfigSpec{},
+		ProjectConfig: projectConfig,
 		Recorder:      k8sManager.GetEventRecorderFor("index-pattern"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
@@ -104,7 +110,7 @@ var _ = BeforeSuite(func() {
 	err = (&LensReconciler{
 		Client:        k8sManager.GetClient(),
 		Scheme:        scheme.Scheme,
-		ProjectConfig: v2.ProjectConfigSpec{},
+		ProjectConfig: projectConfig,
 		Recorder:      k8sManager.GetEventRecorderFor("lens"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
@@ -112,7 +118,7 @@ var _ = BeforeSuite(func() {
 	err = (&SavedSearchReconciler{
 		Client:        k8sManager.GetClient(),
 		Scheme:        scheme.Scheme,
-		ProjectConfig: v2.ProjectConfigSpec{},
+		ProjectConfig: projectConfig,
 		Recorder:      k8sManager.GetEventRecorderFor("saved-search"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
@@ -120,7 +126,7 @@ var _ = BeforeSuite(func() {
 	err = (&SpaceReconciler{
 		Client:        k8sManager.GetClient(),
 		Scheme:        scheme.Scheme,
-		ProjectConfig: v2.ProjectConfigSpec{},
+		ProjectConfig: projectConfig,
 		Recorder:      k8sManager.GetEventRecorderFor("space"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
@@ -128,15 +134,23 @@ var _ = BeforeSuite(func() {
 	err = (&VisualizationReconciler{
 		Client:        k8sManager.GetClient(),
 		Scheme:        scheme.Scheme,
-		ProjectConfig: v2.ProjectConfigSpec{},
+		ProjectConfig: projectConfig,
 		Recorder:      k8sManager.GetEventRecorderFor("visualization"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	go func() {
+		defer GinkgoRecover()
+		err := k8sManager.Start(ctrl.SetupSignalHandler())
+		Expect(err).ToNot(HaveOccurred())
+	}()
 })
 
 var _ = AfterSuite(func() {
 	By("tearing down the test environment")
+	if fakeKibana != nil {
+		fakeKibana.Close()
+	}
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
 })
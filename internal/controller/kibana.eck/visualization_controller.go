@@ -17,20 +17,13 @@ limitations under the License.
 package kibanaeck
 
 import (
-	"context"
-	"fmt"
-
-	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/internal/config"
 	"eck-custom-resources/utils"
-	kibanaUtils "eck-custom-resources/utils/kibana"
-
-	"k8s.io/client-go/tools/record"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
 )
@@ -39,7 +32,7 @@ import (
 type VisualizationReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
-	ProjectConfig configv2.ProjectConfigSpec
+	ProjectConfig *config.Store
 	Recorder      record.EventRecorder
 }
 
@@ -47,87 +40,20 @@ type VisualizationReconciler struct {
 //+kubebuilder:rbac:groups=kibana.eck.github.com,resources=visualizations/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kibana.eck.github.com,resources=visualizations/finalizers,verbs=update
 
-func (r *VisualizationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := log.FromContext(ctx)
-
-	visualizationFinalizer := "visualizations.kibana.eck.github.com/finalizer"
-	savedObjectType := "visualization"
-
-	var visualization kibanaeckv1alpha1.Visualization
-	if err := r.Get(ctx, req.NamespacedName, &visualization); err != nil {
-		return ctrl.Result{}, client.IgnoreNotFound(err)
-	}
-
-	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, r.Recorder, &visualization, r.ProjectConfig.Kibana, visualization.Spec.TargetConfig, req.Namespace)
-	if err != nil {
-		return utils.GetRequeueResult(), err
-	}
-
-	if !targetInstance.Enabled {
-		logger.Info("Kibana reconciler disabled, not reconciling.", "Resource", req.NamespacedName)
-		return ctrl.Result{}, nil
-	}
-
-	targetInstanceNamespace := req.Namespace
-	if visualization.Spec.TargetConfig.KibanaInstanceNamespace != "" {
-		targetInstanceNamespace = visualization.Spec.TargetConfig.KibanaInstanceNamespace
-	}
-
-	// Get the ElasticsearchInstance defined in target (if present and pass to the kibanaUtils.Client)
-	kibanaClient := kibanaUtils.Client{
-		Cli:             r.Client,
-		Ctx:             ctx,
-		KibanaSpec:      *targetInstance,
-		KibanaNamespace: targetInstanceNamespace,
-		Req:             req,
-	}
-
-	if visualization.DeletionTimestamp.IsZero() {
-		if err := kibanaUtils.DependenciesFulfilled(kibanaClient, visualization.Spec.GetSavedObject()); err != nil {
-			r.Recorder.Event(&visualization, "Warning", "Missing dependencies",
-				fmt.Sprintf("Some of declared dependencies are not present yet: %s", err.Error()))
-			return utils.GetRequeueResult(), err
-		}
-
-		logger.Info("Creating/Updating visualization", "id", req.Name)
-		res, err := kibanaUtils.UpsertSavedObject(kibanaClient, savedObjectType, visualization.ObjectMeta, visualization.Spec.GetSavedObject())
-
-		if err == nil {
-			r.Recorder.Event(&visualization, "Normal", "Created",
-				fmt.Sprintf("Created/Updated %s/%s %s", visualization.APIVersion, visualization.Kind, visualization.Name))
-		} else {
-			r.Recorder.Event(&visualization, "Warning", "Failed to create/update",
-				fmt.Sprintf("Failed to create/update %s/%s %s: %s", visualization.APIVersion, visualization.Kind, visualization.Name, err.Error()))
-		}
-
-		if !controllerutil.ContainsFinalizer(&visualization, visualizationFinalizer) {
-			controllerutil.AddFinalizer(&visualization, visualizationFinalizer)
-			if err := r.Update(ctx, &visualization); err != nil {
-				return ctrl.Result{}, err
-			}
-		}
-		return res, err
-	} else {
-		// The object is being deleted
-		if controllerutil.ContainsFinalizer(&visualization, visualizationFinalizer) {
-			if _, err := kibanaUtils.DeleteSavedObject(kibanaClient, savedObjectType, visualization.ObjectMeta, visualization.Spec.GetSavedObject()); err != nil {
-				return ctrl.Result{}, err
-			}
-
-			controllerutil.RemoveFinalizer(&visualization, visualizationFinalizer)
-			if err := r.Update(ctx, &visualization); err != nil {
-				return ctrl.Result{}, err
-			}
-		}
-
-		return ctrl.Result{}, nil
-	}
+var visualizationKind = SavedObjectKind{
+	New:       func() kibanaeckv1alpha1.SavedObjectResource { return &kibanaeckv1alpha1.Visualization{} },
+	Type:      "visualization",
+	Finalizer: "visualizations.kibana.eck.github.com/finalizer",
+	Priority:  utils.PriorityLow,
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *VisualizationReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&kibanaeckv1alpha1.Visualization{}).
-		WithEventFilter(utils.CommonEventFilter()).
-		Complete(r)
+	return (&SavedObjectReconciler{
+		Client:        r.Client,
+		Scheme:        r.Scheme,
+		ProjectConfig: r.ProjectConfig,
+		Recorder:      r.Recorder,
+		Kind:          visualizationKind,
+	}).SetupWithManager(mgr)
 }
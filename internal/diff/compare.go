@@ -0,0 +1,121 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Change is a value of the rendered body which differs from the live object
+type Change struct {
+	// Path of the value in the body, e.g. template.settings.index.number_of_shards
+	Path string `json:"path"`
+	// Desired value rendered from the resource
+	Desired any `json:"desired"`
+	// Live value in the cluster, omitted if it is missing
+	Live any `json:"live,omitempty"`
+}
+
+// Compare returns the values of desired which differ from live. Fields only present in live are ignored, as
+// Elasticsearch and Kibana add defaults and metadata to the objects they store. settings objects are compared
+// flattened with the index. prefix, and scalars by their text, as Elasticsearch returns settings as strings.
+func Compare(desired any, live any) []Change {
+	var changes []Change
+	compare("", normalizeSettings(desired), normalizeSettings(live), &changes)
+	return changes
+}
+
+func compare(path string, desired any, live any, changes *[]Change) {
+	switch desiredValue := desired.(type) {
+	case map[string]any:
+		liveMap, ok := live.(map[string]any)
+		if !ok {
+			*changes = append(*changes, Change{Path: path, Desired: desired, Live: live})
+			return
+		}
+		for _, key := range slices.Sorted(maps.Keys(desiredValue)) {
+			liveValue, exists := liveMap[key]
+			if !exists {
+				*changes = append(*changes, Change{Path: joinPath(path, key), Desired: desiredValue[key]})
+				continue
+			}
+			compare(joinPath(path, key), desiredValue[key], liveValue, changes)
+		}
+	case []any:
+		liveSlice, ok := live.([]any)
+		if !ok || len(liveSlice) != len(desiredValue) {
+			*changes = append(*changes, Change{Path: path, Desired: desired, Live: live})
+			return
+		}
+		for i := range desiredValue {
+			compare(fmt.Sprintf("%s[%d]", path, i), desiredValue[i], liveSlice[i], changes)
+		}
+	default:
+		if scalarText(desired) != scalarText(live) {
+			*changes = append(*changes, Change{Path: path, Desired: desired, Live: live})
+		}
+	}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// scalarText returns the value as text, strings without quotes
+func scalarText(value any) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	marshalled, _ := json.Marshal(value)
+	return string(marshalled)
+}
+
+// normalizeSettings flattens the settings objects found in value into dotted keys prefixed with index., the form
+// the get settings APIs return with flat_settings
+func normalizeSettings(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		normalized := make(map[string]any, len(typed))
+		for key, child := range typed {
+			if settings, ok := child.(map[string]any); ok && key == "settings" {
+				flat := make(map[string]any)
+				flattenSettings("", settings, flat)
+				prefixed := make(map[string]any, len(flat))
+				for setting, settingValue := range flat {
+					if !strings.HasPrefix(setting, "index.") {
+						setting = "index." + setting
+					}
+					prefixed[setting] = settingValue
+				}
+				normalized[key] = prefixed
+				continue
+			}
+			normalized[key] = normalizeSettings(child)
+		}
+		return normalized
+	case []any:
+		normalized := make([]any, len(typed))
+		for i, child := range typed {
+			normalized[i] = normalizeSettings(child)
+		}
+		return normalized
+	default:
+		return value
+	}
+}
+
+func flattenSettings(prefix string, settings map[string]any, flat map[string]any) {
+	for key, value := range settings {
+		key = joinPath(prefix, key)
+		if nested, ok := value.(map[string]any); ok {
+			flattenSettings(key, nested, flat)
+			continue
+		}
+		flat[key] = value
+	}
+}
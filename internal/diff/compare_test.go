@@ -0,0 +1,57 @@
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name    string
+		desired string
+		live    string
+		want    []Change
+	}{
+		{
+			name:    "live defaults are ignored",
+			desired: `{"description": "logs", "processors": [{"set": {"field": "a", "value": 1}}]}`,
+			live:    `{"description": "logs", "processors": [{"set": {"field": "a", "value": 1, "ignore_failure": false}}], "version": 3}`,
+		},
+		{
+			name:    "settings are compared flattened and as text",
+			desired: `{"template": {"settings": {"number_of_shards": 1, "index": {"refresh_interval": "5s"}}}}`,
+			live:    `{"template": {"settings": {"index": {"number_of_shards": "1", "refresh_interval": "5s"}}}}`,
+		},
+		{
+			name:    "changed and missing values",
+			desired: `{"template": {"settings": {"number_of_replicas": 2}, "mappings": {"dynamic": false}}}`,
+			live:    `{"template": {"settings": {"index.number_of_replicas": "1"}}}`,
+			want: []Change{
+				{Path: "template.mappings", Desired: map[string]any{"dynamic": false}},
+				{Path: "template.settings.index.number_of_replicas", Desired: float64(2), Live: "1"},
+			},
+		},
+		{
+			name:    "arrays of another length",
+			desired: `{"index_patterns": ["logs-*", "app-*"]}`,
+			live:    `{"index_patterns": ["logs-*"]}`,
+			want:    []Change{{Path: "index_patterns", Desired: []any{"logs-*", "app-*"}, Live: []any{"logs-*"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var desired, live any
+			if err := json.Unmarshal([]byte(tt.desired), &desired); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.live), &live); err != nil {
+				t.Fatal(err)
+			}
+			if got := Compare(desired, live); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compare() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package diff
+
+import (
+	"context"
+	"encoding/json"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
+	"eck-custom-resources/utils/overlay"
+	"eck-custom-resources/utils/template"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// templateTarget returns the .Target builtins of a body templated with the template spec
+type templateTarget func(templateSpec eseckv1alpha1.CommonTemplatingSpec) template.Target
+
+// elasticsearchKind is a kind of resource deployed to Elasticsearch with a body
+type elasticsearchKind struct {
+	Kind string
+	// NewList returns an empty list of the kind
+	NewList func() client.ObjectList
+	// TargetConfig returns spec.targetInstance of the resource
+	TargetConfig func(obj client.Object) eseckv1alpha1.CommonElasticsearchConfig
+	// Render returns the body the controller puts, target describes the instance for the .Target builtins
+	Render func(ctx context.Context, r *Reporter, obj client.Object, target templateTarget) (string, error)
+	// Live returns the object in Elasticsearch in the shape of the body, nil if it does not exist
+	Live func(esClient *elasticsearch.Client, name string) (any, error)
+	// RemoteName returns the name of the object in Elasticsearch. Defaults to utils.RemoteName.
+	RemoteName func(obj client.Object) string
+}
+
+var elasticsearchKinds = []elasticsearchKind{
+	{
+		Kind:    "IndexTemplate",
+		NewList: func() client.ObjectList { return &eseckv1alpha1.IndexTemplateList{} },
+		TargetConfig: func(obj client.Object) eseckv1alpha1.CommonElasticsearchConfig {
+			return obj.(*eseckv1alpha1.IndexTemplate).Spec.TargetConfig
+		},
+		Render: func(ctx context.Context, r *Reporter, obj client.Object, _ templateTarget) (string, error) {
+			indexTemplate := obj.(*eseckv1alpha1.IndexTemplate)
+			body, err := overlay.Apply(r.Client, ctx, indexTemplate, indexTemplate.Spec.GetBody())
+			if err != nil || indexTemplate.Spec.ILMPolicyRef == nil {
+				return body, err
+			}
+			return esutils.InjectIndexLifecyclePolicy(body, indexTemplate.Spec.ILMPolicyRef.Name, "template", "settings")
+		},
+		Live: func(esClient *elasticsearch.Client, name string) (any, error) {
+			var response struct {
+				IndexTemplates []struct {
+					IndexTemplate any `json:"index_template"`
+				} `json:"index_templates"`
+			}
+			found, err := getJSON(esClient.Indices.GetIndexTemplate(esClient.Indices.GetIndexTemplate.WithName(name)))(&response)
+			if !found || err != nil || len(response.IndexTemplates) == 0 {
+				return nil, err
+			}
+			return response.IndexTemplates[0].IndexTemplate, nil
+		},
+	},
+	{
+		Kind:    "ComponentTemplate",
+		NewList: func() client.ObjectList { return &eseckv1alpha1.ComponentTemplateList{} },
+		TargetConfig: func(obj client.Object) eseckv1alpha1.CommonElasticsearchConfig {
+			return obj.(*eseckv1alpha1.ComponentTemplate).Spec.TargetConfig
+		},
+		Render: func(ctx context.Context, r *Reporter, obj client.Object, _ templateTarget) (string, error) {
+			componentTemplate := obj.(*eseckv1alpha1.ComponentTemplate)
+			return overlay.Apply(r.Client, ctx, componentTemplate, componentTemplate.Spec.GetBody())
+		},
+		Live: func(esClient *elasticsearch.Client, name string) (any, error) {
+			var response struct {
+				ComponentTemplates []struct {
+					ComponentTemplate any `json:"component_template"`
+				} `json:"component_templates"`
+			}
+			found, err := getJSON(esClient.Cluster.GetComponentTemplate(esClient.Cluster.GetComponentTemplate.WithName(name)))(&response)
+			if !found || err != nil || len(response.ComponentTemplates) == 0 {
+				return nil, err
+			}
+			return response.ComponentTemplates[0].ComponentTemplate, nil
+		},
+	},
+	{
+		Kind:    "IndexLifecyclePolicy",
+		NewList: func() client.ObjectList { return &eseckv1alpha1.IndexLifecyclePolicyList{} },
+		TargetConfig: func(obj client.Object) eseckv1alpha1.CommonElasticsearchConfig {
+			return obj.(*eseckv1alpha1.IndexLifecyclePolicy).Spec.TargetConfig
+		},
+		Render: func(ctx context.Context, r *Reporter, obj client.Object, _ templateTarget) (string, error) {
+			indexLifecyclePolicy := obj.(*eseckv1alpha1.IndexLifecyclePolicy)
+			return overlay.Apply(r.Client, ctx, indexLifecyclePolicy, indexLifecyclePolicy.Spec.GetBody())
+		},
+		// the body is {"policy": ...}, as the policy is returned next to its version
+		Live: func(esClient *elasticsearch.Client, name string) (any, error) {
+			return keyedByName(esClient.ILM.GetLifecycle(esClient.ILM.GetLifecycle.WithPolicy(name)))(name)
+		},
+	},
+	{
+		Kind:    "IngestPipeline",
+		NewList: func() client.ObjectList { return &eseckv1alpha1.IngestPipelineList{} },
+		TargetConfig: func(obj client.Object) eseckv1alpha1.CommonElasticsearchConfig {
+			return obj.(*eseckv1alpha1.IngestPipeline).Spec.TargetConfig
+		},
+		Render: func(ctx context.Context, r *Reporter, obj client.Object, target templateTarget) (string, error) {
+			ingestPipeline := obj.(*eseckv1alpha1.IngestPipeline)
+			body, err := template.FetchAndRenderTemplate(r.Client, ctx, ingestPipeline.Spec.Template, ingestPipeline.Spec.GetBody(),
+				template.Builtins{
+					Namespace:      ingestPipeline.Namespace,
+					Name:           ingestPipeline.Name,
+					TargetInstance: ingestPipeline.Spec.TargetConfig.ElasticsearchInstance,
+					Target:         target(ingestPipeline.Spec.Template),
+				}, r.RestConfig)
+			if err != nil {
+				return "", err
+			}
+			return overlay.Apply(r.Client, ctx, ingestPipeline, body)
+		},
+		Live: func(esClient *elasticsearch.Client, name string) (any, error) {
+			return keyedByName(esClient.Ingest.GetPipeline(esClient.Ingest.GetPipeline.WithPipelineID(name)))(name)
+		},
+	},
+	{
+		Kind:    "SnapshotLifecyclePolicy",
+		NewList: func() client.ObjectList { return &eseckv1alpha1.SnapshotLifecyclePolicyList{} },
+		TargetConfig: func(obj client.Object) eseckv1alpha1.CommonElasticsearchConfig {
+			return obj.(*eseckv1alpha1.SnapshotLifecyclePolicy).Spec.TargetConfig
+		},
+		Render: func(ctx context.Context, r *Reporter, obj client.Object, _ templateTarget) (string, error) {
+			snapshotLifecyclePolicy := obj.(*eseckv1alpha1.SnapshotLifecyclePolicy)
+			return overlay.Apply(r.Client, ctx, snapshotLifecyclePolicy, snapshotLifecyclePolicy.Spec.GetBody())
+		},
+		Live: func(esClient *elasticsearch.Client, name string) (any, error) {
+			policy, err := keyedByName(esClient.SlmGetLifecycle(esClient.SlmGetLifecycle.WithPolicyID(name)))(name)
+			if policy, ok := policy.(map[string]any); ok {
+				return policy["policy"], err
+			}
+			return policy, err
+		},
+		RemoteName: client.Object.GetName,
+	},
+	{
+		Kind:    "SnapshotRepository",
+		NewList: func() client.ObjectList { return &eseckv1alpha1.SnapshotRepositoryList{} },
+		TargetConfig: func(obj client.Object) eseckv1alpha1.CommonElasticsearchConfig {
+			return obj.(*eseckv1alpha1.SnapshotRepository).Spec.TargetConfig
+		},
+		Render: func(ctx context.Context, r *Reporter, obj client.Object, _ templateTarget) (string, error) {
+			snapshotRepository := obj.(*eseckv1alpha1.SnapshotRepository)
+			return overlay.Apply(r.Client, ctx, snapshotRepository, snapshotRepository.Spec.GetBody())
+		},
+		Live: func(esClient *elasticsearch.Client, name string) (any, error) {
+			return keyedByName(esClient.Snapshot.GetRepository(esClient.Snapshot.GetRepository.WithRepository(name)))(name)
+		},
+		RemoteName: client.Object.GetName,
+	},
+	{
+		Kind:    "QueryRuleset",
+		NewList: func() client.ObjectList { return &eseckv1alpha1.QueryRulesetList{} },
+		TargetConfig: func(obj client.Object) eseckv1alpha1.CommonElasticsearchConfig {
+			return obj.(*eseckv1alpha1.QueryRuleset).Spec.TargetConfig
+		},
+		Render: func(ctx context.Context, r *Reporter, obj client.Object, _ templateTarget) (string, error) {
+			queryRuleset := obj.(*eseckv1alpha1.QueryRuleset)
+			return overlay.Apply(r.Client, ctx, queryRuleset, queryRuleset.Spec.GetBody())
+		},
+		Live: func(esClient *elasticsearch.Client, name string) (any, error) {
+			var ruleset map[string]any
+			found, err := getJSON(esClient.QueryRulesGetRuleset(name))(&ruleset)
+			if !found || err != nil {
+				return nil, err
+			}
+			return ruleset, nil
+		},
+	},
+	{
+		Kind:    "SynonymSet",
+		NewList: func() client.ObjectList { return &eseckv1alpha1.SynonymSetList{} },
+		TargetConfig: func(obj client.Object) eseckv1alpha1.CommonElasticsearchConfig {
+			return obj.(*eseckv1alpha1.SynonymSet).Spec.TargetConfig
+		},
+		Render: func(ctx context.Context, r *Reporter, obj client.Object, _ templateTarget) (string, error) {
+			synonymSet := obj.(*eseckv1alpha1.SynonymSet)
+			return overlay.Apply(r.Client, ctx, synonymSet, synonymSet.Spec.GetBody())
+		},
+		// the rules are paged, 10000 is the most Elasticsearch returns at once
+		Live: func(esClient *elasticsearch.Client, name string) (any, error) {
+			var synonymSet map[string]any
+			found, err := getJSON(esClient.SynonymsGetSynonym(name, esClient.SynonymsGetSynonym.WithSize(10000)))(&synonymSet)
+			if !found || err != nil {
+				return nil, err
+			}
+			return synonymSet, nil
+		},
+	},
+	{
+		Kind:    "ElasticsearchRole",
+		NewList: func() client.ObjectList { return &eseckv1alpha1.ElasticsearchRoleList{} },
+		TargetConfig: func(obj client.Object) eseckv1alpha1.CommonElasticsearchConfig {
+			return obj.(*eseckv1alpha1.ElasticsearchRole).Spec.TargetConfig
+		},
+		Render: func(ctx context.Context, r *Reporter, obj client.Object, target templateTarget) (string, error) {
+			role := obj.(*eseckv1alpha1.ElasticsearchRole)
+			body, err := template.FetchAndRenderTemplate(r.Client, ctx, role.Spec.Template, role.Spec.GetBody(),
+				template.Builtins{
+					Namespace:      role.Namespace,
+					Name:           role.Name,
+					TargetInstance: role.Spec.TargetConfig.ElasticsearchInstance,
+					Target:         target(role.Spec.Template),
+				}, r.RestConfig)
+			if err != nil {
+				return "", err
+			}
+			if body, err = overlay.Apply(r.Client, ctx, role, body); err != nil {
+				return "", err
+			}
+			return esutils.InjectKibanaPrivileges(body, role.Spec.KibanaPrivileges)
+		},
+		Live: func(esClient *elasticsearch.Client, name string) (any, error) {
+			return keyedByName(esClient.Security.GetRole(esClient.Security.GetRole.WithName(name)))(name)
+		},
+	},
+}
+
+// remoteName returns the name of the object of the resource in Elasticsearch
+func (k elasticsearchKind) remoteName(obj client.Object) string {
+	if k.RemoteName != nil {
+		return k.RemoteName(obj)
+	}
+	return utils.RemoteName(obj)
+}
+
+// getJSON returns a function decoding the response into its argument. It reports false for a missing object.
+func getJSON(res *esapi.Response, err error) func(v any) (bool, error) {
+	return func(v any) (bool, error) {
+		if err != nil {
+			return false, err
+		}
+		defer res.Body.Close()
+		if res.StatusCode == 404 {
+			return false, nil
+		}
+		if res.IsError() {
+			return false, esutils.GetClientErrorOrResponseError(nil, res)
+		}
+		return true, json.NewDecoder(res.Body).Decode(v)
+	}
+}
+
+// keyedByName returns a function returning the object of the given name from a response keyed by the names of the
+// objects
+func keyedByName(res *esapi.Response, err error) func(name string) (any, error) {
+	return func(name string) (any, error) {
+		var response map[string]any
+		found, err := getJSON(res, err)(&response)
+		if !found || err != nil {
+			return nil, err
+		}
+		return response[name], nil
+	}
+}
@@ -0,0 +1,73 @@
+package diff
+
+import (
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	kibanaUtils "eck-custom-resources/utils/kibana"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// savedObjectKind is a kind of resource deployed to Kibana as a saved object
+type savedObjectKind struct {
+	Kind string
+	// NewList returns an empty list of the kind
+	NewList func() client.ObjectList
+	// Field of the body holding what Live returns, e.g. attributes
+	Field string
+	// Live returns the field of the saved object in Kibana as JSON, nil if it does not exist
+	Live func(kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource) (*string, error)
+}
+
+// savedObjectAttributes returns the Live function of the saved objects of the given type
+func savedObjectAttributes(savedObjectType string) func(kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource) (*string, error) {
+	return func(kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource) (*string, error) {
+		return kibanaUtils.GetSavedObjectAttributes(kClient, savedObjectType, kibanaeckv1alpha1.SavedObjectIDOf(obj), obj.GetSavedObjectSpec().Space)
+	}
+}
+
+var savedObjectKinds = []savedObjectKind{
+	{
+		Kind:    "Dashboard",
+		NewList: func() client.ObjectList { return &kibanaeckv1alpha1.DashboardList{} },
+		Field:   "attributes",
+		Live:    savedObjectAttributes("dashboard"),
+	},
+	{
+		Kind:    "Visualization",
+		NewList: func() client.ObjectList { return &kibanaeckv1alpha1.VisualizationList{} },
+		Field:   "attributes",
+		Live:    savedObjectAttributes("visualization"),
+	},
+	{
+		Kind:    "Lens",
+		NewList: func() client.ObjectList { return &kibanaeckv1alpha1.LensList{} },
+		Field:   "attributes",
+		Live:    savedObjectAttributes("lens"),
+	},
+	{
+		Kind:    "SavedSearch",
+		NewList: func() client.ObjectList { return &kibanaeckv1alpha1.SavedSearchList{} },
+		Field:   "attributes",
+		Live:    savedObjectAttributes("search"),
+	},
+	{
+		Kind:    "IndexPattern",
+		NewList: func() client.ObjectList { return &kibanaeckv1alpha1.IndexPatternList{} },
+		Field:   "attributes",
+		Live:    savedObjectAttributes("index-pattern"),
+	},
+	{
+		Kind:    "CanvasWorkpad",
+		NewList: func() client.ObjectList { return &kibanaeckv1alpha1.CanvasWorkpadList{} },
+		Field:   "attributes",
+		Live:    savedObjectAttributes(kibanaUtils.CanvasWorkpadSavedObjectType),
+	},
+	{
+		Kind:    "DataView",
+		NewList: func() client.ObjectList { return &kibanaeckv1alpha1.DataViewList{} },
+		Field:   "data_view",
+		Live: func(kClient kibanaUtils.Client, obj kibanaeckv1alpha1.SavedObjectResource) (*string, error) {
+			return kibanaUtils.GetDataViewAttributes(kClient, *obj.(*kibanaeckv1alpha1.DataView))
+		},
+	},
+}
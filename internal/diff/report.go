@@ -0,0 +1,261 @@
+// Package diff renders the bodies of the resources without applying them and reports how they differ from the
+// objects in Elasticsearch and Kibana, for reviewing what the operator would change before it is enabled.
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
+	kibanaUtils "eck-custom-resources/utils/kibana"
+	"eck-custom-resources/utils/overlay"
+	"eck-custom-resources/utils/template"
+
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Action the operator would take for a resource
+type Action string
+
+const (
+	// ActionCreate creates the object, it does not exist yet
+	ActionCreate Action = "Create"
+	// ActionUpdate changes the object, see the changes of the resource
+	ActionUpdate Action = "Update"
+	// ActionNone leaves the object as it is
+	ActionNone Action = "None"
+	// ActionError could not be determined, see the error of the resource
+	ActionError Action = "Error"
+)
+
+// ReportKey is the key of the ConfigMap the report is written to
+const ReportKey = "report.json"
+
+// Report lists the changes the operator would make to Elasticsearch and Kibana
+type Report struct {
+	GeneratedAt metav1.Time `json:"generatedAt"`
+	// Summary counts the resources by action
+	Summary map[Action]int `json:"summary"`
+	// Resources in the order of their kinds, namespaces and names
+	Resources []Resource `json:"resources"`
+}
+
+// Resource is the diff of a single resource
+type Resource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// RemoteName is the name or ID of the object in Elasticsearch or Kibana
+	RemoteName string `json:"remoteName"`
+	// Target is the URL of the target instance
+	Target  string   `json:"target,omitempty"`
+	Action  Action   `json:"action"`
+	Changes []Change `json:"changes,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update
+
+// Reporter builds the Report of the resources in Namespaces. It only reads from the API server and the target
+// instances.
+type Reporter struct {
+	Client        client.Client
+	RestConfig    *rest.Config
+	ProjectConfig configv2.ProjectConfigSpec
+	Namespaces    []string
+}
+
+// recorder drops the events of the target instance lookups, the report must not change the resources
+var recorder = &record.FakeRecorder{}
+
+// Run renders and diffs the resources of all supported kinds
+func (r *Reporter) Run(ctx context.Context) (*Report, error) {
+	report := &Report{GeneratedAt: metav1.Now(), Summary: map[Action]int{}}
+	for _, kind := range elasticsearchKinds {
+		err := r.forEach(ctx, kind.NewList, func(obj client.Object) {
+			report.add(r.diffElasticsearch(ctx, kind, obj))
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s resources: %w", kind.Kind, err)
+		}
+	}
+	for _, kind := range savedObjectKinds {
+		err := r.forEach(ctx, kind.NewList, func(obj client.Object) {
+			report.add(r.diffSavedObject(ctx, kind, obj.(kibanaeckv1alpha1.SavedObjectResource)))
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s resources: %w", kind.Kind, err)
+		}
+	}
+	return report, nil
+}
+
+func (report *Report) add(resource Resource) {
+	report.Resources = append(report.Resources, resource)
+	report.Summary[resource.Action]++
+}
+
+// forEach calls f for every resource of the list kind in the namespaces, skipping resources being deleted
+func (r *Reporter) forEach(ctx context.Context, newList func() client.ObjectList, f func(obj client.Object)) error {
+	for _, namespace := range r.Namespaces {
+		list := newList()
+		if err := utils.ListPaged(ctx, r.Client, list, client.InNamespace(namespace)); err != nil {
+			return err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if obj := item.(client.Object); obj.GetDeletionTimestamp().IsZero() {
+				f(obj)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Reporter) diffElasticsearch(ctx context.Context, kind elasticsearchKind, obj client.Object) Resource {
+	resource := Resource{Kind: kind.Kind, Namespace: obj.GetNamespace(), Name: obj.GetName(), RemoteName: kind.remoteName(obj)}
+	targetConfig := kind.TargetConfig(obj)
+	targetInstance, err := esutils.GetElasticsearchTargetInstance(r.Client, ctx, recorder, obj, r.ProjectConfig.Elasticsearch, targetConfig, obj.GetNamespace())
+	if err != nil {
+		return resource.failed(err)
+	}
+	resource.Target = targetInstance.Url
+
+	targetInstanceNamespace := obj.GetNamespace()
+	if targetConfig.ElasticsearchInstanceNamespace != "" {
+		targetInstanceNamespace = targetConfig.ElasticsearchInstanceNamespace
+	}
+	esClient, err := esutils.GetElasticsearchClient(r.Client, ctx, *targetInstance, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)}, targetInstanceNamespace)
+	if err != nil {
+		return resource.failed(err)
+	}
+
+	body, err := kind.Render(ctx, r, obj, func(templateSpec eseckv1alpha1.CommonTemplatingSpec) template.Target {
+		return esutils.TemplateTarget(esClient, templateSpec, targetConfig, *targetInstance, obj.GetNamespace())
+	})
+	if err != nil {
+		return resource.failed(err)
+	}
+	live, err := kind.Live(esClient, resource.RemoteName)
+	if err != nil {
+		return resource.failed(err)
+	}
+	return resource.compare(body, live)
+}
+
+func (r *Reporter) diffSavedObject(ctx context.Context, kind savedObjectKind, obj kibanaeckv1alpha1.SavedObjectResource) Resource {
+	resource := Resource{Kind: kind.Kind, Namespace: obj.GetNamespace(), Name: obj.GetName(), RemoteName: obj.GetName()}
+	targetConfig := obj.GetTargetConfig()
+	targetInstance, err := kibanaUtils.GetKibanaTargetInstance(r.Client, ctx, recorder, obj, r.ProjectConfig.Kibana, targetConfig, obj.GetNamespace())
+	if err != nil {
+		return resource.failed(err)
+	}
+	resource.Target = targetInstance.Url
+
+	targetInstanceNamespace := obj.GetNamespace()
+	if targetConfig.KibanaInstanceNamespace != "" {
+		targetInstanceNamespace = targetConfig.KibanaInstanceNamespace
+	}
+	kClient := kibanaUtils.Client{
+		Cli:             r.Client,
+		Ctx:             ctx,
+		KibanaSpec:      *targetInstance,
+		KibanaNamespace: targetInstanceNamespace,
+		Req:             ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)},
+		Elasticsearch:   r.ProjectConfig.Elasticsearch,
+	}
+
+	savedObject, err := kibanaUtils.ResolveBodyFrom(r.Client, ctx, obj.GetNamespace(), obj.GetSavedObjectSpec())
+	if err != nil {
+		return resource.failed(err)
+	}
+	body, err := overlay.Apply(r.Client, ctx, obj, savedObject.Body)
+	if err != nil {
+		return resource.failed(err)
+	}
+	var rendered map[string]any
+	if err := json.Unmarshal([]byte(body), &rendered); err != nil {
+		return resource.failed(fmt.Errorf("the body is not a JSON object: %w", err))
+	}
+	desired, err := json.Marshal(rendered[kind.Field])
+	if err != nil {
+		return resource.failed(err)
+	}
+
+	attributes, err := kind.Live(kClient, obj)
+	if err != nil {
+		return resource.failed(err)
+	}
+	if attributes == nil {
+		return resource.compare(string(desired), nil)
+	}
+	var live any
+	if err := json.Unmarshal([]byte(*attributes), &live); err != nil {
+		return resource.failed(err)
+	}
+	return resource.compare(string(desired), live)
+}
+
+// compare sets the action and changes of the resource from the rendered body and the live object, nil if it does
+// not exist
+func (resource Resource) compare(body string, live any) Resource {
+	var desired any
+	if err := json.Unmarshal([]byte(body), &desired); err != nil {
+		return resource.failed(fmt.Errorf("the body is not valid JSON: %w", err))
+	}
+	if live == nil {
+		resource.Action = ActionCreate
+		return resource
+	}
+	resource.Action = ActionNone
+	if resource.Changes = Compare(desired, live); len(resource.Changes) > 0 {
+		resource.Action = ActionUpdate
+	}
+	return resource
+}
+
+func (resource Resource) failed(err error) Resource {
+	resource.Action = ActionError
+	resource.Error = err.Error()
+	return resource
+}
+
+// Write writes the report as indented JSON
+func (report *Report) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// WriteConfigMap writes the report to the key report.json of the ConfigMap, which is created if it does not exist
+func (report *Report) WriteConfigMap(ctx context.Context, cli client.Client, key client.ObjectKey) error {
+	marshalled, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	configMap := &k8sv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	_, err = controllerutil.CreateOrUpdate(ctx, cli, configMap, func() error {
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[ReportKey] = string(marshalled)
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// bodyObject is a resource with a body given either as spec.body or as spec.bodyJson
+type bodyObject interface {
+	client.Object
+	BodyFields() (string, *apiextensionsv1.JSON)
+}
+
+// SetupBodyWebhooksWithManager registers the webhooks for the resources with a body which have no validator of
+// their own in the manager.
+func SetupBodyWebhooksWithManager(mgr ctrl.Manager) error {
+	for _, resource := range []struct {
+		kind string
+		obj  bodyObject
+	}{
+		{kind: "ComponentTemplate", obj: &eseckv1alpha1.ComponentTemplate{}},
+		{kind: "ElasticsearchRole", obj: &eseckv1alpha1.ElasticsearchRole{}},
+		{kind: "ElasticsearchUser", obj: &eseckv1alpha1.ElasticsearchUser{}},
+		{kind: "IndexLifecyclePolicy", obj: &eseckv1alpha1.IndexLifecyclePolicy{}},
+		{kind: "IndexTemplate", obj: &eseckv1alpha1.IndexTemplate{}},
+		{kind: "IngestPipeline", obj: &eseckv1alpha1.IngestPipeline{}},
+		{kind: "QueryRuleset", obj: &eseckv1alpha1.QueryRuleset{}},
+		{kind: "SnapshotLifecyclePolicy", obj: &eseckv1alpha1.SnapshotLifecyclePolicy{}},
+		{kind: "SnapshotRepository", obj: &eseckv1alpha1.SnapshotRepository{}},
+		{kind: "SynonymSet", obj: &eseckv1alpha1.SynonymSet{}},
+	} {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(resource.obj).
+			WithValidator(&BodyCustomValidator{Kind: resource.kind}).
+			Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-es-eck-github-com-v1alpha1-componenttemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=es.eck.github.com,resources=componenttemplates,verbs=create;update,versions=v1alpha1,name=vcomponenttemplate-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-es-eck-github-com-v1alpha1-elasticsearchrole,mutating=false,failurePolicy=fail,sideEffects=None,groups=es.eck.github.com,resources=elasticsearchroles,verbs=create;update,versions=v1alpha1,name=velasticsearchrole-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-es-eck-github-com-v1alpha1-elasticsearchuser,mutating=false,failurePolicy=fail,sideEffects=None,groups=es.eck.github.com,resources=elasticsearchusers,verbs=create;update,versions=v1alpha1,name=velasticsearchuser-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-es-eck-github-com-v1alpha1-indexlifecyclepolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=es.eck.github.com,resources=indexlifecyclepolicies,verbs=create;update,versions=v1alpha1,name=vindexlifecyclepolicy-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-es-eck-github-com-v1alpha1-indextemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=es.eck.github.com,resources=indextemplates,verbs=create;update,versions=v1alpha1,name=vindextemplate-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-es-eck-github-com-v1alpha1-ingestpipeline,mutating=false,failurePolicy=fail,sideEffects=None,groups=es.eck.github.com,resources=ingestpipelines,verbs=create;update,versions=v1alpha1,name=vingestpipeline-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-es-eck-github-com-v1alpha1-queryruleset,mutating=false,failurePolicy=fail,sideEffects=None,groups=es.eck.github.com,resources=queryrulesets,verbs=create;update,versions=v1alpha1,name=vqueryruleset-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-es-eck-github-com-v1alpha1-snapshotlifecyclepolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=es.eck.github.com,resources=snapshotlifecyclepolicies,verbs=create;update,versions=v1alpha1,name=vsnapshotlifecyclepolicy-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-es-eck-github-com-v1alpha1-snapshotrepository,mutating=false,failurePolicy=fail,sideEffects=None,groups=es.eck.github.com,resources=snapshotrepositories,verbs=create;update,versions=v1alpha1,name=vsnapshotrepository-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-es-eck-github-com-v1alpha1-synonymset,mutating=false,failurePolicy=fail,sideEffects=None,groups=es.eck.github.com,resources=synonymsets,verbs=create;update,versions=v1alpha1,name=vsynonymset-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// BodyCustomValidator rejects resources which set both spec.body and spec.bodyJson, and bodies failing the checks
+// of their kind
+type BodyCustomValidator struct {
+	Kind string
+}
+
+// bodyChecks validate the body of the kinds whose values Elasticsearch rejects with terse parse errors
+var bodyChecks = map[string]func(body string, path *field.Path) field.ErrorList{
+	"IndexLifecyclePolicy":    esutils.ValidateIndexLifecyclePolicyBody,
+	"QueryRuleset":            esutils.ValidateQueryRulesetBody,
+	"SnapshotLifecyclePolicy": esutils.ValidateSnapshotLifecyclePolicyBody,
+	"SnapshotRepository":      esutils.ValidateSnapshotRepositoryBody,
+}
+
+var _ webhook.CustomValidator = &BodyCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator
+func (v *BodyCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validateBody(obj)
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (v *BodyCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validateBody(newObj)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (v *BodyCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *BodyCustomValidator) validateBody(runtimeObj runtime.Object) error {
+	obj, ok := runtimeObj.(bodyObject)
+	if !ok {
+		return fmt.Errorf("expected a %s object but got %T", v.Kind, runtimeObj)
+	}
+	errs := utils.ValidateBodyFields(obj.BodyFields())
+	if check, ok := bodyChecks[v.Kind]; ok && len(errs) == 0 {
+		body, bodyJSON := obj.BodyFields()
+		bodyPath := field.NewPath("spec").Child("body")
+		if body == "" && bodyJSON != nil {
+			body = string(bodyJSON.Raw)
+			bodyPath = field.NewPath("spec").Child("bodyJson")
+		}
+		errs = check(body, bodyPath)
+	}
+	if len(errs) > 0 {
+		return apierrors.NewInvalid(eseckv1alpha1.GroupVersion.WithKind(v.Kind).GroupKind(), obj.GetName(), errs)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBodyCustomValidator(t *testing.T) {
+	bodyJSON := &apiextensionsv1.JSON{Raw: []byte(`{"processors":[]}`)}
+	tests := []struct {
+		name     string
+		body     string
+		bodyJSON *apiextensionsv1.JSON
+		wantErr  bool
+	}{
+		{name: "body", body: `{"processors":[]}`},
+		{name: "bodyJson", bodyJSON: bodyJSON},
+		{name: "both", body: `{"processors":[]}`, bodyJSON: bodyJSON, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := &eseckv1alpha1.IngestPipeline{
+				ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+				Spec:       eseckv1alpha1.IngestPipelineSpec{Body: tt.body, BodyJSON: tt.bodyJSON},
+			}
+			validator := &BodyCustomValidator{Kind: "IngestPipeline"}
+
+			_, createErr := validator.ValidateCreate(context.Background(), pipeline)
+			_, updateErr := validator.ValidateUpdate(context.Background(), pipeline, pipeline)
+
+			for _, err := range []error{createErr, updateErr} {
+				if (err != nil) != tt.wantErr {
+					t.Errorf("Validate error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err != nil && !apierrors.IsInvalid(err) {
+					t.Errorf("Expected an Invalid API error, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestBodyCustomValidator_LifecyclePolicies(t *testing.T) {
+	validator := &BodyCustomValidator{Kind: "SnapshotLifecyclePolicy"}
+	policy := &eseckv1alpha1.SnapshotLifecyclePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly"},
+		Spec:       eseckv1alpha1.SnapshotLifecyclePolicySpec{Body: `{"schedule": "0 30 1 * * ?", "repository": "backups"}`},
+	}
+	if _, err := validator.ValidateCreate(context.Background(), policy); err != nil {
+		t.Errorf("ValidateCreate() error = %v", err)
+	}
+	policy.Spec.Body = `{"schedule": "30 1 * * *", "repository": "backups"}`
+	if _, err := validator.ValidateCreate(context.Background(), policy); !apierrors.IsInvalid(err) {
+		t.Errorf("Expected an Invalid API error for a schedule without seconds, got %v", err)
+	}
+
+	validator = &BodyCustomValidator{Kind: "IndexLifecyclePolicy"}
+	indexLifecyclePolicy := &eseckv1alpha1.IndexLifecyclePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+		Spec: eseckv1alpha1.IndexLifecyclePolicySpec{
+			BodyJSON: &apiextensionsv1.JSON{Raw: []byte(`{"policy": {"phases": {"delete": {"min_age": "30 days", "actions": {"delete": {}}}}}}`)},
+		},
+	}
+	_, err := validator.ValidateUpdate(context.Background(), indexLifecyclePolicy, indexLifecyclePolicy)
+	if !apierrors.IsInvalid(err) || !strings.Contains(err.Error(), "spec.bodyJson.policy.phases.delete.min_age") {
+		t.Errorf("Expected an Invalid API error for the min_age, got %v", err)
+	}
+}
+
+func TestIndexCustomValidator_BodyAndBodyJSON(t *testing.T) {
+	index := &eseckv1alpha1.Index{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-index"},
+		Spec: eseckv1alpha1.IndexSpec{
+			Body:     `{}`,
+			BodyJSON: &apiextensionsv1.JSON{Raw: []byte(`{"settings":{}}`)},
+		},
+	}
+	if _, err := (&IndexCustomValidator{}).ValidateCreate(context.Background(), index); !apierrors.IsInvalid(err) {
+		t.Errorf("Expected an Invalid API error, got %v", err)
+	}
+}
+
+func TestBodyCustomValidator_QueryRuleset(t *testing.T) {
+	validator := &BodyCustomValidator{Kind: "QueryRuleset"}
+	queryRuleset := &eseckv1alpha1.QueryRuleset{
+		ObjectMeta: metav1.ObjectMeta{Name: "products"},
+		Spec: eseckv1alpha1.QueryRulesetSpec{
+			Body: `{"rules": [{"rule_id": "pin", "type": "pinned", "criteria": [{"type": "exact", "metadata": "user_query", "values": ["pugs"]}], "actions": {"ids": ["1"]}}]}`,
+		},
+	}
+	if _, err := validator.ValidateCreate(context.Background(), queryRuleset); err != nil {
+		t.Errorf("ValidateCreate() error = %v", err)
+	}
+	queryRuleset.Spec.Body = strings.Replace(queryRuleset.Spec.Body, `"pinned"`, `"boost"`, 1)
+	if _, err := validator.ValidateCreate(context.Background(), queryRuleset); !apierrors.IsInvalid(err) || !strings.Contains(err.Error(), "spec.body.rules[0].type") {
+		t.Errorf("Expected an Invalid API error for the rule type, got %v", err)
+	}
+}
+
+func TestBodyCustomValidator_SnapshotRepository(t *testing.T) {
+	validator := &BodyCustomValidator{Kind: "SnapshotRepository"}
+	repository := &eseckv1alpha1.SnapshotRepository{
+		ObjectMeta: metav1.ObjectMeta{Name: "backups"},
+		Spec:       eseckv1alpha1.SnapshotRepositorySpec{Body: `{"type": "s3", "settings": {"bucket": "backups"}}`},
+	}
+	if _, err := validator.ValidateCreate(context.Background(), repository); err != nil {
+		t.Errorf("ValidateCreate() error = %v", err)
+	}
+	repository.Spec.Body = `{"type": "azure", "settings": {"bucket": "backups"}}`
+	_, err := validator.ValidateCreate(context.Background(), repository)
+	if !apierrors.IsInvalid(err) || !strings.Contains(err.Error(), "spec.body.settings.container: Required") ||
+		!strings.Contains(err.Error(), "spec.body.settings.bucket: Forbidden") {
+		t.Errorf("Expected an Invalid API error for the azure settings, got %v", err)
+	}
+}
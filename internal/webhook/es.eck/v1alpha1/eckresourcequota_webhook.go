@@ -0,0 +1,81 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"eck-custom-resources/utils/quota"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// EckResourceQuotaWebhookPath is the path the quota webhook is served at
+const EckResourceQuotaWebhookPath = "/validate-eck-resource-quota"
+
+// SetupEckResourceQuotaWebhookWithManager registers the webhook enforcing the EckResourceQuotas on the creation of
+// the limited kinds in the manager.
+func SetupEckResourceQuotaWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(EckResourceQuotaWebhookPath, &webhook.Admission{
+		Handler: &EckResourceQuotaValidator{Client: mgr.GetClient()},
+	})
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-eck-resource-quota,mutating=false,failurePolicy=fail,sideEffects=None,groups=es.eck.github.com;kibana.eck.github.com,resources=indices;ingestpipelines;elasticsearchapikeys;canvasworkpads;dashboards;dataviews;indexpatterns;lens;savedsearches;visualizations,verbs=create,versions=v1alpha1,name=veckresourcequota-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// EckResourceQuotaValidator rejects the creation of resources which would exceed the limit of the EckResourceQuotas
+// of their namespace
+type EckResourceQuotaValidator struct {
+	Client client.Client
+}
+
+var _ admission.Handler = &EckResourceQuotaValidator{}
+
+// Handle implements admission.Handler
+func (v *EckResourceQuotaValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+	resource, ok := quota.ResourceOfKind(schema.GroupKind{Group: req.Kind.Group, Kind: req.Kind.Kind})
+	if !ok {
+		return admission.Allowed("")
+	}
+
+	limit, err := quota.Limit(ctx, v.Client, req.Namespace, resource)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if limit == nil {
+		return admission.Allowed("")
+	}
+	resources, err := quota.List(ctx, v.Client, req.Namespace, resource)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if len(resources) >= int(*limit) {
+		return admission.Denied(fmt.Sprintf("exceeded EckResourceQuota: namespace %s allows %d %s, %d exist", req.Namespace, *limit, resource, len(resources)))
+	}
+	return admission.Allowed("")
+}
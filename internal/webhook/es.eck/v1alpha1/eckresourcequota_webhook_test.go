@@ -0,0 +1,53 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestEckResourceQuotaValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	maxIngestPipelines := int32(1)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&eseckv1alpha1.EckResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "team-a"},
+			Spec:       eseckv1alpha1.EckResourceQuotaSpec{MaxIngestPipelines: &maxIngestPipelines},
+		},
+		&eseckv1alpha1.IngestPipeline{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "team-a"}},
+	).Build()
+	validator := &EckResourceQuotaValidator{Client: cli}
+
+	tests := []struct {
+		name        string
+		kind        string
+		namespace   string
+		operation   admissionv1.Operation
+		wantAllowed bool
+	}{
+		{name: "create over the limit", kind: "IngestPipeline", namespace: "team-a", operation: admissionv1.Create, wantAllowed: false},
+		{name: "update over the limit", kind: "IngestPipeline", namespace: "team-a", operation: admissionv1.Update, wantAllowed: true},
+		{name: "unlimited kind", kind: "Index", namespace: "team-a", operation: admissionv1.Create, wantAllowed: true},
+		{name: "namespace without quota", kind: "IngestPipeline", namespace: "team-b", operation: admissionv1.Create, wantAllowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind{Group: eseckv1alpha1.GroupVersion.Group, Version: "v1alpha1", Kind: tt.kind},
+				Namespace: tt.namespace,
+				Operation: tt.operation,
+			}})
+			if response.Allowed != tt.wantAllowed {
+				t.Errorf("Handle() allowed = %v, want %v: %v", response.Allowed, tt.wantAllowed, response.Result)
+			}
+		})
+	}
+}
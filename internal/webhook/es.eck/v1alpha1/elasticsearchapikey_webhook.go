@@ -0,0 +1,133 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
+
+	k8sv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupElasticsearchApikeyWebhookWithManager registers the webhook for ElasticsearchApikey in the manager.
+func SetupElasticsearchApikeyWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&eseckv1alpha1.ElasticsearchApikey{}).
+		WithValidator(&ElasticsearchApikeyCustomValidator{Client: mgr.GetClient()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-es-eck-github-com-v1alpha1-elasticsearchapikey,mutating=false,failurePolicy=fail,sideEffects=None,groups=es.eck.github.com,resources=elasticsearchapikeys,verbs=create;update,versions=v1alpha1,name=velasticsearchapikey-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ElasticsearchApikeyCustomValidator rejects ElasticsearchApikey resources whose Secret collides with a Secret
+// managed by anything else or with the Secret of another ElasticsearchApikey, and changes of spec.secretName
+type ElasticsearchApikeyCustomValidator struct {
+	Client client.Reader
+}
+
+var _ webhook.CustomValidator = &ElasticsearchApikeyCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator
+func (v *ElasticsearchApikeyCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	apikey, ok := obj.(*eseckv1alpha1.ElasticsearchApikey)
+	if !ok {
+		return nil, fmt.Errorf("expected an ElasticsearchApikey object but got %T", obj)
+	}
+	return nil, v.validateApikey(ctx, apikey, nil)
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (v *ElasticsearchApikeyCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	apikey, ok := newObj.(*eseckv1alpha1.ElasticsearchApikey)
+	if !ok {
+		return nil, fmt.Errorf("expected an ElasticsearchApikey object for the newObj but got %T", newObj)
+	}
+	oldApikey, ok := oldObj.(*eseckv1alpha1.ElasticsearchApikey)
+	if !ok {
+		return nil, fmt.Errorf("expected an ElasticsearchApikey object for the oldObj but got %T", oldObj)
+	}
+	return nil, v.validateApikey(ctx, apikey, oldApikey)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (v *ElasticsearchApikeyCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *ElasticsearchApikeyCustomValidator) validateApikey(ctx context.Context, apikey *eseckv1alpha1.ElasticsearchApikey, oldApikey *eseckv1alpha1.ElasticsearchApikey) error {
+	secretNamePath := field.NewPath("spec").Child("secretName")
+	errs := utils.ValidateBodyFields(apikey.BodyFields())
+
+	if oldApikey != nil && oldApikey.GetSecretName() != apikey.GetSecretName() {
+		errs = append(errs, field.Forbidden(secretNamePath, "the Secret of an API key cannot be changed"))
+	}
+
+	// The Secret was checked when it was set. The operator creates it afterwards and another resource may claim its
+	// name since, neither must block updates like the removal of the finalizer.
+	if oldApikey == nil || (apikey.DeletionTimestamp.IsZero() && oldApikey.GetSecretName() != apikey.GetSecretName()) {
+		secretErrs, err := v.validateApikeySecret(ctx, apikey)
+		if err != nil {
+			return err
+		}
+		errs = append(errs, secretErrs...)
+	}
+
+	if len(errs) > 0 {
+		return apierrors.NewInvalid(eseckv1alpha1.GroupVersion.WithKind("ElasticsearchApikey").GroupKind(), apikey.Name, errs)
+	}
+	return nil
+}
+
+// validateApikeySecret returns errors if the Secret of the API key is managed by anything else or by another
+// ElasticsearchApikey
+func (v *ElasticsearchApikeyCustomValidator) validateApikeySecret(ctx context.Context, apikey *eseckv1alpha1.ElasticsearchApikey) (field.ErrorList, error) {
+	secretNamePath := field.NewPath("spec").Child("secretName")
+	var errs field.ErrorList
+
+	var sec k8sv1.Secret
+	err := v.Client.Get(ctx, client.ObjectKey{Namespace: apikey.Namespace, Name: apikey.GetSecretName()}, &sec)
+	switch {
+	case err == nil:
+		if ownershipErr := esutils.VerifyApikeySecretOwnership(&sec, apikey); ownershipErr != nil {
+			errs = append(errs, field.Invalid(secretNamePath, apikey.GetSecretName(), ownershipErr.Error()))
+		}
+	case !apierrors.IsNotFound(err):
+		return nil, err
+	}
+
+	var apikeys eseckv1alpha1.ElasticsearchApikeyList
+	if err := v.Client.List(ctx, &apikeys, client.InNamespace(apikey.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, other := range apikeys.Items {
+		if other.Name != apikey.Name && other.GetSecretName() == apikey.GetSecretName() {
+			errs = append(errs, field.Duplicate(secretNamePath, apikey.GetSecretName()))
+			break
+		}
+	}
+	return errs, nil
+}
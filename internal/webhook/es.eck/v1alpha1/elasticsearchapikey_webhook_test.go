@@ -0,0 +1,124 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	esutils "eck-custom-resources/utils/elasticsearch"
+
+	k8sv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestElasticsearchApikeyCustomValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	_ = k8sv1.AddToScheme(scheme)
+
+	objects := []client.Object{
+		&k8sv1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "tls-cert", Namespace: "default"}, Data: map[string][]byte{"tls.crt": []byte("cert")}},
+		&k8sv1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name: "ingest-key", Namespace: "default", Labels: map[string]string{esutils.ApikeySecretLabel: "ingest"},
+		}},
+		&eseckv1alpha1.ElasticsearchApikey{
+			ObjectMeta: metav1.ObjectMeta{Name: "ingest", Namespace: "default"},
+			Spec:       eseckv1alpha1.ElasticsearchApikeySpec{Body: `{}`, SecretName: "ingest-key"},
+		},
+	}
+	validator := &ElasticsearchApikeyCustomValidator{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+	}
+
+	tests := []struct {
+		name       string
+		apikeyName string
+		secretName string
+		wantErr    bool
+	}{
+		{name: "new secret", apikeyName: "monitoring", wantErr: false},
+		{name: "own secret", apikeyName: "ingest", secretName: "ingest-key", wantErr: false},
+		{name: "foreign secret", apikeyName: "monitoring", secretName: "tls-cert", wantErr: true},
+		{name: "secret of another API key", apikeyName: "monitoring", secretName: "ingest-key", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apikey := &eseckv1alpha1.ElasticsearchApikey{
+				ObjectMeta: metav1.ObjectMeta{Name: tt.apikeyName, Namespace: "default"},
+				Spec:       eseckv1alpha1.ElasticsearchApikeySpec{Body: `{}`, SecretName: tt.secretName},
+			}
+
+			_, err := validator.ValidateCreate(context.Background(), apikey)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !apierrors.IsInvalid(err) {
+				t.Errorf("Expected an Invalid API error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestElasticsearchApikeyCustomValidator_SecretNameImmutable(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	_ = k8sv1.AddToScheme(scheme)
+	validator := &ElasticsearchApikeyCustomValidator{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+
+	oldApikey := &eseckv1alpha1.ElasticsearchApikey{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingest", Namespace: "default"},
+		Spec:       eseckv1alpha1.ElasticsearchApikeySpec{Body: `{}`},
+	}
+	newApikey := oldApikey.DeepCopy()
+	newApikey.Spec.SecretName = "ingest-key"
+
+	if _, err := validator.ValidateUpdate(context.Background(), oldApikey, newApikey); !apierrors.IsInvalid(err) {
+		t.Errorf("Expected changing the Secret to be rejected, got %v", err)
+	}
+	newApikey.Spec.Body = `{"role_descriptors": {}}`
+	newApikey.Spec.SecretName = ""
+	if _, err := validator.ValidateUpdate(context.Background(), oldApikey, newApikey); err != nil {
+		t.Errorf("Expected a body change to be accepted, got %v", err)
+	}
+}
+
+func TestElasticsearchApikeyCustomValidator_RemoveFinalizer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	_ = k8sv1.AddToScheme(scheme)
+	// Another API key claims the Secret, e.g. one created while the webhook was disabled
+	validator := &ElasticsearchApikeyCustomValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&k8sv1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name: "ingest-key", Namespace: "default", Labels: map[string]string{esutils.ApikeySecretLabel: "other"},
+		}},
+		&eseckv1alpha1.ElasticsearchApikey{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+			Spec:       eseckv1alpha1.ElasticsearchApikeySpec{Body: `{}`, SecretName: "ingest-key"},
+		},
+	).Build()}
+
+	deletionTimestamp := metav1.Now()
+	oldApikey := &eseckv1alpha1.ElasticsearchApikey{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "ingest",
+			Namespace:         "default",
+			Finalizers:        []string{"elasticsearchapikeys.es.eck.github.com/finalizer"},
+			DeletionTimestamp: &deletionTimestamp,
+		},
+		Spec: eseckv1alpha1.ElasticsearchApikeySpec{Body: `{}`, SecretName: "ingest-key"},
+	}
+	newApikey := oldApikey.DeepCopy()
+	newApikey.Finalizers = nil
+
+	if _, err := validator.ValidateUpdate(context.Background(), oldApikey, newApikey); err != nil {
+		t.Errorf("Expected the finalizer to be removable, got %v", err)
+	}
+	if _, err := validator.ValidateCreate(context.Background(), newApikey); !apierrors.IsInvalid(err) {
+		t.Errorf("Expected the Secret to be rejected on create, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupIndexWebhookWithManager registers the webhook for Index in the manager.
+func SetupIndexWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&eseckv1alpha1.Index{}).
+		WithValidator(&IndexCustomValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-es-eck-github-com-v1alpha1-index,mutating=false,failurePolicy=fail,sideEffects=None,groups=es.eck.github.com,resources=indices,verbs=create;update,versions=v1alpha1,name=vindex-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// IndexCustomValidator rejects Index resources targeting hidden or system indices unless spec.force is set
+type IndexCustomValidator struct{}
+
+var _ webhook.CustomValidator = &IndexCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator
+func (v *IndexCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	index, ok := obj.(*eseckv1alpha1.Index)
+	if !ok {
+		return nil, fmt.Errorf("expected an Index object but got %T", obj)
+	}
+	return nil, validateIndex(index)
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (v *IndexCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	index, ok := newObj.(*eseckv1alpha1.Index)
+	if !ok {
+		return nil, fmt.Errorf("expected an Index object for the newObj but got %T", newObj)
+	}
+	return nil, validateIndex(index)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (v *IndexCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateIndex(index *eseckv1alpha1.Index) error {
+	errs := utils.ValidateBodyFields(index.BodyFields())
+	if err := esutils.VerifyIndexNotProtected(utils.RemoteName(index), index.Spec.Force); err != nil {
+		errs = append(errs, field.Forbidden(field.NewPath("metadata").Child("name"), err.Error()))
+	}
+	if len(errs) > 0 {
+		return apierrors.NewInvalid(eseckv1alpha1.GroupVersion.WithKind("Index").GroupKind(), index.Name, errs)
+	}
+	return nil
+}
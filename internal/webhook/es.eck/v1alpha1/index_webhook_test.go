@@ -0,0 +1,64 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIndexCustomValidator(t *testing.T) {
+	tests := []struct {
+		name      string
+		indexName string
+		force     bool
+		wantErr   bool
+	}{
+		{
+			name:      "regular index",
+			indexName: "my-index",
+			wantErr:   false,
+		},
+		{
+			name:      "hidden index",
+			indexName: ".kibana",
+			wantErr:   true,
+		},
+		{
+			name:      "system history index",
+			indexName: "ilm-history-7",
+			wantErr:   true,
+		},
+		{
+			name:      "forced hidden index",
+			indexName: ".my-hidden-index",
+			force:     true,
+			wantErr:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := &eseckv1alpha1.Index{
+				ObjectMeta: metav1.ObjectMeta{Name: tt.indexName},
+				Spec:       eseckv1alpha1.IndexSpec{Body: `{}`, Force: tt.force},
+			}
+			validator := &IndexCustomValidator{}
+
+			_, createErr := validator.ValidateCreate(context.Background(), index)
+			_, updateErr := validator.ValidateUpdate(context.Background(), index, index)
+
+			for _, err := range []error{createErr, updateErr} {
+				if (err != nil) != tt.wantErr {
+					t.Errorf("Validate error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err != nil && !apierrors.IsInvalid(err) {
+					t.Errorf("Expected an Invalid API error, got %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// bodyObject is a resource with a body given either as spec.body or as spec.bodyJson
+type bodyObject interface {
+	client.Object
+	BodyFields() (string, *apiextensionsv1.JSON)
+}
+
+// bodyFromObject is a resource which may read its body from a ConfigMap or Secret with spec.bodyFrom
+type bodyFromObject interface {
+	BodyFromRef() *kibanaeckv1alpha1.BodyFrom
+}
+
+// validateBodyFrom returns field errors if spec.bodyFrom is set together with spec.body or spec.bodyJson, or does not
+// reference exactly one ConfigMap or Secret. The returned bool reports whether the body is read with spec.bodyFrom,
+// its content is not available for validation then.
+func validateBodyFrom(obj bodyObject) (bool, field.ErrorList) {
+	withBodyFrom, ok := obj.(bodyFromObject)
+	if !ok || withBodyFrom.BodyFromRef() == nil {
+		return false, nil
+	}
+	bodyFrom := withBodyFrom.BodyFromRef()
+	path := field.NewPath("spec").Child("bodyFrom")
+
+	var allErrs field.ErrorList
+	if body, bodyJSON := obj.BodyFields(); body != "" || (bodyJSON != nil && len(bodyJSON.Raw) > 0) {
+		allErrs = append(allErrs, field.Forbidden(path, "only one of spec.body, spec.bodyJson and spec.bodyFrom may be set"))
+	}
+	if (bodyFrom.ConfigMapKeyRef == nil) == (bodyFrom.SecretKeyRef == nil) {
+		allErrs = append(allErrs, field.Invalid(path, bodyFrom, "exactly one of configMapKeyRef and secretKeyRef has to be set"))
+	}
+	return true, allErrs
+}
+
+// SetupBodyWebhooksWithManager registers the webhooks for the resources with a body which have no validator of
+// their own in the manager.
+func SetupBodyWebhooksWithManager(mgr ctrl.Manager) error {
+	for _, resource := range []struct {
+		kind string
+		obj  bodyObject
+	}{
+		{kind: "AdvancedSettings", obj: &kibanaeckv1alpha1.AdvancedSettings{}},
+		{kind: "AgentPolicy", obj: &kibanaeckv1alpha1.AgentPolicy{}},
+		{kind: "CanvasWorkpad", obj: &kibanaeckv1alpha1.CanvasWorkpad{}},
+		{kind: "IndexPattern", obj: &kibanaeckv1alpha1.IndexPattern{}},
+		{kind: "MaintenanceWindow", obj: &kibanaeckv1alpha1.MaintenanceWindow{}},
+		{kind: "PackagePolicy", obj: &kibanaeckv1alpha1.PackagePolicy{}},
+		{kind: "SavedSearch", obj: &kibanaeckv1alpha1.SavedSearch{}},
+		{kind: "Visualization", obj: &kibanaeckv1alpha1.Visualization{}},
+	} {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(resource.obj).
+			WithValidator(&BodyCustomValidator{Kind: resource.kind}).
+			Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-kibana-eck-github-com-v1alpha1-advancedsettings,mutating=false,failurePolicy=fail,sideEffects=None,groups=kibana.eck.github.com,resources=advancedsettings,verbs=create;update,versions=v1alpha1,name=vadvancedsettings-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-kibana-eck-github-com-v1alpha1-agentpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=kibana.eck.github.com,resources=agentpolicies,verbs=create;update,versions=v1alpha1,name=vagentpolicy-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-kibana-eck-github-com-v1alpha1-canvasworkpad,mutating=false,failurePolicy=fail,sideEffects=None,groups=kibana.eck.github.com,resources=canvasworkpads,verbs=create;update,versions=v1alpha1,name=vcanvasworkpad-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-kibana-eck-github-com-v1alpha1-indexpattern,mutating=false,failurePolicy=fail,sideEffects=None,groups=kibana.eck.github.com,resources=indexpatterns,verbs=create;update,versions=v1alpha1,name=vindexpattern-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-kibana-eck-github-com-v1alpha1-maintenancewindow,mutating=false,failurePolicy=fail,sideEffects=None,groups=kibana.eck.github.com,resources=maintenancewindows,verbs=create;update,versions=v1alpha1,name=vmaintenancewindow-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-kibana-eck-github-com-v1alpha1-packagepolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=kibana.eck.github.com,resources=packagepolicies,verbs=create;update,versions=v1alpha1,name=vpackagepolicy-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-kibana-eck-github-com-v1alpha1-savedsearch,mutating=false,failurePolicy=fail,sideEffects=None,groups=kibana.eck.github.com,resources=savedsearches,verbs=create;update,versions=v1alpha1,name=vsavedsearch-v1alpha1.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-kibana-eck-github-com-v1alpha1-visualization,mutating=false,failurePolicy=fail,sideEffects=None,groups=kibana.eck.github.com,resources=visualizations,verbs=create;update,versions=v1alpha1,name=vvisualization-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// BodyCustomValidator rejects resources which set both spec.body and spec.bodyJson
+type BodyCustomValidator struct {
+	Kind string
+}
+
+var _ webhook.CustomValidator = &BodyCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator
+func (v *BodyCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validateBody(obj)
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (v *BodyCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	if obj, ok := newObj.(client.Object); ok {
+		if err := invalidIfErrors(v.Kind, obj.GetName(), validateLocationChange(oldObj, newObj)); err != nil {
+			return nil, err
+		}
+	}
+	return nil, v.validateBody(newObj)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (v *BodyCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *BodyCustomValidator) validateBody(runtimeObj runtime.Object) error {
+	obj, ok := runtimeObj.(bodyObject)
+	if !ok {
+		return fmt.Errorf("expected a %s object but got %T", v.Kind, runtimeObj)
+	}
+	allErrs := utils.ValidateBodyFields(obj.BodyFields())
+	_, bodyFromErrs := validateBodyFrom(obj)
+	return invalidIfErrors(v.Kind, obj.GetName(), append(allErrs, bodyFromErrs...))
+}
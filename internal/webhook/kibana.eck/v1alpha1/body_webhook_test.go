@@ -0,0 +1,96 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBodyCustomValidator(t *testing.T) {
+	bodyJSON := &apiextensionsv1.JSON{Raw: []byte(`{"attributes":{"title":"Errors"}}`)}
+	tests := []struct {
+		name     string
+		body     string
+		bodyJSON *apiextensionsv1.JSON
+		wantErr  bool
+	}{
+		{name: "body", body: `{"attributes":{"title":"Errors"}}`},
+		{name: "bodyJson", bodyJSON: bodyJSON},
+		{name: "both", body: `{"attributes":{"title":"Errors"}}`, bodyJSON: bodyJSON, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			savedSearch := &kibanaeckv1alpha1.SavedSearch{
+				ObjectMeta: metav1.ObjectMeta{Name: "errors"},
+			}
+			savedSearch.Spec.Body = tt.body
+			savedSearch.Spec.BodyJSON = tt.bodyJSON
+			validator := &BodyCustomValidator{Kind: "SavedSearch"}
+
+			_, createErr := validator.ValidateCreate(context.Background(), savedSearch)
+			_, updateErr := validator.ValidateUpdate(context.Background(), savedSearch, savedSearch)
+
+			for _, err := range []error{createErr, updateErr} {
+				if (err != nil) != tt.wantErr {
+					t.Errorf("Validate error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err != nil && !apierrors.IsInvalid(err) {
+					t.Errorf("Expected an Invalid API error, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestDashboardCustomValidator_BodyJSON(t *testing.T) {
+	dashboard := &kibanaeckv1alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "kpis"}}
+	dashboard.Spec.BodyJSON = &apiextensionsv1.JSON{Raw: []byte(`{"attributes":{"title":"KPIs","panelsJSON":"[]"}}`)}
+	if _, err := (&DashboardCustomValidator{}).ValidateCreate(context.Background(), dashboard); err != nil {
+		t.Errorf("Expected a valid bodyJson to be accepted, got %v", err)
+	}
+
+	dashboard.Spec.BodyJSON = &apiextensionsv1.JSON{Raw: []byte(`{"attributes":{"title":"KPIs"}}`)}
+	if _, err := (&DashboardCustomValidator{}).ValidateCreate(context.Background(), dashboard); !apierrors.IsInvalid(err) {
+		t.Errorf("Expected the checks to run against bodyJson, got %v", err)
+	}
+
+	dashboard.Spec.Body = `{"attributes":{"title":"KPIs","panelsJSON":"[]"}}`
+	if _, err := (&DashboardCustomValidator{}).ValidateCreate(context.Background(), dashboard); !apierrors.IsInvalid(err) {
+		t.Errorf("Expected body and bodyJson to be rejected, got %v", err)
+	}
+}
+
+func TestDashboardCustomValidator_BodyFrom(t *testing.T) {
+	dashboard := &kibanaeckv1alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "kpis"}}
+	dashboard.Spec.BodyFrom = &kibanaeckv1alpha1.BodyFrom{
+		ConfigMapKeyRef: &kibanaeckv1alpha1.BodyKeyReference{Name: "kpis-dashboard", Key: "dashboard.json.gz"},
+		Encoding:        kibanaeckv1alpha1.BodyEncodingGzip,
+	}
+	if _, err := (&DashboardCustomValidator{}).ValidateCreate(context.Background(), dashboard); err != nil {
+		t.Errorf("Expected a body read with bodyFrom not to be checked, got %v", err)
+	}
+
+	dashboard.Spec.BodyFrom.SecretKeyRef = &kibanaeckv1alpha1.BodyKeyReference{Name: "kpis-dashboard", Key: "dashboard.json"}
+	if _, err := (&DashboardCustomValidator{}).ValidateCreate(context.Background(), dashboard); !apierrors.IsInvalid(err) {
+		t.Errorf("Expected a ConfigMap and a Secret to be rejected, got %v", err)
+	}
+
+	dashboard.Spec.BodyFrom.SecretKeyRef = nil
+	dashboard.Spec.Body = `{"attributes":{"title":"KPIs","panelsJSON":"[]"}}`
+	if _, err := (&DashboardCustomValidator{}).ValidateCreate(context.Background(), dashboard); !apierrors.IsInvalid(err) {
+		t.Errorf("Expected body and bodyFrom to be rejected, got %v", err)
+	}
+
+	savedSearch := &kibanaeckv1alpha1.SavedSearch{ObjectMeta: metav1.ObjectMeta{Name: "errors"}}
+	savedSearch.Spec.Body = `{"attributes":{"title":"Errors"}}`
+	savedSearch.Spec.BodyFrom = dashboard.Spec.BodyFrom
+	if _, err := (&BodyCustomValidator{Kind: "SavedSearch"}).ValidateCreate(context.Background(), savedSearch); !apierrors.IsInvalid(err) {
+		t.Errorf("Expected body and bodyFrom to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupDashboardWebhookWithManager registers the webhook for Dashboard in the manager.
+func SetupDashboardWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&kibanaeckv1alpha1.Dashboard{}).
+		WithValidator(&DashboardCustomValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-kibana-eck-github-com-v1alpha1-dashboard,mutating=false,failurePolicy=fail,sideEffects=None,groups=kibana.eck.github.com,resources=dashboards,verbs=create;update,versions=v1alpha1,name=vdashboard-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// DashboardCustomValidator rejects Dashboard resources whose body is missing attributes.title and attributes.panelsJSON
+type DashboardCustomValidator struct{}
+
+var _ webhook.CustomValidator = &DashboardCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator
+func (v *DashboardCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	dashboard, ok := obj.(*kibanaeckv1alpha1.Dashboard)
+	if !ok {
+		return nil, fmt.Errorf("expected a Dashboard object but got %T", obj)
+	}
+	return nil, validateDashboard(dashboard)
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (v *DashboardCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	dashboard, ok := newObj.(*kibanaeckv1alpha1.Dashboard)
+	if !ok {
+		return nil, fmt.Errorf("expected a Dashboard object for the newObj but got %T", newObj)
+	}
+	if err := invalidIfErrors("Dashboard", dashboard.Name, validateLocationChange(oldObj, dashboard)); err != nil {
+		return nil, err
+	}
+	return nil, validateDashboard(dashboard)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (v *DashboardCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateDashboard(dashboard *kibanaeckv1alpha1.Dashboard) error {
+	return invalidIfErrors("Dashboard", dashboard.Name, validateSavedObjectBody(dashboard, "attributes",
+		requiredString("title"),
+		requiredJSONArrayString("panelsJSON"),
+	))
+}
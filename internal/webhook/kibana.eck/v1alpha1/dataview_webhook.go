@@ -0,0 +1,75 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupDataViewWebhookWithManager registers the webhook for DataView in the manager.
+func SetupDataViewWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&kibanaeckv1alpha1.DataView{}).
+		WithValidator(&DataViewCustomValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-kibana-eck-github-com-v1alpha1-dataview,mutating=false,failurePolicy=fail,sideEffects=None,groups=kibana.eck.github.com,resources=dataviews,verbs=create;update,versions=v1alpha1,name=vdataview-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// DataViewCustomValidator rejects DataView resources whose body is missing title
+type DataViewCustomValidator struct{}
+
+var _ webhook.CustomValidator = &DataViewCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator
+func (v *DataViewCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	dataView, ok := obj.(*kibanaeckv1alpha1.DataView)
+	if !ok {
+		return nil, fmt.Errorf("expected a DataView object but got %T", obj)
+	}
+	return nil, validateDataView(dataView)
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (v *DataViewCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	dataView, ok := newObj.(*kibanaeckv1alpha1.DataView)
+	if !ok {
+		return nil, fmt.Errorf("expected a DataView object for the newObj but got %T", newObj)
+	}
+	if err := invalidIfErrors("DataView", dataView.Name, validateLocationChange(oldObj, dataView)); err != nil {
+		return nil, err
+	}
+	return nil, validateDataView(dataView)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (v *DataViewCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateDataView(dataView *kibanaeckv1alpha1.DataView) error {
+	return invalidIfErrors("DataView", dataView.Name, validateSavedObjectBody(dataView, "",
+		requiredString("title"),
+	))
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupLensWebhookWithManager registers the webhook for Lens in the manager.
+func SetupLensWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&kibanaeckv1alpha1.Lens{}).
+		WithValidator(&LensCustomValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-kibana-eck-github-com-v1alpha1-lens,mutating=false,failurePolicy=fail,sideEffects=None,groups=kibana.eck.github.com,resources=lens,verbs=create;update,versions=v1alpha1,name=vlens-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// LensCustomValidator rejects Lens resources whose body is missing attributes.visualizationType
+type LensCustomValidator struct{}
+
+var _ webhook.CustomValidator = &LensCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator
+func (v *LensCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	lens, ok := obj.(*kibanaeckv1alpha1.Lens)
+	if !ok {
+		return nil, fmt.Errorf("expected a Lens object but got %T", obj)
+	}
+	return nil, validateLens(lens)
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (v *LensCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	lens, ok := newObj.(*kibanaeckv1alpha1.Lens)
+	if !ok {
+		return nil, fmt.Errorf("expected a Lens object for the newObj but got %T", newObj)
+	}
+	if err := invalidIfErrors("Lens", lens.Name, validateLocationChange(oldObj, lens)); err != nil {
+		return nil, err
+	}
+	return nil, validateLens(lens)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (v *LensCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateLens(lens *kibanaeckv1alpha1.Lens) error {
+	return invalidIfErrors("Lens", lens.Name, validateSavedObjectBody(lens, "attributes",
+		requiredString("visualizationType"),
+	))
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// attributeCheck validates a single attribute of a saved object body
+type attributeCheck func(attributes map[string]interface{}, path *field.Path) *field.Error
+
+// requiredString checks that the attribute is a non-empty string
+func requiredString(name string) attributeCheck {
+	return func(attributes map[string]interface{}, path *field.Path) *field.Error {
+		value, ok := attributes[name]
+		if !ok {
+			return field.Required(path.Child(name), "")
+		}
+		stringValue, ok := value.(string)
+		if !ok {
+			return field.Invalid(path.Child(name), value, "must be a string")
+		}
+		if stringValue == "" {
+			return field.Required(path.Child(name), "must not be empty")
+		}
+		return nil
+	}
+}
+
+// requiredJSONArrayString checks that the attribute is a string holding a JSON array, like Kibana's panelsJSON
+func requiredJSONArrayString(name string) attributeCheck {
+	return func(attributes map[string]interface{}, path *field.Path) *field.Error {
+		if err := requiredString(name)(attributes, path); err != nil {
+			return err
+		}
+		var items []interface{}
+		if err := json.Unmarshal([]byte(attributes[name].(string)), &items); err != nil {
+			return field.Invalid(path.Child(name), attributes[name], fmt.Sprintf("must be a JSON encoded array: %s", err.Error()))
+		}
+		return nil
+	}
+}
+
+// validateSavedObjectBody parses the body and runs the checks against the object found under attributesKey,
+// or against the top level object if attributesKey is empty.
+func validateSavedObjectBody(obj bodyObject, attributesKey string, checks ...attributeCheck) field.ErrorList {
+	if allErrs := utils.ValidateBodyFields(obj.BodyFields()); len(allErrs) > 0 {
+		return allErrs
+	}
+	if usesBodyFrom, allErrs := validateBodyFrom(obj); usesBodyFrom || len(allErrs) > 0 {
+		return allErrs
+	}
+	body, bodyJSON := obj.BodyFields()
+	bodyPath := field.NewPath("spec").Child("body")
+	if body == "" && bodyJSON != nil {
+		body = string(bodyJSON.Raw)
+		bodyPath = field.NewPath("spec").Child("bodyJson")
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return field.ErrorList{field.Invalid(bodyPath, body, fmt.Sprintf("must be a JSON object: %s", err.Error()))}
+	}
+
+	attributes := parsed
+	attributesPath := bodyPath
+	if attributesKey != "" {
+		attributesPath = bodyPath.Child(attributesKey)
+		value, ok := parsed[attributesKey]
+		if !ok {
+			return field.ErrorList{field.Required(attributesPath, "")}
+		}
+		if attributes, ok = value.(map[string]interface{}); !ok {
+			return field.ErrorList{field.Invalid(attributesPath, value, "must be an object")}
+		}
+	}
+
+	var allErrs field.ErrorList
+	for _, check := range checks {
+		if err := check(attributes, attributesPath); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	return allErrs
+}
+
+// invalidIfErrors turns the field errors into an Invalid API error of the given kind
+func invalidIfErrors(kind string, name string, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(kibanaeckv1alpha1.GroupVersion.WithKind(kind).GroupKind(), name, allErrs)
+}
+
+// validateLocationChange rejects changes of spec.space and spec.targetInstance of a deployed saved object, which would
+// orphan it in its current space or target instance, unless spec.deletionPolicy allows the controller to delete it
+// there first. Changes of its id are always rejected. Resources which are no saved objects are not checked.
+func validateLocationChange(oldObj runtime.Object, newObj runtime.Object) field.ErrorList {
+	oldSavedObject, ok := oldObj.(kibanaeckv1alpha1.SavedObjectResource)
+	if !ok || oldSavedObject.GetDeployedTo() == nil {
+		return nil
+	}
+	newSavedObject, ok := newObj.(kibanaeckv1alpha1.SavedObjectResource)
+	if !ok {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if kibanaeckv1alpha1.SavedObjectIDOf(oldSavedObject) != kibanaeckv1alpha1.SavedObjectIDOf(newSavedObject) {
+		idPath := field.NewPath("spec").Child("idStrategy")
+		if oldSavedObject.GetSavedObjectSpec().ObjectID != newSavedObject.GetSavedObjectSpec().ObjectID {
+			idPath = field.NewPath("spec").Child("objectId")
+		}
+		allErrs = append(allErrs, field.Forbidden(idPath, "would change the id of the deployed saved object and orphan it"))
+	}
+	if newSavedObject.GetSavedObjectSpec().DeletionPolicy == kibanaeckv1alpha1.DeletionPolicyDelete {
+		return allErrs
+	}
+
+	oldLocation, newLocation := kibanaeckv1alpha1.LocationOf(oldSavedObject), kibanaeckv1alpha1.LocationOf(newSavedObject)
+	detail := "would orphan the deployed saved object, set spec.deletionPolicy to Delete to move it"
+	if !equality.Semantic.DeepEqual(oldLocation.TargetInstance, newLocation.TargetInstance) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("targetInstance"), detail))
+	}
+	if !equality.Semantic.DeepEqual(oldLocation.Space, newLocation.Space) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("space"), detail))
+	}
+	return allErrs
+}
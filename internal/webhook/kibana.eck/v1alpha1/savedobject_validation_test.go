@@ -0,0 +1,235 @@
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDashboardCustomValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{
+			name: "valid dashboard",
+			body: `{"attributes": {"title": "My dashboard", "panelsJSON": "[]"}}`,
+		},
+		{
+			name:    "body is not JSON",
+			body:    `{"attributes": `,
+			wantErr: "spec.body: Invalid value",
+		},
+		{
+			name:    "missing attributes",
+			body:    `{"references": []}`,
+			wantErr: "spec.body.attributes: Required value",
+		},
+		{
+			name:    "missing title",
+			body:    `{"attributes": {"panelsJSON": "[]"}}`,
+			wantErr: "spec.body.attributes.title: Required value",
+		},
+		{
+			name:    "empty title",
+			body:    `{"attributes": {"title": "", "panelsJSON": "[]"}}`,
+			wantErr: "spec.body.attributes.title: Required value",
+		},
+		{
+			name:    "missing panelsJSON",
+			body:    `{"attributes": {"title": "My dashboard"}}`,
+			wantErr: "spec.body.attributes.panelsJSON: Required value",
+		},
+		{
+			name:    "panelsJSON is not an encoded array",
+			body:    `{"attributes": {"title": "My dashboard", "panelsJSON": "{}"}}`,
+			wantErr: "spec.body.attributes.panelsJSON: Invalid value",
+		},
+		{
+			name:    "panelsJSON is not a string",
+			body:    `{"attributes": {"title": "My dashboard", "panelsJSON": []}}`,
+			wantErr: "spec.body.attributes.panelsJSON: Invalid value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dashboard := &kibanaeckv1alpha1.Dashboard{
+				ObjectMeta: metav1.ObjectMeta{Name: "dashboard-sample"},
+				Spec: kibanaeckv1alpha1.DashboardSpec{
+					SavedObject: kibanaeckv1alpha1.SavedObject{Body: tt.body},
+				},
+			}
+			validator := &DashboardCustomValidator{}
+
+			_, createErr := validator.ValidateCreate(context.Background(), dashboard)
+			_, updateErr := validator.ValidateUpdate(context.Background(), dashboard, dashboard)
+
+			assertValidationError(t, createErr, tt.wantErr)
+			assertValidationError(t, updateErr, tt.wantErr)
+		})
+	}
+}
+
+func TestLensCustomValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{
+			name: "valid lens",
+			body: `{"attributes": {"title": "Count", "visualizationType": "lnsXY"}}`,
+		},
+		{
+			name:    "missing visualizationType",
+			body:    `{"attributes": {"title": "Count"}}`,
+			wantErr: "spec.body.attributes.visualizationType: Required value",
+		},
+		{
+			name:    "visualizationType is not a string",
+			body:    `{"attributes": {"visualizationType": 1}}`,
+			wantErr: "spec.body.attributes.visualizationType: Invalid value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lens := &kibanaeckv1alpha1.Lens{
+				ObjectMeta: metav1.ObjectMeta{Name: "lens-sample"},
+				Spec: kibanaeckv1alpha1.LensSpec{
+					SavedObject: kibanaeckv1alpha1.SavedObject{Body: tt.body},
+				},
+			}
+
+			_, err := (&LensCustomValidator{}).ValidateCreate(context.Background(), lens)
+
+			assertValidationError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDataViewCustomValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{
+			name: "valid data view",
+			body: `{"title": "sample-index-*", "name": "Sample"}`,
+		},
+		{
+			name:    "missing title",
+			body:    `{"name": "Sample"}`,
+			wantErr: "spec.body.title: Required value",
+		},
+		{
+			name:    "body is an array",
+			body:    `[]`,
+			wantErr: "spec.body: Invalid value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataView := &kibanaeckv1alpha1.DataView{
+				ObjectMeta: metav1.ObjectMeta{Name: "dataview-sample"},
+				Spec: kibanaeckv1alpha1.DataViewSpec{
+					SavedObject: kibanaeckv1alpha1.SavedObject{Body: tt.body},
+				},
+			}
+
+			_, err := (&DataViewCustomValidator{}).ValidateCreate(context.Background(), dataView)
+
+			assertValidationError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidateDeleteAlwaysAllowed(t *testing.T) {
+	invalid := &kibanaeckv1alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "broken"}}
+
+	if _, err := (&DashboardCustomValidator{}).ValidateDelete(context.Background(), invalid); err != nil {
+		t.Errorf("ValidateDelete() error = %v, want nil", err)
+	}
+}
+
+func TestValidatorRejectsUnexpectedType(t *testing.T) {
+	if _, err := (&LensCustomValidator{}).ValidateCreate(context.Background(), &kibanaeckv1alpha1.Dashboard{}); err == nil {
+		t.Error("Expected an error for a Dashboard passed to the Lens validator")
+	}
+}
+
+func assertValidationError(t *testing.T, err error, wantErr string) {
+	t.Helper()
+	if wantErr == "" {
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		return
+	}
+	if err == nil {
+		t.Fatalf("Expected error containing %q, got nil", wantErr)
+	}
+	if !apierrors.IsInvalid(err) {
+		t.Errorf("Expected an Invalid API error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), wantErr) {
+		t.Errorf("Expected error containing %q, got %v", wantErr, err)
+	}
+}
+
+func TestValidateLocationChange(t *testing.T) {
+	space := "marketing"
+	deployed := &kibanaeckv1alpha1.SavedSearch{ObjectMeta: metav1.ObjectMeta{Name: "errors"}}
+	deployed.Spec.Body = `{"attributes":{"title":"Errors"}}`
+	deployed.Status.DeployedTo = &kibanaeckv1alpha1.SavedObjectLocation{}
+	validator := &BodyCustomValidator{Kind: "SavedSearch"}
+
+	moved := deployed.DeepCopy()
+	moved.Spec.Space = &space
+	moved.Spec.TargetConfig.KibanaInstance = "kibana-eu"
+	_, err := validator.ValidateUpdate(context.Background(), deployed, moved)
+	if !apierrors.IsInvalid(err) || !strings.Contains(err.Error(), "spec.space: Forbidden") || !strings.Contains(err.Error(), "spec.targetInstance: Forbidden") {
+		t.Errorf("Expected the move to be rejected, got %v", err)
+	}
+
+	moved.Spec.DeletionPolicy = kibanaeckv1alpha1.DeletionPolicyDelete
+	if _, err := validator.ValidateUpdate(context.Background(), deployed, moved); err != nil {
+		t.Errorf("Expected the move to be accepted with deletionPolicy Delete, got %v", err)
+	}
+
+	notDeployed := deployed.DeepCopy()
+	notDeployed.Status.DeployedTo = nil
+	moved.Spec.DeletionPolicy = ""
+	if _, err := validator.ValidateUpdate(context.Background(), notDeployed, moved); err != nil {
+		t.Errorf("Expected a saved object which was never deployed to be moved, got %v", err)
+	}
+
+	renamed := deployed.DeepCopy()
+	renamed.Spec.IDStrategy = kibanaeckv1alpha1.IDStrategyUUID
+	renamed.Spec.DeletionPolicy = kibanaeckv1alpha1.DeletionPolicyDelete
+	if _, err := validator.ValidateUpdate(context.Background(), deployed, renamed); !apierrors.IsInvalid(err) || !strings.Contains(err.Error(), "spec.idStrategy: Forbidden") {
+		t.Errorf("Expected the id change to be rejected, got %v", err)
+	}
+	renamed.Spec.IDStrategy = kibanaeckv1alpha1.IDStrategyName
+	if _, err := validator.ValidateUpdate(context.Background(), deployed, renamed); err != nil {
+		t.Errorf("Expected the explicit default id strategy to be accepted, got %v", err)
+	}
+
+	dashboard := &kibanaeckv1alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "kpis"}}
+	dashboard.Spec.Body = `{"attributes":{"title":"KPIs","panelsJSON":"[]"}}`
+	dashboard.Status.DeployedTo = &kibanaeckv1alpha1.SavedObjectLocation{}
+	movedDashboard := dashboard.DeepCopy()
+	movedDashboard.Spec.Space = &space
+	if _, err := (&DashboardCustomValidator{}).ValidateUpdate(context.Background(), dashboard, movedDashboard); !apierrors.IsInvalid(err) {
+		t.Errorf("Expected the Dashboard move to be rejected, got %v", err)
+	}
+}
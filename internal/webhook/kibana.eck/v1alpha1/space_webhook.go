@@ -0,0 +1,92 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/utils"
+	kibanaUtils "eck-custom-resources/utils/kibana"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupSpaceWebhookWithManager registers the webhook for Space in the manager.
+func SetupSpaceWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&kibanaeckv1alpha1.Space{}).
+		WithValidator(&SpaceCustomValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-kibana-eck-github-com-v1alpha1-space,mutating=false,failurePolicy=fail,sideEffects=None,groups=kibana.eck.github.com,resources=spaces,verbs=create;update,versions=v1alpha1,name=vspace-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// SpaceCustomValidator rejects Space resources managing the default space and warns when a space disables
+// critical features
+type SpaceCustomValidator struct{}
+
+var _ webhook.CustomValidator = &SpaceCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator
+func (v *SpaceCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	space, ok := obj.(*kibanaeckv1alpha1.Space)
+	if !ok {
+		return nil, fmt.Errorf("expected a Space object but got %T", obj)
+	}
+	return validateSpace(space, true)
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (v *SpaceCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	space, ok := newObj.(*kibanaeckv1alpha1.Space)
+	if !ok {
+		return nil, fmt.Errorf("expected a Space object for the newObj but got %T", newObj)
+	}
+	// The id of a space is its immutable name, a Space managing the default space was created before the check
+	// existed. It still has to be updatable, e.g. to remove its finalizer.
+	return validateSpace(space, false)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (v *SpaceCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateSpace(space *kibanaeckv1alpha1.Space, create bool) (admission.Warnings, error) {
+	allErrs := utils.ValidateBodyFields(space.BodyFields())
+	if create {
+		if err := kibanaUtils.VerifySpaceNotProtected(space.Name); err != nil {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("metadata").Child("name"), err.Error()))
+		}
+	}
+	if len(allErrs) > 0 {
+		return nil, invalidIfErrors("Space", space.Name, allErrs)
+	}
+
+	var warnings admission.Warnings
+	if disabled := kibanaUtils.GetDisabledCriticalFeatures(space.Spec.GetBody()); len(disabled) > 0 {
+		warnings = append(warnings, fmt.Sprintf("spec.body.disabledFeatures disables %s, the saved objects and settings of these features in space %s are no longer accessible in Kibana",
+			strings.Join(disabled, ", "), space.Name))
+	}
+	return warnings, nil
+}
@@ -0,0 +1,86 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSpaceCustomValidator(t *testing.T) {
+	tests := []struct {
+		name           string
+		spaceName      string
+		body           string
+		wantErr        bool
+		wantCreateOnly bool
+		wantWarnings   int
+	}{
+		{
+			name:      "regular space",
+			spaceName: "team-a",
+			body:      `{"name": "Team A", "disabledFeatures": ["apm"]}`,
+		},
+		{
+			name:      "default space",
+			spaceName: "default",
+			body:      `{"name": "Default"}`,
+			wantErr:   true,
+			// Existing resources are not locked, see TestSpaceCustomValidator_RemoveFinalizer
+			wantCreateOnly: true,
+		},
+		{
+			name:         "critical features disabled",
+			spaceName:    "team-a",
+			body:         `{"name": "Team A", "disabledFeatures": ["dashboard", "savedObjectsManagement"]}`,
+			wantWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			space := &kibanaeckv1alpha1.Space{
+				ObjectMeta: metav1.ObjectMeta{Name: tt.spaceName},
+				Spec:       kibanaeckv1alpha1.SpaceSpec{Body: tt.body},
+			}
+			validator := &SpaceCustomValidator{}
+
+			createWarnings, createErr := validator.ValidateCreate(context.Background(), space)
+			updateWarnings, updateErr := validator.ValidateUpdate(context.Background(), space, space)
+
+			for i, err := range []error{createErr, updateErr} {
+				wantErr := tt.wantErr && (i == 0 || !tt.wantCreateOnly)
+				if (err != nil) != wantErr {
+					t.Errorf("Validate error = %v, wantErr %v", err, wantErr)
+				}
+				if err != nil && !apierrors.IsInvalid(err) {
+					t.Errorf("Expected an Invalid API error, got %v", err)
+				}
+			}
+			if len(createWarnings) != tt.wantWarnings || len(updateWarnings) != tt.wantWarnings {
+				t.Errorf("Expected %d warnings, got %v and %v", tt.wantWarnings, createWarnings, updateWarnings)
+			}
+		})
+	}
+}
+
+func TestSpaceCustomValidator_RemoveFinalizer(t *testing.T) {
+	deletionTimestamp := metav1.Now()
+	oldSpace := &kibanaeckv1alpha1.Space{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "default",
+			Finalizers:        []string{"spaces.kibana.eck.github.com/finalizer"},
+			DeletionTimestamp: &deletionTimestamp,
+		},
+		Spec: kibanaeckv1alpha1.SpaceSpec{Body: `{"name": "Default"}`},
+	}
+	newSpace := oldSpace.DeepCopy()
+	newSpace.Finalizers = nil
+
+	if _, err := (&SpaceCustomValidator{}).ValidateUpdate(context.Background(), oldSpace, newSpace); err != nil {
+		t.Errorf("Expected the finalizer of a Space managing the default space to be removable, got %v", err)
+	}
+}
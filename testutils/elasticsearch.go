@@ -0,0 +1,1487 @@
+// Package testutils provides stateful in-memory doubles of the Elasticsearch and Kibana REST APIs, so that utils
+// and controllers can be tested against realistic request/response round trips without a running cluster.
+package testutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+// Resource kinds stored by FakeElasticsearch
+const (
+	ESIndex                   = "index"
+	ESIndexTemplate           = "index_template"
+	ESComponentTemplate       = "component_template"
+	ESIngestPipeline          = "ingest_pipeline"
+	ESIndexLifecyclePolicy    = "ilm_policy"
+	ESSnapshotLifecyclePolicy = "slm_policy"
+	ESSnapshotRepository      = "snapshot_repository"
+	ESRole                    = "role"
+	ESUser                    = "user"
+	ESAPIKey                  = "api_key"
+	ESAutoFollowPattern       = "auto_follow_pattern"
+)
+
+// RecordedRequest is a request received by a fake server
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+type injectedFailure struct {
+	method     string
+	path       string
+	statusCode int
+	body       string
+}
+
+// fakeServer holds the state shared by FakeElasticsearch and FakeKibana
+type fakeServer struct {
+	Server *httptest.Server
+
+	mu        sync.Mutex
+	resources map[string]map[string]json.RawMessage
+	versions  map[string]int
+	requests  []RecordedRequest
+	failures  []injectedFailure
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{resources: make(map[string]map[string]json.RawMessage), versions: make(map[string]int)}
+}
+
+// URL returns the base URL of the fake server
+func (f *fakeServer) URL() string {
+	return f.Server.URL
+}
+
+// Close shuts the fake server down
+func (f *fakeServer) Close() {
+	f.Server.Close()
+}
+
+// Get returns the stored body of the resource of the given kind
+func (f *fakeServer) Get(kind string, name string) (json.RawMessage, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body, ok := f.resources[kind][name]
+	return body, ok
+}
+
+// Exists reports whether a resource of the given kind is stored
+func (f *fakeServer) Exists(kind string, name string) bool {
+	_, ok := f.Get(kind, name)
+	return ok
+}
+
+// Put stores the body of a resource, e.g. to simulate resources created outside the operator
+func (f *fakeServer) Put(kind string, name string, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.put(kind, name, json.RawMessage(body))
+}
+
+func (f *fakeServer) put(kind string, name string, body json.RawMessage) {
+	if f.resources[kind] == nil {
+		f.resources[kind] = make(map[string]json.RawMessage)
+	}
+	f.resources[kind][name] = body
+	f.versions[kind+"/"+name]++
+}
+
+func (f *fakeServer) delete(kind string, name string) bool {
+	if _, ok := f.resources[kind][name]; !ok {
+		return false
+	}
+	delete(f.resources[kind], name)
+	delete(f.versions, kind+"/"+name)
+	return true
+}
+
+// Requests returns all requests received so far
+func (f *fakeServer) Requests() []RecordedRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]RecordedRequest(nil), f.requests...)
+}
+
+// CountRequests returns the number of received requests with the given method and path
+func (f *fakeServer) CountRequests(method string, path string) int {
+	count := 0
+	for _, request := range f.Requests() {
+		if request.Method == method && request.Path == path {
+			count++
+		}
+	}
+	return count
+}
+
+// FailRequests answers all requests with the given method and path with statusCode and body until ClearFailures
+// is called. An empty method matches all methods.
+func (f *fakeServer) FailRequests(method string, path string, statusCode int, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = append(f.failures, injectedFailure{method: method, path: path, statusCode: statusCode, body: body})
+}
+
+// ClearFailures removes all failures injected by FailRequests
+func (f *fakeServer) ClearFailures() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = nil
+}
+
+// record stores the request and returns the injected failure matching it, if any
+func (f *fakeServer) record(r *http.Request) (string, *injectedFailure) {
+	body, _ := io.ReadAll(r.Body)
+	f.requests = append(f.requests, RecordedRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Body: string(body)})
+	for _, failure := range f.failures {
+		if (failure.method == "" || failure.method == r.Method) && failure.path == r.URL.Path {
+			return string(body), &failure
+		}
+	}
+	return string(body), nil
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.WriteHeader(statusCode)
+	if raw, ok := body.(string); ok {
+		w.Write([]byte(raw))
+		return
+	}
+	encoded, _ := json.Marshal(body)
+	w.Write(encoded)
+}
+
+// FakeElasticsearch is a stateful in-memory double of the Elasticsearch REST API. It supports the endpoints used
+// by the operator: indices, index and component templates, ingest pipelines, ILM and SLM policies, snapshot
+// repositories and restores, roles, users, API keys, authentication and cluster privileges, index aliases, blocks, pipeline and allocation settings,
+// reindexing, shrink, split and clone, index recoveries, cross-cluster replication, resolving index patterns, the disk
+// allocation, the shards and their allocation explanations, the ingest statistics of the nodes and the cluster health.
+type FakeElasticsearch struct {
+	*fakeServer
+
+	clusterHealth     string
+	documentCounts    map[string]int
+	aliases           map[string]map[string]json.RawMessage
+	apiKeySequence    int
+	indexBlocks       map[string]map[string]bool
+	indexSettings     map[string]map[string]string
+	diskUsage         map[string]int
+	highWatermark     string
+	snapshots         map[string][]string
+	recoveries        map[string]fakeRecovery
+	remoteClusters    map[string]bool
+	followers         map[string]*fakeFollower
+	closedIndices     map[string]bool
+	pausedPatterns    map[string]bool
+	missingPrivileges map[string]bool
+	managedIndices    map[string]fakeManagedIndex
+	unassignedShards  map[string]string
+	ingestStats       map[string]map[string]FakeIngestStats
+	tasks             map[string]*fakeTask
+	holdTasks         bool
+}
+
+// fakeTask is a task started without waiting for its completion, e.g. a reindex
+type fakeTask struct {
+	completed bool
+	response  map[string]any
+}
+
+// FakeIngestStats are the ingest statistics of a pipeline on a node, failures are counted for the first processor
+type FakeIngestStats struct {
+	Count     int
+	Failed    int
+	Processor string
+}
+
+// fakeManagedIndex is an index managed by an index lifecycle policy, executing the phase of a version of the policy
+type fakeManagedIndex struct {
+	policy  string
+	version int
+}
+
+// fakeFollower is a follower index replicating a leader index of a remote cluster
+type fakeFollower struct {
+	remoteCluster string
+	leaderIndex   string
+	paused        bool
+	parameters    map[string]any
+}
+
+// fakeRecovery is the recovery of an index restored from a snapshot
+type fakeRecovery struct {
+	repository  string
+	snapshot    string
+	sourceIndex string
+	startTime   int64
+	done        bool
+}
+
+// NewFakeElasticsearch starts a FakeElasticsearch, it has to be closed by the caller
+func NewFakeElasticsearch() *FakeElasticsearch {
+	f := &FakeElasticsearch{
+		fakeServer:        newFakeServer(),
+		clusterHealth:     "green",
+		documentCounts:    make(map[string]int),
+		aliases:           make(map[string]map[string]json.RawMessage),
+		indexBlocks:       make(map[string]map[string]bool),
+		indexSettings:     make(map[string]map[string]string),
+		diskUsage:         make(map[string]int),
+		highWatermark:     "90%",
+		snapshots:         make(map[string][]string),
+		recoveries:        make(map[string]fakeRecovery),
+		remoteClusters:    make(map[string]bool),
+		followers:         make(map[string]*fakeFollower),
+		closedIndices:     make(map[string]bool),
+		pausedPatterns:    make(map[string]bool),
+		missingPrivileges: make(map[string]bool),
+		managedIndices:    make(map[string]fakeManagedIndex),
+		unassignedShards:  make(map[string]string),
+		ingestStats:       make(map[string]map[string]FakeIngestStats),
+		tasks:             make(map[string]*fakeTask),
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+// Client returns an Elasticsearch client connected to the fake server
+func (f *FakeElasticsearch) Client() (*elasticsearch.Client, error) {
+	return elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{f.URL()}})
+}
+
+// SetClusterHealth sets the status returned by the cluster health API
+func (f *FakeElasticsearch) SetClusterHealth(status string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.clusterHealth = status
+}
+
+// SetDocumentCount sets the number of documents reported for the index by the count API
+func (f *FakeElasticsearch) SetDocumentCount(index string, count int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.documentCounts[index] = count
+}
+
+// HoldTasks keeps the tasks started while hold is set running, until HoldTasks is called with false
+func (f *FakeElasticsearch) HoldTasks(hold bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.holdTasks = hold
+	if !hold {
+		for _, task := range f.tasks {
+			task.completed = true
+		}
+	}
+}
+
+// Alias returns the index the alias points to, the write index if it points to several indices
+func (f *FakeElasticsearch) Alias(name string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.resolveAlias(name)
+}
+
+func (f *FakeElasticsearch) resolveAlias(name string) (string, bool) {
+	for index, definition := range f.aliases[name] {
+		if len(f.aliases[name]) == 1 {
+			return index, true
+		}
+		var alias struct {
+			IsWriteIndex bool `json:"is_write_index"`
+		}
+		if json.Unmarshal(definition, &alias) == nil && alias.IsWriteIndex {
+			return index, true
+		}
+	}
+	return "", false
+}
+
+// IndexAliases returns the aliases of the index with their definitions
+func (f *FakeElasticsearch) IndexAliases(index string) map[string]json.RawMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.indexAliases(index)
+}
+
+func (f *FakeElasticsearch) indexAliases(index string) map[string]json.RawMessage {
+	aliases := map[string]json.RawMessage{}
+	for alias, indices := range f.aliases {
+		if definition, ok := indices[index]; ok {
+			aliases[alias] = definition
+		}
+	}
+	return aliases
+}
+
+func (f *FakeElasticsearch) putAlias(index string, alias string, definition json.RawMessage) {
+	if f.aliases[alias] == nil {
+		f.aliases[alias] = make(map[string]json.RawMessage)
+	}
+	if len(definition) == 0 {
+		definition = json.RawMessage(`{}`)
+	}
+	f.aliases[alias][index] = definition
+}
+
+func (f *FakeElasticsearch) removeAlias(index string, alias string) {
+	delete(f.aliases[alias], index)
+	if len(f.aliases[alias]) == 0 {
+		delete(f.aliases, alias)
+	}
+}
+
+// DocumentCount returns the number of documents of the index, as set by SetDocumentCount or moved by a reindex
+func (f *FakeElasticsearch) DocumentCount(index string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.documentCounts[index]
+}
+
+// SetIndexBlock sets or clears an index block, e.g. index.blocks.read_only_allow_delete as set by Elasticsearch on
+// disk pressure
+func (f *FakeElasticsearch) SetIndexBlock(index string, block string, set bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.setIndexBlock(index, block, set)
+}
+
+func (f *FakeElasticsearch) setIndexBlock(index string, block string, set bool) {
+	if f.indexBlocks[index] == nil {
+		f.indexBlocks[index] = make(map[string]bool)
+	}
+	if set {
+		f.indexBlocks[index][block] = true
+	} else {
+		delete(f.indexBlocks[index], block)
+	}
+}
+
+// IndexBlocks returns the blocks set on the index
+func (f *FakeElasticsearch) IndexBlocks(index string) map[string]bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	blocks := make(map[string]bool, len(f.indexBlocks[index]))
+	for block, set := range f.indexBlocks[index] {
+		blocks[block] = set
+	}
+	return blocks
+}
+
+// indexStringSettings are the index settings naming ingest pipelines and the node shards are allocated to stored by
+// the fake
+var indexStringSettings = [...]string{"index.default_pipeline", "index.final_pipeline", "index.routing.allocation.require._name"}
+
+// setIndexSetting stores the index blocks, pipelines and allocation of flat index settings, other settings are
+// ignored
+func (f *FakeElasticsearch) setIndexSetting(index string, setting string, value any) {
+	if !strings.HasPrefix(setting, "index.") {
+		setting = "index." + setting
+	}
+	switch {
+	case strings.HasPrefix(setting, "index.blocks."):
+		f.setIndexBlock(index, setting, value == true)
+	case slices.Contains(indexStringSettings[:], setting):
+		if f.indexSettings[index] == nil {
+			f.indexSettings[index] = make(map[string]string)
+		}
+		if text, ok := value.(string); ok {
+			f.indexSettings[index][setting] = text
+		} else {
+			delete(f.indexSettings[index], setting)
+		}
+	}
+}
+
+// IndexSetting returns the ingest pipeline or allocation setting of the index, e.g. index.default_pipeline
+func (f *FakeElasticsearch) IndexSetting(index string, setting string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.indexSettings[index][setting]
+}
+
+// SetRemoteCluster configures a remote cluster as reported by the remote info API
+func (f *FakeElasticsearch) SetRemoteCluster(alias string, connected bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.remoteClusters[alias] = connected
+}
+
+// Follower returns the remote cluster, the leader index and the status of the follower index
+func (f *FakeElasticsearch) Follower(index string) (remoteCluster string, leaderIndex string, status string, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	follower, ok := f.followers[index]
+	if !ok {
+		return "", "", "", false
+	}
+	return follower.remoteCluster, follower.leaderIndex, follower.status(), true
+}
+
+// AutoFollowPatternPaused reports whether the auto-follow pattern is paused
+func (f *FakeElasticsearch) AutoFollowPatternPaused(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pausedPatterns[name]
+}
+
+func (follower *fakeFollower) status() string {
+	if follower.paused {
+		return "paused"
+	}
+	return "active"
+}
+
+// SetDiskUsage sets the disk usage of the node in percent, as reported by the cat allocation API
+func (f *FakeElasticsearch) SetDiskUsage(node string, percent int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.diskUsage[node] = percent
+}
+
+// SetIngestStats sets the ingest statistics of the pipeline on the node, as reported by the nodes stats API
+func (f *FakeElasticsearch) SetIngestStats(node string, pipeline string, stats FakeIngestStats) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.ingestStats[node] == nil {
+		f.ingestStats[node] = make(map[string]FakeIngestStats)
+	}
+	f.ingestStats[node][pipeline] = stats
+}
+
+// SetAPIKeyState sets the expiration and invalidation state of the API key, a zero expiration never expires
+func (f *FakeElasticsearch) SetAPIKeyState(id string, expiration time.Time, invalidated bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var key map[string]any
+	if err := json.Unmarshal(f.resources[ESAPIKey][id], &key); err != nil {
+		return
+	}
+	delete(key, "expiration")
+	if !expiration.IsZero() {
+		key["expiration"] = expiration.UnixMilli()
+	}
+	key["invalidated"] = invalidated
+	f.resources[ESAPIKey][id], _ = json.Marshal(key)
+}
+
+// AddSnapshot adds a snapshot of the indices to the repository, so that it can be restored
+func (f *FakeElasticsearch) AddSnapshot(repository string, snapshot string, indices ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.snapshots[repository+"/"+snapshot] = indices
+}
+
+// SetMissingPrivileges makes the has privileges API report the cluster privileges as missing, replacing the ones
+// set before
+func (f *FakeElasticsearch) SetMissingPrivileges(privileges ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.missingPrivileges = make(map[string]bool)
+	for _, privilege := range privileges {
+		f.missingPrivileges[privilege] = true
+	}
+}
+
+// SetManagedIndex makes the explain lifecycle API report the index as managed by the index lifecycle policy, executing
+// its phase from the version of the policy; versions start at 1 and are incremented by every update of the policy
+func (f *FakeElasticsearch) SetManagedIndex(index string, policy string, version int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.managedIndices[index] = fakeManagedIndex{policy: policy, version: version}
+}
+
+// SetUnassignedReplica makes the shards API report an unassigned replica of shard 0 of the index, which the cluster
+// allocation explain API explains with explanation; an empty explanation assigns the replica
+func (f *FakeElasticsearch) SetUnassignedReplica(index string, explanation string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if explanation == "" {
+		delete(f.unassignedShards, index)
+		return
+	}
+	f.unassignedShards[index] = explanation
+}
+
+// FinishRecoveries completes the recoveries of all indices restored from snapshots, which stay in the INDEX stage
+// until then
+func (f *FakeElasticsearch) FinishRecoveries() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for index, recovery := range f.recoveries {
+		recovery.done = true
+		f.recoveries[index] = recovery
+	}
+}
+
+func (f *FakeElasticsearch) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+
+	body, failure := f.record(r)
+	if failure != nil {
+		writeJSON(w, failure.statusCode, failure.body)
+		return
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/":
+		writeJSON(w, http.StatusOK, `{"version": {"number": "8.15.0"}, "tagline": "You Know, for Search"}`)
+	case r.URL.Path == "/_cluster/health":
+		writeJSON(w, http.StatusOK, map[string]string{"status": f.clusterHealth})
+	case len(segments) == 3 && segments[0] == "_cluster" && segments[1] == "health":
+		if _, exists := f.resources[ESIndex][segments[2]]; !exists {
+			notFound(w, ESIndex, segments[2])
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"status": f.clusterHealth, "initializing_shards": 0})
+	case len(segments) == 3 && segments[0] == "_cat" && segments[1] == "shards":
+		f.handleCatShards(w, segments[2])
+	case r.URL.Path == "/_cluster/allocation/explain" && r.Method == http.MethodPost:
+		f.handleAllocationExplain(w, body)
+	case r.URL.Path == "/_cluster/settings" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"persistent": map[string]any{}, "transient": map[string]any{},
+			"defaults": map[string]any{"cluster.routing.allocation.disk.watermark.high": f.highWatermark}})
+	case r.URL.Path == "/_cat/allocation":
+		f.handleCatAllocation(w)
+	case r.URL.Path == "/_nodes/stats/ingest" && r.Method == http.MethodGet:
+		f.handleIngestStats(w)
+	case r.URL.Path == "/_index_template" && r.Method == http.MethodGet:
+		f.handleListIndexTemplates(w)
+	case len(segments) == 3 && segments[0] == "_resolve" && segments[1] == "index" && r.Method == http.MethodGet:
+		f.handleResolveIndex(w, strings.Split(segments[2], ","))
+	case len(segments) == 2 && segments[0] == "_index_template":
+		f.handleResource(w, r, ESIndexTemplate, segments[1], body, func(name string, stored json.RawMessage) any {
+			return map[string]any{"index_templates": []any{map[string]any{"name": name, "index_template": stored}}}
+		})
+	case len(segments) == 2 && segments[0] == "_component_template":
+		f.handleResource(w, r, ESComponentTemplate, segments[1], body, func(name string, stored json.RawMessage) any {
+			return map[string]any{"component_templates": []any{map[string]any{"name": name, "component_template": stored}}}
+		})
+	case len(segments) == 3 && segments[0] == "_ingest" && segments[1] == "pipeline" && segments[2] == "_simulate":
+		f.handleSimulate(w, body)
+	case len(segments) == 4 && segments[0] == "_ingest" && segments[1] == "pipeline" && segments[3] == "_simulate":
+		if _, exists := f.resources[ESIngestPipeline][segments[2]]; !exists {
+			notFound(w, ESIngestPipeline, segments[2])
+			return
+		}
+		f.handleSimulate(w, body)
+	case len(segments) == 3 && segments[0] == "_ingest" && segments[1] == "pipeline":
+		f.handleResource(w, r, ESIngestPipeline, segments[2], body, keyedByName)
+	case len(segments) == 3 && segments[0] == "_ilm" && segments[1] == "policy":
+		f.handleResource(w, r, ESIndexLifecyclePolicy, segments[2], body, func(name string, stored json.RawMessage) any {
+			var policy map[string]any
+			_ = json.Unmarshal(stored, &policy)
+			policy["version"] = f.versions[ESIndexLifecyclePolicy+"/"+name]
+			return map[string]any{name: policy}
+		})
+	case len(segments) == 3 && segments[1] == "_ilm" && segments[2] == "explain" && r.Method == http.MethodGet:
+		f.handleExplainLifecycle(w)
+	case len(segments) == 3 && segments[0] == "_slm" && segments[1] == "policy":
+		f.handleResource(w, r, ESSnapshotLifecyclePolicy, segments[2], body, keyedByName)
+	case len(segments) == 2 && segments[0] == "_snapshot":
+		f.handleResource(w, r, ESSnapshotRepository, segments[1], body, keyedByName)
+	case len(segments) == 4 && segments[0] == "_snapshot" && segments[3] == "_restore" && r.Method == http.MethodPost:
+		f.handleRestore(w, segments[1], segments[2], body)
+	case r.URL.Path == "/_recovery" && r.Method == http.MethodGet:
+		f.handleRecovery(w)
+	case r.URL.Path == "/_security/_authenticate" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, `{"username": "elastic", "roles": ["superuser"]}`)
+	case r.URL.Path == "/_security/user/_has_privileges":
+		f.handleHasPrivileges(w, body)
+	case len(segments) == 3 && segments[0] == "_security" && segments[1] == "role" && r.Method == http.MethodGet &&
+		strings.Contains(segments[2], ","):
+		f.handleGetRoles(w, strings.Split(segments[2], ","))
+	case len(segments) == 3 && segments[0] == "_security" && segments[1] == "role":
+		f.handleResource(w, r, ESRole, segments[2], body, keyedByName)
+	case len(segments) == 3 && segments[0] == "_security" && segments[1] == "user":
+		f.handleResource(w, r, ESUser, segments[2], body, func(name string, stored json.RawMessage) any {
+			var user map[string]any
+			_ = json.Unmarshal(stored, &user)
+			user["username"] = name
+			return map[string]any{name: user}
+		})
+	case len(segments) == 4 && segments[0] == "_security" && segments[1] == "user" &&
+		(segments[3] == "_enable" || segments[3] == "_disable"):
+		f.handleUserEnabled(w, segments[2], segments[3] == "_enable")
+	case len(segments) >= 2 && segments[0] == "_security" && segments[1] == "api_key":
+		f.handleAPIKey(w, r, segments[2:], body)
+	case r.URL.Path == "/_remote/info" && r.Method == http.MethodGet:
+		f.handleRemoteInfo(w)
+	case len(segments) == 3 && segments[1] == "_ccr":
+		f.handleFollow(w, r, segments[0], segments[2], body)
+	case len(segments) >= 3 && segments[0] == "_ccr" && segments[1] == "auto_follow":
+		f.handleAutoFollowPattern(w, r, segments[2:], body)
+	case len(segments) == 2 && (segments[1] == "_close" || segments[1] == "_open") && r.Method == http.MethodPost:
+		if _, exists := f.resources[ESIndex][segments[0]]; !exists {
+			notFound(w, ESIndex, segments[0])
+			return
+		}
+		f.closedIndices[segments[0]] = segments[1] == "_close"
+		writeJSON(w, http.StatusOK, `{"acknowledged": true}`)
+	case r.URL.Path == "/_reindex" && r.Method == http.MethodPost:
+		f.handleReindex(w, body, r.URL.Query().Get("wait_for_completion") == "false")
+	case len(segments) == 2 && segments[0] == "_tasks" && r.Method == http.MethodGet:
+		f.handleGetTask(w, segments[1])
+	case len(segments) == 3 && (segments[1] == "_shrink" || segments[1] == "_split" || segments[1] == "_clone") &&
+		(r.Method == http.MethodPut || r.Method == http.MethodPost):
+		f.handleResize(w, segments[0], segments[2], body)
+	case len(segments) == 3 && segments[1] == "_aliases" && r.Method == http.MethodPut:
+		f.handleAlias(w, segments[0], segments[2], body)
+	case r.URL.Path == "/_aliases" && r.Method == http.MethodPost:
+		f.handleUpdateAliases(w, body)
+	case len(segments) == 2 && segments[0] == "_alias" && r.Method == http.MethodGet:
+		f.handleGetAlias(w, "", segments[1])
+	case len(segments) == 2 && segments[1] == "_alias" && r.Method == http.MethodGet:
+		f.handleGetAlias(w, segments[0], "")
+	case len(segments) == 2 && segments[1] == "_count":
+		f.handleCount(w, segments[0])
+	case len(segments) >= 2 && segments[1] == "_settings":
+		f.handleSettings(w, r, segments[0], body)
+	case segments[0] == "_settings" && r.Method == http.MethodGet:
+		f.handleSettings(w, r, "", body)
+	case len(segments) == 2 && segments[1] == "_mapping":
+		f.handleMapping(w, r, segments[0], body)
+	case len(segments) == 1 && !strings.HasPrefix(segments[0], "_"):
+		f.handleIndex(w, r, segments[0], body)
+	default:
+		writeJSON(w, http.StatusBadRequest, fmt.Sprintf(`{"error": {"type": "fake_unsupported_endpoint", "reason": "%s %s"}, "status": 400}`, r.Method, r.URL.Path))
+	}
+}
+
+// handleExplainLifecycle returns the indices set with SetManagedIndex, regardless of the target
+func (f *FakeElasticsearch) handleExplainLifecycle(w http.ResponseWriter) {
+	indices := make(map[string]any, len(f.managedIndices))
+	for index, managed := range f.managedIndices {
+		indices[index] = map[string]any{
+			"index":           index,
+			"managed":         true,
+			"policy":          managed.policy,
+			"phase_execution": map[string]any{"policy": managed.policy, "version": managed.version},
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"indices": indices})
+}
+
+func keyedByName(name string, stored json.RawMessage) any {
+	return map[string]json.RawMessage{name: stored}
+}
+
+func notFound(w http.ResponseWriter, kind string, name string) {
+	writeJSON(w, http.StatusNotFound, fmt.Sprintf(`{"error": {"type": "resource_not_found_exception", "reason": "%s [%s] missing"}, "status": 404}`, kind, name))
+}
+
+// handleGetRoles returns the existing roles of a comma separated list, like Elasticsearch it responds with 404 only if
+// none of them exists
+func (f *FakeElasticsearch) handleGetRoles(w http.ResponseWriter, names []string) {
+	found := map[string]json.RawMessage{}
+	for _, name := range names {
+		if stored, ok := f.resources[ESRole][name]; ok {
+			found[name] = stored
+		}
+	}
+	if len(found) == 0 {
+		writeJSON(w, http.StatusNotFound, `{}`)
+		return
+	}
+	writeJSON(w, http.StatusOK, found)
+}
+
+// handleResource implements HEAD, GET, PUT/POST and DELETE of a named resource
+func (f *FakeElasticsearch) handleResource(w http.ResponseWriter, r *http.Request, kind string, name string, body string,
+	getResponse func(name string, stored json.RawMessage) any) {
+	stored, exists := f.resources[kind][name]
+	switch r.Method {
+	case http.MethodHead:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		if !exists {
+			notFound(w, kind, name)
+			return
+		}
+		writeJSON(w, http.StatusOK, getResponse(name, stored))
+	case http.MethodPut, http.MethodPost:
+		if !json.Valid([]byte(body)) {
+			writeJSON(w, http.StatusBadRequest, `{"error": {"type": "parse_exception", "reason": "request body is not valid JSON"}, "status": 400}`)
+			return
+		}
+		f.put(kind, name, json.RawMessage(body))
+		if kind == ESRole || kind == ESUser {
+			writeJSON(w, http.StatusOK, map[string]any{kind: map[string]bool{"created": !exists}, "created": !exists})
+			return
+		}
+		writeJSON(w, http.StatusOK, `{"acknowledged": true}`)
+	case http.MethodDelete:
+		if !f.delete(kind, name) {
+			notFound(w, kind, name)
+			return
+		}
+		writeJSON(w, http.StatusOK, `{"acknowledged": true, "found": true}`)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *FakeElasticsearch) handleIndex(w http.ResponseWriter, r *http.Request, name string, body string) {
+	if index, ok := f.resolveAlias(name); ok && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+		// An alias resolves to the index it points to, which is returned under its own name
+		f.handleResource(w, r, ESIndex, index, body, keyedByName)
+		return
+	}
+	_, exists := f.resources[ESIndex][name]
+	if r.Method == http.MethodPut && exists {
+		writeJSON(w, http.StatusBadRequest, fmt.Sprintf(`{"error": {"type": "resource_already_exists_exception", "reason": "index [%s] already exists"}, "status": 400}`, name))
+		return
+	}
+	if r.Method == http.MethodPut && body == "" {
+		body = "{}"
+	}
+	if r.Method == http.MethodDelete {
+		f.deleteIndexState(name)
+	}
+	if r.Method == http.MethodPut {
+		var index struct {
+			Settings map[string]any             `json:"settings"`
+			Aliases  map[string]json.RawMessage `json:"aliases"`
+		}
+		_ = json.Unmarshal([]byte(body), &index)
+		for setting, value := range index.Settings {
+			f.setIndexSetting(name, setting, value)
+		}
+		for alias, definition := range index.Aliases {
+			f.putAlias(name, alias, definition)
+		}
+	}
+	f.handleResource(w, r, ESIndex, name, body, keyedByName)
+}
+
+// deleteIndexState removes the documents, settings and aliases of a deleted index
+func (f *FakeElasticsearch) deleteIndexState(name string) {
+	delete(f.documentCounts, name)
+	delete(f.indexBlocks, name)
+	delete(f.indexSettings, name)
+	delete(f.followers, name)
+	delete(f.closedIndices, name)
+	delete(f.recoveries, name)
+	for alias := range f.indexAliases(name) {
+		f.removeAlias(name, alias)
+	}
+}
+
+// handleSettings returns the index blocks, pipelines and allocation as flat settings on GET and stores those of the
+// update on PUT, other settings are accepted but not stored. name is a comma separated list of
+// indices, all indices if empty.
+func (f *FakeElasticsearch) handleSettings(w http.ResponseWriter, r *http.Request, name string, body string) {
+	indices := slices.Sorted(maps.Keys(f.resources[ESIndex]))
+	if name != "" && name != "_all" {
+		indices = strings.Split(name, ",")
+	}
+	for _, index := range indices {
+		if _, exists := f.resources[ESIndex][index]; !exists {
+			notFound(w, ESIndex, index)
+			return
+		}
+	}
+	if r.Method == http.MethodGet {
+		response := make(map[string]any, len(indices))
+		for _, index := range indices {
+			settings := map[string]string{}
+			for block, set := range f.indexBlocks[index] {
+				settings[block] = fmt.Sprint(set)
+			}
+			maps.Copy(settings, f.indexSettings[index])
+			response[index] = map[string]any{"settings": settings}
+		}
+		writeJSON(w, http.StatusOK, response)
+		return
+	}
+	var update map[string]any
+	if err := json.Unmarshal([]byte(body), &update); err != nil {
+		writeJSON(w, http.StatusBadRequest, `{"error": {"type": "parse_exception", "reason": "request body is not valid JSON"}, "status": 400}`)
+		return
+	}
+	for _, index := range indices {
+		for setting, value := range update {
+			f.setIndexSetting(index, setting, value)
+		}
+	}
+	writeJSON(w, http.StatusOK, `{"acknowledged": true}`)
+}
+
+// handleRemoteInfo reports the remote clusters set by SetRemoteCluster
+func (f *FakeElasticsearch) handleRemoteInfo(w http.ResponseWriter) {
+	remotes := make(map[string]any, len(f.remoteClusters))
+	for alias, connected := range f.remoteClusters {
+		remotes[alias] = map[string]any{"connected": connected, "mode": "proxy"}
+	}
+	writeJSON(w, http.StatusOK, remotes)
+}
+
+func ccrError(w http.ResponseWriter, reason string) {
+	writeJSON(w, http.StatusBadRequest, fmt.Sprintf(`{"error": {"type": "illegal_argument_exception", "reason": "%s"}, "status": 400}`, reason))
+}
+
+// handleFollow implements the follow, pause, resume, unfollow and info APIs of follower indices. Following creates
+// the index, replication itself is not simulated.
+func (f *FakeElasticsearch) handleFollow(w http.ResponseWriter, r *http.Request, index string, action string, body string) {
+	follower, following := f.followers[index]
+	_, exists := f.resources[ESIndex][index]
+	switch {
+	case action == "follow" && r.Method == http.MethodPut:
+		var request struct {
+			RemoteCluster string `json:"remote_cluster"`
+			LeaderIndex   string `json:"leader_index"`
+		}
+		var parameters map[string]any
+		if json.Unmarshal([]byte(body), &request) != nil || json.Unmarshal([]byte(body), &parameters) != nil {
+			writeJSON(w, http.StatusBadRequest, `{"error": {"type": "parse_exception", "reason": "request body is not valid JSON"}, "status": 400}`)
+			return
+		}
+		if connected, ok := f.remoteClusters[request.RemoteCluster]; !ok || !connected {
+			ccrError(w, fmt.Sprintf("unknown cluster alias [%s]", request.RemoteCluster))
+			return
+		}
+		if exists {
+			writeJSON(w, http.StatusBadRequest, fmt.Sprintf(`{"error": {"type": "resource_already_exists_exception", "reason": "index [%s] already exists"}, "status": 400}`, index))
+			return
+		}
+		delete(parameters, "remote_cluster")
+		delete(parameters, "leader_index")
+		delete(parameters, "settings")
+		f.put(ESIndex, index, json.RawMessage(`{}`))
+		f.followers[index] = &fakeFollower{remoteCluster: request.RemoteCluster, leaderIndex: request.LeaderIndex, parameters: parameters}
+		writeJSON(w, http.StatusOK, `{"follow_index_created": true, "follow_index_shards_acked": true, "index_following_started": true}`)
+	case action == "info" && r.Method == http.MethodGet:
+		if !exists {
+			notFound(w, ESIndex, index)
+			return
+		}
+		followers := []any{}
+		if following {
+			info := map[string]any{"follower_index": index, "remote_cluster": follower.remoteCluster,
+				"leader_index": follower.leaderIndex, "status": follower.status()}
+			if !follower.paused {
+				info["parameters"] = follower.parameters
+			}
+			followers = append(followers, info)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"follower_indices": followers})
+	case !following:
+		ccrError(w, fmt.Sprintf("index [%s] is not a follower index", index))
+	case action == "pause_follow" && r.Method == http.MethodPost:
+		if follower.paused {
+			ccrError(w, fmt.Sprintf("no shard follow tasks for [%s]", index))
+			return
+		}
+		follower.paused = true
+		writeJSON(w, http.StatusOK, `{"acknowledged": true}`)
+	case action == "resume_follow" && r.Method == http.MethodPost:
+		if !follower.paused {
+			ccrError(w, fmt.Sprintf("shard follow tasks for [%s] are already running", index))
+			return
+		}
+		parameters := map[string]any{}
+		if body != "" && json.Unmarshal([]byte(body), &parameters) != nil {
+			writeJSON(w, http.StatusBadRequest, `{"error": {"type": "parse_exception", "reason": "request body is not valid JSON"}, "status": 400}`)
+			return
+		}
+		follower.paused = false
+		follower.parameters = parameters
+		writeJSON(w, http.StatusOK, `{"acknowledged": true}`)
+	case action == "unfollow" && r.Method == http.MethodPost:
+		if !follower.paused || !f.closedIndices[index] {
+			ccrError(w, fmt.Sprintf("cannot convert the follower index [%s] to a non-follower, because it has not been paused and closed", index))
+			return
+		}
+		delete(f.followers, index)
+		writeJSON(w, http.StatusOK, `{"acknowledged": true}`)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAutoFollowPattern implements the auto-follow pattern APIs. Like Elasticsearch, an update keeps the pattern
+// paused or active.
+func (f *FakeElasticsearch) handleAutoFollowPattern(w http.ResponseWriter, r *http.Request, segments []string, body string) {
+	name := segments[0]
+	if len(segments) == 2 && r.Method == http.MethodPost && (segments[1] == "pause" || segments[1] == "resume") {
+		if _, exists := f.resources[ESAutoFollowPattern][name]; !exists {
+			notFound(w, ESAutoFollowPattern, name)
+			return
+		}
+		f.pausedPatterns[name] = segments[1] == "pause"
+		writeJSON(w, http.StatusOK, `{"acknowledged": true}`)
+		return
+	}
+	if len(segments) != 1 {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if r.Method == http.MethodPut {
+		var request struct {
+			RemoteCluster string `json:"remote_cluster"`
+		}
+		_ = json.Unmarshal([]byte(body), &request)
+		if _, ok := f.remoteClusters[request.RemoteCluster]; !ok {
+			ccrError(w, fmt.Sprintf("unknown cluster alias [%s]", request.RemoteCluster))
+			return
+		}
+	}
+	if r.Method == http.MethodDelete {
+		delete(f.pausedPatterns, name)
+	}
+	f.handleResource(w, r, ESAutoFollowPattern, name, body, func(name string, stored json.RawMessage) any {
+		var pattern map[string]any
+		_ = json.Unmarshal(stored, &pattern)
+		pattern["active"] = !f.pausedPatterns[name]
+		return map[string]any{"patterns": []any{map[string]any{"name": name, "pattern": pattern}}}
+	})
+}
+
+// handleCatAllocation reports the disk usage set by SetDiskUsage for nodes with 100 bytes of disk
+func (f *FakeElasticsearch) handleCatAllocation(w http.ResponseWriter) {
+	nodes := []map[string]string{}
+	for node, percent := range f.diskUsage {
+		nodes = append(nodes, map[string]string{"node": node, "disk.used": fmt.Sprint(percent),
+			"disk.avail": fmt.Sprint(100 - percent), "disk.total": "100", "disk.percent": fmt.Sprint(percent)})
+	}
+	writeJSON(w, http.StatusOK, nodes)
+}
+
+// handleIngestStats reports the ingest statistics set by SetIngestStats, ignoring filter_path
+func (f *FakeElasticsearch) handleIngestStats(w http.ResponseWriter) {
+	nodes := map[string]any{}
+	for node, pipelines := range f.ingestStats {
+		stats := map[string]any{}
+		for pipeline, pipelineStats := range pipelines {
+			processors := []any{}
+			if pipelineStats.Processor != "" {
+				processors = append(processors, map[string]any{pipelineStats.Processor: map[string]any{
+					"type":  pipelineStats.Processor,
+					"stats": map[string]any{"count": pipelineStats.Count, "failed": pipelineStats.Failed, "current": 0, "time_in_millis": 0},
+				}})
+			}
+			stats[pipeline] = map[string]any{"count": pipelineStats.Count, "failed": pipelineStats.Failed, "current": 0,
+				"time_in_millis": pipelineStats.Count, "processors": processors}
+		}
+		nodes[node] = map[string]any{"ingest": map[string]any{"pipelines": stats}}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"nodes": nodes})
+}
+
+// handleMapping returns the mappings of the index on GET and merges runtime fields and properties into them on PUT.
+// Runtime fields set to null are removed, as in Elasticsearch.
+func (f *FakeElasticsearch) handleMapping(w http.ResponseWriter, r *http.Request, name string, body string) {
+	stored, exists := f.resources[ESIndex][name]
+	if !exists {
+		notFound(w, ESIndex, name)
+		return
+	}
+	var index map[string]any
+	_ = json.Unmarshal(stored, &index)
+	mappings, _ := index["mappings"].(map[string]any)
+	if mappings == nil {
+		mappings = map[string]any{}
+	}
+
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, map[string]any{name: map[string]any{"mappings": mappings}})
+		return
+	}
+
+	var update map[string]any
+	if err := json.Unmarshal([]byte(body), &update); err != nil {
+		writeJSON(w, http.StatusBadRequest, `{"error": {"type": "parse_exception", "reason": "request body is not valid JSON"}, "status": 400}`)
+		return
+	}
+	for _, section := range []string{"runtime", "properties"} {
+		fields, ok := update[section].(map[string]any)
+		if !ok {
+			continue
+		}
+		current, _ := mappings[section].(map[string]any)
+		if current == nil {
+			current = map[string]any{}
+		}
+		for field, definition := range fields {
+			if definition == nil {
+				delete(current, field)
+			} else {
+				current[field] = definition
+			}
+		}
+		mappings[section] = current
+	}
+	index["mappings"] = mappings
+	updated, _ := json.Marshal(index)
+	f.put(ESIndex, name, updated)
+	writeJSON(w, http.StatusOK, `{"acknowledged": true}`)
+}
+
+// handleCatShards reports the index with a single started primary shard, allocated to the node required by
+// index.routing.allocation.require._name or node-0
+func (f *FakeElasticsearch) handleCatShards(w http.ResponseWriter, index string) {
+	if _, exists := f.resources[ESIndex][index]; !exists {
+		notFound(w, ESIndex, index)
+		return
+	}
+	node := f.indexSettings[index]["index.routing.allocation.require._name"]
+	if node == "" {
+		node = "node-0"
+	}
+	shards := []map[string]string{{"index": index, "shard": "0", "prirep": "p", "state": "STARTED", "node": node}}
+	if _, unassigned := f.unassignedShards[index]; unassigned {
+		shards = append(shards, map[string]string{"index": index, "shard": "0", "prirep": "r", "state": "UNASSIGNED", "node": ""})
+	}
+	writeJSON(w, http.StatusOK, shards)
+}
+
+// handleAllocationExplain explains the unassigned replica set by SetUnassignedReplica, other shards are assigned
+func (f *FakeElasticsearch) handleAllocationExplain(w http.ResponseWriter, body string) {
+	var request struct {
+		Index   string `json:"index"`
+		Shard   int    `json:"shard"`
+		Primary bool   `json:"primary"`
+	}
+	_ = json.Unmarshal([]byte(body), &request)
+	explanation, unassigned := f.unassignedShards[request.Index]
+	if !unassigned || request.Shard != 0 || request.Primary {
+		writeJSON(w, http.StatusBadRequest, `{"error": {"type": "illegal_argument_exception", "reason": "the shard is assigned"}, "status": 400}`)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"index": request.Index, "shard": request.Shard, "primary": request.Primary,
+		"current_state": "unassigned", "unassigned_info": map[string]any{"reason": "INDEX_CREATED"},
+		"can_allocate": "no", "allocate_explanation": explanation})
+}
+
+// handleResize creates the target index of a shrink, split or clone with the blocks, settings and document count of
+// the source, which has to be write blocked. The settings of the request override those of the source.
+func (f *FakeElasticsearch) handleResize(w http.ResponseWriter, source string, target string, body string) {
+	if _, exists := f.resources[ESIndex][source]; !exists {
+		notFound(w, ESIndex, source)
+		return
+	}
+	if !f.indexBlocks[source]["index.blocks.write"] {
+		writeJSON(w, http.StatusBadRequest, fmt.Sprintf(`{"error": {"type": "illegal_state_exception", "reason": "index %s must be read-only to resize index. use \"index.blocks.write=true\""}, "status": 400}`, source))
+		return
+	}
+	if _, exists := f.resources[ESIndex][target]; exists {
+		writeJSON(w, http.StatusBadRequest, fmt.Sprintf(`{"error": {"type": "resource_already_exists_exception", "reason": "index [%s] already exists"}, "status": 400}`, target))
+		return
+	}
+	var request struct {
+		Settings map[string]any `json:"settings"`
+	}
+	if body != "" {
+		if err := json.Unmarshal([]byte(body), &request); err != nil {
+			writeJSON(w, http.StatusBadRequest, `{"error": {"type": "parse_exception", "reason": "request body is not valid JSON"}, "status": 400}`)
+			return
+		}
+	}
+	for block, set := range f.indexBlocks[source] {
+		f.setIndexBlock(target, block, set)
+	}
+	for setting, value := range f.indexSettings[source] {
+		f.setIndexSetting(target, setting, value)
+	}
+	for setting, value := range request.Settings {
+		f.setIndexSetting(target, setting, value)
+	}
+	f.documentCounts[target] = f.documentCounts[source]
+	f.put(ESIndex, target, json.RawMessage(`{}`))
+	writeJSON(w, http.StatusOK, fmt.Sprintf(`{"acknowledged": true, "shards_acknowledged": true, "index": "%s"}`, target))
+}
+
+// handleReindex moves the document count of the source index to the destination index. Without waiting for the
+// completion the response is stored in a task, which is completed unless tasks are held, see HoldTasks.
+func (f *FakeElasticsearch) handleReindex(w http.ResponseWriter, body string, async bool) {
+	var request struct {
+		Source struct {
+			Index string `json:"index"`
+		} `json:"source"`
+		Dest struct {
+			Index string `json:"index"`
+		} `json:"dest"`
+	}
+	_ = json.Unmarshal([]byte(body), &request)
+	for _, name := range []string{request.Source.Index, request.Dest.Index} {
+		if _, exists := f.resources[ESIndex][name]; !exists {
+			notFound(w, ESIndex, name)
+			return
+		}
+	}
+	count := f.documentCounts[request.Source.Index]
+	f.documentCounts[request.Dest.Index] += count
+	response := map[string]any{"total": count, "created": count, "failures": []any{}}
+	if async {
+		id := fmt.Sprintf("fake-node:%d", len(f.tasks)+1)
+		f.tasks[id] = &fakeTask{completed: !f.holdTasks, response: response}
+		writeJSON(w, http.StatusOK, map[string]any{"task": id})
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleGetTask returns the status of a task and its response once it is completed
+func (f *FakeElasticsearch) handleGetTask(w http.ResponseWriter, id string) {
+	task, exists := f.tasks[id]
+	if !exists {
+		writeJSON(w, http.StatusNotFound, fmt.Sprintf(`{"error": {"type": "resource_not_found_exception", "reason": "task [%s] isn't running and hasn't stored its results"}, "status": 404}`, id))
+		return
+	}
+	response := map[string]any{"completed": task.completed, "task": map[string]any{"action": "indices:data/write/reindex"}}
+	if task.completed {
+		response["response"] = task.response
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleRestore creates the indices of the snapshot matching the request, renamed by its rename pattern, and starts
+// their recovery
+func (f *FakeElasticsearch) handleRestore(w http.ResponseWriter, repository string, snapshot string, body string) {
+	if _, exists := f.resources[ESSnapshotRepository][repository]; !exists {
+		writeJSON(w, http.StatusNotFound, fmt.Sprintf(`{"error": {"type": "repository_missing_exception", "reason": "[%s] missing"}, "status": 404}`, repository))
+		return
+	}
+	indices, exists := f.snapshots[repository+"/"+snapshot]
+	if !exists {
+		writeJSON(w, http.StatusNotFound, fmt.Sprintf(`{"error": {"type": "snapshot_missing_exception", "reason": "[%s:%s] is missing"}, "status": 404}`, repository, snapshot))
+		return
+	}
+	var request struct {
+		Indices           []string       `json:"indices"`
+		RenamePattern     string         `json:"rename_pattern"`
+		RenameReplacement string         `json:"rename_replacement"`
+		IndexSettings     map[string]any `json:"index_settings"`
+	}
+	if err := json.Unmarshal([]byte(body), &request); err != nil {
+		writeJSON(w, http.StatusBadRequest, `{"error": {"type": "parse_exception", "reason": "request body is not valid JSON"}, "status": 400}`)
+		return
+	}
+	var rename *regexp.Regexp
+	if request.RenamePattern != "" {
+		var err error
+		if rename, err = regexp.Compile(request.RenamePattern); err != nil {
+			writeJSON(w, http.StatusBadRequest, fmt.Sprintf(`{"error": {"type": "illegal_argument_exception", "reason": "invalid rename pattern [%s]"}, "status": 400}`, request.RenamePattern))
+			return
+		}
+	}
+
+	restored := map[string]string{}
+	for _, index := range indices {
+		if !matchesAny(index, request.Indices) {
+			continue
+		}
+		target := index
+		if rename != nil {
+			target = rename.ReplaceAllString(index, request.RenameReplacement)
+		}
+		if _, exists := f.resources[ESIndex][target]; exists {
+			writeJSON(w, http.StatusInternalServerError, fmt.Sprintf(`{"error": {"type": "snapshot_restore_exception", "reason": "[%s:%s] cannot restore index [%s] because an open index with same name already exists in the cluster"}, "status": 500}`, repository, snapshot, target))
+			return
+		}
+		restored[target] = index
+	}
+	settings, _ := json.Marshal(map[string]any{"settings": request.IndexSettings})
+	for target, index := range restored {
+		f.put(ESIndex, target, settings)
+		f.recoveries[target] = fakeRecovery{repository: repository, snapshot: snapshot, sourceIndex: index, startTime: time.Now().UnixMilli()}
+	}
+	writeJSON(w, http.StatusOK, `{"accepted": true}`)
+}
+
+// matchesAny reports whether the index matches one of the patterns, all indices match if there are none
+func matchesAny(index string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, index); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// handleListIndexTemplates returns all index templates
+func (f *FakeElasticsearch) handleListIndexTemplates(w http.ResponseWriter) {
+	indexTemplates := []any{}
+	for _, name := range slices.Sorted(maps.Keys(f.resources[ESIndexTemplate])) {
+		indexTemplates = append(indexTemplates, map[string]any{"name": name, "index_template": f.resources[ESIndexTemplate][name]})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"index_templates": indexTemplates})
+}
+
+// handleResolveIndex returns the indices matching the patterns, the fake has no data streams
+func (f *FakeElasticsearch) handleResolveIndex(w http.ResponseWriter, patterns []string) {
+	indices := []any{}
+	for _, index := range slices.Sorted(maps.Keys(f.resources[ESIndex])) {
+		if matchesAny(index, patterns) {
+			indices = append(indices, map[string]any{"name": index, "attributes": []string{"open"}})
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"indices": indices, "aliases": []any{}, "data_streams": []any{}})
+}
+
+// handleRecovery reports the recoveries of the restored indices with one shard each
+func (f *FakeElasticsearch) handleRecovery(w http.ResponseWriter) {
+	response := map[string]any{}
+	for index, recovery := range f.recoveries {
+		stage := "INDEX"
+		if recovery.done {
+			stage = "DONE"
+		}
+		response[index] = map[string]any{"shards": []any{map[string]any{
+			"id":                   0,
+			"type":                 "SNAPSHOT",
+			"stage":                stage,
+			"start_time_in_millis": recovery.startTime,
+			"source":               map[string]string{"repository": recovery.repository, "snapshot": recovery.snapshot, "index": recovery.sourceIndex},
+		}}}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (f *FakeElasticsearch) handleAlias(w http.ResponseWriter, index string, alias string, body string) {
+	if _, exists := f.resources[ESIndex][index]; !exists {
+		notFound(w, ESIndex, index)
+		return
+	}
+	if _, exists := f.resources[ESIndex][alias]; exists {
+		writeJSON(w, http.StatusBadRequest, fmt.Sprintf(`{"error": {"type": "invalid_alias_name_exception", "reason": "an index exists with the same name as the alias [%s]"}, "status": 400}`, alias))
+		return
+	}
+	f.putAlias(index, alias, json.RawMessage(body))
+	writeJSON(w, http.StatusOK, `{"acknowledged": true}`)
+}
+
+// handleGetAlias returns the aliases of the index, or the indices the alias points to if index is empty
+func (f *FakeElasticsearch) handleGetAlias(w http.ResponseWriter, index string, alias string) {
+	response := map[string]any{}
+	if index != "" {
+		if _, exists := f.resources[ESIndex][index]; !exists {
+			notFound(w, ESIndex, index)
+			return
+		}
+		response[index] = map[string]any{"aliases": f.indexAliases(index)}
+	} else {
+		if len(f.aliases[alias]) == 0 {
+			writeJSON(w, http.StatusNotFound, fmt.Sprintf(`{"error": "alias [%s] missing", "status": 404}`, alias))
+			return
+		}
+		for index, definition := range f.aliases[alias] {
+			response[index] = map[string]any{"aliases": map[string]json.RawMessage{alias: definition}}
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleUpdateAliases applies the add, remove and remove_index actions of an aliases request. Like Elasticsearch, all
+// actions are validated first and either all or none are applied, an alias may take the name of an index removed by
+// the same request.
+func (f *FakeElasticsearch) handleUpdateAliases(w http.ResponseWriter, body string) {
+	type aliasAction struct {
+		Index string `json:"index"`
+		Alias string `json:"alias"`
+	}
+	var request struct {
+		Actions []map[string]json.RawMessage `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(body), &request); err != nil {
+		writeJSON(w, http.StatusBadRequest, `{"error": {"type": "parse_exception", "reason": "request body is not valid JSON"}, "status": 400}`)
+		return
+	}
+	removedIndices := map[string]bool{}
+	for _, action := range request.Actions {
+		if raw, ok := action["remove_index"]; ok {
+			var target aliasAction
+			_ = json.Unmarshal(raw, &target)
+			removedIndices[target.Index] = true
+		}
+	}
+	for _, action := range request.Actions {
+		for actionType, raw := range action {
+			var target aliasAction
+			_ = json.Unmarshal(raw, &target)
+			if _, exists := f.resources[ESIndex][target.Index]; !exists {
+				notFound(w, ESIndex, target.Index)
+				return
+			}
+			if _, exists := f.aliases[target.Alias][target.Index]; actionType == "remove" && !exists {
+				writeJSON(w, http.StatusNotFound, fmt.Sprintf(`{"error": {"type": "aliases_not_found_exception", "reason": "aliases [%s] missing"}, "status": 404}`, target.Alias))
+				return
+			}
+			if _, exists := f.resources[ESIndex][target.Alias]; actionType == "add" && exists && !removedIndices[target.Alias] {
+				writeJSON(w, http.StatusBadRequest, fmt.Sprintf(`{"error": {"type": "invalid_alias_name_exception", "reason": "an index exists with the same name as the alias [%s]"}, "status": 400}`, target.Alias))
+				return
+			}
+		}
+	}
+	for index := range removedIndices {
+		f.deleteIndexState(index)
+		f.delete(ESIndex, index)
+	}
+	for _, action := range request.Actions {
+		for actionType, raw := range action {
+			var target aliasAction
+			_ = json.Unmarshal(raw, &target)
+			switch actionType {
+			case "remove_index":
+				continue
+			case "remove":
+				f.removeAlias(target.Index, target.Alias)
+				continue
+			}
+			var definition map[string]any
+			_ = json.Unmarshal(raw, &definition)
+			delete(definition, "index")
+			delete(definition, "alias")
+			encoded, _ := json.Marshal(definition)
+			f.putAlias(target.Index, target.Alias, encoded)
+		}
+	}
+	writeJSON(w, http.StatusOK, `{"acknowledged": true}`)
+}
+
+func (f *FakeElasticsearch) handleCount(w http.ResponseWriter, name string) {
+	if _, exists := f.resources[ESIndex][name]; !exists {
+		notFound(w, ESIndex, name)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"count": f.documentCounts[name]})
+}
+
+func (f *FakeElasticsearch) handleUserEnabled(w http.ResponseWriter, name string, enabled bool) {
+	stored, exists := f.resources[ESUser][name]
+	if !exists {
+		notFound(w, ESUser, name)
+		return
+	}
+	var user map[string]any
+	_ = json.Unmarshal(stored, &user)
+	user["enabled"] = enabled
+	updated, _ := json.Marshal(user)
+	f.put(ESUser, name, updated)
+	writeJSON(w, http.StatusOK, `{}`)
+}
+
+// handleSimulate returns the sample documents unchanged, processors are not executed
+func (f *FakeElasticsearch) handleSimulate(w http.ResponseWriter, body string) {
+	var request struct {
+		Docs []struct {
+			Source json.RawMessage `json:"_source"`
+		} `json:"docs"`
+	}
+	if err := json.Unmarshal([]byte(body), &request); err != nil {
+		writeJSON(w, http.StatusBadRequest, `{"error": {"type": "parse_exception", "reason": "request body is not valid JSON"}, "status": 400}`)
+		return
+	}
+	docs := make([]any, 0, len(request.Docs))
+	for _, doc := range request.Docs {
+		docs = append(docs, map[string]any{"doc": map[string]any{"_source": doc.Source}})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"docs": docs})
+}
+
+func (f *FakeElasticsearch) handleAPIKey(w http.ResponseWriter, r *http.Request, segments []string, body string) {
+	switch {
+	case len(segments) == 0 && (r.Method == http.MethodPost || r.Method == http.MethodPut):
+		var request struct {
+			Name string `json:"name"`
+		}
+		_ = json.Unmarshal([]byte(body), &request)
+		f.apiKeySequence++
+		id := fmt.Sprintf("fake-api-key-%d", f.apiKeySequence)
+		key := map[string]any{"id": id, "name": request.Name, "api_key": "secret-" + id, "encoded": "ZW5jb2RlZA==", "invalidated": false}
+		stored, _ := json.Marshal(key)
+		f.put(ESAPIKey, id, stored)
+		writeJSON(w, http.StatusOK, key)
+	case len(segments) == 0 && r.Method == http.MethodGet:
+		keys := []map[string]any{}
+		for _, key := range f.findAPIKeys(r.URL.Query().Get("id"), r.URL.Query().Get("name")) {
+			if r.URL.Query().Get("active_only") != "true" || key["invalidated"] != true {
+				keys = append(keys, key)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"api_keys": keys})
+	case len(segments) == 0 && r.Method == http.MethodDelete:
+		var request struct {
+			IDs  []string `json:"ids"`
+			Name string   `json:"name"`
+		}
+		_ = json.Unmarshal([]byte(body), &request)
+		invalidated := []string{}
+		for _, key := range f.findAPIKeys("", request.Name) {
+			if request.Name != "" {
+				request.IDs = append(request.IDs, key["id"].(string))
+			}
+		}
+		for _, id := range request.IDs {
+			if f.delete(ESAPIKey, id) {
+				invalidated = append(invalidated, id)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"invalidated_api_keys": invalidated, "error_count": 0})
+	case len(segments) == 1 && r.Method == http.MethodPut:
+		if _, exists := f.resources[ESAPIKey][segments[0]]; !exists {
+			notFound(w, ESAPIKey, segments[0])
+			return
+		}
+		writeJSON(w, http.StatusOK, `{"updated": true}`)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *FakeElasticsearch) findAPIKeys(id string, name string) []map[string]any {
+	keys := []map[string]any{}
+	for keyID, stored := range f.resources[ESAPIKey] {
+		var key map[string]any
+		_ = json.Unmarshal(stored, &key)
+		if (id == "" || keyID == id) && (name == "" || key["name"] == name) {
+			delete(key, "api_key")
+			delete(key, "encoded")
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// handleHasPrivileges reports all requested cluster privileges as granted except the ones set missing
+func (f *FakeElasticsearch) handleHasPrivileges(w http.ResponseWriter, body string) {
+	var request struct {
+		Cluster []string `json:"cluster"`
+	}
+	_ = json.Unmarshal([]byte(body), &request)
+	cluster := map[string]bool{}
+	hasAll := true
+	for _, privilege := range request.Cluster {
+		cluster[privilege] = !f.missingPrivileges[privilege]
+		hasAll = hasAll && cluster[privilege]
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"username": "elastic", "has_all_requested": hasAll, "cluster": cluster})
+}
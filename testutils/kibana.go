@@ -0,0 +1,556 @@
+package testutils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// Resource kinds stored by FakeKibana, saved objects are stored by their saved object type
+const (
+	KibanaSpace             = "space"
+	KibanaDataView          = "data_view"
+	KibanaMaintenanceWindow = "maintenance_window"
+	KibanaAdvancedSetting   = "config"
+	KibanaReportingJob      = "reporting_job"
+	KibanaAgentPolicy       = "agent_policy"
+	KibanaPackagePolicy     = "package_policy"
+)
+
+// DefaultSpace is the space of saved objects and data views requested without a /s/{space} prefix
+const DefaultSpace = "default"
+
+// FakeKibana is a stateful in-memory double of the Kibana REST API. It supports the saved objects (including their
+// import and finding them by type or reference), spaces, data views, maintenance window, advanced settings, reporting and
+// Fleet agent and package policy APIs, including the /s/{space} prefix.
+type FakeKibana struct {
+	*fakeServer
+
+	// Version is reported by the status API
+	Version    string
+	idSequence int
+}
+
+// NewFakeKibana starts a FakeKibana, it has to be closed by the caller
+func NewFakeKibana() *FakeKibana {
+	f := &FakeKibana{fakeServer: newFakeServer(), Version: "8.15.0"}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+// SavedObject returns the stored body of a saved object or data view in the given space
+func (f *FakeKibana) SavedObject(space string, objectType string, id string) (json.RawMessage, bool) {
+	return f.Get(spacedKind(space, objectType), id)
+}
+
+// PutSavedObject stores a saved object or data view in the given space
+func (f *FakeKibana) PutSavedObject(space string, objectType string, id string, body string) {
+	f.Put(spacedKind(space, objectType), id, body)
+}
+
+// AdvancedSetting returns the user value of an advanced setting in the given space
+func (f *FakeKibana) AdvancedSetting(space string, key string) (json.RawMessage, bool) {
+	return f.Get(spacedKind(space, KibanaAdvancedSetting), key)
+}
+
+// PutAdvancedSetting sets the user value of an advanced setting in the given space
+func (f *FakeKibana) PutAdvancedSetting(space string, key string, value string) {
+	f.Put(spacedKind(space, KibanaAdvancedSetting), key, value)
+}
+
+// FakeReportingJob is a report job triggered through the reporting API
+type FakeReportingJob struct {
+	ExportType string `json:"exportType"`
+	JobParams  string `json:"jobParams"`
+	Status     string `json:"status"`
+}
+
+// ReportingJob returns the report job with the given id
+func (f *FakeKibana) ReportingJob(id string) (FakeReportingJob, bool) {
+	var job FakeReportingJob
+	stored, ok := f.Get(KibanaReportingJob, id)
+	if ok {
+		_ = json.Unmarshal(stored, &job)
+	}
+	return job, ok
+}
+
+// SetReportingJobStatus sets the status of a report job to pending, processing, completed or failed
+func (f *FakeKibana) SetReportingJobStatus(id string, status string) {
+	job, _ := f.ReportingJob(id)
+	job.Status = status
+	stored, _ := json.Marshal(job)
+	f.Put(KibanaReportingJob, id, string(stored))
+}
+
+func spacedKind(space string, objectType string) string {
+	return space + "/" + objectType
+}
+
+func (f *FakeKibana) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	body, failure := f.record(r)
+	if failure != nil {
+		writeJSON(w, failure.statusCode, failure.body)
+		return
+	}
+	if r.Method != http.MethodGet && r.Header.Get("kbn-xsrf") == "" {
+		writeKibanaError(w, http.StatusBadRequest, "Request must contain a kbn-xsrf header.")
+		return
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	space := DefaultSpace
+	if len(segments) > 2 && segments[0] == "s" {
+		space = segments[1]
+		segments = segments[2:]
+	}
+
+	switch {
+	case r.URL.Path == "/api/status":
+		writeJSON(w, http.StatusOK, map[string]any{"version": map[string]any{"number": f.Version}, "status": map[string]any{"overall": map[string]any{"level": "available"}}})
+	case len(segments) == 3 && segments[0] == "api" && segments[1] == "saved_objects" && segments[2] == "_find":
+		f.handleFind(w, r, space)
+	case len(segments) == 3 && segments[0] == "api" && segments[1] == "saved_objects" && segments[2] == "_import":
+		f.handleImport(w, r, space, body)
+	case len(segments) == 4 && segments[0] == "api" && segments[1] == "saved_objects":
+		f.handleVersionedSavedObject(w, r, spacedKind(space, segments[2]), segments[3], body)
+	case len(segments) == 3 && segments[0] == "api" && segments[1] == "spaces" && segments[2] == "space":
+		f.handleCreateWithBodyID(w, r, KibanaSpace, body, bodyID)
+	case len(segments) == 4 && segments[0] == "api" && segments[1] == "spaces" && segments[2] == "space":
+		f.handleSavedObject(w, r, KibanaSpace, segments[3], body)
+	case len(segments) == 3 && segments[0] == "api" && segments[1] == "data_views" && segments[2] == "data_view":
+		f.handleCreateWithBodyID(w, r, spacedKind(space, KibanaDataView), body, func(parsed map[string]any) string {
+			dataView, _ := parsed["data_view"].(map[string]any)
+			id, _ := dataView["id"].(string)
+			return id
+		})
+	case len(segments) == 4 && segments[0] == "api" && segments[1] == "data_views" && segments[2] == "data_view":
+		f.handleDataView(w, r, spacedKind(space, KibanaDataView), segments[3], body)
+	case len(segments) == 2 && segments[0] == "api" && segments[1] == "maintenance_window":
+		f.handleCreateMaintenanceWindow(w, r, spacedKind(space, KibanaMaintenanceWindow), body)
+	case len(segments) == 3 && segments[0] == "api" && segments[1] == "maintenance_window":
+		f.handleMaintenanceWindow(w, r, spacedKind(space, KibanaMaintenanceWindow), segments[2], body)
+	case len(segments) == 3 && segments[0] == "api" && segments[1] == "fleet" && segments[2] == "agent_policies":
+		f.handleCreateWithBodyID(w, r, spacedKind(space, KibanaAgentPolicy), body, bodyID)
+	case len(segments) == 4 && segments[0] == "api" && segments[1] == "fleet" && segments[2] == "agent_policies" && segments[3] == "delete":
+		f.handleDeleteAgentPolicy(w, r, space, body)
+	case len(segments) == 4 && segments[0] == "api" && segments[1] == "fleet" && segments[2] == "agent_policies":
+		f.handleSavedObject(w, r, spacedKind(space, KibanaAgentPolicy), segments[3], body)
+	case len(segments) == 3 && segments[0] == "api" && segments[1] == "fleet" && segments[2] == "package_policies":
+		f.handleCreatePackagePolicy(w, r, space, body)
+	case len(segments) == 4 && segments[0] == "api" && segments[1] == "fleet" && segments[2] == "package_policies":
+		f.handleSavedObject(w, r, spacedKind(space, KibanaPackagePolicy), segments[3], body)
+	case len(segments) == 3 && segments[0] == "api" && segments[1] == "kibana" && segments[2] == "settings":
+		f.handleAdvancedSettings(w, r, spacedKind(space, KibanaAdvancedSetting), body)
+	case len(segments) == 4 && segments[0] == "api" && segments[1] == "reporting" && segments[2] == "generate":
+		f.handleGenerateReport(w, r, space, segments[3])
+	case len(segments) == 5 && segments[0] == "api" && segments[1] == "reporting" && segments[2] == "jobs" && segments[3] == "download":
+		f.handleDownloadReport(w, segments[4])
+	default:
+		writeKibanaError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func writeKibanaError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, map[string]any{"statusCode": statusCode, "error": http.StatusText(statusCode), "message": message})
+}
+
+// handleSavedObject implements GET, POST (create), PUT (update) and DELETE of a saved object or space
+func (f *FakeKibana) handleSavedObject(w http.ResponseWriter, r *http.Request, kind string, id string, body string) {
+	_, exists := f.resources[kind][id]
+	switch r.Method {
+	case http.MethodGet:
+		if !exists {
+			writeKibanaError(w, http.StatusNotFound, fmt.Sprintf("Saved object [%s/%s] not found", kind, id))
+			return
+		}
+		writeJSON(w, http.StatusOK, f.resources[kind][id])
+	case http.MethodPost, http.MethodPut:
+		if r.Method == http.MethodPost && exists {
+			writeKibanaError(w, http.StatusConflict, fmt.Sprintf("Saved object [%s/%s] conflict", kind, id))
+			return
+		}
+		if r.Method == http.MethodPut && !exists {
+			writeKibanaError(w, http.StatusNotFound, fmt.Sprintf("Saved object [%s/%s] not found", kind, id))
+			return
+		}
+		if !json.Valid([]byte(body)) {
+			writeKibanaError(w, http.StatusBadRequest, "Request body is not valid JSON")
+			return
+		}
+		f.put(kind, id, json.RawMessage(body))
+		writeJSON(w, http.StatusOK, f.resources[kind][id])
+	case http.MethodDelete:
+		if !f.delete(kind, id) {
+			writeKibanaError(w, http.StatusNotFound, fmt.Sprintf("Saved object [%s/%s] not found", kind, id))
+			return
+		}
+		writeJSON(w, http.StatusOK, `{}`)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// handleVersionedSavedObject implements the saved objects API on top of handleSavedObject: responses carry the
+// version of the saved object, which changes on every write, and an update with another version than the current one
+// is rejected with 409
+func (f *FakeKibana) handleVersionedSavedObject(w http.ResponseWriter, r *http.Request, kind string, id string, body string) {
+	if r.Method == http.MethodPut {
+		var parsed map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(body), &parsed); err == nil && parsed["version"] != nil {
+			var version string
+			_ = json.Unmarshal(parsed["version"], &version)
+			if _, exists := f.resources[kind][id]; exists && version != f.savedObjectVersion(kind, id) {
+				writeKibanaError(w, http.StatusConflict, fmt.Sprintf("Saved object [%s/%s] conflict", kind, id))
+				return
+			}
+			delete(parsed, "version")
+			stripped, _ := json.Marshal(parsed)
+			body = string(stripped)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	f.handleSavedObject(recorder, r, kind, id, body)
+	response := recorder.Body.Bytes()
+	var object map[string]any
+	if recorder.Code == http.StatusOK && r.Method != http.MethodDelete && json.Unmarshal(response, &object) == nil {
+		object["version"] = f.savedObjectVersion(kind, id)
+		response, _ = json.Marshal(object)
+	}
+	w.WriteHeader(recorder.Code)
+	w.Write(response)
+}
+
+// SavedObjectVersion returns the current version of a saved object in the given space
+func (f *FakeKibana) SavedObjectVersion(space string, objectType string, id string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.savedObjectVersion(spacedKind(space, objectType), id)
+}
+
+func (f *FakeKibana) savedObjectVersion(kind string, id string) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("[%d,1]", f.versions[kind+"/"+id])))
+}
+
+// handleFind implements the saved objects find API for the has_reference parameter, finding the saved objects of the
+// space which reference the given object
+func (f *FakeKibana) handleFind(w http.ResponseWriter, r *http.Request, space string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Query().Get("has_reference") == "" {
+		f.handleFindByType(w, space, r.URL.Query().Get("type"))
+		return
+	}
+	var hasReference struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(r.URL.Query().Get("has_reference")), &hasReference); err != nil {
+		writeKibanaError(w, http.StatusBadRequest, "has_reference has to be a JSON object")
+		return
+	}
+
+	savedObjects := []map[string]any{}
+	for kind, objects := range f.resources {
+		objectType, inSpace := strings.CutPrefix(kind, space+"/")
+		if !inSpace {
+			continue
+		}
+		for id, stored := range objects {
+			var object map[string]any
+			_ = json.Unmarshal(stored, &object)
+			references, _ := object["references"].([]any)
+			for _, reference := range references {
+				ref, _ := reference.(map[string]any)
+				if ref["type"] == hasReference.Type && ref["id"] == hasReference.ID {
+					object["type"] = objectType
+					object["id"] = id
+					savedObjects = append(savedObjects, object)
+					break
+				}
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"saved_objects": savedObjects, "total": len(savedObjects)})
+}
+
+// handleFindByType lists the saved objects of the type in the space
+func (f *FakeKibana) handleFindByType(w http.ResponseWriter, space string, objectType string) {
+	savedObjects := []map[string]any{}
+	for id, stored := range f.resources[spacedKind(space, objectType)] {
+		var object map[string]any
+		_ = json.Unmarshal(stored, &object)
+		object["type"] = objectType
+		object["id"] = id
+		savedObjects = append(savedObjects, object)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"saved_objects": savedObjects, "total": len(savedObjects)})
+}
+
+// handleImport implements the saved objects import API, storing the objects of the ndjson file of the multipart
+// form without type and id. Existing objects are only replaced with the overwrite query parameter.
+func (f *FakeKibana) handleImport(w http.ResponseWriter, r *http.Request, space string, body string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		writeKibanaError(w, http.StatusUnsupportedMediaType, "Expected a multipart form")
+		return
+	}
+	form, err := multipart.NewReader(strings.NewReader(body), params["boundary"]).ReadForm(1 << 20)
+	if err != nil || len(form.File["file"]) != 1 {
+		writeKibanaError(w, http.StatusBadRequest, "Expected the file of the multipart form")
+		return
+	}
+	file, err := form.File["file"][0].Open()
+	if err != nil {
+		writeKibanaError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+	content, _ := io.ReadAll(file)
+
+	var errors []map[string]any
+	successCount := 0
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		var object map[string]any
+		if err := json.Unmarshal([]byte(line), &object); err != nil {
+			writeKibanaError(w, http.StatusBadRequest, "Invalid ndjson")
+			return
+		}
+		objectType, _ := object["type"].(string)
+		id, _ := object["id"].(string)
+		kind := spacedKind(space, objectType)
+		if _, exists := f.resources[kind][id]; exists && r.URL.Query().Get("overwrite") != "true" {
+			errors = append(errors, map[string]any{"id": id, "type": objectType, "error": map[string]any{"type": "conflict"}})
+			continue
+		}
+		delete(object, "type")
+		delete(object, "id")
+		stored, _ := json.Marshal(object)
+		f.put(kind, id, stored)
+		successCount++
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"success": len(errors) == 0, "successCount": successCount, "errors": errors})
+}
+
+// handleCreateWithBodyID creates a resource whose id is taken from the request body
+func (f *FakeKibana) handleCreateWithBodyID(w http.ResponseWriter, r *http.Request, kind string, body string, id func(map[string]any) string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		writeKibanaError(w, http.StatusBadRequest, "Request body is not valid JSON")
+		return
+	}
+	resourceID := id(parsed)
+	if resourceID == "" {
+		writeKibanaError(w, http.StatusBadRequest, "id is required")
+		return
+	}
+	f.handleSavedObject(w, r, kind, resourceID, body)
+}
+
+func bodyID(parsed map[string]any) string {
+	id, _ := parsed["id"].(string)
+	return id
+}
+
+// handleDeleteAgentPolicy deletes the agent policy given by agentPolicyId, which Fleet refuses while package policies
+// are added to it
+func (f *FakeKibana) handleDeleteAgentPolicy(w http.ResponseWriter, r *http.Request, space string, body string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var request struct {
+		AgentPolicyID string `json:"agentPolicyId"`
+	}
+	if err := json.Unmarshal([]byte(body), &request); err != nil {
+		writeKibanaError(w, http.StatusBadRequest, "Request body is not valid JSON")
+		return
+	}
+	for _, stored := range f.resources[spacedKind(space, KibanaPackagePolicy)] {
+		var packagePolicy struct {
+			PolicyIDs []string `json:"policy_ids"`
+		}
+		_ = json.Unmarshal(stored, &packagePolicy)
+		for _, policyID := range packagePolicy.PolicyIDs {
+			if policyID == request.AgentPolicyID {
+				writeKibanaError(w, http.StatusBadRequest, fmt.Sprintf("Cannot delete agent policy %s that contains package policies", policyID))
+				return
+			}
+		}
+	}
+	if !f.delete(spacedKind(space, KibanaAgentPolicy), request.AgentPolicyID) {
+		writeKibanaError(w, http.StatusNotFound, fmt.Sprintf("Agent policy %s not found", request.AgentPolicyID))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"id": request.AgentPolicyID})
+}
+
+// handleCreatePackagePolicy creates a package policy, which requires the agent policies it is added to to exist
+func (f *FakeKibana) handleCreatePackagePolicy(w http.ResponseWriter, r *http.Request, space string, body string) {
+	var packagePolicy struct {
+		PolicyIDs []string `json:"policy_ids"`
+	}
+	_ = json.Unmarshal([]byte(body), &packagePolicy)
+	for _, policyID := range packagePolicy.PolicyIDs {
+		if _, exists := f.resources[spacedKind(space, KibanaAgentPolicy)][policyID]; !exists {
+			writeKibanaError(w, http.StatusNotFound, fmt.Sprintf("Agent policy %s not found", policyID))
+			return
+		}
+	}
+	f.handleCreateWithBodyID(w, r, spacedKind(space, KibanaPackagePolicy), body, bodyID)
+}
+
+// handleDataView implements the data view API, where updates are sent as POST to the existing data view
+func (f *FakeKibana) handleDataView(w http.ResponseWriter, r *http.Request, kind string, id string, body string) {
+	if r.Method == http.MethodPost {
+		if _, exists := f.resources[kind][id]; !exists {
+			writeKibanaError(w, http.StatusNotFound, fmt.Sprintf("Saved object [%s/%s] not found", kind, id))
+			return
+		}
+		r.Method = http.MethodPut
+	}
+	f.handleSavedObject(w, r, kind, id, body)
+}
+
+// handleCreateMaintenanceWindow creates a maintenance window with a generated id, like Kibana does
+func (f *FakeKibana) handleCreateMaintenanceWindow(w http.ResponseWriter, r *http.Request, kind string, body string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		writeKibanaError(w, http.StatusBadRequest, "Request body is not valid JSON")
+		return
+	}
+	f.idSequence++
+	parsed["id"] = fmt.Sprintf("fake-maintenance-window-%d", f.idSequence)
+	stored, _ := json.Marshal(parsed)
+	f.put(kind, parsed["id"].(string), stored)
+	writeJSON(w, http.StatusOK, parsed)
+}
+
+// handleMaintenanceWindow implements GET, PATCH and DELETE of a maintenance window
+func (f *FakeKibana) handleMaintenanceWindow(w http.ResponseWriter, r *http.Request, kind string, id string, body string) {
+	if r.Method != http.MethodPatch {
+		f.handleSavedObject(w, r, kind, id, body)
+		return
+	}
+	stored, exists := f.resources[kind][id]
+	if !exists {
+		writeKibanaError(w, http.StatusNotFound, fmt.Sprintf("Saved object [%s/%s] not found", kind, id))
+		return
+	}
+	var current, update map[string]any
+	_ = json.Unmarshal(stored, &current)
+	if err := json.Unmarshal([]byte(body), &update); err != nil {
+		writeKibanaError(w, http.StatusBadRequest, "Request body is not valid JSON")
+		return
+	}
+	for key, value := range update {
+		current[key] = value
+	}
+	updated, _ := json.Marshal(current)
+	f.put(kind, id, updated)
+	writeJSON(w, http.StatusOK, current)
+}
+
+// handleAdvancedSettings implements GET and POST of the advanced settings, a null value in the changes of a POST
+// resets the setting to its default
+func (f *FakeKibana) handleAdvancedSettings(w http.ResponseWriter, r *http.Request, kind string, body string) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var update struct {
+			Changes map[string]json.RawMessage `json:"changes"`
+		}
+		if err := json.Unmarshal([]byte(body), &update); err != nil {
+			writeKibanaError(w, http.StatusBadRequest, "Request body is not valid JSON")
+			return
+		}
+		for key, value := range update.Changes {
+			if string(value) == "null" {
+				f.delete(kind, key)
+				continue
+			}
+			f.put(kind, key, value)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	settings := make(map[string]any, len(f.resources[kind]))
+	for key, value := range f.resources[kind] {
+		settings[key] = map[string]any{"userValue": value}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"settings": settings})
+}
+
+// handleGenerateReport queues a report job, which stays pending until its status is set
+func (f *FakeKibana) handleGenerateReport(w http.ResponseWriter, r *http.Request, space string, exportType string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	jobParams := r.URL.Query().Get("jobParams")
+	if jobParams == "" {
+		writeKibanaError(w, http.StatusBadRequest, "A jobParams RISON string is required in the querystring or POST body")
+		return
+	}
+	f.idSequence++
+	id := fmt.Sprintf("fake-report-%d", f.idSequence)
+	stored, _ := json.Marshal(FakeReportingJob{ExportType: exportType, JobParams: jobParams, Status: "pending"})
+	f.put(KibanaReportingJob, id, stored)
+
+	path := "/api/reporting/jobs/download/" + id
+	if space != DefaultSpace {
+		path = "/s/" + space + path
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"path": path, "job": map[string]any{"id": id, "jobtype": exportType}})
+}
+
+// handleDownloadReport answers like Kibana: 503 while the job is pending or processing, 500 if it failed and the
+// report once it completed
+func (f *FakeKibana) handleDownloadReport(w http.ResponseWriter, id string) {
+	stored, exists := f.resources[KibanaReportingJob][id]
+	if !exists {
+		writeKibanaError(w, http.StatusNotFound, fmt.Sprintf("Report %s not found", id))
+		return
+	}
+	var job FakeReportingJob
+	_ = json.Unmarshal(stored, &job)
+	switch job.Status {
+	case "completed":
+		w.Header().Set("Content-Type", "application/pdf")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("%PDF-1.4"))
+	case "failed":
+		writeKibanaError(w, http.StatusInternalServerError, "Reporting generation failed: timeout while capturing the dashboard")
+	default:
+		w.Header().Set("Retry-After", "30")
+		writeKibanaError(w, http.StatusServiceUnavailable, "pending")
+	}
+}
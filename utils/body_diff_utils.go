@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// MaxEventDiffLength bounds the summary of the changed keys appended to the event message of an update, longer
+// summaries are truncated
+var MaxEventDiffLength = 256
+
+// appliedBody is the body last applied to the object of a resource
+type appliedBody struct {
+	target string
+	fields map[string]any
+}
+
+// appliedBodies holds the appliedBody of each resource, keyed by the type and the namespace/name of the resource
+var appliedBodies sync.Map
+
+// BodyDiff lists the top-level keys of a body which were added, removed or changed since the body applied before
+type BodyDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// IsZero reports whether no key changed
+func (d BodyDiff) IsZero() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String returns the diff as e.g. "changed: template; added: _meta", truncated to MaxEventDiffLength
+func (d BodyDiff) String() string {
+	if d.IsZero() {
+		return "no changes"
+	}
+	var parts []string
+	for _, part := range []struct {
+		label string
+		keys  []string
+	}{{"changed", d.Changed}, {"added", d.Added}, {"removed", d.Removed}} {
+		if len(part.keys) > 0 {
+			parts = append(parts, part.label+": "+strings.Join(part.keys, ", "))
+		}
+	}
+	summary := strings.Join(parts, "; ")
+	if len(summary) > MaxEventDiffLength {
+		summary = summary[:MaxEventDiffLength] + "..."
+	}
+	return summary
+}
+
+// AppliedBodyChanges records body as applied to the object of obj on the target and returns the changes of the
+// top-level keys since the body applied before, formatted to be appended to the event message of the update, e.g.
+// " (changed: template; added: _meta)". The values of the changed keys are logged at debug level.
+// The changes are empty if no body was applied to the target since the operator started, or the body is no JSON
+// object. Changes made to the object in the target are not seen, the diff report covers those.
+func AppliedBodyChanges(ctx context.Context, obj client.Object, target string, body string) string {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(body), &fields); err != nil || fields == nil {
+		ForgetAppliedBody(obj)
+		return ""
+	}
+	previous, ok := appliedBodies.Swap(appliedBodyKey(obj), appliedBody{target: target, fields: fields})
+	if !ok || previous.(appliedBody).target != target {
+		return ""
+	}
+
+	previousFields := previous.(appliedBody).fields
+	diff := diffFields(previousFields, fields)
+	if !diff.IsZero() {
+		changes := make(map[string]any, len(diff.Added)+len(diff.Removed)+len(diff.Changed))
+		for _, key := range diff.Added {
+			changes[key] = map[string]any{"to": fields[key]}
+		}
+		for _, key := range diff.Removed {
+			changes[key] = map[string]any{"from": previousFields[key]}
+		}
+		for _, key := range diff.Changed {
+			changes[key] = map[string]any{"from": previousFields[key], "to": fields[key]}
+		}
+		if marshalled, err := json.Marshal(changes); err == nil {
+			log.FromContext(ctx).V(1).Info("Applied body changes", "changes", string(marshalled))
+		}
+	}
+	return fmt.Sprintf(" (%s)", diff)
+}
+
+// ForgetAppliedBody drops the body recorded for obj, once the resource is deleted
+func ForgetAppliedBody(obj client.Object) {
+	appliedBodies.Delete(appliedBodyKey(obj))
+}
+
+func appliedBodyKey(obj client.Object) string {
+	return fmt.Sprintf("%T/%s", obj, client.ObjectKeyFromObject(obj))
+}
+
+// diffFields returns the top-level keys added, removed and changed from previous to current, each sorted
+func diffFields(previous map[string]any, current map[string]any) BodyDiff {
+	var diff BodyDiff
+	for _, key := range slices.Sorted(maps.Keys(current)) {
+		previousValue, ok := previous[key]
+		if !ok {
+			diff.Added = append(diff.Added, key)
+		} else if !reflect.DeepEqual(previousValue, current[key]) {
+			diff.Changed = append(diff.Changed, key)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(previous)) {
+		if _, ok := current[key]; !ok {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+	return diff
+}
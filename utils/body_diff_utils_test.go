@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAppliedBodyChanges(t *testing.T) {
+	ctx := context.Background()
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "changes"}}
+	defer ForgetAppliedBody(obj)
+
+	steps := []struct {
+		name   string
+		target string
+		body   string
+		want   string
+	}{
+		{name: "no baseline", target: "https://es", body: `{"template":{"a":1},"priority":1,"version":1}`, want: ""},
+		{name: "changed", target: "https://es", body: `{"template":{"a":2},"priority":1,"_meta":{},"version":1}`, want: " (changed: template; added: _meta)"},
+		{name: "removed", target: "https://es", body: `{"template":{"a":2},"priority":1,"_meta":{}}`, want: " (removed: version)"},
+		{name: "unchanged", target: "https://es", body: `{"_meta":{},"priority":1,"template":{"a":2}}`, want: " (no changes)"},
+		{name: "other target", target: "https://other", body: `{"priority":2}`, want: ""},
+		{name: "no json object", target: "https://other", body: `[]`, want: ""},
+		{name: "forgotten", target: "https://other", body: `{"priority":3}`, want: ""},
+	}
+	for _, step := range steps {
+		if got := AppliedBodyChanges(ctx, obj, step.target, step.body); got != step.want {
+			t.Errorf("%s: AppliedBodyChanges() = %q, want %q", step.name, got, step.want)
+		}
+	}
+}
+
+func TestAppliedBodyChangesPerResource(t *testing.T) {
+	ctx := context.Background()
+	first := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "first"}}
+	second := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "first"}}
+	defer ForgetAppliedBody(first)
+	defer ForgetAppliedBody(second)
+
+	AppliedBodyChanges(ctx, first, "https://es", `{"a":1}`)
+	if got := AppliedBodyChanges(ctx, second, "https://es", `{"a":2}`); got != "" {
+		t.Errorf("AppliedBodyChanges() of another kind = %q, want no baseline", got)
+	}
+	ForgetAppliedBody(first)
+	if got := AppliedBodyChanges(ctx, first, "https://es", `{"a":2}`); got != "" {
+		t.Errorf("AppliedBodyChanges() after ForgetAppliedBody = %q, want no baseline", got)
+	}
+}
+
+func TestBodyDiffString(t *testing.T) {
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = "key"
+	}
+	truncated := BodyDiff{Changed: keys}.String()
+	if len(truncated) != MaxEventDiffLength+len("...") || !strings.HasSuffix(truncated, "...") {
+		t.Errorf("String() = %q, want truncated to %d characters", truncated, MaxEventDiffLength)
+	}
+	if got := (BodyDiff{Added: []string{"b"}, Removed: []string{"c"}, Changed: []string{"a"}}).String(); got != "changed: a; added: b; removed: c" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -0,0 +1,14 @@
+package utils
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateBodyFields returns a field error if both spec.body and spec.bodyJson are set
+func ValidateBodyFields(body string, bodyJSON *apiextensionsv1.JSON) field.ErrorList {
+	if body != "" && bodyJSON != nil && len(bodyJSON.Raw) > 0 {
+		return field.ErrorList{field.Forbidden(field.NewPath("spec").Child("bodyJson"), "only one of spec.body and spec.bodyJson may be set")}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestValidateBodyFields(t *testing.T) {
+	bodyJSON := &apiextensionsv1.JSON{Raw: []byte(`{"settings":{}}`)}
+	tests := []struct {
+		name     string
+		body     string
+		bodyJSON *apiextensionsv1.JSON
+		wantErr  bool
+	}{
+		{name: "body", body: `{}`},
+		{name: "bodyJson", bodyJSON: bodyJSON},
+		{name: "none"},
+		{name: "body and empty bodyJson", body: `{}`, bodyJSON: &apiextensionsv1.JSON{}},
+		{name: "both", body: `{}`, bodyJSON: bodyJSON, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := ValidateBodyFields(tt.body, tt.bodyJSON); (len(errs) > 0) != tt.wantErr {
+				t.Errorf("ValidateBodyFields() = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// certificateSecrets maps the certificate Secrets of target instances to the URLs of the instances trusting them.
+// Secrets are registered when a client is built, so only certificates in use are watched.
+type certificateSecrets struct {
+	mu   sync.Mutex
+	urls map[types.NamespacedName]map[string]struct{}
+}
+
+var defaultCertificateSecrets = &certificateSecrets{urls: make(map[types.NamespacedName]map[string]struct{})}
+
+// RegisterCertificateSecret records that clients of the instance at url trust the certificate of the Secret
+func RegisterCertificateSecret(namespace string, secretName string, url string) {
+	defaultCertificateSecrets.register(types.NamespacedName{Namespace: namespace, Name: secretName}, url)
+}
+
+// CertificateSecretURLs returns the URLs of the instances trusting the certificate of the Secret, sorted
+func CertificateSecretURLs(key types.NamespacedName) []string {
+	return defaultCertificateSecrets.get(key)
+}
+
+func (c *certificateSecrets) register(key types.NamespacedName, url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.urls[key] == nil {
+		c.urls[key] = make(map[string]struct{})
+	}
+	c.urls[key][url] = struct{}{}
+}
+
+func (c *certificateSecrets) get(key types.NamespacedName) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	urls := make([]string, 0, len(c.urls[key]))
+	for url := range c.urls[key] {
+		urls = append(urls, url)
+	}
+	slices.Sort(urls)
+	return urls
+}
+
+// CertificateReloader watches the certificate Secrets of the target instances. Clients are built from the current
+// Secret on every reconcile, but requests failing while the certificate rotates open the circuit of the instance.
+// When a certificate Secret changes, the circuits of the instances trusting it are reset so that reconciles resume
+// with the new certificate right away.
+type CertificateReloader struct{}
+
+func (r *CertificateReloader) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	for _, url := range CertificateSecretURLs(req.NamespacedName) {
+		if ResetCircuit(url) {
+			logger.Info("Certificate Secret changed, resuming reconciles against the target instance", "url", url)
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CertificateReloader) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("certificate-reloader").
+		For(&k8sv1.Secret{}, builder.WithPredicates(CertificateSecretChangedFilter())).
+		Complete(r)
+}
+
+// CertificateSecretChangedFilter passes registered certificate Secrets which are created or whose data changed
+func CertificateSecretChangedFilter() predicate.Funcs {
+	registered := func(obj client.Object) bool {
+		return len(CertificateSecretURLs(client.ObjectKeyFromObject(obj))) > 0
+	}
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return registered(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldSecret, okOld := e.ObjectOld.(*k8sv1.Secret)
+			newSecret, okNew := e.ObjectNew.(*k8sv1.Secret)
+			if !okOld || !okNew || !registered(newSecret) {
+				return false
+			}
+			return !equality.Semantic.DeepEqual(oldSecret.Data, newSecret.Data)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestCertificateSecretChangedFilter(t *testing.T) {
+	RegisterCertificateSecret("elastic", "quickstart-es-http-certs-public", "https://quickstart-es-http:9200")
+	filter := CertificateSecretChangedFilter()
+
+	secret := func(name string, ca string) *k8sv1.Secret {
+		return &k8sv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "elastic", Name: name},
+			Data:       map[string][]byte{"ca.crt": []byte(ca)},
+		}
+	}
+	tests := []struct {
+		name string
+		old  *k8sv1.Secret
+		new  *k8sv1.Secret
+		want bool
+	}{
+		{name: "rotated", old: secret("quickstart-es-http-certs-public", "old"), new: secret("quickstart-es-http-certs-public", "new"), want: true},
+		{name: "unchanged data", old: secret("quickstart-es-http-certs-public", "old"), new: secret("quickstart-es-http-certs-public", "old")},
+		{name: "not a certificate Secret", old: secret("other", "old"), new: secret("other", "new")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.Update(event.UpdateEvent{ObjectOld: tt.old, ObjectNew: tt.new}); got != tt.want {
+				t.Errorf("Update() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if !filter.Create(event.CreateEvent{Object: secret("quickstart-es-http-certs-public", "new")}) {
+		t.Error("Expected a recreated certificate Secret to pass")
+	}
+}
+
+func TestCertificateReloader_ResetsCircuit(t *testing.T) {
+	url := "https://rotating-kb-http:5601"
+	RegisterCertificateSecret("kibana", "rotating-kb-http-certs-public", url)
+	RegisterCertificateSecret("kibana", "rotating-kb-http-certs-public", url)
+	key := types.NamespacedName{Namespace: "kibana", Name: "rotating-kb-http-certs-public"}
+	if urls := CertificateSecretURLs(key); !reflect.DeepEqual(urls, []string{url}) {
+		t.Fatalf("CertificateSecretURLs() = %v", urls)
+	}
+
+	for i := 0; i < CircuitBreakerFailureThreshold; i++ {
+		defaultCircuitBreaker.record(url, errors.New("x509: certificate signed by unknown authority"))
+	}
+	if !defaultCircuitBreaker.instance(url).open {
+		t.Fatal("Expected the circuit to open")
+	}
+
+	if _, err := (&CertificateReloader{}).Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if defaultCircuitBreaker.instance(url).open {
+		t.Error("Expected the circuit to be reset after the certificate changed")
+	}
+	if ResetCircuit(url) {
+		t.Error("Expected ResetCircuit() to report a closed circuit")
+	}
+}
@@ -0,0 +1,277 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// CircuitBreakerFailureThreshold is the number of consecutive failed requests after which the circuit of an
+// instance opens
+var CircuitBreakerFailureThreshold = 5
+
+// CircuitBreakerProbeInterval is how often an instance with an open circuit is probed, it is also the interval
+// in which the short-circuited resources are requeued
+var CircuitBreakerProbeInterval = 30 * time.Second
+
+// TargetUnavailable condition, set while reconciles are short-circuited because the target instance is unavailable
+const (
+	TargetUnavailableConditionType = "TargetUnavailable"
+	TargetUnavailableReason        = "TargetUnavailable"
+	TargetAvailableReason          = "TargetAvailable"
+)
+
+// circuitBreaker tracks the availability of target instances by their URL. Requests are observed through
+// the transport returned by Transport, once an instance failed CircuitBreakerFailureThreshold times in a row its
+// circuit opens and reconciles against it are short-circuited until a probe succeeds.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	instances map[string]*circuitBreakerInstance
+	now       func() time.Time
+}
+
+type circuitBreakerInstance struct {
+	failures   int
+	open       bool
+	probing    bool
+	lastProbed time.Time
+	lastError  string
+	notified   bool
+	openedAt   time.Time
+}
+
+var defaultCircuitBreaker = newCircuitBreaker(time.Now)
+
+func newCircuitBreaker(now func() time.Time) *circuitBreaker {
+	return &circuitBreaker{
+		instances: make(map[string]*circuitBreakerInstance),
+		now:       now,
+	}
+}
+
+// CircuitBreakerTransport wraps the transport of a client of the instance at url, recording the outcome of every
+// request in the circuit of the instance
+func CircuitBreakerTransport(url string, next http.RoundTripper) http.RoundTripper {
+	return defaultCircuitBreaker.transport(url, next)
+}
+
+// ResetCircuit closes the circuit of the instance at url, so that the next reconcile tries the instance again instead
+// of waiting for the next probe. It returns whether the circuit was open.
+func ResetCircuit(url string) bool {
+	return defaultCircuitBreaker.reset(url)
+}
+
+// TargetUnavailableSince returns when the circuit of the instance at url opened, and whether it is open
+func TargetUnavailableSince(url string) (time.Time, bool) {
+	return defaultCircuitBreaker.unavailableSince(url)
+}
+
+// ProbeTarget reports whether the instance at url is available like CheckTargetAvailable, probing it if its circuit
+// is open and the probe is due, without reporting the unavailability on an object
+func ProbeTarget(ctx context.Context, url string, probe func(ctx context.Context) error) bool {
+	available, _, _ := defaultCircuitBreaker.check(ctx, url, probe)
+	return available
+}
+
+// CheckTargetAvailable reports whether resources of the instance at url may be reconciled. While the circuit of the
+// instance is open, the TargetUnavailable condition is set on the object, a single event is recorded on the first
+// short-circuited object and the returned result requeues the resource without an error, after the probe interval
+// extended by a per-object jitter. The instance is probed at most every CircuitBreakerProbeInterval, a successful
+// probe closes the circuit.
+// conditions must point into the status of obj.
+func CheckTargetAvailable(ctx context.Context, cli client.Client, recorder record.EventRecorder, obj client.Object,
+	conditions *[]metav1.Condition, url string, probe func(ctx context.Context) error) (bool, ctrl.Result) {
+	logger := log.FromContext(ctx)
+
+	available, message, notify := defaultCircuitBreaker.check(ctx, url, probe)
+	if notify {
+		recorder.Event(obj, "Warning", TargetUnavailableReason, message)
+	}
+
+	if available {
+		if clearTargetUnavailable(conditions) {
+			if err := cli.Status().Update(ctx, obj); err != nil {
+				logger.Error(err, "Failed to clear TargetUnavailable condition")
+			}
+		}
+		return true, ctrl.Result{}
+	}
+
+	if setTargetUnavailable(conditions, obj.GetGeneration(), message) {
+		if err := cli.Status().Update(ctx, obj); err != nil {
+			logger.Error(err, "Failed to set TargetUnavailable condition")
+		}
+	}
+	key := fmt.Sprintf("%T/%s", obj, client.ObjectKeyFromObject(obj))
+	return false, ctrl.Result{RequeueAfter: JitterFor(key, CircuitBreakerProbeInterval)}
+}
+
+func setTargetUnavailable(conditions *[]metav1.Condition, generation int64, message string) bool {
+	changed := meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               TargetUnavailableConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             TargetUnavailableReason,
+		Message:            message,
+	})
+	readyChanged := meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ReadyConditionType,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             TargetUnavailableReason,
+		Message:            message,
+	})
+	return changed || readyChanged
+}
+
+// clearTargetUnavailable removes the TargetUnavailable condition, and the Ready condition if it was set because
+// of it; the reconcile following sets the Ready condition again
+func clearTargetUnavailable(conditions *[]metav1.Condition) bool {
+	if !meta.IsStatusConditionTrue(*conditions, TargetUnavailableConditionType) {
+		return false
+	}
+	meta.RemoveStatusCondition(conditions, TargetUnavailableConditionType)
+	if ready := meta.FindStatusCondition(*conditions, ReadyConditionType); ready != nil && ready.Reason == TargetUnavailableReason {
+		meta.RemoveStatusCondition(conditions, ReadyConditionType)
+	}
+	return true
+}
+
+// check returns whether the instance is available, the message describing why it is not and whether the
+// unavailability has to be reported
+func (b *circuitBreaker) check(ctx context.Context, url string, probe func(ctx context.Context) error) (bool, string, bool) {
+	logger := log.FromContext(ctx)
+
+	b.mu.Lock()
+	instance := b.instance(url)
+	if !instance.open {
+		b.mu.Unlock()
+		return true, "", false
+	}
+	shouldProbe := !instance.probing && b.now().Sub(instance.lastProbed) >= CircuitBreakerProbeInterval
+	if shouldProbe {
+		instance.probing = true
+		instance.lastProbed = b.now()
+	}
+	b.mu.Unlock()
+
+	if shouldProbe {
+		probeCtx, cancel := context.WithTimeout(ctx, CircuitBreakerProbeInterval)
+		err := probe(probeCtx)
+		cancel()
+
+		b.mu.Lock()
+		instance.probing = false
+		if err == nil {
+			b.close(instance)
+			b.mu.Unlock()
+			logger.Info("Target instance recovered, resuming reconciles", "url", url)
+			return true, "", false
+		}
+		instance.lastError = err.Error()
+		b.mu.Unlock()
+		logger.V(1).Info("Target instance is still unavailable", "url", url, "error", err.Error())
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !instance.open {
+		return true, "", false
+	}
+	message := fmt.Sprintf("Target instance %s is unavailable (%s), reconciles against it are paused until it recovers",
+		url, instance.lastError)
+	notify := !instance.notified
+	instance.notified = true
+	return false, message, notify
+}
+
+// record counts the outcome of a request against the instance, opening its circuit once the failure threshold is
+// reached and closing it on any success
+func (b *circuitBreaker) record(url string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	instance := b.instance(url)
+	if err == nil {
+		b.close(instance)
+		return
+	}
+	instance.failures++
+	instance.lastError = err.Error()
+	if !instance.open && instance.failures >= CircuitBreakerFailureThreshold {
+		instance.open = true
+		instance.lastProbed = b.now()
+		instance.openedAt = b.now()
+	}
+}
+
+func (b *circuitBreaker) unavailableSince(url string) (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	instance, ok := b.instances[url]
+	if !ok || !instance.open {
+		return time.Time{}, false
+	}
+	return instance.openedAt, true
+}
+
+func (b *circuitBreaker) reset(url string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	instance, ok := b.instances[url]
+	if !ok || !instance.open {
+		return false
+	}
+	b.close(instance)
+	return true
+}
+
+func (b *circuitBreaker) close(instance *circuitBreakerInstance) {
+	instance.failures = 0
+	instance.open = false
+	instance.notified = false
+}
+
+func (b *circuitBreaker) instance(url string) *circuitBreakerInstance {
+	instance, ok := b.instances[url]
+	if !ok {
+		instance = &circuitBreakerInstance{}
+		b.instances[url] = instance
+	}
+	return instance
+}
+
+func (b *circuitBreaker) transport(url string, next http.RoundTripper) http.RoundTripper {
+	return &circuitBreakerTransport{breaker: b, url: url, next: next}
+}
+
+type circuitBreakerTransport struct {
+	breaker *circuitBreaker
+	url     string
+	next    http.RoundTripper
+}
+
+// RoundTrip records connection errors and 502, 503 and 504 responses as failures, any other response shows
+// that the instance is available. Requests canceled by the caller are not recorded.
+func (t *circuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.next.RoundTrip(req)
+	switch {
+	case err != nil && req.Context().Err() != nil:
+	case err != nil:
+		t.breaker.record(t.url, err)
+	case res.StatusCode == http.StatusBadGateway || res.StatusCode == http.StatusServiceUnavailable ||
+		res.StatusCode == http.StatusGatewayTimeout:
+		t.breaker.record(t.url, fmt.Errorf("status %d", res.StatusCode))
+	default:
+		t.breaker.record(t.url, nil)
+	}
+	return res, err
+}
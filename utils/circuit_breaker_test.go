@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCircuitBreakerTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		wantOpen   bool
+	}{
+		{name: "service unavailable", statusCode: http.StatusServiceUnavailable, wantOpen: true},
+		{name: "bad gateway", statusCode: http.StatusBadGateway, wantOpen: true},
+		{name: "gateway timeout", statusCode: http.StatusGatewayTimeout, wantOpen: true},
+		{name: "unauthorized", statusCode: http.StatusUnauthorized, wantOpen: false},
+		{name: "ok", statusCode: http.StatusOK, wantOpen: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			breaker := newCircuitBreaker(time.Now)
+			httpClient := &http.Client{Transport: breaker.transport(server.URL, &http.Transport{})}
+			for i := 0; i < CircuitBreakerFailureThreshold; i++ {
+				res, err := httpClient.Get(server.URL)
+				if err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+				res.Body.Close()
+			}
+
+			if got := breaker.instance(server.URL).open; got != tt.wantOpen {
+				t.Errorf("open = %v, want %v", got, tt.wantOpen)
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker_Check(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(func() time.Time { return now })
+	url := "https://elasticsearch:9200"
+	probes := 0
+	probeErr := errors.New("connection refused")
+	probe := func(context.Context) error {
+		probes++
+		return probeErr
+	}
+
+	for i := 0; i < CircuitBreakerFailureThreshold-1; i++ {
+		breaker.record(url, probeErr)
+	}
+	if available, _, _ := breaker.check(context.Background(), url, probe); !available {
+		t.Fatal("Expected the instance to be available below the failure threshold")
+	}
+
+	breaker.record(url, probeErr)
+	available, message, notify := breaker.check(context.Background(), url, probe)
+	if available || !notify || message == "" {
+		t.Fatalf("Expected the instance to be unavailable and reported, got available=%v notify=%v message=%q", available, notify, message)
+	}
+	if _, _, notify := breaker.check(context.Background(), url, probe); notify {
+		t.Error("Expected the unavailability to be reported only once")
+	}
+	if probes != 0 {
+		t.Errorf("Expected no probe before the probe interval passed, got %d", probes)
+	}
+
+	now = now.Add(CircuitBreakerProbeInterval)
+	if available, _, _ := breaker.check(context.Background(), url, probe); available || probes != 1 {
+		t.Fatalf("Expected a failed probe to keep the circuit open, got available=%v probes=%d", available, probes)
+	}
+	breaker.check(context.Background(), url, probe)
+	if probes != 1 {
+		t.Errorf("Expected a single probe per interval, got %d", probes)
+	}
+
+	probeErr = nil
+	now = now.Add(CircuitBreakerProbeInterval)
+	if available, _, _ := breaker.check(context.Background(), url, probe); !available {
+		t.Error("Expected a successful probe to close the circuit")
+	}
+	if available, _, _ := breaker.check(context.Background(), "https://other:9200", probe); !available {
+		t.Error("Expected other instances not to be affected")
+	}
+}
+
+func TestCheckTargetAvailable_Conditions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+
+	index := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "my-index", Namespace: "default", Generation: 2}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(index).WithStatusSubresource(index).Build()
+	recorder := record.NewFakeRecorder(10)
+
+	url := "https://unavailable-elasticsearch:9200"
+	for i := 0; i < CircuitBreakerFailureThreshold; i++ {
+		defaultCircuitBreaker.record(url, errors.New("connection refused"))
+	}
+	probeErr := errors.New("connection refused")
+	probe := func(context.Context) error { return probeErr }
+
+	available, res := CheckTargetAvailable(context.Background(), fakeClient, recorder, index, &index.Status.Conditions, url, probe)
+	if available || res.RequeueAfter != JitterFor("*v1alpha1.Index/default/my-index", CircuitBreakerProbeInterval) {
+		t.Fatalf("Expected the reconcile to be short-circuited, got available=%v result=%v", available, res)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Expected a single TargetUnavailable event, got %d", len(recorder.Events))
+	}
+	if !meta.IsStatusConditionTrue(index.Status.Conditions, TargetUnavailableConditionType) {
+		t.Errorf("Expected the TargetUnavailable condition to be set, got %v", index.Status.Conditions)
+	}
+	if ready := meta.FindStatusCondition(index.Status.Conditions, ReadyConditionType); ready == nil ||
+		ready.Status != metav1.ConditionFalse || ready.Reason != TargetUnavailableReason {
+		t.Errorf("Expected the Ready condition to be False with reason TargetUnavailable, got %v", ready)
+	}
+
+	probeErr = nil
+	defaultCircuitBreaker.instance(url).lastProbed = time.Time{}
+	if available, _ := CheckTargetAvailable(context.Background(), fakeClient, recorder, index, &index.Status.Conditions, url, probe); !available {
+		t.Fatal("Expected the reconcile to proceed once the instance recovered")
+	}
+	if len(index.Status.Conditions) != 0 {
+		t.Errorf("Expected the TargetUnavailable and Ready conditions to be removed, got %v", index.Status.Conditions)
+	}
+}
+
+func TestCircuitBreaker_UnavailableSince(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(func() time.Time { return now })
+	url := "https://elasticsearch:9200"
+
+	if _, open := breaker.unavailableSince(url); open {
+		t.Fatal("Expected an unknown instance to be available")
+	}
+	for i := 0; i < CircuitBreakerFailureThreshold; i++ {
+		breaker.record(url, errors.New("connection refused"))
+		now = now.Add(time.Second)
+	}
+	since, open := breaker.unavailableSince(url)
+	if !open || !since.Equal(now.Add(-time.Second)) {
+		t.Errorf("unavailableSince() = %v, %v, want the time the circuit opened", since, open)
+	}
+	breaker.record(url, nil)
+	if _, open := breaker.unavailableSince(url); open {
+		t.Error("Expected a closed circuit not to be unavailable")
+	}
+}
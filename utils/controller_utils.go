@@ -28,10 +28,12 @@ type ErrorEvent struct {
 	Err error
 }
 
+// GetRequeueResult requeues the resource after a minute, extended by a random jitter so that resources failing at
+// the same time are not retried all at once
 func GetRequeueResult() ctrl.Result {
 	return ctrl.Result{
 		Requeue:      true,
-		RequeueAfter: time.Minute,
+		RequeueAfter: Jitter(time.Minute),
 	}
 }
 
@@ -64,19 +66,44 @@ func RecordEventAndReturn(res ctrl.Result, err error, recorder record.EventRecor
 }
 
 func GetUserSecret(cli client.Client, ctx context.Context, namespace string, auth *configv2.UsernamePasswordAuthentication, secret *k8sv1.Secret) error {
-	if err := cli.Get(ctx, client.ObjectKey{Namespace: namespace, Name: auth.SecretName}, secret); err != nil {
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: auth.SecretNamespace(namespace), Name: auth.SecretName}, secret); err != nil {
 		return err
 	}
 	return nil
 }
 
 func GetCertificateSecret(cli client.Client, ctx context.Context, namespace string, certificate *configv2.PublicCertificate, secret *k8sv1.Secret) error {
-	if err := cli.Get(ctx, client.ObjectKey{Namespace: namespace, Name: certificate.SecretName}, secret); err != nil {
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: certificate.SecretNamespace(namespace), Name: certificate.SecretName}, secret); err != nil {
 		return err
 	}
 	return nil
 }
 
+// ValidateInstanceSecrets checks the namespaces of the Secrets of an instance. An instance in a namespace may only use
+// the Secrets of its namespace, a cluster-scoped instance, namespace being empty, has to set the namespace of each
+// of its Secrets.
+func ValidateInstanceSecrets(namespace string, auth *configv2.UsernamePasswordAuthentication, certificate *configv2.PublicCertificate) error {
+	if auth != nil {
+		if err := validateSecretNamespace(namespace, auth.Namespace, "authentication.usernamePasswordSecret"); err != nil {
+			return err
+		}
+	}
+	if certificate != nil {
+		return validateSecretNamespace(namespace, certificate.Namespace, "certificate")
+	}
+	return nil
+}
+
+func validateSecretNamespace(namespace string, secretNamespace string, field string) error {
+	if namespace == "" && secretNamespace == "" {
+		return fmt.Errorf("%s.namespace is required for cluster-scoped instances", field)
+	}
+	if namespace != "" && secretNamespace != "" && secretNamespace != namespace {
+		return fmt.Errorf("%s.namespace %s is not allowed, the Secret has to be in the namespace %s of the instance", field, secretNamespace, namespace)
+	}
+	return nil
+}
+
 const LastUpdateTriggeredAtAnnotation = "eck.github.com/last-update-triggered-at"
 
 func CommonEventFilter() predicate.Funcs {
@@ -86,6 +113,10 @@ func CommonEventFilter() predicate.Funcs {
 			if e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() {
 				return true
 			}
+			// Allow if the resource was handed over to another operator installation
+			if OwnedBy(e.ObjectOld) != OwnedBy(e.ObjectNew) {
+				return true
+			}
 			// Allow if the last-update-triggered-at annotation changed
 			oldAnnotations := e.ObjectOld.GetAnnotations()
 			newAnnotations := e.ObjectNew.GetAnnotations()
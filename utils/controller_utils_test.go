@@ -37,9 +37,9 @@ func TestGetRequeueResult(t *testing.T) {
 		t.Errorf("GetRequeueResult().Requeue = %v, want true", result.Requeue)
 	}
 
-	expectedDuration := time.Duration(time.Minute)
-	if result.RequeueAfter != expectedDuration {
-		t.Errorf("GetRequeueResult().RequeueAfter = %v, want %v", result.RequeueAfter, expectedDuration)
+	maxDuration := time.Minute + time.Duration(RequeueJitterFactor*float64(time.Minute))
+	if result.RequeueAfter < time.Minute || result.RequeueAfter > maxDuration {
+		t.Errorf("GetRequeueResult().RequeueAfter = %v, want between %v and %v", result.RequeueAfter, time.Minute, maxDuration)
 	}
 }
 
@@ -259,6 +259,16 @@ func TestGetUserSecret(t *testing.T) {
 			wantErr:    true,
 			wantSecret: false,
 		},
+		{
+			name:      "secret namespace",
+			namespace: "other-namespace",
+			auth: &configv2.UsernamePasswordAuthentication{
+				SecretName: "test-secret",
+				Namespace:  "default",
+			},
+			wantErr:    false,
+			wantSecret: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -355,6 +365,8 @@ func TestCommonEventFilter(t *testing.T) {
 		newGeneration  int64
 		oldAnnotations map[string]string
 		newAnnotations map[string]string
+		oldLabels      map[string]string
+		newLabels      map[string]string
 		want           bool
 	}{
 		{
@@ -415,6 +427,22 @@ func TestCommonEventFilter(t *testing.T) {
 			newAnnotations: map[string]string{"other-annotation": "value2"},
 			want:           false,
 		},
+		{
+			name:          "operator ID label changed - should process",
+			oldGeneration: 1,
+			newGeneration: 1,
+			oldLabels:     map[string]string{OperatorIDLabel: "blue"},
+			newLabels:     map[string]string{OperatorIDLabel: "green"},
+			want:          true,
+		},
+		{
+			name:          "other label changed - should skip",
+			oldGeneration: 1,
+			newGeneration: 1,
+			oldLabels:     map[string]string{"team": "a"},
+			newLabels:     map[string]string{"team": "b"},
+			want:          false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -424,6 +452,7 @@ func TestCommonEventFilter(t *testing.T) {
 					Name:        "test-object",
 					Generation:  tt.oldGeneration,
 					Annotations: tt.oldAnnotations,
+					Labels:      tt.oldLabels,
 				},
 			}
 			newObj := &MockObject{
@@ -431,6 +460,7 @@ func TestCommonEventFilter(t *testing.T) {
 					Name:        "test-object",
 					Generation:  tt.newGeneration,
 					Annotations: tt.newAnnotations,
+					Labels:      tt.newLabels,
 				},
 			}
 
@@ -460,3 +490,33 @@ func containsSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestValidateInstanceSecrets(t *testing.T) {
+	tests := []struct {
+		name        string
+		namespace   string
+		auth        *configv2.UsernamePasswordAuthentication
+		certificate *configv2.PublicCertificate
+		wantErr     bool
+	}{
+		{name: "no secrets", namespace: ""},
+		{name: "namespaced instance", namespace: "default", auth: &configv2.UsernamePasswordAuthentication{SecretName: "user"}},
+		{name: "own namespace", namespace: "default", certificate: &configv2.PublicCertificate{SecretName: "certs", Namespace: "default"}},
+		{name: "other namespace", namespace: "default", auth: &configv2.UsernamePasswordAuthentication{SecretName: "user", Namespace: "elastic-system"}, wantErr: true},
+		{name: "cluster instance", namespace: "", auth: &configv2.UsernamePasswordAuthentication{SecretName: "user", Namespace: "elastic-system"}},
+		{
+			name:        "cluster instance without certificate namespace",
+			namespace:   "",
+			auth:        &configv2.UsernamePasswordAuthentication{SecretName: "user", Namespace: "elastic-system"},
+			certificate: &configv2.PublicCertificate{SecretName: "certs"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateInstanceSecrets(tt.namespace, tt.auth, tt.certificate); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateInstanceSecrets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
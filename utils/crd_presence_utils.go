@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var crdMissing = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "eck_cr_crd_missing",
+	Help: "Whether the CRD of a kind is not installed and its controller was skipped, 1 or 0.",
+}, []string{"kind"})
+
+func init() {
+	metrics.Registry.MustRegister(crdMissing)
+}
+
+// KindInstalled reports whether the CRD of the kind is installed in the cluster
+func KindInstalled(mapper meta.RESTMapper, gvk schema.GroupVersionKind) (bool, error) {
+	_, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// SetupIfInstalled calls setup, which sets up the controller of the kind of obj, unless the CRD of the kind is not
+// installed. Skipped controllers are logged and reported by the eck_cr_crd_missing metric, so the operator can run
+// with the CRDs of Elasticsearch or Kibana only.
+func SetupIfInstalled(mgr ctrl.Manager, obj client.Object, setup func(mgr ctrl.Manager) error) error {
+	gvk, err := apiutil.GVKForObject(obj, mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+	installed, err := KindInstalled(mgr.GetRESTMapper(), gvk)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		ctrl.Log.WithName("setup").Info("Skipping controller, its CRD is not installed", "controller", gvk.Kind, "group", gvk.Group)
+		crdMissing.WithLabelValues(gvk.Kind).Set(1)
+		return nil
+	}
+	crdMissing.WithLabelValues(gvk.Kind).Set(0)
+	return setup(mgr)
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestKindInstalled(t *testing.T) {
+	installed := schema.GroupVersionKind{Group: "es.eck.github.com", Version: "v1alpha1", Kind: "Index"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{installed.GroupVersion()})
+	mapper.Add(installed, meta.RESTScopeNamespace)
+
+	tests := []struct {
+		name string
+		gvk  schema.GroupVersionKind
+		want bool
+	}{
+		{name: "installed", gvk: installed, want: true},
+		{name: "missing kind", gvk: schema.GroupVersionKind{Group: "es.eck.github.com", Version: "v1alpha1", Kind: "IndexTemplate"}, want: false},
+		{name: "missing group", gvk: schema.GroupVersionKind{Group: "kibana.eck.github.com", Version: "v1alpha1", Kind: "Dashboard"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := KindInstalled(mapper, tt.gvk)
+			if err != nil {
+				t.Fatalf("KindInstalled() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("KindInstalled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
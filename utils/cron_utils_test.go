@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	// Friday
+	from := time.Date(2026, time.January, 9, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		expression string
+		want       time.Time
+	}{
+		{expression: "* * * * *", want: time.Date(2026, time.January, 9, 10, 31, 0, 0, time.UTC)},
+		{expression: "0 7 * * *", want: time.Date(2026, time.January, 10, 7, 0, 0, 0, time.UTC)},
+		{expression: "0 7 * * 1-5", want: time.Date(2026, time.January, 12, 7, 0, 0, 0, time.UTC)},
+		{expression: "0 7 * * mon,fri", want: time.Date(2026, time.January, 12, 7, 0, 0, 0, time.UTC)},
+		{expression: "*/15 * * * *", want: time.Date(2026, time.January, 9, 10, 45, 0, 0, time.UTC)},
+		{expression: "0 0 1 * *", want: time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{expression: "0 0 1 jun *", want: time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		{expression: "0 0 13 * 5", want: time.Date(2026, time.January, 13, 0, 0, 0, 0, time.UTC)},
+		{expression: "0 9 * * 7", want: time.Date(2026, time.January, 11, 9, 0, 0, 0, time.UTC)},
+		{expression: "@weekly", want: time.Date(2026, time.January, 11, 0, 0, 0, 0, time.UTC)},
+		{expression: "@hourly", want: time.Date(2026, time.January, 9, 11, 0, 0, 0, time.UTC)},
+		{expression: "0 0 30 2 *", want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			schedule, err := ParseCronSchedule(tt.expression)
+			if err != nil {
+				t.Fatalf("ParseCronSchedule() error = %v", err)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronSchedule_NextInLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	schedule, err := ParseCronSchedule("0 7 * * *")
+	if err != nil {
+		t.Fatalf("ParseCronSchedule() error = %v", err)
+	}
+	got := schedule.Next(time.Date(2026, time.January, 9, 7, 30, 0, 0, time.UTC).In(berlin))
+	if want := time.Date(2026, time.January, 10, 6, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next() = %v, want %v", got.UTC(), want)
+	}
+}
+
+func TestParseCronSchedule_Invalid(t *testing.T) {
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *",
+		"* * * * 8", "*/0 * * * *", "5-1 * * * *", "* * * foo *"} {
+		if _, err := ParseCronSchedule(expression); err == nil {
+			t.Errorf("ParseCronSchedule(%q) expected an error", expression)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package elasticsearch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"eck-custom-resources/utils"
+
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ApikeyConsumerAnnotationPrefix prefixes the pod template annotation holding the hash of the Secret of the API key
+// a workload consumes. The annotation changes with the Secret, which rolls out the workload when the API key is
+// rotated.
+const ApikeyConsumerAnnotationPrefix = "apikey.es.eck.github.com/"
+
+// ApikeyConsumerAnnotation returns the pod template annotation of the ElasticsearchApikey
+func ApikeyConsumerAnnotation(apikeyName string) string {
+	return ApikeyConsumerAnnotationPrefix + truncateName(apikeyName, validation.LabelValueMaxLength)
+}
+
+// ApikeySecretHash returns the hash of the data of the Secret of an API key
+func ApikeySecretHash(secret k8sv1.Secret) (string, error) {
+	return utils.SpecHash(secret.Data)
+}
+
+// InjectApikeySecret sets the annotation of the ElasticsearchApikey to hash in the pod template and, if mountPath is
+// set, mounts the Secret read-only into all its containers. It returns whether the pod template changed.
+func InjectApikeySecret(template *k8sv1.PodTemplateSpec, apikeyName string, secretName string, mountPath string, hash string) bool {
+	changed := false
+	annotation := ApikeyConsumerAnnotation(apikeyName)
+	if template.Annotations[annotation] != hash {
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[annotation] = hash
+		changed = true
+	}
+	if mountPath == "" {
+		return removeApikeyVolume(&template.Spec, apikeyName) || changed
+	}
+
+	volumeName := apikeyVolumeName(apikeyName)
+	volume := k8sv1.Volume{Name: volumeName, VolumeSource: k8sv1.VolumeSource{Secret: &k8sv1.SecretVolumeSource{SecretName: secretName}}}
+	if i := volumeIndex(template.Spec.Volumes, volumeName); i < 0 {
+		template.Spec.Volumes = append(template.Spec.Volumes, volume)
+		changed = true
+	} else if secret := template.Spec.Volumes[i].Secret; secret == nil || secret.SecretName != secretName {
+		template.Spec.Volumes[i] = volume
+		changed = true
+	}
+
+	mount := k8sv1.VolumeMount{Name: volumeName, MountPath: mountPath, ReadOnly: true}
+	for c := range template.Spec.Containers {
+		container := &template.Spec.Containers[c]
+		if i := volumeMountIndex(container.VolumeMounts, volumeName); i < 0 {
+			container.VolumeMounts = append(container.VolumeMounts, mount)
+			changed = true
+		} else if container.VolumeMounts[i] != mount {
+			container.VolumeMounts[i] = mount
+			changed = true
+		}
+	}
+	return changed
+}
+
+// RemoveApikeySecret removes what InjectApikeySecret added to the pod template for the ElasticsearchApikey. It
+// returns whether the pod template changed.
+func RemoveApikeySecret(template *k8sv1.PodTemplateSpec, apikeyName string) bool {
+	changed := false
+	annotation := ApikeyConsumerAnnotation(apikeyName)
+	if _, ok := template.Annotations[annotation]; ok {
+		delete(template.Annotations, annotation)
+		changed = true
+	}
+	return removeApikeyVolume(&template.Spec, apikeyName) || changed
+}
+
+// IsApikeyConsumer reports whether the Secret of the ElasticsearchApikey is injected into the pod template
+func IsApikeyConsumer(template k8sv1.PodTemplateSpec, apikeyName string) bool {
+	_, ok := template.Annotations[ApikeyConsumerAnnotation(apikeyName)]
+	return ok
+}
+
+func removeApikeyVolume(spec *k8sv1.PodSpec, apikeyName string) bool {
+	changed := false
+	volumeName := apikeyVolumeName(apikeyName)
+	if i := volumeIndex(spec.Volumes, volumeName); i >= 0 {
+		spec.Volumes = append(spec.Volumes[:i], spec.Volumes[i+1:]...)
+		changed = true
+	}
+	for c := range spec.Containers {
+		container := &spec.Containers[c]
+		if i := volumeMountIndex(container.VolumeMounts, volumeName); i >= 0 {
+			container.VolumeMounts = append(container.VolumeMounts[:i], container.VolumeMounts[i+1:]...)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// apikeyVolumeName returns the name of the volume the Secret of the ElasticsearchApikey is mounted from
+func apikeyVolumeName(apikeyName string) string {
+	return truncateName("apikey-"+strings.ReplaceAll(apikeyName, ".", "-"), validation.DNS1123LabelMaxLength)
+}
+
+// truncateName returns name, shortened to maxLength with a hash of the full name as suffix if it is longer
+func truncateName(name string, maxLength int) string {
+	if len(name) <= maxLength {
+		return name
+	}
+	hash := sha256.Sum256([]byte(name))
+	return name[:maxLength-9] + "-" + hex.EncodeToString(hash[:4])
+}
+
+func volumeIndex(volumes []k8sv1.Volume, name string) int {
+	for i, volume := range volumes {
+		if volume.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func volumeMountIndex(mounts []k8sv1.VolumeMount, name string) int {
+	for i, mount := range mounts {
+		if mount.Name == name {
+			return i
+		}
+	}
+	return -1
+}
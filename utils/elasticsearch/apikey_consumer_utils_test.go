@@ -0,0 +1,71 @@
+package elasticsearch
+
+import (
+	"strings"
+	"testing"
+
+	k8sv1 "k8s.io/api/core/v1"
+)
+
+func TestInjectApikeySecret(t *testing.T) {
+	template := k8sv1.PodTemplateSpec{Spec: k8sv1.PodSpec{Containers: []k8sv1.Container{
+		{Name: "app", VolumeMounts: []k8sv1.VolumeMount{{Name: "data", MountPath: "/data"}}},
+		{Name: "sidecar"},
+	}}}
+
+	if !InjectApikeySecret(&template, "ingest", "ingest-key", "/etc/apikey", "hash-1") {
+		t.Fatal("Expected the first injection to change the template")
+	}
+	if template.Annotations["apikey.es.eck.github.com/ingest"] != "hash-1" {
+		t.Errorf("Expected the hash annotation, got %v", template.Annotations)
+	}
+	if len(template.Spec.Volumes) != 1 || template.Spec.Volumes[0].Name != "apikey-ingest" || template.Spec.Volumes[0].Secret.SecretName != "ingest-key" {
+		t.Errorf("Expected the Secret volume, got %v", template.Spec.Volumes)
+	}
+	for _, container := range template.Spec.Containers {
+		mount := container.VolumeMounts[len(container.VolumeMounts)-1]
+		if mount.Name != "apikey-ingest" || mount.MountPath != "/etc/apikey" || !mount.ReadOnly {
+			t.Errorf("Expected the Secret to be mounted into container %s, got %v", container.Name, container.VolumeMounts)
+		}
+	}
+
+	if InjectApikeySecret(&template, "ingest", "ingest-key", "/etc/apikey", "hash-1") {
+		t.Error("Expected an unchanged Secret to leave the template unchanged")
+	}
+	if !InjectApikeySecret(&template, "ingest", "ingest-key", "/etc/apikey", "hash-2") || template.Annotations["apikey.es.eck.github.com/ingest"] != "hash-2" {
+		t.Error("Expected a rotated Secret to change the annotation")
+	}
+	if !InjectApikeySecret(&template, "ingest", "ingest-key", "", "hash-2") || len(template.Spec.Volumes) != 0 || len(template.Spec.Containers[1].VolumeMounts) != 0 {
+		t.Errorf("Expected the volume to be removed without mount path, got %v", template.Spec)
+	}
+
+	InjectApikeySecret(&template, "ingest", "ingest-key", "/etc/apikey", "hash-2")
+	if !IsApikeyConsumer(template, "ingest") || IsApikeyConsumer(template, "other") {
+		t.Error("Expected the template to consume only the injected API key")
+	}
+	if !RemoveApikeySecret(&template, "ingest") {
+		t.Fatal("Expected the removal to change the template")
+	}
+	if len(template.Annotations) != 0 || len(template.Spec.Volumes) != 0 || len(template.Spec.Containers[0].VolumeMounts) != 1 || len(template.Spec.Containers[1].VolumeMounts) != 0 {
+		t.Errorf("Expected only the injected annotation, volume and mounts to be removed, got %v", template)
+	}
+	if RemoveApikeySecret(&template, "ingest") {
+		t.Error("Expected a second removal to leave the template unchanged")
+	}
+}
+
+func TestApikeyConsumerNames(t *testing.T) {
+	long := strings.Repeat("a", 70) + ".example"
+	if annotation := ApikeyConsumerAnnotation(long); len(strings.TrimPrefix(annotation, ApikeyConsumerAnnotationPrefix)) != 63 {
+		t.Errorf("Expected the annotation name to be truncated to 63 characters, got %s", annotation)
+	}
+	if ApikeyConsumerAnnotation(long) == ApikeyConsumerAnnotation(strings.Repeat("a", 70)) {
+		t.Error("Expected truncated names to differ by their hash")
+	}
+	if name := apikeyVolumeName("key.example"); name != "apikey-key-example" {
+		t.Errorf("Expected dots to be replaced in the volume name, got %s", name)
+	}
+	if name := apikeyVolumeName(long); len(name) != 63 {
+		t.Errorf("Expected the volume name to be truncated to 63 characters, got %s", name)
+	}
+}
@@ -0,0 +1,55 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+)
+
+// Keys of the Secret of an API key written by the built-in secret formats
+const (
+	ApikeySecretEnvKey                 = "ES_API_KEY"
+	ApikeySecretBeatsOutputKey         = "elasticsearch-output.yml"
+	ApikeySecretAuthorizationHeaderKey = "authorization"
+)
+
+// ApikeySecretFormats are the secret formats of ElasticsearchApikeys not setting spec.secretFormats
+var ApikeySecretFormats []v1alpha1.ApikeySecretFormat
+
+// ParseApikeySecretFormats parses a comma separated list of secret formats, e.g. Env,AuthorizationHeader
+func ParseApikeySecretFormats(value string) ([]v1alpha1.ApikeySecretFormat, error) {
+	var formats []v1alpha1.ApikeySecretFormat
+	for _, format := range strings.Split(value, ",") {
+		switch format := v1alpha1.ApikeySecretFormat(strings.TrimSpace(format)); format {
+		case "":
+		case v1alpha1.ApikeySecretFormatEnv, v1alpha1.ApikeySecretFormatBeatsOutput, v1alpha1.ApikeySecretFormatAuthorizationHeader:
+			formats = append(formats, format)
+		default:
+			return nil, fmt.Errorf("invalid secret format %q, expected %s, %s or %s", format,
+				v1alpha1.ApikeySecretFormatEnv, v1alpha1.ApikeySecretFormatBeatsOutput, v1alpha1.ApikeySecretFormatAuthorizationHeader)
+		}
+	}
+	return formats, nil
+}
+
+// RenderApikeySecretFormats returns the keys of the secret formats of the ElasticsearchApikey, rendered from the
+// values of ApikeySecretTemplateValues
+func RenderApikeySecretFormats(apikey v1alpha1.ElasticsearchApikey, values map[string]interface{}) map[string][]byte {
+	formats := apikey.Spec.SecretFormats
+	if formats == nil {
+		formats = ApikeySecretFormats
+	}
+	data := make(map[string][]byte, len(formats))
+	for _, format := range formats {
+		switch format {
+		case v1alpha1.ApikeySecretFormatEnv:
+			data[ApikeySecretEnvKey] = []byte(fmt.Sprint(values["encoded"]))
+		case v1alpha1.ApikeySecretFormatBeatsOutput:
+			data[ApikeySecretBeatsOutputKey] = []byte(fmt.Sprintf("output.elasticsearch.api_key: %q\n", fmt.Sprintf("%s:%s", values["id"], values["apiKey"])))
+		case v1alpha1.ApikeySecretFormatAuthorizationHeader:
+			data[ApikeySecretAuthorizationHeaderKey] = []byte(fmt.Sprintf("ApiKey %s", values["encoded"]))
+		}
+	}
+	return data
+}
@@ -0,0 +1,75 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseApikeySecretFormats(t *testing.T) {
+	formats, err := ParseApikeySecretFormats("Env, AuthorizationHeader")
+	if err != nil {
+		t.Fatalf("ParseApikeySecretFormats() error = %v", err)
+	}
+	if want := []v1alpha1.ApikeySecretFormat{v1alpha1.ApikeySecretFormatEnv, v1alpha1.ApikeySecretFormatAuthorizationHeader}; !reflect.DeepEqual(formats, want) {
+		t.Errorf("ParseApikeySecretFormats() = %v, want %v", formats, want)
+	}
+	if formats, err := ParseApikeySecretFormats(""); err != nil || formats != nil {
+		t.Errorf("Expected no formats, got %v, %v", formats, err)
+	}
+	if _, err := ParseApikeySecretFormats("Env,Json"); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
+
+func TestApplyApikeySecretTemplate_SecretFormats(t *testing.T) {
+	defer func(formats []v1alpha1.ApikeySecretFormat) { ApikeySecretFormats = formats }(ApikeySecretFormats)
+	ApikeySecretFormats = []v1alpha1.ApikeySecretFormat{v1alpha1.ApikeySecretFormatEnv}
+
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	_ = k8sv1.AddToScheme(scheme)
+	ctx := context.Background()
+
+	apikey := &v1alpha1.ElasticsearchApikey{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default", UID: "agent-uid"},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).Build()
+	encoded := base64.StdEncoding.EncodeToString([]byte("key-id:secret"))
+	data := map[string][]byte{"id": []byte("key-id"), "name": []byte("agent"), "apikey": []byte(encoded)}
+	if err := CreateApikeySecret(cli, ctx, apikey, data); err != nil {
+		t.Fatalf("CreateApikeySecret() error = %v", err)
+	}
+
+	if _, err := ApplyApikeySecretTemplate(cli, ctx, apikey, ""); err != nil {
+		t.Fatalf("ApplyApikeySecretTemplate() error = %v", err)
+	}
+	sec, _ := GetAPIKeySecret(cli, ctx, "default", "agent")
+	if string(sec.Data[ApikeySecretEnvKey]) != encoded || len(sec.Data) != 4 {
+		t.Errorf("Expected the default format to be added, got %v", sec.Data)
+	}
+
+	apikey.Spec.SecretFormats = []v1alpha1.ApikeySecretFormat{v1alpha1.ApikeySecretFormatBeatsOutput, v1alpha1.ApikeySecretFormatAuthorizationHeader}
+	apikey.Spec.SecretTemplate = &v1alpha1.SecretTemplate{Data: map[string]string{ApikeySecretAuthorizationHeaderKey: "Bearer {{ .Values.encoded }}"}}
+	if _, err := ApplyApikeySecretTemplate(cli, ctx, apikey, ""); err != nil {
+		t.Fatalf("ApplyApikeySecretTemplate() error = %v", err)
+	}
+	sec, _ = GetAPIKeySecret(cli, ctx, "default", "agent")
+	if _, ok := sec.Data[ApikeySecretEnvKey]; ok {
+		t.Error("Expected spec.secretFormats to replace the default formats")
+	}
+	if got, want := string(sec.Data[ApikeySecretBeatsOutputKey]), "output.elasticsearch.api_key: \"key-id:secret\"\n"; got != want {
+		t.Errorf("Expected the Beats output %q, got %q", want, got)
+	}
+	if got := string(sec.Data[ApikeySecretAuthorizationHeaderKey]); got != "Bearer "+encoded {
+		t.Errorf("Expected spec.secretTemplate to take precedence over the formats, got %s", got)
+	}
+}
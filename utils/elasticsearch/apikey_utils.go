@@ -4,23 +4,33 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"regexp"
 	"strings"
+	"time"
 
 	"eck-custom-resources/api/es.eck/v1alpha1"
 	"eck-custom-resources/utils"
 
 	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/prometheus/client_golang/prometheus"
 	k8sv1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
+// ApikeySecretLabel is set on the Secrets of API keys to the name of the ElasticsearchApikey they belong to
+const ApikeySecretLabel = "es.eck.github.com/elasticsearchapikey"
+
 type APIKey struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
@@ -29,6 +39,28 @@ type GetAPIKeysResponse struct {
 	APIKeys []APIKey `json:"api_keys"`
 }
 
+// APIKeyInfo is the expiration and invalidation state of an API key
+type APIKeyInfo struct {
+	ID string `json:"id"`
+	// Expiration in milliseconds since the epoch, 0 if the key does not expire
+	Expiration  int64 `json:"expiration,omitempty"`
+	Invalidated bool  `json:"invalidated"`
+}
+
+var apikeysLost = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "eck_cr_apikey_lost_total",
+	Help: "Number of times the API key of an ElasticsearchApikey was found deleted, invalidated or expired in Elasticsearch.",
+}, []string{"namespace", "name"})
+
+func init() {
+	metrics.Registry.MustRegister(apikeysLost)
+}
+
+// RecordApikeyLost counts the loss of the API key of the ElasticsearchApikey in the eck_cr_apikey_lost_total metric
+func RecordApikeyLost(apikey *v1alpha1.ElasticsearchApikey) {
+	apikeysLost.WithLabelValues(apikey.Namespace, apikey.Name).Inc()
+}
+
 // Accepts: -1, 0, or <number>[nanos|micros|ms|s|m|h|d]
 // <number> may be an integer or decimal (e.g., 1.5h).
 var esDurationRe = regexp.MustCompile(`^(?i)(?:-1|0|(?:\d+(?:\.\d+)?)(?:nanos|micros|ms|s|m|h|d))$`)
@@ -61,7 +93,7 @@ func DeleteApikey(cli client.Client, ctx context.Context, esClient *elasticsearc
 	}
 	defer res.Body.Close()
 
-	if err := DeleteApikeySecret(cli, ctx, req.Namespace, req.Name); err != nil {
+	if err := DeleteApikeySecret(cli, ctx, &apikey); err != nil {
 		return utils.GetRequeueResult(), err
 	}
 
@@ -75,7 +107,7 @@ func UpdateApikey(cli client.Client, ctx context.Context, esClient *elasticsearc
 	}
 	// If this is an UPDATE event: update only the "body"
 
-	apiBody, _ := removeField(apikey.Spec.Body, "name")
+	apiBody, _ := removeField(apikey.Spec.GetBody(), "name")
 
 	if _, err := esClient.Security.UpdateAPIKey(
 		apikeyID,
@@ -129,6 +161,114 @@ func GetApiKeyWithID(cli client.Client, ctx context.Context, esClient *elasticse
 	}
 	return getResp.APIKeys[0], err
 }
+
+// GetApikeyInfo returns the expiration and invalidation state of the API key, which is also returned for
+// invalidated and expired keys
+func GetApikeyInfo(ctx context.Context, esClient *elasticsearch.Client, apiKeyID string) (APIKeyInfo, error) {
+	res, err := esClient.Security.GetAPIKey(
+		esClient.Security.GetAPIKey.WithID(apiKeyID),
+		esClient.Security.GetAPIKey.WithContext(ctx),
+	)
+	if err != nil {
+		return APIKeyInfo{}, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return APIKeyInfo{}, fmt.Errorf("error response from GetAPIKey: %s", res.String())
+	}
+
+	var response struct {
+		APIKeys []APIKeyInfo `json:"api_keys"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return APIKeyInfo{}, err
+	}
+	if len(response.APIKeys) == 0 {
+		return APIKeyInfo{}, fmt.Errorf("API key %s not found", apiKeyID)
+	}
+	return response.APIKeys[0], nil
+}
+
+// GetApikeyLostReason returns why the API key with the ID can no longer be used, "was deleted", "was invalidated"
+// or "expired", and an empty string if it is active
+func GetApikeyLostReason(ctx context.Context, esClient *elasticsearch.Client, apiKeyID string, now time.Time) (string, error) {
+	res, err := esClient.Security.GetAPIKey(
+		esClient.Security.GetAPIKey.WithID(apiKeyID),
+		esClient.Security.GetAPIKey.WithContext(ctx),
+	)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return "was deleted", nil
+	}
+	if res.IsError() {
+		return "", fmt.Errorf("error response from GetAPIKey: %s", res.String())
+	}
+
+	var response struct {
+		APIKeys []APIKeyInfo `json:"api_keys"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return "", err
+	}
+	switch {
+	case len(response.APIKeys) == 0:
+		return "was deleted", nil
+	case response.APIKeys[0].Invalidated:
+		return "was invalidated", nil
+	case response.APIKeys[0].Expiration > 0 && !now.Before(time.UnixMilli(response.APIKeys[0].Expiration)):
+		return "expired", nil
+	}
+	return "", nil
+}
+
+// SetApikeyExpiryStatus writes the expiration and invalidation state of the API key to the status and sets the
+// Expiring condition, which is True once the key expires within spec.expiryWarningDays or has expired. It returns
+// the reason and message of a warning if the key became invalidated or expiring with this update, empty strings
+// otherwise.
+func SetApikeyExpiryStatus(apikey *v1alpha1.ElasticsearchApikey, info APIKeyInfo, now time.Time) (string, string) {
+	wasExpiring := meta.IsStatusConditionTrue(apikey.Status.Conditions, "Expiring")
+	wasInvalidated := apikey.Status.Invalidated
+
+	apikey.Status.Invalidated = info.Invalidated
+	apikey.Status.Expiration = nil
+	if info.Expiration > 0 {
+		apikey.Status.Expiration = &metav1.Time{Time: time.UnixMilli(info.Expiration)}
+	}
+
+	condition := metav1.Condition{
+		Type:               "Expiring",
+		Status:             metav1.ConditionFalse,
+		Reason:             "NotExpiring",
+		Message:            "The API key does not expire",
+		ObservedGeneration: apikey.GetGeneration(),
+	}
+	if expiration := apikey.Status.Expiration; expiration != nil {
+		warnAfter := expiration.AddDate(0, 0, -int(apikey.Spec.ExpiryWarningDays))
+		condition.Message = fmt.Sprintf("The API key expires at %s", expiration.UTC().Format(time.RFC3339))
+		switch {
+		case !now.Before(expiration.Time):
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "Expired"
+			condition.Message = fmt.Sprintf("The API key expired at %s", expiration.UTC().Format(time.RFC3339))
+		case apikey.Spec.ExpiryWarningDays > 0 && !now.Before(warnAfter):
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "ExpiresSoon"
+		}
+	}
+	meta.SetStatusCondition(&apikey.Status.Conditions, condition)
+
+	switch {
+	case !wasInvalidated && info.Invalidated:
+		return "Invalidated", fmt.Sprintf("The API key %s was invalidated", info.ID)
+	case !wasExpiring && condition.Status == metav1.ConditionTrue:
+		return condition.Reason, condition.Message
+	}
+	return "", ""
+}
+
 func GetApiKeyWithName(cli client.Client, ctx context.Context, esClient *elasticsearch.Client, apiKeyName string) []APIKey {
 	getRes, err := esClient.Security.GetAPIKey(
 		esClient.Security.GetAPIKey.WithName(apiKeyName),
@@ -191,8 +331,20 @@ func ApiKeyNameExist(cli client.Client, ctx context.Context, esClient *elasticse
 	return keyExists
 }
 func CreateApikey(cli client.Client, ctx context.Context, esClient *elasticsearch.Client, apikey *v1alpha1.ElasticsearchApikey, req ctrl.Request) (ctrl.Result, error) {
+	// Refuse to overwrite a foreign Secret before the API key is created, otherwise a key leaks on every retry
+	if sec, err := GetAPIKeySecret(cli, ctx, apikey.Namespace, apikey.GetSecretName()); err == nil {
+		if err := VerifyApikeySecretOwnership(sec, apikey); err != nil {
+			return utils.GetRequeueResult(), err
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return utils.GetRequeueResult(), err
+	}
+	if resumed, err := resumePendingApikey(cli, ctx, esClient, apikey); err != nil || resumed {
+		return utils.GetRequeueResult(), err
+	}
+
 	response, err := esClient.Security.CreateAPIKey(
-		strings.NewReader(apikey.Spec.Body),
+		strings.NewReader(apikey.Spec.GetBody()),
 		esClient.Security.CreateAPIKey.WithContext(ctx),
 	)
 	if err != nil {
@@ -232,8 +384,18 @@ func CreateApikey(cli client.Client, ctx context.Context, esClient *elasticsearc
 		"apikey": []byte(apikeyEncoded),
 	}
 
-	if err := CreateApikeySecret(cli, ctx, req.Namespace, req.Name, data); err != nil {
-		return utils.GetRequeueResult(), fmt.Errorf("error creating API key Secret: %v", &err)
+	// Checkpoint the key before its Secret is written, so an interrupted creation is resumed instead of leaking the key
+	if err := setPendingApikey(cli, ctx, apikey, apikeyId); err != nil {
+		if invalidateErr := invalidateApikey(ctx, esClient, apikeyId); invalidateErr != nil {
+			return utils.GetRequeueResult(), errors.Join(err, invalidateErr)
+		}
+		return utils.GetRequeueResult(), fmt.Errorf("error recording the pending API key: %w", err)
+	}
+	if err := CreateApikeySecret(cli, ctx, apikey, data); err != nil {
+		return utils.GetRequeueResult(), fmt.Errorf("error creating API key Secret: %w", err)
+	}
+	if err := setPendingApikey(cli, ctx, apikey, ""); err != nil {
+		return utils.GetRequeueResult(), fmt.Errorf("error clearing the pending API key: %w", err)
 	}
 
 	apikey.Status.APIKeyID = apikeyId
@@ -244,27 +406,76 @@ func CreateApikey(cli client.Client, ctx context.Context, esClient *elasticsearc
 	return utils.GetRequeueResult(), nil
 }
 
+// resumePendingApikey resumes a creation interrupted between creating the API key in Elasticsearch and clearing
+// status.pendingAPIKeyID. It reports whether the creation completed: the Secret holds the pending key. Otherwise the
+// pending key is invalidated, its secret was never stored, and a new key has to be created.
+func resumePendingApikey(cli client.Client, ctx context.Context, esClient *elasticsearch.Client, apikey *v1alpha1.ElasticsearchApikey) (bool, error) {
+	pending := apikey.Status.PendingAPIKeyID
+	if pending == "" {
+		return false, nil
+	}
+	completed := false
+	if sec, err := GetAPIKeySecret(cli, ctx, apikey.Namespace, apikey.GetSecretName()); err == nil {
+		completed = string(sec.Data["id"]) == pending
+	} else if !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	if !completed {
+		if err := invalidateApikey(ctx, esClient, pending); err != nil {
+			return false, err
+		}
+	}
+	if err := setPendingApikey(cli, ctx, apikey, ""); err != nil {
+		return false, err
+	}
+	if completed {
+		apikey.Status.APIKeyID = pending
+	}
+	return completed, nil
+}
+
+// setPendingApikey sets status.pendingAPIKeyID of the ElasticsearchApikey, an empty ID clears it
+func setPendingApikey(cli client.Client, ctx context.Context, apikey *v1alpha1.ElasticsearchApikey, apikeyID string) error {
+	patch := client.MergeFrom(apikey.DeepCopy())
+	apikey.Status.PendingAPIKeyID = apikeyID
+	return cli.Status().Patch(ctx, apikey, patch)
+}
+
+// invalidateApikey invalidates the API key in Elasticsearch
+func invalidateApikey(ctx context.Context, esClient *elasticsearch.Client, apikeyID string) error {
+	body, err := json.Marshal(map[string][]string{"ids": {apikeyID}})
+	if err != nil {
+		return err
+	}
+	res, err := esClient.Security.InvalidateAPIKey(bytes.NewReader(body), esClient.Security.InvalidateAPIKey.WithContext(ctx))
+	if err != nil || res.IsError() {
+		return GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+	return nil
+}
+
 func GetAPIKeyID(cli client.Client, ctx context.Context, req ctrl.Request, apikey v1alpha1.ElasticsearchApikey) (string, error) {
-	if sec, err := GetAPIKeySecret(cli, ctx, req.Namespace, req.Name); err == nil {
+	if sec, err := GetAPIKeySecret(cli, ctx, req.Namespace, apikey.GetSecretName()); err == nil {
 		if id, ok := sec.Data["id"]; ok {
 			return string(id), nil
 		}
-		return "", fmt.Errorf("secret %s/%s found but missing 'id' field", req.Namespace, req.Name)
+		return "", fmt.Errorf("secret %s/%s found but missing 'id' field", req.Namespace, apikey.GetSecretName())
 	}
 
 	if len(apikey.Status.APIKeyID) > 0 {
 		return apikey.Status.APIKeyID, nil
 	}
 
-	return "", fmt.Errorf("neither secret %s/%s nor in CRD status provided API key id", req.Namespace, req.Name)
+	return "", fmt.Errorf("neither secret %s/%s nor in CRD status provided API key id", req.Namespace, apikey.GetSecretName())
 
 }
 
 func CreateApikeyold(cli client.Client, ctx context.Context, esClient *elasticsearch.Client, apikey v1alpha1.ElasticsearchApikey, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
-	sec, err := GetAPIKeySecret(cli, ctx, req.Namespace, req.Name)
+	sec, err := GetAPIKeySecret(cli, ctx, req.Namespace, apikey.GetSecretName())
 	if err != nil {
-		logger.Info("Apikey secret %s not found", req.Name)
+		logger.Info("Apikey secret %s not found", apikey.GetSecretName())
 	}
 	apikeyId := string(sec.Data["id"])
 
@@ -300,7 +511,7 @@ func CreateApikeyold(cli client.Client, ctx context.Context, esClient *elasticse
 		if containsID(getResp.APIKeys, apikeyId) {
 			// If this is an UPDATE event: update only the "body"
 
-			apiBody, _ := removeField(apikey.Spec.Body, "name")
+			apiBody, _ := removeField(apikey.Spec.GetBody(), "name")
 
 			if _, err := esClient.Security.UpdateAPIKey(
 				apikeyId,
@@ -316,7 +527,7 @@ func CreateApikeyold(cli client.Client, ctx context.Context, esClient *elasticse
 		// Key exists but Secret missing → create Secret from existing key
 
 		response, err := esClient.Security.CreateAPIKey(
-			strings.NewReader(apikey.Spec.Body),
+			strings.NewReader(apikey.Spec.GetBody()),
 			esClient.Security.CreateAPIKey.WithContext(ctx),
 		)
 		if err != nil {
@@ -356,7 +567,7 @@ func CreateApikeyold(cli client.Client, ctx context.Context, esClient *elasticse
 			"apikey": []byte(apikeyEncoded),
 		}
 
-		if err := CreateApikeySecret(cli, ctx, req.Namespace, req.Name, data); err != nil {
+		if err := CreateApikeySecret(cli, ctx, &apikey, data); err != nil {
 			return utils.GetRequeueResult(), fmt.Errorf("error creating API key Secret: %v", &err)
 		}
 		//apikey.Status.APIKeyID = apikeyId
@@ -377,8 +588,11 @@ func GetAPIKeySecret(cli client.Client, ctx context.Context, namespace string, s
 	return &sec, nil
 }
 
-func CreateApikeySecret(cli client.Client, ctx context.Context, namespace string, secretName string, data map[string][]byte) error {
-	key := client.ObjectKey{Namespace: namespace, Name: secretName}
+// CreateApikeySecret creates or updates the Secret of the API key. The Secret is labeled with and controlled by the
+// ElasticsearchApikey, so it is garbage collected with it; a Secret of the same name managed by anything else is
+// left untouched and an error is returned.
+func CreateApikeySecret(cli client.Client, ctx context.Context, apikey *v1alpha1.ElasticsearchApikey, data map[string][]byte) error {
+	key := client.ObjectKey{Namespace: apikey.Namespace, Name: apikey.GetSecretName()}
 	var sec k8sv1.Secret
 
 	if err := cli.Get(ctx, key, &sec); err != nil {
@@ -386,20 +600,37 @@ func CreateApikeySecret(cli client.Client, ctx context.Context, namespace string
 			// Create
 			sec = k8sv1.Secret{
 				ObjectMeta: metav1.ObjectMeta{
-					Namespace: namespace,
-					Name:      secretName,
+					Namespace: key.Namespace,
+					Name:      key.Name,
+					Labels:    map[string]string{ApikeySecretLabel: apikey.Name},
 				},
 				Type: k8sv1.SecretTypeOpaque,
 				Data: data,
 			}
+			maps.Copy(sec.Labels, utils.OperatorLabels())
+			if err := controllerutil.SetControllerReference(apikey, &sec, cli.Scheme()); err != nil {
+				return err
+			}
 			return cli.Create(ctx, &sec)
 		}
 		return err
 	}
 
+	if err := VerifyApikeySecretOwnership(&sec, apikey); err != nil {
+		return err
+	}
+
 	// Update with Patch to avoid resourceVersion conflicts
 	patch := client.MergeFrom(sec.DeepCopy())
 	sec.Type = k8sv1.SecretTypeOpaque
+	if sec.Labels == nil {
+		sec.Labels = map[string]string{}
+	}
+	sec.Labels[ApikeySecretLabel] = apikey.Name
+	maps.Copy(sec.Labels, utils.OperatorLabels())
+	if err := controllerutil.SetControllerReference(apikey, &sec, cli.Scheme()); err != nil {
+		return err
+	}
 	if sec.Data == nil {
 		sec.Data = map[string][]byte{}
 	}
@@ -409,16 +640,114 @@ func CreateApikeySecret(cli client.Client, ctx context.Context, namespace string
 	return cli.Patch(ctx, &sec, patch)
 }
 
-func DeleteApikeySecret(cli client.Client, ctx context.Context, namespace string, secretName string) error {
+// DeleteApikeySecret deletes the Secret of the API key, unless it is managed by anything else
+func DeleteApikeySecret(cli client.Client, ctx context.Context, apikey *v1alpha1.ElasticsearchApikey) error {
 	secret := &k8sv1.Secret{}
 
-	if err := cli.Get(ctx, client.ObjectKey{Namespace: namespace, Name: secretName}, secret); err != nil {
-		return err
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: apikey.Namespace, Name: apikey.GetSecretName()}, secret); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if err := VerifyApikeySecretOwnership(secret, apikey); err != nil {
+		return nil
 	}
 
 	if err := cli.Delete(ctx, secret); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	return nil
+}
+
+// VerifyApikeySecretOwnership returns an error unless the Secret is managed by the ElasticsearchApikey: it is
+// controlled by it, or it is not controlled by anything and either labeled for it or an unlabeled Secret holding
+// only the API key fields, as created by earlier versions of the operator. A Secret labeled for another operator
+// installation is never managed by it.
+func VerifyApikeySecretOwnership(sec *k8sv1.Secret, apikey *v1alpha1.ElasticsearchApikey) error {
+	if err := utils.CheckOperatorOwnership("Secret", sec); err != nil {
 		return err
 	}
+	if owner := metav1.GetControllerOf(sec); owner != nil {
+		if owner.Kind == "ElasticsearchApikey" && owner.Name == apikey.Name && (apikey.UID == "" || owner.UID == apikey.UID) {
+			return nil
+		}
+		return fmt.Errorf("secret %s/%s is managed by %s %s", sec.Namespace, sec.Name, owner.Kind, owner.Name)
+	}
+
+	if label, ok := sec.Labels[ApikeySecretLabel]; ok {
+		if label == apikey.Name {
+			return nil
+		}
+		return fmt.Errorf("secret %s/%s is managed by ElasticsearchApikey %s", sec.Namespace, sec.Name, label)
+	}
+
+	if sec.Name == apikey.Name && len(sec.Data) > 0 {
+		legacy := true
+		for k := range sec.Data {
+			if k != "id" && k != "name" && k != "apikey" {
+				legacy = false
+			}
+		}
+		if legacy {
+			return nil
+		}
+	}
+	return fmt.Errorf("secret %s/%s already exists and is not managed by ElasticsearchApikey %s", sec.Namespace, sec.Name, apikey.Name)
+}
+
+// BackfillApikeySecretOwners makes every ElasticsearchApikey the controller of its Secret, so Secrets created before
+// the Secrets were owned are garbage collected with their ElasticsearchApikey as well. Secrets which are managed by
+// anything else, and the ElasticsearchApikeys of other operator installations, are skipped. It returns the number of
+// Secrets patched.
+func BackfillApikeySecretOwners(cli client.Client, ctx context.Context) (int, error) {
+	logger := log.FromContext(ctx)
+
+	var apikeys v1alpha1.ElasticsearchApikeyList
+	if err := cli.List(ctx, &apikeys); err != nil {
+		return 0, err
+	}
+
+	patched := 0
+	for i := range apikeys.Items {
+		apikey := &apikeys.Items[i]
+		if !apikey.DeletionTimestamp.IsZero() || utils.CheckOperatorOwnership("ElasticsearchApikey", apikey) != nil {
+			continue
+		}
+		sec, err := GetAPIKeySecret(cli, ctx, apikey.Namespace, apikey.GetSecretName())
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return patched, err
+		}
+		if err := VerifyApikeySecretOwnership(sec, apikey); err != nil {
+			logger.Info("Not backfilling the owner of the API key Secret", "apikey", client.ObjectKeyFromObject(apikey), "reason", err.Error())
+			continue
+		}
+		changed, err := utils.EnsureControllerReference(cli, ctx, apikey, sec, map[string]string{ApikeySecretLabel: apikey.Name})
+		if err != nil {
+			return patched, err
+		}
+		if changed {
+			patched++
+		}
+	}
+	return patched, nil
+}
+
+// ApikeySecretOwnerMigration is a manager runnable running BackfillApikeySecretOwners once on the leader, after the
+// caches synced. Failures are logged and do not stop the manager.
+type ApikeySecretOwnerMigration struct {
+	Client client.Client
+}
+
+// Start implements manager.Runnable
+func (m *ApikeySecretOwnerMigration) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("apikey-secret-owner-migration")
+	patched, err := BackfillApikeySecretOwners(m.Client, log.IntoContext(ctx, logger))
+	if err != nil {
+		logger.Error(err, "Failed to backfill the owners of API key Secrets", "patched", patched)
+		return nil
+	}
+	logger.Info("Backfilled the owners of API key Secrets", "patched", patched)
 	return nil
 }
 
@@ -1,12 +1,24 @@
 package elasticsearch
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
 
 	"github.com/elastic/go-elasticsearch/v8"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestValidateExpiration(t *testing.T) {
@@ -473,3 +485,282 @@ func TestGetApiKeyWithID_ConnectionError(t *testing.T) {
 		t.Error("GetApiKeyWithID() with connection error should return an error")
 	}
 }
+
+func TestCreateApikeySecret_Ownership(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	_ = k8sv1.AddToScheme(scheme)
+
+	foreign := &k8sv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tls-cert", Namespace: "default"},
+		Data:       map[string][]byte{"tls.crt": []byte("cert")},
+	}
+	legacy := &k8sv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "default"},
+		Data:       map[string][]byte{"id": []byte("old-id"), "apikey": []byte("old-key")},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(foreign, legacy).Build()
+	data := map[string][]byte{"id": []byte("new-id"), "name": []byte("key"), "apikey": []byte("new-key")}
+
+	apikey := &v1alpha1.ElasticsearchApikey{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingest", Namespace: "default", UID: "ingest-uid"},
+		Spec:       v1alpha1.ElasticsearchApikeySpec{SecretName: "ingest-key"},
+	}
+	if err := CreateApikeySecret(cli, context.Background(), apikey, data); err != nil {
+		t.Fatalf("CreateApikeySecret() error = %v", err)
+	}
+	sec, err := GetAPIKeySecret(cli, context.Background(), "default", "ingest-key")
+	if err != nil {
+		t.Fatalf("GetAPIKeySecret() error = %v", err)
+	}
+	if owner := metav1.GetControllerOf(sec); owner == nil || owner.UID != "ingest-uid" {
+		t.Errorf("Expected the Secret to be controlled by the ElasticsearchApikey, got %v", owner)
+	}
+	if sec.Labels[ApikeySecretLabel] != "ingest" {
+		t.Errorf("Expected the Secret to be labeled, got %v", sec.Labels)
+	}
+
+	other := &v1alpha1.ElasticsearchApikey{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default", UID: "other-uid"},
+		Spec:       v1alpha1.ElasticsearchApikeySpec{SecretName: "ingest-key"},
+	}
+	if err := CreateApikeySecret(cli, context.Background(), other, data); err == nil {
+		t.Error("Expected the Secret of another ElasticsearchApikey to be rejected")
+	}
+
+	colliding := &v1alpha1.ElasticsearchApikey{ObjectMeta: metav1.ObjectMeta{Name: "tls-cert", Namespace: "default", UID: "tls-uid"}}
+	if err := CreateApikeySecret(cli, context.Background(), colliding, data); err == nil {
+		t.Error("Expected a foreign Secret to be rejected")
+	}
+	if err := DeleteApikeySecret(cli, context.Background(), colliding); err != nil {
+		t.Fatalf("DeleteApikeySecret() error = %v", err)
+	}
+	if _, err := GetAPIKeySecret(cli, context.Background(), "default", "tls-cert"); err != nil {
+		t.Errorf("Expected a foreign Secret to survive the deletion, got %v", err)
+	}
+
+	legacyApikey := &v1alpha1.ElasticsearchApikey{ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "default", UID: "legacy-uid"}}
+	if err := CreateApikeySecret(cli, context.Background(), legacyApikey, data); err != nil {
+		t.Fatalf("Expected the Secret of an earlier operator version to be adopted, got %v", err)
+	}
+	if sec, _ := GetAPIKeySecret(cli, context.Background(), "default", "legacy"); metav1.GetControllerOf(sec) == nil {
+		t.Error("Expected the adopted Secret to be controlled by the ElasticsearchApikey")
+	}
+}
+
+func TestBackfillApikeySecretOwners(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	_ = k8sv1.AddToScheme(scheme)
+
+	objects := []client.Object{
+		&v1alpha1.ElasticsearchApikey{ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "default", UID: "legacy-uid"}},
+		&v1alpha1.ElasticsearchApikey{ObjectMeta: metav1.ObjectMeta{Name: "tls-cert", Namespace: "default", UID: "tls-uid"}},
+		&v1alpha1.ElasticsearchApikey{ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default", UID: "pending-uid"}},
+		&k8sv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "default"},
+			Data:       map[string][]byte{"id": []byte("id"), "name": []byte("legacy"), "apikey": []byte("key")},
+		},
+		&k8sv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "tls-cert", Namespace: "default"},
+			Data:       map[string][]byte{"tls.crt": []byte("cert")},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+
+	patched, err := BackfillApikeySecretOwners(cli, context.Background())
+	if err != nil {
+		t.Fatalf("BackfillApikeySecretOwners() error = %v", err)
+	}
+	if patched != 1 {
+		t.Errorf("BackfillApikeySecretOwners() patched %d Secrets, want 1", patched)
+	}
+	if sec, _ := GetAPIKeySecret(cli, context.Background(), "default", "legacy"); metav1.GetControllerOf(sec) == nil {
+		t.Error("Expected the legacy Secret to be owned by its ElasticsearchApikey")
+	}
+	if sec, _ := GetAPIKeySecret(cli, context.Background(), "default", "tls-cert"); metav1.GetControllerOf(sec) != nil {
+		t.Error("Expected a foreign Secret to be left untouched")
+	}
+
+	if patched, err := BackfillApikeySecretOwners(cli, context.Background()); err != nil || patched != 0 {
+		t.Errorf("BackfillApikeySecretOwners() = %d, %v, want no patches on the second run", patched, err)
+	}
+}
+
+func TestSetApikeyExpiryStatus(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		info        APIKeyInfo
+		warningDays int32
+		wantStatus  metav1.ConditionStatus
+		wantReason  string
+	}{
+		{name: "no expiration", info: APIKeyInfo{ID: "k1"}, warningDays: 14, wantStatus: metav1.ConditionFalse},
+		{name: "expires later", info: APIKeyInfo{ID: "k1", Expiration: now.AddDate(0, 1, 0).UnixMilli()}, warningDays: 14, wantStatus: metav1.ConditionFalse},
+		{name: "expires soon", info: APIKeyInfo{ID: "k1", Expiration: now.AddDate(0, 0, 3).UnixMilli()}, warningDays: 14, wantStatus: metav1.ConditionTrue, wantReason: "ExpiresSoon"},
+		{name: "warning disabled", info: APIKeyInfo{ID: "k1", Expiration: now.AddDate(0, 0, 3).UnixMilli()}, wantStatus: metav1.ConditionFalse},
+		{name: "expired", info: APIKeyInfo{ID: "k1", Expiration: now.Add(-time.Hour).UnixMilli()}, warningDays: 14, wantStatus: metav1.ConditionTrue, wantReason: "Expired"},
+		{name: "invalidated", info: APIKeyInfo{ID: "k1", Invalidated: true}, warningDays: 14, wantStatus: metav1.ConditionFalse, wantReason: "Invalidated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apikey := &v1alpha1.ElasticsearchApikey{Spec: v1alpha1.ElasticsearchApikeySpec{ExpiryWarningDays: tt.warningDays}}
+
+			reason, message := SetApikeyExpiryStatus(apikey, tt.info, now)
+			if reason != tt.wantReason || (reason != "") != (message != "") {
+				t.Errorf("SetApikeyExpiryStatus() = %q, %q, want reason %q", reason, message, tt.wantReason)
+			}
+			if got := meta.FindStatusCondition(apikey.Status.Conditions, "Expiring"); got == nil || got.Status != tt.wantStatus {
+				t.Errorf("Expected Expiring condition %s, got %+v", tt.wantStatus, got)
+			}
+			if (apikey.Status.Expiration != nil) != (tt.info.Expiration > 0) || apikey.Status.Invalidated != tt.info.Invalidated {
+				t.Errorf("Unexpected status expiration %v, invalidated %v", apikey.Status.Expiration, apikey.Status.Invalidated)
+			}
+
+			// The warning is only returned once
+			if reason, _ := SetApikeyExpiryStatus(apikey, tt.info, now); reason != "" {
+				t.Errorf("Expected no repeated warning, got %s", reason)
+			}
+		})
+	}
+}
+
+func TestGetApikeyInfo_FakeElasticsearch(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	apikey := &v1alpha1.ElasticsearchApikey{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingest", Namespace: "default"},
+		Spec:       v1alpha1.ElasticsearchApikeySpec{Body: `{"name": "ingest"}`},
+	}
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	_ = k8sv1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(apikey).WithStatusSubresource(apikey).Build()
+	if _, err := CreateApikey(cli, context.Background(), esClient, apikey, ctrl.Request{}); err != nil {
+		t.Fatalf("CreateApikey() error = %v", err)
+	}
+
+	expiration := time.Now().Add(48 * time.Hour).Truncate(time.Millisecond)
+	fakeES.SetAPIKeyState(apikey.Status.APIKeyID, expiration, true)
+
+	info, err := GetApikeyInfo(context.Background(), esClient, apikey.Status.APIKeyID)
+	if err != nil {
+		t.Fatalf("GetApikeyInfo() error = %v", err)
+	}
+	if !info.Invalidated || !time.UnixMilli(info.Expiration).Equal(expiration) {
+		t.Errorf("Expected the invalidated key expiring at %s, got %+v", expiration, info)
+	}
+
+	if _, err := GetApikeyInfo(context.Background(), esClient, "unknown"); err == nil {
+		t.Error("Expected an error for an unknown API key")
+	}
+}
+
+func TestGetApikeyLostReason(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	apikey := &v1alpha1.ElasticsearchApikey{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingest", Namespace: "default"},
+		Spec:       v1alpha1.ElasticsearchApikeySpec{Body: `{"name": "ingest"}`},
+	}
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	_ = k8sv1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(apikey).WithStatusSubresource(apikey).Build()
+	if _, err := CreateApikey(cli, context.Background(), esClient, apikey, ctrl.Request{}); err != nil {
+		t.Fatalf("CreateApikey() error = %v", err)
+	}
+	id := apikey.Status.APIKeyID
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		id          string
+		expiration  time.Time
+		invalidated bool
+		want        string
+	}{
+		{name: "active", id: id, expiration: now.Add(time.Hour), want: ""},
+		{name: "invalidated", id: id, invalidated: true, want: "was invalidated"},
+		{name: "expired", id: id, expiration: now.Add(-time.Hour), want: "expired"},
+		{name: "deleted", id: "unknown", want: "was deleted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeES.SetAPIKeyState(id, tt.expiration, tt.invalidated)
+			got, err := GetApikeyLostReason(context.Background(), esClient, tt.id, now)
+			if err != nil {
+				t.Fatalf("GetApikeyLostReason() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetApikeyLostReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateApikey_PendingApikey(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	apikey := &v1alpha1.ElasticsearchApikey{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingest", Namespace: "default"},
+		Spec:       v1alpha1.ElasticsearchApikeySpec{Body: `{"name": "ingest"}`},
+	}
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	_ = k8sv1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(apikey).WithStatusSubresource(apikey).Build()
+	if _, err := CreateApikey(cli, context.Background(), esClient, apikey, ctrl.Request{}); err != nil {
+		t.Fatalf("CreateApikey() error = %v", err)
+	}
+	completed := apikey.Status.APIKeyID
+	if apikey.Status.PendingAPIKeyID != "" {
+		t.Errorf("Expected the checkpoint to be cleared, got %s", apikey.Status.PendingAPIKeyID)
+	}
+
+	// Interrupted after the Secret was written: the pending key is kept
+	apikey.Status.APIKeyID = ""
+	apikey.Status.PendingAPIKeyID = completed
+	if _, err := CreateApikey(cli, context.Background(), esClient, apikey, ctrl.Request{}); err != nil {
+		t.Fatalf("CreateApikey() error = %v", err)
+	}
+	if apikey.Status.APIKeyID != completed || apikey.Status.PendingAPIKeyID != "" {
+		t.Errorf("Expected the pending key %s to be resumed, got %+v", completed, apikey.Status)
+	}
+	if info, err := GetApikeyInfo(context.Background(), esClient, completed); err != nil || info.Invalidated {
+		t.Errorf("Expected the resumed key to stay valid, got %+v, %v", info, err)
+	}
+
+	// Interrupted before the Secret was written: the pending key is invalidated and replaced
+	if err := cli.Delete(context.Background(), &k8sv1.Secret{ObjectMeta: metav1.ObjectMeta{Name: apikey.GetSecretName(), Namespace: "default"}}); err != nil {
+		t.Fatalf("Failed to delete the Secret: %v", err)
+	}
+	apikey.Status.APIKeyID = ""
+	apikey.Status.PendingAPIKeyID = completed
+	if _, err := CreateApikey(cli, context.Background(), esClient, apikey, ctrl.Request{}); err != nil {
+		t.Fatalf("CreateApikey() error = %v", err)
+	}
+	if apikey.Status.APIKeyID == completed || apikey.Status.PendingAPIKeyID != "" {
+		t.Errorf("Expected a new key to replace the pending key %s, got %+v", completed, apikey.Status)
+	}
+	if _, err := GetApikeyInfo(context.Background(), esClient, completed); err == nil {
+		t.Error("Expected the pending key to be invalidated")
+	}
+}
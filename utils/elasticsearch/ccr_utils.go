@@ -0,0 +1,304 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+// FollowerIndexInfo is a follower index as returned by the follow info API
+type FollowerIndexInfo struct {
+	RemoteCluster string         `json:"remote_cluster"`
+	LeaderIndex   string         `json:"leader_index"`
+	Status        string         `json:"status"`
+	Parameters    map[string]any `json:"parameters,omitempty"`
+}
+
+// AutoFollowPatternInfo is an auto-follow pattern as returned by the get auto-follow pattern API
+type AutoFollowPatternInfo struct {
+	Active bool `json:"active"`
+}
+
+// CheckRemoteCluster returns an error if the remote cluster is not configured on the target instance or not
+// connected
+func CheckRemoteCluster(esClient *elasticsearch.Client, remoteCluster string) error {
+	res, err := esClient.Cluster.RemoteInfo()
+	if err != nil || res.IsError() {
+		return GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var remotes map[string]struct {
+		Connected bool `json:"connected"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&remotes); err != nil {
+		return err
+	}
+	remote, ok := remotes[remoteCluster]
+	if !ok {
+		return fmt.Errorf("remote cluster %s is not configured", remoteCluster)
+	}
+	if !remote.Connected {
+		return fmt.Errorf("remote cluster %s is not connected", remoteCluster)
+	}
+	return nil
+}
+
+// replicationBody returns the body of the follow and auto-follow APIs, the parameters at the top level
+func replicationBody(fields map[string]any, settings map[string]string, parameters map[string]string) (string, error) {
+	body := make(map[string]any, len(fields)+len(parameters)+1)
+	for key, value := range parameters {
+		body[key] = value
+	}
+	for key, value := range fields {
+		body[key] = value
+	}
+	if len(settings) > 0 {
+		body["settings"] = settings
+	}
+	marshalled, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	return string(marshalled), nil
+}
+
+// GetFollowerIndexInfo returns the follow info of the index, nil if the index does not exist or is not a follower
+// index
+func GetFollowerIndexInfo(esClient *elasticsearch.Client, indexName string) (*FollowerIndexInfo, error) {
+	res, err := esClient.CCR.FollowInfo([]string{indexName})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return nil, nil
+	}
+	if res.IsError() {
+		return nil, GetClientErrorOrResponseError(nil, res)
+	}
+
+	var response struct {
+		FollowerIndices []struct {
+			FollowerIndex string `json:"follower_index"`
+			FollowerIndexInfo
+		} `json:"follower_indices"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	for _, follower := range response.FollowerIndices {
+		if follower.FollowerIndex == indexName {
+			return &follower.FollowerIndexInfo, nil
+		}
+	}
+	return nil, nil
+}
+
+// ReconcileFollowerIndex creates the follower index if it does not exist yet, then pauses or resumes the
+// replication as set by spec.paused. Changed spec.parameters of an active follower index are applied by pausing and
+// resuming it. The remote cluster and leader index of an existing follower index can not be changed. It returns
+// the replication status.
+func ReconcileFollowerIndex(esClient *elasticsearch.Client, followerIndex v1alpha1.FollowerIndex) (string, error) {
+	// The naming policy does not apply to replication, the follower index is named after the resource
+	indexName := followerIndex.Name
+	spec := followerIndex.Spec
+	info, err := GetFollowerIndexInfo(esClient, indexName)
+	if err != nil {
+		return "", err
+	}
+
+	if info == nil {
+		body, err := replicationBody(map[string]any{"remote_cluster": spec.RemoteCluster, "leader_index": spec.LeaderIndex},
+			spec.Settings, spec.Parameters)
+		if err != nil {
+			return "", err
+		}
+		res, err := esClient.CCR.Follow(indexName, strings.NewReader(body), esClient.CCR.Follow.WithWaitForActiveShards("1"))
+		if err != nil || res.IsError() {
+			return "", GetClientErrorOrResponseError(err, res)
+		}
+		res.Body.Close()
+		info = &FollowerIndexInfo{RemoteCluster: spec.RemoteCluster, LeaderIndex: spec.LeaderIndex, Status: v1alpha1.ReplicationStatusActive}
+	}
+	if info.RemoteCluster != spec.RemoteCluster || info.LeaderIndex != spec.LeaderIndex {
+		return info.Status, fmt.Errorf("follower index %s follows %s:%s, it has to be recreated to follow %s:%s",
+			indexName, info.RemoteCluster, info.LeaderIndex, spec.RemoteCluster, spec.LeaderIndex)
+	}
+
+	active := info.Status == v1alpha1.ReplicationStatusActive
+	switch {
+	case spec.Paused && active:
+		return v1alpha1.ReplicationStatusPaused, pauseFollow(esClient, indexName)
+	case spec.Paused:
+		return info.Status, nil
+	case active && parametersApplied(info.Parameters, spec.Parameters):
+		return info.Status, nil
+	case active:
+		if err := pauseFollow(esClient, indexName); err != nil {
+			return info.Status, err
+		}
+	}
+
+	body, err := replicationBody(nil, nil, spec.Parameters)
+	if err != nil {
+		return v1alpha1.ReplicationStatusPaused, err
+	}
+	res, err := esClient.CCR.ResumeFollow(indexName, esClient.CCR.ResumeFollow.WithBody(strings.NewReader(body)))
+	if err != nil || res.IsError() {
+		return v1alpha1.ReplicationStatusPaused, GetClientErrorOrResponseError(err, res)
+	}
+	res.Body.Close()
+	return v1alpha1.ReplicationStatusActive, nil
+}
+
+// parametersApplied reports whether the follower index runs with the declared parameters. Elasticsearch returns all
+// parameters including defaults, so only the declared ones are compared.
+func parametersApplied(current map[string]any, declared map[string]string) bool {
+	for key, value := range declared {
+		if currentValue, ok := current[key]; !ok || !strings.EqualFold(fmt.Sprint(currentValue), value) {
+			return false
+		}
+	}
+	return true
+}
+
+func pauseFollow(esClient *elasticsearch.Client, indexName string) error {
+	res, err := esClient.CCR.PauseFollow(indexName)
+	if err != nil || res.IsError() {
+		return GetClientErrorOrResponseError(err, res)
+	}
+	res.Body.Close()
+	return nil
+}
+
+// UnfollowIndex converts the follower index into a regular index, keeping its documents. The replication is paused
+// and the index closed for the unfollow API, then reopened. An index which does not exist or is not a follower index
+// is left untouched.
+func UnfollowIndex(esClient *elasticsearch.Client, indexName string) error {
+	info, err := GetFollowerIndexInfo(esClient, indexName)
+	if err != nil || info == nil {
+		return err
+	}
+	if info.Status == v1alpha1.ReplicationStatusActive {
+		if err := pauseFollow(esClient, indexName); err != nil {
+			return err
+		}
+	}
+	res, err := esClient.Indices.Close([]string{indexName})
+	if err != nil || res.IsError() {
+		return GetClientErrorOrResponseError(err, res)
+	}
+	res.Body.Close()
+	res, err = esClient.CCR.Unfollow(indexName)
+	if err != nil || res.IsError() {
+		return GetClientErrorOrResponseError(err, res)
+	}
+	res.Body.Close()
+	res, err = esClient.Indices.Open([]string{indexName})
+	if err != nil || res.IsError() {
+		return GetClientErrorOrResponseError(err, res)
+	}
+	res.Body.Close()
+	return nil
+}
+
+// GetAutoFollowPattern returns the auto-follow pattern, nil if it does not exist
+func GetAutoFollowPattern(esClient *elasticsearch.Client, name string) (*AutoFollowPatternInfo, error) {
+	res, err := esClient.CCR.GetAutoFollowPattern(esClient.CCR.GetAutoFollowPattern.WithName(name))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return nil, nil
+	}
+	if res.IsError() {
+		return nil, GetClientErrorOrResponseError(nil, res)
+	}
+
+	var response struct {
+		Patterns []struct {
+			Name    string                `json:"name"`
+			Pattern AutoFollowPatternInfo `json:"pattern"`
+		} `json:"patterns"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	for _, pattern := range response.Patterns {
+		if pattern.Name == name {
+			return &pattern.Pattern, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpsertAutoFollowPattern creates or updates the auto-follow pattern, then pauses or resumes it as set by
+// spec.paused. It returns the replication status.
+func UpsertAutoFollowPattern(esClient *elasticsearch.Client, autoFollowPattern v1alpha1.AutoFollowPattern) (string, error) {
+	name := autoFollowPattern.Name
+	spec := autoFollowPattern.Spec
+	fields := map[string]any{"remote_cluster": spec.RemoteCluster, "leader_index_patterns": spec.LeaderIndexPatterns}
+	if len(spec.LeaderIndexExclusionPatterns) > 0 {
+		fields["leader_index_exclusion_patterns"] = spec.LeaderIndexExclusionPatterns
+	}
+	if spec.FollowIndexPattern != "" {
+		fields["follow_index_pattern"] = spec.FollowIndexPattern
+	}
+	body, err := replicationBody(fields, spec.Settings, spec.Parameters)
+	if err != nil {
+		return "", err
+	}
+	res, err := esClient.CCR.PutAutoFollowPattern(name, strings.NewReader(body))
+	if err != nil || res.IsError() {
+		return "", GetClientErrorOrResponseError(err, res)
+	}
+	res.Body.Close()
+
+	pattern, err := GetAutoFollowPattern(esClient, name)
+	if err != nil {
+		return "", err
+	}
+	if pattern == nil {
+		return "", fmt.Errorf("auto-follow pattern %s was not found after it was put", name)
+	}
+	switch {
+	case spec.Paused && pattern.Active:
+		res, err = esClient.CCR.PauseAutoFollowPattern(name)
+	case !spec.Paused && !pattern.Active:
+		res, err = esClient.CCR.ResumeAutoFollowPattern(name)
+	default:
+		return replicationStatus(pattern.Active), nil
+	}
+	if err != nil || res.IsError() {
+		return replicationStatus(pattern.Active), GetClientErrorOrResponseError(err, res)
+	}
+	res.Body.Close()
+	return replicationStatus(!spec.Paused), nil
+}
+
+// DeleteAutoFollowPattern deletes the auto-follow pattern, a missing pattern is ignored. Follower indices created
+// by the pattern keep replicating.
+func DeleteAutoFollowPattern(esClient *elasticsearch.Client, name string) error {
+	res, err := esClient.CCR.DeleteAutoFollowPattern(name)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() && res.StatusCode != 404 {
+		return GetClientErrorOrResponseError(nil, res)
+	}
+	return nil
+}
+
+func replicationStatus(active bool) string {
+	if active {
+		return v1alpha1.ReplicationStatusActive
+	}
+	return v1alpha1.ReplicationStatusPaused
+}
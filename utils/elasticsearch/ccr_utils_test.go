@@ -0,0 +1,154 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckRemoteCluster(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	fakeES.SetRemoteCluster("leader", true)
+	fakeES.SetRemoteCluster("offline", false)
+	if err := CheckRemoteCluster(esClient, "leader"); err != nil {
+		t.Errorf("Expected a connected remote cluster to pass, got %v", err)
+	}
+	if err := CheckRemoteCluster(esClient, "offline"); err == nil {
+		t.Error("Expected a disconnected remote cluster to fail")
+	}
+	if err := CheckRemoteCluster(esClient, "missing"); err == nil {
+		t.Error("Expected an unknown remote cluster to fail")
+	}
+}
+
+func TestReconcileFollowerIndex_FakeElasticsearch(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+	fakeES.SetRemoteCluster("leader", true)
+
+	followerIndex := v1alpha1.FollowerIndex{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs-replica"},
+		Spec:       v1alpha1.FollowerIndexSpec{RemoteCluster: "leader", LeaderIndex: "logs"},
+	}
+	if status, err := ReconcileFollowerIndex(esClient, followerIndex); err != nil || status != v1alpha1.ReplicationStatusActive {
+		t.Fatalf("ReconcileFollowerIndex() = %q, %v", status, err)
+	}
+	if remote, leader, status, ok := fakeES.Follower("logs-replica"); !ok || remote != "leader" || leader != "logs" || status != "active" {
+		t.Fatalf("Expected the follower index to be created, got %s:%s %s", remote, leader, status)
+	}
+	if status, err := ReconcileFollowerIndex(esClient, followerIndex); err != nil || status != v1alpha1.ReplicationStatusActive {
+		t.Errorf("Expected an active follower index to be left alone, got %q, %v", status, err)
+	}
+	if count := fakeES.CountRequests("POST", "/logs-replica/_ccr/pause_follow"); count != 0 {
+		t.Errorf("Expected no pause, got %d", count)
+	}
+
+	// Changed parameters are applied by pausing and resuming
+	followerIndex.Spec.Parameters = map[string]string{"max_read_request_operation_count": "1024"}
+	if status, err := ReconcileFollowerIndex(esClient, followerIndex); err != nil || status != v1alpha1.ReplicationStatusActive {
+		t.Fatalf("ReconcileFollowerIndex() = %q, %v", status, err)
+	}
+	if fakeES.CountRequests("POST", "/logs-replica/_ccr/pause_follow") != 1 || fakeES.CountRequests("POST", "/logs-replica/_ccr/resume_follow") != 1 {
+		t.Error("Expected the follower index to be paused and resumed")
+	}
+
+	followerIndex.Spec.Paused = true
+	if status, err := ReconcileFollowerIndex(esClient, followerIndex); err != nil || status != v1alpha1.ReplicationStatusPaused {
+		t.Fatalf("ReconcileFollowerIndex() = %q, %v", status, err)
+	}
+	if _, _, status, _ := fakeES.Follower("logs-replica"); status != "paused" {
+		t.Errorf("Expected the follower index to be paused, got %s", status)
+	}
+	followerIndex.Spec.Paused = false
+	if status, err := ReconcileFollowerIndex(esClient, followerIndex); err != nil || status != v1alpha1.ReplicationStatusActive {
+		t.Fatalf("Expected the follower index to be resumed, got %q, %v", status, err)
+	}
+
+	followerIndex.Spec.LeaderIndex = "metrics"
+	if _, err := ReconcileFollowerIndex(esClient, followerIndex); err == nil {
+		t.Error("Expected changing the leader index to fail")
+	}
+
+	if err := UnfollowIndex(esClient, "logs-replica"); err != nil {
+		t.Fatalf("UnfollowIndex() error = %v", err)
+	}
+	if _, _, _, ok := fakeES.Follower("logs-replica"); ok {
+		t.Error("Expected the index not to be a follower index anymore")
+	}
+	if !fakeES.Exists(testutils.ESIndex, "logs-replica") || fakeES.CountRequests("POST", "/logs-replica/_open") != 1 {
+		t.Error("Expected the index to be kept and reopened")
+	}
+	if err := UnfollowIndex(esClient, "logs-replica"); err != nil {
+		t.Errorf("Expected a regular index to be left untouched, got %v", err)
+	}
+}
+
+func TestUpsertAutoFollowPattern_FakeElasticsearch(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+	fakeES.SetRemoteCluster("leader", true)
+
+	pattern := v1alpha1.AutoFollowPattern{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+		Spec: v1alpha1.AutoFollowPatternSpec{
+			RemoteCluster:       "leader",
+			LeaderIndexPatterns: []string{"logs-*"},
+			FollowIndexPattern:  "{{leader_index}}-replica",
+			Parameters:          map[string]string{"max_read_request_operation_count": "1024"},
+		},
+	}
+	if status, err := UpsertAutoFollowPattern(esClient, pattern); err != nil || status != v1alpha1.ReplicationStatusActive {
+		t.Fatalf("UpsertAutoFollowPattern() = %q, %v", status, err)
+	}
+	stored, _ := fakeES.Get(testutils.ESAutoFollowPattern, "logs")
+	want := `{"follow_index_pattern":"{{leader_index}}-replica","leader_index_patterns":["logs-*"],"max_read_request_operation_count":"1024","remote_cluster":"leader"}`
+	if string(stored) != want {
+		t.Errorf("Expected the pattern to be put, got %s", stored)
+	}
+
+	pattern.Spec.Paused = true
+	if status, err := UpsertAutoFollowPattern(esClient, pattern); err != nil || status != v1alpha1.ReplicationStatusPaused {
+		t.Fatalf("UpsertAutoFollowPattern() = %q, %v", status, err)
+	}
+	if !fakeES.AutoFollowPatternPaused("logs") {
+		t.Error("Expected the pattern to be paused")
+	}
+	if status, err := UpsertAutoFollowPattern(esClient, pattern); err != nil || status != v1alpha1.ReplicationStatusPaused {
+		t.Errorf("Expected a paused pattern to stay paused, got %q, %v", status, err)
+	}
+	if count := fakeES.CountRequests("POST", "/_ccr/auto_follow/logs/pause"); count != 1 {
+		t.Errorf("Expected a single pause, got %d", count)
+	}
+
+	pattern.Spec.RemoteCluster = "missing"
+	if _, err := UpsertAutoFollowPattern(esClient, pattern); err == nil {
+		t.Error("Expected an unknown remote cluster to fail")
+	}
+
+	if err := DeleteAutoFollowPattern(esClient, "logs"); err != nil {
+		t.Fatalf("DeleteAutoFollowPattern() error = %v", err)
+	}
+	if fakeES.Exists(testutils.ESAutoFollowPattern, "logs") {
+		t.Error("Expected the pattern to be deleted")
+	}
+	if err := DeleteAutoFollowPattern(esClient, "logs"); err != nil {
+		t.Errorf("Expected a missing pattern to be ignored, got %v", err)
+	}
+}
@@ -20,11 +20,16 @@ func DeleteComponentTemplate(esClient *elasticsearch.Client, componentTemplateNa
 
 func UpsertComponentTemplate(esClient *elasticsearch.Client, componentTemplate v1alpha1.ComponentTemplate) (ctrl.Result, error) {
 
-	res, err := esClient.Cluster.PutComponentTemplate(componentTemplate.Name, strings.NewReader(componentTemplate.Spec.Body))
+	res, err := esClient.Cluster.PutComponentTemplate(utils.RemoteName(&componentTemplate), strings.NewReader(componentTemplate.Spec.GetBody()))
 	if err != nil || res.IsError() {
 		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
 	}
 
+	if legacyName, ok := utils.LegacyName(&componentTemplate); ok {
+		if err := deleteLegacyObject(esClient.Cluster.DeleteComponentTemplate(legacyName)); err != nil {
+			return utils.GetRequeueResult(), err
+		}
+	}
 	return ctrl.Result{}, nil
 }
 
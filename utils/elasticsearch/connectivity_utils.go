@@ -0,0 +1,148 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+// DefaultConnectionTestClusterPrivileges are checked if a ConnectionTest does not list cluster privileges, the
+// operator needs them for its health checks
+var DefaultConnectionTestClusterPrivileges = []string{"monitor"}
+
+// TestElasticsearchConnection runs the checks of a ConnectionTest against Elasticsearch and records their results,
+// the version and the authenticated user in status. It returns whether all checks passed. A check is only run if the
+// previous one passed.
+func TestElasticsearchConnection(esClient *elasticsearch.Client, clusterPrivileges []string, status *v1alpha1.ConnectionTestStatus) bool {
+	status.Checks = nil
+	status.Version = ""
+	status.Username = ""
+
+	res, err := esClient.Info()
+	if err != nil {
+		return failCheck(status, v1alpha1.ConnectionTestCheckConnectivity, err.Error())
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= http.StatusInternalServerError {
+		return failCheck(status, v1alpha1.ConnectionTestCheckConnectivity, fmt.Sprintf("Elasticsearch is unavailable: %s", res.String()))
+	}
+	passCheck(status, v1alpha1.ConnectionTestCheckConnectivity, fmt.Sprintf("Elasticsearch responded with status %d", res.StatusCode))
+	if !res.IsError() {
+		var info struct {
+			Version struct {
+				Number string `json:"number"`
+			} `json:"version"`
+		}
+		if json.NewDecoder(res.Body).Decode(&info) == nil {
+			status.Version = info.Version.Number
+		}
+	}
+
+	username, securityEnabled, err := authenticate(esClient)
+	if err != nil {
+		return failCheck(status, v1alpha1.ConnectionTestCheckAuthentication, err.Error())
+	}
+	if !securityEnabled {
+		// Without security every request is allowed, there are no privileges to check
+		passCheck(status, v1alpha1.ConnectionTestCheckAuthentication, "Security is disabled in Elasticsearch")
+		return true
+	}
+	status.Username = username
+	passCheck(status, v1alpha1.ConnectionTestCheckAuthentication, fmt.Sprintf("Authenticated as %s", username))
+
+	if len(clusterPrivileges) == 0 {
+		clusterPrivileges = DefaultConnectionTestClusterPrivileges
+	}
+	missing, err := missingClusterPrivileges(esClient, clusterPrivileges)
+	if err != nil {
+		return failCheck(status, v1alpha1.ConnectionTestCheckPermissions, err.Error())
+	}
+	if len(missing) > 0 {
+		return failCheck(status, v1alpha1.ConnectionTestCheckPermissions, fmt.Sprintf("%s is missing the cluster privileges %s", username, strings.Join(missing, ", ")))
+	}
+	passCheck(status, v1alpha1.ConnectionTestCheckPermissions, fmt.Sprintf("%s has the cluster privileges %s", username, strings.Join(clusterPrivileges, ", ")))
+	return true
+}
+
+// authenticate returns the user the client is authenticated as, and whether security is enabled at all
+func authenticate(esClient *elasticsearch.Client) (string, bool, error) {
+	res, err := esClient.Security.Authenticate()
+	if err != nil {
+		return "", false, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		if strings.Contains(string(body), "security is not enabled") || strings.Contains(string(body), "Security must be explicitly enabled") {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("authentication failed: %s", responseReason(res.StatusCode, body))
+	}
+	var response struct {
+		Username string `json:"username"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return "", false, err
+	}
+	return response.Username, true, nil
+}
+
+// missingClusterPrivileges returns the sorted cluster privileges the authenticated user lacks
+func missingClusterPrivileges(esClient *elasticsearch.Client, clusterPrivileges []string) ([]string, error) {
+	body, err := json.Marshal(map[string][]string{"cluster": clusterPrivileges})
+	if err != nil {
+		return nil, err
+	}
+	res, err := esClient.Security.HasPrivileges(strings.NewReader(string(body)))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("checking the privileges failed: %s", responseReason(res.StatusCode, body))
+	}
+	var response struct {
+		Cluster map[string]bool `json:"cluster"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, privilege := range clusterPrivileges {
+		if !response.Cluster[privilege] {
+			missing = append(missing, privilege)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// responseReason returns the reason of an error response, or its body if it has none
+func responseReason(statusCode int, body []byte) string {
+	var response struct {
+		Error struct {
+			Reason string `json:"reason"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &response) == nil && response.Error.Reason != "" {
+		return fmt.Sprintf("status %d, %s", statusCode, response.Error.Reason)
+	}
+	return fmt.Sprintf("status %d, %s", statusCode, body)
+}
+
+func passCheck(status *v1alpha1.ConnectionTestStatus, name string, message string) {
+	status.Checks = append(status.Checks, v1alpha1.ConnectionTestCheck{Name: name, Passed: true, Message: message})
+}
+
+func failCheck(status *v1alpha1.ConnectionTestStatus, name string, message string) bool {
+	status.Checks = append(status.Checks, v1alpha1.ConnectionTestCheck{Name: name, Message: message})
+	return false
+}
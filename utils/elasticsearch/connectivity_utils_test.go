@@ -0,0 +1,52 @@
+package elasticsearch
+
+import (
+	"strings"
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+)
+
+func TestTestElasticsearchConnection_FakeElasticsearch(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	var status v1alpha1.ConnectionTestStatus
+	if !TestElasticsearchConnection(esClient, nil, &status) {
+		t.Fatalf("TestElasticsearchConnection() failed, checks = %v", status.Checks)
+	}
+	if len(status.Checks) != 3 || status.Version != "8.15.0" || status.Username != "elastic" {
+		t.Errorf("Expected three passed checks against 8.15.0 as elastic, got %v, %q, %q", status.Checks, status.Version, status.Username)
+	}
+
+	fakeES.SetMissingPrivileges("manage_security")
+	if TestElasticsearchConnection(esClient, []string{"monitor", "manage_security"}, &status) {
+		t.Fatal("TestElasticsearchConnection() passed, want the missing privilege to fail")
+	}
+	failed := status.Checks[len(status.Checks)-1]
+	if failed.Name != v1alpha1.ConnectionTestCheckPermissions || failed.Passed || !strings.Contains(failed.Message, "manage_security") || strings.Contains(failed.Message, "monitor") {
+		t.Errorf("Expected the Permissions check to fail for manage_security only, got %v", failed)
+	}
+
+	fakeES.FailRequests("GET", "/_security/_authenticate", 401, `{"error": {"reason": "unable to authenticate user"}}`)
+	if TestElasticsearchConnection(esClient, nil, &status) {
+		t.Fatal("TestElasticsearchConnection() passed, want the rejected credentials to fail")
+	}
+	if len(status.Checks) != 2 || status.Checks[1].Name != v1alpha1.ConnectionTestCheckAuthentication || !strings.Contains(status.Checks[1].Message, "unable to authenticate user") {
+		t.Errorf("Expected the Authentication check to fail with the reason, got %v", status.Checks)
+	}
+
+	fakeES.ClearFailures()
+	fakeES.FailRequests("GET", "/", 503, `{"error": {"reason": "master not discovered"}}`)
+	if TestElasticsearchConnection(esClient, nil, &status) {
+		t.Fatal("TestElasticsearchConnection() passed, want the unavailable cluster to fail")
+	}
+	if len(status.Checks) != 1 || status.Checks[0].Name != v1alpha1.ConnectionTestCheckConnectivity || status.Username != "" {
+		t.Errorf("Expected only the Connectivity check to fail, got %v", status.Checks)
+	}
+}
@@ -0,0 +1,127 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DataStreamMismatch condition of Indices and IndexTemplates, set while an Index resource is matched by or depends on
+// an IndexTemplate declaring data_stream. Elasticsearch refuses to create such an index, names matching a data stream
+// template can only be data streams.
+const (
+	DataStreamMismatchConditionType = "DataStreamMismatch"
+	// DataStreamTemplateReason is set on the Index matched by a data stream template
+	DataStreamTemplateReason = "DataStreamTemplate"
+	// IndexResourcesMatchedReason is set on the data stream template matching Index resources
+	IndexResourcesMatchedReason = "IndexResourcesMatched"
+)
+
+// IndexTemplateDeclaresDataStream reports whether the index template body declares data_stream, i.e. creates data
+// streams instead of indices
+func IndexTemplateDeclaresDataStream(body string) (bool, error) {
+	var template struct {
+		DataStream json.RawMessage `json:"data_stream"`
+	}
+	if err := json.Unmarshal([]byte(body), &template); err != nil {
+		return false, err
+	}
+	return len(template.DataStream) > 0 && string(template.DataStream) != "null", nil
+}
+
+// FindDataStreamTemplatesOfIndex returns the namespace/name of the IndexTemplates declaring data_stream which target
+// the same instance as the index and either are the template of the highest priority matching its name, the one
+// Elasticsearch applies, or are listed in its dependencies. Templates with bodies which cannot be parsed are skipped.
+func FindDataStreamTemplatesOfIndex(index v1alpha1.Index, indexTemplates []v1alpha1.IndexTemplate) []string {
+	indexName := utils.RemoteName(&index)
+	var applied *v1alpha1.IndexTemplate
+	var appliedPriority int64
+	var mismatches []string
+	for i, indexTemplate := range indexTemplates {
+		if !indexTemplate.DeletionTimestamp.IsZero() || !sameTargetConfig(index.Namespace, index.Spec.TargetConfig, indexTemplate.Namespace, indexTemplate.Spec.TargetConfig) {
+			continue
+		}
+		patterns, priority, err := indexTemplatePatternsAndPriority(indexTemplate)
+		if err != nil {
+			continue
+		}
+		if anyPatternsOverlap(patterns, []string{indexName}) && (applied == nil || priority > appliedPriority) {
+			applied, appliedPriority = &indexTemplates[i], priority
+		}
+		if slices.Contains(index.Spec.Dependencies.IndexTemplates, utils.RemoteName(&indexTemplate)) {
+			if dataStream, _ := IndexTemplateDeclaresDataStream(indexTemplate.Spec.GetBody()); dataStream {
+				mismatches = append(mismatches, indexTemplate.Namespace+"/"+indexTemplate.Name)
+			}
+		}
+	}
+	if applied != nil {
+		if dataStream, _ := IndexTemplateDeclaresDataStream(applied.Spec.GetBody()); dataStream {
+			if name := applied.Namespace + "/" + applied.Name; !slices.Contains(mismatches, name) {
+				mismatches = append(mismatches, name)
+			}
+		}
+	}
+	sort.Strings(mismatches)
+	return mismatches
+}
+
+// FindIndicesOfDataStreamTemplate returns the namespace/name of the Indices for which FindDataStreamTemplatesOfIndex
+// reports the index template, none if it does not declare data_stream
+func FindIndicesOfDataStreamTemplate(indexTemplate v1alpha1.IndexTemplate, indexTemplates []v1alpha1.IndexTemplate, indices []v1alpha1.Index) []string {
+	if dataStream, err := IndexTemplateDeclaresDataStream(indexTemplate.Spec.GetBody()); err != nil || !dataStream {
+		return nil
+	}
+	name := indexTemplate.Namespace + "/" + indexTemplate.Name
+	var matched []string
+	for _, index := range indices {
+		if index.DeletionTimestamp.IsZero() && slices.Contains(FindDataStreamTemplatesOfIndex(index, indexTemplates), name) {
+			matched = append(matched, index.Namespace+"/"+index.Name)
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
+
+// SetIndexDataStreamMismatchCondition sets the DataStreamMismatch condition of an Index matched by the data stream
+// templates and removes it if there are none. It returns the message of the condition, empty if removed, and whether
+// the templates changed.
+func SetIndexDataStreamMismatchCondition(conditions *[]metav1.Condition, generation int64, indexName string, templates []string) (string, bool) {
+	if len(templates) == 0 {
+		return "", meta.RemoveStatusCondition(conditions, DataStreamMismatchConditionType)
+	}
+	message := fmt.Sprintf("IndexTemplate %s declares data_stream, Elasticsearch only creates %s as a data stream: "+
+		"remove the Index and let the data stream be created on the first write, or remove data_stream from the template",
+		strings.Join(templates, ", "), indexName)
+	return message, setDataStreamMismatchCondition(conditions, generation, DataStreamTemplateReason, message)
+}
+
+// SetIndexTemplateDataStreamMismatchCondition sets the DataStreamMismatch condition of a data stream template
+// matching the Indices and removes it if there are none. It returns whether the Indices changed.
+func SetIndexTemplateDataStreamMismatchCondition(conditions *[]metav1.Condition, generation int64, indices []string) bool {
+	if len(indices) == 0 {
+		return meta.RemoveStatusCondition(conditions, DataStreamMismatchConditionType)
+	}
+	message := fmt.Sprintf("The template declares data_stream but applies to Index %s, which Elasticsearch refuses to create as an index",
+		strings.Join(indices, ", "))
+	return setDataStreamMismatchCondition(conditions, generation, IndexResourcesMatchedReason, message)
+}
+
+func setDataStreamMismatchCondition(conditions *[]metav1.Condition, generation int64, reason string, message string) bool {
+	previous := meta.FindStatusCondition(*conditions, DataStreamMismatchConditionType)
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               DataStreamMismatchConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	return previous == nil || previous.Message != message
+}
@@ -0,0 +1,122 @@
+package elasticsearch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIndexTemplateDeclaresDataStream(t *testing.T) {
+	tests := []struct {
+		body string
+		want bool
+	}{
+		{body: `{"index_patterns": ["logs-*"], "data_stream": {}}`, want: true},
+		{body: `{"index_patterns": ["logs-*"], "data_stream": {"hidden": true}}`, want: true},
+		{body: `{"index_patterns": ["logs-*"], "data_stream": null}`, want: false},
+		{body: `{"index_patterns": ["logs-*"]}`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			if got, err := IndexTemplateDeclaresDataStream(tt.body); err != nil || got != tt.want {
+				t.Errorf("IndexTemplateDeclaresDataStream() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+	if _, err := IndexTemplateDeclaresDataStream(`not json`); err == nil {
+		t.Error("Expected an error for an invalid body")
+	}
+}
+
+func newDataStreamTestTemplates() []v1alpha1.IndexTemplate {
+	newIndexTemplate := func(name string, instance string, body string) v1alpha1.IndexTemplate {
+		return v1alpha1.IndexTemplate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: v1alpha1.IndexTemplateSpec{
+				TargetConfig: v1alpha1.CommonElasticsearchConfig{ElasticsearchInstance: instance},
+				Body:         body,
+			},
+		}
+	}
+	return []v1alpha1.IndexTemplate{
+		newIndexTemplate("logs", "", `{"index_patterns": ["logs-*"], "priority": 100, "data_stream": {}}`),
+		newIndexTemplate("logs-archive", "", `{"index_patterns": ["logs-archive-*"], "priority": 200}`),
+		newIndexTemplate("metrics", "", `{"index_patterns": ["metrics-*"], "data_stream": {}}`),
+		newIndexTemplate("staging-events", "staging", `{"index_patterns": ["events-*"], "data_stream": {}}`),
+		newIndexTemplate("broken", "", `{"index_patterns": `),
+	}
+}
+
+func TestFindDataStreamTemplatesOfIndex(t *testing.T) {
+	indexTemplates := newDataStreamTestTemplates()
+	newIndex := func(name string, dependencies ...string) v1alpha1.Index {
+		return v1alpha1.Index{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       v1alpha1.IndexSpec{Dependencies: v1alpha1.Dependencies{IndexTemplates: dependencies}},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		index v1alpha1.Index
+		want  []string
+	}{
+		{name: "matched by a data stream template", index: newIndex("logs-app"), want: []string{"default/logs"}},
+		{name: "higher priority template without data stream", index: newIndex("logs-archive-2024"), want: nil},
+		{name: "depends on a data stream template", index: newIndex("app", "metrics"), want: []string{"default/metrics"}},
+		{name: "matched and depends on the same template", index: newIndex("logs-app", "logs"), want: []string{"default/logs"}},
+		{name: "data stream template of another instance", index: newIndex("events-2024"), want: nil},
+		{name: "no template", index: newIndex("app"), want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindDataStreamTemplatesOfIndex(tt.index, indexTemplates); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindDataStreamTemplatesOfIndex() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindIndicesOfDataStreamTemplate(t *testing.T) {
+	indexTemplates := newDataStreamTestTemplates()
+	indices := []v1alpha1.Index{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "logs-app"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "logs-team"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "logs-archive-2024"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"}},
+	}
+
+	if got, want := FindIndicesOfDataStreamTemplate(indexTemplates[0], indexTemplates, indices), []string{"default/logs-app", "team/logs-team"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindIndicesOfDataStreamTemplate() = %v, want %v", got, want)
+	}
+	if got := FindIndicesOfDataStreamTemplate(indexTemplates[1], indexTemplates, indices); got != nil {
+		t.Errorf("FindIndicesOfDataStreamTemplate() = %v, want none for a template without data_stream", got)
+	}
+}
+
+func TestSetIndexDataStreamMismatchCondition(t *testing.T) {
+	var conditions []metav1.Condition
+
+	message, changed := SetIndexDataStreamMismatchCondition(&conditions, 1, "logs-app", []string{"default/logs"})
+	if !changed || !strings.Contains(message, "default/logs") || !strings.Contains(message, "logs-app") {
+		t.Errorf("SetIndexDataStreamMismatchCondition() = %q, %v, want a new message naming the template and index", message, changed)
+	}
+	condition := meta.FindStatusCondition(conditions, DataStreamMismatchConditionType)
+	if condition == nil || condition.Status != metav1.ConditionTrue || condition.Reason != DataStreamTemplateReason {
+		t.Fatalf("Expected the DataStreamMismatch condition, got %v", condition)
+	}
+	if _, changed := SetIndexDataStreamMismatchCondition(&conditions, 2, "logs-app", []string{"default/logs"}); changed {
+		t.Error("Expected the same mismatch not to be reported as changed")
+	}
+	if message, changed := SetIndexDataStreamMismatchCondition(&conditions, 2, "logs-app", nil); message != "" || !changed {
+		t.Errorf("SetIndexDataStreamMismatchCondition() = %q, %v, want the resolved mismatch reported as changed", message, changed)
+	}
+	if meta.FindStatusCondition(conditions, DataStreamMismatchConditionType) != nil {
+		t.Error("Expected the DataStreamMismatch condition to be removed")
+	}
+}
@@ -0,0 +1,153 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/utils"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Pending condition, set while the ECK Elasticsearch of the target instance did not reach its startup health yet
+const (
+	PendingConditionType      = "Pending"
+	WaitingForBootstrapReason = "WaitingForBootstrap"
+)
+
+// EckElasticsearchGVK is the kind of the Elasticsearch resources of ECK
+var EckElasticsearchGVK = schema.GroupVersionKind{Group: "elasticsearch.k8s.elastic.co", Version: "v1", Kind: "Elasticsearch"}
+
+// eckBootstrapGate holds back reconciles against instances whose ECK Elasticsearch did not report the required
+// health yet. The health of an ECK Elasticsearch is read at most once per startupGateRecheckInterval, no matter how
+// many resources are waiting for it. Once it reached the health it is considered bootstrapped and never gated again.
+type eckBootstrapGate struct {
+	mu        sync.Mutex
+	instances map[types.NamespacedName]*eckBootstrapInstance
+	now       func() time.Time
+}
+
+type eckBootstrapInstance struct {
+	ready       bool
+	lastChecked time.Time
+	lastHealth  string
+}
+
+var defaultEckBootstrapGate = newEckBootstrapGate()
+
+func newEckBootstrapGate() *eckBootstrapGate {
+	return &eckBootstrapGate{
+		instances: make(map[types.NamespacedName]*eckBootstrapInstance),
+		now:       time.Now,
+	}
+}
+
+// CheckEckBootstrapped reports whether resources of the target instance may be reconciled. While the ECK
+// Elasticsearch of spec.eckRef bootstraps, the Pending condition of obj is set and the returned result requeues it,
+// without an error or an event. Instances without spec.eckRef are not gated.
+func CheckEckBootstrapped(ctx context.Context, cli client.Client, obj client.Object, conditions *[]metav1.Condition, targetInstance configv2.ElasticsearchSpec) (bool, ctrl.Result) {
+	return defaultEckBootstrapGate.check(ctx, cli, obj, conditions, targetInstance)
+}
+
+func (g *eckBootstrapGate) check(ctx context.Context, cli client.Client, obj client.Object, conditions *[]metav1.Condition, targetInstance configv2.ElasticsearchSpec) (bool, ctrl.Result) {
+	logger := log.FromContext(ctx)
+	if targetInstance.EckRef == nil {
+		return true, ctrl.Result{}
+	}
+	key := types.NamespacedName{Namespace: targetInstance.EckRef.Namespace, Name: targetInstance.EckRef.Name}
+	if key.Namespace == "" {
+		key.Namespace = obj.GetNamespace()
+	}
+	required := targetInstance.StartupHealth
+	if required == "" {
+		required = DefaultStartupHealth
+	}
+
+	if g.bootstrapped(ctx, cli, key, required) {
+		if meta.RemoveStatusCondition(conditions, PendingConditionType) {
+			if err := cli.Status().Update(ctx, obj); err != nil {
+				logger.Error(err, "Failed to clear Pending condition")
+			}
+		}
+		return true, ctrl.Result{}
+	}
+
+	g.mu.Lock()
+	health := g.instances[key].lastHealth
+	g.mu.Unlock()
+	message := fmt.Sprintf("Waiting for the ECK Elasticsearch %s to reach %s health (current: %s)", key, required, health)
+	changed := meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               PendingConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             WaitingForBootstrapReason,
+		Message:            message,
+	})
+	readyChanged := meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               utils.ReadyConditionType,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             WaitingForBootstrapReason,
+		Message:            message,
+	})
+	if changed || readyChanged {
+		logger.Info("Waiting for the ECK Elasticsearch to bootstrap", "elasticsearch", key, "health", health)
+		if err := cli.Status().Update(ctx, obj); err != nil {
+			logger.Error(err, "Failed to set Pending condition")
+		}
+	}
+	return false, ctrl.Result{RequeueAfter: startupGateRecheckInterval}
+}
+
+// bootstrapped reports whether the ECK Elasticsearch reached the required health, reading its status unless it was
+// read within the last startupGateRecheckInterval
+func (g *eckBootstrapGate) bootstrapped(ctx context.Context, cli client.Client, key types.NamespacedName, required string) bool {
+	g.mu.Lock()
+	instance, ok := g.instances[key]
+	if !ok {
+		instance = &eckBootstrapInstance{}
+		g.instances[key] = instance
+	}
+	if instance.ready || g.now().Sub(instance.lastChecked) < startupGateRecheckInterval {
+		defer g.mu.Unlock()
+		return instance.ready
+	}
+	instance.lastChecked = g.now()
+	g.mu.Unlock()
+
+	health := EckElasticsearchHealth(ctx, cli, key)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	instance.lastHealth = health
+	if level, known := healthLevels[health]; known && level >= healthLevels[required] {
+		instance.ready = true
+	}
+	return instance.ready
+}
+
+// EckElasticsearchHealth returns status.health of the ECK Elasticsearch, unknown if it can not be read, e.g. because
+// it does not exist yet
+func EckElasticsearchHealth(ctx context.Context, cli client.Client, key types.NamespacedName) string {
+	eck := &unstructured.Unstructured{}
+	eck.SetGroupVersionKind(EckElasticsearchGVK)
+	if err := cli.Get(ctx, key, eck); err != nil {
+		log.FromContext(ctx).V(1).Info("Failed to get the ECK Elasticsearch", "elasticsearch", key, "error", err.Error())
+		return "unknown"
+	}
+	health, found, _ := unstructured.NestedString(eck.Object, "status", "health")
+	if !found || health == "" {
+		return "unknown"
+	}
+	return health
+}
@@ -0,0 +1,88 @@
+package elasticsearch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEckBootstrapGate_Check(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	ctx := context.Background()
+
+	eck := &unstructured.Unstructured{}
+	eck.SetGroupVersionKind(EckElasticsearchGVK)
+	eck.SetNamespace("elastic")
+	eck.SetName("quickstart")
+	_ = unstructured.SetNestedField(eck.Object, "red", "status", "health")
+	index := &v1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "default"}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(eck, index).WithStatusSubresource(index).Build()
+
+	now := time.Now()
+	gate := newEckBootstrapGate()
+	gate.now = func() time.Time { return now }
+	targetInstance := configv2.ElasticsearchSpec{EckRef: &configv2.EckElasticsearchReference{Name: "quickstart", Namespace: "elastic"}}
+
+	ready, res := gate.check(ctx, cli, index, &index.Status.Conditions, targetInstance)
+	if ready || res.RequeueAfter != startupGateRecheckInterval {
+		t.Fatalf("Expected a red cluster to be held back, got %v, %v", ready, res)
+	}
+	pending := meta.FindStatusCondition(index.Status.Conditions, PendingConditionType)
+	if pending == nil || pending.Status != metav1.ConditionTrue || pending.Reason != WaitingForBootstrapReason {
+		t.Errorf("Expected the Pending condition, got %v", index.Status.Conditions)
+	}
+
+	_ = unstructured.SetNestedField(eck.Object, "yellow", "status", "health")
+	if err := cli.Update(ctx, eck); err != nil {
+		t.Fatalf("Failed to update the ECK Elasticsearch: %v", err)
+	}
+	if ready, _ := gate.check(ctx, cli, index, &index.Status.Conditions, targetInstance); ready {
+		t.Error("Expected the health to be read at most once per interval")
+	}
+	now = now.Add(startupGateRecheckInterval)
+	if ready, _ := gate.check(ctx, cli, index, &index.Status.Conditions, targetInstance); !ready {
+		t.Fatal("Expected a yellow cluster to be bootstrapped")
+	}
+	if meta.FindStatusCondition(index.Status.Conditions, PendingConditionType) != nil {
+		t.Errorf("Expected the Pending condition to be removed, got %v", index.Status.Conditions)
+	}
+
+	_ = unstructured.SetNestedField(eck.Object, "red", "status", "health")
+	if err := cli.Update(ctx, eck); err != nil {
+		t.Fatalf("Failed to update the ECK Elasticsearch: %v", err)
+	}
+	now = now.Add(startupGateRecheckInterval)
+	if ready, _ := gate.check(ctx, cli, index, &index.Status.Conditions, targetInstance); !ready {
+		t.Error("Expected a bootstrapped cluster not to be gated again")
+	}
+}
+
+func TestEckBootstrapGate_Check_Missing(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	index := &v1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "default"}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(index).WithStatusSubresource(index).Build()
+	gate := newEckBootstrapGate()
+
+	if ready, _ := gate.check(context.Background(), cli, index, &index.Status.Conditions, configv2.ElasticsearchSpec{}); !ready {
+		t.Error("Expected an instance without eckRef not to be gated")
+	}
+	targetInstance := configv2.ElasticsearchSpec{EckRef: &configv2.EckElasticsearchReference{Name: "quickstart"}}
+	if ready, _ := gate.check(context.Background(), cli, index, &index.Status.Conditions, targetInstance); ready {
+		t.Error("Expected a missing ECK Elasticsearch to be held back")
+	}
+	if pending := meta.FindStatusCondition(index.Status.Conditions, PendingConditionType); pending == nil ||
+		pending.Message != "Waiting for the ECK Elasticsearch default/quickstart to reach yellow health (current: unknown)" {
+		t.Errorf("Unexpected Pending condition %v", pending)
+	}
+}
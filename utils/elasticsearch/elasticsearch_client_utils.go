@@ -2,7 +2,10 @@ package elasticsearch
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 
@@ -11,6 +14,7 @@ import (
 	"eck-custom-resources/api/es.eck/v1alpha1"
 	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 
@@ -30,10 +34,19 @@ func GetElasticsearchClient(cli client.Client, ctx context.Context, esSpec confi
 
 	logger.Info("Elasticsearch client not initialized, initializing.", "Spec", esSpec)
 
+	proxy, err := utils.ProxyFunc(esSpec.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	transport.TLSClientConfig = &tls.Config{}
+
 	config := elasticsearch.Config{
 		Addresses:         []string{esSpec.Url},
 		EnableDebugLogger: true,
 		Logger:            &elastictransport.TextLogger{Output: os.Stdout},
+		Transport:         utils.TracingTransport(ctx, utils.CircuitBreakerTransport(esSpec.Url, transport)),
 	}
 
 	if esSpec.Authentication != nil && esSpec.Authentication.UsernamePassword != nil {
@@ -54,7 +67,12 @@ func GetElasticsearchClient(cli client.Client, ctx context.Context, esSpec confi
 		if err := utils.GetCertificateSecret(cli, ctx, targetInstanceNamespace, esSpec.Certificate, &certificateSecret); err != nil {
 			return nil, err
 		}
-		config.CACert = certificateSecret.Data[esSpec.Certificate.CertificateKey]
+		// The CA is set on the transport itself, the client only accepts CACert for a plain *http.Transport
+		transport.TLSClientConfig.RootCAs = x509.NewCertPool()
+		if !transport.TLSClientConfig.RootCAs.AppendCertsFromPEM(certificateSecret.Data[esSpec.Certificate.CertificateKey]) {
+			return nil, fmt.Errorf("unable to add CA certificate from key %s of Secret %s", esSpec.Certificate.CertificateKey, esSpec.Certificate.SecretName)
+		}
+		utils.RegisterCertificateSecret(esSpec.Certificate.SecretNamespace(targetInstanceNamespace), esSpec.Certificate.SecretName, esSpec.Url)
 	}
 
 	esClient, err := elasticsearch.NewClient(config)
@@ -65,6 +83,37 @@ func GetElasticsearchClient(cli client.Client, ctx context.Context, esSpec confi
 	return esClient, nil
 }
 
+// CheckTargetAvailable short-circuits reconciles against the Elasticsearch instance while its circuit is open, the
+// instance is probed with a ping. The FailedOver condition and status.failover are updated for resources with a
+// failover pair.
+func CheckTargetAvailable(ctx context.Context, cli client.Client, recorder record.EventRecorder, obj client.Object,
+	conditions *[]metav1.Condition, esClient *elasticsearch.Client, targetInstance configv2.ElasticsearchSpec) (bool, ctrl.Result) {
+	if bootstrapped, res := CheckEckBootstrapped(ctx, cli, obj, conditions, targetInstance); !bootstrapped {
+		return false, res
+	}
+	if updateFailoverStatus(obj, conditions, targetInstance.Url) {
+		if err := cli.Status().Update(ctx, obj); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to update the failover status")
+		}
+	}
+	return utils.CheckTargetAvailable(ctx, cli, recorder, obj, conditions, targetInstance.Url, pingProbe(esClient))
+}
+
+// pingProbe returns a probe of the availability of the instance of esClient
+func pingProbe(esClient *elasticsearch.Client) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		res, err := esClient.Ping(esClient.Ping.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		if res.StatusCode >= http.StatusBadGateway && res.StatusCode <= http.StatusGatewayTimeout {
+			return fmt.Errorf("status %d", res.StatusCode)
+		}
+		return nil
+	}
+}
+
 func GetClientErrorOrResponseError(err error, response *esapi.Response) error {
 	if err != nil {
 		return err
@@ -128,8 +177,17 @@ func GetTargetElasticsearchInstance(cli client.Client, ctx context.Context, name
 	return nil
 }
 
+//+kubebuilder:rbac:groups=es.eck.github.com,resources=clusterelasticsearchinstances,verbs=get;list;watch
+
 // GetElasticsearchTargetInstance resolves the target Elasticsearch instance from either the project config
 // or a named ElasticsearchInstance resource. It returns the ElasticsearchSpec to use for API calls.
+// The instance is taken from, in this order: the name of targetConfig, the selector of targetConfig, the
+// DefaultElasticsearchInstanceAnnotation of the namespace and the project config. A targetConfig of the kind
+// ClusterElasticsearchInstance resolves the name and selector among the ClusterElasticsearchInstances.
+// The elasticsearchInstances overrides of the project config take precedence over the enabled setting
+// of a named instance.
+// With a failover pair, the instance resolved this way is the primary; the secondary is returned instead while the
+// primary is unreachable for longer than failover.after.
 func GetElasticsearchTargetInstance(
 	cli client.Client,
 	ctx context.Context,
@@ -138,19 +196,147 @@ func GetElasticsearchTargetInstance(
 	defaultElasticsearch configv2.ElasticsearchSpec,
 	targetConfig eseckv1alpha1.CommonElasticsearchConfig,
 	namespace string,
+) (*configv2.ElasticsearchSpec, error) {
+	primary, err := resolveElasticsearchTargetInstance(cli, ctx, recorder, object, defaultElasticsearch, targetConfig, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if targetConfig.Failover == nil {
+		defaultFailoverDecisions.remove(failoverKey(object), primary.Url)
+		if err := cleanUpRemovedFailover(cli, ctx, recorder, object, defaultElasticsearch, primary.Url, namespace); err != nil {
+			return nil, err
+		}
+		return primary, nil
+	}
+	primaryNamespace := namespace
+	if targetConfig.ElasticsearchInstanceNamespace != "" {
+		primaryNamespace = targetConfig.ElasticsearchInstanceNamespace
+	}
+	return selectFailoverInstance(cli, ctx, recorder, object, defaultElasticsearch, primary, *targetConfig.Failover, primaryNamespace, namespace)
+}
+
+// resolveElasticsearchTargetInstance resolves the instance named or selected by targetConfig, ignoring its failover
+// pair
+func resolveElasticsearchTargetInstance(
+	cli client.Client,
+	ctx context.Context,
+	recorder record.EventRecorder,
+	object runtime.Object,
+	defaultElasticsearch configv2.ElasticsearchSpec,
+	targetConfig eseckv1alpha1.CommonElasticsearchConfig,
+	namespace string,
 ) (*configv2.ElasticsearchSpec, error) {
 	targetInstance := defaultElasticsearch
-	if targetConfig.ElasticsearchInstance != "" {
-		if targetConfig.ElasticsearchInstanceNamespace != "" {
-			namespace = targetConfig.ElasticsearchInstanceNamespace
+	instanceName := targetConfig.ElasticsearchInstance
+	if instanceName == "" && targetConfig.Selector == nil {
+		namespaceDefault, err := utils.GetNamespaceDefault(cli, ctx, namespace, utils.DefaultElasticsearchInstanceAnnotation)
+		if err != nil {
+			return nil, err
+		}
+		instanceName = namespaceDefault
+	}
+	if targetConfig.ElasticsearchInstanceNamespace != "" {
+		namespace = targetConfig.ElasticsearchInstanceNamespace
+	}
+	switch {
+	case targetConfig.IsClusterInstance() && instanceName != "":
+		var resourceInstance eseckv1alpha1.ClusterElasticsearchInstance
+		if err := cli.Get(ctx, client.ObjectKey{Name: instanceName}, &resourceInstance); err != nil {
+			recorder.Event(object, "Warning", "Failed to load target instance", fmt.Sprintf("Target instance not found: %s", err.Error()))
+			return nil, err
+		}
+		return useElasticsearchInstance(recorder, object, resourceInstance.Spec, "", resourceInstance.Name)
+	case targetConfig.IsClusterInstance() && targetConfig.Selector != nil:
+		var resourceInstance eseckv1alpha1.ClusterElasticsearchInstance
+		if err := SelectTargetClusterElasticsearchInstance(cli, ctx, targetConfig.Selector, &resourceInstance); err != nil {
+			recorder.Event(object, "Warning", "Failed to load target instance", fmt.Sprintf("Target instance not selected: %s", err.Error()))
+			return nil, err
 		}
+		return useElasticsearchInstance(recorder, object, resourceInstance.Spec, "", resourceInstance.Name)
+	case instanceName != "":
 		var resourceInstance eseckv1alpha1.ElasticsearchInstance
-		if err := GetTargetElasticsearchInstance(cli, ctx, namespace, targetConfig.ElasticsearchInstance, &resourceInstance); err != nil {
+		if err := GetTargetElasticsearchInstance(cli, ctx, namespace, instanceName, &resourceInstance); err != nil {
 			recorder.Event(object, "Warning", "Failed to load target instance", fmt.Sprintf("Target instance not found: %s", err.Error()))
 			return nil, err
 		}
-
-		targetInstance = resourceInstance.Spec
+		return useElasticsearchInstance(recorder, object, resourceInstance.Spec, resourceInstance.Namespace, resourceInstance.Name)
+	case targetConfig.Selector != nil:
+		var resourceInstance eseckv1alpha1.ElasticsearchInstance
+		if err := SelectTargetElasticsearchInstance(cli, ctx, namespace, targetConfig.Selector, &resourceInstance); err != nil {
+			recorder.Event(object, "Warning", "Failed to load target instance", fmt.Sprintf("Target instance not selected: %s", err.Error()))
+			return nil, err
+		}
+		return useElasticsearchInstance(recorder, object, resourceInstance.Spec, resourceInstance.Namespace, resourceInstance.Name)
 	}
 	return &targetInstance, nil
 }
+
+// useElasticsearchInstance returns the spec of the instance after checking the namespaces of its Secrets, applying
+// the elasticsearchInstances overrides of the project config. The namespace of a ClusterElasticsearchInstance is
+// empty.
+func useElasticsearchInstance(recorder record.EventRecorder, object runtime.Object, spec configv2.ElasticsearchSpec, namespace string, name string) (*configv2.ElasticsearchSpec, error) {
+	var auth *configv2.UsernamePasswordAuthentication
+	if spec.Authentication != nil {
+		auth = spec.Authentication.UsernamePassword
+	}
+	if err := utils.ValidateInstanceSecrets(namespace, auth, spec.Certificate); err != nil {
+		recorder.Event(object, "Warning", "Failed to load target instance", fmt.Sprintf("Target instance %s is invalid: %s", name, err.Error()))
+		return nil, err
+	}
+	spec.Enabled = utils.ElasticsearchInstanceEnabled(namespace, name, spec.Enabled)
+	return &spec, nil
+}
+
+// SelectTargetElasticsearchInstance gets the single ElasticsearchInstance in the namespace matching the selector, it
+// returns an error if none or more than one match
+func SelectTargetElasticsearchInstance(cli client.Client, ctx context.Context, namespace string, selector *metav1.LabelSelector, esInstance *eseckv1alpha1.ElasticsearchInstance) error {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return fmt.Errorf("invalid target instance selector: %w", err)
+	}
+	var instances eseckv1alpha1.ElasticsearchInstanceList
+	if err := cli.List(ctx, &instances, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: labelSelector}); err != nil {
+		return err
+	}
+	switch len(instances.Items) {
+	case 0:
+		return fmt.Errorf("no ElasticsearchInstance in namespace %s matches the selector %q", namespace, labelSelector.String())
+	case 1:
+		*esInstance = instances.Items[0]
+		return nil
+	default:
+		names := make([]string, len(instances.Items))
+		for i, instance := range instances.Items {
+			names[i] = instance.Name
+		}
+		return fmt.Errorf("the selector %q is ambiguous, it matches the ElasticsearchInstances %s in namespace %s",
+			labelSelector.String(), strings.Join(names, ", "), namespace)
+	}
+}
+
+// SelectTargetClusterElasticsearchInstance gets the single ClusterElasticsearchInstance matching the selector, it
+// returns an error if none or more than one match
+func SelectTargetClusterElasticsearchInstance(cli client.Client, ctx context.Context, selector *metav1.LabelSelector, esInstance *eseckv1alpha1.ClusterElasticsearchInstance) error {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return fmt.Errorf("invalid target instance selector: %w", err)
+	}
+	var instances eseckv1alpha1.ClusterElasticsearchInstanceList
+	if err := cli.List(ctx, &instances, client.MatchingLabelsSelector{Selector: labelSelector}); err != nil {
+		return err
+	}
+	switch len(instances.Items) {
+	case 0:
+		return fmt.Errorf("no ClusterElasticsearchInstance matches the selector %q", labelSelector.String())
+	case 1:
+		*esInstance = instances.Items[0]
+		return nil
+	default:
+		names := make([]string, len(instances.Items))
+		for i, instance := range instances.Items {
+			names[i] = instance.Name
+		}
+		return fmt.Errorf("the selector %q is ambiguous, it matches the ClusterElasticsearchInstances %s",
+			labelSelector.String(), strings.Join(names, ", "))
+	}
+}
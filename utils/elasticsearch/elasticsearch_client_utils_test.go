@@ -0,0 +1,305 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetElasticsearchTargetInstance_Selector(t *testing.T) {
+	instance := func(name string, color string) *eseckv1alpha1.ElasticsearchInstance {
+		return &eseckv1alpha1.ElasticsearchInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "logging", Labels: map[string]string{"cluster": "logs", "color": color}},
+			Spec:       configv2.ElasticsearchSpec{Enabled: true, Url: "https://" + name + ":9200"},
+		}
+	}
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance("logs-blue", "blue"), instance("logs-green", "green")).Build()
+
+	tests := []struct {
+		name         string
+		targetConfig eseckv1alpha1.CommonElasticsearchConfig
+		wantUrl      string
+		wantErr      bool
+	}{
+		{
+			name:         "single match",
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"color": "green"}}},
+			wantUrl:      "https://logs-green:9200",
+		},
+		{
+			name:         "ambiguous",
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"cluster": "logs"}}},
+			wantErr:      true,
+		},
+		{
+			name:         "no match",
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"color": "red"}}},
+			wantErr:      true,
+		},
+		{
+			name:         "other namespace",
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{ElasticsearchInstanceNamespace: "default", Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"color": "green"}}},
+			wantErr:      true,
+		},
+		{
+			name: "name takes precedence",
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{
+				ElasticsearchInstance: "logs-blue",
+				Selector:              &metav1.LabelSelector{MatchLabels: map[string]string{"color": "green"}},
+			},
+			wantUrl: "https://logs-blue:9200",
+		},
+		{
+			name:         "default instance",
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{},
+			wantUrl:      "https://default:9200",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "logging"}}
+			targetInstance, err := GetElasticsearchTargetInstance(cli, context.Background(), record.NewFakeRecorder(10), index,
+				configv2.ElasticsearchSpec{Url: "https://default:9200"}, tt.targetConfig, "logging")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetElasticsearchTargetInstance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && targetInstance.Url != tt.wantUrl {
+				t.Errorf("GetElasticsearchTargetInstance() url = %s, want %s", targetInstance.Url, tt.wantUrl)
+			}
+		})
+	}
+}
+
+func TestGetElasticsearchTargetInstance_NamespaceDefault(t *testing.T) {
+	instance := func(name string, namespace string) *eseckv1alpha1.ElasticsearchInstance {
+		return &eseckv1alpha1.ElasticsearchInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       configv2.ElasticsearchSpec{Enabled: true, Url: "https://" + name + "." + namespace + ":9200"},
+		}
+	}
+	teamNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: map[string]string{
+		utils.DefaultElasticsearchInstanceAnnotation: "logs",
+	}}}
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(teamNamespace, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+		instance("logs", "team-a"), instance("metrics", "team-a"), instance("logs", "shared")).Build()
+
+	tests := []struct {
+		name         string
+		namespace    string
+		targetConfig eseckv1alpha1.CommonElasticsearchConfig
+		wantUrl      string
+		wantErr      bool
+	}{
+		{name: "namespace default", namespace: "team-a", wantUrl: "https://logs.team-a:9200"},
+		{name: "name takes precedence", namespace: "team-a", targetConfig: eseckv1alpha1.CommonElasticsearchConfig{ElasticsearchInstance: "metrics"}, wantUrl: "https://metrics.team-a:9200"},
+		{
+			name:         "selector takes precedence",
+			namespace:    "team-a",
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "none"}}},
+			wantErr:      true,
+		},
+		{name: "instance namespace", namespace: "team-a", targetConfig: eseckv1alpha1.CommonElasticsearchConfig{ElasticsearchInstanceNamespace: "shared"}, wantUrl: "https://logs.shared:9200"},
+		{name: "namespace without annotation", namespace: "team-b", wantUrl: "https://default:9200"},
+		{name: "unknown namespace", namespace: "team-c", wantUrl: "https://default:9200"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: tt.namespace}}
+			targetInstance, err := GetElasticsearchTargetInstance(cli, context.Background(), record.NewFakeRecorder(10), index,
+				configv2.ElasticsearchSpec{Url: "https://default:9200"}, tt.targetConfig, tt.namespace)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetElasticsearchTargetInstance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && targetInstance.Url != tt.wantUrl {
+				t.Errorf("GetElasticsearchTargetInstance() url = %s, want %s", targetInstance.Url, tt.wantUrl)
+			}
+		})
+	}
+}
+
+func TestGetElasticsearchTargetInstance_ClusterInstance(t *testing.T) {
+	auth := func(namespace string) *configv2.ElasticsearchAuthentication {
+		return &configv2.ElasticsearchAuthentication{UsernamePassword: &configv2.UsernamePasswordAuthentication{
+			SecretName: "elastic-user", UserName: "elastic", Namespace: namespace,
+		}}
+	}
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&eseckv1alpha1.ClusterElasticsearchInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Labels: map[string]string{"cluster": "shared"}},
+			Spec:       configv2.ElasticsearchSpec{Enabled: true, Url: "https://shared:9200", Authentication: auth("elastic-system")},
+		},
+		&eseckv1alpha1.ClusterElasticsearchInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "no-secret-namespace"},
+			Spec:       configv2.ElasticsearchSpec{Enabled: true, Url: "https://other:9200", Authentication: auth("")},
+		},
+		&eseckv1alpha1.ElasticsearchInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "logging"},
+			Spec:       configv2.ElasticsearchSpec{Enabled: true, Url: "https://logging:9200", Authentication: auth("logging")},
+		},
+		&eseckv1alpha1.ElasticsearchInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "foreign-secret", Namespace: "logging"},
+			Spec:       configv2.ElasticsearchSpec{Enabled: true, Url: "https://foreign:9200", Authentication: auth("elastic-system")},
+		},
+	).Build()
+
+	tests := []struct {
+		name         string
+		targetConfig eseckv1alpha1.CommonElasticsearchConfig
+		wantUrl      string
+		wantErr      bool
+	}{
+		{
+			name:         "by name",
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{Kind: eseckv1alpha1.ClusterElasticsearchInstanceKind, ElasticsearchInstance: "shared"},
+			wantUrl:      "https://shared:9200",
+		},
+		{
+			name: "namespace is ignored",
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{
+				Kind: eseckv1alpha1.ClusterElasticsearchInstanceKind, ElasticsearchInstance: "shared", ElasticsearchInstanceNamespace: "other",
+			},
+			wantUrl: "https://shared:9200",
+		},
+		{
+			name: "by selector",
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{
+				Kind: eseckv1alpha1.ClusterElasticsearchInstanceKind, Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"cluster": "shared"}},
+			},
+			wantUrl: "https://shared:9200",
+		},
+		{
+			name:         "namespaced instance of the same name",
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{ElasticsearchInstance: "shared"},
+			wantUrl:      "https://logging:9200",
+		},
+		{
+			name:         "not found",
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{Kind: eseckv1alpha1.ClusterElasticsearchInstanceKind, ElasticsearchInstance: "missing"},
+			wantErr:      true,
+		},
+		{
+			name:         "cluster instance without secret namespace",
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{Kind: eseckv1alpha1.ClusterElasticsearchInstanceKind, ElasticsearchInstance: "no-secret-namespace"},
+			wantErr:      true,
+		},
+		{
+			name:         "namespaced instance with secret of another namespace",
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{ElasticsearchInstance: "foreign-secret"},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "logging"}}
+			targetInstance, err := GetElasticsearchTargetInstance(cli, context.Background(), record.NewFakeRecorder(10), index,
+				configv2.ElasticsearchSpec{Url: "https://default:9200"}, tt.targetConfig, "logging")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetElasticsearchTargetInstance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && targetInstance.Url != tt.wantUrl {
+				t.Errorf("GetElasticsearchTargetInstance() url = %s, want %s", targetInstance.Url, tt.wantUrl)
+			}
+		})
+	}
+}
+
+func TestGetElasticsearchClient_Certificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	}))
+	defer server.Close()
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "es-ca", Namespace: "logging"},
+			Data:       map[string][]byte{"ca.crt": caCert, "invalid.crt": []byte("not a certificate")},
+		},
+	).Build()
+	esSpec := configv2.ElasticsearchSpec{Url: server.URL, Certificate: &configv2.PublicCertificate{SecretName: "es-ca", CertificateKey: "ca.crt"}}
+
+	esClient, err := GetElasticsearchClient(cli, context.Background(), esSpec, ctrl.Request{}, "logging")
+	if err != nil {
+		t.Fatalf("GetElasticsearchClient() error = %v", err)
+	}
+	res, err := esClient.Ping()
+	if err != nil {
+		t.Fatalf("Expected the instance to be trusted with the CA of the Secret, got %v", err)
+	}
+	res.Body.Close()
+
+	esSpec.Certificate.CertificateKey = "invalid.crt"
+	if _, err := GetElasticsearchClient(cli, context.Background(), esSpec, ctrl.Request{}, "logging"); err == nil {
+		t.Error("Expected an error for a Secret without a PEM certificate")
+	}
+}
+
+func TestGetElasticsearchClient_CertificateTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	}))
+	defer server.Close()
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "es-ca", Namespace: "logging"},
+			Data:       map[string][]byte{"ca.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})},
+		},
+	).Build()
+	esSpec := configv2.ElasticsearchSpec{Url: server.URL, Certificate: &configv2.PublicCertificate{SecretName: "es-ca", CertificateKey: "ca.crt"}}
+
+	ctx, reconcileSpan := utils.StartSpan(context.Background(), "Reconcile Index")
+	esClient, err := GetElasticsearchClient(cli, ctx, esSpec, ctrl.Request{}, "logging")
+	if err != nil {
+		t.Fatalf("GetElasticsearchClient() error = %v", err)
+	}
+	res, err := esClient.Ping()
+	if err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	res.Body.Close()
+	utils.EndSpan(reconcileSpan, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(spans))
+	}
+	if spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Error("request span is not a child of the reconcile span")
+	}
+}
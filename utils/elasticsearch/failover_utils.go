@@ -0,0 +1,391 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultFailoverAfter is how long the primary of a failover pair has to be unreachable before resources are applied
+// to the secondary, if failover.after is not set
+const DefaultFailoverAfter = 5 * time.Minute
+
+// FailedOver condition, True while the resource is applied to the secondary of its failover pair and False while it
+// is applied to the primary. The reason names the instance holding the object.
+const (
+	FailedOverConditionType = "FailedOver"
+	FailoverPrimaryReason   = "Primary"
+	FailoverSecondaryReason = "Secondary"
+)
+
+// failoverDecision is the instance of its failover pair a resource is currently applied to
+type failoverDecision struct {
+	secondary         bool
+	primary           string
+	active            string
+	secondaryInstance eseckv1alpha1.ElasticsearchFailoverInstance
+}
+
+// failoverDecisions holds the last failover decision per resource and primary instance, a resource may target
+// several instances, e.g. the read-only replicas of a snapshot repository. After a restart of the operator, the
+// decision is restored from status.failover of the resource.
+type failoverDecisions struct {
+	mu        sync.Mutex
+	decisions map[string]map[string]failoverDecision
+}
+
+var defaultFailoverDecisions = &failoverDecisions{decisions: make(map[string]map[string]failoverDecision)}
+
+// active returns the decision of key that applies the resource to the instance at url
+func (d *failoverDecisions) active(key string, url string) (failoverDecision, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, decision := range d.decisions[key] {
+		if decision.active == url {
+			return decision, true
+		}
+	}
+	return failoverDecision{}, false
+}
+
+// get returns the decision of key for the primary at url
+func (d *failoverDecisions) get(key string, url string) (failoverDecision, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	decision, ok := d.decisions[key][url]
+	return decision, ok
+}
+
+// set stores the decision of key
+func (d *failoverDecisions) set(key string, decision failoverDecision) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.decisions[key] == nil {
+		d.decisions[key] = make(map[string]failoverDecision)
+	}
+	d.decisions[key][decision.primary] = decision
+}
+
+// remove forgets the decision of key for the primary at url, once the failover pair was removed from the resource
+func (d *failoverDecisions) remove(key string, url string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.decisions[key], url)
+	if len(d.decisions[key]) == 0 {
+		delete(d.decisions, key)
+	}
+}
+
+func failoverKey(object runtime.Object) string {
+	if obj, ok := object.(client.Object); ok {
+		return fmt.Sprintf("%T/%s", obj, client.ObjectKeyFromObject(obj))
+	}
+	return fmt.Sprintf("%T", object)
+}
+
+// FailoverCleanup deletes the object of obj from the instance of esClient at url
+type FailoverCleanup func(esClient *elasticsearch.Client, url string, obj client.Object) error
+
+// failoverCleanups holds the FailoverCleanup per kind
+var failoverCleanups sync.Map
+
+// RegisterFailoverCleanup registers how the object of the kind of obj is deleted from the secondary of a failover pair
+// once the resource fails back to the primary. The object of kinds without a cleanup is left on the secondary.
+func RegisterFailoverCleanup(obj client.Object, cleanup FailoverCleanup) {
+	failoverCleanups.Store(fmt.Sprintf("%T", obj), cleanup)
+}
+
+// selectFailoverInstance returns primary unless its circuit has been open for longer than failover.after and a probe
+// still fails, in that case the secondary of the failover pair is resolved and returned. While the secondary holds
+// the object, it is returned until a probe of the primary succeeds; the object is then deleted from the secondary
+// before the primary is returned. namespace is the namespace the primary was resolved in, defaultNamespace the
+// namespace of the resource. A change of the instance holding the object is recorded as event.
+func selectFailoverInstance(
+	cli client.Client,
+	ctx context.Context,
+	recorder record.EventRecorder,
+	object runtime.Object,
+	defaultElasticsearch configv2.ElasticsearchSpec,
+	primary *configv2.ElasticsearchSpec,
+	failover eseckv1alpha1.ElasticsearchFailover,
+	namespace string,
+	defaultNamespace string,
+) (*configv2.ElasticsearchSpec, error) {
+	logger := log.FromContext(ctx)
+
+	key := failoverKey(object)
+	previous, known := defaultFailoverDecisions.get(key, primary.Url)
+	if !known {
+		previous = failoverDecision{primary: primary.Url}
+		if recorded, ok := recordedFailover(object, primary.Url); ok {
+			previous.secondary = recorded.Secondary != nil
+			previous.active = recorded.Active
+		}
+	}
+	var unreachable bool
+	if previous.secondary {
+		unreachable = !primaryReachable(cli, ctx, *primary, namespace)
+	} else {
+		unreachable = primaryUnreachable(cli, ctx, *primary, failover, namespace)
+	}
+
+	target := primary
+	if unreachable || previous.secondary {
+		secondary, secondaryNamespace, err := resolveSecondary(cli, ctx, recorder, object, defaultElasticsearch, failover, defaultNamespace)
+		if err != nil {
+			return nil, err
+		}
+		if unreachable {
+			target = secondary
+		} else {
+			// The primary is reachable again, the copy on the secondary is removed before failing back
+			if err := cleanUpSecondary(cli, ctx, object, *secondary, secondaryNamespace); err != nil {
+				recorder.Event(object, "Warning", "FailbackFailed",
+					fmt.Sprintf("Failed to delete the object from the secondary instance %s before failing back to the primary instance %s: %s",
+						secondary.Url, primary.Url, err.Error()))
+				return nil, err
+			}
+			previous.active = secondary.Url
+		}
+	}
+
+	decision := failoverDecision{secondary: target != primary, primary: primary.Url, active: target.Url, secondaryInstance: failover.Secondary}
+	defaultFailoverDecisions.set(key, decision)
+	switch {
+	case decision.secondary && !previous.secondary:
+		message := fmt.Sprintf("Primary instance %s is unreachable for more than %s, applying to the secondary instance %s",
+			primary.Url, failoverAfter(failover), target.Url)
+		logger.Info("Failing over to the secondary instance", "primary", primary.Url, "secondary", target.Url)
+		recorder.Event(object, "Warning", "FailedOver", message)
+	case !decision.secondary && previous.secondary:
+		logger.Info("Failing back to the primary instance", "primary", primary.Url, "secondary", previous.active)
+		recorder.Event(object, "Normal", "FailedBack",
+			fmt.Sprintf("Primary instance %s is reachable again, applying to it and deleted the object from %s", primary.Url, previous.active))
+	}
+	return target, nil
+}
+
+// resolveSecondary resolves the secondary of the failover pair and returns it with the namespace its Secrets are
+// read from
+func resolveSecondary(
+	cli client.Client,
+	ctx context.Context,
+	recorder record.EventRecorder,
+	object runtime.Object,
+	defaultElasticsearch configv2.ElasticsearchSpec,
+	failover eseckv1alpha1.ElasticsearchFailover,
+	defaultNamespace string,
+) (*configv2.ElasticsearchSpec, string, error) {
+	secondaryConfig := failover.Secondary.TargetConfig()
+	secondary, err := resolveElasticsearchTargetInstance(cli, ctx, recorder, object, defaultElasticsearch, secondaryConfig, defaultNamespace)
+	if err != nil {
+		return nil, "", err
+	}
+	secondaryNamespace := defaultNamespace
+	if secondaryConfig.ElasticsearchInstanceNamespace != "" {
+		secondaryNamespace = secondaryConfig.ElasticsearchInstanceNamespace
+	}
+	if !secondaryConfig.IsClusterInstance() {
+		pinSecretNamespaces(secondary, secondaryNamespace)
+	}
+	return secondary, secondaryNamespace, nil
+}
+
+// recordedFailover returns the entry of status.failover of object for the primary at url
+func recordedFailover(object runtime.Object, url string) (eseckv1alpha1.FailoverStatus, bool) {
+	holder, ok := object.(eseckv1alpha1.FailoverStatusHolder)
+	if !ok {
+		return eseckv1alpha1.FailoverStatus{}, false
+	}
+	for _, recorded := range *holder.FailoverStatus() {
+		if recorded.Primary == url {
+			return recorded, true
+		}
+	}
+	return eseckv1alpha1.FailoverStatus{}, false
+}
+
+// cleanUpRemovedFailover deletes the object from the secondary recorded in status.failover, if the failover pair of
+// the primary at url was removed from the resource while the secondary held the object. The entry is dropped from
+// the status once the object was deleted, or if the secondary can not be resolved anymore.
+func cleanUpRemovedFailover(
+	cli client.Client,
+	ctx context.Context,
+	recorder record.EventRecorder,
+	object runtime.Object,
+	defaultElasticsearch configv2.ElasticsearchSpec,
+	url string,
+	defaultNamespace string,
+) error {
+	recorded, ok := recordedFailover(object, url)
+	if !ok || recorded.Secondary == nil {
+		return nil
+	}
+	secondary, secondaryNamespace, err := resolveSecondary(cli, ctx, recorder, object, defaultElasticsearch,
+		eseckv1alpha1.ElasticsearchFailover{Secondary: *recorded.Secondary}, defaultNamespace)
+	if err != nil {
+		recorder.Event(object, "Warning", "FailbackFailed",
+			fmt.Sprintf("The failover pair was removed, but its secondary instance %s can not be resolved to delete the object from it: %s",
+				recorded.Active, err.Error()))
+	} else if err := cleanUpSecondary(cli, ctx, object, *secondary, secondaryNamespace); err != nil {
+		recorder.Event(object, "Warning", "FailbackFailed",
+			fmt.Sprintf("Failed to delete the object from the secondary instance %s after the failover pair was removed: %s",
+				secondary.Url, err.Error()))
+		return err
+	} else {
+		recorder.Event(object, "Normal", "FailedBack",
+			fmt.Sprintf("The failover pair was removed, applying to the primary instance %s and deleted the object from %s", url, secondary.Url))
+	}
+	removeFailoverStatus(object.(eseckv1alpha1.FailoverStatusHolder), url)
+	return nil
+}
+
+// removeFailoverStatus drops the entry of the primary at url from status.failover and reports whether it was present
+func removeFailoverStatus(holder eseckv1alpha1.FailoverStatusHolder, url string) bool {
+	statuses := holder.FailoverStatus()
+	for i, recorded := range *statuses {
+		if recorded.Primary == url {
+			*statuses = append((*statuses)[:i:i], (*statuses)[i+1:]...)
+			if len(*statuses) == 0 {
+				*statuses = nil
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// setFailoverStatus records the decision in status.failover and reports whether it changed
+func setFailoverStatus(holder eseckv1alpha1.FailoverStatusHolder, decision failoverDecision) bool {
+	recorded := eseckv1alpha1.FailoverStatus{Primary: decision.primary, Active: decision.active}
+	if decision.secondary {
+		secondary := decision.secondaryInstance
+		recorded.Secondary = &secondary
+	}
+	statuses := holder.FailoverStatus()
+	for i := range *statuses {
+		if (*statuses)[i].Primary == decision.primary {
+			if equality.Semantic.DeepEqual((*statuses)[i], recorded) {
+				return false
+			}
+			(*statuses)[i] = recorded
+			return true
+		}
+	}
+	*statuses = append(*statuses, recorded)
+	return true
+}
+
+// cleanUpSecondary deletes the object from the secondary with the FailoverCleanup registered for its kind
+func cleanUpSecondary(cli client.Client, ctx context.Context, object runtime.Object, secondary configv2.ElasticsearchSpec, namespace string) error {
+	obj, ok := object.(client.Object)
+	if !ok {
+		return nil
+	}
+	cleanup, ok := failoverCleanups.Load(fmt.Sprintf("%T", obj))
+	if !ok {
+		return nil
+	}
+	esClient, err := GetElasticsearchClient(cli, ctx, secondary, ctrl.Request{}, namespace)
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("Deleting the object from the secondary instance", "secondary", secondary.Url)
+	return cleanup.(FailoverCleanup)(esClient, secondary.Url, obj)
+}
+
+// primaryUnreachable reports whether the circuit of the primary has been open for at least failover.after and a probe
+// of the primary, if due, failed
+func primaryUnreachable(cli client.Client, ctx context.Context, primary configv2.ElasticsearchSpec,
+	failover eseckv1alpha1.ElasticsearchFailover, namespace string) bool {
+	since, open := utils.TargetUnavailableSince(primary.Url)
+	if !open || time.Since(since) < failoverAfter(failover) {
+		return false
+	}
+	esClient, err := GetElasticsearchClient(cli, ctx, primary, ctrl.Request{}, namespace)
+	if err != nil {
+		// The primary can not be probed, it is kept unreachable until the client can be created
+		log.FromContext(ctx).Error(err, "Failed to create the Elasticsearch client of the primary instance")
+		return true
+	}
+	return !utils.ProbeTarget(ctx, primary.Url, pingProbe(esClient))
+}
+
+// primaryReachable reports whether a probe of the primary succeeds, while its circuit is open the primary is probed at
+// most every CircuitBreakerProbeInterval
+func primaryReachable(cli client.Client, ctx context.Context, primary configv2.ElasticsearchSpec, namespace string) bool {
+	esClient, err := GetElasticsearchClient(cli, ctx, primary, ctrl.Request{}, namespace)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to create the Elasticsearch client of the primary instance")
+		return false
+	}
+	if _, open := utils.TargetUnavailableSince(primary.Url); open {
+		return utils.ProbeTarget(ctx, primary.Url, pingProbe(esClient))
+	}
+	return pingProbe(esClient)(ctx) == nil
+}
+
+func failoverAfter(failover eseckv1alpha1.ElasticsearchFailover) time.Duration {
+	if failover.After.Duration <= 0 {
+		return DefaultFailoverAfter
+	}
+	return failover.After.Duration
+}
+
+// pinSecretNamespaces sets the namespace of the Secrets of the secondary, so that they are read from the namespace of
+// the secondary instead of the namespace the controller reads the Secrets of the primary from
+func pinSecretNamespaces(spec *configv2.ElasticsearchSpec, namespace string) {
+	if spec.Authentication != nil && spec.Authentication.UsernamePassword != nil && spec.Authentication.UsernamePassword.Namespace == "" {
+		authentication := *spec.Authentication
+		usernamePassword := *authentication.UsernamePassword
+		usernamePassword.Namespace = namespace
+		authentication.UsernamePassword = &usernamePassword
+		spec.Authentication = &authentication
+	}
+	if spec.Certificate != nil && spec.Certificate.Namespace == "" {
+		certificate := *spec.Certificate
+		certificate.Namespace = namespace
+		spec.Certificate = &certificate
+	}
+}
+
+// updateFailoverStatus sets the FailedOver condition and status.failover from the failover decision that applies obj
+// to the instance at url, or removes them if obj has no failover pair. It returns whether the status changed.
+func updateFailoverStatus(obj client.Object, conditions *[]metav1.Condition, url string) bool {
+	decision, ok := defaultFailoverDecisions.active(failoverKey(obj), url)
+	holder, recordsFailover := obj.(eseckv1alpha1.FailoverStatusHolder)
+	if !ok {
+		removed := recordsFailover && removeFailoverStatus(holder, url)
+		return meta.RemoveStatusCondition(conditions, FailedOverConditionType) || removed
+	}
+	recorded := recordsFailover && setFailoverStatus(holder, decision)
+	condition := metav1.Condition{
+		Type:               FailedOverConditionType,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             FailoverPrimaryReason,
+		Message:            fmt.Sprintf("Applied to the primary instance %s", decision.active),
+	}
+	if decision.secondary {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = FailoverSecondaryReason
+		condition.Message = fmt.Sprintf("Applied to the secondary instance %s, the primary instance %s is unreachable",
+			decision.active, decision.primary)
+	}
+	return meta.SetStatusCondition(conditions, condition) || recorded
+}
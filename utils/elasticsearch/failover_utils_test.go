@@ -0,0 +1,253 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetElasticsearchTargetInstance_Failover(t *testing.T) {
+	// The primary answers 503 while it is down, so that its circuit opens and probes fail
+	var primaryDown atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		if primaryDown.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	primaryUrl := server.URL
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&eseckv1alpha1.ElasticsearchInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "primary", Namespace: "logging"},
+			Spec:       configv2.ElasticsearchSpec{Enabled: true, Url: primaryUrl},
+		},
+		&eseckv1alpha1.ElasticsearchInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "secondary", Namespace: "dr"},
+			Spec: configv2.ElasticsearchSpec{Enabled: true, Url: "https://secondary:9200",
+				Authentication: &configv2.ElasticsearchAuthentication{
+					UsernamePassword: &configv2.UsernamePasswordAuthentication{SecretName: "secondary-user", UserName: "operator"},
+				}},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "secondary-user", Namespace: "dr"},
+			Data:       map[string][]byte{"operator": []byte("secret")},
+		},
+	).Build()
+	recorder := record.NewFakeRecorder(10)
+	index := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "logging"}}
+	targetConfig := eseckv1alpha1.CommonElasticsearchConfig{
+		ElasticsearchInstance: "primary",
+		Failover: &eseckv1alpha1.ElasticsearchFailover{
+			Secondary: eseckv1alpha1.ElasticsearchFailoverInstance{Name: "secondary", Namespace: "dr"},
+			After:     metav1.Duration{Duration: time.Nanosecond},
+		},
+	}
+	var cleanedUp []string
+	var cleanupErr error
+	RegisterFailoverCleanup(&eseckv1alpha1.Index{}, func(_ *elasticsearch.Client, url string, obj client.Object) error {
+		if cleanupErr != nil {
+			return cleanupErr
+		}
+		cleanedUp = append(cleanedUp, url+"/"+obj.GetName())
+		return nil
+	})
+	defer failoverCleanups.Delete(fmt.Sprintf("%T", index))
+	resolve := func() (*configv2.ElasticsearchSpec, error) {
+		return GetElasticsearchTargetInstance(cli, context.Background(), recorder, index,
+			configv2.ElasticsearchSpec{Url: "https://default:9200"}, targetConfig, "logging")
+	}
+	mustResolve := func() *configv2.ElasticsearchSpec {
+		t.Helper()
+		targetInstance, err := resolve()
+		if err != nil {
+			t.Fatalf("GetElasticsearchTargetInstance() error = %v", err)
+		}
+		return targetInstance
+	}
+
+	if targetInstance := mustResolve(); targetInstance.Url != primaryUrl {
+		t.Errorf("Expected the primary while it is reachable, got %s", targetInstance.Url)
+	}
+
+	primaryDown.Store(true)
+	for i := 0; i < utils.CircuitBreakerFailureThreshold; i++ {
+		httpClient := &http.Client{Transport: utils.CircuitBreakerTransport(primaryUrl, http.DefaultTransport)}
+		if res, err := httpClient.Get(primaryUrl); err != nil || res.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("Expected the primary to be unavailable, got %v", err)
+		}
+	}
+	defer utils.ResetCircuit(primaryUrl)
+
+	targetInstance := mustResolve()
+	if targetInstance.Url != "https://secondary:9200" {
+		t.Fatalf("Expected the secondary while the primary is unreachable, got %s", targetInstance.Url)
+	}
+	if namespace := targetInstance.Authentication.UsernamePassword.Namespace; namespace != "dr" {
+		t.Errorf("Expected the Secret of the secondary to be read from its namespace, got %q", namespace)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "FailedOver") {
+		t.Errorf("Expected a FailedOver event, got %q", event)
+	}
+	if !updateFailoverStatus(index, &index.Status.Conditions, targetInstance.Url) ||
+		!meta.IsStatusConditionTrue(index.Status.Conditions, FailedOverConditionType) {
+		t.Errorf("Expected the FailedOver condition to be True, got %v", index.Status.Conditions)
+	}
+	wantStatus := []eseckv1alpha1.FailoverStatus{{Primary: primaryUrl, Active: "https://secondary:9200", Secondary: &targetConfig.Failover.Secondary}}
+	if !equality.Semantic.DeepEqual(index.Status.Failover, wantStatus) {
+		t.Errorf("Expected the secondary to be recorded in status.failover, got %v", index.Status.Failover)
+	}
+
+	// After a restart, the secondary recorded in the status holds the object until the primary is reachable
+	defaultFailoverDecisions.remove(failoverKey(index), primaryUrl)
+	utils.ResetCircuit(primaryUrl)
+	if targetInstance := mustResolve(); targetInstance.Url != "https://secondary:9200" {
+		t.Errorf("Expected the secondary recorded in the status after a restart, got %s", targetInstance.Url)
+	}
+	if len(recorder.Events) != 0 || len(cleanedUp) != 0 {
+		t.Errorf("Expected no failover nor cleanup while the secondary holds the object, got %d events and %v", len(recorder.Events), cleanedUp)
+	}
+
+	// The resource fails back once the object was deleted from the secondary
+	primaryDown.Store(false)
+	utils.ResetCircuit(primaryUrl)
+	cleanupErr = fmt.Errorf("secondary unavailable")
+	if _, err := resolve(); err == nil {
+		t.Error("Expected the failback to fail while the object can not be deleted from the secondary")
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "FailbackFailed") {
+		t.Errorf("Expected a FailbackFailed event, got %q", event)
+	}
+	cleanupErr = nil
+	if targetInstance := mustResolve(); targetInstance.Url != primaryUrl {
+		t.Errorf("Expected to fail back to the primary once it is reachable, got %s", targetInstance.Url)
+	}
+	if len(cleanedUp) != 1 || cleanedUp[0] != "https://secondary:9200/logs" {
+		t.Errorf("Expected the object to be deleted from the secondary, got %v", cleanedUp)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "FailedBack") {
+		t.Errorf("Expected a FailedBack event, got %q", event)
+	}
+	updateFailoverStatus(index, &index.Status.Conditions, primaryUrl)
+	if failedOver := meta.FindStatusCondition(index.Status.Conditions, FailedOverConditionType); failedOver == nil ||
+		failedOver.Status != metav1.ConditionFalse || failedOver.Reason != FailoverPrimaryReason {
+		t.Errorf("Expected the FailedOver condition to be False with reason Primary, got %v", failedOver)
+	}
+	mustResolve()
+	if len(cleanedUp) != 1 {
+		t.Errorf("Expected the object to be deleted from the secondary once, got %v", cleanedUp)
+	}
+
+	if len(index.Status.Failover) != 1 || index.Status.Failover[0].Active != primaryUrl || index.Status.Failover[0].Secondary != nil {
+		t.Errorf("Expected the primary to be recorded in status.failover, got %v", index.Status.Failover)
+	}
+
+	targetConfig.Failover = nil
+	mustResolve()
+	if !updateFailoverStatus(index, &index.Status.Conditions, primaryUrl) || len(index.Status.Conditions) != 0 || index.Status.Failover != nil {
+		t.Errorf("Expected the failover status to be removed with the failover pair, got %v and %v", index.Status.Conditions, index.Status.Failover)
+	}
+	if len(cleanedUp) != 1 {
+		t.Errorf("Expected no cleanup once the primary holds the object, got %v", cleanedUp)
+	}
+}
+
+func TestGetElasticsearchTargetInstance_FailoverRemoved(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&eseckv1alpha1.ElasticsearchInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "primary", Namespace: "logging"},
+			Spec:       configv2.ElasticsearchSpec{Enabled: true, Url: "https://primary:9200"},
+		},
+		&eseckv1alpha1.ElasticsearchInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "secondary", Namespace: "dr"},
+			Spec:       configv2.ElasticsearchSpec{Enabled: true, Url: "https://secondary:9200"},
+		},
+	).Build()
+	recorder := record.NewFakeRecorder(10)
+	// The secondary held the object when the failover pair was removed
+	index := &eseckv1alpha1.Index{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "logging"},
+		Status: eseckv1alpha1.IndexStatus{Failover: []eseckv1alpha1.FailoverStatus{{
+			Primary:   "https://primary:9200",
+			Active:    "https://secondary:9200",
+			Secondary: &eseckv1alpha1.ElasticsearchFailoverInstance{Name: "secondary", Namespace: "dr"},
+		}}},
+	}
+	var cleanedUp []string
+	cleanupErr := fmt.Errorf("secondary unavailable")
+	RegisterFailoverCleanup(&eseckv1alpha1.Index{}, func(_ *elasticsearch.Client, url string, obj client.Object) error {
+		if cleanupErr != nil {
+			return cleanupErr
+		}
+		cleanedUp = append(cleanedUp, url+"/"+obj.GetName())
+		return nil
+	})
+	defer failoverCleanups.Delete(fmt.Sprintf("%T", index))
+	resolve := func() (*configv2.ElasticsearchSpec, error) {
+		return GetElasticsearchTargetInstance(cli, context.Background(), recorder, index,
+			configv2.ElasticsearchSpec{}, eseckv1alpha1.CommonElasticsearchConfig{ElasticsearchInstance: "primary"}, "logging")
+	}
+
+	if _, err := resolve(); err == nil {
+		t.Error("Expected an error while the object can not be deleted from the secondary")
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "FailbackFailed") {
+		t.Errorf("Expected a FailbackFailed event, got %q", event)
+	}
+	if len(index.Status.Failover) != 1 {
+		t.Errorf("Expected the secondary to stay recorded until the object was deleted from it, got %v", index.Status.Failover)
+	}
+
+	cleanupErr = nil
+	targetInstance, err := resolve()
+	if err != nil || targetInstance.Url != "https://primary:9200" {
+		t.Fatalf("Expected the primary once the object was deleted from the secondary, got %v, %v", targetInstance, err)
+	}
+	if len(cleanedUp) != 1 || cleanedUp[0] != "https://secondary:9200/logs" {
+		t.Errorf("Expected the object to be deleted from the secondary, got %v", cleanedUp)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "FailedBack") {
+		t.Errorf("Expected a FailedBack event, got %q", event)
+	}
+	if index.Status.Failover != nil {
+		t.Errorf("Expected the secondary to be dropped from status.failover, got %v", index.Status.Failover)
+	}
+	if _, err := resolve(); err != nil || len(cleanedUp) != 1 {
+		t.Errorf("Expected the object to be deleted from the secondary once, got %v, %v", cleanedUp, err)
+	}
+}
+
+func TestFailoverAfter(t *testing.T) {
+	if got := failoverAfter(eseckv1alpha1.ElasticsearchFailover{}); got != DefaultFailoverAfter {
+		t.Errorf("failoverAfter() = %s, want %s", got, DefaultFailoverAfter)
+	}
+	if got := failoverAfter(eseckv1alpha1.ElasticsearchFailover{After: metav1.Duration{Duration: time.Minute}}); got != time.Minute {
+		t.Errorf("failoverAfter() = %s, want 1m", got)
+	}
+}
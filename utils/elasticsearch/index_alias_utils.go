@@ -0,0 +1,245 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// IndexAliasDefinition returns the definition of the alias as taken by the create index and aliases APIs, and as
+// returned by the get alias API
+func IndexAliasDefinition(alias v1alpha1.IndexAlias) (map[string]interface{}, error) {
+	definition := make(map[string]interface{})
+	if alias.Filter != "" {
+		var filter map[string]interface{}
+		if err := json.Unmarshal([]byte(alias.Filter), &filter); err != nil {
+			return nil, fmt.Errorf("filter of alias %s is not a JSON object: %w", alias.Name, err)
+		}
+		definition["filter"] = filter
+	}
+	// Elasticsearch stores routing as index and search routing
+	indexRouting, searchRouting := alias.Routing, alias.Routing
+	if alias.IndexRouting != "" {
+		indexRouting = alias.IndexRouting
+	}
+	if alias.SearchRouting != "" {
+		searchRouting = alias.SearchRouting
+	}
+	if indexRouting != "" {
+		definition["index_routing"] = indexRouting
+	}
+	if searchRouting != "" {
+		definition["search_routing"] = searchRouting
+	}
+	if alias.IsWriteIndex != nil {
+		definition["is_write_index"] = *alias.IsWriteIndex
+	}
+	if alias.IsHidden {
+		definition["is_hidden"] = true
+	}
+	return definition, nil
+}
+
+// mergeIndexAliases returns body with spec.aliases added to its aliases, to create the index with. Aliases moved
+// from other indices are left out, they are moved by UpdateIndexAliases once the index exists.
+func mergeIndexAliases(body string, aliases []v1alpha1.IndexAlias) (string, error) {
+	definitions := make(map[string]interface{})
+	for _, alias := range aliases {
+		if len(alias.MoveFrom) > 0 {
+			continue
+		}
+		definition, err := IndexAliasDefinition(alias)
+		if err != nil {
+			return "", err
+		}
+		definitions[alias.Name] = definition
+	}
+	if len(definitions) == 0 {
+		return body, nil
+	}
+
+	merged := make(map[string]interface{})
+	if strings.TrimSpace(body) != "" {
+		if err := json.Unmarshal([]byte(body), &merged); err != nil {
+			return "", err
+		}
+	}
+	merged["aliases"] = mergeObjects(merged["aliases"], definitions)
+
+	marshalled, err := json.Marshal(merged)
+	if err != nil {
+		return "", err
+	}
+	return string(marshalled), nil
+}
+
+// UpdateIndexAliases brings the aliases of the index in line with spec.aliases in a single aliases request, so that
+// all changes are applied atomically. Aliases declared with moveFrom are removed from the matching indices in the
+// same request. Aliases of status.managedAliases which are neither declared in spec.aliases nor in the body are
+// removed, except for the legacy name kept as alias by MigrateIndex; aliases not managed by the resource are kept. It
+// returns whether the aliases were changed.
+func UpdateIndexAliases(esClient *elasticsearch.Client, index v1alpha1.Index) (bool, error) {
+	if len(index.Spec.Aliases) == 0 && len(index.Status.ManagedAliases) == 0 {
+		return false, nil
+	}
+	indexName := utils.RemoteName(&index)
+	current, err := getAliases(esClient, indexName, "")
+	if err != nil {
+		return false, err
+	}
+	currentAliases := current[indexName]
+
+	keep, err := bodyAliasNames(index.Spec.GetBody())
+	if err != nil {
+		return false, err
+	}
+	if legacyName, ok := utils.LegacyName(&index); ok {
+		keep[legacyName] = true
+	}
+
+	var actions []map[string]interface{}
+	for _, alias := range index.Spec.Aliases {
+		keep[alias.Name] = true
+		definition, err := IndexAliasDefinition(alias)
+		if err != nil {
+			return false, err
+		}
+
+		moved := false
+		if len(alias.MoveFrom) > 0 {
+			holders, err := getAliases(esClient, "", alias.Name)
+			if err != nil {
+				return false, err
+			}
+			for _, holder := range slices.Sorted(maps.Keys(holders)) {
+				if holder != indexName && matchesAnyPattern(holder, alias.MoveFrom) {
+					actions = append(actions, map[string]interface{}{"remove": map[string]interface{}{"index": holder, "alias": alias.Name}})
+					moved = true
+				}
+			}
+		}
+
+		currentDefinition, exists := currentAliases[alias.Name]
+		if exists && !moved && equalJSON(definition, currentDefinition) {
+			continue
+		}
+		add := map[string]interface{}{"index": indexName, "alias": alias.Name}
+		for key, value := range definition {
+			add[key] = value
+		}
+		actions = append(actions, map[string]interface{}{"add": add})
+	}
+	for _, name := range slices.Sorted(slices.Values(index.Status.ManagedAliases)) {
+		if _, exists := currentAliases[name]; exists && !keep[name] {
+			actions = append(actions, map[string]interface{}{"remove": map[string]interface{}{"index": indexName, "alias": name}})
+		}
+	}
+	if len(actions) == 0 {
+		return false, nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return false, err
+	}
+	res, err := esClient.Indices.UpdateAliases(strings.NewReader(string(body)))
+	if err != nil || res.IsError() {
+		return false, GetClientErrorOrResponseError(err, res)
+	}
+	res.Body.Close()
+	return true, nil
+}
+
+// IndexAliasNames returns the names of the aliases, as recorded in status.managedAliases
+func IndexAliasNames(aliases []v1alpha1.IndexAlias) []string {
+	var names []string
+	for _, alias := range aliases {
+		names = append(names, alias.Name)
+	}
+	return names
+}
+
+// getAliases returns the aliases with their definitions by index, of the index or of the indices the alias points
+// to. An alias which does not exist returns no indices.
+func getAliases(esClient *elasticsearch.Client, indexName string, alias string) (map[string]map[string]json.RawMessage, error) {
+	options := []func(*esapi.IndicesGetAliasRequest){}
+	if indexName != "" {
+		options = append(options, esClient.Indices.GetAlias.WithIndex(indexName))
+	}
+	if alias != "" {
+		options = append(options, esClient.Indices.GetAlias.WithName(alias))
+	}
+	res, err := esClient.Indices.GetAlias(options...)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 && indexName == "" {
+		return nil, nil
+	}
+	if res.IsError() {
+		return nil, GetClientErrorOrResponseError(nil, res)
+	}
+
+	var response map[string]struct {
+		Aliases map[string]json.RawMessage `json:"aliases"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	aliases := make(map[string]map[string]json.RawMessage, len(response))
+	for name, index := range response {
+		aliases[name] = index.Aliases
+	}
+	return aliases, nil
+}
+
+// bodyAliasNames returns the names of the aliases declared in the body of the index
+func bodyAliasNames(body string) (map[string]bool, error) {
+	names := make(map[string]bool)
+	if strings.TrimSpace(body) == "" {
+		return names, nil
+	}
+	var index struct {
+		Aliases map[string]json.RawMessage `json:"aliases"`
+	}
+	if err := json.Unmarshal([]byte(body), &index); err != nil {
+		return nil, err
+	}
+	for name := range index.Aliases {
+		names[name] = true
+	}
+	return names, nil
+}
+
+// equalJSON reports whether the definition equals the JSON returned by Elasticsearch
+func equalJSON(definition map[string]interface{}, current json.RawMessage) bool {
+	marshalled, err := json.Marshal(definition)
+	if err != nil {
+		return false
+	}
+	var want, got interface{}
+	if json.Unmarshal(marshalled, &want) != nil || json.Unmarshal(current, &got) != nil {
+		return false
+	}
+	return reflect.DeepEqual(want, got)
+}
+
+func matchesAnyPattern(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIndexAliasDefinition(t *testing.T) {
+	isWriteIndex := false
+	tests := []struct {
+		name    string
+		alias   v1alpha1.IndexAlias
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{name: "plain alias", alias: v1alpha1.IndexAlias{Name: "logs"}, want: map[string]interface{}{}},
+		{
+			name:  "routing is split into index and search routing",
+			alias: v1alpha1.IndexAlias{Name: "logs", Routing: "1", SearchRouting: "2"},
+			want:  map[string]interface{}{"index_routing": "1", "search_routing": "2"},
+		},
+		{
+			name:  "filter and flags",
+			alias: v1alpha1.IndexAlias{Name: "logs", Filter: `{"term": {"team": "a"}}`, IsWriteIndex: &isWriteIndex, IsHidden: true},
+			want: map[string]interface{}{"filter": map[string]interface{}{"term": map[string]interface{}{"team": "a"}},
+				"is_write_index": false, "is_hidden": true},
+		},
+		{name: "invalid filter", alias: v1alpha1.IndexAlias{Name: "logs", Filter: `[]`}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IndexAliasDefinition(tt.alias)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IndexAliasDefinition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("IndexAliasDefinition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexAliases_FakeElasticsearch(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	isWriteIndex := true
+	index := v1alpha1.Index{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs-v1"},
+		Spec: v1alpha1.IndexSpec{
+			Body:    `{"aliases": {"from-body": {}}}`,
+			Aliases: []v1alpha1.IndexAlias{{Name: "logs", IsWriteIndex: &isWriteIndex}, {Name: "team-a", Routing: "a"}},
+		},
+	}
+	if _, err := CreateIndex(esClient, index); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	aliases := fakeES.IndexAliases("logs-v1")
+	if len(aliases) != 3 || !strings.Contains(string(aliases["logs"]), `"is_write_index":true`) {
+		t.Fatalf("Expected the aliases to be created with the index, got %v", aliases)
+	}
+
+	// Unchanged aliases are not updated, managed ones removed from spec.aliases are removed
+	index.Status.ManagedAliases = IndexAliasNames(index.Spec.Aliases)
+	res, err := esClient.Indices.PutAlias([]string{"logs-v1"}, "manual")
+	if err != nil || res.IsError() {
+		t.Fatalf("Failed to add alias: %v", err)
+	}
+	index.Spec.Aliases = index.Spec.Aliases[:1]
+	if updated, err := UpdateIndexAliases(esClient, index); err != nil || !updated {
+		t.Fatalf("UpdateIndexAliases() = %v, %v", updated, err)
+	}
+	aliases = fakeES.IndexAliases("logs-v1")
+	if _, ok := aliases["team-a"]; ok || len(aliases) != 3 {
+		t.Errorf("Expected the removed alias to be removed and the body and unmanaged aliases to be kept, got %v", aliases)
+	}
+	index.Status.ManagedAliases = IndexAliasNames(index.Spec.Aliases)
+	if updated, err := UpdateIndexAliases(esClient, index); err != nil || updated {
+		t.Errorf("Expected in sync aliases not to be updated, got %v, %v", updated, err)
+	}
+
+	// The alias is moved from the old index to the new one in a single request
+	next := v1alpha1.Index{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs-v2"},
+		Spec: v1alpha1.IndexSpec{
+			Aliases: []v1alpha1.IndexAlias{{Name: "logs", IsWriteIndex: &isWriteIndex, MoveFrom: []string{"logs-v*"}}},
+		},
+	}
+	if _, err := CreateIndex(esClient, next); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	if target, ok := fakeES.Alias("logs"); !ok || target != "logs-v2" {
+		t.Errorf("Expected the alias to point to logs-v2, got %s", target)
+	}
+	if _, ok := fakeES.IndexAliases("logs-v1")["logs"]; ok {
+		t.Error("Expected the alias to be removed from logs-v1")
+	}
+	if count := fakeES.CountRequests("POST", "/_aliases"); count != 2 {
+		t.Errorf("Expected the move to be a single aliases request, got %d in total", count)
+	}
+	var request struct {
+		Actions []map[string]json.RawMessage `json:"actions"`
+	}
+	requests := fakeES.Requests()
+	_ = json.Unmarshal([]byte(requests[len(requests)-1].Body), &request)
+	if len(request.Actions) != 2 || request.Actions[0]["remove"] == nil || request.Actions[1]["add"] == nil {
+		t.Errorf("Expected the alias to be removed and added atomically, got %v", request.Actions)
+	}
+
+	// Removing the last alias from spec.aliases removes it from the index
+	next.Status.ManagedAliases = IndexAliasNames(next.Spec.Aliases)
+	next.Spec.Aliases = nil
+	if updated, err := UpdateIndexAliases(esClient, next); err != nil || !updated {
+		t.Fatalf("UpdateIndexAliases() = %v, %v", updated, err)
+	}
+	if _, ok := fakeES.IndexAliases("logs-v2")["logs"]; ok {
+		t.Error("Expected the last alias to be removed from logs-v2")
+	}
+}
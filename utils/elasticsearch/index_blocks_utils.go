@@ -0,0 +1,258 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"k8s.io/client-go/tools/record"
+)
+
+// Index block settings, from the most to the least restrictive
+const (
+	IndexBlockReadOnly            = "index.blocks.read_only"
+	IndexBlockReadOnlyAllowDelete = "index.blocks.read_only_allow_delete"
+	IndexBlockWrite               = "index.blocks.write"
+)
+
+var indexBlocks = [...]string{IndexBlockReadOnly, IndexBlockReadOnlyAllowDelete, IndexBlockWrite}
+
+const (
+	highDiskWatermarkSetting = "cluster.routing.allocation.disk.watermark.high"
+	defaultHighDiskWatermark = "90%"
+)
+
+// IndexBlockSettings returns the index block settings of spec.blocks. Only the most restrictive declared block is
+// set, the others are cleared.
+func IndexBlockSettings(blocks v1alpha1.IndexBlocks) map[string]bool {
+	settings := map[string]bool{IndexBlockReadOnly: false, IndexBlockReadOnlyAllowDelete: false, IndexBlockWrite: false}
+	switch {
+	case blocks.ReadOnly:
+		settings[IndexBlockReadOnly] = true
+	case blocks.ReadOnlyAllowDelete:
+		settings[IndexBlockReadOnlyAllowDelete] = true
+	case blocks.Write:
+		settings[IndexBlockWrite] = true
+	}
+	return settings
+}
+
+// mergeIndexBlockSettings returns body with the blocks of spec.blocks added to its settings, to create the index with
+func mergeIndexBlockSettings(body string, blocks v1alpha1.IndexBlocks) (string, error) {
+	merged := make(map[string]interface{})
+	if strings.TrimSpace(body) != "" {
+		if err := json.Unmarshal([]byte(body), &merged); err != nil {
+			return "", err
+		}
+	}
+	blockSettings := make(map[string]interface{})
+	for block, set := range IndexBlockSettings(blocks) {
+		if set {
+			blockSettings[block] = true
+		}
+	}
+	if len(blockSettings) == 0 {
+		return body, nil
+	}
+	merged["settings"] = mergeObjects(merged["settings"], blockSettings)
+
+	marshalled, err := json.Marshal(merged)
+	if err != nil {
+		return "", err
+	}
+	return string(marshalled), nil
+}
+
+// ApplyIndexBlocks sets the blocks of spec.blocks on the index. A read_only_allow_delete block which is not declared
+// is only cleared once the disk pressure abated, as Elasticsearch would set it again otherwise. It returns whether
+// the index metadata is read-only afterwards, so that settings and mappings can not be updated.
+func ApplyIndexBlocks(esClient *elasticsearch.Client, index v1alpha1.Index, eventRecorder record.EventRecorder) (bool, error) {
+	indexName := utils.RemoteName(&index)
+	desired := IndexBlockSettings(*index.Spec.Blocks)
+	current, err := GetIndexBlocks(esClient, indexName)
+	if err != nil {
+		return false, err
+	}
+
+	if current[IndexBlockReadOnlyAllowDelete] && !desired[IndexBlockReadOnlyAllowDelete] {
+		abated, err := DiskPressureAbated(esClient)
+		if err != nil {
+			return false, err
+		}
+		if abated {
+			eventRecorder.Event(&index, "Normal", "ReadOnlyAllowDeleteReleased",
+				fmt.Sprintf("Disk usage is below the high watermark, releasing the read_only_allow_delete block of %s", indexName))
+		} else {
+			desired[IndexBlockReadOnlyAllowDelete] = true
+			eventRecorder.Event(&index, "Warning", "DiskPressure",
+				fmt.Sprintf("Keeping the read_only_allow_delete block of %s until the disk usage is below the high watermark", indexName))
+		}
+	}
+
+	update := make(map[string]interface{})
+	for _, block := range indexBlocks {
+		if current[block] == desired[block] {
+			continue
+		}
+		// Cleared blocks are reset to their default instead of being set to false
+		update[block] = nil
+		if desired[block] {
+			update[block] = true
+		}
+	}
+	if len(update) > 0 {
+		marshalledUpdate, err := json.Marshal(update)
+		if err != nil {
+			return false, err
+		}
+		res, err := esClient.Indices.PutSettings(strings.NewReader(string(marshalledUpdate)), esClient.Indices.PutSettings.WithIndex(indexName))
+		if err != nil || res.IsError() {
+			return false, GetClientErrorOrResponseError(err, res)
+		}
+		eventRecorder.Event(&index, "Normal", "Index blocks updated", fmt.Sprintf("Index blocks successfully updated for %s", indexName))
+	}
+	return desired[IndexBlockReadOnly] || desired[IndexBlockReadOnlyAllowDelete], nil
+}
+
+// GetIndexBlocks returns the index blocks set on the index
+func GetIndexBlocks(esClient *elasticsearch.Client, indexName string) (map[string]bool, error) {
+	res, err := esClient.Indices.GetSettings(
+		esClient.Indices.GetSettings.WithIndex(indexName),
+		esClient.Indices.GetSettings.WithName("index.blocks.*"),
+		esClient.Indices.GetSettings.WithFlatSettings(true),
+	)
+	if err != nil || res.IsError() {
+		return nil, GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var response map[string]struct {
+		Settings map[string]string `json:"settings"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	blocks := make(map[string]bool, len(indexBlocks))
+	for _, block := range indexBlocks {
+		blocks[block] = response[indexName].Settings[block] == "true"
+	}
+	return blocks, nil
+}
+
+// DiskPressureAbated reports whether the disk usage of all data nodes is below the high disk watermark, the level
+// Elasticsearch releases read_only_allow_delete blocks at
+func DiskPressureAbated(esClient *elasticsearch.Client) (bool, error) {
+	watermark, err := getHighDiskWatermark(esClient)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := esClient.Cat.Allocation(esClient.Cat.Allocation.WithFormat("json"), esClient.Cat.Allocation.WithBytes("b"))
+	if err != nil || res.IsError() {
+		return false, GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var nodes []struct {
+		Node      string `json:"node"`
+		DiskUsed  string `json:"disk.used"`
+		DiskAvail string `json:"disk.avail"`
+		DiskTotal string `json:"disk.total"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&nodes); err != nil {
+		return false, err
+	}
+	for _, node := range nodes {
+		// Unassigned shards are listed without disk usage
+		if node.DiskTotal == "" {
+			continue
+		}
+		used, _ := strconv.ParseFloat(node.DiskUsed, 64)
+		avail, _ := strconv.ParseFloat(node.DiskAvail, 64)
+		total, _ := strconv.ParseFloat(node.DiskTotal, 64)
+		if watermark.exceeded(used, avail, total) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// diskWatermark is either a maximum disk usage in percent or a minimum of free bytes
+type diskWatermark struct {
+	usedPercent float64
+	freeBytes   float64
+}
+
+func (w diskWatermark) exceeded(used float64, avail float64, total float64) bool {
+	if w.freeBytes > 0 {
+		return avail < w.freeBytes
+	}
+	return total > 0 && used/total*100 >= w.usedPercent
+}
+
+func getHighDiskWatermark(esClient *elasticsearch.Client) (diskWatermark, error) {
+	res, err := esClient.Cluster.GetSettings(
+		esClient.Cluster.GetSettings.WithIncludeDefaults(true),
+		esClient.Cluster.GetSettings.WithFlatSettings(true),
+	)
+	if err != nil || res.IsError() {
+		return diskWatermark{}, GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var response struct {
+		Persistent map[string]interface{} `json:"persistent"`
+		Transient  map[string]interface{} `json:"transient"`
+		Defaults   map[string]interface{} `json:"defaults"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return diskWatermark{}, err
+	}
+	watermark := defaultHighDiskWatermark
+	for _, settings := range []map[string]interface{}{response.Defaults, response.Persistent, response.Transient} {
+		if value, ok := settings[highDiskWatermarkSetting].(string); ok && value != "" {
+			watermark = value
+		}
+	}
+	return parseDiskWatermark(watermark)
+}
+
+var byteSizeUnits = map[string]float64{
+	"b":  1,
+	"kb": 1 << 10,
+	"mb": 1 << 20,
+	"gb": 1 << 30,
+	"tb": 1 << 40,
+	"pb": 1 << 50,
+}
+
+// parseDiskWatermark parses a disk watermark setting, given as percentage (90%), ratio (0.9) or byte size of free
+// disk space (500mb)
+func parseDiskWatermark(value string) (diskWatermark, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if percent, ok := strings.CutSuffix(value, "%"); ok {
+		usedPercent, err := strconv.ParseFloat(percent, 64)
+		if err != nil {
+			return diskWatermark{}, fmt.Errorf("invalid disk watermark %q: %w", value, err)
+		}
+		return diskWatermark{usedPercent: usedPercent}, nil
+	}
+	if ratio, err := strconv.ParseFloat(value, 64); err == nil {
+		return diskWatermark{usedPercent: ratio * 100}, nil
+	}
+	number := strings.TrimRight(value, "abcdefghijklmnopqrstuvwxyz")
+	factor, ok := byteSizeUnits[value[len(number):]]
+	if !ok {
+		return diskWatermark{}, fmt.Errorf("invalid disk watermark %q", value)
+	}
+	size, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return diskWatermark{}, fmt.Errorf("invalid disk watermark %q: %w", value, err)
+	}
+	return diskWatermark{freeBytes: size * factor}, nil
+}
@@ -0,0 +1,121 @@
+package elasticsearch
+
+import (
+	"reflect"
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestIndexBlockSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		blocks v1alpha1.IndexBlocks
+		want   string
+	}{
+		{name: "no blocks", blocks: v1alpha1.IndexBlocks{}},
+		{name: "write", blocks: v1alpha1.IndexBlocks{Write: true}, want: IndexBlockWrite},
+		{name: "read only allow delete wins over write", blocks: v1alpha1.IndexBlocks{ReadOnlyAllowDelete: true, Write: true}, want: IndexBlockReadOnlyAllowDelete},
+		{name: "read only wins", blocks: v1alpha1.IndexBlocks{ReadOnly: true, ReadOnlyAllowDelete: true, Write: true}, want: IndexBlockReadOnly},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := IndexBlockSettings(tt.blocks)
+			if len(settings) != 3 {
+				t.Errorf("Expected all blocks to be managed, got %v", settings)
+			}
+			for block, set := range settings {
+				if set != (block == tt.want) {
+					t.Errorf("IndexBlockSettings() %s = %v", block, set)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDiskWatermark(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    diskWatermark
+		wantErr bool
+	}{
+		{value: "90%", want: diskWatermark{usedPercent: 90}},
+		{value: "0.85", want: diskWatermark{usedPercent: 85}},
+		{value: "500mb", want: diskWatermark{freeBytes: 500 << 20}},
+		{value: "2GB", want: diskWatermark{freeBytes: 2 << 30}},
+		{value: "lots", wantErr: true},
+		{value: "x%", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseDiskWatermark(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDiskWatermark(%s) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseDiskWatermark(%s) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestIndexBlocks_FakeElasticsearch(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+	recorder := record.NewFakeRecorder(20)
+
+	index := v1alpha1.Index{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+		Spec: v1alpha1.IndexSpec{
+			Body:   `{"settings": {"number_of_replicas": 1, "refresh_interval": "1s"}, "mappings": {}}`,
+			Blocks: &v1alpha1.IndexBlocks{Write: true},
+		},
+	}
+	if _, err := CreateIndex(esClient, index); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	if blocks := fakeES.IndexBlocks("logs"); !reflect.DeepEqual(blocks, map[string]bool{IndexBlockWrite: true}) {
+		t.Errorf("Expected the index to be created with the write block, got %v", blocks)
+	}
+
+	index.Spec.Blocks = &v1alpha1.IndexBlocks{ReadOnly: true}
+	if _, err := UpdateIndex(esClient, index, recorder); err != nil {
+		t.Fatalf("UpdateIndex() error = %v", err)
+	}
+	if blocks := fakeES.IndexBlocks("logs"); !reflect.DeepEqual(blocks, map[string]bool{IndexBlockReadOnly: true}) {
+		t.Errorf("Expected only the read_only block, got %v", blocks)
+	}
+	if fakeES.CountRequests("PUT", "/logs/_mapping") != 0 {
+		t.Error("Expected the mapping of a read-only index not to be updated")
+	}
+
+	// Elasticsearch blocks the index on disk pressure, the block is kept until the disk usage drops
+	index.Spec.Blocks = &v1alpha1.IndexBlocks{}
+	fakeES.SetIndexBlock("logs", IndexBlockReadOnlyAllowDelete, true)
+	fakeES.SetDiskUsage("node-1", 70)
+	fakeES.SetDiskUsage("node-2", 96)
+	if _, err := UpdateIndex(esClient, index, recorder); err != nil {
+		t.Fatalf("UpdateIndex() error = %v", err)
+	}
+	if blocks := fakeES.IndexBlocks("logs"); !reflect.DeepEqual(blocks, map[string]bool{IndexBlockReadOnlyAllowDelete: true}) {
+		t.Errorf("Expected the read_only_allow_delete block to be kept under disk pressure, got %v", blocks)
+	}
+
+	fakeES.SetDiskUsage("node-2", 80)
+	if _, err := UpdateIndex(esClient, index, recorder); err != nil {
+		t.Fatalf("UpdateIndex() error = %v", err)
+	}
+	if blocks := fakeES.IndexBlocks("logs"); len(blocks) != 0 {
+		t.Errorf("Expected all blocks to be released, got %v", blocks)
+	}
+	if fakeES.CountRequests("PUT", "/logs/_mapping") != 1 {
+		t.Error("Expected the mapping to be updated once the index is writable")
+	}
+}
@@ -1,12 +1,22 @@
 package elasticsearch
 
 import (
+	"context"
 	"eck-custom-resources/api/es.eck/v1alpha1"
 	"eck-custom-resources/utils"
+	"encoding/json"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 func DeleteIndexLifecyclePolicy(esClient *elasticsearch.Client, indexLifecyclePolicyName string) (ctrl.Result, error) {
@@ -20,7 +30,7 @@ func DeleteIndexLifecyclePolicy(esClient *elasticsearch.Client, indexLifecyclePo
 func UpsertIndexLifecyclePolicy(esClient *elasticsearch.Client, indexLifecyclePolicy v1alpha1.IndexLifecyclePolicy) (ctrl.Result, error) {
 	res, err := esClient.ILM.PutLifecycle(
 		indexLifecyclePolicy.Name,
-		esClient.ILM.PutLifecycle.WithBody(strings.NewReader(indexLifecyclePolicy.Spec.Body)),
+		esClient.ILM.PutLifecycle.WithBody(strings.NewReader(indexLifecyclePolicy.Spec.GetBody())),
 	)
 
 	if err != nil || res.IsError() {
@@ -29,3 +39,228 @@ func UpsertIndexLifecyclePolicy(esClient *elasticsearch.Client, indexLifecyclePo
 
 	return ctrl.Result{}, nil
 }
+
+// IndexLifecyclePolicyHasDeletePhase reports whether the body of the index lifecycle policy defines a delete phase
+func IndexLifecyclePolicyHasDeletePhase(body string) (bool, error) {
+	var parsed struct {
+		Policy struct {
+			Phases map[string]json.RawMessage `json:"phases"`
+		} `json:"policy"`
+	}
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return false, err
+	}
+	_, hasDelete := parsed.Policy.Phases["delete"]
+	return hasDelete, nil
+}
+
+// getDeployedIndexLifecyclePolicy returns the deployed index lifecycle policy in the format of a policy body, nil if it
+// does not exist
+func getDeployedIndexLifecyclePolicy(esClient *elasticsearch.Client, indexLifecyclePolicyName string) (json.RawMessage, error) {
+	res, err := esClient.ILM.GetLifecycle(esClient.ILM.GetLifecycle.WithPolicy(indexLifecyclePolicyName))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return nil, nil
+	}
+	if res.IsError() {
+		return nil, GetClientErrorOrResponseError(nil, res)
+	}
+
+	var policies map[string]json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&policies); err != nil {
+		return nil, err
+	}
+	return policies[indexLifecyclePolicyName], nil
+}
+
+// GetIndexLifecyclePolicyRollout counts the indices managed by the index lifecycle policy by whether they execute the
+// deployed version of the policy, reported by the explain lifecycle API. Indices which did not load a phase definition
+// yet execute the deployed version once they do and count as updated. It returns nil if the policy does not exist.
+func GetIndexLifecyclePolicyRollout(esClient *elasticsearch.Client, indexLifecyclePolicyName string) (*v1alpha1.IndexLifecyclePolicyRollout, error) {
+	deployed, err := getDeployedIndexLifecyclePolicy(esClient, indexLifecyclePolicyName)
+	if err != nil || deployed == nil {
+		return nil, err
+	}
+	var policy struct {
+		Version int64 `json:"version"`
+	}
+	if err := json.Unmarshal(deployed, &policy); err != nil {
+		return nil, err
+	}
+
+	// Patterns starting with a dot also match hidden indices, e.g. the backing indices of data streams
+	res, err := esClient.ILM.ExplainLifecycle("*,.*", esClient.ILM.ExplainLifecycle.WithOnlyManaged(true))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, GetClientErrorOrResponseError(nil, res)
+	}
+	var explain struct {
+		Indices map[string]struct {
+			Policy         string `json:"policy"`
+			PhaseExecution *struct {
+				Version int64 `json:"version"`
+			} `json:"phase_execution"`
+		} `json:"indices"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&explain); err != nil {
+		return nil, err
+	}
+
+	rollout := &v1alpha1.IndexLifecyclePolicyRollout{PolicyVersion: policy.Version}
+	for _, index := range explain.Indices {
+		if index.Policy != indexLifecyclePolicyName {
+			continue
+		}
+		rollout.Indices++
+		if index.PhaseExecution == nil || index.PhaseExecution.Version >= policy.Version {
+			rollout.UpdatedIndices++
+		} else {
+			rollout.OutdatedIndices++
+		}
+	}
+	return rollout, nil
+}
+
+// CheckSnapshotBeforeDelete returns why body may not be applied yet, empty if it may. A body is held back when it adds
+// a delete phase to the deployed policy and the snapshot lifecycle policy did not complete a successful snapshot within
+// the max age. Creating a policy is never held back, it is not used by any index yet.
+func CheckSnapshotBeforeDelete(esClient *elasticsearch.Client, indexLifecyclePolicyName string, body string,
+	snapshotBeforeDelete v1alpha1.SnapshotBeforeDeleteSpec, now time.Time) (string, error) {
+	if hasDelete, err := IndexLifecyclePolicyHasDeletePhase(body); err != nil || !hasDelete {
+		return "", err
+	}
+
+	deployed, err := getDeployedIndexLifecyclePolicy(esClient, indexLifecyclePolicyName)
+	if err != nil || deployed == nil {
+		return "", err
+	}
+	if hadDelete, err := IndexLifecyclePolicyHasDeletePhase(string(deployed)); err != nil || hadDelete {
+		return "", err
+	}
+
+	maxAge := snapshotBeforeDelete.MaxAge.Duration
+	if maxAge == 0 {
+		maxAge = 24 * time.Hour
+	}
+	lastSuccess, err := GetSnapshotLifecyclePolicyLastSuccess(esClient, snapshotBeforeDelete.SnapshotLifecyclePolicy)
+	if err != nil {
+		return "", err
+	}
+	if lastSuccess == nil {
+		return fmt.Sprintf("the change adds a delete phase, but snapshot lifecycle policy %s has not completed a successful snapshot yet",
+			snapshotBeforeDelete.SnapshotLifecyclePolicy), nil
+	}
+	if age := now.Sub(*lastSuccess); age > maxAge {
+		return fmt.Sprintf("the change adds a delete phase, but the last successful snapshot of snapshot lifecycle policy %s is %s old, more than %s",
+			snapshotBeforeDelete.SnapshotLifecyclePolicy, age.Round(time.Minute), maxAge), nil
+	}
+	return "", nil
+}
+
+// ILMPolicyRefIndexField indexes resources by the namespace/name key of the IndexLifecyclePolicy they reference
+const ILMPolicyRefIndexField = "spec.ilmPolicyRef"
+
+// ILMPolicyRefKey returns the namespace/name key of the referenced IndexLifecyclePolicy, namespace is the namespace of
+// the referencing resource
+func ILMPolicyRefKey(ref *v1alpha1.IndexLifecyclePolicyReference, namespace string) string {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	return types.NamespacedName{Namespace: namespace, Name: ref.Name}.String()
+}
+
+// VerifyIndexLifecyclePolicyReady returns an error unless the referenced IndexLifecyclePolicy exists and is Ready for
+// its current generation
+func VerifyIndexLifecyclePolicyReady(cli client.Client, ctx context.Context, ref *v1alpha1.IndexLifecyclePolicyReference, namespace string) error {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	var policy v1alpha1.IndexLifecyclePolicy
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, &policy); err != nil {
+		return fmt.Errorf("IndexLifecyclePolicy %s/%s: %w", namespace, ref.Name, err)
+	}
+	ready := meta.FindStatusCondition(policy.Status.Conditions, utils.ReadyConditionType)
+	if ready == nil || ready.Status != metav1.ConditionTrue || ready.ObservedGeneration != policy.Generation {
+		return fmt.Errorf("IndexLifecyclePolicy %s/%s is not Ready yet", namespace, ref.Name)
+	}
+	return nil
+}
+
+// InjectIndexLifecyclePolicy sets the index.lifecycle.name setting of the JSON body to policyName. settingsPath is the
+// path of the settings object in the body, which is created if missing.
+func InjectIndexLifecyclePolicy(body string, policyName string, settingsPath ...string) (string, error) {
+	parsed := make(map[string]interface{})
+	if strings.TrimSpace(body) != "" {
+		if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+			return "", err
+		}
+	}
+
+	settings := parsed
+	for _, key := range settingsPath {
+		child, _ := settings[key].(map[string]interface{})
+		if child == nil {
+			child = make(map[string]interface{})
+			settings[key] = child
+		}
+		settings = child
+	}
+
+	// Settings may be given flat or nested, the policy is set in the form already used to not define it twice
+	if indexSettings, ok := settings["index"].(map[string]interface{}); ok {
+		delete(settings, "index.lifecycle.name")
+		indexSettings["lifecycle.name"] = policyName
+		if lifecycle, ok := indexSettings["lifecycle"].(map[string]interface{}); ok {
+			delete(indexSettings, "lifecycle.name")
+			lifecycle["name"] = policyName
+		}
+	} else {
+		settings["index.lifecycle.name"] = policyName
+	}
+
+	injected, err := json.Marshal(parsed)
+	if err != nil {
+		return "", err
+	}
+	return string(injected), nil
+}
+
+// getIndexLifecyclePolicyName returns the index.lifecycle.name setting of settings, given flat or nested
+func getIndexLifecyclePolicyName(settings map[string]interface{}) (string, bool) {
+	if name, ok := settings["index.lifecycle.name"].(string); ok {
+		return name, true
+	}
+	indexSettings, _ := settings["index"].(map[string]interface{})
+	if name, ok := indexSettings["lifecycle.name"].(string); ok {
+		return name, true
+	}
+	lifecycle, _ := indexSettings["lifecycle"].(map[string]interface{})
+	name, ok := lifecycle["name"].(string)
+	return name, ok
+}
+
+// IndexLifecyclePolicyReadyChangedFilter passes IndexLifecyclePolicy updates which change its Ready condition or the
+// generation it was observed for, i.e. once a changed policy was applied
+func IndexLifecyclePolicyReadyChangedFilter() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPolicy, okOld := e.ObjectOld.(*v1alpha1.IndexLifecyclePolicy)
+			newPolicy, okNew := e.ObjectNew.(*v1alpha1.IndexLifecyclePolicy)
+			if !okOld || !okNew {
+				return false
+			}
+			oldReady := meta.FindStatusCondition(oldPolicy.Status.Conditions, utils.ReadyConditionType)
+			newReady := meta.FindStatusCondition(newPolicy.Status.Conditions, utils.ReadyConditionType)
+			if oldReady == nil || newReady == nil {
+				return oldReady != newReady
+			}
+			return oldReady.Status != newReady.Status || oldReady.ObservedGeneration != newReady.ObservedGeneration
+		},
+	}
+}
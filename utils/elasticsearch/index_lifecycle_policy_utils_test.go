@@ -1,14 +1,23 @@
 package elasticsearch
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+	"eck-custom-resources/utils"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 func TestDeleteIndexLifecyclePolicy(t *testing.T) {
@@ -194,3 +203,213 @@ func TestUpsertIndexLifecyclePolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestInjectIndexLifecyclePolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		settingsPath []string
+		want         string
+	}{
+		{
+			name:         "empty body",
+			body:         "",
+			settingsPath: []string{"settings"},
+			want:         `{"settings":{"index.lifecycle.name":"logs"}}`,
+		},
+		{
+			name:         "flat settings",
+			body:         `{"settings":{"number_of_replicas":1,"index.lifecycle.name":"old"}}`,
+			settingsPath: []string{"settings"},
+			want:         `{"settings":{"index.lifecycle.name":"logs","number_of_replicas":1}}`,
+		},
+		{
+			name:         "nested settings",
+			body:         `{"settings":{"index":{"lifecycle":{"name":"old","rollover_alias":"logs"}}}}`,
+			settingsPath: []string{"settings"},
+			want:         `{"settings":{"index":{"lifecycle":{"name":"logs","rollover_alias":"logs"}}}}`,
+		},
+		{
+			name:         "index template",
+			body:         `{"index_patterns":["logs-*"],"template":{"mappings":{}}}`,
+			settingsPath: []string{"template", "settings"},
+			want:         `{"index_patterns":["logs-*"],"template":{"mappings":{},"settings":{"index.lifecycle.name":"logs"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InjectIndexLifecyclePolicy(tt.body, "logs", tt.settingsPath...)
+			if err != nil {
+				t.Fatalf("InjectIndexLifecyclePolicy() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("InjectIndexLifecyclePolicy() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := InjectIndexLifecyclePolicy("{invalid", "logs", "settings"); err == nil {
+		t.Error("Expected an error for an invalid body")
+	}
+}
+
+func TestVerifyIndexLifecyclePolicyReady(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+
+	policy := func(name string, generation int64, ready *metav1.Condition) *v1alpha1.IndexLifecyclePolicy {
+		p := &v1alpha1.IndexLifecyclePolicy{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "logging", Generation: generation}}
+		if ready != nil {
+			p.Status.Conditions = []metav1.Condition{*ready}
+		}
+		return p
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		policy("ready", 2, &metav1.Condition{Type: utils.ReadyConditionType, Status: metav1.ConditionTrue, ObservedGeneration: 2}),
+		policy("outdated", 3, &metav1.Condition{Type: utils.ReadyConditionType, Status: metav1.ConditionTrue, ObservedGeneration: 2}),
+		policy("failed", 1, &metav1.Condition{Type: utils.ReadyConditionType, Status: metav1.ConditionFalse, ObservedGeneration: 1}),
+		policy("pending", 1, nil),
+	).Build()
+
+	tests := []struct {
+		name      string
+		ref       v1alpha1.IndexLifecyclePolicyReference
+		namespace string
+		wantErr   bool
+	}{
+		{name: "ready", ref: v1alpha1.IndexLifecyclePolicyReference{Name: "ready"}, namespace: "logging", wantErr: false},
+		{name: "ready in referenced namespace", ref: v1alpha1.IndexLifecyclePolicyReference{Name: "ready", Namespace: "logging"}, namespace: "default", wantErr: false},
+		{name: "changed and not applied yet", ref: v1alpha1.IndexLifecyclePolicyReference{Name: "outdated"}, namespace: "logging", wantErr: true},
+		{name: "failed", ref: v1alpha1.IndexLifecyclePolicyReference{Name: "failed"}, namespace: "logging", wantErr: true},
+		{name: "not reconciled yet", ref: v1alpha1.IndexLifecyclePolicyReference{Name: "pending"}, namespace: "logging", wantErr: true},
+		{name: "missing", ref: v1alpha1.IndexLifecyclePolicyReference{Name: "ready"}, namespace: "default", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyIndexLifecyclePolicyReady(fakeClient, context.Background(), &tt.ref, tt.namespace)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyIndexLifecyclePolicyReady() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIndexLifecyclePolicyReadyChangedFilter(t *testing.T) {
+	withReady := func(status metav1.ConditionStatus, generation int64) *v1alpha1.IndexLifecyclePolicy {
+		return &v1alpha1.IndexLifecyclePolicy{Status: v1alpha1.IndexLifecyclePolicyStatus{Conditions: []metav1.Condition{
+			{Type: utils.ReadyConditionType, Status: status, ObservedGeneration: generation},
+		}}}
+	}
+
+	tests := []struct {
+		name string
+		old  *v1alpha1.IndexLifecyclePolicy
+		new  *v1alpha1.IndexLifecyclePolicy
+		want bool
+	}{
+		{name: "became ready", old: &v1alpha1.IndexLifecyclePolicy{}, new: withReady(metav1.ConditionTrue, 1), want: true},
+		{name: "changed policy applied", old: withReady(metav1.ConditionTrue, 1), new: withReady(metav1.ConditionTrue, 2), want: true},
+		{name: "failed", old: withReady(metav1.ConditionTrue, 1), new: withReady(metav1.ConditionFalse, 1), want: true},
+		{name: "unchanged", old: withReady(metav1.ConditionTrue, 1), new: withReady(metav1.ConditionTrue, 1), want: false},
+	}
+
+	filter := IndexLifecyclePolicyReadyChangedFilter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.Update(event.UpdateEvent{ObjectOld: tt.old, ObjectNew: tt.new}); got != tt.want {
+				t.Errorf("Update() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSnapshotBeforeDelete(t *testing.T) {
+	now := time.Now()
+	withoutDelete := `{"policy": {"phases": {"hot": {"actions": {}}}}}`
+	withDelete := `{"policy": {"phases": {"hot": {"actions": {}}, "delete": {"min_age": "30d", "actions": {"delete": {}}}}}}`
+	lastSuccess := func(age time.Duration) string {
+		return fmt.Sprintf(`{"policy": {"schedule": "0 30 1 * * ?"}, "last_success": {"snapshot_name": "nightly", "time": %d}}`,
+			now.Add(-age).UnixMilli())
+	}
+
+	tests := []struct {
+		name        string
+		deployed    string
+		slmPolicy   string
+		body        string
+		wantBlocked bool
+		wantErr     bool
+	}{
+		{name: "no delete phase", deployed: withoutDelete, body: withoutDelete},
+		{name: "new policy", body: withDelete},
+		{name: "delete phase already deployed", deployed: withDelete, body: withDelete},
+		{name: "recent snapshot", deployed: withoutDelete, slmPolicy: lastSuccess(time.Hour), body: withDelete},
+		{name: "outdated snapshot", deployed: withoutDelete, slmPolicy: lastSuccess(48 * time.Hour), body: withDelete, wantBlocked: true},
+		{name: "no successful snapshot", deployed: withoutDelete, slmPolicy: `{"policy": {}}`, body: withDelete, wantBlocked: true},
+		{name: "missing snapshot lifecycle policy", deployed: withoutDelete, body: withDelete, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeElasticsearch := testutils.NewFakeElasticsearch()
+			defer fakeElasticsearch.Close()
+			esClient, err := fakeElasticsearch.Client()
+			if err != nil {
+				t.Fatalf("Failed to create ES client: %v", err)
+			}
+			if tt.deployed != "" {
+				fakeElasticsearch.Put(testutils.ESIndexLifecyclePolicy, "logs", tt.deployed)
+			}
+			if tt.slmPolicy != "" {
+				fakeElasticsearch.Put(testutils.ESSnapshotLifecyclePolicy, "nightly", tt.slmPolicy)
+			}
+
+			reason, err := CheckSnapshotBeforeDelete(esClient, "logs", tt.body, v1alpha1.SnapshotBeforeDeleteSpec{
+				SnapshotLifecyclePolicy: "nightly",
+				MaxAge:                  metav1.Duration{Duration: 24 * time.Hour},
+			}, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckSnapshotBeforeDelete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if blocked := reason != ""; blocked != tt.wantBlocked {
+				t.Errorf("CheckSnapshotBeforeDelete() reason = %q, want blocked %v", reason, tt.wantBlocked)
+			}
+			if tt.wantBlocked && !strings.Contains(reason, "nightly") {
+				t.Errorf("Expected the reason to name the snapshot lifecycle policy, got %q", reason)
+			}
+		})
+	}
+}
+
+func TestGetIndexLifecyclePolicyRollout(t *testing.T) {
+	fakeElasticsearch := testutils.NewFakeElasticsearch()
+	defer fakeElasticsearch.Close()
+	esClient, err := fakeElasticsearch.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	rollout, err := GetIndexLifecyclePolicyRollout(esClient, "logs")
+	if err != nil || rollout != nil {
+		t.Fatalf("GetIndexLifecyclePolicyRollout() of a missing policy = %v, %v, want nil", rollout, err)
+	}
+
+	policy := `{"policy": {"phases": {"hot": {"actions": {}}}}}`
+	fakeElasticsearch.Put(testutils.ESIndexLifecyclePolicy, "logs", policy)
+	fakeElasticsearch.Put(testutils.ESIndexLifecyclePolicy, "logs", policy)
+	fakeElasticsearch.SetManagedIndex("logs-000001", "logs", 1)
+	fakeElasticsearch.SetManagedIndex("logs-000002", "logs", 2)
+	fakeElasticsearch.SetManagedIndex(".ds-logs-000003", "logs", 2)
+	fakeElasticsearch.SetManagedIndex("metrics-000001", "metrics", 1)
+
+	rollout, err = GetIndexLifecyclePolicyRollout(esClient, "logs")
+	if err != nil {
+		t.Fatalf("GetIndexLifecyclePolicyRollout() error = %v", err)
+	}
+	want := v1alpha1.IndexLifecyclePolicyRollout{PolicyVersion: 2, Indices: 3, UpdatedIndices: 2, OutdatedIndices: 1}
+	if rollout == nil || *rollout != want {
+		t.Errorf("GetIndexLifecyclePolicyRollout() = %+v, want %+v", rollout, want)
+	}
+}
@@ -0,0 +1,188 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// shrinkNodeSetting requires the shards of an index to be allocated to the named node
+const shrinkNodeSetting = "index.routing.allocation.require._name"
+
+// ValidateIndexOperation returns an error describing why the spec of the operation cannot be run
+func ValidateIndexOperation(operation v1alpha1.IndexOperation) error {
+	spec := operation.Spec
+	if (spec.Operation == v1alpha1.IndexOperationShrink || spec.Operation == v1alpha1.IndexOperationSplit) && spec.NumberOfShards == nil {
+		return fmt.Errorf("spec.numberOfShards is required for %s", spec.Operation)
+	}
+	if spec.ShrinkNode != "" && spec.Operation != v1alpha1.IndexOperationShrink {
+		return fmt.Errorf("spec.shrinkNode is only supported for %s", v1alpha1.IndexOperationShrink)
+	}
+	if _, ok := spec.Settings["index.number_of_shards"]; ok {
+		return errors.New("spec.settings must not set index.number_of_shards, use spec.numberOfShards")
+	}
+	return nil
+}
+
+// GetIndexOperationBody returns the body of the resize request. The target is created without the write block and
+// the shrink node requirement taken over from the source.
+func GetIndexOperationBody(operation v1alpha1.IndexOperation) (string, error) {
+	spec := operation.Spec
+	settings := map[string]any{IndexBlockWrite: nil}
+	for setting, value := range spec.Settings {
+		settings[setting] = value
+	}
+	if spec.NumberOfShards != nil {
+		settings["index.number_of_shards"] = *spec.NumberOfShards
+	}
+	if spec.ShrinkNode != "" {
+		settings[shrinkNodeSetting] = nil
+	}
+	marshalled, err := json.Marshal(map[string]any{"settings": settings})
+	if err != nil {
+		return "", err
+	}
+	return string(marshalled), nil
+}
+
+// PrepareIndexOperationSource sets the write block on the source index, which Elasticsearch requires for resizing,
+// and moves a copy of every shard to spec.shrinkNode if set. It reports whether the source is ready to be resized.
+func PrepareIndexOperationSource(esClient *elasticsearch.Client, operation v1alpha1.IndexOperation, source string) (bool, error) {
+	settings := map[string]any{IndexBlockWrite: true}
+	if operation.Spec.ShrinkNode != "" {
+		settings[shrinkNodeSetting] = operation.Spec.ShrinkNode
+	}
+	if err := putIndexSettings(esClient, source, settings); err != nil {
+		return false, err
+	}
+	if operation.Spec.ShrinkNode == "" {
+		return true, nil
+	}
+	return shardsOnNode(esClient, source, operation.Spec.ShrinkNode)
+}
+
+// shardsOnNode reports whether a started copy of every shard of the index is allocated to the node and no shard of
+// the index is relocating
+func shardsOnNode(esClient *elasticsearch.Client, index string, node string) (bool, error) {
+	res, err := esClient.Cat.Shards(
+		esClient.Cat.Shards.WithIndex(index),
+		esClient.Cat.Shards.WithFormat("json"),
+		esClient.Cat.Shards.WithH("shard", "prirep", "state", "node"),
+	)
+	if err != nil || res.IsError() {
+		return false, GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var shards []struct {
+		Shard string `json:"shard"`
+		State string `json:"state"`
+		Node  string `json:"node"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&shards); err != nil {
+		return false, err
+	}
+	onNode := map[string]bool{}
+	for _, shard := range shards {
+		if shard.State == "RELOCATING" {
+			return false, nil
+		}
+		if _, ok := onNode[shard.Shard]; !ok {
+			onNode[shard.Shard] = false
+		}
+		if shard.State == "STARTED" && shard.Node == node {
+			onNode[shard.Shard] = true
+		}
+	}
+	for _, ok := range onNode {
+		if !ok {
+			return false, nil
+		}
+	}
+	return len(onNode) > 0, nil
+}
+
+// StartIndexOperation sends the shrink, split or clone request for the source index. rejected is true if
+// Elasticsearch refused the operation, e.g. because the target already exists or the number of shards does not fit
+// the source, as opposed to a failed request.
+func StartIndexOperation(esClient *elasticsearch.Client, operation v1alpha1.IndexOperation, source string) (rejected bool, err error) {
+	body, err := GetIndexOperationBody(operation)
+	if err != nil {
+		return true, err
+	}
+	target := operation.Spec.Target
+	var res *esapi.Response
+	switch operation.Spec.Operation {
+	case v1alpha1.IndexOperationShrink:
+		res, err = esClient.Indices.Shrink(source, target, esClient.Indices.Shrink.WithBody(strings.NewReader(body)))
+	case v1alpha1.IndexOperationSplit:
+		res, err = esClient.Indices.Split(source, target, esClient.Indices.Split.WithBody(strings.NewReader(body)))
+	case v1alpha1.IndexOperationClone:
+		res, err = esClient.Indices.Clone(source, target, esClient.Indices.Clone.WithBody(strings.NewReader(body)))
+	default:
+		return true, fmt.Errorf("unsupported operation %q", operation.Spec.Operation)
+	}
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return true, GetClientErrorOrResponseError(nil, res)
+	}
+	return false, nil
+}
+
+// IndexOperationDone reports whether all primary shards of the target index are allocated and none of its shards is
+// initializing anymore
+func IndexOperationDone(esClient *elasticsearch.Client, operation v1alpha1.IndexOperation) (bool, error) {
+	res, err := esClient.Cluster.Health(esClient.Cluster.Health.WithIndex(operation.Spec.Target))
+	if err != nil || res.IsError() {
+		return false, GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var health struct {
+		Status             string `json:"status"`
+		InitializingShards int    `json:"initializing_shards"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&health); err != nil {
+		return false, err
+	}
+	return health.Status != "red" && health.InitializingShards == 0, nil
+}
+
+// FinishIndexOperation removes the shrink node requirement from the source index and releases its write block if
+// spec.releaseWriteBlock is set
+func FinishIndexOperation(esClient *elasticsearch.Client, operation v1alpha1.IndexOperation, source string) error {
+	settings := map[string]any{}
+	if operation.Spec.ShrinkNode != "" {
+		settings[shrinkNodeSetting] = nil
+	}
+	if operation.Spec.ReleaseWriteBlock {
+		settings[IndexBlockWrite] = nil
+	}
+	if len(settings) == 0 {
+		return nil
+	}
+	return putIndexSettings(esClient, source, settings)
+}
+
+// putIndexSettings updates the settings of the index, settings set to nil are reset to their default
+func putIndexSettings(esClient *elasticsearch.Client, index string, settings map[string]any) error {
+	marshalled, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	res, err := esClient.Indices.PutSettings(strings.NewReader(string(marshalled)), esClient.Indices.PutSettings.WithIndex(index))
+	if err != nil || res.IsError() {
+		return GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+	return nil
+}
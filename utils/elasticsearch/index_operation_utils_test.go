@@ -0,0 +1,133 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+)
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+func TestValidateIndexOperation(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    v1alpha1.IndexOperationSpec
+		wantErr bool
+	}{
+		{name: "clone", spec: v1alpha1.IndexOperationSpec{Operation: v1alpha1.IndexOperationClone}},
+		{name: "shrink", spec: v1alpha1.IndexOperationSpec{Operation: v1alpha1.IndexOperationShrink, NumberOfShards: int32Ptr(1), ShrinkNode: "node-1"}},
+		{name: "split without shards", spec: v1alpha1.IndexOperationSpec{Operation: v1alpha1.IndexOperationSplit}, wantErr: true},
+		{name: "shrink node of split", spec: v1alpha1.IndexOperationSpec{Operation: v1alpha1.IndexOperationSplit, NumberOfShards: int32Ptr(4), ShrinkNode: "node-1"}, wantErr: true},
+		{name: "shards in settings", spec: v1alpha1.IndexOperationSpec{Operation: v1alpha1.IndexOperationClone, Settings: map[string]string{"index.number_of_shards": "2"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateIndexOperation(v1alpha1.IndexOperation{Spec: tt.spec})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateIndexOperation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetIndexOperationBody(t *testing.T) {
+	body, err := GetIndexOperationBody(v1alpha1.IndexOperation{Spec: v1alpha1.IndexOperationSpec{
+		Operation:      v1alpha1.IndexOperationShrink,
+		NumberOfShards: int32Ptr(1),
+		Settings:       map[string]string{"index.number_of_replicas": "1"},
+		ShrinkNode:     "node-1",
+	}})
+	if err != nil {
+		t.Fatalf("GetIndexOperationBody() error = %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"settings": map[string]any{
+		"index.blocks.write":                     nil,
+		"index.number_of_replicas":               "1",
+		"index.number_of_shards":                 float64(1),
+		"index.routing.allocation.require._name": nil,
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetIndexOperationBody() = %v, want %v", got, want)
+	}
+}
+
+func TestIndexOperation_FakeElasticsearch(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+	fakeES.Put(testutils.ESIndex, "logs", `{}`)
+	fakeES.SetDocumentCount("logs", 42)
+	operation := v1alpha1.IndexOperation{Spec: v1alpha1.IndexOperationSpec{
+		Index:             "logs",
+		Operation:         v1alpha1.IndexOperationShrink,
+		Target:            "logs-shrunk",
+		NumberOfShards:    int32Ptr(1),
+		ShrinkNode:        "node-1",
+		ReleaseWriteBlock: true,
+	}}
+
+	ready, err := PrepareIndexOperationSource(esClient, operation, "logs")
+	if err != nil || !ready {
+		t.Fatalf("PrepareIndexOperationSource() = %v, %v, want the source to be ready", ready, err)
+	}
+	if !fakeES.IndexBlocks("logs")[IndexBlockWrite] || fakeES.IndexSetting("logs", shrinkNodeSetting) != "node-1" {
+		t.Errorf("Expected the source to be write blocked and allocated to node-1, got %v", fakeES.IndexBlocks("logs"))
+	}
+
+	if rejected, err := StartIndexOperation(esClient, operation, "logs"); err != nil {
+		t.Fatalf("StartIndexOperation() = %v, %v", rejected, err)
+	}
+	if fakeES.DocumentCount("logs-shrunk") != 42 {
+		t.Errorf("Expected the target to hold the documents of the source, got %d", fakeES.DocumentCount("logs-shrunk"))
+	}
+	if fakeES.IndexBlocks("logs-shrunk")[IndexBlockWrite] || fakeES.IndexSetting("logs-shrunk", shrinkNodeSetting) != "" {
+		t.Errorf("Expected the target to be created without the write block and the shrink node")
+	}
+	if done, err := IndexOperationDone(esClient, operation); err != nil || !done {
+		t.Errorf("IndexOperationDone() = %v, %v, want the target to be allocated", done, err)
+	}
+
+	if err := FinishIndexOperation(esClient, operation, "logs"); err != nil {
+		t.Fatalf("FinishIndexOperation() error = %v", err)
+	}
+	if fakeES.IndexBlocks("logs")[IndexBlockWrite] || fakeES.IndexSetting("logs", shrinkNodeSetting) != "" {
+		t.Errorf("Expected the write block and the shrink node to be removed from the source")
+	}
+
+	rejected, err := StartIndexOperation(esClient, operation, "logs")
+	if err == nil || !rejected {
+		t.Errorf("StartIndexOperation() = %v, %v, want the existing target to be rejected", rejected, err)
+	}
+}
+
+func TestIndexOperation_RequiresWriteBlock(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+	fakeES.Put(testutils.ESIndex, "logs", `{}`)
+
+	operation := v1alpha1.IndexOperation{Spec: v1alpha1.IndexOperationSpec{Operation: v1alpha1.IndexOperationClone, Target: "logs-copy"}}
+	rejected, err := StartIndexOperation(esClient, operation, "logs")
+	if err == nil || !rejected {
+		t.Errorf("StartIndexOperation() = %v, %v, want a source without write block to be rejected", rejected, err)
+	}
+	if fakeES.Exists(testutils.ESIndex, "logs-copy") {
+		t.Error("Expected the target not to be created")
+	}
+}
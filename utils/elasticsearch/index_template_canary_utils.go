@@ -0,0 +1,226 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// CanaryIndexTemplateSuffix is appended to the name of an IndexTemplate for the template its changed version is
+// applied as during a canary
+const CanaryIndexTemplateSuffix = "-canary"
+
+// CanaryIndex returns the name of the canary index of the index template
+func CanaryIndex(indexTemplate v1alpha1.IndexTemplate) string {
+	if canary := indexTemplate.Spec.UpdatePolicy.Canary; canary != nil && canary.Index != "" {
+		return canary.Index
+	}
+	return "eck-canary-" + utils.RemoteName(&indexTemplate)
+}
+
+// RolloutIndexTemplate replaces the index template with body only once a canary of body passed:
+//  1. body is applied as <name>-canary, matching only the canary index at a priority above the one of body.
+//  2. The canary index, or data stream if body declares data_stream, is created from it and the documents of
+//     spec.updatePolicy.canary are indexed.
+//  3. The queries of spec.updatePolicy.canary are run against the canary index.
+//  4. The canary index and <name>-canary are deleted, and if all steps passed, the index template is replaced.
+//
+// A canary index left by an interrupted rollout is deleted first. Steps rejected by Elasticsearch are reported as
+// failed results; the rollout was blocked if not all results passed.
+func RolloutIndexTemplate(esClient *elasticsearch.Client, indexTemplate v1alpha1.IndexTemplate, body string) ([]v1alpha1.IndexTemplateCanaryResult, error) {
+	name := utils.RemoteName(&indexTemplate) + CanaryIndexTemplateSuffix
+	index := CanaryIndex(indexTemplate)
+	canaryBody, dataStream, err := canaryIndexTemplateBody(body, index)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := deleteCanaryIndex(esClient, index, dataStream); err != nil {
+		return nil, err
+	}
+	results, err := runIndexTemplateCanary(esClient, indexTemplate, name, index, canaryBody, dataStream)
+	if err != nil {
+		return nil, err
+	}
+	if err := deleteCanaryIndex(esClient, index, dataStream); err != nil {
+		return results, err
+	}
+	if err := deleteCanaryIndexTemplate(esClient, name); err != nil {
+		return results, err
+	}
+	if !IndexTemplateCanaryPassed(results) {
+		return results, nil
+	}
+
+	indexTemplate.Spec.Body = body
+	indexTemplate.Spec.BodyJSON = nil
+	_, err = UpsertIndexTemplate(esClient, indexTemplate)
+	return results, err
+}
+
+// IndexTemplateCanaryPassed reports whether all steps of the canary passed
+func IndexTemplateCanaryPassed(results []v1alpha1.IndexTemplateCanaryResult) bool {
+	for _, result := range results {
+		if !result.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// canaryStep is a request setting up the canary index, which the canary fails at if Elasticsearch rejects it
+type canaryStep struct {
+	name    string
+	request func() (*esapi.Response, error)
+}
+
+// runIndexTemplateCanary applies the canary template, creates the canary index and runs the queries. It stops at
+// the first setup step rejected by Elasticsearch.
+func runIndexTemplateCanary(esClient *elasticsearch.Client, indexTemplate v1alpha1.IndexTemplate, name string, index string,
+	canaryBody string, dataStream bool) ([]v1alpha1.IndexTemplateCanaryResult, error) {
+	steps := []canaryStep{
+		{"template", func() (*esapi.Response, error) {
+			return esClient.Indices.PutIndexTemplate(name, strings.NewReader(canaryBody))
+		}},
+		{"index", func() (*esapi.Response, error) {
+			if dataStream {
+				return esClient.Indices.CreateDataStream(index)
+			}
+			return esClient.Indices.Create(index)
+		}},
+	}
+	canary := indexTemplate.Spec.UpdatePolicy.Canary
+	if canary == nil {
+		canary = &v1alpha1.IndexTemplateCanary{}
+	}
+	for i, document := range canary.Documents {
+		steps = append(steps, canaryStep{fmt.Sprintf("documents[%d]", i), func() (*esapi.Response, error) {
+			return esClient.Index(index, strings.NewReader(document),
+				esClient.Index.WithOpType("create"),
+				esClient.Index.WithRefresh("true"),
+			)
+		}})
+	}
+	for _, step := range steps {
+		rejection, err := canaryRejection(step.request())
+		if err != nil {
+			return nil, err
+		}
+		if rejection != "" {
+			return []v1alpha1.IndexTemplateCanaryResult{{Name: step.name, Message: rejection}}, nil
+		}
+	}
+
+	results := make([]v1alpha1.IndexTemplateCanaryResult, 0, len(canary.Queries))
+	for _, query := range canary.Queries {
+		result, err := runIndexTemplateCanaryQuery(esClient, index, query)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// runIndexTemplateCanaryQuery runs the query against the canary index and compares its total hits
+func runIndexTemplateCanaryQuery(esClient *elasticsearch.Client, index string, query v1alpha1.IndexTemplateCanaryQuery) (v1alpha1.IndexTemplateCanaryResult, error) {
+	res, err := esClient.Search(
+		esClient.Search.WithIndex(index),
+		esClient.Search.WithBody(strings.NewReader(query.Body)),
+		esClient.Search.WithTrackTotalHits(true),
+	)
+	if err != nil {
+		return v1alpha1.IndexTemplateCanaryResult{}, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return v1alpha1.IndexTemplateCanaryResult{Name: query.Name, Message: GetClientErrorOrResponseError(nil, res).Error()}, nil
+	}
+
+	var response struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return v1alpha1.IndexTemplateCanaryResult{}, err
+	}
+	hits := response.Hits.Total.Value
+	if query.ExpectedHits != nil && hits != *query.ExpectedHits {
+		return v1alpha1.IndexTemplateCanaryResult{Name: query.Name,
+			Message: fmt.Sprintf("expected %d hits, got %d", *query.ExpectedHits, hits)}, nil
+	}
+	return v1alpha1.IndexTemplateCanaryResult{Name: query.Name, Passed: true}, nil
+}
+
+// canaryRejection returns why Elasticsearch rejected a step of the canary, and the error if the request failed
+func canaryRejection(res *esapi.Response, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 500 {
+		return "", GetClientErrorOrResponseError(nil, res)
+	}
+	if res.IsError() {
+		return GetClientErrorOrResponseError(nil, res).Error(), nil
+	}
+	return "", nil
+}
+
+// canaryIndexTemplateBody returns body matching only the canary index, at a priority above the one of body, and
+// whether body declares a data stream
+func canaryIndexTemplateBody(body string, index string) (string, bool, error) {
+	var template map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(body), &template); err != nil {
+		return "", false, err
+	}
+	var priority int64
+	if raw, ok := template["priority"]; ok {
+		if err := json.Unmarshal(raw, &priority); err != nil {
+			return "", false, fmt.Errorf("invalid priority: %w", err)
+		}
+	}
+	patterns, err := json.Marshal([]string{index})
+	if err != nil {
+		return "", false, err
+	}
+	template["index_patterns"] = patterns
+	template["priority"] = json.RawMessage(fmt.Sprint(priority + 1))
+	_, dataStream := template["data_stream"]
+
+	canaryBody, err := json.Marshal(template)
+	return string(canaryBody), dataStream, err
+}
+
+// deleteCanaryIndex deletes the canary index or data stream, a missing one is ignored
+func deleteCanaryIndex(esClient *elasticsearch.Client, index string, dataStream bool) error {
+	if dataStream {
+		return ignoreNotFound(esClient.Indices.DeleteDataStream([]string{index}))
+	}
+	return ignoreNotFound(esClient.Indices.Delete([]string{index}))
+}
+
+// deleteCanaryIndexTemplate deletes the template applied by a canary, a missing template is ignored
+func deleteCanaryIndexTemplate(esClient *elasticsearch.Client, name string) error {
+	return ignoreNotFound(esClient.Indices.DeleteIndexTemplate(name))
+}
+
+func ignoreNotFound(res *esapi.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() && res.StatusCode != 404 {
+		return GetClientErrorOrResponseError(nil, res)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCanaryIndexTemplateBody(t *testing.T) {
+	body, dataStream, err := canaryIndexTemplateBody(`{"index_patterns": ["logs-*"], "priority": 10, "data_stream": {}, "template": {}}`, "eck-canary-logs")
+	if err != nil {
+		t.Fatalf("canaryIndexTemplateBody() error = %v", err)
+	}
+	if !dataStream {
+		t.Error("Expected the data stream to be detected")
+	}
+	var template map[string]any
+	if err := json.Unmarshal([]byte(body), &template); err != nil {
+		t.Fatalf("Invalid canary body %s: %v", body, err)
+	}
+	if want := []any{"eck-canary-logs"}; !reflect.DeepEqual(template["index_patterns"], want) {
+		t.Errorf("Expected the canary index as only pattern, got %v", template["index_patterns"])
+	}
+	if template["priority"] != float64(11) {
+		t.Errorf("Expected the priority above the template, got %v", template["priority"])
+	}
+
+	if _, dataStream, _ := canaryIndexTemplateBody(`{"index_patterns": "logs-*"}`, "eck-canary-logs"); dataStream {
+		t.Error("Expected no data stream")
+	}
+	if _, _, err := canaryIndexTemplateBody(`{"priority": "high"}`, "eck-canary-logs"); err == nil {
+		t.Error("Expected an invalid priority to be rejected")
+	}
+}
+
+func TestRolloutIndexTemplate(t *testing.T) {
+	expectedHits := int64(1)
+	indexTemplate := v1alpha1.IndexTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+		Spec: v1alpha1.IndexTemplateSpec{UpdatePolicy: v1alpha1.IndexTemplateUpdatePolicy{
+			UpdateMode: v1alpha1.IndexTemplateUpdateModeCanary,
+			Canary: &v1alpha1.IndexTemplateCanary{
+				Documents: []string{`{"status": 200}`},
+				Queries:   []v1alpha1.IndexTemplateCanaryQuery{{Name: "status", Body: `{"query": {"term": {"status": 200}}}`, ExpectedHits: &expectedHits}},
+			},
+		}},
+	}
+
+	tests := []struct {
+		name         string
+		documentCode int
+		searchHits   int
+		wantResults  []v1alpha1.IndexTemplateCanaryResult
+		wantReplaced bool
+	}{
+		{
+			name:         "passed",
+			documentCode: http.StatusCreated,
+			searchHits:   1,
+			wantResults:  []v1alpha1.IndexTemplateCanaryResult{{Name: "status", Passed: true}},
+			wantReplaced: true,
+		},
+		{
+			name:         "unexpected hits",
+			documentCode: http.StatusCreated,
+			searchHits:   0,
+			wantResults:  []v1alpha1.IndexTemplateCanaryResult{{Name: "status", Message: "expected 1 hits, got 0"}},
+		},
+		{
+			name:         "document rejected",
+			documentCode: http.StatusBadRequest,
+			wantResults: []v1alpha1.IndexTemplateCanaryResult{{Name: "documents[0]",
+				Message: `error(status: 400, response: [400 Bad Request] {"error": {"type": "document_parsing_exception"}})`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			var canaryTemplate string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				w.Header().Set("X-Elastic-Product", "Elasticsearch")
+				switch r.Method + " " + r.URL.Path {
+				case "PUT /_index_template/logs-canary":
+					body, _ := io.ReadAll(r.Body)
+					canaryTemplate = string(body)
+					w.Write([]byte(`{"acknowledged": true}`))
+				case "PUT /eck-canary-logs", "PUT /_index_template/logs":
+					w.Write([]byte(`{"acknowledged": true}`))
+				case "PUT /eck-canary-logs/_doc", "POST /eck-canary-logs/_doc":
+					if r.URL.Query().Get("op_type") != "create" || r.URL.Query().Get("refresh") != "true" {
+						t.Errorf("Expected the document to be created and refreshed, got %s", r.URL.RawQuery)
+					}
+					w.WriteHeader(tt.documentCode)
+					w.Write([]byte(`{"error": {"type": "document_parsing_exception"}}`))
+				case "POST /eck-canary-logs/_search", "GET /eck-canary-logs/_search":
+					json.NewEncoder(w).Encode(map[string]any{"hits": map[string]any{"total": map[string]any{"value": tt.searchHits}}})
+				case "DELETE /eck-canary-logs", "DELETE /_index_template/logs-canary":
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(`{}`))
+				default:
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			esClient, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{server.URL}})
+			if err != nil {
+				t.Fatalf("Failed to create ES client: %v", err)
+			}
+
+			results, err := RolloutIndexTemplate(esClient, indexTemplate, `{"index_patterns": ["logs-*"], "priority": 5}`)
+			if err != nil {
+				t.Fatalf("RolloutIndexTemplate() error = %v", err)
+			}
+			if !reflect.DeepEqual(results, tt.wantResults) {
+				t.Errorf("RolloutIndexTemplate() = %v, want %v", results, tt.wantResults)
+			}
+			if canaryTemplate != `{"index_patterns":["eck-canary-logs"],"priority":6}` {
+				t.Errorf("Unexpected canary template %s", canaryTemplate)
+			}
+			replaced := false
+			for _, request := range requests {
+				replaced = replaced || request == "PUT /_index_template/logs"
+			}
+			if replaced != tt.wantReplaced {
+				t.Errorf("Expected the template to be replaced: %v, requests %v", tt.wantReplaced, requests)
+			}
+			if last := requests[len(requests)-1]; !tt.wantReplaced && last != "DELETE /_index_template/logs-canary" {
+				t.Errorf("Expected the canary template to be deleted last, got %v", requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Conflict condition of IndexTemplates, set while another IndexTemplate targeting the same instance has overlapping
+// index patterns and the same priority. Elasticsearch rejects the later of two such templates.
+const (
+	IndexTemplateConflictConditionType = "Conflict"
+	IndexTemplateConflictReason        = "OverlappingIndexPatterns"
+)
+
+// IndexTemplatePriority returns the priority of the index template body, 0 if it is not set as Elasticsearch does
+func IndexTemplatePriority(body string) (int64, error) {
+	var template struct {
+		Priority *int64 `json:"priority"`
+	}
+	if err := json.Unmarshal([]byte(body), &template); err != nil {
+		return 0, err
+	}
+	if template.Priority == nil {
+		return 0, nil
+	}
+	return *template.Priority, nil
+}
+
+// IndexPatternsOverlap reports whether an index name exists which matches both wildcard patterns
+func IndexPatternsOverlap(a string, b string) bool {
+	memo := make(map[[2]int]bool)
+	var overlap func(i, j int) bool
+	overlap = func(i, j int) bool {
+		key := [2]int{i, j}
+		if result, ok := memo[key]; ok {
+			return result
+		}
+		result := false
+		switch {
+		case i == len(a) && j == len(b):
+			result = true
+		case i < len(a) && a[i] == '*':
+			// The wildcard of a matches nothing more, or the next character of b
+			result = overlap(i+1, j) || (j < len(b) && overlap(i, j+1))
+		case j < len(b) && b[j] == '*':
+			result = overlap(i, j+1) || (i < len(a) && overlap(i+1, j))
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			result = overlap(i+1, j+1)
+		}
+		memo[key] = result
+		return result
+	}
+	return overlap(0, 0)
+}
+
+// FindIndexTemplateConflicts returns the namespace/name of the other IndexTemplates targeting the same instance as
+// the index template whose index patterns overlap with its own at the same priority. Templates with bodies which
+// cannot be parsed are skipped.
+func FindIndexTemplateConflicts(indexTemplate v1alpha1.IndexTemplate, others []v1alpha1.IndexTemplate) []string {
+	patterns, priority, err := indexTemplatePatternsAndPriority(indexTemplate)
+	if err != nil {
+		return nil
+	}
+	var conflicts []string
+	for _, other := range others {
+		if other.Namespace == indexTemplate.Namespace && other.Name == indexTemplate.Name {
+			continue
+		}
+		if !other.DeletionTimestamp.IsZero() || !sameTargetInstance(indexTemplate, other) {
+			continue
+		}
+		otherPatterns, otherPriority, err := indexTemplatePatternsAndPriority(other)
+		if err != nil || otherPriority != priority {
+			continue
+		}
+		if anyPatternsOverlap(patterns, otherPatterns) {
+			conflicts = append(conflicts, other.Namespace+"/"+other.Name)
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+// SetIndexTemplateConflictCondition sets the Conflict condition if there are conflicts and removes it otherwise. It
+// reports whether the conflicting templates changed.
+func SetIndexTemplateConflictCondition(conditions *[]metav1.Condition, generation int64, conflicts []string) bool {
+	previous := meta.FindStatusCondition(*conditions, IndexTemplateConflictConditionType)
+	if len(conflicts) == 0 {
+		return meta.RemoveStatusCondition(conditions, IndexTemplateConflictConditionType)
+	}
+	message := fmt.Sprintf("Index patterns overlap at the same priority with IndexTemplate %s", strings.Join(conflicts, ", "))
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               IndexTemplateConflictConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             IndexTemplateConflictReason,
+		Message:            message,
+	})
+	return previous == nil || previous.Message != message
+}
+
+func indexTemplatePatternsAndPriority(indexTemplate v1alpha1.IndexTemplate) ([]string, int64, error) {
+	body := indexTemplate.Spec.GetBody()
+	patterns, err := IndexTemplatePatterns(body)
+	if err != nil {
+		return nil, 0, err
+	}
+	priority, err := IndexTemplatePriority(body)
+	return patterns, priority, err
+}
+
+func anyPatternsOverlap(patterns []string, otherPatterns []string) bool {
+	for _, pattern := range patterns {
+		for _, otherPattern := range otherPatterns {
+			if IndexPatternsOverlap(pattern, otherPattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameTargetInstance reports whether both index templates declare the same target instance. Instances selected by
+// labels are only considered the same for equal selectors.
+func sameTargetInstance(a v1alpha1.IndexTemplate, b v1alpha1.IndexTemplate) bool {
+	return sameTargetConfig(a.Namespace, a.Spec.TargetConfig, b.Namespace, b.Spec.TargetConfig)
+}
+
+// sameTargetConfig reports whether the target configs of resources of the given namespaces declare the same instance
+func sameTargetConfig(aNamespace string, aConfig v1alpha1.CommonElasticsearchConfig, bNamespace string, bConfig v1alpha1.CommonElasticsearchConfig) bool {
+	targetNamespace := func(namespace string, config v1alpha1.CommonElasticsearchConfig) string {
+		if config.IsClusterInstance() {
+			return ""
+		}
+		if config.ElasticsearchInstanceNamespace != "" {
+			return config.ElasticsearchInstanceNamespace
+		}
+		return namespace
+	}
+	if aConfig.ElasticsearchInstance != "" || bConfig.ElasticsearchInstance != "" {
+		return aConfig.ElasticsearchInstance == bConfig.ElasticsearchInstance && aConfig.IsClusterInstance() == bConfig.IsClusterInstance() &&
+			targetNamespace(aNamespace, aConfig) == targetNamespace(bNamespace, bConfig)
+	}
+	if aConfig.Selector != nil || bConfig.Selector != nil {
+		return reflect.DeepEqual(aConfig.Selector, bConfig.Selector) && aConfig.IsClusterInstance() == bConfig.IsClusterInstance() &&
+			targetNamespace(aNamespace, aConfig) == targetNamespace(bNamespace, bConfig)
+	}
+	// Both target the instance of the operator configuration
+	return true
+}
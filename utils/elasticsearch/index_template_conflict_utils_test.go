@@ -0,0 +1,101 @@
+package elasticsearch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIndexPatternsOverlap(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want bool
+	}{
+		{a: "logs-*", b: "logs-*", want: true},
+		{a: "logs-*", b: "logs-nginx-*", want: true},
+		{a: "logs-*", b: "*-nginx", want: true},
+		{a: "logs-*", b: "metrics-*", want: false},
+		{a: "logs-2024", b: "logs-2024", want: true},
+		{a: "logs-2024", b: "logs-2025", want: false},
+		{a: "*", b: "anything", want: true},
+		{a: "a*c", b: "ab*", want: true},
+		{a: "a*c", b: "*b", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if got := IndexPatternsOverlap(tt.a, tt.b); got != tt.want {
+				t.Errorf("IndexPatternsOverlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if got := IndexPatternsOverlap(tt.b, tt.a); got != tt.want {
+				t.Errorf("IndexPatternsOverlap(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexTemplatePriority(t *testing.T) {
+	if priority, err := IndexTemplatePriority(`{"index_patterns": ["logs-*"]}`); err != nil || priority != 0 {
+		t.Errorf("IndexTemplatePriority() = %d, %v, want 0", priority, err)
+	}
+	if priority, err := IndexTemplatePriority(`{"index_patterns": ["logs-*"], "priority": 200}`); err != nil || priority != 200 {
+		t.Errorf("IndexTemplatePriority() = %d, %v, want 200", priority, err)
+	}
+	if _, err := IndexTemplatePriority(`not json`); err == nil {
+		t.Error("Expected an error for an invalid body")
+	}
+}
+
+func TestFindIndexTemplateConflicts(t *testing.T) {
+	newIndexTemplate := func(namespace string, name string, instance string, body string) v1alpha1.IndexTemplate {
+		return v1alpha1.IndexTemplate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: v1alpha1.IndexTemplateSpec{
+				TargetConfig: v1alpha1.CommonElasticsearchConfig{ElasticsearchInstance: instance},
+				Body:         body,
+			},
+		}
+	}
+	logs := newIndexTemplate("default", "logs", "", `{"index_patterns": ["logs-*"], "priority": 100}`)
+	others := []v1alpha1.IndexTemplate{
+		logs,
+		newIndexTemplate("default", "nginx", "", `{"index_patterns": ["logs-nginx-*"], "priority": 100}`),
+		newIndexTemplate("team", "app-logs", "", `{"index_patterns": "*-app", "priority": 100}`),
+		newIndexTemplate("default", "nginx-override", "", `{"index_patterns": ["logs-nginx-*"], "priority": 200}`),
+		newIndexTemplate("default", "metrics", "", `{"index_patterns": ["metrics-*"], "priority": 100}`),
+		newIndexTemplate("default", "other-instance", "staging", `{"index_patterns": ["logs-*"], "priority": 100}`),
+		newIndexTemplate("default", "broken", "", `{"index_patterns": `),
+	}
+
+	conflicts := FindIndexTemplateConflicts(logs, others)
+	if want := []string{"default/nginx", "team/app-logs"}; !reflect.DeepEqual(conflicts, want) {
+		t.Errorf("FindIndexTemplateConflicts() = %v, want %v", conflicts, want)
+	}
+}
+
+func TestSetIndexTemplateConflictCondition(t *testing.T) {
+	var conditions []metav1.Condition
+
+	if !SetIndexTemplateConflictCondition(&conditions, 1, []string{"default/nginx"}) {
+		t.Error("Expected a new conflict to be reported as changed")
+	}
+	condition := meta.FindStatusCondition(conditions, IndexTemplateConflictConditionType)
+	if condition == nil || condition.Status != metav1.ConditionTrue || !strings.Contains(condition.Message, "default/nginx") {
+		t.Fatalf("Expected the Conflict condition naming the other IndexTemplate, got %v", condition)
+	}
+	if SetIndexTemplateConflictCondition(&conditions, 2, []string{"default/nginx"}) {
+		t.Error("Expected the same conflict not to be reported as changed")
+	}
+	if !SetIndexTemplateConflictCondition(&conditions, 2, nil) {
+		t.Error("Expected the resolved conflict to be reported as changed")
+	}
+	if meta.FindStatusCondition(conditions, IndexTemplateConflictConditionType) != nil {
+		t.Error("Expected the Conflict condition to be removed")
+	}
+}
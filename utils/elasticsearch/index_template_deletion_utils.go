@@ -0,0 +1,100 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+// GetIndexTemplateDeletionImpact returns what deleting the index template of the given name and index patterns
+// affects: the indices and data streams matching the patterns, and the patterns which no other index template in
+// Elasticsearch matches. A pattern is matched by another template if every name matching it also matches one of the
+// patterns of the other template.
+func GetIndexTemplateDeletionImpact(esClient *elasticsearch.Client, name string, patterns []string) (v1alpha1.IndexTemplateDeletionImpact, error) {
+	impact := v1alpha1.IndexTemplateDeletionImpact{}
+
+	matching, err := countMatchingIndices(esClient, patterns)
+	if err != nil {
+		return impact, err
+	}
+	impact.MatchingIndices = matching
+
+	others, err := otherIndexTemplatePatterns(esClient, name)
+	if err != nil {
+		return impact, err
+	}
+	for _, pattern := range patterns {
+		// Treating the pattern as a name, a wildcard of the other pattern also matches its wildcards
+		if !matchesAnyPattern(pattern, others) {
+			impact.UncoveredPatterns = append(impact.UncoveredPatterns, pattern)
+		}
+	}
+	return impact, nil
+}
+
+// FormatIndexTemplateDeletionImpact describes the impact for events
+func FormatIndexTemplateDeletionImpact(impact v1alpha1.IndexTemplateDeletionImpact) string {
+	message := fmt.Sprintf("%d existing indices and data streams match the index patterns", impact.MatchingIndices)
+	if len(impact.UncoveredPatterns) == 0 {
+		return message + ", other index templates match all of them"
+	}
+	return fmt.Sprintf("%s, new indices of %s get no mappings from another index template", message, strings.Join(impact.UncoveredPatterns, ", "))
+}
+
+// countMatchingIndices returns the number of open and closed indices and data streams matching the patterns
+func countMatchingIndices(esClient *elasticsearch.Client, patterns []string) (int32, error) {
+	res, err := esClient.Indices.ResolveIndex(patterns,
+		esClient.Indices.ResolveIndex.WithExpandWildcards("open,closed"),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return 0, fmt.Errorf("error response from ResolveIndex: %s", res.String())
+	}
+
+	var response struct {
+		Indices     []json.RawMessage `json:"indices"`
+		DataStreams []json.RawMessage `json:"data_streams"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return 0, err
+	}
+	return int32(len(response.Indices) + len(response.DataStreams)), nil
+}
+
+// otherIndexTemplatePatterns returns the index patterns of the index templates in Elasticsearch other than name
+func otherIndexTemplatePatterns(esClient *elasticsearch.Client, name string) ([]string, error) {
+	res, err := esClient.Indices.GetIndexTemplate()
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("error response from GetIndexTemplate: %s", res.String())
+	}
+
+	var response struct {
+		IndexTemplates []struct {
+			Name          string `json:"name"`
+			IndexTemplate struct {
+				IndexPatterns []string `json:"index_patterns"`
+			} `json:"index_template"`
+		} `json:"index_templates"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	var patterns []string
+	for _, indexTemplate := range response.IndexTemplates {
+		if indexTemplate.Name != name {
+			patterns = append(patterns, indexTemplate.IndexTemplate.IndexPatterns...)
+		}
+	}
+	return patterns, nil
+}
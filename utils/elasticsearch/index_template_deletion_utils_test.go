@@ -0,0 +1,56 @@
+package elasticsearch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+)
+
+func TestGetIndexTemplateDeletionImpact_FakeElasticsearch(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	fakeES.Put(testutils.ESIndexTemplate, "logs", `{"index_patterns": ["logs-*", "audit-*"]}`)
+	fakeES.Put(testutils.ESIndexTemplate, "all-logs", `{"index_patterns": ["logs*"], "priority": 0}`)
+	fakeES.Put(testutils.ESIndex, "logs-2024.01", `{}`)
+	fakeES.Put(testutils.ESIndex, "logs-2024.02", `{}`)
+	fakeES.Put(testutils.ESIndex, "audit-2024.01", `{}`)
+	fakeES.Put(testutils.ESIndex, "metrics-2024.01", `{}`)
+
+	impact, err := GetIndexTemplateDeletionImpact(esClient, "logs", []string{"logs-*", "audit-*"})
+	if err != nil {
+		t.Fatalf("GetIndexTemplateDeletionImpact() error = %v", err)
+	}
+	want := v1alpha1.IndexTemplateDeletionImpact{MatchingIndices: 3, UncoveredPatterns: []string{"audit-*"}}
+	if !reflect.DeepEqual(impact, want) {
+		t.Errorf("GetIndexTemplateDeletionImpact() = %+v, want %+v", impact, want)
+	}
+
+	// The template itself does not cover its patterns
+	impact, err = GetIndexTemplateDeletionImpact(esClient, "all-logs", []string{"logs*"})
+	if err != nil {
+		t.Fatalf("GetIndexTemplateDeletionImpact() error = %v", err)
+	}
+	want = v1alpha1.IndexTemplateDeletionImpact{MatchingIndices: 2, UncoveredPatterns: []string{"logs*"}}
+	if !reflect.DeepEqual(impact, want) {
+		t.Errorf("GetIndexTemplateDeletionImpact() = %+v, want %+v", impact, want)
+	}
+}
+
+func TestFormatIndexTemplateDeletionImpact(t *testing.T) {
+	message := FormatIndexTemplateDeletionImpact(v1alpha1.IndexTemplateDeletionImpact{MatchingIndices: 3, UncoveredPatterns: []string{"audit-*", "app-*"}})
+	if !strings.HasPrefix(message, "3 existing indices") || !strings.Contains(message, "audit-*, app-*") {
+		t.Errorf("Unexpected message %q", message)
+	}
+	message = FormatIndexTemplateDeletionImpact(v1alpha1.IndexTemplateDeletionImpact{})
+	if !strings.Contains(message, "other index templates match all of them") {
+		t.Errorf("Unexpected message %q", message)
+	}
+}
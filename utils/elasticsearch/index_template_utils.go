@@ -1,6 +1,8 @@
 package elasticsearch
 
 import (
+	"encoding/json"
+	"fmt"
 	"strings"
 
 	"eck-custom-resources/api/es.eck/v1alpha1"
@@ -19,12 +21,17 @@ func DeleteIndexTemplate(esClient *elasticsearch.Client, indexTemplateName strin
 }
 
 func UpsertIndexTemplate(esClient *elasticsearch.Client, indexTemplate v1alpha1.IndexTemplate) (ctrl.Result, error) {
-	res, err := esClient.Indices.PutIndexTemplate(indexTemplate.Name, strings.NewReader(indexTemplate.Spec.Body))
+	res, err := esClient.Indices.PutIndexTemplate(utils.RemoteName(&indexTemplate), strings.NewReader(indexTemplate.Spec.GetBody()))
 
 	if err != nil || res.IsError() {
 		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
 	}
 
+	if legacyName, ok := utils.LegacyName(&indexTemplate); ok {
+		if err := deleteLegacyObject(esClient.Indices.DeleteIndexTemplate(legacyName)); err != nil {
+			return utils.GetRequeueResult(), err
+		}
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -43,3 +50,29 @@ func IndexTemplateExists(esClient *elasticsearch.Client, indexTemplateName strin
 
 	return false, GetClientErrorOrResponseError(nil, res)
 }
+
+// IndexTemplatePatterns returns the index_patterns of the index template body, which are given as a single pattern or
+// as a list
+func IndexTemplatePatterns(body string) ([]string, error) {
+	var template struct {
+		IndexPatterns json.RawMessage `json:"index_patterns"`
+	}
+	if err := json.Unmarshal([]byte(body), &template); err != nil {
+		return nil, err
+	}
+	if len(template.IndexPatterns) == 0 {
+		return nil, fmt.Errorf("index template has no index_patterns")
+	}
+	var pattern string
+	if err := json.Unmarshal(template.IndexPatterns, &pattern); err == nil {
+		return []string{pattern}, nil
+	}
+	var patterns []string
+	if err := json.Unmarshal(template.IndexPatterns, &patterns); err != nil {
+		return nil, fmt.Errorf("invalid index_patterns: %w", err)
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("index template has no index_patterns")
+	}
+	return patterns, nil
+}
@@ -3,9 +3,11 @@ package elasticsearch
 import (
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -263,3 +265,64 @@ func TestIndexTemplateExists(t *testing.T) {
 		})
 	}
 }
+
+func TestIndexTemplateLifecycle_FakeElasticsearch(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	indexTemplate := v1alpha1.IndexTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+		Spec:       v1alpha1.IndexTemplateSpec{Body: `{"index_patterns": ["logs-*"]}`},
+	}
+	if exists, err := IndexTemplateExists(esClient, "logs"); err != nil || exists {
+		t.Fatalf("IndexTemplateExists() = %v, %v, want false", exists, err)
+	}
+	if _, err := UpsertIndexTemplate(esClient, indexTemplate); err != nil {
+		t.Fatalf("UpsertIndexTemplate() error = %v", err)
+	}
+	if exists, err := IndexTemplateExists(esClient, "logs"); err != nil || !exists {
+		t.Fatalf("IndexTemplateExists() = %v, %v, want true", exists, err)
+	}
+
+	fakeES.FailRequests(http.MethodPut, "/_index_template/logs", http.StatusBadRequest, `{"error": "invalid template"}`)
+	if _, err := UpsertIndexTemplate(esClient, indexTemplate); err == nil {
+		t.Error("Expected an error response to be returned")
+	}
+	fakeES.ClearFailures()
+
+	if _, err := DeleteIndexTemplate(esClient, "logs"); err != nil {
+		t.Fatalf("DeleteIndexTemplate() error = %v", err)
+	}
+	if fakeES.Exists(testutils.ESIndexTemplate, "logs") {
+		t.Error("Expected the index template to be deleted")
+	}
+}
+
+func TestIndexTemplatePatterns(t *testing.T) {
+	tests := []struct {
+		body    string
+		want    []string
+		wantErr bool
+	}{
+		{body: `{"index_patterns": ["logs-*", "audit-*"]}`, want: []string{"logs-*", "audit-*"}},
+		{body: `{"index_patterns": "logs-*"}`, want: []string{"logs-*"}},
+		{body: `{"index_patterns": []}`, wantErr: true},
+		{body: `{"template": {}}`, wantErr: true},
+		{body: `{"index_patterns": 1}`, wantErr: true},
+		{body: `not json`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := IndexTemplatePatterns(tt.body)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("IndexTemplatePatterns(%s) error = %v, wantErr %v", tt.body, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("IndexTemplatePatterns(%s) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"eck-custom-resources/utils"
 	"encoding/json"
 	"fmt"
+	"path"
+	"slices"
 	"strings"
 
 	"github.com/elastic/go-elasticsearch/v8"
@@ -18,6 +20,31 @@ var UpdatableSettings = [...]string{
 	"refresh_interval",
 }
 
+// ProtectedIndexPatterns match hidden and system indices which are only managed when the Index sets spec.force
+var ProtectedIndexPatterns = [...]string{
+	".*",
+	"ilm-history-*",
+	"slm-history-*",
+}
+
+// IsProtectedIndex reports whether indexName is a hidden or system index matched by ProtectedIndexPatterns
+func IsProtectedIndex(indexName string) bool {
+	for _, pattern := range ProtectedIndexPatterns {
+		if matched, _ := path.Match(pattern, indexName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyIndexNotProtected returns an error if the index is protected and managing it was not forced
+func VerifyIndexNotProtected(indexName string, force bool) error {
+	if force || !IsProtectedIndex(indexName) {
+		return nil
+	}
+	return fmt.Errorf("index %s is a hidden or system index, set spec.force to manage it anyway", indexName)
+}
+
 func VerifyIndexExists(esClient *elasticsearch.Client, indexName string) (bool, error) {
 	existsResponse, err := esClient.Indices.Exists([]string{indexName})
 	if err != nil {
@@ -91,20 +118,51 @@ func DeleteIndex(esClient *elasticsearch.Client, indexName string) (ctrl.Result,
 }
 
 func CreateIndex(esClient *elasticsearch.Client, index v1alpha1.Index) (ctrl.Result, error) {
-	res, err := esClient.Indices.Create(index.Name,
-		esClient.Indices.Create.WithBody(strings.NewReader(index.Spec.Body)),
+	body, err := MergeMappingHelpers(index)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if index.Spec.Blocks != nil {
+		if body, err = mergeIndexBlockSettings(body, *index.Spec.Blocks); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if body, err = mergeIndexAliases(body, index.Spec.Aliases); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	res, err := esClient.Indices.Create(utils.RemoteName(&index),
+		esClient.Indices.Create.WithBody(strings.NewReader(body)),
 	)
 
 	if err != nil || res.IsError() {
 		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
 	}
 
+	// Aliases moved from other indices are only added once the index exists
+	if _, err := UpdateIndexAliases(esClient, index); err != nil {
+		return utils.GetRequeueResult(), err
+	}
+
 	return ctrl.Result{}, nil
 }
 
 func UpdateIndex(esClient *elasticsearch.Client, index v1alpha1.Index, eventRecorder record.EventRecorder) (ctrl.Result, error) {
+	// Blocks are applied first, so that released blocks do not fail the settings and mapping updates
+	if index.Spec.Blocks != nil {
+		readOnly, err := ApplyIndexBlocks(esClient, index, eventRecorder)
+		if err != nil {
+			return utils.GetRequeueResult(), err
+		}
+		if readOnly {
+			eventRecorder.Event(&index, "Normal", "Index read-only",
+				fmt.Sprintf("Index %s is read-only, settings and mapping are not updated", utils.RemoteName(&index)))
+			return ctrl.Result{}, nil
+		}
+	}
+
 	var updatedBody map[string]interface{}
-	err := json.NewDecoder(strings.NewReader(index.Spec.Body)).Decode(&updatedBody)
+	err := json.NewDecoder(strings.NewReader(index.Spec.GetBody())).Decode(&updatedBody)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -113,6 +171,10 @@ func UpdateIndex(esClient *elasticsearch.Client, index v1alpha1.Index, eventReco
 	for _, updatable := range UpdatableSettings {
 		whitelistedUpdatedBody[updatable] = updatedBody["settings"].(map[string]interface{})[updatable]
 	}
+	// The lifecycle policy is only updated when set, so policies attached outside of the operator are kept
+	if policyName, ok := getIndexLifecyclePolicyName(updatedBody["settings"].(map[string]interface{})); ok {
+		whitelistedUpdatedBody["index.lifecycle.name"] = policyName
+	}
 
 	marshalledSettings, err := json.Marshal(whitelistedUpdatedBody)
 	if err != nil {
@@ -120,25 +182,199 @@ func UpdateIndex(esClient *elasticsearch.Client, index v1alpha1.Index, eventReco
 	}
 	settingsRes, settingsErr := esClient.Indices.PutSettings(
 		strings.NewReader(string(marshalledSettings)),
-		esClient.Indices.PutSettings.WithIndex(index.Name),
+		esClient.Indices.PutSettings.WithIndex(utils.RemoteName(&index)),
 	)
 	if settingsErr != nil || settingsRes.IsError() {
 		return utils.GetRequeueResult(), GetClientErrorOrResponseError(settingsErr, settingsRes)
 	}
-	eventRecorder.Event(&index, "Normal", "Index settings updated", fmt.Sprintf("Index settings successfully updated for %s", index.Name))
+	eventRecorder.Event(&index, "Normal", "Index settings updated", fmt.Sprintf("Index settings successfully updated for %s", utils.RemoteName(&index)))
+
+	aliasesUpdated, err := UpdateIndexAliases(esClient, index)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	if aliasesUpdated {
+		eventRecorder.Event(&index, "Normal", "Index aliases updated", fmt.Sprintf("Index aliases successfully updated for %s", utils.RemoteName(&index)))
+	}
+
+	// Runtime fields and aliases are applied first, they can be changed even when the static mappings can not
+	if res, err := UpdateMappingHelpers(esClient, index); err != nil {
+		return res, err
+	}
 
 	marshalledMapping, err := json.Marshal(updatedBody["mappings"])
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 	mappingRes, mappingErr := esClient.Indices.PutMapping(
-		[]string{index.Name},
+		[]string{utils.RemoteName(&index)},
 		strings.NewReader(string(marshalledMapping)),
 	)
 	if mappingErr != nil || mappingRes.IsError() {
 		return utils.GetRequeueResult(), GetClientErrorOrResponseError(mappingErr, mappingRes)
 	}
-	eventRecorder.Event(&index, "Normal", "Index mapping updated", fmt.Sprintf("Index mapping successfully updated for %s", index.Name))
+	eventRecorder.Event(&index, "Normal", "Index mapping updated", fmt.Sprintf("Index mapping successfully updated for %s", utils.RemoteName(&index)))
 
 	return ctrl.Result{}, nil
 }
+
+// parseRuntimeMappings parses spec.runtimeMappings, returning nil if it is not set
+func parseRuntimeMappings(index v1alpha1.Index) (map[string]interface{}, error) {
+	if index.Spec.RuntimeMappings == "" {
+		return nil, nil
+	}
+	var runtimeMappings map[string]interface{}
+	if err := json.Unmarshal([]byte(index.Spec.RuntimeMappings), &runtimeMappings); err != nil {
+		return nil, fmt.Errorf("spec.runtimeMappings is not a JSON object: %w", err)
+	}
+	return runtimeMappings, nil
+}
+
+// fieldAliasProperties returns the mapping properties of spec.fieldAliases
+func fieldAliasProperties(index v1alpha1.Index) map[string]interface{} {
+	properties := make(map[string]interface{}, len(index.Spec.FieldAliases))
+	for alias, fieldPath := range index.Spec.FieldAliases {
+		properties[alias] = map[string]interface{}{"type": "alias", "path": fieldPath}
+	}
+	return properties
+}
+
+// MergeMappingHelpers returns spec.body with spec.runtimeMappings and spec.fieldAliases added to its mappings
+func MergeMappingHelpers(index v1alpha1.Index) (string, error) {
+	runtimeMappings, err := parseRuntimeMappings(index)
+	if err != nil {
+		return "", err
+	}
+	if runtimeMappings == nil && len(index.Spec.FieldAliases) == 0 {
+		return index.Spec.GetBody(), nil
+	}
+
+	body := make(map[string]interface{})
+	if strings.TrimSpace(index.Spec.GetBody()) != "" {
+		if err := json.Unmarshal([]byte(index.Spec.GetBody()), &body); err != nil {
+			return "", err
+		}
+	}
+	mappings, _ := body["mappings"].(map[string]interface{})
+	if mappings == nil {
+		mappings = make(map[string]interface{})
+	}
+	if runtimeMappings != nil {
+		mappings["runtime"] = mergeObjects(mappings["runtime"], runtimeMappings)
+	}
+	if len(index.Spec.FieldAliases) > 0 {
+		mappings["properties"] = mergeObjects(mappings["properties"], fieldAliasProperties(index))
+	}
+	body["mappings"] = mappings
+
+	merged, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	return string(merged), nil
+}
+
+// UpdateMappingHelpers applies spec.runtimeMappings and spec.fieldAliases to an existing index via the _mapping
+// API. Runtime fields recorded in status.managedRuntimeFields but no longer declared in spec.runtimeMappings are
+// removed, other runtime fields of the index are kept.
+func UpdateMappingHelpers(esClient *elasticsearch.Client, index v1alpha1.Index) (ctrl.Result, error) {
+	runtimeMappings, err := parseRuntimeMappings(index)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	var removedRuntimeFields []string
+	for _, name := range index.Status.ManagedRuntimeFields {
+		if _, declared := runtimeMappings[name]; !declared {
+			removedRuntimeFields = append(removedRuntimeFields, name)
+		}
+	}
+	if runtimeMappings == nil && len(removedRuntimeFields) == 0 && len(index.Spec.FieldAliases) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	mappingUpdate := make(map[string]interface{})
+	if runtimeMappings != nil || len(removedRuntimeFields) > 0 {
+		runtime := make(map[string]interface{}, len(runtimeMappings)+len(removedRuntimeFields))
+		if len(removedRuntimeFields) > 0 {
+			currentRuntimeFields, err := getRuntimeFields(esClient, utils.RemoteName(&index))
+			if err != nil {
+				return utils.GetRequeueResult(), err
+			}
+			for _, name := range removedRuntimeFields {
+				if slices.Contains(currentRuntimeFields, name) {
+					runtime[name] = nil
+				}
+			}
+		}
+		for name, definition := range runtimeMappings {
+			runtime[name] = definition
+		}
+		if len(runtime) > 0 {
+			mappingUpdate["runtime"] = runtime
+		}
+	}
+	if len(mappingUpdate) == 0 && len(index.Spec.FieldAliases) == 0 {
+		return ctrl.Result{}, nil
+	}
+	if len(index.Spec.FieldAliases) > 0 {
+		mappingUpdate["properties"] = fieldAliasProperties(index)
+	}
+
+	marshalledUpdate, err := json.Marshal(mappingUpdate)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	res, err := esClient.Indices.PutMapping([]string{utils.RemoteName(&index)}, strings.NewReader(string(marshalledUpdate)))
+	if err != nil || res.IsError() {
+		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
+	}
+	return ctrl.Result{}, nil
+}
+
+// RuntimeFieldNames returns the sorted names of the runtime fields of spec.runtimeMappings, as recorded in
+// status.managedRuntimeFields
+func RuntimeFieldNames(index v1alpha1.Index) []string {
+	runtimeMappings, _ := parseRuntimeMappings(index)
+	var names []string
+	for name := range runtimeMappings {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// getRuntimeFields returns the names of the runtime fields defined in the mappings of the index
+func getRuntimeFields(esClient *elasticsearch.Client, indexName string) ([]string, error) {
+	res, err := esClient.Indices.GetMapping(esClient.Indices.GetMapping.WithIndex(indexName))
+	if err != nil || res.IsError() {
+		return nil, GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var response map[string]struct {
+		Mappings struct {
+			Runtime map[string]json.RawMessage `json:"runtime"`
+		} `json:"mappings"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range response[indexName].Mappings.Runtime {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// mergeObjects adds the entries of additions to the JSON object base, which may be nil
+func mergeObjects(base interface{}, additions map[string]interface{}) map[string]interface{} {
+	merged, _ := base.(map[string]interface{})
+	if merged == nil {
+		merged = make(map[string]interface{}, len(additions))
+	}
+	for key, value := range additions {
+		merged[key] = value
+	}
+	return merged
+}
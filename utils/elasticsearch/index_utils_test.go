@@ -1,11 +1,13 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -392,3 +394,218 @@ func TestCreateIndex_ConnectionError(t *testing.T) {
 		t.Error("CreateIndex() with connection error should request requeue")
 	}
 }
+
+func TestVerifyIndexNotProtected(t *testing.T) {
+	tests := []struct {
+		name      string
+		indexName string
+		force     bool
+		wantErr   bool
+	}{
+		{name: "regular index", indexName: "logs-2024", wantErr: false},
+		{name: "kibana index", indexName: ".kibana", wantErr: true},
+		{name: "security index", indexName: ".security-7", wantErr: true},
+		{name: "slm history index", indexName: "slm-history-5", wantErr: true},
+		{name: "dot inside name", indexName: "my.index", wantErr: false},
+		{name: "forced kibana index", indexName: ".kibana", force: true, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyIndexNotProtected(tt.indexName, tt.force)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyIndexNotProtected() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIndexLifecycle_FakeElasticsearch(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	index := v1alpha1.Index{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+		Spec:       v1alpha1.IndexSpec{Body: `{"settings": {"number_of_shards": 1}}`},
+	}
+	if _, err := CreateIndex(esClient, index); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	if exists, err := VerifyIndexExists(esClient, "logs"); err != nil || !exists {
+		t.Fatalf("VerifyIndexExists() = %v, %v, want true", exists, err)
+	}
+	if _, err := CreateIndex(esClient, index); err == nil {
+		t.Error("Expected creating an existing index to fail")
+	}
+
+	fakeES.SetDocumentCount("logs", 3)
+	if _, err := DeleteIndexIfEmpty(esClient, "logs"); err != nil {
+		t.Fatalf("DeleteIndexIfEmpty() error = %v", err)
+	}
+	if !fakeES.Exists(testutils.ESIndex, "logs") {
+		t.Fatal("Expected a non-empty index to be kept")
+	}
+
+	fakeES.SetDocumentCount("logs", 0)
+	if _, err := DeleteIndexIfEmpty(esClient, "logs"); err != nil {
+		t.Fatalf("DeleteIndexIfEmpty() error = %v", err)
+	}
+	if fakeES.Exists(testutils.ESIndex, "logs") {
+		t.Error("Expected an empty index to be deleted")
+	}
+}
+
+func TestMergeMappingHelpers(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    v1alpha1.IndexSpec
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "no helpers keeps the body unchanged",
+			spec: v1alpha1.IndexSpec{Body: `{"settings": {"number_of_shards": 1}}`},
+			want: `{"settings": {"number_of_shards": 1}}`,
+		},
+		{
+			name: "runtime fields are merged with the runtime section of the body",
+			spec: v1alpha1.IndexSpec{
+				Body:            `{"mappings": {"runtime": {"a": {"type": "long"}}}}`,
+				RuntimeMappings: `{"b": {"type": "keyword"}}`,
+			},
+			want: `{"mappings":{"runtime":{"a":{"type":"long"},"b":{"type":"keyword"}}}}`,
+		},
+		{
+			name: "field aliases are added to the properties",
+			spec: v1alpha1.IndexSpec{
+				Body:         `{"mappings": {"properties": {"duration": {"type": "long"}}}}`,
+				FieldAliases: map[string]string{"took": "duration"},
+			},
+			want: `{"mappings":{"properties":{"duration":{"type":"long"},"took":{"path":"duration","type":"alias"}}}}`,
+		},
+		{
+			name: "empty body",
+			spec: v1alpha1.IndexSpec{RuntimeMappings: `{"b": {"type": "keyword"}}`},
+			want: `{"mappings":{"runtime":{"b":{"type":"keyword"}}}}`,
+		},
+		{
+			name:    "invalid runtime mappings",
+			spec:    v1alpha1.IndexSpec{Body: `{}`, RuntimeMappings: `[]`},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeMappingHelpers(v1alpha1.Index{Spec: tt.spec})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MergeMappingHelpers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("MergeMappingHelpers() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateMappingHelpers_FakeElasticsearch(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	index := v1alpha1.Index{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+		Spec: v1alpha1.IndexSpec{
+			Body:            `{"mappings": {"properties": {"duration": {"type": "long"}}, "runtime": {"body_field": {"type": "keyword"}}}}`,
+			RuntimeMappings: `{"old_field": {"type": "keyword"}}`,
+		},
+	}
+	if _, err := CreateIndex(esClient, index); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	index.Status.ManagedRuntimeFields = RuntimeFieldNames(index)
+
+	index.Spec.RuntimeMappings = `{"new_field": {"type": "long"}}`
+	index.Spec.FieldAliases = map[string]string{"took": "duration"}
+	if _, err := UpdateMappingHelpers(esClient, index); err != nil {
+		t.Fatalf("UpdateMappingHelpers() error = %v", err)
+	}
+
+	stored, _ := fakeES.Get(testutils.ESIndex, "logs")
+	var body struct {
+		Mappings struct {
+			Runtime    map[string]interface{} `json:"runtime"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"mappings"`
+	}
+	if err := json.Unmarshal(stored, &body); err != nil {
+		t.Fatalf("Failed to parse stored index: %v", err)
+	}
+	if _, ok := body.Mappings.Runtime["old_field"]; ok {
+		t.Error("Expected undeclared runtime field to be removed")
+	}
+	if _, ok := body.Mappings.Runtime["new_field"]; !ok {
+		t.Error("Expected declared runtime field to be added")
+	}
+	if _, ok := body.Mappings.Runtime["body_field"]; !ok {
+		t.Error("Expected runtime field of spec.body to be kept")
+	}
+	if _, ok := body.Mappings.Properties["took"]; !ok {
+		t.Error("Expected field alias to be added")
+	}
+	if _, ok := body.Mappings.Properties["duration"]; !ok {
+		t.Error("Expected static mappings to be kept")
+	}
+}
+
+func TestUpdateMappingHelpers_RuntimeMappingsRemoved(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	index := v1alpha1.Index{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+		Spec: v1alpha1.IndexSpec{
+			Body:            `{"mappings": {"runtime": {"body_field": {"type": "keyword"}}}}`,
+			RuntimeMappings: `{"managed_field": {"type": "keyword"}}`,
+		},
+	}
+	if _, err := CreateIndex(esClient, index); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	index.Status.ManagedRuntimeFields = RuntimeFieldNames(index)
+
+	index.Spec.RuntimeMappings = ""
+	if _, err := UpdateMappingHelpers(esClient, index); err != nil {
+		t.Fatalf("UpdateMappingHelpers() error = %v", err)
+	}
+
+	stored, _ := fakeES.Get(testutils.ESIndex, "logs")
+	var body struct {
+		Mappings struct {
+			Runtime map[string]interface{} `json:"runtime"`
+		} `json:"mappings"`
+	}
+	if err := json.Unmarshal(stored, &body); err != nil {
+		t.Fatalf("Failed to parse stored index: %v", err)
+	}
+	if _, ok := body.Mappings.Runtime["managed_field"]; ok {
+		t.Error("Expected the managed runtime field to be removed with spec.runtimeMappings")
+	}
+	if _, ok := body.Mappings.Runtime["body_field"]; !ok {
+		t.Error("Expected runtime field of spec.body to be kept")
+	}
+	if names := RuntimeFieldNames(index); names != nil {
+		t.Errorf("RuntimeFieldNames() = %v, want none", names)
+	}
+}
@@ -0,0 +1,187 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+// NextIngestPipelineSuffix is appended to the name of an IngestPipeline for the pipeline its new version is staged
+// as during a blue/green rollout
+const NextIngestPipelineSuffix = "-next"
+
+// ingestPipelineSettings are the index settings which name the ingest pipeline documents are run through
+var ingestPipelineSettings = [...]string{"index.default_pipeline", "index.final_pipeline"}
+
+// deployedIngestPipelineFields are returned with a pipeline by Elasticsearch but not part of its body
+var deployedIngestPipelineFields = [...]string{"created_date", "created_date_millis", "modified_date", "modified_date_millis"}
+
+// IngestPipelineRollout is the outcome of a blue/green rollout
+type IngestPipelineRollout struct {
+	// Unchanged is set if the deployed pipeline already equals the body and nothing was rolled out
+	Unchanged bool
+	// Tests are the results of spec.tests against the staged pipeline, the rollout was blocked if not all passed
+	Tests []v1alpha1.IngestPipelineTestResult
+	// Indices are the indices switched over to the staged pipeline
+	Indices []string
+}
+
+// RolloutIngestPipeline deploys body as a blue/green rollout, so no document is processed by an untested version:
+//  1. body is staged as <name>-next and spec.tests are run against it; if any fails, <name>-next is deleted and the
+//     deployed pipeline is left untouched.
+//  2. The indices using <name> as default or final pipeline are switched to <name>-next.
+//  3. <name> is updated to body and the indices are switched back.
+//  4. <name>-next is deleted.
+//
+// Indices left on <name>-next by an interrupted rollout are switched back to <name> first. Nothing is rolled out if
+// <name> already equals body, e.g. at the periodic resync.
+func RolloutIngestPipeline(esClient *elasticsearch.Client, ingestPipeline v1alpha1.IngestPipeline, body string) (IngestPipelineRollout, error) {
+	name := utils.RemoteName(&ingestPipeline)
+	next := name + NextIngestPipelineSuffix
+
+	if _, err := switchIngestPipeline(esClient, next, name); err != nil {
+		return IngestPipelineRollout{}, err
+	}
+	if deployed, err := ingestPipelineDeployed(esClient, name, body); err != nil || deployed {
+		if err == nil {
+			err = deleteNextIngestPipeline(esClient, next)
+		}
+		return IngestPipelineRollout{Unchanged: deployed}, err
+	}
+	res, err := esClient.Ingest.PutPipeline(next, strings.NewReader(body))
+	if err != nil || res.IsError() {
+		return IngestPipelineRollout{}, GetClientErrorOrResponseError(err, res)
+	}
+	res.Body.Close()
+
+	var rollout IngestPipelineRollout
+	if len(ingestPipeline.Spec.Tests) > 0 {
+		if rollout.Tests, err = SimulateStoredIngestPipeline(esClient, next, ingestPipeline.Spec.Tests); err != nil {
+			return IngestPipelineRollout{}, err
+		}
+		if !IngestPipelineTestsPassed(rollout.Tests) {
+			return rollout, deleteNextIngestPipeline(esClient, next)
+		}
+	}
+
+	if rollout.Indices, err = switchIngestPipeline(esClient, name, next); err != nil {
+		return rollout, err
+	}
+	if _, err := UpsertIngestPipeline(esClient, ingestPipeline, body); err != nil {
+		return rollout, err
+	}
+	if _, err := switchIngestPipeline(esClient, next, name); err != nil {
+		return rollout, err
+	}
+	return rollout, deleteNextIngestPipeline(esClient, next)
+}
+
+// ingestPipelineDeployed reports whether the pipeline exists with body, ignoring the timestamps Elasticsearch adds
+func ingestPipelineDeployed(esClient *elasticsearch.Client, name string, body string) (bool, error) {
+	res, err := esClient.Ingest.GetPipeline(esClient.Ingest.GetPipeline.WithPipelineID(name))
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return false, nil
+	}
+	if res.IsError() {
+		return false, GetClientErrorOrResponseError(nil, res)
+	}
+	var pipelines map[string]map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&pipelines); err != nil {
+		return false, err
+	}
+	deployed, exists := pipelines[name]
+	if !exists {
+		return false, nil
+	}
+	for _, field := range deployedIngestPipelineFields {
+		delete(deployed, field)
+	}
+	var want map[string]any
+	if err := json.Unmarshal([]byte(body), &want); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(want, deployed), nil
+}
+
+// switchIngestPipeline points the default and final pipeline settings of all indices using from to to. Both
+// settings of an index are updated in the same request, so no index uses from and to at the same time; indices with
+// the same settings to update share a request. It returns the indices switched.
+func switchIngestPipeline(esClient *elasticsearch.Client, from string, to string) ([]string, error) {
+	res, err := esClient.Indices.GetSettings(
+		esClient.Indices.GetSettings.WithName(ingestPipelineSettings[:]...),
+		esClient.Indices.GetSettings.WithFlatSettings(true),
+		esClient.Indices.GetSettings.WithExpandWildcards("all"),
+	)
+	if err != nil || res.IsError() {
+		return nil, GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var response map[string]struct {
+		Settings map[string]string `json:"settings"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	// Indices by the settings using from, e.g. "index.default_pipeline,index.final_pipeline"
+	indicesBySettings := make(map[string][]string)
+	var switched []string
+	for _, index := range slices.Sorted(maps.Keys(response)) {
+		var settings []string
+		for _, setting := range ingestPipelineSettings {
+			if response[index].Settings[setting] == from {
+				settings = append(settings, setting)
+			}
+		}
+		if len(settings) > 0 {
+			key := strings.Join(settings, ",")
+			indicesBySettings[key] = append(indicesBySettings[key], index)
+			switched = append(switched, index)
+		}
+	}
+
+	for _, settings := range slices.Sorted(maps.Keys(indicesBySettings)) {
+		update := make(map[string]string)
+		for _, setting := range strings.Split(settings, ",") {
+			update[setting] = to
+		}
+		marshalled, err := json.Marshal(update)
+		if err != nil {
+			return nil, err
+		}
+		res, err := esClient.Indices.PutSettings(strings.NewReader(string(marshalled)),
+			esClient.Indices.PutSettings.WithIndex(indicesBySettings[settings]...),
+			esClient.Indices.PutSettings.WithExpandWildcards("all"),
+		)
+		if err != nil || res.IsError() {
+			return nil, GetClientErrorOrResponseError(err, res)
+		}
+		res.Body.Close()
+	}
+	return switched, nil
+}
+
+// deleteNextIngestPipeline deletes the pipeline staged by a rollout, a missing pipeline is ignored
+func deleteNextIngestPipeline(esClient *elasticsearch.Client, next string) error {
+	res, err := esClient.Ingest.DeletePipeline(next)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() && res.StatusCode != 404 {
+		return GetClientErrorOrResponseError(nil, res)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package elasticsearch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRolloutIngestPipeline_FakeElasticsearch(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	fakeES.Put(testutils.ESIngestPipeline, "logs", `{"description": "v1"}`)
+	for index, settings := range map[string]string{
+		"logs-a":  `{"settings": {"index.default_pipeline": "logs"}}`,
+		"logs-b":  `{"settings": {"index.final_pipeline": "logs"}}`,
+		"logs-d":  `{"settings": {"index.default_pipeline": "logs", "index.final_pipeline": "logs"}}`,
+		"metrics": `{"settings": {"index.default_pipeline": "metrics"}}`,
+		// left behind by an interrupted rollout
+		"logs-c": `{"settings": {"index.default_pipeline": "logs-next"}}`,
+	} {
+		res, err := esClient.Indices.Create(index, esClient.Indices.Create.WithBody(strings.NewReader(settings)))
+		if err != nil || res.IsError() {
+			t.Fatalf("Failed to create index %s: %v", index, err)
+		}
+	}
+
+	pipeline := v1alpha1.IngestPipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+		Spec: v1alpha1.IngestPipelineSpec{Tests: []v1alpha1.IngestPipelineTest{{
+			Name:           "message",
+			Document:       `{"message": "hello"}`,
+			ExpectedFields: map[string]apiextensionsv1.JSON{"message": {Raw: []byte(`"hello"`)}},
+		}}},
+	}
+	countSettingsUpdates := func() int {
+		count := 0
+		for _, request := range fakeES.Requests() {
+			if request.Method == "PUT" && strings.HasSuffix(request.Path, "/_settings") {
+				count++
+			}
+		}
+		return count
+	}
+	rollout, err := RolloutIngestPipeline(esClient, pipeline, `{"description": "v2"}`)
+	if err != nil || rollout.Unchanged || !IngestPipelineTestsPassed(rollout.Tests) {
+		t.Fatalf("RolloutIngestPipeline() = %+v, %v", rollout, err)
+	}
+	if want := []string{"logs-a", "logs-b", "logs-c", "logs-d"}; !reflect.DeepEqual(rollout.Indices, want) {
+		t.Errorf("Expected the indices using the pipeline to be switched, got %v, want %v", rollout.Indices, want)
+	}
+	// logs-c switched back, then default, final and both settings switched over and back
+	if got := countSettingsUpdates(); got != 7 {
+		t.Errorf("Expected one settings update per group of indices with the same settings, got %d", got)
+	}
+	if body, _ := fakeES.Get(testutils.ESIngestPipeline, "logs"); string(body) != `{"description": "v2"}` {
+		t.Errorf("Expected the pipeline to be updated, got %s", body)
+	}
+	if fakeES.Exists(testutils.ESIngestPipeline, "logs-next") {
+		t.Error("Expected the staged pipeline to be deleted")
+	}
+	if fakeES.IndexSetting("logs-a", "index.default_pipeline") != "logs" || fakeES.IndexSetting("logs-b", "index.final_pipeline") != "logs" ||
+		fakeES.IndexSetting("logs-c", "index.default_pipeline") != "logs" || fakeES.IndexSetting("metrics", "index.default_pipeline") != "metrics" ||
+		fakeES.IndexSetting("logs-d", "index.default_pipeline") != "logs" || fakeES.IndexSetting("logs-d", "index.final_pipeline") != "logs" {
+		t.Error("Expected the indices to be switched back to the pipeline")
+	}
+	if fakeES.CountRequests("POST", "/_ingest/pipeline/logs-next/_simulate") != 1 {
+		t.Error("Expected the tests to be run against the staged pipeline")
+	}
+
+	// The deployed version is not rolled out again
+	settingsUpdates := countSettingsUpdates()
+	rollout, err = RolloutIngestPipeline(esClient, pipeline, `{"description": "v2"}`)
+	if err != nil || !rollout.Unchanged || len(rollout.Indices) > 0 {
+		t.Fatalf("Expected the unchanged pipeline not to be rolled out, got %+v, %v", rollout, err)
+	}
+	if countSettingsUpdates() != settingsUpdates || fakeES.CountRequests("PUT", "/_ingest/pipeline/logs-next") != 1 {
+		t.Error("Expected no pipeline to be staged and no index to be switched")
+	}
+
+	// A failing version is never switched to
+	pipeline.Spec.Tests[0].ExpectedFields["message"] = apiextensionsv1.JSON{Raw: []byte(`"bye"`)}
+	rollout, err = RolloutIngestPipeline(esClient, pipeline, `{"description": "v3"}`)
+	if err != nil || IngestPipelineTestsPassed(rollout.Tests) || len(rollout.Indices) > 0 {
+		t.Fatalf("Expected the rollout to be blocked, got %+v, %v", rollout, err)
+	}
+	if body, _ := fakeES.Get(testutils.ESIngestPipeline, "logs"); string(body) != `{"description": "v2"}` {
+		t.Errorf("Expected the deployed pipeline to be kept, got %s", body)
+	}
+	if fakeES.Exists(testutils.ESIngestPipeline, "logs-next") {
+		t.Error("Expected the failing staged pipeline to be deleted")
+	}
+	if countSettingsUpdates() != settingsUpdates {
+		t.Error("Expected no index to be switched")
+	}
+}
@@ -0,0 +1,118 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"strings"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	ingestPipelineDocuments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "eck_cr_ingest_pipeline_documents",
+		Help: "Number of documents the pipeline of an IngestPipeline processed, summed over the nodes of the cluster.",
+	}, []string{"namespace", "name"})
+	ingestPipelineFailedDocuments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "eck_cr_ingest_pipeline_failed_documents",
+		Help: "Number of documents the pipeline of an IngestPipeline failed to process, summed over the nodes of the cluster.",
+	}, []string{"namespace", "name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(ingestPipelineDocuments, ingestPipelineFailedDocuments)
+}
+
+// nodesIngestStats is the response of the nodes stats API for the ingest metric
+type nodesIngestStats struct {
+	Nodes map[string]struct {
+		Ingest struct {
+			Pipelines map[string]struct {
+				Count        int64 `json:"count"`
+				Failed       int64 `json:"failed"`
+				TimeInMillis int64 `json:"time_in_millis"`
+				// Processors are keyed by their tag, or their type if they have no tag
+				Processors []map[string]struct {
+					Type  string `json:"type"`
+					Stats struct {
+						Failed int64 `json:"failed"`
+					} `json:"stats"`
+				} `json:"processors"`
+			} `json:"pipelines"`
+		} `json:"ingest"`
+	} `json:"nodes"`
+}
+
+// GetIngestPipelineStats returns the ingest statistics of the pipeline summed over the nodes of the cluster, nil if no
+// node reports the pipeline, e.g. because it did not process any documents since the nodes were started. Failures of
+// the processors are summed by their position in the pipeline.
+func GetIngestPipelineStats(esClient *elasticsearch.Client, pipelineID string) (*v1alpha1.IngestPipelineStats, error) {
+	// The filter_path can not address pipelines whose ID contains a path separator or wildcard
+	filterPath := "nodes.*.ingest.pipelines"
+	if !strings.ContainsAny(pipelineID, ".*,") {
+		filterPath += "." + pipelineID
+	}
+	res, err := esClient.Nodes.Stats(
+		esClient.Nodes.Stats.WithMetric("ingest"),
+		esClient.Nodes.Stats.WithFilterPath(filterPath),
+	)
+	if err != nil || res.IsError() {
+		return nil, GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var response nodesIngestStats
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	var stats *v1alpha1.IngestPipelineStats
+	var processors []v1alpha1.IngestProcessorFailures
+	for _, node := range response.Nodes {
+		pipeline, ok := node.Ingest.Pipelines[pipelineID]
+		if !ok {
+			continue
+		}
+		if stats == nil {
+			stats = &v1alpha1.IngestPipelineStats{}
+		}
+		stats.Count += pipeline.Count
+		stats.Failed += pipeline.Failed
+		stats.TimeInMillis += pipeline.TimeInMillis
+		for i, processor := range pipeline.Processors {
+			if i == len(processors) {
+				processors = append(processors, v1alpha1.IngestProcessorFailures{})
+			}
+			for name, processorStats := range processor {
+				processors[i].Processor = name
+				processors[i].Type = processorStats.Type
+				processors[i].Failed += processorStats.Stats.Failed
+			}
+		}
+	}
+	if stats == nil {
+		return nil, nil
+	}
+	for _, processor := range processors {
+		if processor.Failed > 0 {
+			stats.FailedProcessors = append(stats.FailedProcessors, processor)
+		}
+	}
+	return stats, nil
+}
+
+// RecordIngestPipelineStats sets the eck_cr_ingest_pipeline_documents and eck_cr_ingest_pipeline_failed_documents
+// metrics of the IngestPipeline to its stats
+func RecordIngestPipelineStats(ingestPipeline *v1alpha1.IngestPipeline, stats v1alpha1.IngestPipelineStats) {
+	ingestPipelineDocuments.WithLabelValues(ingestPipeline.Namespace, ingestPipeline.Name).Set(float64(stats.Count))
+	ingestPipelineFailedDocuments.WithLabelValues(ingestPipeline.Namespace, ingestPipeline.Name).Set(float64(stats.Failed))
+}
+
+// ForgetIngestPipelineStats removes the metrics of the deleted IngestPipeline
+func ForgetIngestPipelineStats(ingestPipeline *v1alpha1.IngestPipeline) {
+	ingestPipelineDocuments.DeleteLabelValues(ingestPipeline.Namespace, ingestPipeline.Name)
+	ingestPipelineFailedDocuments.DeleteLabelValues(ingestPipeline.Namespace, ingestPipeline.Name)
+}
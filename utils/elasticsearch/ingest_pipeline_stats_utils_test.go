@@ -0,0 +1,58 @@
+package elasticsearch
+
+import (
+	"reflect"
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetIngestPipelineStats(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	fakeES.SetIngestStats("node-0", "logs", testutils.FakeIngestStats{Count: 100, Failed: 3, Processor: "grok"})
+	fakeES.SetIngestStats("node-1", "logs", testutils.FakeIngestStats{Count: 50, Failed: 2, Processor: "grok"})
+	fakeES.SetIngestStats("node-1", "metrics", testutils.FakeIngestStats{Count: 10, Processor: "set"})
+
+	stats, err := GetIngestPipelineStats(esClient, "logs")
+	if err != nil {
+		t.Fatalf("GetIngestPipelineStats() error = %v", err)
+	}
+	want := &v1alpha1.IngestPipelineStats{Count: 150, Failed: 5, TimeInMillis: 150,
+		FailedProcessors: []v1alpha1.IngestProcessorFailures{{Processor: "grok", Type: "grok", Failed: 5}}}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("GetIngestPipelineStats() = %+v, want %+v", stats, want)
+	}
+
+	stats, err = GetIngestPipelineStats(esClient, "metrics")
+	if err != nil || stats == nil || stats.Count != 10 || stats.FailedProcessors != nil {
+		t.Errorf("GetIngestPipelineStats() = %+v, %v, want 10 documents without failed processors", stats, err)
+	}
+
+	if stats, err := GetIngestPipelineStats(esClient, "traces"); err != nil || stats != nil {
+		t.Errorf("GetIngestPipelineStats() = %+v, %v, want nil for a pipeline without stats", stats, err)
+	}
+}
+
+func TestRecordIngestPipelineStats(t *testing.T) {
+	ingestPipeline := &v1alpha1.IngestPipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "logs"}}
+
+	RecordIngestPipelineStats(ingestPipeline, v1alpha1.IngestPipelineStats{Count: 150, Failed: 5})
+	if got := testutil.ToFloat64(ingestPipelineFailedDocuments.WithLabelValues("team-a", "logs")); got != 5 {
+		t.Errorf("eck_cr_ingest_pipeline_failed_documents = %v, want 5", got)
+	}
+
+	ForgetIngestPipelineStats(ingestPipeline)
+	if got := testutil.CollectAndCount(ingestPipelineDocuments); got != 0 {
+		t.Errorf("Expected the metrics to be removed, got %d series", got)
+	}
+}
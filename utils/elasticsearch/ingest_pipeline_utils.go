@@ -3,11 +3,15 @@ package elasticsearch
 import (
 	"eck-custom-resources/utils"
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 
 	"eck-custom-resources/api/es.eck/v1alpha1"
 
 	"github.com/elastic/go-elasticsearch/v8"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -29,12 +33,17 @@ func DeleteIngestPipeline(esClient *elasticsearch.Client, ingestPipelineId strin
 }
 
 func UpsertIngestPipeline(esClient *elasticsearch.Client, ingestPipeline v1alpha1.IngestPipeline, body string) (ctrl.Result, error) {
-	res, err := esClient.Ingest.PutPipeline(ingestPipeline.Name, strings.NewReader(body))
+	res, err := esClient.Ingest.PutPipeline(utils.RemoteName(&ingestPipeline), strings.NewReader(body))
 
 	if err != nil || res.IsError() {
 		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
 	}
 
+	if legacyName, ok := utils.LegacyName(&ingestPipeline); ok {
+		if err := deleteLegacyObject(esClient.Ingest.DeletePipeline(legacyName)); err != nil {
+			return utils.GetRequeueResult(), err
+		}
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -64,3 +73,206 @@ func GetIngestPipeline(esClient *elasticsearch.Client, pipelineId string) (*Inge
 
 	return &pipeline, nil
 }
+
+// MergeIngestPipelineMeta merges the _meta of the deployed pipeline into the _meta of body, so keys set by other
+// tools are kept. Keys of body take precedence. body is returned unchanged if the pipeline does not exist yet.
+func MergeIngestPipelineMeta(esClient *elasticsearch.Client, pipelineId string, body string) (string, error) {
+	res, err := esClient.Ingest.GetPipeline(
+		esClient.Ingest.GetPipeline.WithPipelineID(pipelineId),
+	)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return body, nil
+	}
+	if res.IsError() {
+		return "", GetClientErrorOrResponseError(nil, res)
+	}
+
+	var pipelines map[string]IngestPipelineResponse
+	if err := json.NewDecoder(res.Body).Decode(&pipelines); err != nil {
+		return "", err
+	}
+	return mergeMeta(body, pipelines[pipelineId].Meta)
+}
+
+// mergeMeta sets the _meta of body to the existing _meta overridden by the keys of the _meta of body
+func mergeMeta(body string, existing map[string]any) (string, error) {
+	if len(existing) == 0 {
+		return body, nil
+	}
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return "", fmt.Errorf("body is not a JSON object: %w", err)
+	}
+
+	merged := make(map[string]any, len(existing))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	if declared, ok := parsed["_meta"].(map[string]any); ok {
+		for key, value := range declared {
+			merged[key] = value
+		}
+	}
+	parsed["_meta"] = merged
+
+	mergedBody, err := json.Marshal(parsed)
+	if err != nil {
+		return "", err
+	}
+	return string(mergedBody), nil
+}
+
+type simulatePipelineRequest struct {
+	Pipeline json.RawMessage       `json:"pipeline,omitempty"`
+	Docs     []simulatePipelineDoc `json:"docs"`
+}
+
+type simulatePipelineDoc struct {
+	Source json.RawMessage `json:"_source"`
+}
+
+type simulatePipelineResponse struct {
+	Docs []struct {
+		Doc *struct {
+			Source map[string]any `json:"_source"`
+		} `json:"doc,omitempty"`
+		Error *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error,omitempty"`
+	} `json:"docs"`
+}
+
+// SimulateIngestPipeline runs the tests against the pipeline body using the simulate API and returns
+// one result per test. An error is only returned if the simulation itself could not be performed.
+func SimulateIngestPipeline(esClient *elasticsearch.Client, body string, tests []v1alpha1.IngestPipelineTest) ([]v1alpha1.IngestPipelineTestResult, error) {
+	return simulateIngestPipeline(esClient, "", simulatePipelineRequest{Pipeline: json.RawMessage(body)}, tests)
+}
+
+// SimulateStoredIngestPipeline runs the tests against the pipeline deployed as pipelineId, like SimulateIngestPipeline
+func SimulateStoredIngestPipeline(esClient *elasticsearch.Client, pipelineId string, tests []v1alpha1.IngestPipelineTest) ([]v1alpha1.IngestPipelineTestResult, error) {
+	return simulateIngestPipeline(esClient, pipelineId, simulatePipelineRequest{}, tests)
+}
+
+func simulateIngestPipeline(esClient *elasticsearch.Client, pipelineId string, request simulatePipelineRequest, tests []v1alpha1.IngestPipelineTest) ([]v1alpha1.IngestPipelineTestResult, error) {
+	results := make([]v1alpha1.IngestPipelineTestResult, len(tests))
+	var simulated []int
+
+	for i, test := range tests {
+		results[i].Name = test.Name
+		var document map[string]any
+		if err := json.Unmarshal([]byte(test.Document), &document); err != nil {
+			results[i].Message = fmt.Sprintf("document is not a JSON object: %s", err.Error())
+			continue
+		}
+		request.Docs = append(request.Docs, simulatePipelineDoc{Source: json.RawMessage(test.Document)})
+		simulated = append(simulated, i)
+	}
+	if len(simulated) == 0 {
+		return results, nil
+	}
+
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := esClient.Ingest.Simulate(strings.NewReader(string(requestBody)), esClient.Ingest.Simulate.WithPipelineID(pipelineId))
+	if err != nil || res.IsError() {
+		return nil, GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var response simulatePipelineResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	if len(response.Docs) != len(simulated) {
+		return nil, fmt.Errorf("simulate returned %d documents, expected %d", len(response.Docs), len(simulated))
+	}
+
+	for docIndex, testIndex := range simulated {
+		doc := response.Docs[docIndex]
+		switch {
+		case doc.Error != nil:
+			results[testIndex].Message = fmt.Sprintf("pipeline failed: %s: %s", doc.Error.Type, doc.Error.Reason)
+		case doc.Doc == nil:
+			results[testIndex].Message = "pipeline dropped the document"
+		default:
+			results[testIndex].Passed, results[testIndex].Message = compareExpectedFields(doc.Doc.Source, tests[testIndex].ExpectedFields)
+		}
+	}
+	return results, nil
+}
+
+// IngestPipelineTestsPassed reports whether all test results passed
+func IngestPipelineTestsPassed(results []v1alpha1.IngestPipelineTestResult) bool {
+	for _, result := range results {
+		if !result.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+func compareExpectedFields(source map[string]any, expectedFields map[string]apiextensionsv1.JSON) (bool, string) {
+	var mismatches []string
+	for _, field := range sortedKeys(expectedFields) {
+		var expected any
+		if err := json.Unmarshal(expectedFields[field].Raw, &expected); err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("%s: invalid expected value: %s", field, err.Error()))
+			continue
+		}
+		actual, found := lookupField(source, field)
+		if !found {
+			mismatches = append(mismatches, fmt.Sprintf("%s: missing", field))
+			continue
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			actualJson, _ := json.Marshal(actual)
+			mismatches = append(mismatches, fmt.Sprintf("%s: expected %s, got %s", field, string(expectedFields[field].Raw), string(actualJson)))
+		}
+	}
+	if len(mismatches) > 0 {
+		return false, strings.Join(mismatches, "; ")
+	}
+	return true, ""
+}
+
+// lookupField resolves a field in the document, either by its literal (dotted) name or as a path of nested objects
+func lookupField(source map[string]any, field string) (any, bool) {
+	if value, ok := source[field]; ok {
+		return value, true
+	}
+	head, rest, found := strings.Cut(field, ".")
+	if !found {
+		return nil, false
+	}
+	for ; found; head, rest, found = cutNext(head, rest) {
+		if nested, ok := source[head].(map[string]any); ok {
+			if value, ok := lookupField(nested, rest); ok {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// cutNext moves the next dot separated segment of rest to head
+func cutNext(head string, rest string) (string, string, bool) {
+	next, remaining, found := strings.Cut(rest, ".")
+	return head + "." + next, remaining, found
+}
+
+func sortedKeys(m map[string]apiextensionsv1.JSON) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,13 +1,17 @@
 package elasticsearch
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 
 	"eck-custom-resources/api/es.eck/v1alpha1"
 
 	"github.com/elastic/go-elasticsearch/v8"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -487,3 +491,237 @@ func TestGetIngestPipeline_ConnectionError(t *testing.T) {
 		t.Error("GetIngestPipeline() with connection error should return nil pipeline")
 	}
 }
+
+func TestSimulateIngestPipeline(t *testing.T) {
+	pipelineBody := `{"processors": [{"grok": {"field": "message", "patterns": ["%{IP:client.ip} %{NUMBER:http.status:int}"]}}]}`
+	expected := func(fields map[string]string) map[string]apiextensionsv1.JSON {
+		result := map[string]apiextensionsv1.JSON{}
+		for field, value := range fields {
+			result[field] = apiextensionsv1.JSON{Raw: []byte(value)}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name             string
+		tests            []v1alpha1.IngestPipelineTest
+		serverStatusCode int
+		serverResponse   string
+		wantPassed       []bool
+		wantMessages     []string
+		wantRequests     int
+		wantErr          bool
+	}{
+		{
+			name: "matching nested and dotted fields pass",
+			tests: []v1alpha1.IngestPipelineTest{{
+				Name:           "access log",
+				Document:       `{"message": "10.0.0.1 200"}`,
+				ExpectedFields: expected(map[string]string{"client.ip": `"10.0.0.1"`, "http.status": `200`}),
+			}},
+			serverStatusCode: http.StatusOK,
+			serverResponse:   `{"docs": [{"doc": {"_source": {"message": "10.0.0.1 200", "client": {"ip": "10.0.0.1"}, "http.status": 200}}}]}`,
+			wantPassed:       []bool{true},
+			wantMessages:     []string{""},
+			wantRequests:     1,
+		},
+		{
+			name: "mismatching and missing fields fail",
+			tests: []v1alpha1.IngestPipelineTest{{
+				Name:           "access log",
+				Document:       `{"message": "10.0.0.1 500"}`,
+				ExpectedFields: expected(map[string]string{"client.ip": `"10.0.0.2"`, "user.name": `"bob"`}),
+			}},
+			serverStatusCode: http.StatusOK,
+			serverResponse:   `{"docs": [{"doc": {"_source": {"client": {"ip": "10.0.0.1"}}}}]}`,
+			wantPassed:       []bool{false},
+			wantMessages:     []string{`client.ip: expected "10.0.0.2", got "10.0.0.1"; user.name: missing`},
+			wantRequests:     1,
+		},
+		{
+			name: "processor error fails the test",
+			tests: []v1alpha1.IngestPipelineTest{{
+				Name:           "garbage",
+				Document:       `{"message": "garbage"}`,
+				ExpectedFields: expected(map[string]string{"client.ip": `"10.0.0.1"`}),
+			}},
+			serverStatusCode: http.StatusOK,
+			serverResponse:   `{"docs": [{"error": {"type": "illegal_argument_exception", "reason": "Provided Grok expressions do not match field value"}}]}`,
+			wantPassed:       []bool{false},
+			wantMessages:     []string{"pipeline failed: illegal_argument_exception: Provided Grok expressions do not match field value"},
+			wantRequests:     1,
+		},
+		{
+			name: "invalid document is not sent",
+			tests: []v1alpha1.IngestPipelineTest{{
+				Name:           "broken",
+				Document:       `not json`,
+				ExpectedFields: expected(map[string]string{"client.ip": `"10.0.0.1"`}),
+			}},
+			wantPassed:   []bool{false},
+			wantRequests: 0,
+		},
+		{
+			name: "server error",
+			tests: []v1alpha1.IngestPipelineTest{{
+				Name:           "access log",
+				Document:       `{"message": "10.0.0.1 200"}`,
+				ExpectedFields: expected(map[string]string{"client.ip": `"10.0.0.1"`}),
+			}},
+			serverStatusCode: http.StatusBadRequest,
+			serverResponse:   `{"error": {"type": "parse_exception"}}`,
+			wantRequests:     1,
+			wantErr:          true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if r.URL.Path != "/_ingest/pipeline/_simulate" {
+					t.Errorf("Expected path /_ingest/pipeline/_simulate, got %s", r.URL.Path)
+				}
+				body, _ := io.ReadAll(r.Body)
+				var request map[string]json.RawMessage
+				if err := json.Unmarshal(body, &request); err != nil {
+					t.Errorf("Request body is not JSON: %v", err)
+				}
+				if _, ok := request["pipeline"]; !ok {
+					t.Errorf("Expected pipeline in simulate request, got %s", string(body))
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Elastic-Product", "Elasticsearch")
+				w.WriteHeader(tt.serverStatusCode)
+				w.Write([]byte(tt.serverResponse))
+			}))
+			defer server.Close()
+
+			esClient, err := elasticsearch.NewClient(elasticsearch.Config{
+				Addresses: []string{server.URL},
+			})
+			if err != nil {
+				t.Fatalf("Failed to create ES client: %v", err)
+			}
+
+			results, err := SimulateIngestPipeline(esClient, pipelineBody, tt.tests)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SimulateIngestPipeline() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("Expected %d simulate requests, got %d", tt.wantRequests, requests)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(results) != len(tt.wantPassed) {
+				t.Fatalf("Expected %d results, got %d", len(tt.wantPassed), len(results))
+			}
+			for i, result := range results {
+				if result.Name != tt.tests[i].Name {
+					t.Errorf("Result %d name = %s, want %s", i, result.Name, tt.tests[i].Name)
+				}
+				if result.Passed != tt.wantPassed[i] {
+					t.Errorf("Result %d passed = %v, want %v (%s)", i, result.Passed, tt.wantPassed[i], result.Message)
+				}
+				if tt.wantMessages != nil && result.Message != tt.wantMessages[i] {
+					t.Errorf("Result %d message = %q, want %q", i, result.Message, tt.wantMessages[i])
+				}
+			}
+			if IngestPipelineTestsPassed(results) != !slices.Contains(tt.wantPassed, false) {
+				t.Errorf("IngestPipelineTestsPassed() does not match results %v", results)
+			}
+		})
+	}
+}
+
+func TestMergeIngestPipelineMeta(t *testing.T) {
+	tests := []struct {
+		name             string
+		serverStatusCode int
+		serverResponse   string
+		body             string
+		wantMeta         map[string]any
+		wantUnchanged    bool
+		wantErr          bool
+	}{
+		{
+			name:             "merges foreign keys",
+			serverStatusCode: http.StatusOK,
+			serverResponse:   `{"test-pipeline": {"processors": [], "_meta": {"owner": "team-a", "managed_by": "other"}}}`,
+			body:             `{"processors": [], "_meta": {"managed_by": "eck-custom-resources"}}`,
+			wantMeta:         map[string]any{"owner": "team-a", "managed_by": "eck-custom-resources"},
+		},
+		{
+			name:             "body without meta",
+			serverStatusCode: http.StatusOK,
+			serverResponse:   `{"test-pipeline": {"processors": [], "_meta": {"owner": "team-a"}}}`,
+			body:             `{"processors": []}`,
+			wantMeta:         map[string]any{"owner": "team-a"},
+		},
+		{
+			name:             "pipeline not found",
+			serverStatusCode: http.StatusNotFound,
+			serverResponse:   `{}`,
+			body:             `{"processors": [], "_meta": {"managed_by": "eck-custom-resources"}}`,
+			wantUnchanged:    true,
+		},
+		{
+			name:             "server error",
+			serverStatusCode: http.StatusInternalServerError,
+			serverResponse:   `{"error": {"type": "internal_server_error"}}`,
+			body:             `{"processors": []}`,
+			wantErr:          true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Elastic-Product", "Elasticsearch")
+				w.WriteHeader(tt.serverStatusCode)
+				w.Write([]byte(tt.serverResponse))
+			}))
+			defer server.Close()
+
+			esClient, err := elasticsearch.NewClient(elasticsearch.Config{
+				Addresses: []string{server.URL},
+			})
+			if err != nil {
+				t.Fatalf("Failed to create ES client: %v", err)
+			}
+
+			body, err := MergeIngestPipelineMeta(esClient, "test-pipeline", tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MergeIngestPipelineMeta() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantUnchanged {
+				if body != tt.body {
+					t.Errorf("Expected the body to be unchanged, got %s", body)
+				}
+				return
+			}
+
+			var parsed struct {
+				Meta map[string]any `json:"_meta"`
+			}
+			if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+				t.Fatalf("Failed to parse merged body: %v", err)
+			}
+			if len(parsed.Meta) != len(tt.wantMeta) {
+				t.Fatalf("Expected _meta %v, got %v", tt.wantMeta, parsed.Meta)
+			}
+			for key, value := range tt.wantMeta {
+				if parsed.Meta[key] != value {
+					t.Errorf("Expected _meta.%s = %v, got %v", key, value, parsed.Meta[key])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// timeValuePattern matches the time values of Elasticsearch, a whole number followed by a unit
+var timeValuePattern = regexp.MustCompile(`^(\d+)(d|h|m|s|ms|micros|nanos)$`)
+
+// ValidateTimeValue returns an error describing the expected format unless value is a time value Elasticsearch
+// accepts, like 30d or 12h
+func ValidateTimeValue(value string) error {
+	if value == "0" || value == "-1" || timeValuePattern.MatchString(value) {
+		return nil
+	}
+	if number, unit, ok := cutNumber(value); ok && strings.Contains(number, ".") {
+		return fmt.Errorf("%q is not supported, fractions are not allowed, e.g. use 36h instead of 1.5d", value)
+	} else if ok && unit != "" {
+		return fmt.Errorf("%q has the unknown unit %q, expected one of d, h, m, s, ms, micros, nanos", value, unit)
+	}
+	return fmt.Errorf("%q is not a time value, expected a whole number followed by one of the units d, h, m, s, ms, micros, nanos, e.g. 30d", value)
+}
+
+// cutNumber splits value into its leading number and the rest, reporting false if it does not start with a number
+func cutNumber(value string) (string, string, bool) {
+	end := strings.IndexFunc(value, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if end == 0 {
+		return "", value, false
+	}
+	if end < 0 {
+		end = len(value)
+	}
+	return value[:end], value[end:], true
+}
+
+// cronField describes a field of the cron expressions of Elasticsearch
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+	// special lists the characters the field accepts besides * , - and /
+	special string
+}
+
+var cronFields = []cronField{
+	{name: "second", min: 0, max: 59},
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day-of-month", min: 1, max: 31, special: "?LW"},
+	{name: "month", min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{name: "day-of-week", min: 1, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}, special: "?L#"},
+	{name: "year", min: 1970, max: 2199},
+}
+
+const cronFormat = "expected <second> <minute> <hour> <day-of-month> <month> <day-of-week> [<year>], e.g. \"0 30 1 * * ?\" for 01:30 every day"
+
+// ValidateCronSchedule returns an error describing the problem unless expression is a cron expression of the format
+// Elasticsearch uses for schedules, which starts with the seconds and requires ? for either the day of month or the
+// day of week
+func ValidateCronSchedule(expression string) error {
+	fields := strings.Fields(expression)
+	if len(fields) < 6 || len(fields) > 7 {
+		return fmt.Errorf("%q has %d fields, %s", expression, len(fields), cronFormat)
+	}
+	for i, value := range fields {
+		if err := cronFields[i].validate(value); err != nil {
+			return fmt.Errorf("%q: %s: %w", expression, cronFields[i].name, err)
+		}
+	}
+	if dayOfMonth, dayOfWeek := fields[3], fields[5]; (dayOfMonth == "?") == (dayOfWeek == "?") {
+		return fmt.Errorf("%q: exactly one of day-of-month and day-of-week has to be ?, e.g. \"0 0 9 ? * MON\" for Mondays or \"0 0 9 1 * ?\" for the first day of the month", expression)
+	}
+	return nil
+}
+
+func (f cronField) validate(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		if err := f.validatePart(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f cronField) validatePart(part string) error {
+	if part == "*" || (part == "?" && strings.Contains(f.special, "?")) {
+		return nil
+	}
+	base, step, hasStep := strings.Cut(part, "/")
+	if hasStep {
+		if n, err := strconv.Atoi(step); err != nil || n < 1 {
+			return fmt.Errorf("%q has the invalid increment %q, expected a positive number, e.g. 0/15", part, step)
+		}
+		if base == "*" {
+			return nil
+		}
+	}
+
+	switch {
+	case strings.Contains(f.special, "L") && (base == "L" || base == "LW"):
+		return nil
+	case strings.Contains(f.special, "L") && strings.HasPrefix(base, "L-"):
+		return f.validateValue(strings.TrimPrefix(base, "L-"), part)
+	case strings.Contains(f.special, "W") && strings.HasSuffix(base, "W"):
+		return f.validateValue(strings.TrimSuffix(base, "W"), part)
+	case strings.Contains(f.special, "L") && strings.HasSuffix(base, "L"):
+		return f.validateValue(strings.TrimSuffix(base, "L"), part)
+	case strings.Contains(f.special, "#") && strings.Contains(base, "#"):
+		day, nth, _ := strings.Cut(base, "#")
+		if n, err := strconv.Atoi(nth); err != nil || n < 1 || n > 5 {
+			return fmt.Errorf("%q must name the 1st to 5th weekday of the month, e.g. MON#2", part)
+		}
+		return f.validateValue(day, part)
+	}
+
+	start, end, isRange := strings.Cut(base, "-")
+	if err := f.validateValue(start, part); err != nil {
+		return err
+	}
+	if isRange {
+		return f.validateValue(end, part)
+	}
+	return nil
+}
+
+func (f cronField) validateValue(value string, part string) error {
+	if slices.Contains(f.names, strings.ToUpper(value)) {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < f.min || n > f.max {
+		expected := fmt.Sprintf("%d-%d", f.min, f.max)
+		if len(f.names) > 0 {
+			expected = fmt.Sprintf("%s or %s-%s", expected, f.names[0], f.names[len(f.names)-1])
+		}
+		return fmt.Errorf("%q is not a valid value, expected %s", part, expected)
+	}
+	return nil
+}
+
+// ValidateSnapshotLifecyclePolicyBody checks the schedule and retention.expire_after of a snapshot lifecycle policy
+// body. Bodies which are no JSON object are left to Elasticsearch.
+func ValidateSnapshotLifecyclePolicyBody(body string, path *field.Path) field.ErrorList {
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if schedule, ok := parsed["schedule"].(string); ok {
+		// Intervals like 1h are accepted by Elasticsearch besides cron expressions
+		if err := ValidateCronSchedule(schedule); err != nil && ValidateTimeValue(schedule) != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("schedule"), schedule, err.Error()))
+		}
+	}
+	if retention, ok := parsed["retention"].(map[string]interface{}); ok {
+		allErrs = append(allErrs, validateTimeValueField(retention, "expire_after", path.Child("retention"))...)
+	}
+	return allErrs
+}
+
+// ValidateIndexLifecyclePolicyBody checks the min_age of the phases and the age conditions of the rollover actions of
+// an index lifecycle policy body. Bodies which are no JSON object are left to Elasticsearch.
+func ValidateIndexLifecyclePolicyBody(body string, path *field.Path) field.ErrorList {
+	var parsed struct {
+		Policy struct {
+			Phases map[string]map[string]interface{} `json:"phases"`
+		} `json:"policy"`
+	}
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	phasesPath := path.Child("policy", "phases")
+	phaseNames := make([]string, 0, len(parsed.Policy.Phases))
+	for name := range parsed.Policy.Phases {
+		phaseNames = append(phaseNames, name)
+	}
+	slices.Sort(phaseNames)
+	for _, name := range phaseNames {
+		phase := parsed.Policy.Phases[name]
+		allErrs = append(allErrs, validateTimeValueField(phase, "min_age", phasesPath.Child(name))...)
+		actions, _ := phase["actions"].(map[string]interface{})
+		if rollover, ok := actions["rollover"].(map[string]interface{}); ok {
+			rolloverPath := phasesPath.Child(name, "actions", "rollover")
+			allErrs = append(allErrs, validateTimeValueField(rollover, "max_age", rolloverPath)...)
+			allErrs = append(allErrs, validateTimeValueField(rollover, "min_age", rolloverPath)...)
+		}
+	}
+	return allErrs
+}
+
+// validateTimeValueField checks the key of object, if set, is a time value
+func validateTimeValueField(object map[string]interface{}, key string, path *field.Path) field.ErrorList {
+	value, ok := object[key]
+	if !ok {
+		return nil
+	}
+	text, ok := value.(string)
+	if !ok {
+		return field.ErrorList{field.Invalid(path.Child(key), value, "must be a string holding a time value, e.g. \"30d\"")}
+	}
+	if err := ValidateTimeValue(text); err != nil {
+		return field.ErrorList{field.Invalid(path.Child(key), text, err.Error())}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package elasticsearch
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateTimeValue(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr string
+	}{
+		{value: "30d"},
+		{value: "0ms"},
+		{value: "500micros"},
+		{value: "0"},
+		{value: "1.5d", wantErr: "fractions are not allowed"},
+		{value: "7w", wantErr: `unknown unit "w"`},
+		{value: "30", wantErr: "expected a whole number followed by one of the units"},
+		{value: "thirty days", wantErr: "is not a time value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			err := ValidateTimeValue(tt.value)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("ValidateTimeValue(%q) error = %v", tt.value, err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("ValidateTimeValue(%q) error = %v, want containing %q", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCronSchedule(t *testing.T) {
+	tests := []struct {
+		expression string
+		wantErr    string
+	}{
+		{expression: "0 30 1 * * ?"},
+		{expression: "0 0/15 * * * ?"},
+		{expression: "0 0 9 ? * MON-FRI"},
+		{expression: "0 0 22 ? * 6L"},
+		{expression: "0 0 12 ? * WED#2"},
+		{expression: "0 0 12 LW * ?"},
+		{expression: "0 0 12 15W JAN,JUL ? 2030"},
+		{expression: "30 1 * * *", wantErr: "has 5 fields"},
+		{expression: "0 60 1 * * ?", wantErr: `minute: "60" is not a valid value, expected 0-59`},
+		{expression: "0 0 1 * * MOO", wantErr: "day-of-week: \"MOO\" is not a valid value, expected 1-7 or SUN-SAT"},
+		{expression: "0 0/0 1 * * ?", wantErr: "invalid increment"},
+		{expression: "0 0 12 ? * MON#6", wantErr: "1st to 5th weekday"},
+		{expression: "0 0 1 * * *", wantErr: "exactly one of day-of-month and day-of-week has to be ?"},
+		{expression: "0 0 1 ? * ?", wantErr: "exactly one of day-of-month and day-of-week has to be ?"},
+		{expression: "0 0 ? * * ?", wantErr: "hour"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			err := ValidateCronSchedule(tt.expression)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("ValidateCronSchedule(%q) error = %v", tt.expression, err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("ValidateCronSchedule(%q) error = %v, want containing %q", tt.expression, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSnapshotLifecyclePolicyBody(t *testing.T) {
+	path := field.NewPath("spec").Child("body")
+	valid := []string{
+		`{"schedule": "0 30 1 * * ?", "name": "<nightly-snap-{now/d}>", "repository": "backups", "retention": {"expire_after": "30d"}}`,
+		`{"schedule": "1h", "repository": "backups"}`,
+		`not json`,
+	}
+	for _, body := range valid {
+		if errs := ValidateSnapshotLifecyclePolicyBody(body, path); len(errs) > 0 {
+			t.Errorf("ValidateSnapshotLifecyclePolicyBody(%s) = %v", body, errs)
+		}
+	}
+
+	errs := ValidateSnapshotLifecyclePolicyBody(`{"schedule": "30 1 * * *", "retention": {"expire_after": "1 month"}}`, path)
+	if len(errs) != 2 || errs[0].Field != "spec.body.schedule" || errs[1].Field != "spec.body.retention.expire_after" {
+		t.Errorf("ValidateSnapshotLifecyclePolicyBody() = %v, want errors for the schedule and expire_after", errs)
+	}
+}
+
+func TestValidateIndexLifecyclePolicyBody(t *testing.T) {
+	path := field.NewPath("spec").Child("body")
+	valid := `{"policy": {"phases": {"hot": {"actions": {"rollover": {"max_age": "1d", "max_primary_shard_size": "50gb"}}}, "delete": {"min_age": "30d", "actions": {"delete": {}}}}}}`
+	if errs := ValidateIndexLifecyclePolicyBody(valid, path); len(errs) > 0 {
+		t.Errorf("ValidateIndexLifecyclePolicyBody() = %v", errs)
+	}
+
+	invalid := `{"policy": {"phases": {"hot": {"actions": {"rollover": {"max_age": "1 day"}}}, "delete": {"min_age": 30, "actions": {"delete": {}}}}}}`
+	errs := ValidateIndexLifecyclePolicyBody(invalid, path)
+	if len(errs) != 2 || errs[0].Field != "spec.body.policy.phases.delete.min_age" || errs[1].Field != "spec.body.policy.phases.hot.actions.rollover.max_age" {
+		t.Errorf("ValidateIndexLifecyclePolicyBody() = %v, want errors for the delete min_age and the rollover max_age", errs)
+	}
+}
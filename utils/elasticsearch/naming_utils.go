@@ -0,0 +1,190 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// deleteLegacyObject checks the response of deleting the object a resource managed before the naming policy
+// applied, an object which does not exist (anymore) is not an error
+func deleteLegacyObject(res *esapi.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return nil
+	}
+	if res.IsError() {
+		return fmt.Errorf("failed to delete the object renamed by the naming policy: %w", GetClientErrorOrResponseError(nil, res))
+	}
+	return nil
+}
+
+// MigrateIndex renames the index a resource managed before the naming policy applied, in steps across reconciles:
+// writes to the legacy index are blocked, its documents are reindexed into indexName, which has to exist, by a task
+// tracked in task, and once all documents arrived the legacy index is replaced by an alias of indexName in a single
+// aliases request. running is true while the reindex task runs, migrated once the legacy index is replaced; both are
+// false if there is no legacy index (anymore).
+func MigrateIndex(esClient *elasticsearch.Client, legacyName string, indexName string, task *string) (migrated bool, running bool, err error) {
+	concrete, err := isConcreteIndex(esClient, legacyName)
+	if err != nil || !concrete {
+		if err == nil {
+			*task = ""
+		}
+		return false, false, err
+	}
+
+	// Documents written after the reindex started would be lost with the legacy index
+	if err := putIndexSettings(esClient, legacyName, map[string]any{IndexBlockWrite: true}); err != nil {
+		return false, false, fmt.Errorf("failed to block writes to %s: %w", legacyName, err)
+	}
+
+	if *task == "" {
+		id, err := startReindex(esClient, legacyName, indexName)
+		if err != nil {
+			return false, false, err
+		}
+		*task = id
+		return false, true, nil
+	}
+
+	reindexed, completed, err := getReindexTask(esClient, *task)
+	if err != nil || !completed {
+		return false, !completed && err == nil, err
+	}
+	// A failed reindex is started again at the next reconcile
+	*task = ""
+	if reindexed < 0 {
+		return false, false, fmt.Errorf("reindexing %s into %s failed, the index is not renamed", legacyName, indexName)
+	}
+
+	legacyCount, err := countDocuments(esClient, legacyName)
+	if err != nil {
+		return false, false, err
+	}
+	count, err := countDocuments(esClient, indexName)
+	if err != nil {
+		return false, false, err
+	}
+	if reindexed != legacyCount || count < legacyCount {
+		return false, false, fmt.Errorf("reindexed %d of %d documents of %s into %s, which has %d documents, the index is not renamed",
+			reindexed, legacyCount, legacyName, indexName, count)
+	}
+
+	body, err := json.Marshal(map[string]any{"actions": []any{
+		map[string]any{"add": map[string]any{"index": indexName, "alias": legacyName}},
+		map[string]any{"remove_index": map[string]any{"index": legacyName}},
+	}})
+	if err != nil {
+		return false, false, err
+	}
+	res, err := esClient.Indices.UpdateAliases(strings.NewReader(string(body)))
+	if err != nil || res.IsError() {
+		return false, false, GetClientErrorOrResponseError(err, res)
+	}
+	res.Body.Close()
+	return true, false, nil
+}
+
+// startReindex starts reindexing the documents of source into dest without waiting for the completion and returns
+// the ID of the task
+func startReindex(esClient *elasticsearch.Client, source string, dest string) (string, error) {
+	body, err := json.Marshal(map[string]any{
+		"source": map[string]any{"index": source},
+		"dest":   map[string]any{"index": dest},
+	})
+	if err != nil {
+		return "", err
+	}
+	res, err := esClient.Reindex(strings.NewReader(string(body)),
+		esClient.Reindex.WithWaitForCompletion(false),
+		esClient.Reindex.WithRefresh(true),
+	)
+	if err != nil || res.IsError() {
+		return "", GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+	var response struct {
+		Task string `json:"task"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return "", err
+	}
+	return response.Task, nil
+}
+
+// getReindexTask returns whether the reindex task completed and the number of documents it reindexed, -1 if it
+// failed or is unknown, e.g. because its result was deleted
+func getReindexTask(esClient *elasticsearch.Client, task string) (reindexed int, completed bool, err error) {
+	res, err := esClient.Tasks.Get(task)
+	if err != nil {
+		return 0, false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return -1, true, nil
+	}
+	if res.IsError() {
+		return 0, false, GetClientErrorOrResponseError(nil, res)
+	}
+	var response struct {
+		Completed bool            `json:"completed"`
+		Error     json.RawMessage `json:"error"`
+		Response  struct {
+			Total    int               `json:"total"`
+			Failures []json.RawMessage `json:"failures"`
+		} `json:"response"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return 0, false, err
+	}
+	if !response.Completed {
+		return 0, false, nil
+	}
+	if len(response.Error) > 0 || len(response.Response.Failures) > 0 {
+		return -1, true, nil
+	}
+	return response.Response.Total, true, nil
+}
+
+// countDocuments returns the number of documents of the index
+func countDocuments(esClient *elasticsearch.Client, index string) (int, error) {
+	res, err := esClient.Count(esClient.Count.WithIndex(index))
+	if err != nil || res.IsError() {
+		return 0, GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+	var response struct {
+		Count int `json:"count"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return 0, err
+	}
+	return response.Count, nil
+}
+
+// isConcreteIndex reports whether name is an index and not an alias or data stream pointing to one
+func isConcreteIndex(esClient *elasticsearch.Client, name string) (bool, error) {
+	res, err := esClient.Indices.Get([]string{name})
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return false, nil
+	}
+	if res.IsError() {
+		return false, GetClientErrorOrResponseError(nil, res)
+	}
+	var indices map[string]json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&indices); err != nil {
+		return false, err
+	}
+	_, ok := indices[name]
+	return ok, nil
+}
@@ -0,0 +1,123 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+	"eck-custom-resources/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpsertIngestPipeline_NamingPolicy(t *testing.T) {
+	defer utils.SetNamingPolicy(nil)
+	fakeElasticsearch := testutils.NewFakeElasticsearch()
+	defer fakeElasticsearch.Close()
+	esClient, err := fakeElasticsearch.Client()
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	fakeElasticsearch.Put(testutils.ESIngestPipeline, "logs", `{"processors": []}`)
+	pipeline := v1alpha1.IngestPipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "logs"}}
+
+	utils.SetNamingPolicy(&configv2.NamingPolicy{Pattern: "{namespace}-{name}"})
+	if _, err := UpsertIngestPipeline(esClient, pipeline, `{"processors": []}`); err != nil {
+		t.Fatalf("UpsertIngestPipeline() error = %v", err)
+	}
+	if !fakeElasticsearch.Exists(testutils.ESIngestPipeline, "team-a-logs") || !fakeElasticsearch.Exists(testutils.ESIngestPipeline, "logs") {
+		t.Fatal("Expected the pipeline to be created under the new name and the old one to be kept")
+	}
+
+	utils.SetNamingPolicy(&configv2.NamingPolicy{Pattern: "{namespace}-{name}", Migrate: true})
+	for i := 0; i < 2; i++ {
+		if _, err := UpsertIngestPipeline(esClient, pipeline, `{"processors": []}`); err != nil {
+			t.Fatalf("UpsertIngestPipeline() error = %v", err)
+		}
+	}
+	if fakeElasticsearch.Exists(testutils.ESIngestPipeline, "logs") {
+		t.Error("Expected the pipeline under the old name to be deleted by the migration")
+	}
+}
+
+func TestMigrateIndex(t *testing.T) {
+	fakeElasticsearch := testutils.NewFakeElasticsearch()
+	defer fakeElasticsearch.Close()
+	esClient, err := fakeElasticsearch.Client()
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	var task string
+	if migrated, running, err := MigrateIndex(esClient, "logs", "team-a-logs", &task); err != nil || migrated || running {
+		t.Fatalf("MigrateIndex() without legacy index = %v, %v, %v, want false, false, nil", migrated, running, err)
+	}
+
+	fakeElasticsearch.Put(testutils.ESIndex, "logs", `{}`)
+	fakeElasticsearch.Put(testutils.ESIndex, "team-a-logs", `{}`)
+	fakeElasticsearch.SetDocumentCount("logs", 42)
+	fakeElasticsearch.HoldTasks(true)
+
+	// The reindex runs across reconciles, the legacy index is kept until it completed
+	for i := 0; i < 2; i++ {
+		migrated, running, err := MigrateIndex(esClient, "logs", "team-a-logs", &task)
+		if err != nil || migrated || !running || task == "" {
+			t.Fatalf("MigrateIndex() while reindexing = %v, %v, %v, task %q, want false, true, nil", migrated, running, err, task)
+		}
+	}
+	if !fakeElasticsearch.IndexBlocks("logs")[IndexBlockWrite] {
+		t.Error("Expected writes to the legacy index to be blocked while reindexing")
+	}
+	if !fakeElasticsearch.Exists(testutils.ESIndex, "logs") {
+		t.Fatal("Expected the legacy index to be kept while reindexing")
+	}
+
+	fakeElasticsearch.HoldTasks(false)
+	migrated, running, err := MigrateIndex(esClient, "logs", "team-a-logs", &task)
+	if err != nil || !migrated || running || task != "" {
+		t.Fatalf("MigrateIndex() = %v, %v, %v, task %q, want true, false, nil", migrated, running, err, task)
+	}
+	if count := fakeElasticsearch.DocumentCount("team-a-logs"); count != 42 {
+		t.Errorf("Expected the documents to be reindexed once, got %d", count)
+	}
+	if fakeElasticsearch.Exists(testutils.ESIndex, "logs") {
+		t.Error("Expected the legacy index to be deleted")
+	}
+	if index, ok := fakeElasticsearch.Alias("logs"); !ok || index != "team-a-logs" {
+		t.Errorf("Expected the legacy name to be an alias of the new index, got %s", index)
+	}
+
+	// The alias is not migrated again
+	if migrated, running, err := MigrateIndex(esClient, "logs", "team-a-logs", &task); err != nil || migrated || running {
+		t.Errorf("MigrateIndex() after the migration = %v, %v, %v, want false, false, nil", migrated, running, err)
+	}
+}
+
+func TestMigrateIndex_MissingDocuments(t *testing.T) {
+	fakeElasticsearch := testutils.NewFakeElasticsearch()
+	defer fakeElasticsearch.Close()
+	esClient, err := fakeElasticsearch.Client()
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	fakeElasticsearch.Put(testutils.ESIndex, "logs", `{}`)
+	fakeElasticsearch.Put(testutils.ESIndex, "team-a-logs", `{}`)
+	fakeElasticsearch.SetDocumentCount("logs", 42)
+
+	var task string
+	if _, _, err := MigrateIndex(esClient, "logs", "team-a-logs", &task); err != nil {
+		t.Fatalf("MigrateIndex() error = %v", err)
+	}
+	// Documents missing in the new index, e.g. deleted since the reindex, keep the legacy index
+	fakeElasticsearch.SetDocumentCount("team-a-logs", 40)
+	if migrated, _, err := MigrateIndex(esClient, "logs", "team-a-logs", &task); err == nil || migrated {
+		t.Fatalf("MigrateIndex() = %v, %v, want an error for missing documents", migrated, err)
+	}
+	if !fakeElasticsearch.Exists(testutils.ESIndex, "logs") {
+		t.Error("Expected the legacy index to be kept")
+	}
+	if task != "" {
+		t.Errorf("Expected the reindex to be started again, got task %q", task)
+	}
+}
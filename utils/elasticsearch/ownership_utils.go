@@ -0,0 +1,166 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// OwnershipConflictRecheckInterval is how often a resource whose remote object is owned by another resource checks
+// whether it became the owner
+var OwnershipConflictRecheckInterval = time.Minute
+
+// OwnershipConflict condition, set while another resource of the same kind manages the object of the same name on the
+// same instance
+const (
+	OwnershipConflictConditionType = "OwnershipConflict"
+	OwnershipConflictReason        = "OwnershipConflict"
+)
+
+// FindOwner returns the resource of the kind of obj owning the remote object obj manages, obj itself if it owns it.
+// Resources of any namespace declaring the same target instance and resolving to the same remote name claim the same
+// object; the oldest of them owns it, by creation time and then by namespace and name. Resources being deleted keep
+// owning the object until their finalizer deleted it.
+func FindOwner(ctx context.Context, cli client.Client, obj client.Object, targetConfig v1alpha1.CommonElasticsearchConfig) (client.Object, error) {
+	gvk, err := apiutil.GVKForObject(obj, cli.Scheme())
+	if err != nil {
+		return nil, err
+	}
+	runtimeList, err := cli.Scheme().New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err != nil {
+		return nil, err
+	}
+	list := runtimeList.(client.ObjectList)
+	if err := utils.ListPaged(ctx, cli, list); err != nil {
+		return nil, err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteName := utils.RemoteName(obj)
+	owner := obj
+	for _, item := range items {
+		other, ok := item.(client.Object)
+		if !ok || (other.GetNamespace() == obj.GetNamespace() && other.GetName() == obj.GetName()) {
+			continue
+		}
+		if utils.RemoteName(other) != remoteName || !sameTargetConfig(obj.GetNamespace(), targetConfig, other.GetNamespace(), targetConfigOf(other)) {
+			continue
+		}
+		if claimedBefore(other, owner) {
+			owner = other
+		}
+	}
+	return owner, nil
+}
+
+// CheckOwnership reports whether obj owns its remote object and may manage it. While another resource owns it, the
+// OwnershipConflict condition is set on obj, an event is recorded when the owner changes and the returned result
+// requeues the resource after OwnershipConflictRecheckInterval extended by a per-object jitter. The conditions of
+// resources being deleted are not changed.
+// conditions must point into the status of obj.
+func CheckOwnership(ctx context.Context, cli client.Client, recorder record.EventRecorder, obj client.Object,
+	conditions *[]metav1.Condition, targetConfig v1alpha1.CommonElasticsearchConfig) (bool, ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	gvk, err := apiutil.GVKForObject(obj, cli.Scheme())
+	if err != nil {
+		return false, utils.GetRequeueResult(), err
+	}
+	owner, err := FindOwner(ctx, cli, obj, targetConfig)
+	if err != nil {
+		return false, utils.GetRequeueResult(), err
+	}
+	if !obj.GetDeletionTimestamp().IsZero() {
+		return owner == obj, ctrl.Result{}, nil
+	}
+
+	if owner == obj {
+		if clearOwnershipConflict(conditions) {
+			if err := cli.Status().Update(ctx, obj); err != nil {
+				logger.Error(err, "Failed to clear OwnershipConflict condition")
+			}
+		}
+		return true, ctrl.Result{}, nil
+	}
+
+	message := fmt.Sprintf("%s %s/%s manages the object %s on the same instance, it was created first",
+		gvk.Kind, owner.GetNamespace(), owner.GetName(), utils.RemoteName(obj))
+	logger.Info("Another resource owns the object, not reconciling", "owner", client.ObjectKeyFromObject(owner).String())
+	if setOwnershipConflict(conditions, obj.GetGeneration(), message) {
+		recorder.Event(obj, "Warning", OwnershipConflictReason, message)
+		if err := cli.Status().Update(ctx, obj); err != nil {
+			logger.Error(err, "Failed to set OwnershipConflict condition")
+		}
+	}
+	key := fmt.Sprintf("%T/%s", obj, client.ObjectKeyFromObject(obj))
+	return false, ctrl.Result{RequeueAfter: utils.JitterFor(key, OwnershipConflictRecheckInterval)}, nil
+}
+
+// claimedBefore reports whether a claimed the remote object before b
+func claimedBefore(a client.Object, b client.Object) bool {
+	createdA, createdB := a.GetCreationTimestamp(), b.GetCreationTimestamp()
+	if !createdA.Equal(&createdB) {
+		return createdA.Before(&createdB)
+	}
+	return a.GetNamespace()+"/"+a.GetName() < b.GetNamespace()+"/"+b.GetName()
+}
+
+// targetConfigOf returns spec.targetInstance of the resource
+func targetConfigOf(obj client.Object) v1alpha1.CommonElasticsearchConfig {
+	var targetConfig v1alpha1.CommonElasticsearchConfig
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return targetConfig
+	}
+	if raw, ok, _ := unstructured.NestedMap(content, "spec", "targetInstance"); ok {
+		_ = runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &targetConfig)
+	}
+	return targetConfig
+}
+
+func setOwnershipConflict(conditions *[]metav1.Condition, generation int64, message string) bool {
+	changed := meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               OwnershipConflictConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             OwnershipConflictReason,
+		Message:            message,
+	})
+	readyChanged := meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               utils.ReadyConditionType,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             OwnershipConflictReason,
+		Message:            message,
+	})
+	return changed || readyChanged
+}
+
+// clearOwnershipConflict removes the OwnershipConflict condition, and the Ready condition if it was set because of
+// it; the reconcile following sets the Ready condition again
+func clearOwnershipConflict(conditions *[]metav1.Condition) bool {
+	if !meta.IsStatusConditionTrue(*conditions, OwnershipConflictConditionType) {
+		return false
+	}
+	meta.RemoveStatusCondition(conditions, OwnershipConflictConditionType)
+	if ready := meta.FindStatusCondition(*conditions, utils.ReadyConditionType); ready != nil && ready.Reason == OwnershipConflictReason {
+		meta.RemoveStatusCondition(conditions, utils.ReadyConditionType)
+	}
+	return true
+}
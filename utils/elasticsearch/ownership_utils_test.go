@@ -0,0 +1,88 @@
+package elasticsearch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckOwnership(t *testing.T) {
+	created := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	index := func(namespace string, age time.Duration, targetConfig v1alpha1.CommonElasticsearchConfig) *v1alpha1.Index {
+		return &v1alpha1.Index{
+			ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: namespace, Generation: 1, CreationTimestamp: metav1.NewTime(created.Add(-age))},
+			Spec:       v1alpha1.IndexSpec{TargetConfig: targetConfig},
+		}
+	}
+	first := index("team-a", time.Hour, v1alpha1.CommonElasticsearchConfig{})
+	second := index("team-b", time.Minute, v1alpha1.CommonElasticsearchConfig{})
+	otherInstance := index("team-c", 0, v1alpha1.CommonElasticsearchConfig{ElasticsearchInstance: "other"})
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(first, second, otherInstance).
+		WithStatusSubresource(first, second, otherInstance).Build()
+	recorder := record.NewFakeRecorder(10)
+	ctx := context.Background()
+
+	if owned, _, err := CheckOwnership(ctx, cli, recorder, first, &first.Status.Conditions, first.Spec.TargetConfig); err != nil || !owned {
+		t.Errorf("Expected the oldest Index to own the index, got owned=%v err=%v", owned, err)
+	}
+	if owned, _, err := CheckOwnership(ctx, cli, recorder, otherInstance, &otherInstance.Status.Conditions, otherInstance.Spec.TargetConfig); err != nil || !owned {
+		t.Errorf("Expected an Index of another instance to own its index, got owned=%v err=%v", owned, err)
+	}
+
+	owned, res, err := CheckOwnership(ctx, cli, recorder, second, &second.Status.Conditions, second.Spec.TargetConfig)
+	if err != nil || owned || res.RequeueAfter == 0 {
+		t.Fatalf("CheckOwnership() = %v, %v, %v, want the later Index to be requeued", owned, res, err)
+	}
+	if !meta.IsStatusConditionTrue(second.Status.Conditions, OwnershipConflictConditionType) {
+		t.Errorf("Expected the OwnershipConflict condition to be set, got %v", second.Status.Conditions)
+	}
+	if ready := meta.FindStatusCondition(second.Status.Conditions, utils.ReadyConditionType); ready == nil ||
+		ready.Status != metav1.ConditionFalse || ready.Reason != OwnershipConflictReason {
+		t.Errorf("Expected the Ready condition to be False with reason OwnershipConflict, got %v", ready)
+	}
+	CheckOwnership(ctx, cli, recorder, second, &second.Status.Conditions, second.Spec.TargetConfig)
+	if len(recorder.Events) != 1 {
+		t.Errorf("Expected a single OwnershipConflict event, got %d", len(recorder.Events))
+	}
+
+	if err := cli.Delete(ctx, first); err != nil {
+		t.Fatalf("Failed to delete the owning Index: %v", err)
+	}
+	if owned, _, err := CheckOwnership(ctx, cli, recorder, second, &second.Status.Conditions, second.Spec.TargetConfig); err != nil || !owned {
+		t.Fatalf("Expected the later Index to own the index once the first is gone, got owned=%v err=%v", owned, err)
+	}
+	if len(second.Status.Conditions) != 0 {
+		t.Errorf("Expected the OwnershipConflict and Ready conditions to be removed, got %v", second.Status.Conditions)
+	}
+}
+
+func TestFindOwner_NamingPolicy(t *testing.T) {
+	defer utils.SetNamingPolicy(nil)
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	first := &v1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "team-a", CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))}}
+	second := &v1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "team-b", CreationTimestamp: metav1.NewTime(time.Now())}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(first, second).Build()
+
+	utils.SetNamingPolicy(&configv2.NamingPolicy{Pattern: "{namespace}-{name}"})
+	owner, err := FindOwner(context.Background(), cli, second, second.Spec.TargetConfig)
+	if err != nil {
+		t.Fatalf("FindOwner() error = %v", err)
+	}
+	if client.ObjectKeyFromObject(owner) != client.ObjectKeyFromObject(second) {
+		t.Errorf("Expected Indices with different remote names not to conflict, got owner %s", client.ObjectKeyFromObject(owner))
+	}
+}
@@ -0,0 +1,158 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/utils"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// SecurityClusterPrivileges are the cluster privileges the operator needs to manage users, roles and API keys
+var SecurityClusterPrivileges = []string{"manage_security"}
+
+// PrivilegesPreflightInterval is how long the result of a privileges check is reused, it is also the interval in
+// which resources are requeued while privileges are missing
+var PrivilegesPreflightInterval = time.Minute
+
+// InsufficientPrivileges condition, set while the credentials of the target instance lack privileges the resource
+// needs
+const (
+	InsufficientPrivilegesConditionType = "InsufficientPrivileges"
+	InsufficientPrivilegesReason        = "InsufficientPrivileges"
+)
+
+// privilegesPreflight caches the missing cluster privileges per instance and credentials, so that a check is sent
+// at most every PrivilegesPreflightInterval instead of on every reconcile
+type privilegesPreflight struct {
+	mu      sync.Mutex
+	results map[string]privilegesPreflightResult
+	now     func() time.Time
+}
+
+type privilegesPreflightResult struct {
+	missing []string
+	checked time.Time
+}
+
+var defaultPrivilegesPreflight = newPrivilegesPreflight(time.Now)
+
+func newPrivilegesPreflight(now func() time.Time) *privilegesPreflight {
+	return &privilegesPreflight{
+		results: make(map[string]privilegesPreflightResult),
+		now:     now,
+	}
+}
+
+// CheckSecurityPrivileges reports whether the credentials of the target instance have SecurityClusterPrivileges,
+// checked with the has privileges API before users, roles and API keys are reconciled. While privileges are missing,
+// the InsufficientPrivileges condition is set on the object, an event is recorded when it is set and the returned
+// result requeues the resource without an error after PrivilegesPreflightInterval extended by a per-object jitter.
+// The resource is reconciled if the check itself fails, e.g. because security is disabled.
+// conditions must point into the status of obj.
+func CheckSecurityPrivileges(ctx context.Context, cli client.Client, recorder record.EventRecorder, obj client.Object,
+	conditions *[]metav1.Condition, esClient *elasticsearch.Client, targetInstance configv2.ElasticsearchSpec) (bool, ctrl.Result) {
+	logger := log.FromContext(ctx)
+
+	missing, err := defaultPrivilegesPreflight.check(privilegesPreflightKey(targetInstance), func() ([]string, error) {
+		return missingClusterPrivileges(esClient, SecurityClusterPrivileges)
+	})
+	if err != nil {
+		logger.V(1).Info("Failed to check the privileges of the operator, reconciling anyway", "error", err.Error())
+		missing = nil
+	}
+
+	if len(missing) == 0 {
+		if clearInsufficientPrivileges(conditions) {
+			if err := cli.Status().Update(ctx, obj); err != nil {
+				logger.Error(err, "Failed to clear InsufficientPrivileges condition")
+			}
+		}
+		return true, ctrl.Result{}
+	}
+
+	message := fmt.Sprintf("The credentials of %s lack the cluster privileges %s, grant them to the operator user",
+		targetInstance.Url, strings.Join(missing, ", "))
+	logger.Info("Insufficient privileges, not reconciling", "missing", missing)
+	if setInsufficientPrivileges(conditions, obj.GetGeneration(), message) {
+		recorder.Event(obj, "Warning", InsufficientPrivilegesReason, message)
+		if err := cli.Status().Update(ctx, obj); err != nil {
+			logger.Error(err, "Failed to set InsufficientPrivileges condition")
+		}
+	}
+	key := fmt.Sprintf("%T/%s", obj, client.ObjectKeyFromObject(obj))
+	return false, ctrl.Result{RequeueAfter: utils.JitterFor(key, PrivilegesPreflightInterval)}
+}
+
+// privilegesPreflightKey identifies the instance and the credentials used for it
+func privilegesPreflightKey(targetInstance configv2.ElasticsearchSpec) string {
+	user := ""
+	if targetInstance.Authentication != nil && targetInstance.Authentication.UsernamePassword != nil {
+		user = targetInstance.Authentication.UsernamePassword.UserName
+	} else if targetInstance.Authentication != nil && targetInstance.Authentication.APIKey != nil {
+		user = "apikey"
+	}
+	return targetInstance.Url + "|" + user
+}
+
+// check returns the cached missing privileges of key, running checkMissing if there is no result younger than
+// PrivilegesPreflightInterval. Failed checks are not cached.
+func (p *privilegesPreflight) check(key string, checkMissing func() ([]string, error)) ([]string, error) {
+	p.mu.Lock()
+	result, ok := p.results[key]
+	p.mu.Unlock()
+	if ok && p.now().Sub(result.checked) < PrivilegesPreflightInterval {
+		return result.missing, nil
+	}
+
+	missing, err := checkMissing()
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	p.results[key] = privilegesPreflightResult{missing: missing, checked: p.now()}
+	p.mu.Unlock()
+	return missing, nil
+}
+
+func setInsufficientPrivileges(conditions *[]metav1.Condition, generation int64, message string) bool {
+	changed := meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               InsufficientPrivilegesConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             InsufficientPrivilegesReason,
+		Message:            message,
+	})
+	readyChanged := meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               utils.ReadyConditionType,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             InsufficientPrivilegesReason,
+		Message:            message,
+	})
+	return changed || readyChanged
+}
+
+// clearInsufficientPrivileges removes the InsufficientPrivileges condition, and the Ready condition if it was set
+// because of it; the reconcile following sets the Ready condition again
+func clearInsufficientPrivileges(conditions *[]metav1.Condition) bool {
+	if !meta.IsStatusConditionTrue(*conditions, InsufficientPrivilegesConditionType) {
+		return false
+	}
+	meta.RemoveStatusCondition(conditions, InsufficientPrivilegesConditionType)
+	if ready := meta.FindStatusCondition(*conditions, utils.ReadyConditionType); ready != nil && ready.Reason == InsufficientPrivilegesReason {
+		meta.RemoveStatusCondition(conditions, utils.ReadyConditionType)
+	}
+	return true
+}
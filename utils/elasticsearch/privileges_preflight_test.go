@@ -0,0 +1,86 @@
+package elasticsearch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+	"eck-custom-resources/utils"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPrivilegesPreflight_Check(t *testing.T) {
+	now := time.Now()
+	preflight := newPrivilegesPreflight(func() time.Time { return now })
+	checks := 0
+	checkMissing := func() ([]string, error) {
+		checks++
+		return []string{"manage_security"}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if missing, err := preflight.check("https://elasticsearch:9200|elastic", checkMissing); err != nil || len(missing) != 1 {
+			t.Fatalf("check() = %v, %v, want manage_security to be missing", missing, err)
+		}
+	}
+	if checks != 1 {
+		t.Errorf("Expected the result to be reused within the interval, got %d checks", checks)
+	}
+
+	now = now.Add(PrivilegesPreflightInterval)
+	preflight.check("https://elasticsearch:9200|elastic", checkMissing)
+	preflight.check("https://elasticsearch:9200|operator", checkMissing)
+	if checks != 3 {
+		t.Errorf("Expected a check after the interval and for other credentials, got %d checks", checks)
+	}
+}
+
+func TestCheckSecurityPrivileges_Conditions(t *testing.T) {
+	defer func() { defaultPrivilegesPreflight = newPrivilegesPreflight(time.Now) }()
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	role := &v1alpha1.ElasticsearchRole{ObjectMeta: metav1.ObjectMeta{Name: "reader", Namespace: "default", Generation: 1}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(role).WithStatusSubresource(role).Build()
+	recorder := record.NewFakeRecorder(10)
+	targetInstance := configv2.ElasticsearchSpec{Url: fakeES.URL()}
+
+	fakeES.SetMissingPrivileges("manage_security")
+	permitted, res := CheckSecurityPrivileges(context.Background(), cli, recorder, role, &role.Status.Conditions, esClient, targetInstance)
+	if permitted || res.RequeueAfter == 0 {
+		t.Fatalf("CheckSecurityPrivileges() = %v, %v, want the reconcile to be requeued", permitted, res)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Expected a single InsufficientPrivileges event, got %d", len(recorder.Events))
+	}
+	if ready := meta.FindStatusCondition(role.Status.Conditions, utils.ReadyConditionType); ready == nil ||
+		ready.Status != metav1.ConditionFalse || ready.Reason != InsufficientPrivilegesReason {
+		t.Errorf("Expected the Ready condition to be False with reason InsufficientPrivileges, got %v", ready)
+	}
+	CheckSecurityPrivileges(context.Background(), cli, recorder, role, &role.Status.Conditions, esClient, targetInstance)
+	if fakeES.CountRequests("POST", "/_security/user/_has_privileges")+fakeES.CountRequests("GET", "/_security/user/_has_privileges") != 1 {
+		t.Errorf("Expected the result to be reused, got %v", fakeES.Requests())
+	}
+
+	fakeES.SetMissingPrivileges()
+	defaultPrivilegesPreflight = newPrivilegesPreflight(time.Now)
+	if permitted, _ := CheckSecurityPrivileges(context.Background(), cli, recorder, role, &role.Status.Conditions, esClient, targetInstance); !permitted {
+		t.Fatal("Expected the reconcile to proceed once the privileges are granted")
+	}
+	if len(role.Status.Conditions) != 0 {
+		t.Errorf("Expected the InsufficientPrivileges and Ready conditions to be removed, got %v", role.Status.Conditions)
+	}
+}
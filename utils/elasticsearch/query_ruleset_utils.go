@@ -0,0 +1,118 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// QueryRuleTypes are the types of the rules of a query ruleset
+var QueryRuleTypes = []string{"pinned", "exclude"}
+
+// QueryRuleCriteriaTypes are the types of the criteria of a query rule
+var QueryRuleCriteriaTypes = []string{"always", "exact", "fuzzy", "prefix", "suffix", "contains", "lt", "lte", "gt", "gte"}
+
+func DeleteQueryRuleset(esClient *elasticsearch.Client, queryRulesetName string) (ctrl.Result, error) {
+	res, err := esClient.QueryRulesDeleteRuleset(queryRulesetName)
+	if err != nil || (res.IsError() && res.StatusCode != http.StatusNotFound) {
+		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+	return ctrl.Result{}, nil
+}
+
+func UpsertQueryRuleset(esClient *elasticsearch.Client, queryRuleset v1alpha1.QueryRuleset) (ctrl.Result, error) {
+	res, err := esClient.QueryRulesPutRuleset(strings.NewReader(queryRuleset.Spec.GetBody()), utils.RemoteName(&queryRuleset))
+	if err != nil || res.IsError() {
+		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+	return ctrl.Result{}, nil
+}
+
+// ValidateQueryRulesetBody checks the ids, types, criteria and actions of the rules of a query ruleset body.
+// Bodies which are no JSON object are left to Elasticsearch.
+func ValidateQueryRulesetBody(body string, path *field.Path) field.ErrorList {
+	var parsed struct {
+		Rules []map[string]interface{} `json:"rules"`
+	}
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	ruleIDs := map[string]bool{}
+	for i, rule := range parsed.Rules {
+		rulePath := path.Child("rules").Index(i)
+		ruleID, _ := rule["rule_id"].(string)
+		if ruleID == "" {
+			allErrs = append(allErrs, field.Required(rulePath.Child("rule_id"), ""))
+		} else if ruleIDs[ruleID] {
+			allErrs = append(allErrs, field.Duplicate(rulePath.Child("rule_id"), ruleID))
+		}
+		ruleIDs[ruleID] = true
+
+		if ruleType, _ := rule["type"].(string); !slices.Contains(QueryRuleTypes, ruleType) {
+			allErrs = append(allErrs, field.NotSupported(rulePath.Child("type"), rule["type"], QueryRuleTypes))
+		}
+		allErrs = append(allErrs, validateQueryRuleCriteria(rule["criteria"], rulePath.Child("criteria"))...)
+
+		actions, _ := rule["actions"].(map[string]interface{})
+		_, hasIDs := actions["ids"]
+		_, hasDocs := actions["docs"]
+		if hasIDs == hasDocs {
+			allErrs = append(allErrs, field.Invalid(rulePath.Child("actions"), rule["actions"], "exactly one of ids and docs has to be set"))
+		}
+	}
+	return allErrs
+}
+
+// validateQueryRuleCriteria checks the criteria of a rule, given as a list or as a single criterion
+func validateQueryRuleCriteria(criteria interface{}, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	check := func(criterion map[string]interface{}, criterionPath *field.Path) {
+		criteriaType, _ := criterion["type"].(string)
+		if !slices.Contains(QueryRuleCriteriaTypes, criteriaType) {
+			allErrs = append(allErrs, field.NotSupported(criterionPath.Child("type"), criterion["type"], QueryRuleCriteriaTypes))
+			return
+		}
+		if criteriaType == "always" {
+			return
+		}
+		if _, ok := criterion["metadata"].(string); !ok {
+			allErrs = append(allErrs, field.Required(criterionPath.Child("metadata"), fmt.Sprintf("criteria of type %s match a metadata field", criteriaType)))
+		}
+		if values, ok := criterion["values"].([]interface{}); !ok || len(values) == 0 {
+			allErrs = append(allErrs, field.Required(criterionPath.Child("values"), fmt.Sprintf("criteria of type %s match values", criteriaType)))
+		}
+	}
+
+	switch criteria := criteria.(type) {
+	case map[string]interface{}:
+		check(criteria, path)
+	case []interface{}:
+		if len(criteria) == 0 {
+			allErrs = append(allErrs, field.Required(path, ""))
+		}
+		for i, value := range criteria {
+			criterion, ok := value.(map[string]interface{})
+			if !ok {
+				allErrs = append(allErrs, field.Invalid(path.Index(i), value, "a criterion has to be an object"))
+				continue
+			}
+			check(criterion, path.Index(i))
+		}
+	default:
+		allErrs = append(allErrs, field.Required(path, ""))
+	}
+	return allErrs
+}
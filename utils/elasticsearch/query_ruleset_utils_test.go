@@ -0,0 +1,124 @@
+package elasticsearch
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestQueryRuleset(t *testing.T) {
+	tests := []struct {
+		name             string
+		delete           bool
+		serverStatusCode int
+		wantErr          bool
+	}{
+		{name: "put", serverStatusCode: http.StatusOK},
+		{name: "put rejected", serverStatusCode: http.StatusBadRequest, wantErr: true},
+		{name: "delete", delete: true, serverStatusCode: http.StatusOK},
+		{name: "delete already gone", delete: true, serverStatusCode: http.StatusNotFound},
+		{name: "delete failed", delete: true, serverStatusCode: http.StatusInternalServerError, wantErr: true},
+	}
+
+	body := `{"rules": [{"rule_id": "pin", "type": "pinned", "criteria": {"type": "always"}, "actions": {"ids": ["1"]}}]}`
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				wantMethod, wantBody := http.MethodPut, body
+				if tt.delete {
+					wantMethod, wantBody = http.MethodDelete, ""
+				}
+				requestBody, _ := io.ReadAll(r.Body)
+				if r.Method != wantMethod || r.URL.Path != "/_query_rules/products" || string(requestBody) != wantBody {
+					t.Errorf("Unexpected request %s %s %s", r.Method, r.URL.RequestURI(), requestBody)
+				}
+				w.Header().Set("X-Elastic-Product", "Elasticsearch")
+				w.WriteHeader(tt.serverStatusCode)
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			esClient, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{server.URL}})
+			if err != nil {
+				t.Fatalf("Failed to create ES client: %v", err)
+			}
+
+			if tt.delete {
+				_, err = DeleteQueryRuleset(esClient, "products")
+			} else {
+				_, err = UpsertQueryRuleset(esClient, v1alpha1.QueryRuleset{
+					ObjectMeta: metav1.ObjectMeta{Name: "products"},
+					Spec:       v1alpha1.QueryRulesetSpec{Body: body},
+				})
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateQueryRulesetBody(t *testing.T) {
+	path := field.NewPath("spec").Child("body")
+	valid := []string{
+		`{"rules": [{"rule_id": "pin", "type": "pinned", "criteria": [{"type": "contains", "metadata": "user_query", "values": ["pug"]}], "actions": {"ids": ["1", "2"]}}]}`,
+		`{"rules": [{"rule_id": "hide", "type": "exclude", "criteria": {"type": "always"}, "actions": {"docs": [{"_index": "products", "_id": "1"}]}}]}`,
+		`not json`,
+	}
+	for _, body := range valid {
+		if errs := ValidateQueryRulesetBody(body, path); len(errs) > 0 {
+			t.Errorf("ValidateQueryRulesetBody(%s) = %v", body, errs)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantFields []string
+	}{
+		{
+			name:       "rule type",
+			body:       `{"rules": [{"rule_id": "pin", "type": "boost", "criteria": {"type": "always"}, "actions": {"ids": ["1"]}}]}`,
+			wantFields: []string{"spec.body.rules[0].type"},
+		},
+		{
+			name:       "duplicate rule id",
+			body:       `{"rules": [{"rule_id": "pin", "type": "pinned", "criteria": {"type": "always"}, "actions": {"ids": ["1"]}}, {"rule_id": "pin", "type": "exclude", "criteria": {"type": "always"}, "actions": {"ids": ["2"]}}]}`,
+			wantFields: []string{"spec.body.rules[1].rule_id"},
+		},
+		{
+			name:       "criteria",
+			body:       `{"rules": [{"rule_id": "pin", "type": "pinned", "criteria": [{"type": "matches"}, {"type": "exact", "metadata": "user_query"}], "actions": {"ids": ["1"]}}]}`,
+			wantFields: []string{"spec.body.rules[0].criteria[0].type", "spec.body.rules[0].criteria[1].values"},
+		},
+		{
+			name:       "actions",
+			body:       `{"rules": [{"type": "pinned", "criteria": {"type": "always"}, "actions": {"ids": ["1"], "docs": []}}]}`,
+			wantFields: []string{"spec.body.rules[0].rule_id", "spec.body.rules[0].actions"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateQueryRulesetBody(tt.body, path)
+			var fields []string
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			if len(fields) != len(tt.wantFields) {
+				t.Fatalf("ValidateQueryRulesetBody() = %v, want errors for %v", errs, tt.wantFields)
+			}
+			for i := range fields {
+				if fields[i] != tt.wantFields[i] {
+					t.Errorf("ValidateQueryRulesetBody() = %v, want errors for %v", errs, tt.wantFields)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"strings"
+
+	"eck-custom-resources/utils"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// PutRawRequest sends a PUT request with the body to the path of the target instance
+func PutRawRequest(esClient *elasticsearch.Client, path string, body string) (ctrl.Result, error) {
+	res, err := performRawRequest(esClient, http.MethodPut, path, body)
+	if err != nil || res.IsError() {
+		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+	return ctrl.Result{}, nil
+}
+
+// DeleteRawRequest sends a DELETE request to the path of the target instance, objects which are already gone are
+// not reported as error
+func DeleteRawRequest(esClient *elasticsearch.Client, path string) (ctrl.Result, error) {
+	res, err := performRawRequest(esClient, http.MethodDelete, path, "")
+	if err != nil || (res.IsError() && res.StatusCode != http.StatusNotFound) {
+		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+	return ctrl.Result{}, nil
+}
+
+func performRawRequest(esClient *elasticsearch.Client, method string, path string, body string) (*esapi.Response, error) {
+	httpRequest, err := http.NewRequest(method, path, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != "" {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+	res, err := esClient.Perform(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	return &esapi.Response{StatusCode: res.StatusCode, Header: res.Header, Body: res.Body}, nil
+}
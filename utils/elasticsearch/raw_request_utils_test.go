@@ -0,0 +1,58 @@
+package elasticsearch
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+func TestRawRequest(t *testing.T) {
+	tests := []struct {
+		name             string
+		delete           bool
+		serverStatusCode int
+		wantErr          bool
+	}{
+		{name: "put", serverStatusCode: http.StatusOK},
+		{name: "put rejected", serverStatusCode: http.StatusBadRequest, wantErr: true},
+		{name: "delete", delete: true, serverStatusCode: http.StatusOK},
+		{name: "delete already gone", delete: true, serverStatusCode: http.StatusNotFound},
+		{name: "delete failed", delete: true, serverStatusCode: http.StatusInternalServerError, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				wantMethod, wantBody := http.MethodPut, `{"enabled": true}`
+				if tt.delete {
+					wantMethod, wantBody = http.MethodDelete, ""
+				}
+				body, _ := io.ReadAll(r.Body)
+				if r.Method != wantMethod || r.URL.Path != "/_security/role_mapping/admins" || r.URL.RawQuery != "refresh=true" || string(body) != wantBody {
+					t.Errorf("Unexpected request %s %s %s", r.Method, r.URL.RequestURI(), body)
+				}
+				w.Header().Set("X-Elastic-Product", "Elasticsearch")
+				w.WriteHeader(tt.serverStatusCode)
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			esClient, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{server.URL}})
+			if err != nil {
+				t.Fatalf("Failed to create ES client: %v", err)
+			}
+
+			if tt.delete {
+				_, err = DeleteRawRequest(esClient, "/_security/role_mapping/admins?refresh=true")
+			} else {
+				_, err = PutRawRequest(esClient, "/_security/role_mapping/admins?refresh=true", `{"enabled": true}`)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,26 +3,114 @@ package elasticsearch
 import (
 	"eck-custom-resources/api/es.eck/v1alpha1"
 	"eck-custom-resources/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-func DeleteRole(esClient *elasticsearch.Client, roleName string) (ctrl.Result, error) {
-	res, err := esClient.Security.DeleteRole(roleName)
+// DeleteRole deletes the role, refresh is the refresh parameter of the request
+func DeleteRole(esClient *elasticsearch.Client, roleName string, refresh string) (ctrl.Result, error) {
+	res, err := esClient.Security.DeleteRole(roleName, esClient.Security.DeleteRole.WithRefresh(refresh))
 	if err != nil || res.IsError() {
 		return utils.GetRequeueResult(), err
 	}
 	return ctrl.Result{}, nil
 }
 
-func UpsertRole(esClient *elasticsearch.Client, role v1alpha1.ElasticsearchRole) (ctrl.Result, error) {
-	res, err := esClient.Security.PutRole(role.Name, strings.NewReader(role.Spec.Body))
+// UpsertRole creates or updates the role with the body, refresh is the refresh parameter of the request
+func UpsertRole(esClient *elasticsearch.Client, role v1alpha1.ElasticsearchRole, body string, refresh string) (ctrl.Result, error) {
+	res, err := esClient.Security.PutRole(utils.RemoteName(&role), strings.NewReader(body), esClient.Security.PutRole.WithRefresh(refresh))
 
 	if err != nil || res.IsError() {
 		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
 	}
 
+	if legacyName, ok := utils.LegacyName(&role); ok {
+		if err := deleteLegacyObject(esClient.Security.DeleteRole(legacyName)); err != nil {
+			return utils.GetRequeueResult(), err
+		}
+	}
 	return ctrl.Result{}, nil
 }
+
+// KibanaApplication is the application Kibana checks privileges of, for Kibana using the default .kibana index
+const KibanaApplication = "kibana-.kibana"
+
+// InjectKibanaPrivileges returns the role body with the application privileges of spec.kibanaPrivileges added to
+// its applications
+func InjectKibanaPrivileges(body string, privileges []v1alpha1.KibanaPrivilege) (string, error) {
+	if len(privileges) == 0 {
+		return body, nil
+	}
+	role := make(map[string]interface{})
+	if strings.TrimSpace(body) != "" {
+		if err := json.Unmarshal([]byte(body), &role); err != nil {
+			return "", err
+		}
+	}
+	applications, _ := role["applications"].([]interface{})
+	for i, privilege := range privileges {
+		application, err := KibanaApplicationPrivileges(privilege)
+		if err != nil {
+			return "", fmt.Errorf("spec.kibanaPrivileges[%d]: %w", i, err)
+		}
+		applications = append(applications, application)
+	}
+	role["applications"] = applications
+
+	marshalled, err := json.Marshal(role)
+	if err != nil {
+		return "", err
+	}
+	return string(marshalled), nil
+}
+
+// KibanaApplicationPrivileges returns the application privileges of the Kibana application granting the privilege:
+// the base privilege becomes all/read in all spaces or space_all/space_read in the given spaces, features become
+// feature_<id>.<privilege>
+func KibanaApplicationPrivileges(privilege v1alpha1.KibanaPrivilege) (map[string]interface{}, error) {
+	if privilege.Base != "" && len(privilege.Features) > 0 {
+		return nil, errors.New("base and features can not be granted together")
+	}
+	if privilege.Base == "" && len(privilege.Features) == 0 {
+		return nil, errors.New("either base or features has to be set")
+	}
+
+	resources := []string{"*"}
+	if len(privilege.Spaces) > 0 {
+		resources = make([]string, len(privilege.Spaces))
+		for i, space := range privilege.Spaces {
+			if space == "*" {
+				return nil, errors.New("all spaces are granted by leaving spaces empty")
+			}
+			resources[i] = "space:" + space
+		}
+	}
+
+	var privileges []string
+	if privilege.Base != "" {
+		if len(privilege.Spaces) > 0 {
+			privileges = []string{"space_" + privilege.Base}
+		} else {
+			privileges = []string{privilege.Base}
+		}
+	}
+	for feature, featurePrivilege := range privilege.Features {
+		if feature == "" || featurePrivilege == "" {
+			return nil, fmt.Errorf("invalid feature privilege %q: %q", feature, featurePrivilege)
+		}
+		privileges = append(privileges, fmt.Sprintf("feature_%s.%s", feature, featurePrivilege))
+	}
+	sort.Strings(privileges)
+
+	return map[string]interface{}{
+		"application": KibanaApplication,
+		"privileges":  privileges,
+		"resources":   resources,
+	}, nil
+}
@@ -1,6 +1,7 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -71,7 +72,7 @@ func TestDeleteRole(t *testing.T) {
 				t.Fatalf("Failed to create ES client: %v", err)
 			}
 
-			result, err := DeleteRole(esClient, tt.roleName)
+			result, err := DeleteRole(esClient, tt.roleName, "")
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DeleteRole() error = %v, wantErr %v", err, tt.wantErr)
@@ -181,7 +182,7 @@ func TestUpsertRole(t *testing.T) {
 				t.Fatalf("Failed to create ES client: %v", err)
 			}
 
-			result, err := UpsertRole(esClient, tt.role)
+			result, err := UpsertRole(esClient, tt.role, tt.role.Spec.Body, "")
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("UpsertRole() error = %v, wantErr %v", err, tt.wantErr)
@@ -194,3 +195,71 @@ func TestUpsertRole(t *testing.T) {
 		})
 	}
 }
+
+func TestKibanaApplicationPrivileges(t *testing.T) {
+	tests := []struct {
+		name      string
+		privilege v1alpha1.KibanaPrivilege
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "base in all spaces",
+			privilege: v1alpha1.KibanaPrivilege{Base: "read"},
+			want:      `{"application":"kibana-.kibana","privileges":["read"],"resources":["*"]}`,
+		},
+		{
+			name:      "base in spaces",
+			privilege: v1alpha1.KibanaPrivilege{Base: "all", Spaces: []string{"ops", "dev"}},
+			want:      `{"application":"kibana-.kibana","privileges":["space_all"],"resources":["space:ops","space:dev"]}`,
+		},
+		{
+			name:      "features",
+			privilege: v1alpha1.KibanaPrivilege{Spaces: []string{"ops"}, Features: map[string]string{"discover": "read", "dashboard": "all"}},
+			want:      `{"application":"kibana-.kibana","privileges":["feature_dashboard.all","feature_discover.read"],"resources":["space:ops"]}`,
+		},
+		{name: "base and features", privilege: v1alpha1.KibanaPrivilege{Base: "read", Features: map[string]string{"discover": "all"}}, wantErr: true},
+		{name: "nothing granted", privilege: v1alpha1.KibanaPrivilege{Spaces: []string{"ops"}}, wantErr: true},
+		{name: "wildcard space", privilege: v1alpha1.KibanaPrivilege{Base: "read", Spaces: []string{"*"}}, wantErr: true},
+		{name: "empty feature privilege", privilege: v1alpha1.KibanaPrivilege{Features: map[string]string{"discover": ""}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := KibanaApplicationPrivileges(tt.privilege)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("KibanaApplicationPrivileges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			marshalled, _ := json.Marshal(got)
+			if string(marshalled) != tt.want {
+				t.Errorf("KibanaApplicationPrivileges() = %s, want %s", marshalled, tt.want)
+			}
+		})
+	}
+}
+
+func TestInjectKibanaPrivileges(t *testing.T) {
+	body := `{"cluster": ["monitor"], "applications": [{"application": "custom", "privileges": ["use"], "resources": ["*"]}]}`
+	got, err := InjectKibanaPrivileges(body, []v1alpha1.KibanaPrivilege{{Base: "read"}})
+	if err != nil {
+		t.Fatalf("InjectKibanaPrivileges() error = %v", err)
+	}
+	want := `{"applications":[{"application":"custom","privileges":["use"],"resources":["*"]},` +
+		`{"application":"kibana-.kibana","privileges":["read"],"resources":["*"]}],"cluster":["monitor"]}`
+	if got != want {
+		t.Errorf("InjectKibanaPrivileges() = %s, want %s", got, want)
+	}
+
+	if got, err := InjectKibanaPrivileges(body, nil); err != nil || got != body {
+		t.Errorf("Expected the body to be unchanged without Kibana privileges, got %s, %v", got, err)
+	}
+	if got, err := InjectKibanaPrivileges("", []v1alpha1.KibanaPrivilege{{Features: map[string]string{"discover": "read"}}}); err != nil ||
+		got != `{"applications":[{"application":"kibana-.kibana","privileges":["feature_discover.read"],"resources":["*"]}]}` {
+		t.Errorf("InjectKibanaPrivileges() of an empty body = %s, %v", got, err)
+	}
+	if _, err := InjectKibanaPrivileges(body, []v1alpha1.KibanaPrivilege{{}}); err == nil {
+		t.Error("Expected an error for an invalid Kibana privilege")
+	}
+}
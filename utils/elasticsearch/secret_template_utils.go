@@ -0,0 +1,173 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+	"eck-custom-resources/utils/template"
+
+	k8sv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// UserSecretLabel is set on the Secrets generated for ElasticsearchUsers to the name of the ElasticsearchUser
+const UserSecretLabel = "es.eck.github.com/elasticsearchuser"
+
+// apikeySecretKeys are the keys of the Secret of an API key written by the operator, spec.secretTemplate.data can
+// not override them
+var apikeySecretKeys = [...]string{"id", "name", "apikey"}
+
+// RenderSecretTemplate renders the data of spec.secretTemplate, each value as a Helm template with values as .Values
+func RenderSecretTemplate(secretTemplate v1alpha1.SecretTemplate, values map[string]interface{}) (map[string][]byte, error) {
+	data := make(map[string][]byte, len(secretTemplate.Data))
+	for _, key := range slices.Sorted(maps.Keys(secretTemplate.Data)) {
+		rendered, err := template.RenderBodyWithValues(secretTemplate.Data[key], values, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render key %s of spec.secretTemplate: %w", key, err)
+		}
+		data[key] = []byte(rendered)
+	}
+	return data, nil
+}
+
+// applySecretTemplateMetadata adds the labels and annotations of spec.secretTemplate to the Secret
+func applySecretTemplateMetadata(sec *k8sv1.Secret, secretTemplate v1alpha1.SecretTemplate) {
+	if len(secretTemplate.Labels) > 0 && sec.Labels == nil {
+		sec.Labels = make(map[string]string, len(secretTemplate.Labels))
+	}
+	maps.Copy(sec.Labels, secretTemplate.Labels)
+	if len(secretTemplate.Annotations) > 0 && sec.Annotations == nil {
+		sec.Annotations = make(map[string]string, len(secretTemplate.Annotations))
+	}
+	maps.Copy(sec.Annotations, secretTemplate.Annotations)
+}
+
+// ApikeySecretTemplateValues returns the values spec.secretTemplate of an ElasticsearchApikey is rendered with, taken
+// from the Secret of the API key. The key itself is decoded from the encoded API key, as Elasticsearch only returns
+// it on creation.
+func ApikeySecretTemplateValues(data map[string][]byte, url string) map[string]interface{} {
+	encoded := string(data["apikey"])
+	apiKey := ""
+	if decoded, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+		_, apiKey, _ = strings.Cut(string(decoded), ":")
+	}
+	return map[string]interface{}{
+		"id":      string(data["id"]),
+		"name":    string(data["name"]),
+		"apiKey":  apiKey,
+		"encoded": encoded,
+		"url":     url,
+	}
+}
+
+// ApplyApikeySecretTemplate adds the keys of the secret formats and the labels, annotations and rendered data of
+// spec.secretTemplate to the Secret of the API key. Keys which are no longer declared are removed. A missing Secret is left to the creation of the
+// API key. It returns whether the Secret was changed.
+func ApplyApikeySecretTemplate(cli client.Client, ctx context.Context, apikey *v1alpha1.ElasticsearchApikey, url string) (bool, error) {
+	sec, err := GetAPIKeySecret(cli, ctx, apikey.Namespace, apikey.GetSecretName())
+	if err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if err := VerifyApikeySecretOwnership(sec, apikey); err != nil {
+		return false, err
+	}
+
+	data := make(map[string][]byte, len(apikeySecretKeys))
+	for _, key := range apikeySecretKeys {
+		if value, ok := sec.Data[key]; ok {
+			data[key] = value
+		}
+	}
+	original := sec.DeepCopy()
+	values := ApikeySecretTemplateValues(data, url)
+	// Keys rendered by spec.secretTemplate take precedence over those of the secret formats
+	maps.Copy(data, RenderApikeySecretFormats(*apikey, values))
+	if apikey.Spec.SecretTemplate != nil {
+		rendered, err := RenderSecretTemplate(*apikey.Spec.SecretTemplate, values)
+		if err != nil {
+			return false, err
+		}
+		for key, value := range rendered {
+			if slices.Contains(apikeySecretKeys[:], key) {
+				return false, fmt.Errorf("key %s of spec.secretTemplate is reserved for the API key", key)
+			}
+			data[key] = value
+		}
+		applySecretTemplateMetadata(sec, *apikey.Spec.SecretTemplate)
+	}
+	sec.Data = data
+
+	if equality.Semantic.DeepEqual(original.Data, sec.Data) && equality.Semantic.DeepEqual(original.ObjectMeta, sec.ObjectMeta) {
+		return false, nil
+	}
+	return true, cli.Patch(ctx, sec, client.MergeFrom(original))
+}
+
+// ReconcileUserSecret creates or updates the Secret generated from spec.secretTemplate of the ElasticsearchUser with
+// the credentials of the user. The Secret is labeled with and controlled by the ElasticsearchUser, so it is garbage
+// collected with it; a Secret of the same name managed by anything else is left untouched and an error is returned.
+func ReconcileUserSecret(cli client.Client, ctx context.Context, user *v1alpha1.ElasticsearchUser, url string) error {
+	if user.Spec.SecretTemplate == nil {
+		return nil
+	}
+	var credentials k8sv1.Secret
+	if err := getUserSecret(cli, ctx, user.Namespace, *user, &credentials); err != nil {
+		return err
+	}
+	data, err := RenderSecretTemplate(user.Spec.SecretTemplate.SecretTemplate, map[string]interface{}{
+		"username": user.Name,
+		"password": string(credentials.Data[user.Name]),
+		"url":      url,
+	})
+	if err != nil {
+		return err
+	}
+
+	var sec k8sv1.Secret
+	key := client.ObjectKey{Namespace: user.Namespace, Name: user.GetGeneratedSecretName()}
+	if err := cli.Get(ctx, key, &sec); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		sec = k8sv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace,
+				Name:      key.Name,
+				Labels:    map[string]string{UserSecretLabel: user.Name},
+			},
+			Type: k8sv1.SecretTypeOpaque,
+			Data: data,
+		}
+		maps.Copy(sec.Labels, utils.OperatorLabels())
+		applySecretTemplateMetadata(&sec, user.Spec.SecretTemplate.SecretTemplate)
+		if err := controllerutil.SetControllerReference(user, &sec, cli.Scheme()); err != nil {
+			return err
+		}
+		return cli.Create(ctx, &sec)
+	}
+
+	if err := utils.CheckOperatorOwnership("Secret", &sec); err != nil {
+		return err
+	}
+	if owner := metav1.GetControllerOf(&sec); owner == nil || owner.Kind != "ElasticsearchUser" || owner.Name != user.Name ||
+		(user.UID != "" && owner.UID != user.UID) {
+		return fmt.Errorf("secret %s/%s already exists and is not managed by ElasticsearchUser %s", sec.Namespace, sec.Name, user.Name)
+	}
+	original := sec.DeepCopy()
+	sec.Data = data
+	applySecretTemplateMetadata(&sec, user.Spec.SecretTemplate.SecretTemplate)
+	if equality.Semantic.DeepEqual(original.Data, sec.Data) && equality.Semantic.DeepEqual(original.ObjectMeta, sec.ObjectMeta) {
+		return nil
+	}
+	return cli.Patch(ctx, &sec, client.MergeFrom(original))
+}
@@ -0,0 +1,126 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplyApikeySecretTemplate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	_ = k8sv1.AddToScheme(scheme)
+	ctx := context.Background()
+
+	apikey := &v1alpha1.ElasticsearchApikey{
+		ObjectMeta: metav1.ObjectMeta{Name: "beats", Namespace: "default", UID: "beats-uid"},
+		Spec: v1alpha1.ElasticsearchApikeySpec{SecretTemplate: &v1alpha1.SecretTemplate{
+			Labels: map[string]string{"app": "filebeat"},
+			Data: map[string]string{
+				"output.yml": `output.elasticsearch: {hosts: [{{ .Values.url | quote }}], api_key: "{{ .Values.id }}:{{ .Values.apiKey }}"}`,
+			},
+		}},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).Build()
+	encoded := base64.StdEncoding.EncodeToString([]byte("key-id:secret"))
+	data := map[string][]byte{"id": []byte("key-id"), "name": []byte("beats"), "apikey": []byte(encoded)}
+	if err := CreateApikeySecret(cli, ctx, apikey, data); err != nil {
+		t.Fatalf("CreateApikeySecret() error = %v", err)
+	}
+
+	changed, err := ApplyApikeySecretTemplate(cli, ctx, apikey, "https://es:9200")
+	if err != nil || !changed {
+		t.Fatalf("ApplyApikeySecretTemplate() = %v, %v", changed, err)
+	}
+	sec, _ := GetAPIKeySecret(cli, ctx, "default", "beats")
+	want := `output.elasticsearch: {hosts: ["https://es:9200"], api_key: "key-id:secret"}`
+	if string(sec.Data["output.yml"]) != want {
+		t.Errorf("Expected the output configuration to be rendered, got %s", sec.Data["output.yml"])
+	}
+	if sec.Labels["app"] != "filebeat" || sec.Labels[ApikeySecretLabel] != "beats" {
+		t.Errorf("Expected the template labels to be added, got %v", sec.Labels)
+	}
+	if changed, err := ApplyApikeySecretTemplate(cli, ctx, apikey, "https://es:9200"); err != nil || changed {
+		t.Errorf("Expected an applied template not to change the Secret, got %v, %v", changed, err)
+	}
+
+	apikey.Spec.SecretTemplate.Data = map[string]string{"id": "{{ .Values.name }}"}
+	if _, err := ApplyApikeySecretTemplate(cli, ctx, apikey, ""); err == nil {
+		t.Error("Expected the keys of the API key to be reserved")
+	}
+
+	apikey.Spec.SecretTemplate = nil
+	if _, err := ApplyApikeySecretTemplate(cli, ctx, apikey, ""); err != nil {
+		t.Fatalf("ApplyApikeySecretTemplate() error = %v", err)
+	}
+	sec, _ = GetAPIKeySecret(cli, ctx, "default", "beats")
+	if _, ok := sec.Data["output.yml"]; ok || string(sec.Data["apikey"]) != encoded {
+		t.Errorf("Expected the rendered keys to be removed and the API key to be kept, got %v", sec.Data)
+	}
+}
+
+func TestReconcileUserSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	_ = k8sv1.AddToScheme(scheme)
+	ctx := context.Background()
+
+	credentials := &k8sv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "passwords", Namespace: "default"},
+		Data:       map[string][]byte{"logstash": []byte("s3cret")},
+	}
+	foreign := &k8sv1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "taken", Namespace: "default"}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(credentials, foreign).Build()
+
+	user := &v1alpha1.ElasticsearchUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "logstash", Namespace: "default", UID: "logstash-uid"},
+		Spec: v1alpha1.ElasticsearchUserSpec{
+			SecretName: "passwords",
+			SecretTemplate: &v1alpha1.UserSecretTemplate{SecretTemplate: v1alpha1.SecretTemplate{
+				Annotations: map[string]string{"reloader": "true"},
+				Data: map[string]string{
+					"elasticsearch.yml": "username: {{ .Values.username }}\npassword: {{ .Values.password }}",
+				},
+			}},
+		},
+	}
+	if err := ReconcileUserSecret(cli, ctx, user, ""); err != nil {
+		t.Fatalf("ReconcileUserSecret() error = %v", err)
+	}
+	sec, err := GetAPIKeySecret(cli, ctx, "default", "logstash-credentials")
+	if err != nil {
+		t.Fatalf("Expected the Secret to be generated: %v", err)
+	}
+	if string(sec.Data["elasticsearch.yml"]) != "username: logstash\npassword: s3cret" {
+		t.Errorf("Expected the credentials to be rendered, got %s", sec.Data["elasticsearch.yml"])
+	}
+	if owner := metav1.GetControllerOf(sec); owner == nil || owner.UID != "logstash-uid" {
+		t.Errorf("Expected the Secret to be controlled by the ElasticsearchUser, got %v", owner)
+	}
+	if sec.Annotations["reloader"] != "true" || sec.Labels[UserSecretLabel] != "logstash" {
+		t.Errorf("Expected the Secret to be labeled and annotated, got %v and %v", sec.Labels, sec.Annotations)
+	}
+
+	credentials.Data["logstash"] = []byte("rotated")
+	if err := cli.Update(ctx, credentials); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReconcileUserSecret(cli, ctx, user, ""); err != nil {
+		t.Fatalf("ReconcileUserSecret() error = %v", err)
+	}
+	if sec, _ := GetAPIKeySecret(cli, ctx, "default", "logstash-credentials"); string(sec.Data["elasticsearch.yml"]) != "username: logstash\npassword: rotated" {
+		t.Errorf("Expected the rotated password to be rendered, got %s", sec.Data["elasticsearch.yml"])
+	}
+
+	user.Spec.SecretTemplate.Name = "taken"
+	if err := ReconcileUserSecret(cli, ctx, user, ""); err == nil {
+		t.Error("Expected a foreign Secret to be rejected")
+	}
+}
@@ -0,0 +1,83 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AdoptAnnotation set to "true" on an ElasticsearchRole or ElasticsearchUser adopts the role or user of the same name
+// existing in Elasticsearch before the resource manages it, instead of overwriting it
+const AdoptAnnotation = "eck.github.com/adopt"
+
+// GetRoleDefinition returns the definition of the role in Elasticsearch, nil if it does not exist
+func GetRoleDefinition(esClient *elasticsearch.Client, name string) (map[string]interface{}, error) {
+	res, err := esClient.Security.GetRole(esClient.Security.GetRole.WithName(name))
+	return securityDefinition(res, err, name)
+}
+
+// GetUserDefinition returns the definition of the user in Elasticsearch, nil if it does not exist. It does not
+// contain the password.
+func GetUserDefinition(esClient *elasticsearch.Client, name string) (map[string]interface{}, error) {
+	res, err := esClient.Security.GetUser(esClient.Security.GetUser.WithUsername(name))
+	return securityDefinition(res, err, name)
+}
+
+// securityDefinition reads the object of the name from the response of the get role or get user API, which maps
+// names to definitions
+func securityDefinition(res *esapi.Response, err error, name string) (map[string]interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if res.IsError() {
+		return nil, GetClientErrorOrResponseError(err, res)
+	}
+	var definitions map[string]map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&definitions); err != nil {
+		return nil, err
+	}
+	return definitions[name], nil
+}
+
+// ResolveSecurityAdoption decides whether the body of an ElasticsearchRole or ElasticsearchUser is to be applied.
+// managed reports whether the resource already manages the role or user, remote returns its definition in
+// Elasticsearch, nil if it does not exist. A resource annotated with AdoptAnnotation which does not manage the role
+// or user yet adopts an existing one: it is left untouched and the hash of its definition is recorded in the returned
+// adoption, until the generation of the resource changes. It returns whether the body is to be applied and the
+// adoption to record in the status.
+func ResolveSecurityAdoption(obj client.Object, managed bool, adoption *v1alpha1.SecurityAdoption, remote func() (map[string]interface{}, error)) (bool, *v1alpha1.SecurityAdoption, error) {
+	if managed {
+		return adoption == nil || adoption.Generation != obj.GetGeneration(), adoption, nil
+	}
+	if obj.GetAnnotations()[AdoptAnnotation] != "true" {
+		return true, adoption, nil
+	}
+
+	definition, err := remote()
+	if err != nil {
+		return false, adoption, err
+	}
+	if definition == nil {
+		return true, adoption, nil
+	}
+	hash, err := utils.SpecHash(definition)
+	if err != nil {
+		return false, adoption, err
+	}
+	return false, &v1alpha1.SecurityAdoption{
+		Hash:       hash,
+		Generation: obj.GetGeneration(),
+		Time:       metav1.Now(),
+	}, nil
+}
@@ -0,0 +1,134 @@
+package elasticsearch
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetRoleDefinition(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		switch r.URL.Path {
+		case "/_security/role/logs-reader":
+			w.Write([]byte(`{"logs-reader": {"cluster": ["monitor"], "indices": [{"names": ["logs-*"], "privileges": ["read"]}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	esClient, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{server.URL}})
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	definition, err := GetRoleDefinition(esClient, "logs-reader")
+	if err != nil {
+		t.Fatalf("GetRoleDefinition() error = %v", err)
+	}
+	if definition == nil || definition["cluster"] == nil {
+		t.Errorf("Expected the definition of the role, got %v", definition)
+	}
+
+	definition, err = GetRoleDefinition(esClient, "missing")
+	if err != nil || definition != nil {
+		t.Errorf("Expected no definition and no error for a missing role, got %v, %v", definition, err)
+	}
+}
+
+func TestResolveSecurityAdoption(t *testing.T) {
+	existing := map[string]interface{}{"cluster": []interface{}{"monitor"}}
+	previous := &v1alpha1.SecurityAdoption{Hash: "abc", Generation: 1}
+
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		generation   int64
+		managed      bool
+		adoption     *v1alpha1.SecurityAdoption
+		remote       map[string]interface{}
+		remoteErr    error
+		wantUpsert   bool
+		wantAdoption bool
+		wantErr      bool
+	}{
+		{
+			name:       "not annotated overwrites",
+			remote:     existing,
+			wantUpsert: true,
+		},
+		{
+			name:        "annotated and missing creates",
+			annotations: map[string]string{AdoptAnnotation: "true"},
+			wantUpsert:  true,
+		},
+		{
+			name:         "annotated and existing adopts",
+			annotations:  map[string]string{AdoptAnnotation: "true"},
+			generation:   1,
+			remote:       existing,
+			wantAdoption: true,
+		},
+		{
+			name:        "annotated and lookup failing",
+			annotations: map[string]string{AdoptAnnotation: "true"},
+			remoteErr:   errors.New("unavailable"),
+			wantErr:     true,
+		},
+		{
+			name:         "adopted and unchanged",
+			annotations:  map[string]string{AdoptAnnotation: "true"},
+			generation:   1,
+			managed:      true,
+			adoption:     previous,
+			wantAdoption: true,
+		},
+		{
+			name:         "adopted and changed applies",
+			annotations:  map[string]string{AdoptAnnotation: "true"},
+			generation:   2,
+			managed:      true,
+			adoption:     previous,
+			wantUpsert:   true,
+			wantAdoption: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := &v1alpha1.ElasticsearchRole{ObjectMeta: metav1.ObjectMeta{
+				Name: "logs-reader", Annotations: tt.annotations, Generation: tt.generation,
+			}}
+			upsert, adoption, err := ResolveSecurityAdoption(role, tt.managed, tt.adoption, func() (map[string]interface{}, error) {
+				if tt.managed {
+					t.Error("Expected a managed role not to be looked up")
+				}
+				return tt.remote, tt.remoteErr
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveSecurityAdoption() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if upsert != tt.wantUpsert {
+				t.Errorf("ResolveSecurityAdoption() upsert = %v, want %v", upsert, tt.wantUpsert)
+			}
+			if (adoption != nil) != tt.wantAdoption {
+				t.Fatalf("ResolveSecurityAdoption() adoption = %v, want one %v", adoption, tt.wantAdoption)
+			}
+			if adoption != nil && adoption.Generation != 1 {
+				t.Errorf("Expected the generation of the adoption to be 1, got %d", adoption.Generation)
+			}
+			if adoption != nil && adoption.Hash == "" {
+				t.Error("Expected the hash of the definition to be recorded")
+			}
+		})
+	}
+}
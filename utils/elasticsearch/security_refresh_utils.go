@@ -0,0 +1,54 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"sync"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+)
+
+// SecurityRefresh is the refresh strategy of writes to users and roles of resources not setting spec.refresh. Empty
+// leaves it to Elasticsearch, which refreshes the security index right after every write.
+var SecurityRefresh v1alpha1.SecurityRefresh
+
+// SecurityRefreshBatchSize batches the refreshes of the security index: only every SecurityRefreshBatchSize-th write
+// to users and roles of an instance uses the refresh strategy, the writes in between are sent with refresh=false and
+// become visible with the next refresh. 0 and 1 disable batching.
+var SecurityRefreshBatchSize = 0
+
+// securityWrites counts the writes to the security index per instance since its last refresh
+var securityWrites = struct {
+	sync.Mutex
+	counts map[string]int
+}{counts: make(map[string]int)}
+
+// ValidateSecurityRefresh returns an error if refresh is not a refresh strategy of the security APIs
+func ValidateSecurityRefresh(refresh v1alpha1.SecurityRefresh) error {
+	switch refresh {
+	case "", v1alpha1.SecurityRefreshTrue, v1alpha1.SecurityRefreshWaitFor, v1alpha1.SecurityRefreshFalse:
+		return nil
+	}
+	return fmt.Errorf("invalid refresh strategy %q, expected %s, %s or %s", refresh,
+		v1alpha1.SecurityRefreshTrue, v1alpha1.SecurityRefreshWaitFor, v1alpha1.SecurityRefreshFalse)
+}
+
+// SecurityRefreshFor returns the refresh parameter of the next write to a user or role of the instance at url, refresh
+// being the strategy of the resource. Empty uses the default of Elasticsearch.
+func SecurityRefreshFor(url string, refresh v1alpha1.SecurityRefresh) string {
+	if refresh == "" {
+		refresh = SecurityRefresh
+	}
+	if refresh == v1alpha1.SecurityRefreshFalse || SecurityRefreshBatchSize <= 1 {
+		return string(refresh)
+	}
+
+	securityWrites.Lock()
+	defer securityWrites.Unlock()
+	count := securityWrites.counts[url] + 1
+	if count < SecurityRefreshBatchSize {
+		securityWrites.counts[url] = count
+		return string(v1alpha1.SecurityRefreshFalse)
+	}
+	delete(securityWrites.counts, url)
+	return string(refresh)
+}
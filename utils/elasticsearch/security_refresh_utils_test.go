@@ -0,0 +1,99 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func withSecurityRefresh(t *testing.T, refresh v1alpha1.SecurityRefresh, batchSize int) {
+	previousRefresh, previousBatchSize := SecurityRefresh, SecurityRefreshBatchSize
+	SecurityRefresh, SecurityRefreshBatchSize = refresh, batchSize
+	t.Cleanup(func() {
+		SecurityRefresh, SecurityRefreshBatchSize = previousRefresh, previousBatchSize
+	})
+}
+
+func TestSecurityRefreshFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		global    v1alpha1.SecurityRefresh
+		batchSize int
+		resource  v1alpha1.SecurityRefresh
+		want      []string
+	}{
+		{name: "default of Elasticsearch", want: []string{"", ""}},
+		{name: "global strategy", global: v1alpha1.SecurityRefreshWaitFor, want: []string{"wait_for", "wait_for"}},
+		{name: "resource overrides global", global: v1alpha1.SecurityRefreshWaitFor, resource: v1alpha1.SecurityRefreshTrue, want: []string{"true"}},
+		{name: "batched", global: v1alpha1.SecurityRefreshWaitFor, batchSize: 3,
+			want: []string{"false", "false", "wait_for", "false", "false", "wait_for"}},
+		{name: "batched default of Elasticsearch", batchSize: 2, want: []string{"false", "", "false", ""}},
+		{name: "false is not batched", batchSize: 2, resource: v1alpha1.SecurityRefreshFalse, want: []string{"false", "false", "false"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withSecurityRefresh(t, tt.global, tt.batchSize)
+			url := "https://" + tt.name
+			for i, want := range tt.want {
+				if got := SecurityRefreshFor(url, tt.resource); got != want {
+					t.Errorf("SecurityRefreshFor() write %d = %q, want %q", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSecurityRefreshFor_BatchesPerInstance(t *testing.T) {
+	withSecurityRefresh(t, v1alpha1.SecurityRefreshTrue, 2)
+	if got := SecurityRefreshFor("https://a", ""); got != "false" {
+		t.Errorf("First write to a = %q, want false", got)
+	}
+	if got := SecurityRefreshFor("https://b", ""); got != "false" {
+		t.Errorf("First write to b = %q, want false", got)
+	}
+	if got := SecurityRefreshFor("https://a", ""); got != "true" {
+		t.Errorf("Second write to a = %q, want true", got)
+	}
+}
+
+func TestValidateSecurityRefresh(t *testing.T) {
+	for _, refresh := range []v1alpha1.SecurityRefresh{"", "true", "wait_for", "false"} {
+		if err := ValidateSecurityRefresh(refresh); err != nil {
+			t.Errorf("ValidateSecurityRefresh(%q) error = %v", refresh, err)
+		}
+	}
+	if err := ValidateSecurityRefresh("immediate"); err == nil {
+		t.Error("Expected an error for an unknown strategy")
+	}
+}
+
+func TestUpsertRole_Refresh_FakeElasticsearch(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	role := v1alpha1.ElasticsearchRole{ObjectMeta: metav1.ObjectMeta{Name: "reader"}}
+	if _, err := UpsertRole(esClient, role, `{"cluster": ["monitor"]}`, "wait_for"); err != nil {
+		t.Fatalf("UpsertRole() error = %v", err)
+	}
+	if _, err := DeleteRole(esClient, "reader", ""); err != nil {
+		t.Fatalf("DeleteRole() error = %v", err)
+	}
+
+	requests := fakeES.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(requests))
+	}
+	if requests[0].Query != "refresh=wait_for" {
+		t.Errorf("PUT role query = %q, want refresh=wait_for", requests[0].Query)
+	}
+	if requests[1].Query != "" {
+		t.Errorf("DELETE role query = %q, want none", requests[1].Query)
+	}
+}
@@ -0,0 +1,151 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ShardsUnassigned condition of Indices, set while shards of the index are not allocated to any node, with the
+// explanation of the cluster allocation explain API
+const (
+	ShardsUnassignedConditionType = "ShardsUnassigned"
+	ShardsUnassignedReason        = "ShardsUnassigned"
+)
+
+// ShardAllocationRecheckInterval is the interval an index with unassigned shards is checked again at, so the
+// ShardsUnassigned condition is removed once the shards are allocated
+var ShardAllocationRecheckInterval = 5 * time.Minute
+
+// maxExplainedShards limits the unassigned shards explained per index, the explanations of the copies of a shard
+// are mostly the same
+const maxExplainedShards = 3
+
+// UnassignedShard is an unassigned copy of a shard with the explanation why Elasticsearch does not allocate it
+type UnassignedShard struct {
+	Shard       string
+	Primary     bool
+	Explanation string
+}
+
+// String formats the shard like [logs][0] replica: <explanation>
+func (s UnassignedShard) String() string {
+	kind := "replica"
+	if s.Primary {
+		kind = "primary"
+	}
+	return fmt.Sprintf("[%s] %s: %s", s.Shard, kind, s.Explanation)
+}
+
+// GetUnassignedShards returns the unassigned shard copies of the index, primaries first, and explains the first
+// maxExplainedShards of them with the cluster allocation explain API
+func GetUnassignedShards(esClient *elasticsearch.Client, index string) ([]UnassignedShard, error) {
+	res, err := esClient.Cat.Shards(
+		esClient.Cat.Shards.WithIndex(index),
+		esClient.Cat.Shards.WithFormat("json"),
+		esClient.Cat.Shards.WithH("shard", "prirep", "state"),
+	)
+	if err != nil || res.IsError() {
+		return nil, GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var shards []struct {
+		Shard  string `json:"shard"`
+		PriRep string `json:"prirep"`
+		State  string `json:"state"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&shards); err != nil {
+		return nil, err
+	}
+	var unassigned []UnassignedShard
+	for _, shard := range shards {
+		if shard.State == "UNASSIGNED" {
+			unassigned = append(unassigned, UnassignedShard{Shard: shard.Shard, Primary: shard.PriRep == "p"})
+		}
+	}
+	sort.SliceStable(unassigned, func(i, j int) bool {
+		return unassigned[i].Primary && !unassigned[j].Primary
+	})
+
+	for i := range unassigned {
+		if i >= maxExplainedShards {
+			break
+		}
+		explanation, err := explainShardAllocation(esClient, index, unassigned[i])
+		if err != nil {
+			return nil, err
+		}
+		unassigned[i].Explanation = explanation
+	}
+	return unassigned, nil
+}
+
+// explainShardAllocation returns the summary of the cluster allocation explain API for the shard copy, the
+// allocation explanation or, if there is none, the reason it became unassigned
+func explainShardAllocation(esClient *elasticsearch.Client, index string, shard UnassignedShard) (string, error) {
+	shardNumber := 0
+	if _, err := fmt.Sscan(shard.Shard, &shardNumber); err != nil {
+		return "", fmt.Errorf("invalid shard number %q: %w", shard.Shard, err)
+	}
+	body, err := json.Marshal(map[string]any{"index": index, "shard": shardNumber, "primary": shard.Primary})
+	if err != nil {
+		return "", err
+	}
+	res, err := esClient.Cluster.AllocationExplain(esClient.Cluster.AllocationExplain.WithBody(strings.NewReader(string(body))))
+	if err != nil || res.IsError() {
+		return "", GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var explain struct {
+		AllocateExplanation string `json:"allocate_explanation"`
+		UnassignedInfo      struct {
+			Reason string `json:"reason"`
+		} `json:"unassigned_info"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&explain); err != nil {
+		return "", err
+	}
+	if explain.AllocateExplanation != "" {
+		return explain.AllocateExplanation, nil
+	}
+	if explain.UnassignedInfo.Reason != "" {
+		return "unassigned: " + explain.UnassignedInfo.Reason, nil
+	}
+	return "no explanation", nil
+}
+
+// SetShardsUnassignedCondition sets the ShardsUnassigned condition of an Index with unassigned shards and removes it
+// if there are none. It returns the message of the condition, empty if removed, and whether it changed.
+func SetShardsUnassignedCondition(conditions *[]metav1.Condition, generation int64, indexName string, shards []UnassignedShard) (string, bool) {
+	if len(shards) == 0 {
+		return "", meta.RemoveStatusCondition(conditions, ShardsUnassignedConditionType)
+	}
+	var explained []string
+	for _, shard := range shards {
+		if shard.Explanation != "" {
+			explained = append(explained, shard.String())
+		}
+	}
+	message := fmt.Sprintf("%d shard copies of %s are unassigned: %s", len(shards), indexName, strings.Join(explained, "; "))
+	if len(explained) < len(shards) {
+		message += fmt.Sprintf("; %d more", len(shards)-len(explained))
+	}
+
+	previous := meta.FindStatusCondition(*conditions, ShardsUnassignedConditionType)
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ShardsUnassignedConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             ShardsUnassignedReason,
+		Message:            message,
+	})
+	return message, previous == nil || previous.Message != message
+}
@@ -0,0 +1,68 @@
+package elasticsearch
+
+import (
+	"strings"
+	"testing"
+
+	"eck-custom-resources/testutils"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetUnassignedShards_FakeElasticsearch(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+	fakeES.Put(testutils.ESIndex, "logs", `{}`)
+
+	shards, err := GetUnassignedShards(esClient, "logs")
+	if err != nil || len(shards) != 0 {
+		t.Fatalf("GetUnassignedShards() = %v, %v, want no unassigned shards", shards, err)
+	}
+
+	explanation := "cannot allocate because allocation is not permitted to any of the nodes"
+	fakeES.SetUnassignedReplica("logs", explanation)
+	shards, err = GetUnassignedShards(esClient, "logs")
+	if err != nil {
+		t.Fatalf("GetUnassignedShards() error = %v", err)
+	}
+	if len(shards) != 1 || shards[0].Shard != "0" || shards[0].Primary || shards[0].Explanation != explanation {
+		t.Errorf("GetUnassignedShards() = %v, want the explained replica of shard 0", shards)
+	}
+
+	if _, err := GetUnassignedShards(esClient, "missing"); err == nil {
+		t.Error("GetUnassignedShards() expected an error for a missing index")
+	}
+}
+
+func TestSetShardsUnassignedCondition(t *testing.T) {
+	var conditions []metav1.Condition
+	shards := []UnassignedShard{
+		{Shard: "0", Primary: true, Explanation: "no valid shard copy"},
+		{Shard: "1", Explanation: "disk watermark exceeded"},
+		{Shard: "2"},
+	}
+
+	message, changed := SetShardsUnassignedCondition(&conditions, 1, "logs", shards)
+	if !changed || !strings.Contains(message, "3 shard copies of logs") || !strings.Contains(message, "[0] primary: no valid shard copy") ||
+		!strings.Contains(message, "[1] replica: disk watermark exceeded") || !strings.HasSuffix(message, "1 more") {
+		t.Errorf("SetShardsUnassignedCondition() = %q, %v, want a new message summarizing the shards", message, changed)
+	}
+	condition := meta.FindStatusCondition(conditions, ShardsUnassignedConditionType)
+	if condition == nil || condition.Status != metav1.ConditionTrue || condition.Reason != ShardsUnassignedReason {
+		t.Fatalf("Expected the ShardsUnassigned condition, got %v", condition)
+	}
+	if _, changed := SetShardsUnassignedCondition(&conditions, 2, "logs", shards); changed {
+		t.Error("Expected the same shards not to be reported as changed")
+	}
+	if message, changed := SetShardsUnassignedCondition(&conditions, 2, "logs", nil); message != "" || !changed {
+		t.Errorf("SetShardsUnassignedCondition() = %q, %v, want the allocated shards reported as changed", message, changed)
+	}
+	if meta.FindStatusCondition(conditions, ShardsUnassignedConditionType) != nil {
+		t.Error("Expected the ShardsUnassigned condition to be removed")
+	}
+}
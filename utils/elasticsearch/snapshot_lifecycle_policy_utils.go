@@ -3,7 +3,10 @@ package elasticsearch
 import (
 	"eck-custom-resources/api/es.eck/v1alpha1"
 	"eck-custom-resources/utils"
+	"encoding/json"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -18,9 +21,37 @@ func DeleteSnapshotLifecyclePolicy(esClient *elasticsearch.Client, snapshotLifec
 }
 
 func UpsertSnapshotLifecyclePolicy(esClient *elasticsearch.Client, snapshotLifecyclePolicy v1alpha1.SnapshotLifecyclePolicy) (ctrl.Result, error) {
-	res, err := esClient.SlmPutLifecycle(snapshotLifecyclePolicy.Name, esClient.SlmPutLifecycle.WithBody(strings.NewReader(snapshotLifecyclePolicy.Spec.Body)))
+	res, err := esClient.SlmPutLifecycle(snapshotLifecyclePolicy.Name, esClient.SlmPutLifecycle.WithBody(strings.NewReader(snapshotLifecyclePolicy.Spec.GetBody())))
 	if err != nil || res.IsError() {
 		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
 	}
 	return ctrl.Result{}, nil
 }
+
+// GetSnapshotLifecyclePolicyLastSuccess returns the time of the last successful snapshot taken by the snapshot
+// lifecycle policy, nil if it never succeeded
+func GetSnapshotLifecyclePolicyLastSuccess(esClient *elasticsearch.Client, snapshotLifecyclePolicyName string) (*time.Time, error) {
+	res, err := esClient.SlmGetLifecycle(esClient.SlmGetLifecycle.WithPolicyID(snapshotLifecyclePolicyName))
+	if err != nil || res.IsError() {
+		return nil, GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var policies map[string]struct {
+		LastSuccess *struct {
+			Time int64 `json:"time"`
+		} `json:"last_success,omitempty"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&policies); err != nil {
+		return nil, err
+	}
+	policy, exists := policies[snapshotLifecyclePolicyName]
+	if !exists {
+		return nil, fmt.Errorf("snapshot lifecycle policy %s not found", snapshotLifecyclePolicyName)
+	}
+	if policy.LastSuccess == nil {
+		return nil, nil
+	}
+	lastSuccess := time.UnixMilli(policy.LastSuccess.Time)
+	return &lastSuccess, nil
+}
@@ -2,14 +2,77 @@ package elasticsearch
 
 import (
 	"eck-custom-resources/utils"
+	"encoding/json"
+	"fmt"
+	"path"
 	"strings"
 
 	"eck-custom-resources/api/es.eck/v1alpha1"
 
 	"github.com/elastic/go-elasticsearch/v8"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
+// snapshotRepositorySettings are the settings each repository type requires, and the settings identifying other
+// types it forbids, as they indicate that the wrong type was chosen
+var snapshotRepositorySettings = map[string]struct {
+	required  []string
+	forbidden []string
+}{
+	"fs":     {required: []string{"location"}, forbidden: []string{"bucket", "container", "url"}},
+	"url":    {required: []string{"url"}, forbidden: []string{"bucket", "container", "location"}},
+	"s3":     {required: []string{"bucket"}, forbidden: []string{"container", "location", "url"}},
+	"azure":  {required: []string{"container"}, forbidden: []string{"bucket", "location", "url"}},
+	"gcs":    {required: []string{"bucket"}, forbidden: []string{"container", "location", "url"}},
+	"hdfs":   {required: []string{"uri", "path"}, forbidden: []string{"bucket", "container", "location"}},
+	"source": {required: []string{"delegate_type"}},
+}
+
+// ValidateSnapshotRepositoryBody checks the settings of a snapshot repository body against its type: the settings the
+// type requires have to be set, settings identifying other types must not be. The location of an fs repository must
+// not leave path.repo, which Elasticsearch resolves relative locations against; absolute locations are checked by
+// Elasticsearch, path.repo is a node setting unknown at admission. Types of plugins not listed are only required to
+// be set. Bodies which are no JSON object are left to Elasticsearch.
+func ValidateSnapshotRepositoryBody(body string, fieldPath *field.Path) field.ErrorList {
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return nil
+	}
+
+	repositoryType, ok := parsed["type"].(string)
+	if !ok || repositoryType == "" {
+		return field.ErrorList{field.Required(fieldPath.Child("type"), "the repository type, e.g. fs, s3 or azure")}
+	}
+	typeSettings, known := snapshotRepositorySettings[repositoryType]
+	if !known {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	settingsPath := fieldPath.Child("settings")
+	settings, ok := parsed["settings"].(map[string]interface{})
+	if !ok && parsed["settings"] != nil {
+		return field.ErrorList{field.Invalid(settingsPath, parsed["settings"], "must be an object")}
+	}
+	for _, name := range typeSettings.required {
+		if value, ok := settings[name]; !ok || value == "" {
+			allErrs = append(allErrs, field.Required(settingsPath.Child(name), fmt.Sprintf("required by %s repositories", repositoryType)))
+		}
+	}
+	for _, name := range typeSettings.forbidden {
+		if _, ok := settings[name]; ok {
+			allErrs = append(allErrs, field.Forbidden(settingsPath.Child(name), fmt.Sprintf("not a setting of %s repositories", repositoryType)))
+		}
+	}
+	if location, ok := settings["location"].(string); ok && repositoryType == "fs" && !path.IsAbs(location) {
+		if cleaned := path.Clean(location); cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			allErrs = append(allErrs, field.Invalid(settingsPath.Child("location"), location, "relative locations must stay within path.repo"))
+		}
+	}
+	return allErrs
+}
+
 func DeleteSnapshotRepository(esClient *elasticsearch.Client, repositoryName string) (ctrl.Result, error) {
 	res, err := esClient.Snapshot.DeleteRepository([]string{repositoryName})
 	if err != nil || res.IsError() {
@@ -32,8 +95,45 @@ func UpsertSnapshotRepository(esClient *elasticsearch.Client, snapshotRepository
 	return updateSnapshotRepository(esClient, snapshotRepository)
 }
 
+// UpsertReadonlySnapshotRepository registers the repository as readonly, regardless of spec.readonly.
+// Used for the replicas of a repository shared between several clusters, where only one cluster may write.
+func UpsertReadonlySnapshotRepository(esClient *elasticsearch.Client, snapshotRepository v1alpha1.SnapshotRepository) (ctrl.Result, error) {
+	snapshotRepository.Spec.Readonly = true
+	return UpsertSnapshotRepository(esClient, snapshotRepository)
+}
+
+// SnapshotRepositoryBody returns the repository definition sent to Elasticsearch,
+// with settings.readonly set when the repository is registered as readonly.
+func SnapshotRepositoryBody(snapshotRepository v1alpha1.SnapshotRepository) (string, error) {
+	if !snapshotRepository.Spec.Readonly {
+		return snapshotRepository.Spec.GetBody(), nil
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal([]byte(snapshotRepository.Spec.GetBody()), &body); err != nil {
+		return "", fmt.Errorf("failed to parse snapshot repository body: %w", err)
+	}
+	settings, ok := body["settings"].(map[string]any)
+	if !ok {
+		settings = map[string]any{}
+	}
+	settings["readonly"] = true
+	body["settings"] = settings
+
+	marshalledBody, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	return string(marshalledBody), nil
+}
+
 func createSnapshotRepository(esClient *elasticsearch.Client, snapshotRepository v1alpha1.SnapshotRepository) (ctrl.Result, error) {
-	res, err := esClient.Snapshot.CreateRepository(snapshotRepository.Name, strings.NewReader(snapshotRepository.Spec.Body))
+	body, err := SnapshotRepositoryBody(snapshotRepository)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	res, err := esClient.Snapshot.CreateRepository(snapshotRepository.Name, strings.NewReader(body))
 
 	if err != nil || res.IsError() {
 		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
@@ -43,12 +143,17 @@ func createSnapshotRepository(esClient *elasticsearch.Client, snapshotRepository
 }
 
 func updateSnapshotRepository(esClient *elasticsearch.Client, snapshotRepository v1alpha1.SnapshotRepository) (ctrl.Result, error) {
+	body, err := SnapshotRepositoryBody(snapshotRepository)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	_, repoDeleteErr := DeleteSnapshotRepository(esClient, snapshotRepository.Name)
 	if repoDeleteErr != nil {
 		return ctrl.Result{}, repoDeleteErr
 	}
 
-	res, err := esClient.Snapshot.CreateRepository(snapshotRepository.Name, strings.NewReader(snapshotRepository.Spec.Body))
+	res, err := esClient.Snapshot.CreateRepository(snapshotRepository.Name, strings.NewReader(body))
 	if err != nil || res.IsError() {
 		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
 	}
@@ -1,14 +1,18 @@
 package elasticsearch
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"eck-custom-resources/api/es.eck/v1alpha1"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
 func TestDeleteSnapshotRepository(t *testing.T) {
@@ -296,3 +300,164 @@ func TestCreateSnapshotRepository(t *testing.T) {
 		})
 	}
 }
+
+func TestSnapshotRepositoryBody(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		readonly     bool
+		wantReadonly bool
+		wantErr      bool
+	}{
+		{
+			name:         "writable repository body is passed through",
+			body:         `{"type": "fs", "settings": {"location": "/backup"}}`,
+			readonly:     false,
+			wantReadonly: false,
+		},
+		{
+			name:         "readonly repository gets settings.readonly",
+			body:         `{"type": "fs", "settings": {"location": "/backup"}}`,
+			readonly:     true,
+			wantReadonly: true,
+		},
+		{
+			name:         "readonly repository without settings",
+			body:         `{"type": "source"}`,
+			readonly:     true,
+			wantReadonly: true,
+		},
+		{
+			name:     "readonly repository with invalid body",
+			body:     `{invalid`,
+			readonly: true,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := v1alpha1.SnapshotRepository{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-repo"},
+				Spec: v1alpha1.SnapshotRepositorySpec{
+					Body:     tt.body,
+					Readonly: tt.readonly,
+				},
+			}
+
+			got, err := SnapshotRepositoryBody(repo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SnapshotRepositoryBody() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !tt.readonly && got != tt.body {
+				t.Errorf("SnapshotRepositoryBody() = %s, want unchanged body %s", got, tt.body)
+			}
+
+			var body map[string]any
+			if err := json.Unmarshal([]byte(got), &body); err != nil {
+				t.Fatalf("Failed to parse body: %v", err)
+			}
+			settings, _ := body["settings"].(map[string]any)
+			if readonly, _ := settings["readonly"].(bool); readonly != tt.wantReadonly {
+				t.Errorf("settings.readonly = %v, want %v", readonly, tt.wantReadonly)
+			}
+		})
+	}
+}
+
+func TestUpsertReadonlySnapshotRepository(t *testing.T) {
+	var putBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"type": "repository_missing_exception"}}`))
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			putBody = string(body)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"acknowledged": true}`))
+		}
+	}))
+	defer server.Close()
+
+	esClient, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses: []string{server.URL},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	repo := v1alpha1.SnapshotRepository{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared-repo"},
+		Spec: v1alpha1.SnapshotRepositorySpec{
+			Body: `{"type": "fs", "settings": {"location": "/backup"}}`,
+		},
+	}
+
+	if _, err := UpsertReadonlySnapshotRepository(esClient, repo); err != nil {
+		t.Fatalf("UpsertReadonlySnapshotRepository() error = %v", err)
+	}
+	if repo.Spec.Readonly {
+		t.Error("Expected the passed repository to be left untouched")
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal([]byte(putBody), &body); err != nil {
+		t.Fatalf("Failed to parse request body: %v", err)
+	}
+	settings, _ := body["settings"].(map[string]any)
+	if readonly, _ := settings["readonly"].(bool); !readonly {
+		t.Errorf("Expected settings.readonly = true, got body %s", putBody)
+	}
+}
+
+func TestValidateSnapshotRepositoryBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantErrs []string
+	}{
+		{name: "fs", body: `{"type": "fs", "settings": {"location": "/mnt/backups"}}`},
+		{name: "fs relative location", body: `{"type": "fs", "settings": {"location": "team-a/backups"}}`},
+		{name: "fs escaping path.repo", body: `{"type": "fs", "settings": {"location": "../../etc"}}`,
+			wantErrs: []string{"spec.body.settings.location: Invalid value"}},
+		{name: "fs without location", body: `{"type": "fs", "settings": {"compress": true}}`,
+			wantErrs: []string{"spec.body.settings.location: Required"}},
+		{name: "fs without settings", body: `{"type": "fs"}`,
+			wantErrs: []string{"spec.body.settings.location: Required"}},
+		{name: "s3", body: `{"type": "s3", "settings": {"bucket": "backups", "base_path": "prod"}}`},
+		{name: "s3 with location", body: `{"type": "s3", "settings": {"location": "/mnt/backups"}}`,
+			wantErrs: []string{"spec.body.settings.bucket: Required", "spec.body.settings.location: Forbidden"}},
+		{name: "azure", body: `{"type": "azure", "settings": {"container": "backups"}}`},
+		{name: "azure with bucket", body: `{"type": "azure", "settings": {"container": "backups", "bucket": "backups"}}`,
+			wantErrs: []string{"spec.body.settings.bucket: Forbidden"}},
+		{name: "gcs without bucket", body: `{"type": "gcs", "settings": {}}`,
+			wantErrs: []string{"spec.body.settings.bucket: Required"}},
+		{name: "plugin type", body: `{"type": "oss", "settings": {"endpoint": "oss.example.com"}}`},
+		{name: "missing type", body: `{"settings": {"location": "/mnt/backups"}}`,
+			wantErrs: []string{"spec.body.type: Required"}},
+		{name: "settings no object", body: `{"type": "fs", "settings": "/mnt/backups"}`,
+			wantErrs: []string{"spec.body.settings: Invalid value"}},
+		{name: "no JSON object", body: `not json`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateSnapshotRepositoryBody(tt.body, field.NewPath("spec").Child("body"))
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("ValidateSnapshotRepositoryBody() = %v, want %v", errs, tt.wantErrs)
+			}
+			for i, want := range tt.wantErrs {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("ValidateSnapshotRepositoryBody()[%d] = %v, want %q", i, errs[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+// SnapshotRestoreProgress is the progress of the recovery of the indices restored from a snapshot
+type SnapshotRestoreProgress struct {
+	Indices         []string
+	TotalShards     int32
+	RecoveredShards int32
+}
+
+// Done reports whether the recovery of all restored shards is done
+func (p SnapshotRestoreProgress) Done() bool {
+	return p.TotalShards > 0 && p.RecoveredShards == p.TotalShards
+}
+
+// RestoreRequested reports whether the snapshot has to be restored, which is the case if it has never been restored
+// or a higher restore generation is requested
+func RestoreRequested(snapshotRestore v1alpha1.SnapshotRestore) bool {
+	return snapshotRestore.Status.Phase == "" || snapshotRestore.Spec.RestoreGeneration > snapshotRestore.Status.RestoreGeneration
+}
+
+// GetSnapshotRestoreBody returns the body of the restore request
+func GetSnapshotRestoreBody(snapshotRestore v1alpha1.SnapshotRestore) (string, error) {
+	spec := snapshotRestore.Spec
+	body := map[string]any{
+		"include_global_state": spec.IncludeGlobalState,
+		"partial":              spec.Partial,
+	}
+	if len(spec.Indices) > 0 {
+		body["indices"] = spec.Indices
+	}
+	if spec.RenamePattern != "" {
+		body["rename_pattern"] = spec.RenamePattern
+		body["rename_replacement"] = spec.RenameReplacement
+	}
+	if len(spec.IndexSettings) > 0 {
+		body["index_settings"] = spec.IndexSettings
+	}
+	if len(spec.IgnoreIndexSettings) > 0 {
+		body["ignore_index_settings"] = spec.IgnoreIndexSettings
+	}
+	marshalled, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	return string(marshalled), nil
+}
+
+// RestoreSnapshot starts the restore of the snapshot without waiting for its completion. rejected is true if
+// Elasticsearch refused the restore, e.g. because a restored index already exists, as opposed to a failed request.
+func RestoreSnapshot(esClient *elasticsearch.Client, snapshotRestore v1alpha1.SnapshotRestore) (rejected bool, err error) {
+	body, err := GetSnapshotRestoreBody(snapshotRestore)
+	if err != nil {
+		return true, err
+	}
+	res, err := esClient.Snapshot.Restore(snapshotRestore.Spec.Repository, snapshotRestore.Spec.Snapshot,
+		esClient.Snapshot.Restore.WithBody(strings.NewReader(body)),
+		esClient.Snapshot.Restore.WithWaitForCompletion(false))
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return true, GetClientErrorOrResponseError(nil, res)
+	}
+	return false, nil
+}
+
+// GetSnapshotRestoreProgress returns the progress of the recoveries of the indices restored from the snapshot since
+// the start time
+func GetSnapshotRestoreProgress(esClient *elasticsearch.Client, snapshotRestore v1alpha1.SnapshotRestore, since time.Time) (*SnapshotRestoreProgress, error) {
+	res, err := esClient.Indices.Recovery(esClient.Indices.Recovery.WithActiveOnly(false))
+	if err != nil || res.IsError() {
+		return nil, GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var recoveries map[string]struct {
+		Shards []struct {
+			Type              string `json:"type"`
+			Stage             string `json:"stage"`
+			StartTimeInMillis int64  `json:"start_time_in_millis"`
+			Source            struct {
+				Repository string `json:"repository"`
+				Snapshot   string `json:"snapshot"`
+			} `json:"source"`
+		} `json:"shards"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&recoveries); err != nil {
+		return nil, err
+	}
+
+	progress := &SnapshotRestoreProgress{}
+	for index, recovery := range recoveries {
+		restored := false
+		for _, shard := range recovery.Shards {
+			// Recoveries of earlier restores of the same snapshot are reported until their indices are deleted
+			if shard.Type != "SNAPSHOT" || shard.Source.Repository != snapshotRestore.Spec.Repository ||
+				shard.Source.Snapshot != snapshotRestore.Spec.Snapshot || shard.StartTimeInMillis < since.UnixMilli() {
+				continue
+			}
+			restored = true
+			progress.TotalShards++
+			if shard.Stage == "DONE" {
+				progress.RecoveredShards++
+			}
+		}
+		if restored {
+			progress.Indices = append(progress.Indices, index)
+		}
+	}
+	sort.Strings(progress.Indices)
+	return progress, nil
+}
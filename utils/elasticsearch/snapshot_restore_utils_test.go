@@ -0,0 +1,134 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRestoreRequested(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   int64
+		status v1alpha1.SnapshotRestoreStatus
+		want   bool
+	}{
+		{name: "never restored", want: true},
+		{name: "restored", status: v1alpha1.SnapshotRestoreStatus{Phase: v1alpha1.SnapshotRestorePhaseCompleted}, want: false},
+		{name: "failed restore is not retried", status: v1alpha1.SnapshotRestoreStatus{Phase: v1alpha1.SnapshotRestorePhaseFailed}, want: false},
+		{name: "new generation", spec: 2, status: v1alpha1.SnapshotRestoreStatus{Phase: v1alpha1.SnapshotRestorePhaseCompleted, RestoreGeneration: 1}, want: true},
+		{name: "lower generation", spec: 1, status: v1alpha1.SnapshotRestoreStatus{Phase: v1alpha1.SnapshotRestorePhaseRunning, RestoreGeneration: 2}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshotRestore := v1alpha1.SnapshotRestore{
+				Spec:   v1alpha1.SnapshotRestoreSpec{RestoreGeneration: tt.spec},
+				Status: tt.status,
+			}
+			if got := RestoreRequested(snapshotRestore); got != tt.want {
+				t.Errorf("RestoreRequested() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSnapshotRestoreBody(t *testing.T) {
+	body, err := GetSnapshotRestoreBody(v1alpha1.SnapshotRestore{Spec: v1alpha1.SnapshotRestoreSpec{
+		Repository:          "backups",
+		Snapshot:            "nightly",
+		Indices:             []string{"logs-*"},
+		RenamePattern:       "(.+)",
+		RenameReplacement:   "restored-$1",
+		IndexSettings:       map[string]string{"index.number_of_replicas": "0"},
+		IgnoreIndexSettings: []string{"index.refresh_interval"},
+	}})
+	if err != nil {
+		t.Fatalf("GetSnapshotRestoreBody() error = %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"indices":               []any{"logs-*"},
+		"rename_pattern":        "(.+)",
+		"rename_replacement":    "restored-$1",
+		"index_settings":        map[string]any{"index.number_of_replicas": "0"},
+		"ignore_index_settings": []any{"index.refresh_interval"},
+		"include_global_state":  false,
+		"partial":               false,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetSnapshotRestoreBody() = %v, want %v", got, want)
+	}
+}
+
+func TestSnapshotRestore_FakeElasticsearch(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	snapshotRestore := v1alpha1.SnapshotRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+		Spec: v1alpha1.SnapshotRestoreSpec{
+			Repository:        "backups",
+			Snapshot:          "nightly",
+			Indices:           []string{"logs-*"},
+			RenamePattern:     "(.+)",
+			RenameReplacement: "restored-$1",
+		},
+	}
+	if rejected, err := RestoreSnapshot(esClient, snapshotRestore); err == nil || !rejected {
+		t.Errorf("Expected the restore from a missing repository to be rejected, got %v, %v", rejected, err)
+	}
+
+	fakeES.Put(testutils.ESSnapshotRepository, "backups", `{"type": "fs", "settings": {"location": "/backups"}}`)
+	fakeES.AddSnapshot("backups", "nightly", "logs-000001", "logs-000002", "metrics-000001")
+	since := time.Now().Add(-time.Second)
+	if _, err := RestoreSnapshot(esClient, snapshotRestore); err != nil {
+		t.Fatalf("RestoreSnapshot() error = %v", err)
+	}
+	for _, index := range []string{"restored-logs-000001", "restored-logs-000002"} {
+		if !fakeES.Exists(testutils.ESIndex, index) {
+			t.Errorf("Expected index %s to be restored", index)
+		}
+	}
+	if fakeES.Exists(testutils.ESIndex, "restored-metrics-000001") {
+		t.Error("Expected only the indices matching spec.indices to be restored")
+	}
+
+	progress, err := GetSnapshotRestoreProgress(esClient, snapshotRestore, since)
+	if err != nil {
+		t.Fatalf("GetSnapshotRestoreProgress() error = %v", err)
+	}
+	if progress.Done() || progress.TotalShards != 2 || progress.RecoveredShards != 0 {
+		t.Errorf("Expected the recovery of 2 shards to be running, got %+v", progress)
+	}
+	fakeES.FinishRecoveries()
+	progress, err = GetSnapshotRestoreProgress(esClient, snapshotRestore, since)
+	if err != nil {
+		t.Fatalf("GetSnapshotRestoreProgress() error = %v", err)
+	}
+	if !progress.Done() || !reflect.DeepEqual(progress.Indices, []string{"restored-logs-000001", "restored-logs-000002"}) {
+		t.Errorf("Expected the recovery of the restored indices to be done, got %+v", progress)
+	}
+	if progress, _ := GetSnapshotRestoreProgress(esClient, snapshotRestore, time.Now().Add(time.Minute)); progress.TotalShards != 0 {
+		t.Errorf("Expected recoveries started before the restore to be ignored, got %+v", progress)
+	}
+
+	rejected, err := RestoreSnapshot(esClient, snapshotRestore)
+	if err == nil || !rejected || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected restoring over existing indices to be rejected, got %v, %v", rejected, err)
+	}
+}
@@ -0,0 +1,149 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	configv2 "eck-custom-resources/api/config/v2"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultStartupHealth is the cluster health an instance has to reach before its resources are reconciled
+const DefaultStartupHealth = "yellow"
+
+// startupGateRecheckInterval limits how often the health of a not yet ready instance is queried,
+// no matter how many resources are waiting for it
+const startupGateRecheckInterval = 10 * time.Second
+
+var healthLevels = map[string]int{
+	"red":    0,
+	"yellow": 1,
+	"green":  2,
+}
+
+// startupGate holds back reconciles against Elasticsearch instances which did not reach the required
+// cluster health yet. Once an instance reached it, it is considered started and never gated again. The lock only
+// guards the instances, the health is queried without holding it, so a slow instance never delays the others.
+type startupGate struct {
+	mu        sync.Mutex
+	instances map[string]*startupGateInstance
+	now       func() time.Time
+}
+
+type startupGateInstance struct {
+	ready       bool
+	lastChecked time.Time
+	lastStatus  string
+	notified    bool
+	waiting     int
+	// checking is set while the health is queried, concurrent reconciles are held back meanwhile
+	checking bool
+}
+
+var defaultStartupGate = newStartupGate()
+
+func newStartupGate() *startupGate {
+	return &startupGate{
+		instances: make(map[string]*startupGateInstance),
+		now:       time.Now,
+	}
+}
+
+// CheckStartupHealth reports whether resources of the target instance may be reconciled. While the instance
+// has not reached its startup health, a single event is recorded on the first waiting object and the returned
+// result requeues the resource without an error.
+func CheckStartupHealth(ctx context.Context, recorder record.EventRecorder, object runtime.Object, esClient *elasticsearch.Client, targetInstance configv2.ElasticsearchSpec) (bool, ctrl.Result) {
+	return defaultStartupGate.check(ctx, recorder, object, esClient, targetInstance)
+}
+
+func (g *startupGate) check(ctx context.Context, recorder record.EventRecorder, object runtime.Object, esClient *elasticsearch.Client, targetInstance configv2.ElasticsearchSpec) (bool, ctrl.Result) {
+	logger := log.FromContext(ctx)
+	if targetInstance.EckRef != nil {
+		// The health is taken from the ECK Elasticsearch instead, see CheckEckBootstrapped
+		return true, ctrl.Result{}
+	}
+	required := targetInstance.StartupHealth
+	if required == "" {
+		required = DefaultStartupHealth
+	}
+
+	g.mu.Lock()
+	instance, ok := g.instances[targetInstance.Url]
+	if !ok {
+		instance = &startupGateInstance{}
+		g.instances[targetInstance.Url] = instance
+	}
+	if instance.ready {
+		g.mu.Unlock()
+		return true, ctrl.Result{}
+	}
+	check := !instance.checking && g.now().Sub(instance.lastChecked) >= startupGateRecheckInterval
+	if check {
+		instance.checking = true
+		instance.lastChecked = g.now()
+	}
+	g.mu.Unlock()
+
+	if check {
+		healthCtx, cancel := context.WithTimeout(ctx, startupGateRecheckInterval)
+		status, err := GetClusterHealthStatus(healthCtx, esClient)
+		cancel()
+		if err != nil {
+			status = "unavailable"
+			logger.V(1).Info("Failed to get cluster health", "url", targetInstance.Url, "error", err.Error())
+		}
+
+		g.mu.Lock()
+		instance.checking = false
+		instance.lastStatus = status
+		if level, known := healthLevels[status]; known && level >= healthLevels[required] {
+			instance.ready = true
+			waiting := instance.waiting
+			g.mu.Unlock()
+			if waiting > 0 {
+				logger.Info("Elasticsearch instance reached startup health", "url", targetInstance.Url,
+					"health", status, "waitingResources", waiting)
+			}
+			return true, ctrl.Result{}
+		}
+		g.mu.Unlock()
+	}
+
+	g.mu.Lock()
+	instance.waiting++
+	notify := !instance.notified
+	instance.notified = true
+	lastStatus := instance.lastStatus
+	g.mu.Unlock()
+	if notify {
+		recorder.Event(object, "Warning", "WaitingForInstance",
+			fmt.Sprintf("Waiting for Elasticsearch %s to reach %s health (current: %s), reconciles against it are held back",
+				targetInstance.Url, required, lastStatus))
+	}
+	return false, ctrl.Result{RequeueAfter: startupGateRecheckInterval}
+}
+
+// GetClusterHealthStatus returns the cluster health status (green, yellow or red) of the Elasticsearch instance.
+func GetClusterHealthStatus(ctx context.Context, esClient *elasticsearch.Client) (string, error) {
+	res, err := esClient.Cluster.Health(esClient.Cluster.Health.WithContext(ctx))
+	if err != nil || res.IsError() {
+		return "", GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var health struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&health); err != nil {
+		return "", fmt.Errorf("decode response: %w", err)
+	}
+	return health.Status, nil
+}
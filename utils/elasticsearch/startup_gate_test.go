@@ -0,0 +1,208 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestStartupGate_Check(t *testing.T) {
+	tests := []struct {
+		name          string
+		startupHealth string
+		clusterHealth string
+		statusCode    int
+		wantReady     bool
+	}{
+		{
+			name:          "green cluster passes default yellow",
+			clusterHealth: "green",
+			statusCode:    http.StatusOK,
+			wantReady:     true,
+		},
+		{
+			name:          "yellow cluster passes default yellow",
+			clusterHealth: "yellow",
+			statusCode:    http.StatusOK,
+			wantReady:     true,
+		},
+		{
+			name:          "red cluster is held back",
+			clusterHealth: "red",
+			statusCode:    http.StatusOK,
+			wantReady:     false,
+		},
+		{
+			name:          "yellow cluster is held back when green is required",
+			startupHealth: "green",
+			clusterHealth: "yellow",
+			statusCode:    http.StatusOK,
+			wantReady:     false,
+		},
+		{
+			name:          "red cluster passes when red is required",
+			startupHealth: "red",
+			clusterHealth: "red",
+			statusCode:    http.StatusOK,
+			wantReady:     true,
+		},
+		{
+			name:       "unavailable cluster is held back",
+			statusCode: http.StatusServiceUnavailable,
+			wantReady:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/_cluster/health" {
+					t.Errorf("Expected path /_cluster/health, got %s", r.URL.Path)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Elastic-Product", "Elasticsearch")
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(`{"status": "` + tt.clusterHealth + `"}`))
+			}))
+			defer server.Close()
+
+			esClient, err := elasticsearch.NewClient(elasticsearch.Config{
+				Addresses: []string{server.URL},
+			})
+			if err != nil {
+				t.Fatalf("Failed to create ES client: %v", err)
+			}
+
+			recorder := record.NewFakeRecorder(10)
+			gate := newStartupGate()
+			target := configv2.ElasticsearchSpec{Url: server.URL, StartupHealth: tt.startupHealth}
+
+			ready, result := gate.check(context.Background(), recorder, &v1alpha1.Index{}, esClient, target)
+
+			if ready != tt.wantReady {
+				t.Errorf("check() ready = %v, want %v", ready, tt.wantReady)
+			}
+			if !tt.wantReady && result.RequeueAfter != startupGateRecheckInterval {
+				t.Errorf("check() RequeueAfter = %v, want %v", result.RequeueAfter, startupGateRecheckInterval)
+			}
+			if !tt.wantReady && len(recorder.Events) != 1 {
+				t.Errorf("Expected one WaitingForInstance event, got %d", len(recorder.Events))
+			}
+		})
+	}
+}
+
+func TestStartupGate_AggregatesAndRemembersReadiness(t *testing.T) {
+	health := "red"
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status": "` + health + `"}`))
+	}))
+	defer server.Close()
+
+	esClient, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses: []string{server.URL},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+
+	now := time.Now()
+	recorder := record.NewFakeRecorder(10)
+	gate := newStartupGate()
+	gate.now = func() time.Time { return now }
+	target := configv2.ElasticsearchSpec{Url: server.URL}
+
+	// Many resources waiting for the same instance produce a single health request and a single event
+	for i := 0; i < 5; i++ {
+		if ready, _ := gate.check(context.Background(), recorder, &v1alpha1.Index{}, esClient, target); ready {
+			t.Fatal("Expected red instance to be held back")
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 health request within the recheck interval, got %d", requests)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Expected 1 aggregated event, got %d", len(recorder.Events))
+	}
+
+	health = "green"
+	now = now.Add(startupGateRecheckInterval)
+	if ready, _ := gate.check(context.Background(), recorder, &v1alpha1.Index{}, esClient, target); !ready {
+		t.Fatal("Expected green instance to pass the gate")
+	}
+
+	// Once started, the instance is never gated again
+	health = "red"
+	now = now.Add(startupGateRecheckInterval)
+	if ready, _ := gate.check(context.Background(), recorder, &v1alpha1.Index{}, esClient, target); !ready {
+		t.Error("Expected started instance to stay ready")
+	}
+	if requests != 2 {
+		t.Errorf("Expected no health request after the instance started, got %d requests", requests)
+	}
+}
+
+func TestStartupGate_SlowInstanceDoesNotBlockOthers(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Write([]byte(`{"status": "green"}`))
+	}))
+	defer slow.Close()
+	defer close(release)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Write([]byte(`{"status": "green"}`))
+	}))
+	defer fast.Close()
+
+	slowClient, _ := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{slow.URL}})
+	fastClient, _ := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{fast.URL}})
+	recorder := record.NewFakeRecorder(10)
+	gate := newStartupGate()
+
+	go func() {
+		gate.check(context.Background(), recorder, &v1alpha1.Index{}, slowClient, configv2.ElasticsearchSpec{Url: slow.URL})
+	}()
+	// Wait for the health request of the slow instance to be in flight
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		gate.mu.Lock()
+		inFlight := gate.instances[slow.URL] != nil && gate.instances[slow.URL].checking
+		gate.mu.Unlock()
+		if inFlight {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the health of the slow instance to be queried")
+		}
+	}
+
+	done := make(chan bool)
+	go func() {
+		fastReady, _ := gate.check(context.Background(), recorder, &v1alpha1.Index{}, fastClient, configv2.ElasticsearchSpec{Url: fast.URL})
+		slowReady, _ := gate.check(context.Background(), recorder, &v1alpha1.Index{}, slowClient, configv2.ElasticsearchSpec{Url: slow.URL})
+		done <- fastReady && !slowReady
+	}()
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Error("Expected the fast instance to pass and the slow one to be held back while its health is queried")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected checks to not wait for the health request of the slow instance")
+	}
+}
@@ -0,0 +1,61 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// SynonymSetReload is the outcome of the reload of the search analyzers using a synonym set, which Elasticsearch
+// performs whenever the set is updated
+type SynonymSetReload struct {
+	// Indices whose analyzers were reloaded
+	Indices []string
+	// FailedShards counts the shards whose analyzers still use the previous synonyms
+	FailedShards int
+}
+
+func DeleteSynonymSet(esClient *elasticsearch.Client, synonymSetName string) (ctrl.Result, error) {
+	// Elasticsearch refuses to delete a synonym set used by the analyzers of an index
+	res, err := esClient.SynonymsDeleteSynonym(synonymSetName)
+	if err != nil || (res.IsError() && res.StatusCode != http.StatusNotFound) {
+		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+	return ctrl.Result{}, nil
+}
+
+// UpsertSynonymSet creates or replaces the synonym set and returns the reload of the search analyzers using it
+func UpsertSynonymSet(esClient *elasticsearch.Client, synonymSet v1alpha1.SynonymSet) (SynonymSetReload, ctrl.Result, error) {
+	res, err := esClient.SynonymsPutSynonym(utils.RemoteName(&synonymSet), strings.NewReader(synonymSet.Spec.GetBody()))
+	if err != nil || res.IsError() {
+		return SynonymSetReload{}, utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var response struct {
+		ReloadAnalyzersDetails struct {
+			Shards struct {
+				Failed int `json:"failed"`
+			} `json:"_shards"`
+			ReloadDetails []struct {
+				Index string `json:"index"`
+			} `json:"reload_details"`
+		} `json:"reload_analyzers_details"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		// The set is stored, only the details of the reload are unknown
+		return SynonymSetReload{}, ctrl.Result{}, nil
+	}
+	reload := SynonymSetReload{FailedShards: response.ReloadAnalyzersDetails.Shards.Failed}
+	for _, details := range response.ReloadAnalyzersDetails.ReloadDetails {
+		reload.Indices = append(reload.Indices, details.Index)
+	}
+	return reload, ctrl.Result{}, nil
+}
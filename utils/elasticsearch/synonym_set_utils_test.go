@@ -0,0 +1,112 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"eck-custom-resources/api/es.eck/v1alpha1"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpsertSynonymSet(t *testing.T) {
+	tests := []struct {
+		name             string
+		serverStatusCode int
+		serverResponse   string
+		wantReload       SynonymSetReload
+		wantErr          bool
+	}{
+		{
+			name:             "reloaded",
+			serverStatusCode: http.StatusOK,
+			serverResponse:   `{"result": "updated", "reload_analyzers_details": {"_shards": {"total": 2, "successful": 2, "failed": 0}, "reload_details": [{"index": "products", "reloaded_analyzers": ["product_search"]}]}}`,
+			wantReload:       SynonymSetReload{Indices: []string{"products"}},
+		},
+		{
+			name:             "reload failed",
+			serverStatusCode: http.StatusOK,
+			serverResponse:   `{"result": "updated", "reload_analyzers_details": {"_shards": {"total": 2, "successful": 1, "failed": 1}, "reload_details": [{"index": "products"}]}}`,
+			wantReload:       SynonymSetReload{Indices: []string{"products"}, FailedShards: 1},
+		},
+		{
+			name:             "not used",
+			serverStatusCode: http.StatusOK,
+			serverResponse:   `{"result": "created", "reload_analyzers_details": {"_shards": {"total": 0, "successful": 0, "failed": 0}, "reload_details": []}}`,
+		},
+		{
+			name:             "rejected",
+			serverStatusCode: http.StatusBadRequest,
+			serverResponse:   `{"error": {"type": "x_content_parse_exception"}}`,
+			wantErr:          true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPut || r.URL.Path != "/_synonyms/products" {
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.RequestURI())
+				}
+				w.Header().Set("X-Elastic-Product", "Elasticsearch")
+				w.WriteHeader(tt.serverStatusCode)
+				w.Write([]byte(tt.serverResponse))
+			}))
+			defer server.Close()
+
+			esClient, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{server.URL}})
+			if err != nil {
+				t.Fatalf("Failed to create ES client: %v", err)
+			}
+
+			reload, _, err := UpsertSynonymSet(esClient, v1alpha1.SynonymSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "products"},
+				Spec:       v1alpha1.SynonymSetSpec{Body: `{"synonyms_set": [{"synonyms": "laptop => notebook"}]}`},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpsertSynonymSet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(reload.Indices, tt.wantReload.Indices) || reload.FailedShards != tt.wantReload.FailedShards {
+				t.Errorf("UpsertSynonymSet() reload = %+v, want %+v", reload, tt.wantReload)
+			}
+		})
+	}
+}
+
+func TestDeleteSynonymSet(t *testing.T) {
+	tests := []struct {
+		name             string
+		serverStatusCode int
+		wantErr          bool
+	}{
+		{name: "deleted", serverStatusCode: http.StatusOK},
+		{name: "already gone", serverStatusCode: http.StatusNotFound},
+		{name: "used by an index", serverStatusCode: http.StatusBadRequest, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodDelete || r.URL.Path != "/_synonyms/products" {
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.RequestURI())
+				}
+				w.Header().Set("X-Elastic-Product", "Elasticsearch")
+				w.WriteHeader(tt.serverStatusCode)
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			esClient, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{server.URL}})
+			if err != nil {
+				t.Fatalf("Failed to create ES client: %v", err)
+			}
+
+			if _, err := DeleteSynonymSet(esClient, "products"); (err != nil) != tt.wantErr {
+				t.Errorf("DeleteSynonymSet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
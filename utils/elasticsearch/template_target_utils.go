@@ -0,0 +1,49 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils/template"
+
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+// GetElasticsearchVersion returns the version number Elasticsearch reports on its root endpoint, e.g. 8.15.0
+func GetElasticsearchVersion(esClient *elasticsearch.Client) (string, error) {
+	res, err := esClient.Info()
+	if err != nil || res.IsError() {
+		return "", GetClientErrorOrResponseError(err, res)
+	}
+	defer res.Body.Close()
+
+	var info struct {
+		Version struct {
+			Number string `json:"number"`
+		} `json:"version"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&info); err != nil {
+		return "", err
+	}
+	return info.Version.Number, nil
+}
+
+// TemplateTarget describes the resolved target instance for the .Target builtins of templated bodies. The version is
+// only requested from Elasticsearch if the body is rendered at all, it is left empty if it can not be determined, the
+// following request to the instance reports the error then.
+func TemplateTarget(esClient *elasticsearch.Client, templateSpec eseckv1alpha1.CommonTemplatingSpec,
+	targetConfig eseckv1alpha1.CommonElasticsearchConfig, targetInstance configv2.ElasticsearchSpec, namespace string) template.Target {
+	if !template.IsTemplate(templateSpec) {
+		return template.Target{}
+	}
+	target := template.Target{Name: targetConfig.ElasticsearchInstance, URL: targetInstance.Url}
+	if target.Name != "" && !targetConfig.IsClusterInstance() {
+		target.Namespace = namespace
+		if targetConfig.ElasticsearchInstanceNamespace != "" {
+			target.Namespace = targetConfig.ElasticsearchInstanceNamespace
+		}
+	}
+	target.Version, _ = GetElasticsearchVersion(esClient)
+	return target
+}
@@ -0,0 +1,66 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+	"eck-custom-resources/utils/template"
+)
+
+func TestTemplateTarget(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+	templated := eseckv1alpha1.CommonTemplatingSpec{Builtins: true}
+	targetInstance := configv2.ElasticsearchSpec{Url: "https://quickstart-es-http:9200"}
+
+	tests := []struct {
+		name         string
+		templateSpec eseckv1alpha1.CommonTemplatingSpec
+		targetConfig eseckv1alpha1.CommonElasticsearchConfig
+		want         template.Target
+	}{
+		{
+			name:         "not templated",
+			templateSpec: eseckv1alpha1.CommonTemplatingSpec{},
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{ElasticsearchInstance: "quickstart"},
+			want:         template.Target{},
+		},
+		{
+			name:         "default instance",
+			templateSpec: templated,
+			want:         template.Target{URL: targetInstance.Url, Version: "8.15.0"},
+		},
+		{
+			name:         "instance in the namespace of the resource",
+			templateSpec: templated,
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{ElasticsearchInstance: "quickstart"},
+			want:         template.Target{Name: "quickstart", Namespace: "team-a", URL: targetInstance.Url, Version: "8.15.0"},
+		},
+		{
+			name:         "instance in another namespace",
+			templateSpec: templated,
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{ElasticsearchInstance: "quickstart", ElasticsearchInstanceNamespace: "elastic"},
+			want:         template.Target{Name: "quickstart", Namespace: "elastic", URL: targetInstance.Url, Version: "8.15.0"},
+		},
+		{
+			name:         "cluster instance",
+			templateSpec: templated,
+			targetConfig: eseckv1alpha1.CommonElasticsearchConfig{ElasticsearchInstance: "shared", Kind: eseckv1alpha1.ClusterElasticsearchInstanceKind},
+			want:         template.Target{Name: "shared", URL: targetInstance.Url, Version: "8.15.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TemplateTarget(esClient, tt.templateSpec, tt.targetConfig, targetInstance, "team-a"); got != tt.want {
+				t.Errorf("TemplateTarget() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"sort"
 	"strings"
 
 	"eck-custom-resources/api/es.eck/v1alpha1"
 	"eck-custom-resources/utils"
 
 	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
 	k8sv1 "k8s.io/api/core/v1"
 
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -46,15 +49,71 @@ func GetUser(esClient *elasticsearch.Client, username string) (*User, error) {
 	return &u, nil
 
 }
-func DeleteUser(esClient *elasticsearch.Client, userName string) (ctrl.Result, error) {
-	res, err := esClient.Security.DeleteUser(userName)
+
+// MissingRolesReason is the reason of the Pending condition of an ElasticsearchUser whose roles do not exist
+const MissingRolesReason = "MissingRoles"
+
+// MissingUserRoles returns the sorted roles of the user body which do not exist in Elasticsearch, looked up with the
+// get roles API, which returns native, file and reserved roles. Elasticsearch accepts users with unknown roles
+// silently, the user would just lack their privileges.
+func MissingUserRoles(esClient *elasticsearch.Client, body string) ([]string, error) {
+	var userBody struct {
+		Roles []string `json:"roles"`
+	}
+	if err := json.Unmarshal([]byte(body), &userBody); err != nil {
+		return nil, err
+	}
+	roles := make(map[string]bool)
+	for _, role := range userBody.Roles {
+		if role != "" {
+			roles[role] = true
+		}
+	}
+	if len(roles) == 0 {
+		return nil, nil
+	}
+	names := make([]string, 0, len(roles))
+	for role := range roles {
+		names = append(names, role)
+	}
+	sort.Strings(names)
+
+	res, err := esClient.Security.GetRole(esClient.Security.GetRole.WithName(names...))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		// None of the roles exists
+		return names, nil
+	}
+	if res.IsError() {
+		return nil, GetClientErrorOrResponseError(err, res)
+	}
+	var found map[string]json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&found); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	var missing []string
+	for _, role := range names {
+		if _, ok := found[role]; !ok {
+			missing = append(missing, role)
+		}
+	}
+	return missing, nil
+}
+
+// DeleteUser deletes the user, refresh is the refresh parameter of the request
+func DeleteUser(esClient *elasticsearch.Client, userName string, refresh string) (ctrl.Result, error) {
+	res, err := esClient.Security.DeleteUser(userName, esClient.Security.DeleteUser.WithRefresh(refresh))
 	if err != nil || res.IsError() {
 		return utils.GetRequeueResult(), err
 	}
 	return ctrl.Result{}, nil
 }
 
-func UpsertUser(esClient *elasticsearch.Client, cli client.Client, ctx context.Context, user v1alpha1.ElasticsearchUser) (ctrl.Result, error) {
+// UpsertUser creates or updates the user, refresh is the refresh parameter of the requests
+func UpsertUser(esClient *elasticsearch.Client, cli client.Client, ctx context.Context, user v1alpha1.ElasticsearchUser, refresh string) (ctrl.Result, error) {
 	var secret k8sv1.Secret
 
 	// Inject password field with data from given secret
@@ -62,27 +121,99 @@ func UpsertUser(esClient *elasticsearch.Client, cli client.Client, ctx context.C
 	if err != nil {
 		return utils.GetRequeueResult(), err
 	}
-	var password = secret.Data[user.Name]
 
 	var userBody map[string]interface{}
-	unmarshallErr := json.Unmarshal([]byte(user.Spec.Body), &userBody)
+	unmarshallErr := json.Unmarshal([]byte(user.Spec.GetBody()), &userBody)
 	if unmarshallErr != nil {
 		return ctrl.Result{}, unmarshallErr
 	}
 
-	userBody["password"] = string(password)
+	if err := applyUserProfile(userBody, user.Spec); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := setUserPassword(userBody, user.Spec, user.Name, secret); err != nil {
+		return ctrl.Result{}, err
+	}
 	userWithPassword, marshallErr := json.Marshal(userBody)
 	if marshallErr != nil {
 		return ctrl.Result{}, marshallErr
 	}
 
-	res, err := esClient.Security.PutUser(user.Name, strings.NewReader(string(userWithPassword)))
+	res, err := esClient.Security.PutUser(user.Name, strings.NewReader(string(userWithPassword)),
+		esClient.Security.PutUser.WithRefresh(refresh))
 	if err != nil || res.IsError() {
 		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
 	}
+
+	if user.Spec.Enabled != nil {
+		return SetUserEnabled(esClient, user.Name, *user.Spec.Enabled, refresh)
+	}
 	return ctrl.Result{}, nil
 }
 
+// SetUserEnabled enables or disables the user using the enable/disable user APIs, refresh is the refresh parameter
+// of the request
+func SetUserEnabled(esClient *elasticsearch.Client, userName string, enabled bool, refresh string) (ctrl.Result, error) {
+	var res *esapi.Response
+	var err error
+	if enabled {
+		res, err = esClient.Security.EnableUser(userName, esClient.Security.EnableUser.WithRefresh(refresh))
+	} else {
+		res, err = esClient.Security.DisableUser(userName, esClient.Security.DisableUser.WithRefresh(refresh))
+	}
+	if err != nil || res.IsError() {
+		return utils.GetRequeueResult(), GetClientErrorOrResponseError(err, res)
+	}
+	return ctrl.Result{}, nil
+}
+
+// applyUserProfile overrides the profile fields of the user body with the structured fields of the spec.
+// The enabled flag is put into the body as well, as PUT user would otherwise enable a disabled user
+// until SetUserEnabled disables it again.
+func applyUserProfile(userBody map[string]interface{}, spec v1alpha1.ElasticsearchUserSpec) error {
+	if spec.FullName != "" {
+		userBody["full_name"] = spec.FullName
+	}
+	if spec.Email != "" {
+		userBody["email"] = spec.Email
+	}
+	if len(spec.Metadata) > 0 {
+		metadata, ok := userBody["metadata"].(map[string]interface{})
+		if !ok {
+			metadata = map[string]interface{}{}
+		}
+		for key, value := range spec.Metadata {
+			var v interface{}
+			if err := json.Unmarshal(value.Raw, &v); err != nil {
+				return fmt.Errorf("invalid metadata value for key %s: %w", key, err)
+			}
+			metadata[key] = v
+		}
+		userBody["metadata"] = metadata
+	}
+	if spec.Enabled != nil {
+		userBody["enabled"] = *spec.Enabled
+	}
+	return nil
+}
+
+// setUserPassword puts the password of the user from the Secret into the user body, the password_hash from the
+// PasswordHashKey if it is set, the cleartext password from the key of the name of the user otherwise
+func setUserPassword(userBody map[string]interface{}, spec v1alpha1.ElasticsearchUserSpec, userName string, secret k8sv1.Secret) error {
+	if spec.PasswordHashKey == "" {
+		userBody["password"] = string(secret.Data[userName])
+		return nil
+	}
+	passwordHash := strings.TrimSpace(string(secret.Data[spec.PasswordHashKey]))
+	if passwordHash == "" {
+		return fmt.Errorf("key %s of Secret %s does not contain a password hash", spec.PasswordHashKey, secret.Name)
+	}
+	delete(userBody, "password")
+	userBody["password_hash"] = passwordHash
+	return nil
+}
+
 func getUserSecret(cli client.Client, ctx context.Context, namespace string, user v1alpha1.ElasticsearchUser, secret *k8sv1.Secret) error {
 	if err := cli.Get(ctx, client.ObjectKey{Namespace: namespace, Name: user.Spec.SecretName}, secret); err != nil {
 		return err
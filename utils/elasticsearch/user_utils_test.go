@@ -3,9 +3,16 @@ package elasticsearch
 import (
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
+	"eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+
 	"github.com/elastic/go-elasticsearch/v8"
+	k8sv1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestGetUser(t *testing.T) {
@@ -138,7 +145,7 @@ func TestDeleteUser(t *testing.T) {
 				t.Fatalf("Failed to create ES client: %v", err)
 			}
 
-			result, err := DeleteUser(esClient, tt.username)
+			result, err := DeleteUser(esClient, tt.username, "")
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DeleteUser() error = %v, wantErr %v", err, tt.wantErr)
@@ -160,7 +167,7 @@ func TestDeleteUser_ConnectionError(t *testing.T) {
 		t.Fatalf("Failed to create ES client: %v", err)
 	}
 
-	result, err := DeleteUser(esClient, "testuser")
+	result, err := DeleteUser(esClient, "testuser", "")
 
 	if err == nil {
 		t.Error("DeleteUser() with connection error should return an error")
@@ -189,3 +196,202 @@ func TestGetUser_ConnectionError(t *testing.T) {
 		t.Error("GetUser() with connection error should return nil user")
 	}
 }
+
+func TestMissingUserRoles(t *testing.T) {
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+	esClient, err := fakeES.Client()
+	if err != nil {
+		t.Fatalf("Failed to create ES client: %v", err)
+	}
+	fakeES.Put(testutils.ESRole, "logs-reader", `{"indices": [{"names": ["logs-*"], "privileges": ["read"]}]}`)
+	fakeES.Put(testutils.ESRole, "metrics-reader", `{"indices": [{"names": ["metrics-*"], "privileges": ["read"]}]}`)
+
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "all roles exist",
+			body: `{"roles": ["logs-reader", "metrics-reader"]}`,
+		},
+		{
+			name: "single existing role",
+			body: `{"roles": ["logs-reader"]}`,
+		},
+		{
+			name: "some roles missing",
+			body: `{"roles": ["traces-reader", "logs-reader", "alerts-reader", "traces-reader"]}`,
+			want: []string{"alerts-reader", "traces-reader"},
+		},
+		{
+			name: "no role exists",
+			body: `{"roles": ["traces-reader"]}`,
+			want: []string{"traces-reader"},
+		},
+		{
+			name: "no roles",
+			body: `{"full_name": "Jane"}`,
+		},
+		{
+			name:    "invalid body",
+			body:    `{"roles": "logs-reader"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MissingUserRoles(esClient, tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MissingUserRoles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MissingUserRoles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetUserEnabled(t *testing.T) {
+	tests := []struct {
+		name             string
+		enabled          bool
+		serverStatusCode int
+		wantPath         string
+		wantRequeue      bool
+		wantErr          bool
+	}{
+		{
+			name:             "enable user",
+			enabled:          true,
+			serverStatusCode: http.StatusOK,
+			wantPath:         "/_security/user/testuser/_enable",
+		},
+		{
+			name:             "disable user",
+			enabled:          false,
+			serverStatusCode: http.StatusOK,
+			wantPath:         "/_security/user/testuser/_disable",
+		},
+		{
+			name:             "user not found",
+			enabled:          false,
+			serverStatusCode: http.StatusNotFound,
+			wantPath:         "/_security/user/testuser/_disable",
+			wantRequeue:      true,
+			wantErr:          true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPut {
+					t.Errorf("Expected PUT request, got %s", r.Method)
+				}
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("Expected path %s, got %s", tt.wantPath, r.URL.Path)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Elastic-Product", "Elasticsearch")
+				w.WriteHeader(tt.serverStatusCode)
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			esClient, err := elasticsearch.NewClient(elasticsearch.Config{
+				Addresses: []string{server.URL},
+			})
+			if err != nil {
+				t.Fatalf("Failed to create ES client: %v", err)
+			}
+
+			result, err := SetUserEnabled(esClient, "testuser", tt.enabled, "")
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetUserEnabled() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result.Requeue != tt.wantRequeue {
+				t.Errorf("SetUserEnabled() Requeue = %v, want %v", result.Requeue, tt.wantRequeue)
+			}
+		})
+	}
+}
+
+func TestApplyUserProfile(t *testing.T) {
+	disabled := false
+	spec := v1alpha1.ElasticsearchUserSpec{
+		Enabled:  &disabled,
+		FullName: "Richard Feynman",
+		Email:    "rfeynman@example.com",
+		Metadata: map[string]apiextensionsv1.JSON{
+			"team": {Raw: []byte(`"physics"`)},
+		},
+	}
+	userBody := map[string]interface{}{
+		"roles":     []interface{}{"admin"},
+		"full_name": "Someone Else",
+		"metadata":  map[string]interface{}{"intelligence": float64(7)},
+	}
+
+	if err := applyUserProfile(userBody, spec); err != nil {
+		t.Fatalf("applyUserProfile() error = %v", err)
+	}
+
+	if userBody["full_name"] != "Richard Feynman" {
+		t.Errorf("full_name = %v, want Richard Feynman", userBody["full_name"])
+	}
+	if userBody["email"] != "rfeynman@example.com" {
+		t.Errorf("email = %v, want rfeynman@example.com", userBody["email"])
+	}
+	if userBody["enabled"] != false {
+		t.Errorf("enabled = %v, want false", userBody["enabled"])
+	}
+	metadata := userBody["metadata"].(map[string]interface{})
+	if metadata["team"] != "physics" || metadata["intelligence"] != float64(7) {
+		t.Errorf("metadata = %v, want merged metadata", metadata)
+	}
+
+	if err := applyUserProfile(map[string]interface{}{}, v1alpha1.ElasticsearchUserSpec{
+		Metadata: map[string]apiextensionsv1.JSON{"broken": {Raw: []byte(`{`)}},
+	}); err == nil {
+		t.Error("Expected error for invalid metadata value")
+	}
+}
+
+func TestSetUserPassword(t *testing.T) {
+	secret := k8sv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "users"},
+		Data: map[string][]byte{
+			"feynman":      []byte("cleartext"),
+			"feynman-hash": []byte("$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy\n"),
+			"empty":        []byte(""),
+		},
+	}
+
+	userBody := map[string]interface{}{}
+	if err := setUserPassword(userBody, v1alpha1.ElasticsearchUserSpec{}, "feynman", secret); err != nil {
+		t.Fatalf("setUserPassword() error = %v", err)
+	}
+	if userBody["password"] != "cleartext" || userBody["password_hash"] != nil {
+		t.Errorf("Expected the cleartext password from the key of the user name, got %v", userBody)
+	}
+
+	userBody = map[string]interface{}{"password": "from-body"}
+	if err := setUserPassword(userBody, v1alpha1.ElasticsearchUserSpec{PasswordHashKey: "feynman-hash"}, "feynman", secret); err != nil {
+		t.Fatalf("setUserPassword() error = %v", err)
+	}
+	if userBody["password_hash"] != "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy" || userBody["password"] != nil {
+		t.Errorf("Expected only the trimmed password_hash to be sent, got %v", userBody)
+	}
+
+	for _, key := range []string{"empty", "missing"} {
+		if err := setUserPassword(map[string]interface{}{}, v1alpha1.ElasticsearchUserSpec{PasswordHashKey: key}, "feynman", secret); err == nil {
+			t.Errorf("Expected an error for the password hash key %s", key)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"context"
+	"sync/atomic"
+
+	configv2 "eck-custom-resources/api/config/v2"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ReconciliationDisabled condition, set while the target instance of a resource is disabled
+const (
+	ReconciliationDisabledConditionType = "ReconciliationDisabled"
+	ReconciliationDisabledReason        = "ReconciliationDisabled"
+)
+
+// elasticsearchInstanceOverrides and kibanaInstanceOverrides are the overrides of named instances, set from the
+// ProjectConfig
+var (
+	elasticsearchInstanceOverrides atomic.Pointer[map[string]configv2.InstanceOverride]
+	kibanaInstanceOverrides        atomic.Pointer[map[string]configv2.InstanceOverride]
+)
+
+// SetInstanceOverrides sets the overrides of ElasticsearchInstances and KibanaInstances applied by
+// ElasticsearchInstanceEnabled and KibanaInstanceEnabled
+func SetInstanceOverrides(elasticsearch map[string]configv2.InstanceOverride, kibana map[string]configv2.InstanceOverride) {
+	elasticsearchInstanceOverrides.Store(&elasticsearch)
+	kibanaInstanceOverrides.Store(&kibana)
+}
+
+// ElasticsearchInstanceEnabled returns whether the ElasticsearchInstance is enabled, enabled being the setting of
+// the instance itself
+func ElasticsearchInstanceEnabled(namespace string, name string, enabled bool) bool {
+	return instanceEnabled(elasticsearchInstanceOverrides.Load(), namespace, name, enabled)
+}
+
+// KibanaInstanceEnabled returns whether the KibanaInstance is enabled, enabled being the setting of the instance
+// itself
+func KibanaInstanceEnabled(namespace string, name string, enabled bool) bool {
+	return instanceEnabled(kibanaInstanceOverrides.Load(), namespace, name, enabled)
+}
+
+// instanceEnabled looks up the override of the instance by namespace/name, then by name
+func instanceEnabled(overrides *map[string]configv2.InstanceOverride, namespace string, name string, enabled bool) bool {
+	if overrides == nil {
+		return enabled
+	}
+	for _, key := range []string{namespace + "/" + name, name} {
+		if override, ok := (*overrides)[key]; ok && override.Enabled != nil {
+			return *override.Enabled
+		}
+	}
+	return enabled
+}
+
+// CheckReconciliationEnabled reports whether the resource may be reconciled against its target instance. While the
+// instance is disabled, the ReconciliationDisabled condition is set on the object and an event is recorded when it
+// is set; the condition is removed again once the instance is enabled.
+// conditions must point into the status of obj.
+func CheckReconciliationEnabled(ctx context.Context, cli client.Client, recorder record.EventRecorder, obj client.Object,
+	conditions *[]metav1.Condition, enabled bool) bool {
+	logger := log.FromContext(ctx)
+
+	if enabled {
+		if clearReconciliationDisabled(conditions) {
+			if err := cli.Status().Update(ctx, obj); err != nil {
+				logger.Error(err, "Failed to clear ReconciliationDisabled condition")
+			}
+		}
+		return true
+	}
+
+	logger.Info("Target instance is disabled, not reconciling")
+	message := "Reconciliation is disabled for the target instance"
+	if setReconciliationDisabled(conditions, obj.GetGeneration(), message) {
+		recorder.Event(obj, "Normal", ReconciliationDisabledReason, message)
+		if err := cli.Status().Update(ctx, obj); err != nil {
+			logger.Error(err, "Failed to set ReconciliationDisabled condition")
+		}
+	}
+	return false
+}
+
+func setReconciliationDisabled(conditions *[]metav1.Condition, generation int64, message string) bool {
+	changed := meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ReconciliationDisabledConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             ReconciliationDisabledReason,
+		Message:            message,
+	})
+	readyChanged := meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ReadyConditionType,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             ReconciliationDisabledReason,
+		Message:            message,
+	})
+	return changed || readyChanged
+}
+
+// clearReconciliationDisabled removes the ReconciliationDisabled condition, and the Ready condition if it was set
+// because of it; the reconcile following sets the Ready condition again
+func clearReconciliationDisabled(conditions *[]metav1.Condition) bool {
+	if !meta.IsStatusConditionTrue(*conditions, ReconciliationDisabledConditionType) {
+		return false
+	}
+	meta.RemoveStatusCondition(conditions, ReconciliationDisabledConditionType)
+	if ready := meta.FindStatusCondition(*conditions, ReadyConditionType); ready != nil && ready.Reason == ReconciliationDisabledReason {
+		meta.RemoveStatusCondition(conditions, ReadyConditionType)
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestElasticsearchInstanceEnabled(t *testing.T) {
+	defer SetInstanceOverrides(nil, nil)
+	SetInstanceOverrides(map[string]configv2.InstanceOverride{
+		"prod/elasticsearch": {Enabled: boolPtr(false)},
+		"elasticsearch":      {Enabled: boolPtr(true)},
+		"legacy":             {Enabled: boolPtr(false)},
+		"staging/legacy":     {},
+	}, nil)
+
+	tests := []struct {
+		name      string
+		namespace string
+		instance  string
+		enabled   bool
+		want      bool
+	}{
+		{name: "namespace/name takes precedence", namespace: "prod", instance: "elasticsearch", enabled: true, want: false},
+		{name: "name in any namespace", namespace: "staging", instance: "elasticsearch", enabled: false, want: true},
+		{name: "override without enabled", namespace: "staging", instance: "legacy", enabled: true, want: false},
+		{name: "no override", namespace: "prod", instance: "other", enabled: true, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ElasticsearchInstanceEnabled(tt.namespace, tt.instance, tt.enabled); got != tt.want {
+				t.Errorf("ElasticsearchInstanceEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if !KibanaInstanceEnabled("prod", "elasticsearch", true) {
+		t.Error("Expected the overrides of ElasticsearchInstances not to apply to KibanaInstances")
+	}
+}
+
+func TestCheckReconciliationEnabled_Conditions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+
+	index := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "my-index", Namespace: "default", Generation: 2}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(index).WithStatusSubresource(index).Build()
+	recorder := record.NewFakeRecorder(10)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if CheckReconciliationEnabled(ctx, fakeClient, recorder, index, &index.Status.Conditions, false) {
+			t.Fatal("Expected the reconcile to be skipped while the instance is disabled")
+		}
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Expected a single ReconciliationDisabled event, got %d", len(recorder.Events))
+	}
+	if !meta.IsStatusConditionTrue(index.Status.Conditions, ReconciliationDisabledConditionType) {
+		t.Errorf("Expected the ReconciliationDisabled condition to be set, got %v", index.Status.Conditions)
+	}
+	if ready := meta.FindStatusCondition(index.Status.Conditions, ReadyConditionType); ready == nil ||
+		ready.Status != metav1.ConditionFalse || ready.Reason != ReconciliationDisabledReason {
+		t.Errorf("Expected the Ready condition to be False with reason ReconciliationDisabled, got %v", ready)
+	}
+
+	if !CheckReconciliationEnabled(ctx, fakeClient, recorder, index, &index.Status.Conditions, true) {
+		t.Fatal("Expected the reconcile to proceed once the instance is enabled")
+	}
+	if len(index.Status.Conditions) != 0 {
+		t.Errorf("Expected the ReconciliationDisabled and Ready conditions to be removed, got %v", index.Status.Conditions)
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
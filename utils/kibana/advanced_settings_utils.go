@@ -0,0 +1,135 @@
+package kibana
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// UpsertAdvancedSettings applies the declared settings whose current value differs and resets the settings managed
+// before which are no longer declared, all other settings are left untouched. It returns the keys managed afterwards.
+func UpsertAdvancedSettings(kClient Client, advancedSettings kibanaeckv1alpha1.AdvancedSettings) ([]string, ctrl.Result, error) {
+	managedKeys := advancedSettings.Status.ManagedKeys
+
+	var declared map[string]any
+	if err := json.Unmarshal([]byte(advancedSettings.Spec.GetBody()), &declared); err != nil {
+		return managedKeys, ctrl.Result{}, fmt.Errorf("spec.body is not a JSON object: %w", err)
+	}
+
+	current, err := GetAdvancedSettings(kClient, advancedSettings.Spec.Space)
+	if err != nil {
+		return managedKeys, utils.GetRequeueResult(), err
+	}
+
+	changes := make(map[string]any)
+	for key, value := range declared {
+		if currentValue, ok := current[key]; !ok || !reflect.DeepEqual(currentValue, value) {
+			changes[key] = value
+		}
+	}
+	for _, key := range managedKeys {
+		if _, stillDeclared := declared[key]; stillDeclared {
+			continue
+		}
+		if _, ok := current[key]; ok {
+			changes[key] = nil
+		}
+	}
+
+	if err := postAdvancedSettings(kClient, advancedSettings.Spec.Space, changes); err != nil {
+		return managedKeys, utils.GetRequeueResult(), err
+	}
+
+	keys := make([]string, 0, len(declared))
+	for key := range declared {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, ctrl.Result{}, nil
+}
+
+// DeleteAdvancedSettings resets the settings managed by the resource to their defaults
+func DeleteAdvancedSettings(kClient Client, advancedSettings kibanaeckv1alpha1.AdvancedSettings) (ctrl.Result, error) {
+	if len(advancedSettings.Status.ManagedKeys) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	current, err := GetAdvancedSettings(kClient, advancedSettings.Spec.Space)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+
+	changes := make(map[string]any)
+	for _, key := range advancedSettings.Status.ManagedKeys {
+		if _, ok := current[key]; ok {
+			changes[key] = nil
+		}
+	}
+	if err := postAdvancedSettings(kClient, advancedSettings.Spec.Space, changes); err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	return ctrl.Result{}, nil
+}
+
+// GetAdvancedSettings returns the user values of the advanced settings of the space, settings left at their default
+// are not included
+func GetAdvancedSettings(kClient Client, space *string) (map[string]any, error) {
+	res, err := kClient.DoGet(formatAdvancedSettingsUrl(space))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 {
+		return nil, nonSuccessResponseError(res)
+	}
+
+	var response struct {
+		Settings map[string]struct {
+			UserValue any `json:"userValue"`
+		} `json:"settings"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any, len(response.Settings))
+	for key, setting := range response.Settings {
+		if setting.UserValue != nil {
+			values[key] = setting.UserValue
+		}
+	}
+	return values, nil
+}
+
+func postAdvancedSettings(kClient Client, space *string, changes map[string]any) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(map[string]any{"changes": changes})
+	if err != nil {
+		return err
+	}
+
+	res, err := kClient.DoPost(formatAdvancedSettingsUrl(space), string(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 {
+		return nonSuccessResponseError(res)
+	}
+	return nil
+}
+
+func formatAdvancedSettingsUrl(space *string) string {
+	if space == nil {
+		return "/api/kibana/settings"
+	}
+	return fmt.Sprintf("/s/%s/api/kibana/settings", *space)
+}
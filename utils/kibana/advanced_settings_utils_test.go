@@ -0,0 +1,97 @@
+package kibana
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+)
+
+func TestFormatAdvancedSettingsUrl(t *testing.T) {
+	if got := formatAdvancedSettingsUrl(nil); got != "/api/kibana/settings" {
+		t.Errorf("formatAdvancedSettingsUrl(nil) = %s", got)
+	}
+	if got := formatAdvancedSettingsUrl(strPtr("ops")); got != "/s/ops/api/kibana/settings" {
+		t.Errorf("formatAdvancedSettingsUrl(ops) = %s", got)
+	}
+}
+
+func TestAdvancedSettingsLifecycle_FakeKibana(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	kClient := createTestKibanaClient(fakeKibana.URL())
+
+	fakeKibana.PutAdvancedSetting("ops", "timepicker:timeDefaults", `{"from":"now-1h","to":"now"}`)
+
+	advancedSettings := kibanaeckv1alpha1.AdvancedSettings{
+		Spec: kibanaeckv1alpha1.AdvancedSettingsSpec{
+			Space: strPtr("ops"),
+			Body:  `{"defaultRoute": "/app/dashboards", "dateFormat": "YYYY-MM-DD", "theme:darkMode": true}`,
+		},
+	}
+
+	managedKeys, _, err := UpsertAdvancedSettings(kClient, advancedSettings)
+	if err != nil {
+		t.Fatalf("UpsertAdvancedSettings() error = %v", err)
+	}
+	if want := []string{"dateFormat", "defaultRoute", "theme:darkMode"}; !reflect.DeepEqual(managedKeys, want) {
+		t.Errorf("UpsertAdvancedSettings() managed keys = %v, want %v", managedKeys, want)
+	}
+	if value, _ := fakeKibana.AdvancedSetting("ops", "defaultRoute"); string(value) != `"/app/dashboards"` {
+		t.Errorf("Expected defaultRoute to be set, got %s", value)
+	}
+	advancedSettings.Status.ManagedKeys = managedKeys
+
+	posts := fakeKibana.CountRequests(http.MethodPost, "/s/ops/api/kibana/settings")
+	if _, _, err := UpsertAdvancedSettings(kClient, advancedSettings); err != nil {
+		t.Fatalf("UpsertAdvancedSettings() error = %v", err)
+	}
+	if got := fakeKibana.CountRequests(http.MethodPost, "/s/ops/api/kibana/settings"); got != posts {
+		t.Errorf("Expected no update when the settings are unchanged, got %d new requests", got-posts)
+	}
+
+	advancedSettings.Spec.Body = `{"defaultRoute": "/app/discover"}`
+	managedKeys, _, err = UpsertAdvancedSettings(kClient, advancedSettings)
+	if err != nil {
+		t.Fatalf("UpsertAdvancedSettings() error = %v", err)
+	}
+	if value, _ := fakeKibana.AdvancedSetting("ops", "defaultRoute"); string(value) != `"/app/discover"` {
+		t.Errorf("Expected defaultRoute to be updated, got %s", value)
+	}
+	if _, ok := fakeKibana.AdvancedSetting("ops", "dateFormat"); ok {
+		t.Error("Expected dateFormat to be reset once no longer declared")
+	}
+	if _, ok := fakeKibana.AdvancedSetting("ops", "timepicker:timeDefaults"); !ok {
+		t.Error("Expected settings which were never declared to be left untouched")
+	}
+	advancedSettings.Status.ManagedKeys = managedKeys
+
+	if _, err := DeleteAdvancedSettings(kClient, advancedSettings); err != nil {
+		t.Fatalf("DeleteAdvancedSettings() error = %v", err)
+	}
+	if _, ok := fakeKibana.AdvancedSetting("ops", "defaultRoute"); ok {
+		t.Error("Expected defaultRoute to be reset on deletion")
+	}
+	if _, ok := fakeKibana.AdvancedSetting("ops", "timepicker:timeDefaults"); !ok {
+		t.Error("Expected unmanaged settings to survive the deletion")
+	}
+}
+
+func TestUpsertAdvancedSettings_InvalidBody(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+
+	advancedSettings := kibanaeckv1alpha1.AdvancedSettings{
+		Spec:   kibanaeckv1alpha1.AdvancedSettingsSpec{Body: `["defaultRoute"]`},
+		Status: kibanaeckv1alpha1.AdvancedSettingsStatus{ManagedKeys: []string{"defaultRoute"}},
+	}
+	managedKeys, _, err := UpsertAdvancedSettings(createTestKibanaClient(fakeKibana.URL()), advancedSettings)
+	if err == nil {
+		t.Fatal("Expected an error for a body which is not a JSON object")
+	}
+	if !reflect.DeepEqual(managedKeys, advancedSettings.Status.ManagedKeys) {
+		t.Errorf("Expected the managed keys to be kept on failure, got %v", managedKeys)
+	}
+}
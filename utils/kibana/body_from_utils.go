@@ -0,0 +1,94 @@
+package kibana
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	k8sv1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MaxBodyFromSize is the maximum size of a body read with bodyFrom after decompression
+const MaxBodyFromSize = 64 << 20
+
+// ResolveBodyFrom returns the saved object with the body read from the ConfigMap or Secret referenced by bodyFrom,
+// decompressed according to its encoding. Saved objects without bodyFrom are returned as they are.
+func ResolveBodyFrom(cli client.Client, ctx context.Context, namespace string, savedObject kibanaeckv1alpha1.SavedObject) (kibanaeckv1alpha1.SavedObject, error) {
+	bodyFrom := savedObject.BodyFrom
+	if bodyFrom == nil {
+		return savedObject, nil
+	}
+
+	stored, err := readBodyFrom(cli, ctx, namespace, *bodyFrom)
+	if err != nil {
+		return savedObject, err
+	}
+	body, err := decodeBody(stored, bodyFrom.Encoding)
+	if err != nil {
+		return savedObject, err
+	}
+	savedObject.Body = body
+	return savedObject, nil
+}
+
+func readBodyFrom(cli client.Client, ctx context.Context, namespace string, bodyFrom kibanaeckv1alpha1.BodyFrom) ([]byte, error) {
+	switch {
+	case bodyFrom.ConfigMapKeyRef != nil && bodyFrom.SecretKeyRef != nil:
+		return nil, errors.New("only one of bodyFrom.configMapKeyRef and bodyFrom.secretKeyRef may be set")
+	case bodyFrom.ConfigMapKeyRef != nil:
+		ref := bodyFrom.ConfigMapKeyRef
+		var configMap k8sv1.ConfigMap
+		if err := cli.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, &configMap); err != nil {
+			return nil, err
+		}
+		if value, ok := configMap.Data[ref.Key]; ok {
+			return []byte(value), nil
+		}
+		if value, ok := configMap.BinaryData[ref.Key]; ok {
+			return value, nil
+		}
+		return nil, fmt.Errorf("key %s not found in ConfigMap %s", ref.Key, ref.Name)
+	case bodyFrom.SecretKeyRef != nil:
+		ref := bodyFrom.SecretKeyRef
+		var secret k8sv1.Secret
+		if err := cli.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, &secret); err != nil {
+			return nil, err
+		}
+		if value, ok := secret.Data[ref.Key]; ok {
+			return value, nil
+		}
+		return nil, fmt.Errorf("key %s not found in Secret %s", ref.Key, ref.Name)
+	default:
+		return nil, errors.New("one of bodyFrom.configMapKeyRef and bodyFrom.secretKeyRef has to be set")
+	}
+}
+
+// decodeBody decompresses the stored body, refusing bodies larger than MaxBodyFromSize
+func decodeBody(stored []byte, encoding kibanaeckv1alpha1.BodyEncoding) (string, error) {
+	switch encoding {
+	case "", kibanaeckv1alpha1.BodyEncodingNone:
+		return string(stored), nil
+	case kibanaeckv1alpha1.BodyEncodingGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(stored))
+		if err != nil {
+			return "", fmt.Errorf("failed to decompress body: %w", err)
+		}
+		defer reader.Close()
+		body, err := io.ReadAll(io.LimitReader(reader, MaxBodyFromSize+1))
+		if err != nil {
+			return "", fmt.Errorf("failed to decompress body: %w", err)
+		}
+		if len(body) > MaxBodyFromSize {
+			return "", fmt.Errorf("decompressed body exceeds %d bytes", MaxBodyFromSize)
+		}
+		return string(body), nil
+	default:
+		return "", fmt.Errorf("unsupported body encoding %q", encoding)
+	}
+}
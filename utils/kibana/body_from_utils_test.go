@@ -0,0 +1,119 @@
+package kibana
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"strings"
+	"testing"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func gzipBody(t *testing.T, body string) []byte {
+	t.Helper()
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write([]byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return compressed.Bytes()
+}
+
+func TestResolveBodyFrom(t *testing.T) {
+	body := `{"attributes":{"title":"Large dashboard"}}`
+	scheme := runtime.NewScheme()
+	_ = k8sv1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&k8sv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "dashboards", Namespace: "kibana"},
+			Data:       map[string]string{"plain.json": body},
+			BinaryData: map[string][]byte{"large.json.gz": gzipBody(t, body)},
+		},
+		&k8sv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "dashboards", Namespace: "kibana"},
+			Data:       map[string][]byte{"large.json.gz": gzipBody(t, body), "broken.json.gz": []byte(body)},
+		},
+	).Build()
+
+	tests := []struct {
+		name     string
+		bodyFrom *kibanaeckv1alpha1.BodyFrom
+		want     string
+		wantErr  string
+	}{
+		{name: "without bodyFrom", want: "inline"},
+		{
+			name:     "ConfigMap data",
+			bodyFrom: &kibanaeckv1alpha1.BodyFrom{ConfigMapKeyRef: &kibanaeckv1alpha1.BodyKeyReference{Name: "dashboards", Key: "plain.json"}},
+			want:     body,
+		},
+		{
+			name: "gzip compressed ConfigMap binaryData",
+			bodyFrom: &kibanaeckv1alpha1.BodyFrom{
+				ConfigMapKeyRef: &kibanaeckv1alpha1.BodyKeyReference{Name: "dashboards", Key: "large.json.gz"},
+				Encoding:        kibanaeckv1alpha1.BodyEncodingGzip,
+			},
+			want: body,
+		},
+		{
+			name: "gzip compressed Secret",
+			bodyFrom: &kibanaeckv1alpha1.BodyFrom{
+				SecretKeyRef: &kibanaeckv1alpha1.BodyKeyReference{Name: "dashboards", Key: "large.json.gz"},
+				Encoding:     kibanaeckv1alpha1.BodyEncodingGzip,
+			},
+			want: body,
+		},
+		{
+			name: "not compressed",
+			bodyFrom: &kibanaeckv1alpha1.BodyFrom{
+				SecretKeyRef: &kibanaeckv1alpha1.BodyKeyReference{Name: "dashboards", Key: "broken.json.gz"},
+				Encoding:     kibanaeckv1alpha1.BodyEncodingGzip,
+			},
+			wantErr: "failed to decompress body",
+		},
+		{
+			name:     "missing key",
+			bodyFrom: &kibanaeckv1alpha1.BodyFrom{ConfigMapKeyRef: &kibanaeckv1alpha1.BodyKeyReference{Name: "dashboards", Key: "other.json"}},
+			wantErr:  "key other.json not found in ConfigMap dashboards",
+		},
+		{
+			name:     "no reference",
+			bodyFrom: &kibanaeckv1alpha1.BodyFrom{},
+			wantErr:  "has to be set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			savedObject := kibanaeckv1alpha1.SavedObject{Body: "inline", BodyFrom: tt.bodyFrom}
+			got, err := ResolveBodyFrom(cli, context.Background(), "kibana", savedObject)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ResolveBodyFrom() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveBodyFrom() error = %v", err)
+			}
+			if got.Body != tt.want {
+				t.Errorf("ResolveBodyFrom() body = %s, want %s", got.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeBody_SizeLimit(t *testing.T) {
+	large := strings.Repeat(" ", MaxBodyFromSize+1)
+	if _, err := decodeBody(gzipBody(t, large), kibanaeckv1alpha1.BodyEncodingGzip); err == nil {
+		t.Error("Expected a body exceeding the size limit to be rejected")
+	}
+}
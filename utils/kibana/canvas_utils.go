@@ -0,0 +1,92 @@
+package kibana
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const CanvasWorkpadSavedObjectType = "canvas-workpad"
+
+// NormalizeCanvasWorkpadBody prepares a canvas-workpad saved object body for the saved objects API.
+// Canvas keeps embedded assets (images) in attributes.assets keyed by asset id, and the Canvas app
+// expects every asset to carry its own id, type and creation timestamp. Workpad exports frequently
+// omit those, so they are filled in here; the creation timestamp is taken from created, the creation
+// time of the resource, so that the body is the same on every reconcile. A workpad without pages is
+// given a single empty page.
+func NormalizeCanvasWorkpadBody(body string, created time.Time) (string, error) {
+	var savedObject map[string]interface{}
+	if err := json.NewDecoder(strings.NewReader(body)).Decode(&savedObject); err != nil {
+		return "", fmt.Errorf("failed to parse canvas workpad body: %w", err)
+	}
+
+	attributes, ok := savedObject["attributes"].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("canvas workpad body must contain an attributes object")
+	}
+
+	if name, _ := attributes["name"].(string); name == "" {
+		return "", fmt.Errorf("canvas workpad attributes.name is required")
+	}
+
+	assets, ok := attributes["assets"].(map[string]interface{})
+	if !ok {
+		if attributes["assets"] != nil {
+			return "", fmt.Errorf("canvas workpad attributes.assets must be an object keyed by asset id")
+		}
+		assets = map[string]interface{}{}
+	}
+	for assetId, rawAsset := range assets {
+		asset, ok := rawAsset.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("canvas workpad asset %s must be an object", assetId)
+		}
+		value, _ := asset["value"].(string)
+		if !strings.HasPrefix(value, "data:") {
+			return "", fmt.Errorf("canvas workpad asset %s must have a data URL value", assetId)
+		}
+		asset["id"] = assetId
+		if _, ok := asset["type"]; !ok {
+			asset["type"] = "dataurl"
+		}
+		if _, ok := asset["@created"]; !ok {
+			asset["@created"] = created.UTC().Format(time.RFC3339)
+		}
+	}
+	attributes["assets"] = assets
+
+	if pages, ok := attributes["pages"].([]interface{}); !ok || len(pages) == 0 {
+		attributes["pages"] = []interface{}{
+			map[string]interface{}{
+				"id":       "page-1",
+				"style":    map[string]interface{}{"background": "#FFF"},
+				"elements": []interface{}{},
+				"groups":   []interface{}{},
+			},
+		}
+	}
+
+	marshalledBody, err := json.Marshal(savedObject)
+	if err != nil {
+		return "", err
+	}
+	return string(marshalledBody), nil
+}
+
+// UpsertCanvasWorkpad normalizes the workpad body and creates or updates it via the saved objects API, see
+// UpsertSavedObject for version.
+func UpsertCanvasWorkpad(kClient Client, workpadMeta metav1.ObjectMeta, savedObject kibanaeckv1alpha1.SavedObject, version *string) (ctrl.Result, error) {
+	body, err := NormalizeCanvasWorkpadBody(savedObject.Body, workpadMeta.CreationTimestamp.Time)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	savedObject.Body = body
+
+	return UpsertSavedObject(kClient, CanvasWorkpadSavedObjectType, workpadMeta, savedObject, version)
+}
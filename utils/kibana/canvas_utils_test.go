@@ -0,0 +1,159 @@
+package kibana
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNormalizeCanvasWorkpadBody(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+		check   func(t *testing.T, attributes map[string]interface{})
+	}{
+		{
+			name:    "invalid json",
+			body:    `{invalid`,
+			wantErr: true,
+		},
+		{
+			name:    "missing attributes",
+			body:    `{"references": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing name",
+			body:    `{"attributes": {"width": 1080}}`,
+			wantErr: true,
+		},
+		{
+			name:    "assets not an object",
+			body:    `{"attributes": {"name": "wp", "assets": []}}`,
+			wantErr: true,
+		},
+		{
+			name:    "asset without data url",
+			body:    `{"attributes": {"name": "wp", "assets": {"asset-1": {"value": "http://example.com/x.png"}}}}`,
+			wantErr: true,
+		},
+		{
+			name: "defaults assets and pages",
+			body: `{"attributes": {"name": "wp"}}`,
+			check: func(t *testing.T, attributes map[string]interface{}) {
+				if assets, ok := attributes["assets"].(map[string]interface{}); !ok || len(assets) != 0 {
+					t.Errorf("Expected empty assets object, got %v", attributes["assets"])
+				}
+				pages, ok := attributes["pages"].([]interface{})
+				if !ok || len(pages) != 1 {
+					t.Errorf("Expected a single default page, got %v", attributes["pages"])
+				}
+			},
+		},
+		{
+			name: "fills asset id, type and created timestamp",
+			body: `{"attributes": {"name": "wp", "pages": [{"id": "page-a"}], "assets": {"asset-1": {"value": "data:image/png;base64,AAAA"}}}}`,
+			check: func(t *testing.T, attributes map[string]interface{}) {
+				asset := attributes["assets"].(map[string]interface{})["asset-1"].(map[string]interface{})
+				if asset["id"] != "asset-1" {
+					t.Errorf("Expected asset id asset-1, got %v", asset["id"])
+				}
+				if asset["type"] != "dataurl" {
+					t.Errorf("Expected asset type dataurl, got %v", asset["type"])
+				}
+				if asset["@created"] != "2024-05-01T10:00:00Z" {
+					t.Errorf("Expected asset @created to be the creation time of the resource, got %v", asset["@created"])
+				}
+				pages := attributes["pages"].([]interface{})
+				if len(pages) != 1 || pages[0].(map[string]interface{})["id"] != "page-a" {
+					t.Errorf("Expected declared pages to be preserved, got %v", pages)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeCanvasWorkpadBody(tt.body, created)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeCanvasWorkpadBody() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var savedObject map[string]interface{}
+			if err := json.Unmarshal([]byte(got), &savedObject); err != nil {
+				t.Fatalf("Failed to parse normalized body: %v", err)
+			}
+			tt.check(t, savedObject["attributes"].(map[string]interface{}))
+		})
+	}
+}
+
+func TestUpsertCanvasWorkpad(t *testing.T) {
+	var postedBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/saved_objects/canvas-workpad/my-workpad" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			postedBody = string(body)
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("Unexpected method %s", r.Method)
+		}
+	}))
+	defer server.Close()
+
+	kClient := Client{
+		KibanaSpec: configv2.KibanaSpec{Url: server.URL},
+	}
+
+	_, err := UpsertCanvasWorkpad(kClient, metav1.ObjectMeta{Name: "my-workpad"}, kibanaeckv1alpha1.SavedObject{
+		Body: `{"attributes": {"name": "My Workpad"}}`,
+	}, nil)
+	if err != nil {
+		t.Fatalf("UpsertCanvasWorkpad() error = %v", err)
+	}
+
+	var savedObject map[string]interface{}
+	if err := json.Unmarshal([]byte(postedBody), &savedObject); err != nil {
+		t.Fatalf("Posted body is not valid JSON: %v", err)
+	}
+	attributes := savedObject["attributes"].(map[string]interface{})
+	if _, ok := attributes["assets"]; !ok {
+		t.Error("Expected posted body to contain normalized assets")
+	}
+}
+
+func TestUpsertCanvasWorkpad_InvalidBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expected no request for invalid body, got %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	kClient := Client{
+		KibanaSpec: configv2.KibanaSpec{Url: server.URL},
+	}
+
+	if _, err := UpsertCanvasWorkpad(kClient, metav1.ObjectMeta{Name: "my-workpad"}, kibanaeckv1alpha1.SavedObject{
+		Body: `{"attributes": {}}`,
+	}, nil); err == nil {
+		t.Error("Expected error for workpad without name")
+	}
+}
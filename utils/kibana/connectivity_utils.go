@@ -0,0 +1,70 @@
+package kibana
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+)
+
+// TestKibanaConnection runs the checks of a ConnectionTest against Kibana and records their results and the version
+// in status: the status API has to respond, accept the credentials, and saved objects of the space have to be
+// readable. It returns whether all checks passed. A check is only run if the previous one passed.
+func TestKibanaConnection(kClient Client, space string, status *eseckv1alpha1.ConnectionTestStatus) bool {
+	status.Checks = nil
+	status.Version = ""
+	status.Username = ""
+
+	res, err := kClient.DoGet("/api/status")
+	if err != nil {
+		return failCheck(status, eseckv1alpha1.ConnectionTestCheckConnectivity, err.Error())
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode >= http.StatusInternalServerError {
+		return failCheck(status, eseckv1alpha1.ConnectionTestCheckConnectivity, fmt.Sprintf("Kibana is unavailable: status %d, %s", res.StatusCode, body))
+	}
+	passCheck(status, eseckv1alpha1.ConnectionTestCheckConnectivity, fmt.Sprintf("Kibana responded with status %d", res.StatusCode))
+
+	if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+		return failCheck(status, eseckv1alpha1.ConnectionTestCheckAuthentication, fmt.Sprintf("authentication failed: status %d, %s", res.StatusCode, body))
+	}
+	var kibanaStatus struct {
+		Version struct {
+			Number string `json:"number"`
+		} `json:"version"`
+	}
+	if json.Unmarshal(body, &kibanaStatus) == nil {
+		status.Version = kibanaStatus.Version.Number
+	}
+	passCheck(status, eseckv1alpha1.ConnectionTestCheckAuthentication, "Kibana accepted the credentials")
+
+	path := "/api/saved_objects/_find?type=index-pattern&per_page=1"
+	spaceName := "default"
+	if space != "" {
+		path = fmt.Sprintf("/s/%s%s", space, path)
+		spaceName = space
+	}
+	res, err = kClient.DoGet(path)
+	if err != nil {
+		return failCheck(status, eseckv1alpha1.ConnectionTestCheckPermissions, err.Error())
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		return failCheck(status, eseckv1alpha1.ConnectionTestCheckPermissions, fmt.Sprintf("reading saved objects of the space %s failed: status %d, %s", spaceName, res.StatusCode, body))
+	}
+	passCheck(status, eseckv1alpha1.ConnectionTestCheckPermissions, fmt.Sprintf("Saved objects of the space %s are readable", spaceName))
+	return true
+}
+
+func passCheck(status *eseckv1alpha1.ConnectionTestStatus, name string, message string) {
+	status.Checks = append(status.Checks, eseckv1alpha1.ConnectionTestCheck{Name: name, Passed: true, Message: message})
+}
+
+func failCheck(status *eseckv1alpha1.ConnectionTestStatus, name string, message string) bool {
+	status.Checks = append(status.Checks, eseckv1alpha1.ConnectionTestCheck{Name: name, Message: message})
+	return false
+}
@@ -0,0 +1,41 @@
+package kibana
+
+import (
+	"testing"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+)
+
+func TestTestKibanaConnection_FakeKibana(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	kClient := createTestKibanaClient(fakeKibana.URL())
+
+	var status eseckv1alpha1.ConnectionTestStatus
+	if !TestKibanaConnection(kClient, "team-a", &status) {
+		t.Fatalf("TestKibanaConnection() failed, checks = %v", status.Checks)
+	}
+	if len(status.Checks) != 3 || status.Version != "8.15.0" {
+		t.Errorf("Expected three passed checks against 8.15.0, got %v, %q", status.Checks, status.Version)
+	}
+	if fakeKibana.CountRequests("GET", "/s/team-a/api/saved_objects/_find") != 1 {
+		t.Errorf("Expected the saved objects of the space team-a to be read, got %v", fakeKibana.Requests())
+	}
+
+	fakeKibana.FailRequests("GET", "/s/team-a/api/saved_objects/_find", 403, `{"message": "forbidden"}`)
+	if TestKibanaConnection(kClient, "team-a", &status) {
+		t.Fatal("TestKibanaConnection() passed, want the forbidden space to fail")
+	}
+	if len(status.Checks) != 3 || status.Checks[2].Name != eseckv1alpha1.ConnectionTestCheckPermissions || status.Checks[2].Passed {
+		t.Errorf("Expected the Permissions check to fail, got %v", status.Checks)
+	}
+
+	fakeKibana.FailRequests("GET", "/api/status", 401, `{"message": "unauthorized"}`)
+	if TestKibanaConnection(kClient, "", &status) {
+		t.Fatal("TestKibanaConnection() passed, want the rejected credentials to fail")
+	}
+	if len(status.Checks) != 2 || status.Checks[1].Name != eseckv1alpha1.ConnectionTestCheckAuthentication || status.Version != "" {
+		t.Errorf("Expected the Authentication check to fail, got %v", status.Checks)
+	}
+}
@@ -0,0 +1,126 @@
+package kibana
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BrokenReferences condition of Dashboards validating their references, set while panels reference saved objects
+// which do not exist in the space of the dashboard
+const (
+	BrokenReferencesConditionType = "BrokenReferences"
+	BrokenReferencesReason        = "BrokenReferences"
+)
+
+// BrokenReference is a reference of a saved object which does not resolve
+type BrokenReference struct {
+	// Name of the reference, e.g. the panelRefName of a panel
+	Name string
+	// Type and ID of the referenced saved object, empty for a panel without reference
+	Type string
+	ID   string
+}
+
+// String formats the reference like panel_1 -> lens/9b3d6a2e, or panel_1 -> no reference
+func (b BrokenReference) String() string {
+	if b.Type == "" {
+		return fmt.Sprintf("%s -> no reference", b.Name)
+	}
+	return fmt.Sprintf("%s -> %s/%s", b.Name, b.Type, b.ID)
+}
+
+// FindBrokenDashboardReferences reads the dashboard from Kibana and resolves its references against the saved
+// objects in its space, like the visualizations, lenses, saved searches and data views of its panels. Panels whose
+// panelRefName has no reference, neither by itself nor prefixed with the panelIndex, are broken as well. Each referenced saved object is looked up once.
+func FindBrokenDashboardReferences(kClient Client, id string, space *string) ([]BrokenReference, error) {
+	res, err := kClient.DoGet(formatSavedObjectUrl("dashboard", id, space))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get dashboard %s: status %d, %s", id, res.StatusCode, string(resBody))
+	}
+
+	var dashboard struct {
+		Attributes struct {
+			PanelsJSON string `json:"panelsJSON"`
+		} `json:"attributes"`
+		References []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+			ID   string `json:"id"`
+		} `json:"references"`
+	}
+	if err := json.Unmarshal(resBody, &dashboard); err != nil {
+		return nil, err
+	}
+
+	var broken []BrokenReference
+	referenceNames := make(map[string]bool)
+	exists := make(map[string]bool)
+	for _, reference := range dashboard.References {
+		referenceNames[reference.Name] = true
+		key := reference.Type + "/" + reference.ID
+		found, checked := exists[key]
+		if !checked {
+			if found, err = SavedObjectExists(kClient, reference.Type, reference.ID, space); err != nil {
+				return nil, err
+			}
+			exists[key] = found
+		}
+		if !found {
+			broken = append(broken, BrokenReference{Name: reference.Name, Type: reference.Type, ID: reference.ID})
+		}
+	}
+
+	if dashboard.Attributes.PanelsJSON != "" {
+		var panels []struct {
+			PanelIndex   string `json:"panelIndex"`
+			PanelRefName string `json:"panelRefName"`
+		}
+		if err := json.Unmarshal([]byte(dashboard.Attributes.PanelsJSON), &panels); err != nil {
+			return nil, fmt.Errorf("invalid panelsJSON of dashboard %s: %w", id, err)
+		}
+		for _, panel := range panels {
+			// Kibana 8 prefixes the names of the panel references with the panelIndex
+			if panel.PanelRefName != "" && !referenceNames[panel.PanelRefName] && !referenceNames[panel.PanelIndex+":"+panel.PanelRefName] {
+				broken = append(broken, BrokenReference{Name: panel.PanelRefName})
+			}
+		}
+	}
+	return broken, nil
+}
+
+// SetBrokenReferencesCondition sets the BrokenReferences condition of a dashboard with broken references and removes
+// it if there are none. It returns the message of the condition, empty if removed, and whether it changed.
+func SetBrokenReferencesCondition(conditions *[]metav1.Condition, generation int64, broken []BrokenReference) (string, bool) {
+	if len(broken) == 0 {
+		return "", meta.RemoveStatusCondition(conditions, BrokenReferencesConditionType)
+	}
+	formatted := make([]string, len(broken))
+	for i, reference := range broken {
+		formatted[i] = reference.String()
+	}
+	message := fmt.Sprintf("%d references do not resolve: %s", len(broken), strings.Join(formatted, ", "))
+
+	previous := meta.FindStatusCondition(*conditions, BrokenReferencesConditionType)
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               BrokenReferencesConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             BrokenReferencesReason,
+		Message:            message,
+	})
+	return message, previous == nil || previous.Message != message
+}
@@ -0,0 +1,66 @@
+package kibana
+
+import (
+	"reflect"
+	"testing"
+
+	"eck-custom-resources/testutils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFindBrokenDashboardReferences_FakeKibana(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	kClient := createTestKibanaClient(fakeKibana.URL())
+
+	fakeKibana.PutSavedObject("ops", "visualization", "requests", `{"attributes": {"title": "Requests"}}`)
+	fakeKibana.PutSavedObject("ops", "index-pattern", "logs", `{"attributes": {"title": "logs-*"}}`)
+	fakeKibana.PutSavedObject("ops", "dashboard", "overview", `{
+		"attributes": {
+			"title": "Overview",
+			"panelsJSON": "[{\"panelRefName\": \"panel_0\"}, {\"panelRefName\": \"panel_1\"}, {\"panelIndex\": \"7a1c\", \"panelRefName\": \"panel_7a1c\"}, {\"panelRefName\": \"panel_3\"}]"
+		},
+		"references": [
+			{"name": "panel_0", "type": "visualization", "id": "requests"},
+			{"name": "panel_1", "type": "lens", "id": "latency"},
+			{"name": "7a1c:panel_7a1c", "type": "visualization", "id": "requests"},
+			{"name": "kibanaSavedObjectMeta.searchSourceJSON.index", "type": "index-pattern", "id": "logs"}
+		]
+	}`)
+
+	broken, err := FindBrokenDashboardReferences(kClient, "overview", strPtr("ops"))
+	if err != nil {
+		t.Fatalf("FindBrokenDashboardReferences() error = %v", err)
+	}
+	want := []BrokenReference{
+		{Name: "panel_1", Type: "lens", ID: "latency"},
+		{Name: "panel_3"},
+	}
+	if !reflect.DeepEqual(broken, want) {
+		t.Errorf("FindBrokenDashboardReferences() = %+v, want %+v", broken, want)
+	}
+	if got := fakeKibana.CountRequests("GET", "/s/ops/api/saved_objects/visualization/requests"); got != 1 {
+		t.Errorf("Expected the visualization referenced twice to be requested once, got %d requests", got)
+	}
+
+	if _, err := FindBrokenDashboardReferences(kClient, "missing", strPtr("ops")); err == nil {
+		t.Error("Expected an error for a missing dashboard")
+	}
+}
+
+func TestSetBrokenReferencesCondition(t *testing.T) {
+	var conditions []metav1.Condition
+	broken := []BrokenReference{{Name: "panel_1", Type: "lens", ID: "latency"}, {Name: "panel_3"}}
+
+	message, changed := SetBrokenReferencesCondition(&conditions, 2, broken)
+	if want := "2 references do not resolve: panel_1 -> lens/latency, panel_3 -> no reference"; message != want || !changed {
+		t.Errorf("SetBrokenReferencesCondition() = %q, %v, want %q, true", message, changed, want)
+	}
+	if _, changed := SetBrokenReferencesCondition(&conditions, 3, broken); changed {
+		t.Error("Expected the same broken references not to change the condition")
+	}
+	if message, changed := SetBrokenReferencesCondition(&conditions, 3, nil); message != "" || !changed || len(conditions) != 0 {
+		t.Errorf("Expected the condition to be removed, got %q, %v, %v", message, changed, conditions)
+	}
+}
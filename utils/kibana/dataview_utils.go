@@ -16,8 +16,11 @@ import (
 const OVERRIDE = true
 const REFRESH_FIELDS = true
 
+// DefaultIndexSetting is the advanced setting holding the id of the default data view of a space
+const DefaultIndexSetting = "defaultIndex"
+
 func DeleteDataView(kClient Client, dataView kibanaeckv1alpha1.DataView) (ctrl.Result, error) {
-	_, deleteErr := kClient.DoDelete(formatExistingDataViewUrl(dataView.Name, dataView.Spec.Space))
+	_, deleteErr := kClient.DoDelete(formatExistingDataViewUrl(kibanaeckv1alpha1.SavedObjectIDOf(&dataView), dataView.Spec.Space))
 	return ctrl.Result{}, deleteErr
 }
 
@@ -36,7 +39,7 @@ func UpsertDataView(kClient Client, dataView kibanaeckv1alpha1.DataView) (ctrl.R
 	}
 
 	if exists {
-		res, err = kClient.DoPost(formatExistingDataViewUrl(dataView.Name, dataView.Spec.Space), *modifiedBody)
+		res, err = kClient.DoPost(formatExistingDataViewUrl(kibanaeckv1alpha1.SavedObjectIDOf(&dataView), dataView.Spec.Space), *modifiedBody)
 	} else {
 		res, err = kClient.DoPost(formatDataViewUrl(dataView.Spec.Space), *modifiedBody)
 	}
@@ -51,15 +54,48 @@ func UpsertDataView(kClient Client, dataView kibanaeckv1alpha1.DataView) (ctrl.R
 		}
 		return utils.GetRequeueResult(), fmt.Errorf("Non-success (%d) response: %s, ", res.StatusCode, string(resBody))
 	}
+	if !exists {
+		awaitReadable(kClient, formatExistingDataViewUrl(kibanaeckv1alpha1.SavedObjectIDOf(&dataView), dataView.Spec.Space))
+	}
 
 	return ctrl.Result{}, nil
 }
 
 func DataViewExists(kClient Client, dataView kibanaeckv1alpha1.DataView) (bool, error) {
-	res, err := kClient.DoGet(formatExistingDataViewUrl(dataView.Name, dataView.Spec.Space))
+	res, err := kClient.DoGet(formatExistingDataViewUrl(kibanaeckv1alpha1.SavedObjectIDOf(&dataView), dataView.Spec.Space))
 	return err == nil && res.StatusCode == 200, err
 }
 
+// SetDefaultDataView makes the data view the default data view of its space, unless it already is
+func SetDefaultDataView(kClient Client, dataView kibanaeckv1alpha1.DataView) error {
+	current, err := GetAdvancedSettings(kClient, dataView.Spec.Space)
+	if err != nil {
+		return err
+	}
+	if current[DefaultIndexSetting] == kibanaeckv1alpha1.SavedObjectIDOf(&dataView) {
+		return nil
+	}
+	return postAdvancedSettings(kClient, dataView.Spec.Space, map[string]any{DefaultIndexSetting: kibanaeckv1alpha1.SavedObjectIDOf(&dataView)})
+}
+
+// ResetDefaultDataView resets the default data view of the space of the data view if it is the data view, a default
+// data view chosen in Kibana in the meantime is kept
+func ResetDefaultDataView(kClient Client, dataView kibanaeckv1alpha1.DataView) error {
+	current, err := GetAdvancedSettings(kClient, dataView.Spec.Space)
+	if err != nil {
+		return err
+	}
+	if current[DefaultIndexSetting] != kibanaeckv1alpha1.SavedObjectIDOf(&dataView) {
+		return nil
+	}
+	return postAdvancedSettings(kClient, dataView.Spec.Space, map[string]any{DefaultIndexSetting: nil})
+}
+
+// GetDataViewAttributes returns the data view in Kibana as JSON, nil if it does not exist
+func GetDataViewAttributes(kClient Client, dataView kibanaeckv1alpha1.DataView) (*string, error) {
+	return getAttributes(kClient, formatExistingDataViewUrl(kibanaeckv1alpha1.SavedObjectIDOf(&dataView), dataView.Spec.Space), "data_view")
+}
+
 func formatExistingDataViewUrl(name string, space *string) string {
 	return fmt.Sprintf("%s/%s", formatDataViewUrl(space), name)
 }
@@ -74,10 +110,11 @@ func formatDataViewUrl(space *string) string {
 func wrapDataView(dataView kibanaeckv1alpha1.DataView, isUpdate bool) (*string, error) {
 	var err error
 
-	dataViewString := &dataView.Spec.Body
+	specBody := dataView.Spec.GetBody()
+	dataViewString := &specBody
 
 	if !isUpdate {
-		dataViewString, err = InjectId(*dataViewString, dataView.Name)
+		dataViewString, err = InjectId(*dataViewString, kibanaeckv1alpha1.SavedObjectIDOf(&dataView))
 		if err != nil {
 			return nil, err
 		}
@@ -8,6 +8,7 @@ import (
 
 	configv2 "eck-custom-resources/api/config/v2"
 	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/testutils"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -427,8 +428,9 @@ func TestUpsertDataView_Create(t *testing.T) {
 		t.Errorf("UpsertDataView() result = %v, want empty Result", result)
 	}
 
-	if callCount != 2 {
-		t.Errorf("Expected 2 HTTP calls, got %d", callCount)
+	// The existence check, the creation and the read after the creation
+	if callCount != 3 {
+		t.Errorf("Expected 3 HTTP calls, got %d", callCount)
 	}
 }
 
@@ -533,3 +535,73 @@ func createDataViewTestClient(serverURL string) Client {
 		Req: ctrl.Request{},
 	}
 }
+
+func TestDataViewLifecycle_FakeKibana(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	kClient := createDataViewTestClient(fakeKibana.URL())
+
+	dataView := createTestDataView("logs", `{"title": "logs-*", "name": "Logs"}`, nil)
+	if _, err := UpsertDataView(kClient, dataView); err != nil {
+		t.Fatalf("UpsertDataView() create error = %v", err)
+	}
+	dataView.Spec.Body = `{"title": "logs-*", "name": "Application logs"}`
+	if _, err := UpsertDataView(kClient, dataView); err != nil {
+		t.Fatalf("UpsertDataView() update error = %v", err)
+	}
+	if exists, err := DataViewExists(kClient, dataView); err != nil || !exists {
+		t.Fatalf("DataViewExists() = %v, %v, want true", exists, err)
+	}
+	if fakeKibana.CountRequests(http.MethodPost, "/api/data_views/data_view/logs") != 1 {
+		t.Error("Expected the second upsert to update the existing data view")
+	}
+
+	if _, err := DeleteDataView(kClient, dataView); err != nil {
+		t.Fatalf("DeleteDataView() error = %v", err)
+	}
+	if _, ok := fakeKibana.SavedObject(testutils.DefaultSpace, testutils.KibanaDataView, "logs"); ok {
+		t.Error("Expected the data view to be deleted")
+	}
+}
+
+func TestDefaultDataView_FakeKibana(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	kClient := createTestKibanaClient(fakeKibana.URL())
+
+	dataView := kibanaeckv1alpha1.DataView{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+		Spec:       kibanaeckv1alpha1.DataViewSpec{SavedObject: kibanaeckv1alpha1.SavedObject{Space: strPtr("ops")}, Default: true},
+	}
+
+	if err := SetDefaultDataView(kClient, dataView); err != nil {
+		t.Fatalf("SetDefaultDataView() error = %v", err)
+	}
+	if value, _ := fakeKibana.AdvancedSetting("ops", DefaultIndexSetting); string(value) != `"logs"` {
+		t.Errorf("Expected defaultIndex to be logs, got %s", value)
+	}
+
+	posts := fakeKibana.CountRequests(http.MethodPost, "/s/ops/api/kibana/settings")
+	if err := SetDefaultDataView(kClient, dataView); err != nil {
+		t.Fatalf("SetDefaultDataView() error = %v", err)
+	}
+	if got := fakeKibana.CountRequests(http.MethodPost, "/s/ops/api/kibana/settings"); got != posts {
+		t.Errorf("Expected no update when the data view is the default already, got %d new requests", got-posts)
+	}
+
+	if err := ResetDefaultDataView(kClient, dataView); err != nil {
+		t.Fatalf("ResetDefaultDataView() error = %v", err)
+	}
+	if _, ok := fakeKibana.AdvancedSetting("ops", DefaultIndexSetting); ok {
+		t.Error("Expected defaultIndex to be reset")
+	}
+
+	// A default chosen in Kibana in the meantime is kept
+	fakeKibana.PutAdvancedSetting("ops", DefaultIndexSetting, `"metrics"`)
+	if err := ResetDefaultDataView(kClient, dataView); err != nil {
+		t.Fatalf("ResetDefaultDataView() error = %v", err)
+	}
+	if value, _ := fakeKibana.AdvancedSetting("ops", DefaultIndexSetting); string(value) != `"metrics"` {
+		t.Errorf("Expected defaultIndex to be kept, got %s", value)
+	}
+}
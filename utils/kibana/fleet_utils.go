@@ -0,0 +1,159 @@
+package kibana
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// UpsertAgentPolicy creates the agent policy in Fleet or updates it if it exists. The id of the agent policy is the
+// name of the resource.
+func UpsertAgentPolicy(kClient Client, agentPolicy kibanaeckv1alpha1.AgentPolicy) (ctrl.Result, error) {
+	return upsertFleetObject(kClient, agentPolicy.Spec.Space, "agent_policies", agentPolicy.Name, agentPolicy.Spec.GetBody(), nil)
+}
+
+// DeleteAgentPolicy deletes the agent policy from Fleet. Fleet refuses to delete agent policies agents are still
+// enrolled in.
+func DeleteAgentPolicy(kClient Client, agentPolicy kibanaeckv1alpha1.AgentPolicy) (ctrl.Result, error) {
+	body, err := json.Marshal(map[string]string{"agentPolicyId": agentPolicy.Name})
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	res, err := kClient.DoPost(formatFleetUrl(agentPolicy.Spec.Space, "agent_policies/delete"), string(body))
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 && res.StatusCode != http.StatusNotFound {
+		return utils.GetRequeueResult(), nonSuccessResponseError(res)
+	}
+	return ctrl.Result{}, nil
+}
+
+// UpsertPackagePolicy creates the package policy in Fleet or updates it if it exists. The id of the package policy
+// is the name of the resource, spec.agentPolicies replace the agent policies of the body.
+func UpsertPackagePolicy(kClient Client, packagePolicy kibanaeckv1alpha1.PackagePolicy) (ctrl.Result, error) {
+	var fields map[string]interface{}
+	if len(packagePolicy.Spec.AgentPolicies) > 0 {
+		fields = map[string]interface{}{
+			"policy_id":  packagePolicy.Spec.AgentPolicies[0],
+			"policy_ids": packagePolicy.Spec.AgentPolicies,
+		}
+	}
+	return upsertFleetObject(kClient, packagePolicy.Spec.Space, "package_policies", packagePolicy.Name, packagePolicy.Spec.GetBody(), fields)
+}
+
+// DeletePackagePolicy deletes the package policy from Fleet
+func DeletePackagePolicy(kClient Client, packagePolicy kibanaeckv1alpha1.PackagePolicy) (ctrl.Result, error) {
+	res, err := kClient.DoDelete(formatFleetUrl(packagePolicy.Spec.Space, "package_policies/"+packagePolicy.Name))
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 && res.StatusCode != http.StatusNotFound {
+		return utils.GetRequeueResult(), nonSuccessResponseError(res)
+	}
+	return ctrl.Result{}, nil
+}
+
+// AgentPoliciesExist returns an error listing the agent policies of the package policy which do not exist in Fleet
+func AgentPoliciesExist(kClient Client, packagePolicy kibanaeckv1alpha1.PackagePolicy) error {
+	var missing []string
+	for _, id := range packagePolicy.Spec.AgentPolicies {
+		exists, err := FleetObjectExists(kClient, packagePolicy.Spec.Space, "agent_policies", id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("agent policies not found in Fleet: %s", strings.Join(missing, ","))
+	}
+	return nil
+}
+
+// FleetObjectExists checks whether the agent or package policy with the id exists in Fleet
+func FleetObjectExists(kClient Client, space *string, objectType string, id string) (bool, error) {
+	res, err := kClient.DoGet(formatFleetUrl(space, objectType+"/"+id))
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if res.StatusCode > 299 {
+		return false, nonSuccessResponseError(res)
+	}
+	return true, nil
+}
+
+// upsertFleetObject creates the object with the id by a POST to the Fleet API of the type, or replaces it by a PUT if
+// it exists. The fields are set on the body, Fleet only accepts the id on creation.
+func upsertFleetObject(kClient Client, space *string, objectType string, id string, body string, fields map[string]interface{}) (ctrl.Result, error) {
+	exists, err := FleetObjectExists(kClient, space, objectType, id)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+
+	path := formatFleetUrl(space, objectType+"/"+id)
+	doRequest := kClient.DoPut
+	if !exists {
+		path = formatFleetUrl(space, objectType)
+		doRequest = kClient.DoPost
+		if fields == nil {
+			fields = make(map[string]interface{})
+		}
+		fields["id"] = id
+	}
+	body, err = setFleetBodyFields(body, fields)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+
+	res, err := doRequest(path, body)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 {
+		return utils.GetRequeueResult(), nonSuccessResponseError(res)
+	}
+	return ctrl.Result{}, nil
+}
+
+func setFleetBodyFields(body string, fields map[string]interface{}) (string, error) {
+	if len(fields) == 0 {
+		return body, nil
+	}
+	parsed := make(map[string]interface{})
+	if strings.TrimSpace(body) != "" {
+		if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+			return "", err
+		}
+	}
+	for key, value := range fields {
+		parsed[key] = value
+	}
+	marshalled, err := json.Marshal(parsed)
+	if err != nil {
+		return "", err
+	}
+	return string(marshalled), nil
+}
+
+func formatFleetUrl(space *string, path string) string {
+	url := "/api/fleet/" + path
+	if space == nil {
+		return url
+	}
+	return fmt.Sprintf("/s/%s%s", *space, url)
+}
@@ -0,0 +1,98 @@
+package kibana
+
+import (
+	"strings"
+	"testing"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFormatFleetUrl(t *testing.T) {
+	tests := []struct {
+		name     string
+		space    *string
+		path     string
+		expected string
+	}{
+		{name: "default space", path: "agent_policies", expected: "/api/fleet/agent_policies"},
+		{name: "in space", space: strPtr("ops"), path: "package_policies/system", expected: "/s/ops/api/fleet/package_policies/system"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatFleetUrl(tt.space, tt.path); got != tt.expected {
+				t.Errorf("formatFleetUrl() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFleetPolicyLifecycle_FakeKibana(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	kClient := createTestKibanaClient(fakeKibana.URL())
+
+	agentPolicy := kibanaeckv1alpha1.AgentPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "hosts"},
+		Spec: kibanaeckv1alpha1.AgentPolicySpec{
+			Space: strPtr("ops"),
+			Body:  `{"name": "Hosts", "namespace": "default"}`,
+		},
+	}
+	packagePolicy := kibanaeckv1alpha1.PackagePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "system"},
+		Spec: kibanaeckv1alpha1.PackagePolicySpec{
+			Space:         strPtr("ops"),
+			AgentPolicies: []string{"hosts"},
+			Body:          `{"name": "system-1", "policy_id": "other", "package": {"name": "system", "version": "1.60.0"}}`,
+		},
+	}
+
+	if err := AgentPoliciesExist(kClient, packagePolicy); err == nil || !strings.Contains(err.Error(), "hosts") {
+		t.Errorf("AgentPoliciesExist() error = %v, want the missing agent policy hosts", err)
+	}
+
+	if _, err := UpsertAgentPolicy(kClient, agentPolicy); err != nil {
+		t.Fatalf("UpsertAgentPolicy() create error = %v", err)
+	}
+	if stored, ok := fakeKibana.SavedObject("ops", testutils.KibanaAgentPolicy, "hosts"); !ok || !strings.Contains(string(stored), `"id":"hosts"`) {
+		t.Fatalf("Expected the agent policy to be created with the name of the resource as id, got %s", stored)
+	}
+	agentPolicy.Spec.Body = `{"name": "Hosts", "namespace": "production"}`
+	if _, err := UpsertAgentPolicy(kClient, agentPolicy); err != nil {
+		t.Fatalf("UpsertAgentPolicy() update error = %v", err)
+	}
+	if fakeKibana.CountRequests("PUT", "/s/ops/api/fleet/agent_policies/hosts") != 1 {
+		t.Error("Expected the existing agent policy to be updated")
+	}
+
+	if err := AgentPoliciesExist(kClient, packagePolicy); err != nil {
+		t.Errorf("AgentPoliciesExist() error = %v", err)
+	}
+	if _, err := UpsertPackagePolicy(kClient, packagePolicy); err != nil {
+		t.Fatalf("UpsertPackagePolicy() create error = %v", err)
+	}
+	stored, _ := fakeKibana.SavedObject("ops", testutils.KibanaPackagePolicy, "system")
+	if !strings.Contains(string(stored), `"policy_id":"hosts"`) || !strings.Contains(string(stored), `"policy_ids":["hosts"]`) {
+		t.Errorf("Expected spec.agentPolicies to replace the agent policies of the body, got %s", stored)
+	}
+
+	if _, err := DeleteAgentPolicy(kClient, agentPolicy); err == nil {
+		t.Error("Expected Fleet to refuse deleting an agent policy with package policies")
+	}
+	if _, err := DeletePackagePolicy(kClient, packagePolicy); err != nil {
+		t.Fatalf("DeletePackagePolicy() error = %v", err)
+	}
+	if _, err := DeleteAgentPolicy(kClient, agentPolicy); err != nil {
+		t.Fatalf("DeleteAgentPolicy() error = %v", err)
+	}
+	if exists, err := FleetObjectExists(kClient, agentPolicy.Spec.Space, "agent_policies", "hosts"); err != nil || exists {
+		t.Errorf("FleetObjectExists() = %v, %v, want false", exists, err)
+	}
+	if _, err := DeleteAgentPolicy(kClient, agentPolicy); err != nil {
+		t.Errorf("Expected deleting an agent policy which is already gone to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package kibana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// DataViewSpaceAnnotation opts an Index or IndexTemplate in to a generated DataView, created in the given Kibana
+	// space once the resource is Ready. "default" is the default space.
+	DataViewSpaceAnnotation = "kibana.eck.github.com/data-view-space"
+	// DataViewTimeFieldAnnotation sets the time field of the generated DataView
+	DataViewTimeFieldAnnotation = "kibana.eck.github.com/data-view-time-field"
+	// DataViewKibanaInstanceAnnotation sets the KibanaInstance the generated DataView is deployed to, the Kibana of the
+	// operator configuration if not set
+	DataViewKibanaInstanceAnnotation = "kibana.eck.github.com/data-view-kibana-instance"
+	// GeneratedDataViewLabel is set on generated DataViews to the name of the resource they are generated from
+	GeneratedDataViewLabel = "kibana.eck.github.com/generated-from"
+)
+
+var dataViewAnnotations = []string{DataViewSpaceAnnotation, DataViewTimeFieldAnnotation, DataViewKibanaInstanceAnnotation}
+
+// GeneratedDataViewName returns the name of the DataView generated for the resource of the kind, which is also its id
+// in Kibana
+func GeneratedDataViewName(kind string, name string) string {
+	return strings.ToLower(kind) + "-" + name
+}
+
+// ReconcileGeneratedDataView creates or updates the DataView of the index patterns, controlled by owner, if owner is
+// annotated with DataViewSpaceAnnotation. Otherwise a DataView generated before is deleted. A DataView of the same
+// name managed by anything else is left untouched and an error is returned. The returned bool reports whether the
+// DataView was created or changed.
+func ReconcileGeneratedDataView(cli client.Client, ctx context.Context, owner client.Object, kind string, patterns []string) (bool, error) {
+	key := client.ObjectKey{Namespace: owner.GetNamespace(), Name: GeneratedDataViewName(kind, owner.GetName())}
+	var dataView kibanaeckv1alpha1.DataView
+	exists := true
+	if err := cli.Get(ctx, key, &dataView); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, err
+		}
+		exists = false
+		dataView = kibanaeckv1alpha1.DataView{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	}
+	generated := exists && metav1.IsControlledBy(&dataView, owner)
+
+	space, optedIn := owner.GetAnnotations()[DataViewSpaceAnnotation]
+	if !optedIn {
+		if !generated {
+			return false, nil
+		}
+		return true, client.IgnoreNotFound(cli.Delete(ctx, &dataView))
+	}
+	if exists && !generated {
+		return false, fmt.Errorf("DataView %s exists and is not generated from %s %s", key.Name, kind, owner.GetName())
+	}
+
+	spec, err := buildGeneratedDataViewSpec(owner, space, patterns)
+	if err != nil {
+		return false, err
+	}
+
+	if !exists {
+		dataView.Labels = map[string]string{GeneratedDataViewLabel: owner.GetName()}
+		dataView.Spec = spec
+		if err := controllerutil.SetControllerReference(owner, &dataView, cli.Scheme()); err != nil {
+			return false, err
+		}
+		return true, cli.Create(ctx, &dataView)
+	}
+
+	if dataView.Labels[GeneratedDataViewLabel] == owner.GetName() && equality.Semantic.DeepEqual(dataView.Spec, spec) {
+		return false, nil
+	}
+	patch := client.MergeFrom(dataView.DeepCopy())
+	if dataView.Labels == nil {
+		dataView.Labels = map[string]string{}
+	}
+	dataView.Labels[GeneratedDataViewLabel] = owner.GetName()
+	dataView.Spec = spec
+	return true, cli.Patch(ctx, &dataView, patch)
+}
+
+// DataViewAnnotationsChangedFilter passes updates changing the annotations of the generated DataView, which do not
+// change the generation of the resource
+func DataViewAnnotationsChangedFilter() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			for _, annotation := range dataViewAnnotations {
+				if e.ObjectOld.GetAnnotations()[annotation] != e.ObjectNew.GetAnnotations()[annotation] {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+func buildGeneratedDataViewSpec(owner client.Object, space string, patterns []string) (kibanaeckv1alpha1.DataViewSpec, error) {
+	body := map[string]string{
+		"title": strings.Join(patterns, ","),
+		"name":  owner.GetName(),
+	}
+	if timeField := owner.GetAnnotations()[DataViewTimeFieldAnnotation]; timeField != "" {
+		body["timeFieldName"] = timeField
+	}
+	marshalledBody, err := json.Marshal(body)
+	if err != nil {
+		return kibanaeckv1alpha1.DataViewSpec{}, err
+	}
+
+	spec := kibanaeckv1alpha1.DataViewSpec{
+		TargetConfig: kibanaeckv1alpha1.CommonKibanaConfig{
+			KibanaInstance: owner.GetAnnotations()[DataViewKibanaInstanceAnnotation],
+		},
+		SavedObject: kibanaeckv1alpha1.SavedObject{Body: string(marshalledBody)},
+	}
+	if space != "" && space != "default" {
+		spec.Space = &space
+	}
+	return spec, nil
+}
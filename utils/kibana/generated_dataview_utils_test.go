@@ -0,0 +1,101 @@
+package kibana
+
+import (
+	"context"
+	"testing"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileGeneratedDataView(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	_ = kibanaeckv1alpha1.AddToScheme(scheme)
+	indexTemplate := &eseckv1alpha1.IndexTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "logging", UID: "template-uid", Annotations: map[string]string{
+			DataViewSpaceAnnotation:     "ops",
+			DataViewTimeFieldAnnotation: "@timestamp",
+		}},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(indexTemplate).Build()
+	ctx := context.Background()
+	key := client.ObjectKey{Namespace: "logging", Name: "indextemplate-logs"}
+
+	changed, err := ReconcileGeneratedDataView(cli, ctx, indexTemplate, "IndexTemplate", []string{"logs-*", "audit-*"})
+	if err != nil || !changed {
+		t.Fatalf("ReconcileGeneratedDataView() = %v, %v, want the DataView to be created", changed, err)
+	}
+	var dataView kibanaeckv1alpha1.DataView
+	if err := cli.Get(ctx, key, &dataView); err != nil {
+		t.Fatalf("Expected the DataView to be created: %v", err)
+	}
+	if !metav1.IsControlledBy(&dataView, indexTemplate) || dataView.Labels[GeneratedDataViewLabel] != "logs" {
+		t.Errorf("Expected the DataView to be controlled by and labeled with the IndexTemplate, got %+v", dataView.ObjectMeta)
+	}
+	if dataView.Spec.Space == nil || *dataView.Spec.Space != "ops" {
+		t.Errorf("Expected the DataView in space ops, got %v", dataView.Spec.Space)
+	}
+	if want := `{"name":"logs","timeFieldName":"@timestamp","title":"logs-*,audit-*"}`; dataView.Spec.Body != want {
+		t.Errorf("DataView body = %s, want %s", dataView.Spec.Body, want)
+	}
+
+	changed, err = ReconcileGeneratedDataView(cli, ctx, indexTemplate, "IndexTemplate", []string{"logs-*", "audit-*"})
+	if err != nil || changed {
+		t.Errorf("ReconcileGeneratedDataView() = %v, %v, want no change", changed, err)
+	}
+
+	indexTemplate.Annotations[DataViewSpaceAnnotation] = "default"
+	changed, err = ReconcileGeneratedDataView(cli, ctx, indexTemplate, "IndexTemplate", []string{"logs-*"})
+	if err != nil || !changed {
+		t.Fatalf("ReconcileGeneratedDataView() = %v, %v, want the DataView to be updated", changed, err)
+	}
+	if err := cli.Get(ctx, key, &dataView); err != nil {
+		t.Fatal(err)
+	}
+	if dataView.Spec.Space != nil {
+		t.Errorf("Expected the DataView in the default space, got %s", *dataView.Spec.Space)
+	}
+
+	delete(indexTemplate.Annotations, DataViewSpaceAnnotation)
+	changed, err = ReconcileGeneratedDataView(cli, ctx, indexTemplate, "IndexTemplate", []string{"logs-*"})
+	if err != nil || !changed {
+		t.Fatalf("ReconcileGeneratedDataView() = %v, %v, want the DataView to be deleted", changed, err)
+	}
+	if err := cli.Get(ctx, key, &dataView); err == nil {
+		t.Error("Expected the DataView to be deleted")
+	}
+}
+
+func TestReconcileGeneratedDataView_NotOwned(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	_ = kibanaeckv1alpha1.AddToScheme(scheme)
+	index := &eseckv1alpha1.Index{
+		ObjectMeta: metav1.ObjectMeta{Name: "audit", Namespace: "logging", UID: "index-uid"},
+	}
+	existing := &kibanaeckv1alpha1.DataView{
+		ObjectMeta: metav1.ObjectMeta{Name: "index-audit", Namespace: "logging"},
+		Spec:       kibanaeckv1alpha1.DataViewSpec{SavedObject: kibanaeckv1alpha1.SavedObject{Body: `{"title":"audit"}`}},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(index, existing).Build()
+	ctx := context.Background()
+
+	if changed, err := ReconcileGeneratedDataView(cli, ctx, index, "Index", []string{"audit"}); err != nil || changed {
+		t.Errorf("ReconcileGeneratedDataView() = %v, %v, want a DataView not generated to be left untouched", changed, err)
+	}
+
+	index.Annotations = map[string]string{DataViewSpaceAnnotation: "default"}
+	if _, err := ReconcileGeneratedDataView(cli, ctx, index, "Index", []string{"audit"}); err == nil {
+		t.Error("Expected an error for a DataView not generated from the Index")
+	}
+	var dataView kibanaeckv1alpha1.DataView
+	if err := cli.Get(ctx, client.ObjectKeyFromObject(existing), &dataView); err != nil || dataView.Spec.Body != `{"title":"audit"}` {
+		t.Errorf("Expected the DataView to be left untouched, got %+v, %v", dataView.Spec, err)
+	}
+}
@@ -0,0 +1,227 @@
+package kibana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// MigrateToDataViewAnnotation migrates an IndexPattern to a DataView on Kibana 8+ targets. The value is the name
+	// of the DataView, "true" keeps the name of the IndexPattern.
+	MigrateToDataViewAnnotation = "kibana.eck.github.com/migrate-to-data-view"
+	// MigratedFromLabel is set on DataViews migrated from an IndexPattern to the name of the IndexPattern
+	MigratedFromLabel = "kibana.eck.github.com/migrated-from"
+)
+
+// Deprecated condition, set on IndexPatterns once they are migrated to a DataView
+const (
+	DeprecatedConditionType     = "Deprecated"
+	DeprecatedReasonDataView    = "MigratedToDataView"
+	indexPatternSavedObjectType = "index-pattern"
+)
+
+// dataViewJSONAttributes are the attributes of index pattern saved objects holding JSON strings, mapped to the fields
+// of the data views API taking the parsed values
+var dataViewJSONAttributes = map[string]string{
+	"fieldFormatMap":  "fieldFormats",
+	"fieldAttrs":      "fieldAttrs",
+	"runtimeFieldMap": "runtimeFieldMap",
+	"sourceFilters":   "sourceFilters",
+	"typeMeta":        "typeMeta",
+}
+
+// dataViewAttributes are the attributes of index pattern saved objects taken over as they are
+var dataViewAttributes = []string{"title", "name", "timeFieldName", "allowNoIndex", "type"}
+
+// IndexPatternMigrationTarget returns the name of the DataView the IndexPattern is migrated to, false if it is not
+// annotated with MigrateToDataViewAnnotation
+func IndexPatternMigrationTarget(indexPattern client.Object) (string, bool) {
+	target, ok := indexPattern.GetAnnotations()[MigrateToDataViewAnnotation]
+	if !ok || target == "" || target == "false" {
+		return "", false
+	}
+	if target == "true" {
+		return indexPattern.GetName(), true
+	}
+	return target, true
+}
+
+// GetKibanaMajorVersion returns the major version of Kibana as reported by its status API
+func GetKibanaMajorVersion(kClient Client) (int, error) {
+	res, err := kClient.DoGet("/api/status")
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return 0, err
+	}
+	if res.StatusCode > 299 {
+		return 0, fmt.Errorf("Non-success (%d) response: %s, ", res.StatusCode, string(resBody))
+	}
+
+	var status struct {
+		Version struct {
+			Number string `json:"number"`
+		} `json:"version"`
+	}
+	if err := json.Unmarshal(resBody, &status); err != nil {
+		return 0, err
+	}
+	major, _, _ := strings.Cut(status.Version.Number, ".")
+	version, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse Kibana version %q", status.Version.Number)
+	}
+	return version, nil
+}
+
+// IndexPatternToDataViewBody converts the body of an index pattern saved object to the body of a DataView. The
+// attributes holding JSON strings are parsed, fields are left out as data views compute them.
+func IndexPatternToDataViewBody(body string) (string, error) {
+	var savedObject struct {
+		Attributes map[string]any `json:"attributes"`
+	}
+	if err := json.Unmarshal([]byte(body), &savedObject); err != nil {
+		return "", err
+	}
+
+	dataView := make(map[string]any)
+	for _, attribute := range dataViewAttributes {
+		if value, ok := savedObject.Attributes[attribute]; ok {
+			dataView[attribute] = value
+		}
+	}
+	for attribute, field := range dataViewJSONAttributes {
+		value, ok := savedObject.Attributes[attribute].(string)
+		if !ok || value == "" {
+			continue
+		}
+		var parsed any
+		if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+			return "", fmt.Errorf("attribute %s is not valid JSON: %w", attribute, err)
+		}
+		dataView[field] = parsed
+	}
+	if _, ok := dataView["title"]; !ok {
+		return "", fmt.Errorf("the index pattern has no title")
+	}
+
+	marshalled, err := json.Marshal(dataView)
+	if err != nil {
+		return "", err
+	}
+	return string(marshalled), nil
+}
+
+// ReconcileMigratedDataView creates the DataView the IndexPattern is migrated to, in the namespace of the IndexPattern
+// and with its target instance, space and dependencies, and returns it. body is the body of the IndexPattern. A
+// DataView of the same name not migrated from the IndexPattern is left untouched and an error is returned.
+func ReconcileMigratedDataView(cli client.Client, ctx context.Context, indexPattern *kibanaeckv1alpha1.IndexPattern, name string, body string) (*kibanaeckv1alpha1.DataView, error) {
+	dataViewBody, err := IndexPatternToDataViewBody(body)
+	if err != nil {
+		return nil, err
+	}
+	spec := kibanaeckv1alpha1.DataViewSpec{
+		TargetConfig: indexPattern.Spec.TargetConfig,
+		SavedObject: kibanaeckv1alpha1.SavedObject{
+			Space:        indexPattern.Spec.Space,
+			Dependencies: indexPattern.Spec.Dependencies,
+			Body:         dataViewBody,
+		},
+	}
+
+	var dataView kibanaeckv1alpha1.DataView
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: indexPattern.Namespace, Name: name}, &dataView); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		dataView = kibanaeckv1alpha1.DataView{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: indexPattern.Namespace,
+				Name:      name,
+				Labels:    map[string]string{MigratedFromLabel: indexPattern.Name},
+			},
+			Spec: spec,
+		}
+		return &dataView, cli.Create(ctx, &dataView)
+	}
+	if dataView.Labels[MigratedFromLabel] != indexPattern.Name {
+		return nil, fmt.Errorf("DataView %s exists and is not migrated from IndexPattern %s", name, indexPattern.Name)
+	}
+	// Once created, the DataView is managed on its own, e.g. after being added to the manifests of the IndexPattern
+	return &dataView, nil
+}
+
+// MigrateIndexPatternReferences replaces the references to the index pattern in the saved objects of the space with
+// references to the data view, and returns the saved objects changed as type/id
+func MigrateIndexPatternReferences(kClient Client, space *string, indexPatternID string, dataViewID string) ([]string, error) {
+	hasReference, err := json.Marshal(map[string]string{"type": indexPatternSavedObjectType, "id": indexPatternID})
+	if err != nil {
+		return nil, err
+	}
+	findUrl := fmt.Sprintf("/api/saved_objects/_find?per_page=10000&has_reference=%s", url.QueryEscape(string(hasReference)))
+	if space != nil {
+		findUrl = fmt.Sprintf("/s/%s%s", *space, findUrl)
+	}
+
+	res, err := kClient.DoGet(findUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode > 299 {
+		return nil, fmt.Errorf("Non-success (%d) response: %s, ", res.StatusCode, string(resBody))
+	}
+
+	var found struct {
+		SavedObjects []struct {
+			Type       string                   `json:"type"`
+			ID         string                   `json:"id"`
+			Attributes json.RawMessage          `json:"attributes"`
+			References []map[string]interface{} `json:"references"`
+		} `json:"saved_objects"`
+	}
+	if err := json.Unmarshal(resBody, &found); err != nil {
+		return nil, err
+	}
+
+	var migrated []string
+	for _, savedObject := range found.SavedObjects {
+		for _, reference := range savedObject.References {
+			if reference["type"] == indexPatternSavedObjectType && reference["id"] == indexPatternID {
+				reference["id"] = dataViewID
+			}
+		}
+		body, err := json.Marshal(map[string]any{"attributes": savedObject.Attributes, "references": savedObject.References})
+		if err != nil {
+			return migrated, err
+		}
+		res, err := kClient.DoPut(formatSavedObjectUrl(savedObject.Type, savedObject.ID, space), string(body))
+		if err != nil {
+			return migrated, err
+		}
+		res.Body.Close()
+		if res.StatusCode > 299 {
+			return migrated, fmt.Errorf("Non-success (%d) response updating the references of %s/%s", res.StatusCode, savedObject.Type, savedObject.ID)
+		}
+		migrated = append(migrated, savedObject.Type+"/"+savedObject.ID)
+	}
+	return migrated, nil
+}
@@ -0,0 +1,132 @@
+package kibana
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIndexPatternToDataViewBody(t *testing.T) {
+	body, err := IndexPatternToDataViewBody(`{"attributes": {"title": "logs-*", "timeFieldName": "@timestamp",
+		"fields": "[]", "fieldFormatMap": "{\"bytes\":{\"id\":\"bytes\"}}", "sourceFilters": "[{\"value\":\"secret\"}]"}}`)
+	if err != nil {
+		t.Fatalf("IndexPatternToDataViewBody() error = %v", err)
+	}
+	var dataView map[string]any
+	if err := json.Unmarshal([]byte(body), &dataView); err != nil {
+		t.Fatal(err)
+	}
+	if dataView["title"] != "logs-*" || dataView["timeFieldName"] != "@timestamp" {
+		t.Errorf("Expected title and timeFieldName to be taken over, got %s", body)
+	}
+	if _, ok := dataView["fields"]; ok {
+		t.Errorf("Expected fields to be left out, got %s", body)
+	}
+	if formats, ok := dataView["fieldFormats"].(map[string]any); !ok || formats["bytes"] == nil {
+		t.Errorf("Expected fieldFormatMap to be parsed into fieldFormats, got %s", body)
+	}
+	if filters, ok := dataView["sourceFilters"].([]any); !ok || len(filters) != 1 {
+		t.Errorf("Expected sourceFilters to be parsed, got %s", body)
+	}
+
+	if _, err := IndexPatternToDataViewBody(`{"attributes": {"timeFieldName": "@timestamp"}}`); err == nil {
+		t.Error("Expected an error for an index pattern without title")
+	}
+}
+
+func TestIndexPatternMigrationTarget(t *testing.T) {
+	tests := []struct {
+		annotation string
+		want       string
+		wantOk     bool
+	}{
+		{annotation: "", wantOk: false},
+		{annotation: "false", wantOk: false},
+		{annotation: "true", want: "logs", wantOk: true},
+		{annotation: "logs-view", want: "logs-view", wantOk: true},
+	}
+	for _, tt := range tests {
+		indexPattern := &kibanaeckv1alpha1.IndexPattern{ObjectMeta: metav1.ObjectMeta{Name: "logs"}}
+		if tt.annotation != "" {
+			indexPattern.Annotations = map[string]string{MigrateToDataViewAnnotation: tt.annotation}
+		}
+		got, ok := IndexPatternMigrationTarget(indexPattern)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("IndexPatternMigrationTarget(%q) = %q, %v, want %q, %v", tt.annotation, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestReconcileMigratedDataView(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = kibanaeckv1alpha1.AddToScheme(scheme)
+	space := "ops"
+	indexPattern := &kibanaeckv1alpha1.IndexPattern{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "logging"},
+		Spec: kibanaeckv1alpha1.IndexPatternSpec{
+			TargetConfig: kibanaeckv1alpha1.CommonKibanaConfig{KibanaInstance: "kibana"},
+			SavedObject:  kibanaeckv1alpha1.SavedObject{Space: &space},
+		},
+	}
+	other := &kibanaeckv1alpha1.DataView{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "logging"}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(indexPattern, other).Build()
+	ctx := context.Background()
+
+	dataView, err := ReconcileMigratedDataView(cli, ctx, indexPattern, "logs", `{"attributes": {"title": "logs-*"}}`)
+	if err != nil {
+		t.Fatalf("ReconcileMigratedDataView() error = %v", err)
+	}
+	var created kibanaeckv1alpha1.DataView
+	if err := cli.Get(ctx, client.ObjectKeyFromObject(dataView), &created); err != nil {
+		t.Fatalf("Expected the DataView to be created: %v", err)
+	}
+	if created.Labels[MigratedFromLabel] != "logs" || created.Spec.TargetConfig.KibanaInstance != "kibana" ||
+		created.Spec.Space == nil || *created.Spec.Space != "ops" || created.Spec.Body != `{"title":"logs-*"}` {
+		t.Errorf("Expected the DataView to take over the IndexPattern, got %+v", created)
+	}
+
+	if _, err := ReconcileMigratedDataView(cli, ctx, indexPattern, "other", `{"attributes": {"title": "logs-*"}}`); err == nil {
+		t.Error("Expected an error for a DataView not migrated from the IndexPattern")
+	}
+}
+
+func TestMigrateIndexPatternReferences_FakeKibana(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	kClient := createTestKibanaClient(fakeKibana.URL())
+
+	space := "ops"
+	fakeKibana.PutSavedObject(space, "visualization", "requests", `{"attributes": {"title": "Requests"},
+		"references": [{"type": "index-pattern", "id": "logs", "name": "kibanaSavedObjectMeta.searchSourceJSON.index"}]}`)
+	fakeKibana.PutSavedObject(space, "search", "errors", `{"attributes": {"title": "Errors"},
+		"references": [{"type": "index-pattern", "id": "metrics", "name": "kibanaSavedObjectMeta.searchSourceJSON.index"}]}`)
+
+	if major, err := GetKibanaMajorVersion(kClient); err != nil || major != 8 {
+		t.Fatalf("GetKibanaMajorVersion() = %d, %v, want 8", major, err)
+	}
+
+	migrated, err := MigrateIndexPatternReferences(kClient, &space, "logs", "logs-view")
+	if err != nil {
+		t.Fatalf("MigrateIndexPatternReferences() error = %v", err)
+	}
+	if len(migrated) != 1 || migrated[0] != "visualization/requests" {
+		t.Errorf("Expected the visualization to be migrated, got %v", migrated)
+	}
+	visualization, _ := fakeKibana.SavedObject(space, "visualization", "requests")
+	if !strings.Contains(string(visualization), `"id":"logs-view"`) {
+		t.Errorf("Expected the reference to point to the data view, got %s", visualization)
+	}
+	search, _ := fakeKibana.SavedObject(space, "search", "errors")
+	if !strings.Contains(string(search), `"id": "metrics"`) {
+		t.Errorf("Expected other references to be left untouched, got %s", search)
+	}
+}
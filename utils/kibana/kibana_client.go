@@ -1,10 +1,13 @@
 package kibana
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"strings"
 
@@ -12,6 +15,8 @@ import (
 	"eck-custom-resources/utils"
 
 	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -22,6 +27,8 @@ type Client struct {
 	KibanaSpec      configv2.KibanaSpec
 	KibanaNamespace string
 	Req             ctrl.Request
+	// Elasticsearch elasticsearchIndex dependencies are resolved against, unless they reference an instance
+	Elasticsearch configv2.ElasticsearchSpec
 }
 
 func (kClient Client) DoGet(path string) (*http.Response, error) {
@@ -51,6 +58,39 @@ func (kClient Client) DoPut(path string, body string) (*http.Response, error) {
 	return kClient.doRequest(httpRequest)
 }
 
+func (kClient Client) DoPatch(path string, body string) (*http.Response, error) {
+	httpRequest, err := http.NewRequest("PATCH", kClient.KibanaSpec.Url+path, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return kClient.doRequest(httpRequest)
+}
+
+// DoPostFile posts the content as the file of a multipart form, as expected by the saved objects import API
+func (kClient Client) DoPostFile(path string, fileName string, content string) (*http.Response, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write([]byte(content)); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	httpRequest, err := http.NewRequest("POST", kClient.KibanaSpec.Url+path, &body)
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return kClient.doRequest(httpRequest)
+}
+
 func (kClient Client) DoDelete(path string) (*http.Response, error) {
 	httpRequest, err := http.NewRequest("DELETE", kClient.KibanaSpec.Url+path, nil)
 	if err != nil {
@@ -60,9 +100,29 @@ func (kClient Client) DoDelete(path string) (*http.Response, error) {
 	return kClient.doRequest(httpRequest)
 }
 
+// CheckTargetAvailable short-circuits reconciles against the Kibana instance while its circuit is open, the
+// instance is probed with its status API
+func CheckTargetAvailable(kClient Client, recorder record.EventRecorder, obj client.Object, conditions *[]metav1.Condition) (bool, ctrl.Result) {
+	return utils.CheckTargetAvailable(kClient.Ctx, kClient.Cli, recorder, obj, conditions, kClient.KibanaSpec.Url, func(context.Context) error {
+		res, err := kClient.DoGet("/api/status")
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		if res.StatusCode >= http.StatusBadGateway && res.StatusCode <= http.StatusGatewayTimeout {
+			return fmt.Errorf("status %d", res.StatusCode)
+		}
+		return nil
+	})
+}
+
 func (kClient Client) getHttpClient() (*http.Client, error) {
 
-	tr := &http.Transport{}
+	proxy, err := utils.ProxyFunc(kClient.KibanaSpec.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	tr := &http.Transport{Proxy: proxy}
 
 	namespace := kClient.Req.Namespace
 	if kClient.KibanaNamespace != "" {
@@ -75,6 +135,8 @@ func (kClient Client) getHttpClient() (*http.Client, error) {
 			return nil, err
 		}
 
+		utils.RegisterCertificateSecret(kClient.KibanaSpec.Certificate.SecretNamespace(namespace), kClient.KibanaSpec.Certificate.SecretName, kClient.KibanaSpec.Url)
+
 		caCertPool := x509.NewCertPool()
 		caCertPool.AppendCertsFromPEM(certificateSecret.Data[kClient.KibanaSpec.Certificate.CertificateKey])
 
@@ -87,7 +149,7 @@ func (kClient Client) getHttpClient() (*http.Client, error) {
 	}
 
 	httpClient := &http.Client{
-		Transport: tr,
+		Transport: utils.TracingTransport(kClient.Ctx, utils.CircuitBreakerTransport(kClient.KibanaSpec.Url, tr)),
 	}
 
 	return httpClient, nil
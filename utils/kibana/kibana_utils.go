@@ -8,7 +8,9 @@ import (
 
 	configv2 "eck-custom-resources/api/config/v2"
 	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/utils"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -39,8 +41,15 @@ func GetTargetInstance(cli client.Client, ctx context.Context, namespace string,
 	return nil
 }
 
+//+kubebuilder:rbac:groups=kibana.eck.github.com,resources=clusterkibanainstances,verbs=get;list;watch
+
 // GetKibanaTargetInstance resolves the target Kibana instance from either the project config
 // or a named KibanaInstance resource. It returns the KibanaSpec to use for API calls.
+// The instance is taken from, in this order: the name of targetConfig, the selector of targetConfig, the
+// DefaultKibanaInstanceAnnotation of the namespace and the project config. A targetConfig of the kind
+// ClusterKibanaInstance resolves the name and selector among the ClusterKibanaInstances.
+// The kibanaInstances overrides of the project config take precedence over the enabled setting
+// of a named instance.
 func GetKibanaTargetInstance(
 	cli client.Client,
 	ctx context.Context,
@@ -51,17 +60,115 @@ func GetKibanaTargetInstance(
 	namespace string,
 ) (*configv2.KibanaSpec, error) {
 	targetInstance := defaultKibana
-	if targetConfig.KibanaInstance != "" {
-		if targetConfig.KibanaInstanceNamespace != "" {
-			namespace = targetConfig.KibanaInstanceNamespace
+	instanceName := targetConfig.KibanaInstance
+	if instanceName == "" && targetConfig.Selector == nil {
+		namespaceDefault, err := utils.GetNamespaceDefault(cli, ctx, namespace, utils.DefaultKibanaInstanceAnnotation)
+		if err != nil {
+			return nil, err
+		}
+		instanceName = namespaceDefault
+	}
+	if targetConfig.KibanaInstanceNamespace != "" {
+		namespace = targetConfig.KibanaInstanceNamespace
+	}
+	switch {
+	case targetConfig.IsClusterInstance() && instanceName != "":
+		var resourceInstance kibanaeckv1alpha1.ClusterKibanaInstance
+		if err := cli.Get(ctx, client.ObjectKey{Name: instanceName}, &resourceInstance); err != nil {
+			recorder.Event(object, "Warning", "Failed to load target instance", fmt.Sprintf("Target instance not found: %s", err.Error()))
+			return nil, err
+		}
+		return useKibanaInstance(recorder, object, resourceInstance.Spec, "", resourceInstance.Name)
+	case targetConfig.IsClusterInstance() && targetConfig.Selector != nil:
+		var resourceInstance kibanaeckv1alpha1.ClusterKibanaInstance
+		if err := SelectTargetClusterInstance(cli, ctx, targetConfig.Selector, &resourceInstance); err != nil {
+			recorder.Event(object, "Warning", "Failed to load target instance", fmt.Sprintf("Target instance not selected: %s", err.Error()))
+			return nil, err
 		}
+		return useKibanaInstance(recorder, object, resourceInstance.Spec, "", resourceInstance.Name)
+	case instanceName != "":
 		var resourceInstance kibanaeckv1alpha1.KibanaInstance
-		if err := GetTargetInstance(cli, ctx, namespace, targetConfig.KibanaInstance, &resourceInstance); err != nil {
+		if err := GetTargetInstance(cli, ctx, namespace, instanceName, &resourceInstance); err != nil {
 			recorder.Event(object, "Warning", "Failed to load target instance", fmt.Sprintf("Target instance not found: %s", err.Error()))
 			return nil, err
 		}
-
-		targetInstance = resourceInstance.Spec
+		return useKibanaInstance(recorder, object, resourceInstance.Spec, resourceInstance.Namespace, resourceInstance.Name)
+	case targetConfig.Selector != nil:
+		var resourceInstance kibanaeckv1alpha1.KibanaInstance
+		if err := SelectTargetInstance(cli, ctx, namespace, targetConfig.Selector, &resourceInstance); err != nil {
+			recorder.Event(object, "Warning", "Failed to load target instance", fmt.Sprintf("Target instance not selected: %s", err.Error()))
+			return nil, err
+		}
+		return useKibanaInstance(recorder, object, resourceInstance.Spec, resourceInstance.Namespace, resourceInstance.Name)
 	}
 	return &targetInstance, nil
 }
+
+// useKibanaInstance returns the spec of the instance after checking the namespaces of its Secrets, applying the
+// kibanaInstances overrides of the project config. The namespace of a ClusterKibanaInstance is empty.
+func useKibanaInstance(recorder record.EventRecorder, object runtime.Object, spec configv2.KibanaSpec, namespace string, name string) (*configv2.KibanaSpec, error) {
+	var auth *configv2.UsernamePasswordAuthentication
+	if spec.Authentication != nil {
+		auth = spec.Authentication.UsernamePassword
+	}
+	if err := utils.ValidateInstanceSecrets(namespace, auth, spec.Certificate); err != nil {
+		recorder.Event(object, "Warning", "Failed to load target instance", fmt.Sprintf("Target instance %s is invalid: %s", name, err.Error()))
+		return nil, err
+	}
+	spec.Enabled = utils.KibanaInstanceEnabled(namespace, name, spec.Enabled)
+	return &spec, nil
+}
+
+// SelectTargetInstance gets the single KibanaInstance in the namespace matching the selector, it returns an error
+// if none or more than one match
+func SelectTargetInstance(cli client.Client, ctx context.Context, namespace string, selector *metav1.LabelSelector, kibanaInstance *kibanaeckv1alpha1.KibanaInstance) error {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return fmt.Errorf("invalid target instance selector: %w", err)
+	}
+	var instances kibanaeckv1alpha1.KibanaInstanceList
+	if err := cli.List(ctx, &instances, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: labelSelector}); err != nil {
+		return err
+	}
+	switch len(instances.Items) {
+	case 0:
+		return fmt.Errorf("no KibanaInstance in namespace %s matches the selector %q", namespace, labelSelector.String())
+	case 1:
+		*kibanaInstance = instances.Items[0]
+		return nil
+	default:
+		names := make([]string, len(instances.Items))
+		for i, instance := range instances.Items {
+			names[i] = instance.Name
+		}
+		return fmt.Errorf("the selector %q is ambiguous, it matches the KibanaInstances %s in namespace %s",
+			labelSelector.String(), strings.Join(names, ", "), namespace)
+	}
+}
+
+// SelectTargetClusterInstance gets the single ClusterKibanaInstance matching the selector, it returns an error if
+// none or more than one match
+func SelectTargetClusterInstance(cli client.Client, ctx context.Context, selector *metav1.LabelSelector, kibanaInstance *kibanaeckv1alpha1.ClusterKibanaInstance) error {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return fmt.Errorf("invalid target instance selector: %w", err)
+	}
+	var instances kibanaeckv1alpha1.ClusterKibanaInstanceList
+	if err := cli.List(ctx, &instances, client.MatchingLabelsSelector{Selector: labelSelector}); err != nil {
+		return err
+	}
+	switch len(instances.Items) {
+	case 0:
+		return fmt.Errorf("no ClusterKibanaInstance matches the selector %q", labelSelector.String())
+	case 1:
+		*kibanaInstance = instances.Items[0]
+		return nil
+	default:
+		names := make([]string, len(instances.Items))
+		for i, instance := range instances.Items {
+			names[i] = instance.Name
+		}
+		return fmt.Errorf("the selector %q is ambiguous, it matches the ClusterKibanaInstances %s",
+			labelSelector.String(), strings.Join(names, ", "))
+	}
+}
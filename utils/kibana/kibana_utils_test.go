@@ -1,7 +1,18 @@
 package kibana
 
 import (
+	"context"
 	"testing"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestInjectId(t *testing.T) {
@@ -115,3 +126,149 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestGetKibanaTargetInstance_Selector(t *testing.T) {
+	instance := func(name string, color string) *kibanaeckv1alpha1.KibanaInstance {
+		return &kibanaeckv1alpha1.KibanaInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "logging", Labels: map[string]string{"app": "kibana", "color": color}},
+			Spec:       configv2.KibanaSpec{Enabled: true, Url: "https://" + name + ":5601"},
+		}
+	}
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = kibanaeckv1alpha1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance("kibana-blue", "blue"), instance("kibana-green", "green")).Build()
+
+	tests := []struct {
+		name     string
+		selector *metav1.LabelSelector
+		wantUrl  string
+		wantErr  bool
+	}{
+		{name: "single match", selector: &metav1.LabelSelector{MatchLabels: map[string]string{"color": "blue"}}, wantUrl: "https://kibana-blue:5601"},
+		{name: "ambiguous", selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "kibana"}}, wantErr: true},
+		{name: "no match", selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "grafana"}}, wantErr: true},
+		{
+			name: "invalid selector",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "color", Operator: "Near"},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			space := &kibanaeckv1alpha1.Space{ObjectMeta: metav1.ObjectMeta{Name: "ops", Namespace: "logging"}}
+			targetInstance, err := GetKibanaTargetInstance(cli, context.Background(), record.NewFakeRecorder(10), space,
+				configv2.KibanaSpec{}, kibanaeckv1alpha1.CommonKibanaConfig{Selector: tt.selector}, "logging")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetKibanaTargetInstance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && targetInstance.Url != tt.wantUrl {
+				t.Errorf("GetKibanaTargetInstance() url = %s, want %s", targetInstance.Url, tt.wantUrl)
+			}
+		})
+	}
+}
+
+func TestGetKibanaTargetInstance_NamespaceDefault(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = kibanaeckv1alpha1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: map[string]string{utils.DefaultKibanaInstanceAnnotation: "kibana-team"}}},
+		&kibanaeckv1alpha1.KibanaInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "kibana-team", Namespace: "team-a"},
+			Spec:       configv2.KibanaSpec{Enabled: true, Url: "https://kibana-team:5601"},
+		},
+	).Build()
+
+	for _, tt := range []struct {
+		namespace string
+		wantUrl   string
+	}{
+		{namespace: "team-a", wantUrl: "https://kibana-team:5601"},
+		{namespace: "team-b", wantUrl: "https://default:5601"},
+	} {
+		space := &kibanaeckv1alpha1.Space{ObjectMeta: metav1.ObjectMeta{Name: "ops", Namespace: tt.namespace}}
+		targetInstance, err := GetKibanaTargetInstance(cli, context.Background(), record.NewFakeRecorder(10), space,
+			configv2.KibanaSpec{Url: "https://default:5601"}, kibanaeckv1alpha1.CommonKibanaConfig{}, tt.namespace)
+		if err != nil {
+			t.Fatalf("GetKibanaTargetInstance() error = %v", err)
+		}
+		if targetInstance.Url != tt.wantUrl {
+			t.Errorf("GetKibanaTargetInstance() in %s url = %s, want %s", tt.namespace, targetInstance.Url, tt.wantUrl)
+		}
+	}
+}
+
+func TestGetKibanaTargetInstance_ClusterInstance(t *testing.T) {
+	certificate := func(namespace string) *configv2.PublicCertificate {
+		return &configv2.PublicCertificate{SecretName: "kibana-certs", CertificateKey: "ca.crt", Namespace: namespace}
+	}
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = kibanaeckv1alpha1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&kibanaeckv1alpha1.ClusterKibanaInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Labels: map[string]string{"app": "kibana"}},
+			Spec:       configv2.KibanaSpec{Enabled: true, Url: "https://shared:5601", Certificate: certificate("elastic-system")},
+		},
+		&kibanaeckv1alpha1.ClusterKibanaInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "no-secret-namespace"},
+			Spec:       configv2.KibanaSpec{Enabled: true, Url: "https://other:5601", Certificate: certificate("")},
+		},
+		&kibanaeckv1alpha1.KibanaInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "foreign-secret", Namespace: "logging"},
+			Spec:       configv2.KibanaSpec{Enabled: true, Url: "https://foreign:5601", Certificate: certificate("elastic-system")},
+		},
+	).Build()
+
+	tests := []struct {
+		name         string
+		targetConfig kibanaeckv1alpha1.CommonKibanaConfig
+		wantUrl      string
+		wantErr      bool
+	}{
+		{
+			name:         "by name",
+			targetConfig: kibanaeckv1alpha1.CommonKibanaConfig{Kind: kibanaeckv1alpha1.ClusterKibanaInstanceKind, KibanaInstance: "shared"},
+			wantUrl:      "https://shared:5601",
+		},
+		{
+			name: "by selector",
+			targetConfig: kibanaeckv1alpha1.CommonKibanaConfig{
+				Kind: kibanaeckv1alpha1.ClusterKibanaInstanceKind, Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "kibana"}},
+			},
+			wantUrl: "https://shared:5601",
+		},
+		{
+			name:         "namespaced kind",
+			targetConfig: kibanaeckv1alpha1.CommonKibanaConfig{KibanaInstance: "shared"},
+			wantErr:      true,
+		},
+		{
+			name:         "cluster instance without secret namespace",
+			targetConfig: kibanaeckv1alpha1.CommonKibanaConfig{Kind: kibanaeckv1alpha1.ClusterKibanaInstanceKind, KibanaInstance: "no-secret-namespace"},
+			wantErr:      true,
+		},
+		{
+			name:         "namespaced instance with secret of another namespace",
+			targetConfig: kibanaeckv1alpha1.CommonKibanaConfig{KibanaInstance: "foreign-secret"},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			space := &kibanaeckv1alpha1.Space{ObjectMeta: metav1.ObjectMeta{Name: "ops", Namespace: "logging"}}
+			targetInstance, err := GetKibanaTargetInstance(cli, context.Background(), record.NewFakeRecorder(10), space,
+				configv2.KibanaSpec{}, tt.targetConfig, "logging")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetKibanaTargetInstance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && targetInstance.Url != tt.wantUrl {
+				t.Errorf("GetKibanaTargetInstance() url = %s, want %s", targetInstance.Url, tt.wantUrl)
+			}
+		})
+	}
+}
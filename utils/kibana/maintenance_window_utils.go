@@ -0,0 +1,134 @@
+package kibana
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// UpsertMaintenanceWindow updates the maintenance window recorded in the status, or creates it if it does not exist
+// (anymore). A maintenance window cannot be moved, if the space of the spec changed it is deleted from the space it
+// was created in and created again. It returns the id of the maintenance window in Kibana.
+func UpsertMaintenanceWindow(kClient Client, maintenanceWindow kibanaeckv1alpha1.MaintenanceWindow) (string, ctrl.Result, error) {
+	id := maintenanceWindow.Status.MaintenanceWindowID
+	space := maintenanceWindow.Spec.Space
+
+	if id != "" && MaintenanceWindowSpace(space) != deployedMaintenanceWindowSpace(maintenanceWindow) {
+		if res, err := DeleteMaintenanceWindow(kClient, maintenanceWindow); err != nil {
+			return id, res, err
+		}
+		id = ""
+	}
+
+	if id != "" {
+		exists, err := MaintenanceWindowExists(kClient, id, space)
+		if err != nil {
+			return id, utils.GetRequeueResult(), err
+		}
+		if exists {
+			res, err := kClient.DoPatch(formatMaintenanceWindowUrl(space, id), maintenanceWindow.Spec.GetBody())
+			if err != nil {
+				return id, utils.GetRequeueResult(), err
+			}
+			defer res.Body.Close()
+			if res.StatusCode > 299 {
+				return id, utils.GetRequeueResult(), nonSuccessResponseError(res)
+			}
+			return id, ctrl.Result{}, nil
+		}
+	}
+
+	res, err := kClient.DoPost(formatMaintenanceWindowUrl(space, ""), maintenanceWindow.Spec.GetBody())
+	if err != nil {
+		return "", utils.GetRequeueResult(), err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 {
+		return "", utils.GetRequeueResult(), nonSuccessResponseError(res)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&created); err != nil {
+		return "", utils.GetRequeueResult(), err
+	}
+	return created.ID, ctrl.Result{}, nil
+}
+
+// DeleteMaintenanceWindow deletes the maintenance window recorded in the status, if any, from the space it was
+// created in
+func DeleteMaintenanceWindow(kClient Client, maintenanceWindow kibanaeckv1alpha1.MaintenanceWindow) (ctrl.Result, error) {
+	id := maintenanceWindow.Status.MaintenanceWindowID
+	if id == "" {
+		return ctrl.Result{}, nil
+	}
+
+	space := deployedMaintenanceWindowSpace(maintenanceWindow)
+	res, err := kClient.DoDelete(formatMaintenanceWindowUrl(&space, id))
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 && res.StatusCode != http.StatusNotFound {
+		return utils.GetRequeueResult(), nonSuccessResponseError(res)
+	}
+	return ctrl.Result{}, nil
+}
+
+// MaintenanceWindowSpace returns the id of the space a maintenance window with the given spec space is created in
+func MaintenanceWindowSpace(space *string) string {
+	if space == nil || *space == "" {
+		return DefaultSpaceID
+	}
+	return *space
+}
+
+// deployedMaintenanceWindowSpace returns the space recorded in the status. Maintenance windows created before the
+// space was recorded are still in the space of the spec.
+func deployedMaintenanceWindowSpace(maintenanceWindow kibanaeckv1alpha1.MaintenanceWindow) string {
+	if maintenanceWindow.Status.Space != "" {
+		return maintenanceWindow.Status.Space
+	}
+	return MaintenanceWindowSpace(maintenanceWindow.Spec.Space)
+}
+
+func MaintenanceWindowExists(kClient Client, id string, space *string) (bool, error) {
+	res, err := kClient.DoGet(formatMaintenanceWindowUrl(space, id))
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if res.StatusCode > 299 {
+		return false, nonSuccessResponseError(res)
+	}
+	return true, nil
+}
+
+func formatMaintenanceWindowUrl(space *string, id string) string {
+	url := "/api/maintenance_window"
+	if id != "" {
+		url = fmt.Sprintf("%s/%s", url, id)
+	}
+	if space == nil || *space == DefaultSpaceID {
+		return url
+	}
+	return fmt.Sprintf("/s/%s%s", *space, url)
+}
+
+func nonSuccessResponseError(res *http.Response) error {
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("Non-success (%d) response: %s, ", res.StatusCode, string(resBody))
+}
@@ -0,0 +1,177 @@
+package kibana
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFormatMaintenanceWindowUrl(t *testing.T) {
+	tests := []struct {
+		name     string
+		space    *string
+		id       string
+		expected string
+	}{
+		{name: "create in default space", expected: "/api/maintenance_window"},
+		{name: "existing in default space", id: "abc", expected: "/api/maintenance_window/abc"},
+		{name: "create in space", space: strPtr("ops"), expected: "/s/ops/api/maintenance_window"},
+		{name: "existing in space", space: strPtr("ops"), id: "abc", expected: "/s/ops/api/maintenance_window/abc"},
+		{name: "existing in explicit default space", space: strPtr("default"), id: "abc", expected: "/api/maintenance_window/abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatMaintenanceWindowUrl(tt.space, tt.id); got != tt.expected {
+				t.Errorf("formatMaintenanceWindowUrl() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMaintenanceWindowLifecycle_FakeKibana(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	kClient := createTestKibanaClient(fakeKibana.URL())
+
+	maintenanceWindow := kibanaeckv1alpha1.MaintenanceWindow{
+		ObjectMeta: metav1.ObjectMeta{Name: "deployment"},
+		Spec: kibanaeckv1alpha1.MaintenanceWindowSpec{
+			Space: strPtr("ops"),
+			Body:  `{"title": "Deployment", "enabled": true}`,
+		},
+	}
+
+	id, _, err := UpsertMaintenanceWindow(kClient, maintenanceWindow)
+	if err != nil {
+		t.Fatalf("UpsertMaintenanceWindow() create error = %v", err)
+	}
+	if id == "" {
+		t.Fatal("Expected the id assigned by Kibana to be returned")
+	}
+	maintenanceWindow.Status.MaintenanceWindowID = id
+
+	maintenanceWindow.Spec.Body = `{"enabled": false}`
+	updatedID, _, err := UpsertMaintenanceWindow(kClient, maintenanceWindow)
+	if err != nil {
+		t.Fatalf("UpsertMaintenanceWindow() update error = %v", err)
+	}
+	if updatedID != id {
+		t.Errorf("Expected the existing maintenance window %s to be updated, got %s", id, updatedID)
+	}
+	stored, _ := fakeKibana.SavedObject("ops", testutils.KibanaMaintenanceWindow, id)
+	if !strings.Contains(string(stored), `"enabled":false`) || !strings.Contains(string(stored), `"title":"Deployment"`) {
+		t.Errorf("Expected the maintenance window to be patched, got %s", stored)
+	}
+
+	if _, err := DeleteMaintenanceWindow(kClient, maintenanceWindow); err != nil {
+		t.Fatalf("DeleteMaintenanceWindow() error = %v", err)
+	}
+	if exists, err := MaintenanceWindowExists(kClient, id, maintenanceWindow.Spec.Space); err != nil || exists {
+		t.Errorf("MaintenanceWindowExists() = %v, %v, want false", exists, err)
+	}
+}
+
+func TestUpsertMaintenanceWindow_RecreatesDeleted(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	kClient := createTestKibanaClient(fakeKibana.URL())
+
+	maintenanceWindow := kibanaeckv1alpha1.MaintenanceWindow{
+		Spec:   kibanaeckv1alpha1.MaintenanceWindowSpec{Body: `{"title": "Deployment"}`},
+		Status: kibanaeckv1alpha1.MaintenanceWindowStatus{MaintenanceWindowID: "deleted-in-kibana"},
+	}
+
+	id, _, err := UpsertMaintenanceWindow(kClient, maintenanceWindow)
+	if err != nil {
+		t.Fatalf("UpsertMaintenanceWindow() error = %v", err)
+	}
+	if id == "deleted-in-kibana" || !fakeKibana.Exists(testutils.DefaultSpace+"/"+testutils.KibanaMaintenanceWindow, id) {
+		t.Errorf("Expected a new maintenance window to be created, got id %s", id)
+	}
+}
+
+func TestUpsertMaintenanceWindow_SpaceChanged(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	kClient := createTestKibanaClient(fakeKibana.URL())
+
+	maintenanceWindow := kibanaeckv1alpha1.MaintenanceWindow{
+		Spec: kibanaeckv1alpha1.MaintenanceWindowSpec{Space: strPtr("ops"), Body: `{"title": "Deployment"}`},
+	}
+	id, _, err := UpsertMaintenanceWindow(kClient, maintenanceWindow)
+	if err != nil {
+		t.Fatalf("UpsertMaintenanceWindow() create error = %v", err)
+	}
+	maintenanceWindow.Status.MaintenanceWindowID = id
+	maintenanceWindow.Status.Space = MaintenanceWindowSpace(maintenanceWindow.Spec.Space)
+
+	maintenanceWindow.Spec.Space = nil
+	movedID, _, err := UpsertMaintenanceWindow(kClient, maintenanceWindow)
+	if err != nil {
+		t.Fatalf("UpsertMaintenanceWindow() move error = %v", err)
+	}
+	if fakeKibana.Exists("ops/"+testutils.KibanaMaintenanceWindow, id) {
+		t.Error("Expected the maintenance window to be deleted from the space it was created in")
+	}
+	if !fakeKibana.Exists(testutils.DefaultSpace+"/"+testutils.KibanaMaintenanceWindow, movedID) {
+		t.Errorf("Expected the maintenance window to be created in the default space, got id %s", movedID)
+	}
+
+	maintenanceWindow.Status.MaintenanceWindowID = movedID
+	maintenanceWindow.Status.Space = MaintenanceWindowSpace(maintenanceWindow.Spec.Space)
+	maintenanceWindow.Spec.Space = strPtr("ops")
+	if _, err := DeleteMaintenanceWindow(kClient, maintenanceWindow); err != nil {
+		t.Fatalf("DeleteMaintenanceWindow() error = %v", err)
+	}
+	if fakeKibana.Exists(testutils.DefaultSpace+"/"+testutils.KibanaMaintenanceWindow, movedID) {
+		t.Error("Expected the maintenance window to be deleted from the recorded space, not the one of the spec")
+	}
+}
+
+func TestDeleteMaintenanceWindow(t *testing.T) {
+	tests := []struct {
+		name             string
+		id               string
+		serverStatusCode int
+		wantErr          bool
+		wantRequest      bool
+	}{
+		{name: "not created yet", id: "", wantRequest: false},
+		{name: "deleted", id: "abc", serverStatusCode: http.StatusNoContent, wantRequest: true},
+		{name: "already gone", id: "abc", serverStatusCode: http.StatusNotFound, wantRequest: true},
+		{name: "server error", id: "abc", serverStatusCode: http.StatusInternalServerError, wantErr: true, wantRequest: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requested := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requested = true
+				if r.Method != http.MethodDelete || r.URL.Path != "/api/maintenance_window/abc" {
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				w.WriteHeader(tt.serverStatusCode)
+			}))
+			defer server.Close()
+
+			maintenanceWindow := kibanaeckv1alpha1.MaintenanceWindow{
+				Status: kibanaeckv1alpha1.MaintenanceWindowStatus{MaintenanceWindowID: tt.id},
+			}
+			_, err := DeleteMaintenanceWindow(createTestKibanaClient(server.URL), maintenanceWindow)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeleteMaintenanceWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if requested != tt.wantRequest {
+				t.Errorf("Expected request %v, got %v", tt.wantRequest, requested)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package kibana
+
+import (
+	"net/http"
+
+	"eck-custom-resources/utils"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// PutRawRequest sends a PUT request with the body to the path of the target instance
+func PutRawRequest(kClient Client, path string, body string) (ctrl.Result, error) {
+	res, err := kClient.DoPut(path, body)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 {
+		return utils.GetRequeueResult(), nonSuccessResponseError(res)
+	}
+	return ctrl.Result{}, nil
+}
+
+// DeleteRawRequest sends a DELETE request to the path of the target instance, objects which are already gone are
+// not reported as error
+func DeleteRawRequest(kClient Client, path string) (ctrl.Result, error) {
+	res, err := kClient.DoDelete(path)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 && res.StatusCode != http.StatusNotFound {
+		return utils.GetRequeueResult(), nonSuccessResponseError(res)
+	}
+	return ctrl.Result{}, nil
+}
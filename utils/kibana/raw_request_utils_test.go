@@ -0,0 +1,53 @@
+package kibana
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRawRequest(t *testing.T) {
+	tests := []struct {
+		name             string
+		delete           bool
+		serverStatusCode int
+		wantErr          bool
+	}{
+		{name: "put", serverStatusCode: http.StatusOK},
+		{name: "put rejected", serverStatusCode: http.StatusBadRequest, wantErr: true},
+		{name: "delete", delete: true, serverStatusCode: http.StatusOK},
+		{name: "delete already gone", delete: true, serverStatusCode: http.StatusNotFound},
+		{name: "delete failed", delete: true, serverStatusCode: http.StatusInternalServerError, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				wantMethod, wantBody := http.MethodPut, `{"stream": {}}`
+				if tt.delete {
+					wantMethod, wantBody = http.MethodDelete, ""
+				}
+				body, _ := io.ReadAll(r.Body)
+				if r.Method != wantMethod || r.URL.Path != "/s/ops/api/streams/logs.nginx" || string(body) != wantBody {
+					t.Errorf("Unexpected request %s %s %s", r.Method, r.URL.Path, body)
+				}
+				if r.Header.Get("kbn-xsrf") != "true" {
+					t.Error("Expected the kbn-xsrf header to be set")
+				}
+				w.WriteHeader(tt.serverStatusCode)
+			}))
+			defer server.Close()
+
+			var err error
+			if tt.delete {
+				_, err = DeleteRawRequest(createTestKibanaClient(server.URL), "/s/ops/api/streams/logs.nginx")
+			} else {
+				_, err = PutRawRequest(createTestKibanaClient(server.URL), "/s/ops/api/streams/logs.nginx", `{"stream": {}}`)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
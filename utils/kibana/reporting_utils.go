@@ -0,0 +1,192 @@
+package kibana
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+)
+
+// reportingParamsVersion is the Kibana version the generated job parameters are written for, Kibana migrates them
+// to its own version
+const reportingParamsVersion = "8.0.0"
+
+// reportingExportTypes maps the report formats to the Kibana export types
+var reportingExportTypes = map[kibanaeckv1alpha1.ReportingFormat]string{
+	kibanaeckv1alpha1.ReportingFormatPDF: "printablePdfV2",
+	kibanaeckv1alpha1.ReportingFormatPNG: "pngV2",
+	kibanaeckv1alpha1.ReportingFormatCSV: "csv_v2",
+}
+
+// VerifyReportingJob returns an error if the format cannot be generated for the type of the reported object
+func VerifyReportingJob(reportingJob kibanaeckv1alpha1.ReportingJob) error {
+	if _, ok := reportingExportTypes[reportingJob.Spec.Format]; !ok {
+		return fmt.Errorf("unsupported format %q", reportingJob.Spec.Format)
+	}
+	csv := reportingJob.Spec.Format == kibanaeckv1alpha1.ReportingFormatCSV
+	switch {
+	case csv && reportingJob.Spec.ObjectType != "search":
+		return fmt.Errorf("CSV reports are only generated for saved searches, not for %s", reportingJob.Spec.ObjectType)
+	case !csv && reportingJob.Spec.ObjectType != "dashboard":
+		return fmt.Errorf("%s reports are only generated for dashboards, not for %s", reportingJob.Spec.Format, reportingJob.Spec.ObjectType)
+	}
+	return nil
+}
+
+// GenerateReport triggers a report job and returns the id of the job and the path the report is downloaded from
+func GenerateReport(kClient Client, reportingJob kibanaeckv1alpha1.ReportingJob) (string, string, error) {
+	if err := VerifyReportingJob(reportingJob); err != nil {
+		return "", "", err
+	}
+	jobParams := reportingJob.Spec.JobParams
+	if jobParams == "" {
+		jobParams = buildReportingJobParams(reportingJob)
+	}
+
+	res, err := kClient.DoPost(formatReportingGenerateUrl(reportingJob.Spec.Space, reportingExportTypes[reportingJob.Spec.Format], jobParams), "")
+	if err != nil {
+		return "", "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 {
+		return "", "", nonSuccessResponseError(res)
+	}
+
+	var response struct {
+		Path string `json:"path"`
+		Job  struct {
+			ID string `json:"id"`
+		} `json:"job"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return "", "", err
+	}
+	if response.Path == "" {
+		return "", "", fmt.Errorf("reporting API returned no download path for job %s", response.Job.ID)
+	}
+	return response.Job.ID, response.Path, nil
+}
+
+// GetReportStatus returns the status of the report job downloaded from path, one of pending, completed or failed,
+// and the reason of a failure. The report itself is not read.
+func GetReportStatus(kClient Client, path string) (string, string, error) {
+	res, err := kClient.DoGet(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return kibanaeckv1alpha1.ReportingJobStatusCompleted, "", nil
+	case http.StatusServiceUnavailable:
+		return kibanaeckv1alpha1.ReportingJobStatusPending, "", nil
+	case http.StatusInternalServerError, http.StatusNotFound:
+		body, _ := io.ReadAll(res.Body)
+		var response struct {
+			Message string `json:"message"`
+		}
+		message := string(body)
+		if json.Unmarshal(body, &response) == nil && response.Message != "" {
+			message = response.Message
+		}
+		return kibanaeckv1alpha1.ReportingJobStatusFailed, message, nil
+	default:
+		return "", "", nonSuccessResponseError(res)
+	}
+}
+
+// buildReportingJobParams returns the rison encoded job parameters of the report, as the Kibana share menu
+// generates them
+func buildReportingJobParams(reportingJob kibanaeckv1alpha1.ReportingJob) string {
+	spec := reportingJob.Spec
+	timeZone := spec.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+
+	locatorParams := map[string]any{}
+	if spec.TimeRange != nil {
+		locatorParams["timeRange"] = map[string]any{"from": spec.TimeRange.From, "to": spec.TimeRange.To}
+	}
+	jobParams := map[string]any{
+		"browserTimezone": timeZone,
+		"objectType":      spec.ObjectType,
+		"title":           reportingJob.Name,
+		"version":         reportingParamsVersion,
+	}
+
+	locatorID := "DASHBOARD_APP_LOCATOR"
+	switch spec.Format {
+	case kibanaeckv1alpha1.ReportingFormatCSV:
+		locatorID = "DISCOVER_APP_LOCATOR"
+		locatorParams["savedSearchId"] = spec.ObjectID
+	case kibanaeckv1alpha1.ReportingFormatPNG:
+		locatorParams["dashboardId"] = spec.ObjectID
+		locatorParams["viewMode"] = "view"
+		jobParams["layout"] = map[string]any{"id": "preserve_layout", "dimensions": map[string]any{"width": 1920, "height": 1080}}
+	default:
+		locatorParams["dashboardId"] = spec.ObjectID
+		locatorParams["viewMode"] = "view"
+		jobParams["layout"] = map[string]any{"id": "preserve_layout"}
+	}
+	jobParams["locatorParams"] = []any{map[string]any{"id": locatorID, "params": locatorParams, "version": reportingParamsVersion}}
+	return encodeRison(jobParams)
+}
+
+var risonIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+
+// encodeRison encodes maps, slices, strings, booleans, numbers and nil as rison, which the reporting API expects
+// for the job parameters
+func encodeRison(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "!n"
+	case bool:
+		if v {
+			return "!t"
+		}
+		return "!f"
+	case int:
+		return strconv.Itoa(v)
+	case string:
+		if risonIdentifier.MatchString(v) && v != "true" && v != "false" && v != "null" {
+			return v
+		}
+		return "'" + strings.NewReplacer("!", "!!", "'", "!'").Replace(v) + "'"
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = encodeRison(item)
+		}
+		return "!(" + strings.Join(items, ",") + ")"
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fields := make([]string, len(keys))
+		for i, key := range keys {
+			fields[i] = encodeRison(key) + ":" + encodeRison(v[key])
+		}
+		return "(" + strings.Join(fields, ",") + ")"
+	default:
+		return encodeRison(fmt.Sprint(v))
+	}
+}
+
+func formatReportingGenerateUrl(space *string, exportType string, jobParams string) string {
+	path := fmt.Sprintf("/api/reporting/generate/%s?jobParams=%s", exportType, url.QueryEscape(jobParams))
+	if space == nil {
+		return path
+	}
+	return fmt.Sprintf("/s/%s%s", *space, path)
+}
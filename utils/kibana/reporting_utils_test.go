@@ -0,0 +1,130 @@
+package kibana
+
+import (
+	"testing"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/testutils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEncodeRison(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{value: "dashboard", want: "dashboard"},
+		{value: "now-7d", want: "now-7d"},
+		{value: "8.0.0", want: "'8.0.0'"},
+		{value: "it's great!", want: "'it!'s great!!'"},
+		{value: "true", want: "'true'"},
+		{value: true, want: "!t"},
+		{value: nil, want: "!n"},
+		{value: 1920, want: "1920"},
+		{value: []any{"a", false}, want: "!(a,!f)"},
+		{value: map[string]any{"to": "now", "from": "now-7d"}, want: "(from:now-7d,to:now)"},
+	}
+	for _, tt := range tests {
+		if got := encodeRison(tt.value); got != tt.want {
+			t.Errorf("encodeRison(%v) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestBuildReportingJobParams(t *testing.T) {
+	reportingJob := kibanaeckv1alpha1.ReportingJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "weekly-kpis"},
+		Spec: kibanaeckv1alpha1.ReportingJobSpec{
+			ObjectType: "dashboard",
+			ObjectID:   "kpis",
+			Format:     kibanaeckv1alpha1.ReportingFormatPDF,
+			TimeZone:   "Europe/Berlin",
+			TimeRange:  &kibanaeckv1alpha1.ReportingTimeRange{From: "now-7d", To: "now"},
+		},
+	}
+	want := "(browserTimezone:'Europe/Berlin',layout:(id:preserve_layout)," +
+		"locatorParams:!((id:DASHBOARD_APP_LOCATOR,params:(dashboardId:kpis,timeRange:(from:now-7d,to:now),viewMode:view),version:'8.0.0'))," +
+		"objectType:dashboard,title:weekly-kpis,version:'8.0.0')"
+	if got := buildReportingJobParams(reportingJob); got != want {
+		t.Errorf("buildReportingJobParams() = %s, want %s", got, want)
+	}
+}
+
+func TestVerifyReportingJob(t *testing.T) {
+	tests := []struct {
+		objectType string
+		format     kibanaeckv1alpha1.ReportingFormat
+		wantErr    bool
+	}{
+		{objectType: "dashboard", format: kibanaeckv1alpha1.ReportingFormatPDF},
+		{objectType: "dashboard", format: kibanaeckv1alpha1.ReportingFormatPNG},
+		{objectType: "search", format: kibanaeckv1alpha1.ReportingFormatCSV},
+		{objectType: "dashboard", format: kibanaeckv1alpha1.ReportingFormatCSV, wantErr: true},
+		{objectType: "search", format: kibanaeckv1alpha1.ReportingFormatPDF, wantErr: true},
+		{objectType: "dashboard", format: "XLSX", wantErr: true},
+	}
+	for _, tt := range tests {
+		reportingJob := kibanaeckv1alpha1.ReportingJob{Spec: kibanaeckv1alpha1.ReportingJobSpec{ObjectType: tt.objectType, Format: tt.format}}
+		if err := VerifyReportingJob(reportingJob); (err != nil) != tt.wantErr {
+			t.Errorf("VerifyReportingJob(%s, %s) error = %v, wantErr %v", tt.objectType, tt.format, err, tt.wantErr)
+		}
+	}
+}
+
+func TestReportingLifecycle_FakeKibana(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	kClient := createTestKibanaClient(fakeKibana.URL())
+
+	reportingJob := kibanaeckv1alpha1.ReportingJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "daily-errors"},
+		Spec: kibanaeckv1alpha1.ReportingJobSpec{
+			Space:      strPtr("ops"),
+			ObjectType: "search",
+			ObjectID:   "errors",
+			Format:     kibanaeckv1alpha1.ReportingFormatCSV,
+		},
+	}
+
+	jobID, path, err := GenerateReport(kClient, reportingJob)
+	if err != nil {
+		t.Fatalf("GenerateReport() error = %v", err)
+	}
+	if path != "/s/ops/api/reporting/jobs/download/"+jobID {
+		t.Errorf("Expected the download path of the job in the space, got %s", path)
+	}
+	job, ok := fakeKibana.ReportingJob(jobID)
+	if !ok || job.ExportType != "csv_v2" {
+		t.Fatalf("Expected a csv_v2 job to be queued, got %+v", job)
+	}
+
+	for _, tt := range []struct {
+		status      string
+		want        string
+		wantMessage bool
+	}{
+		{status: "pending", want: kibanaeckv1alpha1.ReportingJobStatusPending},
+		{status: "processing", want: kibanaeckv1alpha1.ReportingJobStatusPending},
+		{status: "failed", want: kibanaeckv1alpha1.ReportingJobStatusFailed, wantMessage: true},
+		{status: "completed", want: kibanaeckv1alpha1.ReportingJobStatusCompleted},
+	} {
+		fakeKibana.SetReportingJobStatus(jobID, tt.status)
+		status, message, err := GetReportStatus(kClient, path)
+		if err != nil {
+			t.Fatalf("GetReportStatus() error = %v", err)
+		}
+		if status != tt.want || (message != "") != tt.wantMessage {
+			t.Errorf("GetReportStatus() for a %s job = %s, %q", tt.status, status, message)
+		}
+	}
+
+	reportingJob.Spec.JobParams = "(custom:params)"
+	jobID, _, err = GenerateReport(kClient, reportingJob)
+	if err != nil {
+		t.Fatalf("GenerateReport() error = %v", err)
+	}
+	if job, _ := fakeKibana.ReportingJob(jobID); job.JobParams != "(custom:params)" {
+		t.Errorf("Expected the given job params to be used, got %s", job.JobParams)
+	}
+}
@@ -0,0 +1,120 @@
+package kibana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"time"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// SavedObjectBackupsAnnotation holds the backups of a saved object with the Annotation sink, as a JSON list from
+	// the oldest to the newest backup
+	SavedObjectBackupsAnnotation = "kibana.eck.github.com/backups"
+	// DefaultBackupRetention is the number of backups kept if spec.backup.retention is not set
+	DefaultBackupRetention = 3
+	// backupKeyFormat names the keys of backup ConfigMaps, which sort from the oldest to the newest backup
+	backupKeyFormat = "20060102T150405Z"
+)
+
+// savedObjectBackup is a backup of the attributes of a saved object stored in the annotation
+type savedObjectBackup struct {
+	Time       string          `json:"time"`
+	Attributes json.RawMessage `json:"attributes"`
+}
+
+// BackupConfigMapName returns the name of the ConfigMap the backups of the saved object of the resource are written to
+func BackupConfigMapName(name string) string {
+	return name + "-backup"
+}
+
+// BackupSavedObject saves attributes, the attributes of the saved object of obj in Kibana, to the sink of backup
+// unless they equal the newest backup, and removes the backups exceeding the retention. It returns whether a backup
+// was taken.
+func BackupSavedObject(cli client.Client, ctx context.Context, obj client.Object, backup kibanaeckv1alpha1.SavedObjectBackup,
+	attributes string, now time.Time) (bool, error) {
+	retention := int(backup.Retention)
+	if retention < 1 {
+		retention = DefaultBackupRetention
+	}
+	if backup.Sink == kibanaeckv1alpha1.BackupSinkAnnotation {
+		return backupToAnnotation(cli, ctx, obj, retention, attributes, now)
+	}
+	return backupToConfigMap(cli, ctx, obj, retention, attributes, now)
+}
+
+// backupToConfigMap writes attributes to a key named after the time of the backup of the ConfigMap of obj, which is
+// created and owned by obj if it does not exist
+func backupToConfigMap(cli client.Client, ctx context.Context, obj client.Object, retention int, attributes string, now time.Time) (bool, error) {
+	configMap := &k8sv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: BackupConfigMapName(obj.GetName()), Namespace: obj.GetNamespace()}}
+	taken := false
+	_, err := controllerutil.CreateOrUpdate(ctx, cli, configMap, func() error {
+		if err := controllerutil.SetOwnerReference(obj, configMap, cli.Scheme()); err != nil {
+			return err
+		}
+		keys := slices.Sorted(maps.Keys(configMap.Data))
+		if len(keys) > 0 && configMap.Data[keys[len(keys)-1]] == attributes {
+			return nil
+		}
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		key := now.UTC().Format(backupKeyFormat) + ".json"
+		configMap.Data[key] = attributes
+		keys = append(keys, key)
+		for _, expired := range keys[:max(len(keys)-retention, 0)] {
+			delete(configMap.Data, expired)
+		}
+		taken = true
+		return nil
+	})
+	return taken, err
+}
+
+// backupToAnnotation appends attributes to the backups in the annotation of obj
+func backupToAnnotation(cli client.Client, ctx context.Context, obj client.Object, retention int, attributes string, now time.Time) (bool, error) {
+	// Backups are stored compacted, attributes are compacted the same way to compare them with the newest one
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, []byte(attributes)); err != nil {
+		return false, err
+	}
+	attributes = compacted.String()
+
+	var backups []savedObjectBackup
+	if stored, ok := obj.GetAnnotations()[SavedObjectBackupsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(stored), &backups); err != nil {
+			// An unreadable annotation is replaced, it can not be used for recovery anyway
+			backups = nil
+		}
+	}
+	if len(backups) > 0 && string(backups[len(backups)-1].Attributes) == attributes {
+		return false, nil
+	}
+	backups = append(backups, savedObjectBackup{Time: now.UTC().Format(time.RFC3339), Attributes: json.RawMessage(attributes)})
+	backups = backups[max(len(backups)-retention, 0):]
+	marshalled, err := json.Marshal(backups)
+	if err != nil {
+		return false, err
+	}
+
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[SavedObjectBackupsAnnotation] = string(marshalled)
+	obj.SetAnnotations(annotations)
+	if err := cli.Patch(ctx, obj, patch); err != nil {
+		return false, err
+	}
+	return true, nil
+}
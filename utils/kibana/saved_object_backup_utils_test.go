@@ -0,0 +1,89 @@
+package kibana
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBackupSavedObject_ConfigMap(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = k8sv1.AddToScheme(scheme)
+	_ = kibanaeckv1alpha1.AddToScheme(scheme)
+	dashboard := &kibanaeckv1alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "overview", Namespace: "kibana", UID: "uid"}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dashboard).Build()
+	ctx := context.Background()
+	backup := kibanaeckv1alpha1.SavedObjectBackup{Retention: 2}
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	for i, attributes := range []string{`{"title":"v1"}`, `{"title":"v1"}`, `{"title":"v2"}`, `{"title":"v3"}`} {
+		taken, err := BackupSavedObject(cli, ctx, dashboard, backup, attributes, start.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatalf("BackupSavedObject() error = %v", err)
+		}
+		if wantTaken := i != 1; taken != wantTaken {
+			t.Errorf("BackupSavedObject() of backup %d taken = %v, want %v", i, taken, wantTaken)
+		}
+	}
+
+	var configMap k8sv1.ConfigMap
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: "kibana", Name: BackupConfigMapName("overview")}, &configMap); err != nil {
+		t.Fatalf("Failed to get the backup ConfigMap: %v", err)
+	}
+	want := map[string]string{
+		"20261016T120200Z.json": `{"title":"v2"}`,
+		"20261016T120300Z.json": `{"title":"v3"}`,
+	}
+	if len(configMap.Data) != len(want) {
+		t.Errorf("Backups = %v, want %v", configMap.Data, want)
+	}
+	for key, attributes := range want {
+		if configMap.Data[key] != attributes {
+			t.Errorf("Backup %s = %q, want %q", key, configMap.Data[key], attributes)
+		}
+	}
+	if len(configMap.OwnerReferences) != 1 || configMap.OwnerReferences[0].Name != "overview" {
+		t.Errorf("Expected the ConfigMap to be owned by the dashboard, got %v", configMap.OwnerReferences)
+	}
+}
+
+func TestBackupSavedObject_Annotation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = kibanaeckv1alpha1.AddToScheme(scheme)
+	dashboard := &kibanaeckv1alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "overview", Namespace: "kibana"}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dashboard).Build()
+	ctx := context.Background()
+	backup := kibanaeckv1alpha1.SavedObjectBackup{Sink: kibanaeckv1alpha1.BackupSinkAnnotation, Retention: 2}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	for i, attributes := range []string{`{"title": "v1"}`, `{"title":"v2"}`, `{"title":"v2"}`, `{"title":"v3"}`} {
+		taken, err := BackupSavedObject(cli, ctx, dashboard, backup, attributes, now)
+		if err != nil {
+			t.Fatalf("BackupSavedObject() error = %v", err)
+		}
+		if wantTaken := i != 2; taken != wantTaken {
+			t.Errorf("BackupSavedObject() of backup %d taken = %v, want %v", i, taken, wantTaken)
+		}
+	}
+
+	var stored kibanaeckv1alpha1.Dashboard
+	if err := cli.Get(ctx, client.ObjectKeyFromObject(dashboard), &stored); err != nil {
+		t.Fatalf("Failed to get the dashboard: %v", err)
+	}
+	var backups []savedObjectBackup
+	if err := json.Unmarshal([]byte(stored.Annotations[SavedObjectBackupsAnnotation]), &backups); err != nil {
+		t.Fatalf("Failed to parse the backups annotation: %v", err)
+	}
+	if len(backups) != 2 || string(backups[0].Attributes) != `{"title":"v2"}` || string(backups[1].Attributes) != `{"title":"v3"}` {
+		t.Errorf("Backups = %s, want v2 and v3", stored.Annotations[SavedObjectBackupsAnnotation])
+	}
+}
@@ -0,0 +1,137 @@
+package kibana
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+)
+
+// defaultSpace is the space of saved objects without spec.space
+const defaultSpace = "default"
+
+// allSpaces shares a saved object to all spaces, including those created later
+const allSpaces = "*"
+
+// updateObjectsSpacesRequest is the body of the share to space API
+type updateObjectsSpacesRequest struct {
+	Objects        []savedObjectReference `json:"objects"`
+	SpacesToAdd    []string               `json:"spacesToAdd"`
+	SpacesToRemove []string               `json:"spacesToRemove"`
+}
+
+type savedObjectReference struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// updateObjectsSpacesResponse is the response of the share to space API, which reports errors per object
+type updateObjectsSpacesResponse struct {
+	Objects []struct {
+		savedObjectReference
+		Error *struct {
+			StatusCode int    `json:"statusCode"`
+			Message    string `json:"message"`
+		} `json:"error,omitempty"`
+	} `json:"objects"`
+}
+
+// ShareSavedObject shares the saved object to the spaces of spec.shareToSpaces through the share to space API, which
+// updates the namespaces attribute of the one saved object instead of copying it. shared are the spaces the operator
+// shared it to before, it is removed from those no longer listed; spaces it was shared to in Kibana and its own space
+// are left untouched. It returns the spaces the saved object is shared to by the operator now.
+func ShareSavedObject(kClient Client, savedObjectType string, name string, savedObject kibanaeckv1alpha1.SavedObject, shared []string) ([]string, error) {
+	if len(savedObject.ShareToSpaces) == 0 && len(shared) == 0 {
+		return nil, nil
+	}
+	namespaces, err := GetSavedObjectSpaces(kClient, savedObjectType, name, savedObject.Space)
+	if err != nil {
+		return shared, err
+	}
+	if namespaces == nil {
+		return shared, fmt.Errorf("saved object %s/%s not found", savedObjectType, name)
+	}
+
+	home := defaultSpace
+	if savedObject.Space != nil {
+		home = *savedObject.Space
+	}
+	spacesToAdd, spacesToRemove := []string{}, []string{}
+	for _, space := range savedObject.ShareToSpaces {
+		if !slices.Contains(namespaces, space) && !slices.Contains(namespaces, allSpaces) {
+			spacesToAdd = append(spacesToAdd, space)
+		}
+	}
+	for _, space := range shared {
+		if !slices.Contains(savedObject.ShareToSpaces, space) && space != home && slices.Contains(namespaces, space) {
+			spacesToRemove = append(spacesToRemove, space)
+		}
+	}
+
+	sharedNow := slices.Clone(savedObject.ShareToSpaces)
+	slices.Sort(sharedNow)
+	if len(spacesToAdd) == 0 && len(spacesToRemove) == 0 {
+		return sharedNow, nil
+	}
+	if err := updateObjectsSpaces(kClient, savedObject.Space, updateObjectsSpacesRequest{
+		Objects:        []savedObjectReference{{Type: savedObjectType, ID: name}},
+		SpacesToAdd:    spacesToAdd,
+		SpacesToRemove: spacesToRemove,
+	}); err != nil {
+		return shared, err
+	}
+	return sharedNow, nil
+}
+
+// GetSavedObjectSpaces returns the namespaces attribute of the saved object, the spaces it is visible in, nil if it
+// does not exist
+func GetSavedObjectSpaces(kClient Client, savedObjectType string, name string, space *string) ([]string, error) {
+	namespaces, err := getAttributes(kClient, formatSavedObjectUrl(savedObjectType, name, space), "namespaces")
+	if err != nil || namespaces == nil {
+		return nil, err
+	}
+	spaces := []string{}
+	if *namespaces != "" {
+		if err := json.Unmarshal([]byte(*namespaces), &spaces); err != nil {
+			return nil, err
+		}
+	}
+	return spaces, nil
+}
+
+// updateObjectsSpaces calls the share to space API in the space of the saved object
+func updateObjectsSpaces(kClient Client, space *string, request updateObjectsSpacesRequest) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	url := "/api/spaces/_update_objects_spaces"
+	if space != nil {
+		url = fmt.Sprintf("/s/%s%s", *space, url)
+	}
+	res, err := kClient.DoPost(url, string(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode > 299 {
+		return fmt.Errorf("Non-success (%d) response: %s, ", res.StatusCode, string(resBody))
+	}
+
+	var response updateObjectsSpacesResponse
+	if err := json.Unmarshal(resBody, &response); err != nil {
+		return err
+	}
+	for _, object := range response.Objects {
+		if object.Error != nil {
+			return fmt.Errorf("failed to share %s/%s: %s", object.Type, object.ID, object.Error.Message)
+		}
+	}
+	return nil
+}
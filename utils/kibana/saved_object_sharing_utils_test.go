@@ -0,0 +1,108 @@
+package kibana
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+)
+
+func TestShareSavedObject(t *testing.T) {
+	space := "team-a"
+	tests := []struct {
+		name          string
+		shareToSpaces []string
+		shared        []string
+		namespaces    []string
+		wantRequest   *updateObjectsSpacesRequest
+		wantShared    []string
+	}{
+		{
+			name:          "share to new spaces",
+			shareToSpaces: []string{"team-c", "team-b"},
+			namespaces:    []string{"team-a", "team-b"},
+			wantRequest:   &updateObjectsSpacesRequest{SpacesToAdd: []string{"team-c"}, SpacesToRemove: []string{}},
+			wantShared:    []string{"team-b", "team-c"},
+		},
+		{
+			name:          "unshare from spaces no longer listed",
+			shareToSpaces: []string{"team-b"},
+			shared:        []string{"team-a", "team-b", "team-c"},
+			namespaces:    []string{"team-a", "team-b", "team-c", "team-d"},
+			wantRequest:   &updateObjectsSpacesRequest{SpacesToAdd: []string{}, SpacesToRemove: []string{"team-c"}},
+			wantShared:    []string{"team-b"},
+		},
+		{
+			name:          "already shared to all spaces",
+			shareToSpaces: []string{"team-b"},
+			shared:        []string{"team-b"},
+			namespaces:    []string{"*"},
+			wantShared:    []string{"team-b"},
+		},
+		{
+			name: "never shared",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request *updateObjectsSpacesRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method + " " + r.URL.Path {
+				case "GET /s/team-a/api/saved_objects/dashboard/overview":
+					json.NewEncoder(w).Encode(map[string]any{"id": "overview", "namespaces": tt.namespaces})
+				case "POST /s/team-a/api/spaces/_update_objects_spaces":
+					request = &updateObjectsSpacesRequest{}
+					if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+						t.Errorf("Invalid request body: %v", err)
+					}
+					w.Write([]byte(`{"objects": [{"type": "dashboard", "id": "overview", "spaces": []}]}`))
+				default:
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			kClient := Client{KibanaSpec: configv2.KibanaSpec{Url: server.URL}}
+			shared, err := ShareSavedObject(kClient, "dashboard", "overview",
+				kibanaeckv1alpha1.SavedObject{Space: &space, ShareToSpaces: tt.shareToSpaces}, tt.shared)
+			if err != nil {
+				t.Fatalf("ShareSavedObject() error = %v", err)
+			}
+			if !reflect.DeepEqual(shared, tt.wantShared) {
+				t.Errorf("ShareSavedObject() = %v, want %v", shared, tt.wantShared)
+			}
+			if tt.wantRequest != nil {
+				tt.wantRequest.Objects = []savedObjectReference{{Type: "dashboard", ID: "overview"}}
+			}
+			if !reflect.DeepEqual(request, tt.wantRequest) {
+				t.Errorf("Expected the share request %+v, got %+v", tt.wantRequest, request)
+			}
+		})
+	}
+}
+
+func TestShareSavedObject_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"id": "overview", "namespaces": ["default"]}`))
+			return
+		}
+		w.Write([]byte(`{"objects": [{"type": "dashboard", "id": "overview", "error": {"statusCode": 404, "message": "Saved object not found"}}]}`))
+	}))
+	defer server.Close()
+
+	kClient := Client{KibanaSpec: configv2.KibanaSpec{Url: server.URL}}
+	shared, err := ShareSavedObject(kClient, "dashboard", "overview",
+		kibanaeckv1alpha1.SavedObject{ShareToSpaces: []string{"team-b"}}, []string{"team-c"})
+	if err == nil {
+		t.Fatal("Expected the error of the object to be returned")
+	}
+	if !reflect.DeepEqual(shared, []string{"team-c"}) {
+		t.Errorf("Expected the previously shared spaces to be kept, got %v", shared)
+	}
+}
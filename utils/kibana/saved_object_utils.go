@@ -1,61 +1,356 @@
 package kibana
 
 import (
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
 	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
 	"eck-custom-resources/utils"
+	esutils "eck-custom-resources/utils/elasticsearch"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// ErrSavedObjectConflict is returned for a saved object which exists in Kibana before the resource manages it, if
+// the conflict policy is Fail
+var ErrSavedObjectConflict = errors.New("saved object exists in Kibana and is not managed by the resource")
+
+// ErrSavedObjectModified is returned for a saved object changed in Kibana since the operator wrote it last, if the
+// update policy is Fail
+var ErrSavedObjectModified = errors.New("saved object was changed in Kibana since the operator wrote it last")
+
+// ReadAfterWriteAttempts is the number of times a created saved object is read back until Kibana returns it, as
+// Kibana may respond with 404 right after the creation
+var ReadAfterWriteAttempts = 5
+
+// ReadAfterWriteInterval is the interval between the reads of a created saved object, extended by a random jitter
+var ReadAfterWriteInterval = 100 * time.Millisecond
+
 func DeleteSavedObject(kClient Client, savedObjectType string, savedObjectMeta metav1.ObjectMeta, savedObject kibanaeckv1alpha1.SavedObject) (ctrl.Result, error) {
-	_, deleteErr := kClient.DoDelete(formatSavedObjectUrl(savedObjectType, savedObjectMeta.Name, savedObject.Space))
+	url := formatSavedObjectUrl(savedObjectType, savedObject.ObjectIDFor(savedObjectMeta.Namespace, savedObjectMeta.Name), savedObject.Space)
+	if len(savedObject.ShareToSpaces) > 0 {
+		// Kibana refuses to delete saved objects shared to several spaces unless forced
+		url += "?force=true"
+	}
+	_, deleteErr := kClient.DoDelete(url)
 	return ctrl.Result{}, deleteErr
 }
 
-func UpsertSavedObject(kClient Client, savedObjectType string, savedObjectMeta metav1.ObjectMeta, savedObject kibanaeckv1alpha1.SavedObject) (ctrl.Result, error) {
+// UpsertSavedObject creates or updates the saved object. version is the version of the saved object the operator
+// wrote last, an update is conditional on it and version is set to the version written; nil skips the check.
+func UpsertSavedObject(kClient Client, savedObjectType string, savedObjectMeta metav1.ObjectMeta, savedObject kibanaeckv1alpha1.SavedObject, version *string) (ctrl.Result, error) {
+	body, err := addDependencyReferences(savedObject.Body, savedObject.Dependencies)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	savedObject.Body = body
+	if savedObject.ManagedNotice {
+		body, err := addManagedNotice(savedObject.Body, savedObjectMeta)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		savedObject.Body = body
+	}
+	if savedObject.Managed {
+		if version != nil {
+			// The import API does not report versions, the next update is unconditional
+			*version = ""
+		}
+		return importManagedSavedObject(kClient, savedObjectType, savedObjectMeta, savedObject)
+	}
 
-	exists, err := SavedObjectExists(kClient, savedObjectType, savedObjectMeta.Name, savedObject.Space)
+	id := savedObject.ObjectIDFor(savedObjectMeta.Namespace, savedObjectMeta.Name)
+	url := formatSavedObjectUrl(savedObjectType, id, savedObject.Space)
+	exists, err := SavedObjectExists(kClient, savedObjectType, id, savedObject.Space)
 	if err != nil {
 		return utils.GetRequeueResult(), err
 	}
 
 	var res *http.Response
 	if exists {
-		res, err = kClient.DoPut(formatSavedObjectUrl(savedObjectType, savedObjectMeta.Name, savedObject.Space), savedObject.Body)
+		res, err = updateSavedObject(kClient, url, id, savedObject, version)
 	} else {
-		res, err = kClient.DoPost(formatSavedObjectUrl(savedObjectType, savedObjectMeta.Name, savedObject.Space), savedObject.Body)
+		res, err = kClient.DoPost(url, savedObject.Body)
 	}
 
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	defer res.Body.Close()
+	resBody, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return utils.GetRequeueResult(), err
 	}
 	if res.StatusCode > 299 {
-		resBody, err := ioutil.ReadAll(res.Body)
+		return utils.GetRequeueResult(), fmt.Errorf("Non-success (%d) response: %s, ", res.StatusCode, string(resBody))
+	}
+	if version != nil {
+		var written struct {
+			Version string `json:"version"`
+		}
+		_ = json.Unmarshal(resBody, &written)
+		*version = written.Version
+	}
+	if !exists {
+		awaitReadable(kClient, url)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// updateSavedObject updates the saved object conditionally on version, so that changes made in Kibana since the
+// operator wrote it last are detected instead of silently overwritten. On a conflict the saved object is overwritten
+// with its current version if the update policy is Overwrite, ErrSavedObjectModified is returned otherwise.
+func updateSavedObject(kClient Client, url string, name string, savedObject kibanaeckv1alpha1.SavedObject, version *string) (*http.Response, error) {
+	if version == nil || *version == "" {
+		return kClient.DoPut(url, savedObject.Body)
+	}
+	body, err := withVersion(savedObject.Body, *version)
+	if err != nil {
+		return nil, err
+	}
+	res, err := kClient.DoPut(url, body)
+	if err != nil || res.StatusCode != http.StatusConflict {
+		return res, err
+	}
+	res.Body.Close()
+	if savedObject.UpdatePolicy == kibanaeckv1alpha1.UpdatePolicyFail {
+		return nil, fmt.Errorf("%w: %s", ErrSavedObjectModified, name)
+	}
+
+	current, err := getAttributes(kClient, url, "version")
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, fmt.Errorf("saved object %s was deleted in Kibana during the update", name)
+	}
+	var currentVersion string
+	if err := json.Unmarshal([]byte(*current), &currentVersion); err != nil {
+		return nil, err
+	}
+	if body, err = withVersion(savedObject.Body, currentVersion); err != nil {
+		return nil, err
+	}
+	return kClient.DoPut(url, body)
+}
+
+// withVersion sets the version of the update request body
+func withVersion(body string, version string) (string, error) {
+	parsed := make(map[string]json.RawMessage)
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(version)
+	if err != nil {
+		return "", err
+	}
+	parsed["version"] = encoded
+	marshalled, err := json.Marshal(parsed)
+	if err != nil {
+		return "", err
+	}
+	return string(marshalled), nil
+}
+
+// awaitReadable reads the object at url until Kibana returns it, at most ReadAfterWriteAttempts times. A created
+// object not being readable yet is not an error, the write succeeded and later reads see it eventually, waiting
+// only keeps dependency checks following the creation from failing spuriously.
+func awaitReadable(kClient Client, url string) {
+	for attempt := 0; attempt < ReadAfterWriteAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(utils.Jitter(ReadAfterWriteInterval))
+		}
+		res, err := kClient.DoGet(url)
 		if err != nil {
-			return utils.GetRequeueResult(), err
+			return
 		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusNotFound {
+			return
+		}
+	}
+}
+
+// importManagedSavedObject creates or overwrites the saved object through the import API, which keeps the managed
+// flag of the imported objects
+func importManagedSavedObject(kClient Client, savedObjectType string, savedObjectMeta metav1.ObjectMeta, savedObject kibanaeckv1alpha1.SavedObject) (ctrl.Result, error) {
+	object := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(savedObject.Body), &object); err != nil {
+		return ctrl.Result{}, err
+	}
+	id := savedObject.ObjectIDFor(savedObjectMeta.Namespace, savedObjectMeta.Name)
+	object["type"] = savedObjectType
+	object["id"] = id
+	object["managed"] = true
+	ndjson, err := json.Marshal(object)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	path := "/api/saved_objects/_import?overwrite=true"
+	if savedObject.Space != nil {
+		path = fmt.Sprintf("/s/%s%s", *savedObject.Space, path)
+	}
+	res, err := kClient.DoPostFile(path, id+".ndjson", string(ndjson))
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	defer res.Body.Close()
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	if res.StatusCode > 299 {
 		return utils.GetRequeueResult(), fmt.Errorf("Non-success (%d) response: %s, ", res.StatusCode, string(resBody))
 	}
 
+	// The import API responds with 200 for objects failing to import, listing them in errors
+	var response struct {
+		Success bool              `json:"success"`
+		Errors  []json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(resBody, &response); err != nil {
+		return utils.GetRequeueResult(), err
+	}
+	if !response.Success {
+		return utils.GetRequeueResult(), fmt.Errorf("failed to import saved object %s: %s", id, string(resBody))
+	}
 	return ctrl.Result{}, nil
 }
 
+// addDependencyReferences merges the dependencies with a reference name into the references of the body. A reference
+// of the same name is replaced, so the body may keep a placeholder for it.
+func addDependencyReferences(body string, dependencies []kibanaeckv1alpha1.Dependency) (string, error) {
+	var referenced []kibanaeckv1alpha1.Dependency
+	for _, dependency := range dependencies {
+		if dependency.ReferenceName != "" && dependency.ObjectType != kibanaeckv1alpha1.SavedObjectTypeElasticsearchIndex {
+			referenced = append(referenced, dependency)
+		}
+	}
+	if len(referenced) == 0 {
+		return body, nil
+	}
+
+	parsed := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return "", err
+	}
+	references, _ := parsed["references"].([]interface{})
+	for _, dependency := range referenced {
+		reference := map[string]interface{}{
+			"name": dependency.ReferenceName,
+			"type": string(dependency.ObjectType),
+			"id":   dependency.Name,
+		}
+		replaced := false
+		for i, existing := range references {
+			if existing, ok := existing.(map[string]interface{}); ok && existing["name"] == dependency.ReferenceName {
+				references[i] = reference
+				replaced = true
+			}
+		}
+		if !replaced {
+			references = append(references, reference)
+		}
+	}
+	parsed["references"] = references
+
+	marshalled, err := json.Marshal(parsed)
+	if err != nil {
+		return "", err
+	}
+	return string(marshalled), nil
+}
+
+// addManagedNotice appends the notice that the saved object is managed by the operator to the description attribute
+// of the body
+func addManagedNotice(body string, savedObjectMeta metav1.ObjectMeta) (string, error) {
+	parsed := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return "", err
+	}
+	attributes, ok := parsed["attributes"].(map[string]interface{})
+	if !ok {
+		attributes = make(map[string]interface{})
+	}
+	notice := fmt.Sprintf("Managed by eck-custom-resources (%s/%s), changes made in Kibana are reverted.",
+		savedObjectMeta.Namespace, savedObjectMeta.Name)
+	if description, _ := attributes["description"].(string); description != "" {
+		notice = description + "\n\n" + notice
+	}
+	attributes["description"] = notice
+	parsed["attributes"] = attributes
+
+	marshalled, err := json.Marshal(parsed)
+	if err != nil {
+		return "", err
+	}
+	return string(marshalled), nil
+}
+
 func SavedObjectExists(kClient Client, savedObjectType string, name string, space *string) (bool, error) {
 	res, err := kClient.DoGet(formatSavedObjectUrl(savedObjectType, name, space))
 	return err == nil && res.StatusCode == 200, err
 }
 
+// GetSavedObjectAttributes returns the attributes of the saved object as JSON, nil if it does not exist
+func GetSavedObjectAttributes(kClient Client, savedObjectType string, name string, space *string) (*string, error) {
+	return getAttributes(kClient, formatSavedObjectUrl(savedObjectType, name, space), "attributes")
+}
+
+// ResolveSavedObjectConflict applies the conflict policy of the saved object. managed reports whether the resource
+// already manages the saved object, remote returns the attributes of the saved object in Kibana, nil if it does not
+// exist. It returns whether the body of the resource is to be applied and the adoption to record in the status. An
+// adopted saved object is left untouched until the generation of the resource changes.
+func ResolveSavedObjectConflict(obj client.Object, managed bool, savedObject kibanaeckv1alpha1.SavedObject, adoption *kibanaeckv1alpha1.SavedObjectAdoption, remote func() (*string, error)) (bool, *kibanaeckv1alpha1.SavedObjectAdoption, error) {
+	if managed {
+		return adoption == nil || adoption.Generation != obj.GetGeneration(), adoption, nil
+	}
+	if savedObject.ConflictPolicy == "" || savedObject.ConflictPolicy == kibanaeckv1alpha1.ConflictPolicyOverwrite {
+		return true, adoption, nil
+	}
+
+	attributes, err := remote()
+	if err != nil {
+		return false, adoption, err
+	}
+	if attributes == nil {
+		return true, adoption, nil
+	}
+	if savedObject.ConflictPolicy == kibanaeckv1alpha1.ConflictPolicyFail {
+		return false, adoption, fmt.Errorf("%w: %s", ErrSavedObjectConflict, obj.GetName())
+	}
+	return false, &kibanaeckv1alpha1.SavedObjectAdoption{
+		Attributes: *attributes,
+		Generation: obj.GetGeneration(),
+		Time:       metav1.Now(),
+	}, nil
+}
+
 func DependenciesFulfilled(kClient Client, savedObject kibanaeckv1alpha1.SavedObject) error {
 
 	var missingDependencies []string
 	var errors []string
 
 	for _, dependency := range savedObject.Dependencies {
+		if dependency.ObjectType == kibanaeckv1alpha1.SavedObjectTypeElasticsearchIndex {
+			exists, err := ElasticsearchIndexExists(kClient, dependency)
+			if err != nil {
+				errors = append(errors, err.Error())
+			} else if !exists {
+				missingDependencies = append(missingDependencies, fmt.Sprintf("%s/%s", dependency.ObjectType, dependency.Name))
+			}
+			continue
+		}
+
 		dSpace := savedObject.Space
 		if dependency.Space != nil {
 			dSpace = dependency.Space
@@ -84,6 +379,58 @@ func DependenciesFulfilled(kClient Client, savedObject kibanaeckv1alpha1.SavedOb
 	return nil
 }
 
+// ElasticsearchIndexExists checks whether the index, alias or data stream of an elasticsearchIndex dependency exists
+// in the Elasticsearch instance referenced by the dependency, or in the default Elasticsearch of the client
+func ElasticsearchIndexExists(kClient Client, dependency kibanaeckv1alpha1.Dependency) (bool, error) {
+	esSpec := kClient.Elasticsearch
+	esNamespace := kClient.Req.Namespace
+	if dependency.ElasticsearchInstance != nil {
+		if dependency.ElasticsearchInstance.Namespace != "" {
+			esNamespace = dependency.ElasticsearchInstance.Namespace
+		}
+		var esInstance eseckv1alpha1.ElasticsearchInstance
+		if err := esutils.GetTargetElasticsearchInstance(kClient.Cli, kClient.Ctx, esNamespace, dependency.ElasticsearchInstance.Name, &esInstance); err != nil {
+			return false, err
+		}
+		esSpec = esInstance.Spec
+	}
+	if esSpec.Url == "" {
+		return false, fmt.Errorf("no Elasticsearch configured to look up %s/%s in", dependency.ObjectType, dependency.Name)
+	}
+
+	esClient, err := esutils.GetElasticsearchClient(kClient.Cli, kClient.Ctx, esSpec, kClient.Req, esNamespace)
+	if err != nil {
+		return false, err
+	}
+	return esutils.VerifyIndexExists(esClient, dependency.Name)
+}
+
+// getAttributes returns the field of the JSON response as JSON, nil if the object does not exist
+func getAttributes(kClient Client, url string, field string) (*string, error) {
+	res, err := kClient.DoGet(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode > 299 {
+		return nil, fmt.Errorf("Non-success (%d) response: %s, ", res.StatusCode, string(resBody))
+	}
+
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(resBody, &response); err != nil {
+		return nil, err
+	}
+	attributes := string(response[field])
+	return &attributes, nil
+}
+
 func formatSavedObjectUrl(savedObjectType string, name string, space *string) string {
 	if space == nil {
 		return fmt.Sprintf("/api/saved_objects/%s/%s", savedObjectType, name)
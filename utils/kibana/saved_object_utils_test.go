@@ -1,12 +1,18 @@
 package kibana
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	configv2 "eck-custom-resources/api/config/v2"
 	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/testutils"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -258,7 +264,7 @@ func TestUpsertSavedObject_Create(t *testing.T) {
 		Body: `{"title": "My Dashboard"}`,
 	}
 
-	result, err := UpsertSavedObject(kClient, "dashboard", metav1.ObjectMeta{Name: "my-dashboard"}, savedObject)
+	result, err := UpsertSavedObject(kClient, "dashboard", metav1.ObjectMeta{Name: "my-dashboard"}, savedObject, nil)
 
 	if err != nil {
 		t.Errorf("UpsertSavedObject() unexpected error: %v", err)
@@ -268,8 +274,9 @@ func TestUpsertSavedObject_Create(t *testing.T) {
 		t.Errorf("UpsertSavedObject() result = %v, want empty Result", result)
 	}
 
-	if callCount != 2 {
-		t.Errorf("Expected 2 HTTP calls, got %d", callCount)
+	// The existence check, the creation and the read after the creation
+	if callCount != 3 {
+		t.Errorf("Expected 3 HTTP calls, got %d", callCount)
 	}
 }
 
@@ -303,7 +310,7 @@ func TestUpsertSavedObject_Update(t *testing.T) {
 		Body: `{"title": "Updated Dashboard"}`,
 	}
 
-	result, err := UpsertSavedObject(kClient, "dashboard", metav1.ObjectMeta{Name: "existing-dashboard"}, savedObject)
+	result, err := UpsertSavedObject(kClient, "dashboard", metav1.ObjectMeta{Name: "existing-dashboard"}, savedObject, nil)
 
 	if err != nil {
 		t.Errorf("UpsertSavedObject() unexpected error: %v", err)
@@ -339,7 +346,7 @@ func TestUpsertSavedObject_ServerError(t *testing.T) {
 		Body: `{"invalid": "body"}`,
 	}
 
-	_, err := UpsertSavedObject(kClient, "dashboard", metav1.ObjectMeta{Name: "bad-dashboard"}, savedObject)
+	_, err := UpsertSavedObject(kClient, "dashboard", metav1.ObjectMeta{Name: "bad-dashboard"}, savedObject, nil)
 
 	if err == nil {
 		t.Error("UpsertSavedObject() expected error for bad request, got nil")
@@ -436,3 +443,299 @@ func createTestKibanaClient(serverURL string) Client {
 		Req: ctrl.Request{},
 	}
 }
+
+func TestSavedObjectLifecycle_FakeKibana(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	kClient := createTestKibanaClient(fakeKibana.URL())
+
+	space := "analytics"
+	meta := metav1.ObjectMeta{Name: "my-dashboard"}
+	savedObject := kibanaeckv1alpha1.SavedObject{Space: &space, Body: `{"attributes": {"title": "v1"}}`}
+
+	if _, err := UpsertSavedObject(kClient, "dashboard", meta, savedObject, nil); err != nil {
+		t.Fatalf("UpsertSavedObject() create error = %v", err)
+	}
+	savedObject.Body = `{"attributes": {"title": "v2"}}`
+	if _, err := UpsertSavedObject(kClient, "dashboard", meta, savedObject, nil); err != nil {
+		t.Fatalf("UpsertSavedObject() update error = %v", err)
+	}
+
+	stored, ok := fakeKibana.SavedObject(space, "dashboard", "my-dashboard")
+	if !ok || string(stored) != savedObject.Body {
+		t.Fatalf("Expected stored body %s, got %s (found %v)", savedObject.Body, stored, ok)
+	}
+	if _, ok := fakeKibana.SavedObject(testutils.DefaultSpace, "dashboard", "my-dashboard"); ok {
+		t.Error("Expected the dashboard not to be created in the default space")
+	}
+
+	dependent := kibanaeckv1alpha1.SavedObject{
+		Space:        &space,
+		Dependencies: []kibanaeckv1alpha1.Dependency{{ObjectType: "dashboard", Name: "my-dashboard"}},
+	}
+	if err := DependenciesFulfilled(kClient, dependent); err != nil {
+		t.Errorf("DependenciesFulfilled() error = %v", err)
+	}
+
+	if _, err := DeleteSavedObject(kClient, "dashboard", meta, savedObject); err != nil {
+		t.Fatalf("DeleteSavedObject() error = %v", err)
+	}
+	if err := DependenciesFulfilled(kClient, dependent); err == nil {
+		t.Error("Expected dependencies not to be fulfilled after deletion")
+	}
+}
+
+func TestUpsertSavedObject_ReadAfterWrite(t *testing.T) {
+	defer func(interval time.Duration) { ReadAfterWriteInterval = interval }(ReadAfterWriteInterval)
+	ReadAfterWriteInterval = time.Millisecond
+
+	tests := []struct {
+		name          string
+		readableAfter int
+		wantReads     int
+	}{
+		{name: "readable right away", readableAfter: 0, wantReads: 1},
+		{name: "readable after two reads", readableAfter: 2, wantReads: 3},
+		{name: "never readable", readableAfter: 100, wantReads: ReadAfterWriteAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := false
+			reads := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					created = true
+					return
+				}
+				if !created {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				reads++
+				if reads <= tt.readableAfter {
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			kClient := createTestKibanaClient(server.URL)
+			savedObject := kibanaeckv1alpha1.SavedObject{Body: `{"attributes": {"title": "My Dashboard"}}`}
+			if _, err := UpsertSavedObject(kClient, "dashboard", metav1.ObjectMeta{Name: "my-dashboard"}, savedObject, nil); err != nil {
+				t.Fatalf("UpsertSavedObject() error = %v", err)
+			}
+			if reads != tt.wantReads {
+				t.Errorf("Expected %d reads after the creation, got %d", tt.wantReads, reads)
+			}
+		})
+	}
+}
+
+func TestUpsertSavedObject_Managed_FakeKibana(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	kClient := createTestKibanaClient(fakeKibana.URL())
+
+	space := "analytics"
+	meta := metav1.ObjectMeta{Name: "my-search", Namespace: "team-a"}
+	savedObject := kibanaeckv1alpha1.SavedObject{
+		Space:         &space,
+		Body:          `{"attributes": {"title": "v1", "description": "Errors of the last hour"}}`,
+		Managed:       true,
+		ManagedNotice: true,
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := UpsertSavedObject(kClient, "search", meta, savedObject, nil); err != nil {
+			t.Fatalf("UpsertSavedObject() error = %v", err)
+		}
+	}
+	if fakeKibana.CountRequests("POST", "/s/analytics/api/saved_objects/_import") != 2 {
+		t.Error("Expected managed saved objects to be imported")
+	}
+
+	stored, ok := fakeKibana.SavedObject(space, "search", "my-search")
+	if !ok {
+		t.Fatal("Expected the saved search to be imported into the space")
+	}
+	var object struct {
+		Managed    bool              `json:"managed"`
+		Attributes map[string]string `json:"attributes"`
+	}
+	if err := json.Unmarshal(stored, &object); err != nil {
+		t.Fatal(err)
+	}
+	if !object.Managed {
+		t.Errorf("Expected the managed flag to be set, got %s", stored)
+	}
+	wantDescription := "Errors of the last hour\n\nManaged by eck-custom-resources (team-a/my-search), changes made in Kibana are reverted."
+	if object.Attributes["description"] != wantDescription {
+		t.Errorf("description = %q, want %q", object.Attributes["description"], wantDescription)
+	}
+}
+
+func TestAddManagedNotice(t *testing.T) {
+	body, err := addManagedNotice(`{"references": []}`, metav1.ObjectMeta{Name: "dashboard", Namespace: "default"})
+	if err != nil {
+		t.Fatalf("addManagedNotice() error = %v", err)
+	}
+	want := `{"attributes":{"description":"Managed by eck-custom-resources (default/dashboard), changes made in Kibana are reverted."},"references":[]}`
+	if body != want {
+		t.Errorf("addManagedNotice() = %s, want %s", body, want)
+	}
+
+	if _, err := addManagedNotice(`{`, metav1.ObjectMeta{}); err == nil {
+		t.Error("Expected an error for an invalid body")
+	}
+}
+
+func TestAddDependencyReferences(t *testing.T) {
+	dependencies := []kibanaeckv1alpha1.Dependency{
+		{ObjectType: "lens", Name: "errors-by-host", ReferenceName: "panel_1"},
+		{ObjectType: "index-pattern", Name: "logs", ReferenceName: "kibanaSavedObjectMeta.searchSourceJSON.index"},
+		{ObjectType: "visualization", Name: "not-referenced"},
+		{ObjectType: kibanaeckv1alpha1.SavedObjectTypeElasticsearchIndex, Name: "logs-onboarding", ReferenceName: "ignored"},
+	}
+	body, err := addDependencyReferences(`{"attributes": {}, "references": [{"name": "panel_1", "type": "lens", "id": "placeholder"}, {"name": "panel_2", "type": "search", "id": "errors"}]}`, dependencies)
+	if err != nil {
+		t.Fatalf("addDependencyReferences() error = %v", err)
+	}
+	want := `{"attributes":{},"references":[{"id":"errors-by-host","name":"panel_1","type":"lens"},{"id":"errors","name":"panel_2","type":"search"},{"id":"logs","name":"kibanaSavedObjectMeta.searchSourceJSON.index","type":"index-pattern"}]}`
+	if body != want {
+		t.Errorf("addDependencyReferences() = %s, want %s", body, want)
+	}
+
+	unchanged := `{ "attributes": {} }`
+	if body, err := addDependencyReferences(unchanged, dependencies[2:3]); err != nil || body != unchanged {
+		t.Errorf("addDependencyReferences() = %s, %v, want the body unchanged", body, err)
+	}
+	if _, err := addDependencyReferences(`{`, dependencies); err == nil {
+		t.Error("Expected an error for an invalid body")
+	}
+}
+
+func TestDependenciesFulfilled_ElasticsearchIndex(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	fakeES := testutils.NewFakeElasticsearch()
+	defer fakeES.Close()
+
+	kClient := createTestKibanaClient(fakeKibana.URL())
+	kClient.Ctx = context.Background()
+	kClient.Elasticsearch = configv2.ElasticsearchSpec{Enabled: true, Url: fakeES.URL()}
+
+	dashboard := kibanaeckv1alpha1.SavedObject{
+		Dependencies: []kibanaeckv1alpha1.Dependency{
+			{ObjectType: kibanaeckv1alpha1.SavedObjectTypeElasticsearchIndex, Name: "logs-onboarding"},
+		},
+	}
+
+	err := DependenciesFulfilled(kClient, dashboard)
+	if err == nil || !strings.Contains(err.Error(), "elasticsearchIndex/logs-onboarding") {
+		t.Fatalf("Expected the missing index to be reported, got %v", err)
+	}
+	if fakeES.CountRequests(http.MethodHead, "/logs-onboarding") != 1 {
+		t.Errorf("Expected the index to be looked up in Elasticsearch, got requests %v", fakeES.Requests())
+	}
+
+	fakeES.Put(testutils.ESIndex, "logs-onboarding", `{}`)
+	if err := DependenciesFulfilled(kClient, dashboard); err != nil {
+		t.Errorf("DependenciesFulfilled() error = %v", err)
+	}
+
+	kClient.Elasticsearch = configv2.ElasticsearchSpec{}
+	if err := DependenciesFulfilled(kClient, dashboard); err == nil || !strings.Contains(err.Error(), "no Elasticsearch configured") {
+		t.Errorf("Expected an error without Elasticsearch, got %v", err)
+	}
+}
+
+func TestResolveSavedObjectConflict_FakeKibana(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	kClient := createTestKibanaClient(fakeKibana.URL())
+
+	dashboard := &kibanaeckv1alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "my-dashboard", Generation: 1}}
+	remote := func() (*string, error) {
+		return GetSavedObjectAttributes(kClient, "dashboard", "my-dashboard", nil)
+	}
+	savedObject := kibanaeckv1alpha1.SavedObject{Body: `{"attributes":{"title":"managed"}}`, ConflictPolicy: kibanaeckv1alpha1.ConflictPolicyFail}
+
+	upsert, _, err := ResolveSavedObjectConflict(dashboard, false, savedObject, nil, remote)
+	if err != nil || !upsert {
+		t.Errorf("ResolveSavedObjectConflict() = %v, %v, want a saved object not existing yet to be created", upsert, err)
+	}
+
+	fakeKibana.PutSavedObject(testutils.DefaultSpace, "dashboard", "my-dashboard", `{"attributes":{"title":"existing"}}`)
+	upsert, _, err = ResolveSavedObjectConflict(dashboard, false, savedObject, nil, remote)
+	if !errors.Is(err, ErrSavedObjectConflict) || upsert {
+		t.Errorf("ResolveSavedObjectConflict() = %v, %v, want a conflict", upsert, err)
+	}
+
+	savedObject.ConflictPolicy = kibanaeckv1alpha1.ConflictPolicyOverwrite
+	if upsert, _, err := ResolveSavedObjectConflict(dashboard, false, savedObject, nil, remote); err != nil || !upsert {
+		t.Errorf("ResolveSavedObjectConflict() = %v, %v, want the saved object to be overwritten", upsert, err)
+	}
+
+	savedObject.ConflictPolicy = kibanaeckv1alpha1.ConflictPolicyAdopt
+	upsert, adoption, err := ResolveSavedObjectConflict(dashboard, false, savedObject, nil, remote)
+	if err != nil || upsert {
+		t.Fatalf("ResolveSavedObjectConflict() = %v, %v, want the saved object to be adopted", upsert, err)
+	}
+	if adoption == nil || adoption.Attributes != `{"title":"existing"}` || adoption.Generation != 1 {
+		t.Fatalf("Expected the remote attributes to be recorded, got %+v", adoption)
+	}
+
+	// Once managed, the adopted saved object is only updated when the resource changes
+	if upsert, _, err := ResolveSavedObjectConflict(dashboard, true, savedObject, adoption, remote); err != nil || upsert {
+		t.Errorf("ResolveSavedObjectConflict() = %v, %v, want the adopted saved object to be left untouched", upsert, err)
+	}
+	dashboard.Generation = 2
+	upsert, kept, err := ResolveSavedObjectConflict(dashboard, true, savedObject, adoption, remote)
+	if err != nil || !upsert || kept != adoption {
+		t.Errorf("ResolveSavedObjectConflict() = %v, %+v, %v, want the changed resource to be applied", upsert, kept, err)
+	}
+	if fakeKibana.CountRequests(http.MethodGet, "/api/saved_objects/dashboard/my-dashboard") != 3 {
+		t.Errorf("Expected only unmanaged saved objects with conflict policy Fail or Adopt to be looked up, got requests %v", fakeKibana.Requests())
+	}
+}
+
+func TestUpsertSavedObject_Version_FakeKibana(t *testing.T) {
+	fakeKibana := testutils.NewFakeKibana()
+	defer fakeKibana.Close()
+	kClient := createTestKibanaClient(fakeKibana.URL())
+
+	meta := metav1.ObjectMeta{Name: "my-dashboard"}
+	savedObject := kibanaeckv1alpha1.SavedObject{Body: `{"attributes": {"title": "v1"}}`, UpdatePolicy: kibanaeckv1alpha1.UpdatePolicyFail}
+	var version string
+	if _, err := UpsertSavedObject(kClient, "dashboard", meta, savedObject, &version); err != nil {
+		t.Fatalf("UpsertSavedObject() create error = %v", err)
+	}
+	if version == "" || version != fakeKibana.SavedObjectVersion(testutils.DefaultSpace, "dashboard", "my-dashboard") {
+		t.Fatalf("Expected the version of the created saved object to be recorded, got %q", version)
+	}
+	savedObject.Body = `{"attributes": {"title": "v2"}}`
+	if _, err := UpsertSavedObject(kClient, "dashboard", meta, savedObject, &version); err != nil {
+		t.Fatalf("UpsertSavedObject() update error = %v", err)
+	}
+
+	edited := `{"attributes": {"title": "edited in Kibana"}}`
+	fakeKibana.PutSavedObject(testutils.DefaultSpace, "dashboard", "my-dashboard", edited)
+	savedObject.Body = `{"attributes": {"title": "v3"}}`
+	if _, err := UpsertSavedObject(kClient, "dashboard", meta, savedObject, &version); !errors.Is(err, ErrSavedObjectModified) {
+		t.Fatalf("UpsertSavedObject() error = %v, want ErrSavedObjectModified", err)
+	}
+	if stored, _ := fakeKibana.SavedObject(testutils.DefaultSpace, "dashboard", "my-dashboard"); string(stored) != edited {
+		t.Errorf("Expected the changes made in Kibana to be kept, got %s", stored)
+	}
+
+	savedObject.UpdatePolicy = kibanaeckv1alpha1.UpdatePolicyOverwrite
+	if _, err := UpsertSavedObject(kClient, "dashboard", meta, savedObject, &version); err != nil {
+		t.Fatalf("UpsertSavedObject() overwrite error = %v", err)
+	}
+	if stored, _ := fakeKibana.SavedObject(testutils.DefaultSpace, "dashboard", "my-dashboard"); !strings.Contains(string(stored), "v3") {
+		t.Errorf("Expected the changes made in Kibana to be overwritten, got %s", stored)
+	}
+	if version != fakeKibana.SavedObjectVersion(testutils.DefaultSpace, "dashboard", "my-dashboard") {
+		t.Errorf("Expected the version of the overwritten saved object to be recorded, got %q", version)
+	}
+}
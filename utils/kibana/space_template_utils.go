@@ -0,0 +1,167 @@
+package kibana
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// spaceTemplateKind is a kind of resource a SpaceTemplate selects, with the type of its saved object
+type spaceTemplateKind struct {
+	NewList         func() client.ObjectList
+	SavedObjectType string
+}
+
+var spaceTemplateKinds = []spaceTemplateKind{
+	{NewList: func() client.ObjectList { return &kibanaeckv1alpha1.DashboardList{} }, SavedObjectType: "dashboard"},
+	{NewList: func() client.ObjectList { return &kibanaeckv1alpha1.VisualizationList{} }, SavedObjectType: "visualization"},
+	{NewList: func() client.ObjectList { return &kibanaeckv1alpha1.LensList{} }, SavedObjectType: "lens"},
+	{NewList: func() client.ObjectList { return &kibanaeckv1alpha1.SavedSearchList{} }, SavedObjectType: "search"},
+	{NewList: func() client.ObjectList { return &kibanaeckv1alpha1.IndexPatternList{} }, SavedObjectType: "index-pattern"},
+	{NewList: func() client.ObjectList { return &kibanaeckv1alpha1.CanvasWorkpadList{} }, SavedObjectType: CanvasWorkpadSavedObjectType},
+	{NewList: func() client.ObjectList { return &kibanaeckv1alpha1.DataViewList{} }, SavedObjectType: "index-pattern"},
+}
+
+// SpaceTemplateObject is a saved object copied into a space by its template
+type SpaceTemplateObject struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	// Space the saved object is copied from, nil for the default space
+	Space *string `json:"-"`
+}
+
+// GetSpaceTemplateObjects returns the saved objects of the resources selected by spec.template of the space, sorted by
+// their space, type and ID. Resources deployed to another Kibana instance are skipped. It reports false if selected
+// resources were not deployed yet, the template is incomplete then.
+func GetSpaceTemplateObjects(kClient Client, space kibanaeckv1alpha1.Space) ([]SpaceTemplateObject, bool, error) {
+	if space.Spec.Template == nil {
+		return nil, true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&space.Spec.Template.Selector)
+	if err != nil {
+		return nil, false, fmt.Errorf("spec.template.selector is invalid: %w", err)
+	}
+
+	var objects []SpaceTemplateObject
+	complete := true
+	for _, kind := range spaceTemplateKinds {
+		list := kind.NewList()
+		if err := utils.ListPaged(kClient.Ctx, kClient.Cli, list, client.InNamespace(space.Namespace), client.MatchingLabelsSelector{Selector: selector}); meta.IsNoMatchError(err) {
+			// The CRD of the kind is not installed
+			continue
+		} else if err != nil {
+			return nil, false, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, item := range items {
+			resource := item.(kibanaeckv1alpha1.SavedObjectResource)
+			if !resource.GetDeletionTimestamp().IsZero() || !equality.Semantic.DeepEqual(resource.GetTargetConfig(), space.Spec.TargetConfig) {
+				continue
+			}
+			deployedTo := resource.GetDeployedTo()
+			if deployedTo == nil {
+				complete = false
+				continue
+			}
+			objects = append(objects, SpaceTemplateObject{Type: kind.SavedObjectType, ID: kibanaeckv1alpha1.SavedObjectIDOf(resource), Space: deployedTo.Space})
+		}
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		if spaceI, spaceJ := spaceID(objects[i].Space), spaceID(objects[j].Space); spaceI != spaceJ {
+			return spaceI < spaceJ
+		}
+		if objects[i].Type != objects[j].Type {
+			return objects[i].Type < objects[j].Type
+		}
+		return objects[i].ID < objects[j].ID
+	})
+	return objects, complete, nil
+}
+
+// CopySpaceTemplateObjects copies the saved objects and the saved objects they reference into the space, overwriting
+// objects of the same ID. The objects are copied from each of their spaces by the copy saved objects API of Kibana.
+func CopySpaceTemplateObjects(kClient Client, objects []SpaceTemplateObject, space string) error {
+	bySpace := map[string][]SpaceTemplateObject{}
+	var sources []string
+	for _, object := range objects {
+		source := spaceID(object.Space)
+		if source == space {
+			continue
+		}
+		if _, ok := bySpace[source]; !ok {
+			sources = append(sources, source)
+		}
+		bySpace[source] = append(bySpace[source], object)
+	}
+
+	for _, source := range sources {
+		body, err := json.Marshal(map[string]any{
+			"objects":           bySpace[source],
+			"spaces":            []string{space},
+			"includeReferences": true,
+			"overwrite":         true,
+		})
+		if err != nil {
+			return err
+		}
+		path := "/api/spaces/_copy_saved_objects"
+		if source != DefaultSpaceID {
+			path = fmt.Sprintf("/s/%s%s", source, path)
+		}
+		res, err := kClient.DoPost(path, string(body))
+		if err != nil {
+			return err
+		}
+		if err := checkCopyResponse(res.StatusCode, res.Body, space); err != nil {
+			return fmt.Errorf("failed to copy the saved objects of space %s: %w", source, err)
+		}
+	}
+	return nil
+}
+
+// checkCopyResponse returns an error unless the copy saved objects API reported success for the space
+func checkCopyResponse(statusCode int, body io.ReadCloser, space string) error {
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if statusCode > 299 {
+		return fmt.Errorf("Non-success (%d) response: %s", statusCode, string(content))
+	}
+	var response map[string]struct {
+		Success bool              `json:"success"`
+		Errors  []json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(content, &response); err != nil {
+		return err
+	}
+	result, ok := response[space]
+	if !ok {
+		return fmt.Errorf("the response does not report space %s: %s", space, string(content))
+	}
+	if !result.Success {
+		return fmt.Errorf("%d saved objects were not copied: %s", len(result.Errors), string(content))
+	}
+	return nil
+}
+
+// spaceID returns the ID of the space, the default space for nil
+func spaceID(space *string) string {
+	if space == nil {
+		return DefaultSpaceID
+	}
+	return *space
+}
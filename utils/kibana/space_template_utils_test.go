@@ -0,0 +1,110 @@
+package kibana
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetSpaceTemplateObjects(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = kibanaeckv1alpha1.AddToScheme(scheme)
+	labels := map[string]string{"template": "tenant"}
+	deployed := func(space *string) kibanaeckv1alpha1.DashboardStatus {
+		return kibanaeckv1alpha1.DashboardStatus{DeployedTo: &kibanaeckv1alpha1.SavedObjectLocation{Space: space}}
+	}
+	space := kibanaeckv1alpha1.Space{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Namespace: "default"},
+		Spec: kibanaeckv1alpha1.SpaceSpec{
+			Template: &kibanaeckv1alpha1.SpaceTemplate{Selector: metav1.LabelSelector{MatchLabels: labels}},
+		},
+	}
+
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&kibanaeckv1alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "overview", Namespace: "default", Labels: labels}, Status: deployed(strPtr("templates"))},
+		&kibanaeckv1alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "unlabeled", Namespace: "default"}, Status: deployed(nil)},
+		&kibanaeckv1alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "other", Labels: labels}, Status: deployed(nil)},
+		&kibanaeckv1alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "other-kibana", Namespace: "default", Labels: labels},
+			Spec: kibanaeckv1alpha1.DashboardSpec{TargetConfig: kibanaeckv1alpha1.CommonKibanaConfig{KibanaInstance: "other"}}},
+		&kibanaeckv1alpha1.DataView{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "default", Labels: labels},
+			Status: kibanaeckv1alpha1.DataViewStatus{DeployedTo: &kibanaeckv1alpha1.SavedObjectLocation{}}},
+	).WithStatusSubresource(&kibanaeckv1alpha1.Dashboard{}, &kibanaeckv1alpha1.DataView{}).Build()
+	kClient := Client{Cli: cli, Ctx: context.Background()}
+
+	objects, complete, err := GetSpaceTemplateObjects(kClient, space)
+	if err != nil || !complete {
+		t.Fatalf("GetSpaceTemplateObjects() = %v, %v, want all selected resources to be deployed", complete, err)
+	}
+	want := []SpaceTemplateObject{
+		{Type: "index-pattern", ID: "logs"},
+		{Type: "dashboard", ID: "overview", Space: strPtr("templates")},
+	}
+	if !reflect.DeepEqual(objects, want) {
+		t.Errorf("GetSpaceTemplateObjects() = %+v, want %+v", objects, want)
+	}
+
+	if err := cli.Create(context.Background(), &kibanaeckv1alpha1.Lens{ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default", Labels: labels}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, complete, err := GetSpaceTemplateObjects(kClient, space); err != nil || complete {
+		t.Errorf("GetSpaceTemplateObjects() = %v, %v, want the undeployed Lens to be pending", complete, err)
+	}
+}
+
+func TestCopySpaceTemplateObjects(t *testing.T) {
+	var requests = map[string][]map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request map[string]any
+		if err := json.Unmarshal(body, &request); err != nil {
+			t.Errorf("Invalid request body %s: %v", body, err)
+		}
+		requests[r.URL.Path] = append(requests[r.URL.Path], request)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"tenant-a": {"success": true, "successCount": 1}}`))
+	}))
+	defer server.Close()
+
+	objects := []SpaceTemplateObject{
+		{Type: "index-pattern", ID: "logs"},
+		{Type: "dashboard", ID: "overview", Space: strPtr("templates")},
+		{Type: "lens", ID: "already-there", Space: strPtr("tenant-a")},
+	}
+	if err := CopySpaceTemplateObjects(createTestKibanaClient(server.URL), objects, "tenant-a"); err != nil {
+		t.Fatalf("CopySpaceTemplateObjects() error = %v", err)
+	}
+
+	if len(requests) != 2 || len(requests["/api/spaces/_copy_saved_objects"]) != 1 || len(requests["/s/templates/api/spaces/_copy_saved_objects"]) != 1 {
+		t.Fatalf("Expected one copy from the default and the templates space, got %v", requests)
+	}
+	request := requests["/s/templates/api/spaces/_copy_saved_objects"][0]
+	if !reflect.DeepEqual(request["objects"], []any{map[string]any{"type": "dashboard", "id": "overview"}}) {
+		t.Errorf("Expected the dashboard to be copied, got %v", request["objects"])
+	}
+	if !reflect.DeepEqual(request["spaces"], []any{"tenant-a"}) || request["includeReferences"] != true || request["overwrite"] != true {
+		t.Errorf("Expected the references to be copied into tenant-a, got %v", request)
+	}
+}
+
+func TestCopySpaceTemplateObjects_Failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"tenant-a": {"success": false, "successCount": 0, "errors": [{"id": "overview", "type": "dashboard", "error": {"type": "missing_references"}}]}}`))
+	}))
+	defer server.Close()
+
+	objects := []SpaceTemplateObject{{Type: "dashboard", ID: "overview"}}
+	if err := CopySpaceTemplateObjects(createTestKibanaClient(server.URL), objects, "tenant-a"); err == nil {
+		t.Error("Expected an error when Kibana reports a failed copy")
+	}
+}
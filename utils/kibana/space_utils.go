@@ -1,9 +1,11 @@
 package kibana
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"slices"
 
 	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
 	"eck-custom-resources/utils"
@@ -11,7 +13,43 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
+// DefaultSpaceID is the ID of the default space of Kibana. It cannot be deleted in Kibana and changing it affects
+// every user, so it is never managed by a Space resource.
+const DefaultSpaceID = "default"
+
+// CriticalSpaceFeatures are the features whose disabling makes the saved objects and settings managed by the
+// operator in the space inaccessible in the Kibana UI
+var CriticalSpaceFeatures = []string{"advancedSettings", "dashboard", "discover", "indexPatterns", "savedObjectsManagement", "visualize"}
+
+// VerifySpaceNotProtected returns an error if the space is the default space
+func VerifySpaceNotProtected(spaceName string) error {
+	if spaceName != DefaultSpaceID {
+		return nil
+	}
+	return fmt.Errorf("space %s is the default space of Kibana and cannot be managed by a Space resource", spaceName)
+}
+
+// GetDisabledCriticalFeatures returns the CriticalSpaceFeatures listed in disabledFeatures of the space body
+func GetDisabledCriticalFeatures(body string) []string {
+	var space struct {
+		DisabledFeatures []string `json:"disabledFeatures"`
+	}
+	if err := json.Unmarshal([]byte(body), &space); err != nil {
+		return nil
+	}
+	var disabled []string
+	for _, feature := range CriticalSpaceFeatures {
+		if slices.Contains(space.DisabledFeatures, feature) {
+			disabled = append(disabled, feature)
+		}
+	}
+	return disabled
+}
+
 func DeleteSpace(kClient Client, spaceName string) (ctrl.Result, error) {
+	if err := VerifySpaceNotProtected(spaceName); err != nil {
+		return ctrl.Result{}, err
+	}
 	_, deleteErr := kClient.DoDelete(fmt.Sprintf("/api/spaces/space/%s", spaceName))
 	return ctrl.Result{}, deleteErr
 }
@@ -25,7 +63,7 @@ func UpsertSpace(kClient Client, space kibanaeckv1alpha1.Space) (ctrl.Result, er
 
 	var res *http.Response
 
-	modifiedBody, err := InjectId(space.Spec.Body, space.Name)
+	modifiedBody, err := InjectId(space.Spec.GetBody(), space.Name)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
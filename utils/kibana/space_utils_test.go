@@ -3,6 +3,7 @@ package kibana
 import (
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	configv2 "eck-custom-resources/api/config/v2"
@@ -304,3 +305,36 @@ func createTestClient(serverURL string) Client {
 		Req: ctrl.Request{},
 	}
 }
+
+func TestVerifySpaceNotProtected(t *testing.T) {
+	if err := VerifySpaceNotProtected("default"); err == nil {
+		t.Error("Expected the default space to be protected")
+	}
+	if err := VerifySpaceNotProtected("team-a"); err != nil {
+		t.Errorf("VerifySpaceNotProtected(team-a) error = %v", err)
+	}
+	if _, err := DeleteSpace(Client{}, "default"); err == nil {
+		t.Error("Expected deleting the default space to be refused")
+	}
+}
+
+func TestGetDisabledCriticalFeatures(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "no disabled features", body: `{"name": "Team A", "disabledFeatures": []}`, want: nil},
+		{name: "uncritical features", body: `{"disabledFeatures": ["apm", "uptime"]}`, want: nil},
+		{name: "critical features", body: `{"disabledFeatures": ["dashboard", "apm", "advancedSettings"]}`, want: []string{"advancedSettings", "dashboard"}},
+		{name: "invalid body", body: `{`, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetDisabledCriticalFeatures(tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetDisabledCriticalFeatures() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
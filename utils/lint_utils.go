@@ -0,0 +1,288 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	configv2 "eck-custom-resources/api/config/v2"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PolicyViolation condition, set while the rendered body of a resource violates lint rules of the ProjectConfig
+const (
+	PolicyViolationConditionType = "PolicyViolation"
+	PolicyViolationReason        = "PolicyViolation"
+)
+
+// lintRules are the rules checked by LintBody, set from the ProjectConfig
+var lintRules atomic.Pointer[[]configv2.LintRule]
+
+// SetLintRules sets the rules checked by LintBody
+func SetLintRules(rules []configv2.LintRule) {
+	lintRules.Store(&rules)
+}
+
+// PolicyViolationError lists the lint rules the body of a resource violates
+type PolicyViolationError struct {
+	Violations []string
+}
+
+func (e *PolicyViolationError) Error() string {
+	return "the body violates lint rules: " + strings.Join(e.Violations, "; ")
+}
+
+// ValidateLintRules returns an error describing the first rule which is incomplete or cannot be evaluated
+func ValidateLintRules(rules []configv2.LintRule) error {
+	names := map[string]bool{}
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return fmt.Errorf("lint[%d].name is required", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("lint[%d]: duplicate rule name %q", i, rule.Name)
+		}
+		names[rule.Name] = true
+		if _, err := parseLintPath(rule.Path); err != nil {
+			return fmt.Errorf("lint[%d].path: %w", i, err)
+		}
+		switch rule.Operator {
+		case configv2.LintOperatorExists, configv2.LintOperatorNotExists, configv2.LintOperatorEquals, configv2.LintOperatorNotEquals:
+		case configv2.LintOperatorGreaterOrEqual, configv2.LintOperatorLessOrEqual:
+			if _, ok := lintNumber(rule.Value); !ok {
+				return fmt.Errorf("lint[%d].value: %q is neither a number nor a time value like 30d", i, rule.Value)
+			}
+		case configv2.LintOperatorMatches:
+			if _, err := regexp.Compile(rule.Value); err != nil {
+				return fmt.Errorf("lint[%d].value: %w", i, err)
+			}
+		default:
+			return fmt.Errorf("lint[%d].operator: unsupported operator %q", i, rule.Operator)
+		}
+	}
+	return nil
+}
+
+// LintBody checks the rendered body of obj against the lint rules applying to its kind and namespace. It returns a
+// *PolicyViolationError listing the violated rules, nil if the body complies. Bodies which are no JSON are left to
+// the target.
+func LintBody(obj client.Object, body string) error {
+	rules := lintRules.Load()
+	if rules == nil {
+		return nil
+	}
+	return lintBody(*rules, reflect.TypeOf(obj).Elem().Name(), obj.GetNamespace(), body)
+}
+
+func lintBody(rules []configv2.LintRule, kind string, namespace string, body string) error {
+	var parsed any
+	if len(rules) == 0 || json.Unmarshal([]byte(body), &parsed) != nil {
+		return nil
+	}
+	var violations []string
+	for _, rule := range rules {
+		if len(rule.Kinds) > 0 && !slices.Contains(rule.Kinds, kind) {
+			continue
+		}
+		if len(rule.Namespaces) > 0 && !slices.Contains(rule.Namespaces, namespace) {
+			continue
+		}
+		if problem := checkLintRule(rule, parsed); problem != "" {
+			violation := fmt.Sprintf("%s: %s", rule.Name, problem)
+			if rule.Message != "" {
+				violation += " (" + rule.Message + ")"
+			}
+			violations = append(violations, violation)
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return &PolicyViolationError{Violations: violations}
+}
+
+// IsPolicyViolation reports whether err is or wraps a *PolicyViolationError
+func IsPolicyViolation(err error) bool {
+	var violation *PolicyViolationError
+	return errors.As(err, &violation)
+}
+
+// checkLintRule returns why the body violates the rule, empty if it does not
+func checkLintRule(rule configv2.LintRule, body any) string {
+	segments, err := parseLintPath(rule.Path)
+	if err != nil {
+		return err.Error()
+	}
+	values := selectLintPath(body, segments)
+	switch rule.Operator {
+	case configv2.LintOperatorExists:
+		if len(values) == 0 {
+			return fmt.Sprintf("%s is not set", rule.Path)
+		}
+		return ""
+	case configv2.LintOperatorNotExists:
+		if len(values) > 0 {
+			return fmt.Sprintf("%s must not be set", rule.Path)
+		}
+		return ""
+	}
+	if len(values) == 0 {
+		if rule.Optional {
+			return ""
+		}
+		return fmt.Sprintf("%s is not set", rule.Path)
+	}
+	for _, value := range values {
+		if problem := compareLintValue(rule, value); problem != "" {
+			return problem
+		}
+	}
+	return ""
+}
+
+// compareLintValue returns why the value found at the path of the rule violates it, empty if it does not
+func compareLintValue(rule configv2.LintRule, value any) string {
+	text := lintString(value)
+	switch rule.Operator {
+	case configv2.LintOperatorEquals:
+		if text != rule.Value {
+			return fmt.Sprintf("%s is %s, expected %s", rule.Path, text, rule.Value)
+		}
+	case configv2.LintOperatorNotEquals:
+		if text == rule.Value {
+			return fmt.Sprintf("%s must not be %s", rule.Path, text)
+		}
+	case configv2.LintOperatorMatches:
+		pattern, err := regexp.Compile(rule.Value)
+		if err != nil {
+			return err.Error()
+		}
+		if !pattern.MatchString(text) {
+			return fmt.Sprintf("%s is %s, expected it to match %s", rule.Path, text, rule.Value)
+		}
+	case configv2.LintOperatorGreaterOrEqual, configv2.LintOperatorLessOrEqual:
+		actual, ok := lintNumber(text)
+		limit, limitOk := lintNumber(rule.Value)
+		if !ok || !limitOk {
+			return fmt.Sprintf("%s is %s, expected a number or time value", rule.Path, text)
+		}
+		if rule.Operator == configv2.LintOperatorGreaterOrEqual && actual < limit {
+			return fmt.Sprintf("%s is %s, expected at least %s", rule.Path, text, rule.Value)
+		}
+		if rule.Operator == configv2.LintOperatorLessOrEqual && actual > limit {
+			return fmt.Sprintf("%s is %s, expected at most %s", rule.Path, text, rule.Value)
+		}
+	}
+	return ""
+}
+
+// lintString formats a JSON value for comparisons, scalars as they are written in the body
+func lintString(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	marshalled, _ := json.Marshal(value)
+	return string(marshalled)
+}
+
+var lintTimeUnits = map[string]time.Duration{
+	"d": 24 * time.Hour, "h": time.Hour, "m": time.Minute, "s": time.Second,
+	"ms": time.Millisecond, "micros": time.Microsecond, "nanos": time.Nanosecond,
+}
+
+var lintTimeValuePattern = regexp.MustCompile(`^(\d+)(d|h|m|s|ms|micros|nanos)$`)
+
+// lintNumber parses a number, or a time value of Elasticsearch like 30d as nanoseconds
+func lintNumber(value string) (float64, bool) {
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return number, true
+	}
+	match := lintTimeValuePattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, false
+	}
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return number * float64(lintTimeUnits[match[2]]), true
+}
+
+// lintPathPattern matches a segment of a lint path: .key, .*, [*], [n] or ['key']
+var lintPathPattern = regexp.MustCompile(`^(?:\.([^.\[\]]+)|\[(\*|\d+)\]|\['([^']*)'\])`)
+
+// parseLintPath splits a JSONPath like $.settings['index.number_of_replicas'] into its keys, * selecting all keys or
+// items
+func parseLintPath(path string) ([]string, error) {
+	rest := strings.TrimPrefix(path, "$")
+	if rest == path && !strings.HasPrefix(path, ".") && !strings.HasPrefix(path, "[") {
+		rest = "." + path
+	}
+	if rest == "" {
+		return nil, errors.New("the path has to select a value, e.g. $.settings.index.number_of_replicas")
+	}
+	var segments []string
+	for rest != "" {
+		match := lintPathPattern.FindStringSubmatch(rest)
+		if match == nil {
+			return nil, fmt.Errorf("%q is not a supported JSONPath, expected keys like $.settings['index.number_of_replicas'] or $.phases.*.min_age", path)
+		}
+		segments = append(segments, match[1]+match[2]+match[3])
+		rest = rest[len(match[0]):]
+	}
+	return segments, nil
+}
+
+// selectLintPath returns the values at the path in the body, in the order of their keys
+func selectLintPath(value any, segments []string) []any {
+	if len(segments) == 0 {
+		return []any{value}
+	}
+	segment, rest := segments[0], segments[1:]
+	switch value := value.(type) {
+	case map[string]any:
+		if segment != "*" {
+			child, ok := value[segment]
+			if !ok {
+				return nil
+			}
+			return selectLintPath(child, rest)
+		}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var values []any
+		for _, key := range keys {
+			values = append(values, selectLintPath(value[key], rest)...)
+		}
+		return values
+	case []any:
+		if segment != "*" {
+			index, err := strconv.Atoi(segment)
+			if err != nil || index >= len(value) {
+				return nil
+			}
+			return selectLintPath(value[index], rest)
+		}
+		var values []any
+		for _, item := range value {
+			values = append(values, selectLintPath(item, rest)...)
+		}
+		return values
+	}
+	return nil
+}
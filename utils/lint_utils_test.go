@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	configv2 "eck-custom-resources/api/config/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLintBody(t *testing.T) {
+	replicas := configv2.LintRule{Name: "replicas", Path: "$.settings.index.number_of_replicas", Operator: configv2.LintOperatorGreaterOrEqual, Value: "1"}
+	retention := configv2.LintRule{Name: "retention", Path: "$.policy.phases.delete.min_age", Operator: configv2.LintOperatorGreaterOrEqual, Value: "30d", Optional: true}
+
+	tests := []struct {
+		name      string
+		rule      configv2.LintRule
+		kind      string
+		namespace string
+		body      string
+		wantRules []string
+	}{
+		{name: "number complies", rule: replicas, body: `{"settings": {"index": {"number_of_replicas": 1}}}`},
+		{name: "number as string", rule: replicas, body: `{"settings": {"index": {"number_of_replicas": "0"}}}`, wantRules: []string{"replicas"}},
+		{name: "missing path", rule: replicas, body: `{"settings": {}}`, wantRules: []string{"replicas"}},
+		{name: "quoted key", rule: configv2.LintRule{Name: "dotted", Path: "$.settings['index.number_of_replicas']", Operator: configv2.LintOperatorGreaterOrEqual, Value: "1"},
+			body: `{"settings": {"index.number_of_replicas": 0}}`, wantRules: []string{"dotted"}},
+		{name: "time value complies", rule: retention, body: `{"policy": {"phases": {"delete": {"min_age": "90d"}}}}`},
+		{name: "time value in other unit", rule: retention, body: `{"policy": {"phases": {"delete": {"min_age": "72h"}}}}`, wantRules: []string{"retention"}},
+		{name: "optional missing path", rule: retention, body: `{"policy": {"phases": {"hot": {}}}}`},
+		{name: "wildcard", rule: configv2.LintRule{Name: "ages", Path: "$.policy.phases.*.min_age", Operator: configv2.LintOperatorLessOrEqual, Value: "365d"},
+			body: `{"policy": {"phases": {"warm": {"min_age": "7d"}, "delete": {"min_age": "400d"}}}}`, wantRules: []string{"ages"}},
+		{name: "list index", rule: configv2.LintRule{Name: "first", Path: "$.index_patterns[0]", Operator: configv2.LintOperatorMatches, Value: "^logs-"},
+			body: `{"index_patterns": ["metrics-*"]}`, wantRules: []string{"first"}},
+		{name: "exists", rule: configv2.LintRule{Name: "ilm", Path: "settings.index.lifecycle.name", Operator: configv2.LintOperatorExists},
+			body: `{"settings": {}}`, wantRules: []string{"ilm"}},
+		{name: "not exists", rule: configv2.LintRule{Name: "no-script", Path: "$.processors[*].script", Operator: configv2.LintOperatorNotExists},
+			body: `{"processors": [{"set": {}}, {"script": {}}]}`, wantRules: []string{"no-script"}},
+		{name: "equals", rule: configv2.LintRule{Name: "codec", Path: "$.settings.index.codec", Operator: configv2.LintOperatorEquals, Value: "best_compression"},
+			body: `{"settings": {"index": {"codec": "best_compression"}}}`},
+		{name: "not equals", rule: configv2.LintRule{Name: "hidden", Path: "$.settings.index.hidden", Operator: configv2.LintOperatorNotEquals, Value: "true"},
+			body: `{"settings": {"index": {"hidden": true}}}`, wantRules: []string{"hidden"}},
+		{name: "other kind", rule: configv2.LintRule{Name: "replicas", Kinds: []string{"Index"}, Path: replicas.Path, Operator: replicas.Operator, Value: "1"},
+			body: `{}`},
+		{name: "other namespace", rule: configv2.LintRule{Name: "replicas", Namespaces: []string{"prod"}, Path: replicas.Path, Operator: replicas.Operator, Value: "1"},
+			namespace: "dev", body: `{}`},
+		{name: "no JSON", rule: replicas, body: `PUT _ingest`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind := tt.kind
+			if kind == "" {
+				kind = "IndexTemplate"
+			}
+			err := lintBody([]configv2.LintRule{tt.rule}, kind, tt.namespace, tt.body)
+			var violation *PolicyViolationError
+			if len(tt.wantRules) == 0 {
+				if err != nil {
+					t.Errorf("lintBody() error = %v, want no violation", err)
+				}
+				return
+			}
+			if !errors.As(err, &violation) {
+				t.Fatalf("lintBody() error = %v, want a PolicyViolationError", err)
+			}
+			var rules []string
+			for _, v := range violation.Violations {
+				rules = append(rules, strings.SplitN(v, ":", 2)[0])
+			}
+			if !reflect.DeepEqual(rules, tt.wantRules) {
+				t.Errorf("Violations = %v, want the rules %v", violation.Violations, tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestLintBody_SetLintRules(t *testing.T) {
+	defer SetLintRules(nil)
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "logs"}}
+	SetLintRules([]configv2.LintRule{{Name: "owner", Kinds: []string{"ConfigMap"}, Path: "$.owner", Operator: configv2.LintOperatorExists, Message: "set the owning team"}})
+
+	err := LintBody(obj, `{}`)
+	if !IsPolicyViolation(err) || !strings.Contains(err.Error(), "set the owning team") {
+		t.Errorf("LintBody() error = %v, want a violation explained by the message", err)
+	}
+	if !IsPolicyViolation(fmt.Errorf("wrapped: %w", err)) {
+		t.Error("Expected IsPolicyViolation to detect wrapped violations")
+	}
+	if err := LintBody(obj, `{"owner": "team-a"}`); err != nil {
+		t.Errorf("LintBody() error = %v, want no violation", err)
+	}
+}
+
+func TestValidateLintRules(t *testing.T) {
+	valid := configv2.LintRule{Name: "replicas", Path: "$.settings.index.number_of_replicas", Operator: configv2.LintOperatorGreaterOrEqual, Value: "1"}
+	tests := []struct {
+		name    string
+		rules   []configv2.LintRule
+		wantErr bool
+	}{
+		{name: "no rules"},
+		{name: "valid", rules: []configv2.LintRule{valid, {Name: "ilm", Path: "$.settings['index.lifecycle.name']", Operator: configv2.LintOperatorExists}}},
+		{name: "missing name", rules: []configv2.LintRule{{Path: valid.Path, Operator: configv2.LintOperatorExists}}, wantErr: true},
+		{name: "duplicate name", rules: []configv2.LintRule{valid, valid}, wantErr: true},
+		{name: "invalid path", rules: []configv2.LintRule{{Name: "path", Path: "$.settings[index", Operator: configv2.LintOperatorExists}}, wantErr: true},
+		{name: "empty path", rules: []configv2.LintRule{{Name: "path", Path: "$", Operator: configv2.LintOperatorExists}}, wantErr: true},
+		{name: "unsupported operator", rules: []configv2.LintRule{{Name: "op", Path: valid.Path, Operator: "Contains"}}, wantErr: true},
+		{name: "no number", rules: []configv2.LintRule{{Name: "age", Path: valid.Path, Operator: configv2.LintOperatorLessOrEqual, Value: "1.5d"}}, wantErr: true},
+		{name: "invalid expression", rules: []configv2.LintRule{{Name: "re", Path: valid.Path, Operator: configv2.LintOperatorMatches, Value: "("}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateLintRules(tt.rules); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLintRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetReadyCondition_PolicyViolation(t *testing.T) {
+	var conditions []metav1.Condition
+	SetReadyCondition(&conditions, 2, &PolicyViolationError{Violations: []string{"replicas: too few"}})
+
+	ready := meta.FindStatusCondition(conditions, ReadyConditionType)
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != PolicyViolationReason {
+		t.Errorf("Expected Ready=False with reason PolicyViolation, got %+v", ready)
+	}
+	if !meta.IsStatusConditionTrue(conditions, PolicyViolationConditionType) {
+		t.Errorf("Expected the PolicyViolation condition to be set, got %+v", conditions)
+	}
+
+	SetReadyCondition(&conditions, 3, nil)
+	if meta.FindStatusCondition(conditions, PolicyViolationConditionType) != nil {
+		t.Errorf("Expected the PolicyViolation condition to be removed, got %+v", conditions)
+	}
+}
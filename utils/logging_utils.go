@@ -0,0 +1,255 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// Log levels are logr verbosities: 0 logs info messages, 1 debug messages and higher levels more details. -1 only
+// logs errors.
+const (
+	LogLevelError = -1
+	LogLevelInfo  = 0
+	LogLevelDebug = 1
+)
+
+// logLevels holds the default log level and the log levels of the reconciles of single kinds. The zap logger logs at
+// the highest level in use, messages above the level of their kind are dropped by levelFilterSink.
+type logLevels struct {
+	mu           sync.RWMutex
+	defaultLevel int
+	kinds        map[string]int
+	zapLevel     *uberzap.AtomicLevel
+}
+
+var defaultLogLevels = &logLevels{kinds: make(map[string]int)}
+
+// ConfigureLogLevels takes the default log level from the zap options and sets the levels of the controllers, given
+// as comma separated kind=level pairs. The level of the zap options is replaced by one following the highest level
+// in use, the logger built from them has to be wrapped with FilterLogLevel.
+func ConfigureLogLevels(opts *zap.Options, controllerLevels string) error {
+	defaultLevel := LogLevelInfo
+	if opts.Development {
+		defaultLevel = LogLevelDebug
+	}
+	if opts.Level != nil {
+		defaultLevel = LogLevelError
+		for level := LogLevelInfo; opts.Level.Enabled(zapcore.Level(-level)); level++ {
+			defaultLevel = level
+		}
+	}
+	kinds, err := ParseControllerLogLevels(controllerLevels)
+	if err != nil {
+		return err
+	}
+
+	zapLevel := uberzap.NewAtomicLevel()
+	opts.Level = &zapLevel
+	defaultLogLevels.mu.Lock()
+	defer defaultLogLevels.mu.Unlock()
+	defaultLogLevels.defaultLevel = defaultLevel
+	defaultLogLevels.kinds = kinds
+	defaultLogLevels.zapLevel = &zapLevel
+	defaultLogLevels.updateZapLevel()
+	return nil
+}
+
+// ParseControllerLogLevels parses comma separated kind=level pairs, e.g. Index=debug,Dashboard=2
+func ParseControllerLogLevels(value string) (map[string]int, error) {
+	kinds := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kind, levelValue, ok := strings.Cut(pair, "=")
+		kind = strings.TrimSpace(kind)
+		if !ok || kind == "" {
+			return nil, fmt.Errorf("invalid controller log level %q, expected kind=level", pair)
+		}
+		level, err := ParseLogLevel(levelValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level of %s: %w", kind, err)
+		}
+		kinds[kind] = level
+	}
+	return kinds, nil
+}
+
+// ParseLogLevel parses error, info, debug or a verbosity
+func ParseLogLevel(value string) (int, error) {
+	switch value = strings.ToLower(strings.TrimSpace(value)); value {
+	case "error":
+		return LogLevelError, nil
+	case "info":
+		return LogLevelInfo, nil
+	case "debug":
+		return LogLevelDebug, nil
+	}
+	level, err := strconv.Atoi(value)
+	if err != nil || level < LogLevelError {
+		return 0, fmt.Errorf("%q is not one of error, info, debug or a verbosity", value)
+	}
+	return level, nil
+}
+
+// SetControllerLogLevel sets the log level of the reconciles of the kind at runtime
+func SetControllerLogLevel(kind string, level int) {
+	defaultLogLevels.mu.Lock()
+	defer defaultLogLevels.mu.Unlock()
+	defaultLogLevels.kinds[kind] = level
+	defaultLogLevels.updateZapLevel()
+}
+
+// ResetControllerLogLevel makes the reconciles of the kind log at the default level again
+func ResetControllerLogLevel(kind string) {
+	defaultLogLevels.mu.Lock()
+	defer defaultLogLevels.mu.Unlock()
+	delete(defaultLogLevels.kinds, kind)
+	defaultLogLevels.updateZapLevel()
+}
+
+// SetDefaultLogLevel sets the log level of everything not logged by a reconcile of a kind with a level of its own
+func SetDefaultLogLevel(level int) {
+	defaultLogLevels.mu.Lock()
+	defer defaultLogLevels.mu.Unlock()
+	defaultLogLevels.defaultLevel = level
+	defaultLogLevels.updateZapLevel()
+}
+
+func (l *logLevels) level(kind string) int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if level, ok := l.kinds[kind]; ok {
+		return level
+	}
+	return l.defaultLevel
+}
+
+// updateZapLevel lets the zap logger log at the highest level in use, l.mu has to be held
+func (l *logLevels) updateZapLevel() {
+	if l.zapLevel == nil {
+		return
+	}
+	highest := l.defaultLevel
+	for _, level := range l.kinds {
+		highest = max(highest, level)
+	}
+	l.zapLevel.SetLevel(zapcore.Level(-highest))
+}
+
+// FilterLogLevel wraps the logger so that it logs at the default log level
+func FilterLogLevel(logger logr.Logger) logr.Logger {
+	return logger.WithSink(newLevelFilterSink(logger.GetSink(), ""))
+}
+
+// ReconcileLogger returns the context of a reconcile of the kind with a logger logging at the level of the kind. Next
+// to the namespace and name added by controller-runtime, the messages carry the kind and the attempt, the number of
+// the reconcile of the resource since its last success. The attempt is left out if it is 0.
+func ReconcileLogger(ctx context.Context, kind string, attempt int) (context.Context, logr.Logger) {
+	logger := log.FromContext(ctx)
+	logger = logger.WithSink(newLevelFilterSink(logger.GetSink(), kind)).WithValues("kind", kind)
+	if attempt > 0 {
+		logger = logger.WithValues("attempt", attempt)
+	}
+	return log.IntoContext(ctx, logger), logger
+}
+
+// WithTargetInstance adds the URL of the target instance the resource is reconciled against to the logger of the
+// reconcile
+func WithTargetInstance(ctx context.Context, url string) (context.Context, logr.Logger) {
+	logger := log.FromContext(ctx).WithValues("targetInstance", url)
+	return log.IntoContext(ctx, logger), logger
+}
+
+// levelFilterSink drops messages above the log level of its kind, the default level if kind is empty
+type levelFilterSink struct {
+	logr.LogSink
+	kind string
+}
+
+func newLevelFilterSink(sink logr.LogSink, kind string) logr.LogSink {
+	if withCallDepth, ok := sink.(logr.CallDepthLogSink); ok {
+		// levelFilterSink adds a frame between the caller and the sink
+		sink = withCallDepth.WithCallDepth(1)
+	}
+	return levelFilterSink{LogSink: sink, kind: kind}
+}
+
+// Enabled reports whether messages of the level are logged. Only the level of the kind applies: the sinks wrapped
+// are asked for their messages without checking whether they are enabled.
+func (s levelFilterSink) Enabled(level int) bool {
+	return level <= defaultLogLevels.level(s.kind)
+}
+
+func (s levelFilterSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return levelFilterSink{LogSink: s.LogSink.WithValues(keysAndValues...), kind: s.kind}
+}
+
+func (s levelFilterSink) WithName(name string) logr.LogSink {
+	return levelFilterSink{LogSink: s.LogSink.WithName(name), kind: s.kind}
+}
+
+func (s levelFilterSink) WithCallDepth(depth int) logr.LogSink {
+	if withCallDepth, ok := s.LogSink.(logr.CallDepthLogSink); ok {
+		return levelFilterSink{LogSink: withCallDepth.WithCallDepth(depth), kind: s.kind}
+	}
+	return s
+}
+
+type logLevelsResponse struct {
+	Default int            `json:"default"`
+	Kinds   map[string]int `json:"kinds"`
+}
+
+// LogLevelsHandler serves the log levels as JSON on GET. PUT sets the level given by the level query parameter for
+// the kind query parameter, or the default level without kind; level=default resets the level of the kind.
+func LogLevelsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			kind := r.URL.Query().Get("kind")
+			levelValue := r.URL.Query().Get("level")
+			switch {
+			case kind != "" && levelValue == "default":
+				ResetControllerLogLevel(kind)
+			default:
+				level, err := ParseLogLevel(levelValue)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				if kind == "" {
+					SetDefaultLogLevel(level)
+				} else {
+					SetControllerLogLevel(kind, level)
+				}
+				ctrl.Log.WithName("log-levels").Info("Log level changed", "kind", kind, "level", level)
+			}
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		defaultLogLevels.mu.RLock()
+		response := logLevelsResponse{Default: defaultLogLevels.defaultLevel, Kinds: make(map[string]int, len(defaultLogLevels.kinds))}
+		for kind, level := range defaultLogLevels.kinds {
+			response.Kinds[kind] = level
+		}
+		defaultLogLevels.mu.RUnlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	})
+}
@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+func TestParseControllerLogLevels(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    map[string]int
+		wantErr bool
+	}{
+		{value: "", want: map[string]int{}},
+		{value: "Index=debug, Dashboard=2,Space=error", want: map[string]int{"Index": LogLevelDebug, "Dashboard": 2, "Space": LogLevelError}},
+		{value: "Index=info,", want: map[string]int{"Index": LogLevelInfo}},
+		{value: "Index", wantErr: true},
+		{value: "=debug", wantErr: true},
+		{value: "Index=verbose", wantErr: true},
+		{value: "Index=-2", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseControllerLogLevels(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseControllerLogLevels(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseControllerLogLevels(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestConfigureLogLevels(t *testing.T) {
+	defer resetLogLevels()
+	opts := zap.Options{Development: true}
+	if err := ConfigureLogLevels(&opts, "Index=3"); err != nil {
+		t.Fatalf("ConfigureLogLevels() error = %v", err)
+	}
+	if level := defaultLogLevels.level("Dashboard"); level != LogLevelDebug {
+		t.Errorf("Expected the development default level debug, got %d", level)
+	}
+	if level := defaultLogLevels.level("Index"); level != 3 {
+		t.Errorf("Expected the level of Index to be 3, got %d", level)
+	}
+	if !opts.Level.Enabled(-3) || opts.Level.Enabled(-4) {
+		t.Error("Expected the zap level to follow the highest level in use")
+	}
+
+	ResetControllerLogLevel("Index")
+	if !opts.Level.Enabled(-1) || opts.Level.Enabled(-2) {
+		t.Error("Expected the zap level to drop to the default level")
+	}
+
+	if err := ConfigureLogLevels(&zap.Options{}, "Index"); err == nil {
+		t.Error("Expected an error for an invalid controller log level")
+	}
+}
+
+func TestReconcileLogger(t *testing.T) {
+	defer resetLogLevels()
+	var messages []string
+	sink := funcr.New(func(prefix, args string) {
+		messages = append(messages, args)
+	}, funcr.Options{Verbosity: 10})
+	root := log.IntoContext(context.Background(), FilterLogLevel(sink))
+	SetControllerLogLevel("Index", LogLevelDebug)
+
+	ctx, _ := ReconcileLogger(root, "Index", 2)
+	_, indexLogger := WithTargetInstance(ctx, "https://quickstart-es-http:9200")
+	indexLogger.V(1).Info("debug")
+	indexLogger.V(2).Info("trace")
+	_, dashboardLogger := ReconcileLogger(root, "Dashboard", 0)
+	dashboardLogger.V(1).Info("debug")
+	dashboardLogger.Info("info")
+
+	if len(messages) != 2 {
+		t.Fatalf("Expected the debug message of Index and the info message of Dashboard, got %v", messages)
+	}
+	for _, field := range []string{`"kind"="Index"`, `"attempt"=2`, `"targetInstance"="https://quickstart-es-http:9200"`} {
+		if !strings.Contains(messages[0], field) {
+			t.Errorf("Expected %s in %s", field, messages[0])
+		}
+	}
+	if !strings.Contains(messages[1], `"msg"="info"`) {
+		t.Errorf("Expected the info message of Dashboard, got %s", messages[1])
+	}
+}
+
+func TestLogLevelsHandler(t *testing.T) {
+	defer resetLogLevels()
+	handler := LogLevelsHandler()
+	serve := func(method string, target string) (*httptest.ResponseRecorder, logLevelsResponse) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+		var response logLevelsResponse
+		_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+		return recorder, response
+	}
+
+	if _, response := serve(http.MethodPut, "/log-levels?kind=Index&level=debug"); response.Kinds["Index"] != LogLevelDebug {
+		t.Errorf("Expected the level of Index to be set, got %+v", response)
+	}
+	if _, response := serve(http.MethodPut, "/log-levels?level=error"); response.Default != LogLevelError {
+		t.Errorf("Expected the default level to be set, got %+v", response)
+	}
+	if _, response := serve(http.MethodPut, "/log-levels?kind=Index&level=default"); len(response.Kinds) != 0 {
+		t.Errorf("Expected the level of Index to be reset, got %+v", response)
+	}
+	if recorder, _ := serve(http.MethodPut, "/log-levels?kind=Index&level=verbose"); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected an invalid level to be rejected, got %d", recorder.Code)
+	}
+	if recorder, _ := serve(http.MethodPost, "/log-levels"); recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected POST not to be allowed, got %d", recorder.Code)
+	}
+	if recorder, response := serve(http.MethodGet, "/log-levels"); recorder.Code != http.StatusOK || response.Default != LogLevelError {
+		t.Errorf("GET = %d %+v", recorder.Code, response)
+	}
+}
+
+func resetLogLevels() {
+	defaultLogLevels.mu.Lock()
+	defer defaultLogLevels.mu.Unlock()
+	defaultLogLevels.defaultLevel = LogLevelInfo
+	defaultLogLevels.kinds = make(map[string]int)
+	defaultLogLevels.zapLevel = nil
+}
@@ -0,0 +1,253 @@
+package utils
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ReconcileStalledAfter is how long a resource may go without converging before it is reported by the
+// eck_cr_reconcile_stalled metric
+var ReconcileStalledAfter = 15 * time.Minute
+
+var reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "eck_cr_reconcile_duration_seconds",
+	Help:    "Duration of the reconciles of the custom resources of a kind.",
+	Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+}, []string{"kind"})
+
+var (
+	queueDepthDesc = prometheus.NewDesc("eck_cr_reconcile_queue_depth",
+		"Number of custom resources of a kind waiting to be reconciled.", []string{"kind"}, nil)
+	cacheSyncedDesc = prometheus.NewDesc("eck_cr_cache_synced",
+		"Whether the informer cache of the custom resources of a kind has synced, 1 or 0.", []string{"kind"}, nil)
+	unconvergedAgeDesc = prometheus.NewDesc("eck_cr_unconverged_age_seconds",
+		"Time since the oldest custom resource of a kind that has not converged was last converged, 0 if all converged.", []string{"kind"}, nil)
+	stalledDesc = prometheus.NewDesc("eck_cr_reconcile_stalled",
+		"Set to 1 for each custom resource that has not converged for longer than the stalled period.", []string{"kind", "namespace", "name"}, nil)
+	degradedDesc = prometheus.NewDesc("eck_cr_degraded",
+		"Set to 1 for each custom resource whose reconciles failed persistently.", []string{"kind", "namespace", "name"}, nil)
+)
+
+// reconcileCollector collects the metrics of all ReconcileMetrics which are computed when scraped
+type reconcileCollector struct {
+	mu          sync.Mutex
+	kinds       map[string]*ReconcileMetrics
+	unconverged map[string]map[ctrl.Request]time.Time
+	degraded    map[string]map[ctrl.Request]bool
+	now         func() time.Time
+}
+
+var defaultReconcileCollector = newReconcileCollector(time.Now)
+
+func init() {
+	metrics.Registry.MustRegister(reconcileDuration, defaultReconcileCollector)
+}
+
+func newReconcileCollector(now func() time.Time) *reconcileCollector {
+	return &reconcileCollector{
+		kinds:       make(map[string]*ReconcileMetrics),
+		unconverged: make(map[string]map[ctrl.Request]time.Time),
+		degraded:    make(map[string]map[ctrl.Request]bool),
+		now:         now,
+	}
+}
+
+// observe records whether the resource converged, a resource converged when its reconcile neither failed nor
+// was requeued
+func (c *reconcileCollector) observe(kind string, req ctrl.Request, converged bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if converged {
+		delete(c.unconverged[kind], req)
+		return
+	}
+	if c.unconverged[kind] == nil {
+		c.unconverged[kind] = make(map[ctrl.Request]time.Time)
+	}
+	if _, ok := c.unconverged[kind][req]; !ok {
+		c.unconverged[kind][req] = c.now()
+	}
+}
+
+// observeDegraded records whether the resource is Degraded, see RetryBudget
+func (c *reconcileCollector) observeDegraded(kind string, req ctrl.Request, degraded bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !degraded {
+		delete(c.degraded[kind], req)
+		return
+	}
+	if c.degraded[kind] == nil {
+		c.degraded[kind] = make(map[ctrl.Request]bool)
+	}
+	c.degraded[kind][req] = true
+}
+
+func (c *reconcileCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueDepthDesc
+	ch <- cacheSyncedDesc
+	ch <- unconvergedAgeDesc
+	ch <- stalledDesc
+	ch <- degradedDesc
+}
+
+func (c *reconcileCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+
+	for kind, m := range c.kinds {
+		if m.queue != nil {
+			ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(m.queue.Len()), kind)
+		}
+		if m.cache != nil {
+			synced := 0.0
+			if m.cacheSynced() {
+				synced = 1
+			}
+			ch <- prometheus.MustNewConstMetric(cacheSyncedDesc, prometheus.GaugeValue, synced, kind)
+		}
+		if _, ok := c.unconverged[kind]; !ok {
+			ch <- prometheus.MustNewConstMetric(unconvergedAgeDesc, prometheus.GaugeValue, 0, kind)
+		}
+	}
+
+	for kind, requests := range c.unconverged {
+		oldest := time.Duration(0)
+		for req, since := range requests {
+			age := now.Sub(since)
+			if age > oldest {
+				oldest = age
+			}
+			if age > ReconcileStalledAfter {
+				ch <- prometheus.MustNewConstMetric(stalledDesc, prometheus.GaugeValue, 1, kind, req.Namespace, req.Name)
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(unconvergedAgeDesc, prometheus.GaugeValue, oldest.Seconds(), kind)
+	}
+
+	for kind, requests := range c.degraded {
+		for req := range requests {
+			ch <- prometheus.MustNewConstMetric(degradedDesc, prometheus.GaugeValue, 1, kind, req.Namespace, req.Name)
+		}
+	}
+}
+
+// ReconcileMetrics exposes the queue depth, cache sync state, reconcile duration and convergence of the
+// resources of one kind as Prometheus metrics labeled with the kind.
+type ReconcileMetrics struct {
+	kind      string
+	object    client.Object
+	cache     cache.Cache
+	queue     workqueue.TypedRateLimitingInterface[reconcile.Request]
+	collector *reconcileCollector
+}
+
+// NewReconcileMetrics creates the ReconcileMetrics for resources of the kind of object
+func NewReconcileMetrics(mgr ctrl.Manager, object client.Object) *ReconcileMetrics {
+	m := &ReconcileMetrics{
+		kind:      reflect.TypeOf(object).Elem().Name(),
+		object:    object,
+		cache:     mgr.GetCache(),
+		collector: defaultReconcileCollector,
+	}
+	m.collector.mu.Lock()
+	defer m.collector.mu.Unlock()
+	m.collector.kinds[m.kind] = m
+	return m
+}
+
+func (m *ReconcileMetrics) cacheSynced() bool {
+	informer, err := m.cache.GetInformer(context.Background(), m.object, cache.BlockUntilSynced(false))
+	return err == nil && informer.HasSynced()
+}
+
+// Options returns the controller options which make the work queue of the controller observable
+func (m *ReconcileMetrics) Options() controller.Options {
+	return controller.Options{
+		NewQueue: func(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+			queue := workqueue.NewTypedRateLimitingQueueWithConfig(rateLimiter, workqueue.TypedRateLimitingQueueConfig[reconcile.Request]{
+				Name: controllerName,
+			})
+			m.collector.mu.Lock()
+			defer m.collector.mu.Unlock()
+			m.queue = queue
+			return queue
+		},
+	}
+}
+
+type scheduledKey struct{}
+
+// RequeueScheduled returns the result requeueing the resource for its next scheduled run. Unlike other requeues,
+// it does not keep the resource from counting as converged.
+func RequeueScheduled(ctx context.Context, after time.Duration) ctrl.Result {
+	if scheduled, ok := ctx.Value(scheduledKey{}).(*bool); ok {
+		*scheduled = true
+	}
+	return ctrl.Result{RequeueAfter: after}
+}
+
+// Reconciler wraps the reconciler, recording the duration and outcome of every reconcile. Converged resources
+// which still exist are requeued for their resync, see ResyncAfter. The reconcile logs with the fields and at the
+// log level of the kind, see ReconcileLogger. Each reconcile is traced as a span, see SetupTracing. Reconciles in flight when the operator is stopped are drained, see
+// DrainContext.
+func (m *ReconcileMetrics) Reconciler(reconciler reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+		// Remote calls in flight when the operator is stopped are drained instead of cancelled
+		ctx, cancel := DrainContext(ctx)
+		defer cancel()
+		attempt := m.attempt(req)
+		ctx, _ = ReconcileLogger(ctx, m.kind, attempt)
+		ctx, span := StartSpan(ctx, "Reconcile "+m.kind,
+			attribute.String("k8s.namespace.name", req.Namespace),
+			attribute.String("eck.resource.name", req.Name),
+			attribute.Int("eck.reconcile.attempt", attempt),
+		)
+		start := time.Now()
+		scheduled := false
+		res, err := reconciler.Reconcile(context.WithValue(ctx, scheduledKey{}, &scheduled), req)
+		reconcileDuration.WithLabelValues(m.kind).Observe(time.Since(start).Seconds())
+		EndSpan(span, err)
+		m.collector.observe(m.kind, req, err == nil && (res.IsZero() || scheduled))
+		if err == nil && res.IsZero() {
+			res.RequeueAfter = m.resyncAfter(ctx, req)
+		}
+		return res, err
+	})
+}
+
+// attempt returns the number of the reconcile of the resource since it was last reconciled successfully
+func (m *ReconcileMetrics) attempt(req ctrl.Request) int {
+	m.collector.mu.Lock()
+	queue := m.queue
+	m.collector.mu.Unlock()
+	if queue == nil {
+		return 1
+	}
+	return queue.NumRequeues(req) + 1
+}
+
+// resyncAfter returns when the resource is resynced, 0 if the resync is disabled or the resource was deleted
+func (m *ReconcileMetrics) resyncAfter(ctx context.Context, req ctrl.Request) time.Duration {
+	after := ResyncAfter(m.kind + "/" + req.String())
+	if after == 0 || m.cache == nil {
+		return 0
+	}
+	if err := m.cache.Get(ctx, req.NamespacedName, m.object.DeepCopyObject().(client.Object)); err != nil {
+		return 0
+	}
+	return after
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// collectGauges returns the gauge values of the collector keyed by the metric name and its label values
+func collectGauges(t *testing.T, collector prometheus.Collector) map[string]float64 {
+	ch := make(chan prometheus.Metric, 100)
+	collector.Collect(ch)
+	close(ch)
+
+	names := map[*prometheus.Desc]string{
+		queueDepthDesc:     "eck_cr_reconcile_queue_depth",
+		cacheSyncedDesc:    "eck_cr_cache_synced",
+		unconvergedAgeDesc: "eck_cr_unconverged_age_seconds",
+		stalledDesc:        "eck_cr_reconcile_stalled",
+	}
+	gauges := map[string]float64{}
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		key := names[metric.Desc()]
+		for _, label := range m.GetLabel() {
+			key += "/" + label.GetValue()
+		}
+		gauges[key] = m.GetGauge().GetValue()
+	}
+	return gauges
+}
+
+func TestReconcileMetrics_Convergence(t *testing.T) {
+	now := time.Now()
+	collector := newReconcileCollector(func() time.Time { return now })
+	metrics := &ReconcileMetrics{kind: "Index", collector: collector}
+	collector.kinds["Index"] = metrics
+
+	var result ctrl.Result
+	var err error
+	reconciler := metrics.Reconciler(reconcile.Func(func(context.Context, ctrl.Request) (ctrl.Result, error) {
+		return result, err
+	}))
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "logs"}}
+
+	if gauges := collectGauges(t, collector); gauges["eck_cr_unconverged_age_seconds/Index"] != 0 {
+		t.Errorf("Expected no unconverged age before any reconcile, got %v", gauges)
+	}
+
+	result, err = GetRequeueResult(), errors.New("elasticsearch unavailable")
+	_, _ = reconciler.Reconcile(context.Background(), req)
+	now = now.Add(10 * time.Minute)
+	result, err = ctrl.Result{RequeueAfter: time.Second}, nil
+	_, _ = reconciler.Reconcile(context.Background(), req)
+
+	gauges := collectGauges(t, collector)
+	if gauges["eck_cr_unconverged_age_seconds/Index"] != (10 * time.Minute).Seconds() {
+		t.Errorf("Expected the age since the first failed reconcile, got %v", gauges)
+	}
+	if _, ok := gauges["eck_cr_reconcile_stalled/Index/logs/team-a"]; ok {
+		t.Errorf("Expected the resource not to be stalled yet, got %v", gauges)
+	}
+
+	now = now.Add(ReconcileStalledAfter)
+	gauges = collectGauges(t, collector)
+	if gauges["eck_cr_reconcile_stalled/Index/logs/team-a"] != 1 {
+		t.Errorf("Expected the resource to be stalled, got %v", gauges)
+	}
+
+	result, err = ctrl.Result{}, nil
+	_, _ = reconciler.Reconcile(context.Background(), req)
+	gauges = collectGauges(t, collector)
+	if _, ok := gauges["eck_cr_reconcile_stalled/Index/logs/team-a"]; ok {
+		t.Errorf("Expected the converged resource not to be stalled, got %v", gauges)
+	}
+	if gauges["eck_cr_unconverged_age_seconds/Index"] != 0 {
+		t.Errorf("Expected no unconverged age after converging, got %v", gauges)
+	}
+}
+
+func TestReconcileMetrics_Scheduled(t *testing.T) {
+	collector := newReconcileCollector(time.Now)
+	metrics := &ReconcileMetrics{kind: "ReportingJob", collector: collector}
+	collector.kinds["ReportingJob"] = metrics
+
+	reconciler := metrics.Reconciler(reconcile.Func(func(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+		return RequeueScheduled(ctx, time.Hour), nil
+	}))
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "weekly"}}
+
+	res, err := reconciler.Reconcile(context.Background(), req)
+	if err != nil || res.RequeueAfter != time.Hour {
+		t.Fatalf("Expected the scheduled requeue to be kept, got %v, %v", res, err)
+	}
+	if _, ok := collector.unconverged["ReportingJob"][req]; ok {
+		t.Error("Expected a resource requeued for its schedule to count as converged")
+	}
+}
+
+func TestReconcileMetrics_QueueDepth(t *testing.T) {
+	collector := newReconcileCollector(time.Now)
+	metrics := &ReconcileMetrics{kind: "Dashboard", collector: collector}
+	collector.kinds["Dashboard"] = metrics
+
+	if _, ok := collectGauges(t, collector)["eck_cr_reconcile_queue_depth/Dashboard"]; ok {
+		t.Error("Expected no queue depth before the controller started")
+	}
+
+	queue := metrics.Options().NewQueue("dashboard", workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+	queue.Add(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "a"}})
+	queue.Add(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "b"}})
+
+	if depth := collectGauges(t, collector)["eck_cr_reconcile_queue_depth/Dashboard"]; depth != 2 {
+		t.Errorf("Expected queue depth 2, got %v", depth)
+	}
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NamespaceCleanupInterval is how often the cleanup of a terminating namespace is checked, and resources waiting for
+// earlier cleanup stages are requeued
+var NamespaceCleanupInterval = 5 * time.Second
+
+// NamespaceCleanupStages orders the deletion of the resources of a terminating namespace: the resources of a stage are
+// only finalized once the resources of all earlier stages are gone, so that remote objects are deleted before the
+// objects they depend on, e.g. indices before their index lifecycle policies and dashboards before their
+// visualizations. Kinds without remote objects, like the instances, are not ordered.
+var NamespaceCleanupStages = [][]schema.GroupVersionKind{
+	{
+		eseckv1alpha1.GroupVersion.WithKind("ElasticsearchRawRequest"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("KibanaRawRequest"),
+		eseckv1alpha1.GroupVersion.WithKind("QueryRuleset"),
+		eseckv1alpha1.GroupVersion.WithKind("IndexOperation"),
+		eseckv1alpha1.GroupVersion.WithKind("SnapshotRestore"),
+		eseckv1alpha1.GroupVersion.WithKind("FollowerIndex"),
+		eseckv1alpha1.GroupVersion.WithKind("AutoFollowPattern"),
+		eseckv1alpha1.GroupVersion.WithKind("Index"),
+		eseckv1alpha1.GroupVersion.WithKind("ElasticsearchApikey"),
+		eseckv1alpha1.GroupVersion.WithKind("ElasticsearchUser"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("ReportingJob"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("Dashboard"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("CanvasWorkpad"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("PackagePolicy"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("MaintenanceWindow"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("AdvancedSettings"),
+	},
+	{
+		eseckv1alpha1.GroupVersion.WithKind("IndexTemplate"),
+		eseckv1alpha1.GroupVersion.WithKind("ElasticsearchRole"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("Visualization"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("Lens"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("SavedSearch"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("AgentPolicy"),
+	},
+	{
+		eseckv1alpha1.GroupVersion.WithKind("ComponentTemplate"),
+		eseckv1alpha1.GroupVersion.WithKind("IngestPipeline"),
+		eseckv1alpha1.GroupVersion.WithKind("IndexLifecyclePolicy"),
+		eseckv1alpha1.GroupVersion.WithKind("SnapshotLifecyclePolicy"),
+		eseckv1alpha1.GroupVersion.WithKind("SynonymSet"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("IndexPattern"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("DataView"),
+	},
+	{
+		eseckv1alpha1.GroupVersion.WithKind("SnapshotRepository"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("Space"),
+	},
+}
+
+// NamespaceCleanupStage returns the cleanup stage of the kind, false if its deletion is not ordered
+func NamespaceCleanupStage(kind schema.GroupKind) (int, bool) {
+	for stage, gvks := range NamespaceCleanupStages {
+		for _, gvk := range gvks {
+			if gvk.GroupKind() == kind {
+				return stage, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// NamespaceTerminating reports whether the namespace is being deleted. A namespace which does not exist is not
+// terminating.
+func NamespaceTerminating(ctx context.Context, cli client.Client, namespace string) (bool, error) {
+	var ns corev1.Namespace
+	if err := cli.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return !ns.DeletionTimestamp.IsZero(), nil
+}
+
+// NextNamespaceCleanupStage returns the first cleanup stage with resources left in the namespace and those resources,
+// no resources if the namespace is cleaned up
+func NextNamespaceCleanupStage(ctx context.Context, cli client.Client, namespace string) (int, []unstructured.Unstructured, error) {
+	for stage := range NamespaceCleanupStages {
+		resources, err := namespaceCleanupResources(ctx, cli, namespace, stage)
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(resources) > 0 {
+			return stage, resources, nil
+		}
+	}
+	return len(NamespaceCleanupStages), nil, nil
+}
+
+// PendingNamespaceCleanup returns the resources of the namespace in cleanup stages before the stage of kind which
+// still exist, nil if the kind is not ordered or the namespace is not terminating
+func PendingNamespaceCleanup(ctx context.Context, cli client.Client, namespace string, kind schema.GroupKind) ([]string, error) {
+	stage, ok := NamespaceCleanupStage(kind)
+	if !ok || stage == 0 {
+		return nil, nil
+	}
+	terminating, err := NamespaceTerminating(ctx, cli, namespace)
+	if err != nil || !terminating {
+		return nil, err
+	}
+	next, resources, err := NextNamespaceCleanupStage(ctx, cli, namespace)
+	if err != nil || next >= stage {
+		return nil, err
+	}
+	pending := make([]string, len(resources))
+	for i, resource := range resources {
+		pending[i] = fmt.Sprintf("%s %s", resource.GetKind(), resource.GetName())
+	}
+	sort.Strings(pending)
+	return pending, nil
+}
+
+// namespaceCleanupResources lists the resources of the kinds of the cleanup stage in the namespace, including those
+// being deleted
+func namespaceCleanupResources(ctx context.Context, cli client.Client, namespace string, stage int) ([]unstructured.Unstructured, error) {
+	var resources []unstructured.Unstructured
+	for _, gvk := range NamespaceCleanupStages[stage] {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := ListPaged(ctx, cli, list, client.InNamespace(namespace)); meta.IsNoMatchError(err) {
+			// The CRD of the kind is not installed
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			item.SetGroupVersionKind(gvk)
+			resources = append(resources, item)
+		}
+	}
+	return resources, nil
+}
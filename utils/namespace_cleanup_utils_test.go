@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestNamespaceCleanupStages(t *testing.T) {
+	stageOf := func(kind string) int {
+		t.Helper()
+		for _, group := range []string{eseckv1alpha1.GroupVersion.Group, kibanaeckv1alpha1.GroupVersion.Group} {
+			if stage, ok := NamespaceCleanupStage(schema.GroupKind{Group: group, Kind: kind}); ok {
+				return stage
+			}
+		}
+		t.Fatalf("Expected %s to have a cleanup stage", kind)
+		return 0
+	}
+	before := func(earlier string, later string) {
+		t.Helper()
+		if earlierStage, laterStage := stageOf(earlier), stageOf(later); earlierStage >= laterStage {
+			t.Errorf("Expected %s (stage %d) to be deleted before %s (stage %d)", earlier, earlierStage, later, laterStage)
+		}
+	}
+	before("Index", "IndexLifecyclePolicy")
+	before("Index", "IndexTemplate")
+	before("IndexTemplate", "ComponentTemplate")
+	before("ElasticsearchUser", "ElasticsearchRole")
+	before("SnapshotLifecyclePolicy", "SnapshotRepository")
+	before("Dashboard", "Visualization")
+	before("Visualization", "DataView")
+	before("PackagePolicy", "AgentPolicy")
+	before("DataView", "Space")
+
+	if _, ok := NamespaceCleanupStage(schema.GroupKind{Group: eseckv1alpha1.GroupVersion.Group, Kind: "ElasticsearchInstance"}); ok {
+		t.Error("Expected the deletion of instances not to be ordered")
+	}
+}
+
+func TestSyncWave_ReconcilerNamespaceCleanup(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	_ = kibanaeckv1alpha1.AddToScheme(scheme)
+
+	deleted := metav1.NewTime(time.Now())
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", DeletionTimestamp: &deleted, Finalizers: []string{"kubernetes"}}}
+	index := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "logs-000001", Namespace: "team-a",
+		DeletionTimestamp: &deleted, Finalizers: []string{"indices.es.eck.github.com/finalizer"}}}
+	policy := &eseckv1alpha1.IndexLifecyclePolicy{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "team-a",
+		DeletionTimestamp: &deleted, Finalizers: []string{"indexlifecyclepolicies.es.eck.github.com/finalizer"}}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespace, index, policy).Build()
+	ctx := context.Background()
+
+	stage, resources, err := NextNamespaceCleanupStage(ctx, fakeClient, "team-a")
+	if err != nil {
+		t.Fatalf("NextNamespaceCleanupStage() error = %v", err)
+	}
+	if stage != 0 || len(resources) != 1 || resources[0].GetName() != "logs-000001" {
+		t.Errorf("NextNamespaceCleanupStage() = %d, %v, want the index in stage 0", stage, resources)
+	}
+
+	reconciles := 0
+	recorder := record.NewFakeRecorder(10)
+	waves := &syncWaves{members: make(map[string]syncWaveMember), waiting: make(map[string]bool)}
+	policyWave := &SyncWave{object: &eseckv1alpha1.IndexLifecyclePolicy{}, gvk: eseckv1alpha1.GroupVersion.WithKind("IndexLifecyclePolicy"),
+		scheme: scheme, recorder: recorder, waves: waves}
+	reconciler := policyWave.Reconciler(fakeClient, reconcile.Func(func(context.Context, ctrl.Request) (ctrl.Result, error) {
+		reconciles++
+		return ctrl.Result{}, nil
+	}))
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(policy)}
+
+	res, err := reconciler.Reconcile(ctx, req)
+	if err != nil || res.RequeueAfter != NamespaceCleanupInterval || reconciles != 0 {
+		t.Fatalf("Expected the deletion to be held back while the index is left, got result=%v err=%v reconciles=%d", res, err, reconciles)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Expected a WaitingForNamespaceCleanup event, got %d events", len(recorder.Events))
+	}
+
+	index.Finalizers = nil
+	if err := fakeClient.Update(ctx, index); err != nil {
+		t.Fatalf("Failed to finalize the index: %v", err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err != nil || reconciles != 1 {
+		t.Errorf("Expected the deletion to proceed once the index is gone, got err=%v reconciles=%d", err, reconciles)
+	}
+
+	namespace.Finalizers = nil
+	if err := fakeClient.Update(ctx, namespace); err != nil {
+		t.Fatalf("Failed to remove the namespace: %v", err)
+	}
+	if pending, err := PendingNamespaceCleanup(ctx, fakeClient, "team-a", schema.GroupKind{Group: eseckv1alpha1.GroupVersion.Group, Kind: "SnapshotRepository"}); err != nil || pending != nil {
+		t.Errorf("Expected nothing to be pending outside of terminating namespaces, got %v, %v", pending, err)
+	}
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultElasticsearchInstanceAnnotation on a namespace names the ElasticsearchInstance used by the resources in
+	// the namespace which neither name nor select one in spec.targetInstance
+	DefaultElasticsearchInstanceAnnotation = "eck.github.com/default-elasticsearch-instance"
+	// DefaultKibanaInstanceAnnotation on a namespace names the KibanaInstance used by the resources in the namespace
+	// which neither name nor select one in spec.targetInstance
+	DefaultKibanaInstanceAnnotation = "eck.github.com/default-kibana-instance"
+)
+
+// GetNamespaceDefault returns the value of the annotation of the namespace, or an empty string if the namespace is
+// not annotated or does not exist
+func GetNamespaceDefault(cli client.Client, ctx context.Context, namespace string, annotation string) (string, error) {
+	var ns corev1.Namespace
+	if err := cli.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return ns.Annotations[annotation], nil
+}
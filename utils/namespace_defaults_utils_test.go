@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetNamespaceDefault(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "team-a",
+		Annotations: map[string]string{DefaultElasticsearchInstanceAnnotation: "logs"},
+	}}).Build()
+
+	tests := []struct {
+		namespace  string
+		annotation string
+		want       string
+	}{
+		{namespace: "team-a", annotation: DefaultElasticsearchInstanceAnnotation, want: "logs"},
+		{namespace: "team-a", annotation: DefaultKibanaInstanceAnnotation, want: ""},
+		{namespace: "team-b", annotation: DefaultElasticsearchInstanceAnnotation, want: ""},
+	}
+	for _, tt := range tests {
+		got, err := GetNamespaceDefault(cli, context.Background(), tt.namespace, tt.annotation)
+		if err != nil {
+			t.Fatalf("GetNamespaceDefault() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("GetNamespaceDefault(%s, %s) = %q, want %q", tt.namespace, tt.annotation, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	configv2 "eck-custom-resources/api/config/v2"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NamingPolicyKinds are the kinds of resources a NamingPolicy applies to
+var NamingPolicyKinds = []string{"IngestPipeline", "IndexTemplate", "ComponentTemplate", "Index", "ElasticsearchRole"}
+
+// namingPolicy is the naming convention of the objects created in Elasticsearch, set from the ProjectConfig
+var namingPolicy atomic.Pointer[configv2.NamingPolicy]
+
+// SetNamingPolicy sets the naming convention applied by RemoteName
+func SetNamingPolicy(policy *configv2.NamingPolicy) {
+	namingPolicy.Store(policy)
+}
+
+// ValidateNamingPolicy returns an error if the pattern does not contain the name of the resource or a kind is not
+// supported
+func ValidateNamingPolicy(policy *configv2.NamingPolicy) error {
+	if policy == nil || policy.Pattern == "" {
+		return nil
+	}
+	if !strings.Contains(policy.Pattern, "{name}") {
+		return errors.New("naming.pattern has to contain {name}")
+	}
+	for _, kind := range policy.Kinds {
+		if !slices.Contains(NamingPolicyKinds, kind) {
+			return fmt.Errorf("naming.kinds: unsupported kind %q, expected one of %s", kind, strings.Join(NamingPolicyKinds, ", "))
+		}
+	}
+	return nil
+}
+
+// RemoteName returns the name of the object the resource manages in Elasticsearch, according to the naming policy
+func RemoteName(obj client.Object) string {
+	policy := namingPolicy.Load()
+	if !namingPolicyApplies(policy, obj) {
+		return obj.GetName()
+	}
+	return strings.NewReplacer("{namespace}", obj.GetNamespace(), "{name}", obj.GetName()).Replace(policy.Pattern)
+}
+
+// LegacyName returns the name the resource managed its object under before the naming policy applied, if it is
+// different from the RemoteName and the naming policy migrates such objects
+func LegacyName(obj client.Object) (string, bool) {
+	if policy := namingPolicy.Load(); policy == nil || !policy.Migrate {
+		return "", false
+	}
+	if RemoteName(obj) == obj.GetName() {
+		return "", false
+	}
+	return obj.GetName(), true
+}
+
+func namingPolicyApplies(policy *configv2.NamingPolicy, obj client.Object) bool {
+	if policy == nil || policy.Pattern == "" {
+		return false
+	}
+	if slices.Contains(policy.ExcludedNamespaces, obj.GetNamespace()) {
+		return false
+	}
+	kinds := policy.Kinds
+	if len(kinds) == 0 {
+		kinds = NamingPolicyKinds
+	}
+	// The kind is taken from the type, TypeMeta is not set on objects read from the cache
+	return slices.Contains(kinds, reflect.Indirect(reflect.ValueOf(obj)).Type().Name())
+}
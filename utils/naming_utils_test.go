@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"testing"
+
+	configv2 "eck-custom-resources/api/config/v2"
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestRemoteName(t *testing.T) {
+	defer SetNamingPolicy(nil)
+	obj := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "logs"}}
+
+	tests := []struct {
+		name       string
+		policy     *configv2.NamingPolicy
+		want       string
+		wantLegacy bool
+	}{
+		{name: "no policy", want: "logs"},
+		{name: "no pattern", policy: &configv2.NamingPolicy{Migrate: true}, want: "logs"},
+		{name: "pattern", policy: &configv2.NamingPolicy{Pattern: "{namespace}-{name}"}, want: "team-a-logs"},
+		{name: "migrate", policy: &configv2.NamingPolicy{Pattern: "{namespace}-{name}", Migrate: true}, want: "team-a-logs", wantLegacy: true},
+		{name: "kind selected", policy: &configv2.NamingPolicy{Pattern: "{namespace}-{name}", Kinds: []string{"Index"}}, want: "team-a-logs"},
+		{name: "kind not selected", policy: &configv2.NamingPolicy{Pattern: "{namespace}-{name}", Kinds: []string{"IngestPipeline"}, Migrate: true}, want: "logs"},
+		{name: "excluded namespace", policy: &configv2.NamingPolicy{Pattern: "{namespace}-{name}", ExcludedNamespaces: []string{"team-a"}, Migrate: true}, want: "logs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetNamingPolicy(tt.policy)
+			if got := RemoteName(obj); got != tt.want {
+				t.Errorf("RemoteName() = %s, want %s", got, tt.want)
+			}
+			legacyName, ok := LegacyName(obj)
+			if ok != tt.wantLegacy || (ok && legacyName != "logs") {
+				t.Errorf("LegacyName() = %s, %v, want the name of the resource: %v", legacyName, ok, tt.wantLegacy)
+			}
+		})
+	}
+}
+
+func TestRemoteName_DefaultKinds(t *testing.T) {
+	defer SetNamingPolicy(nil)
+	SetNamingPolicy(&configv2.NamingPolicy{Pattern: "{namespace}-{name}"})
+
+	tests := []struct {
+		name string
+		obj  client.Object
+		want string
+	}{
+		{name: "supported kind", obj: &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "logs"}}, want: "team-a-logs"},
+		{name: "unsupported kind", obj: &eseckv1alpha1.SynonymSet{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "logs"}}, want: "logs"},
+		{name: "other resource", obj: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "logs"}}, want: "logs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RemoteName(tt.obj); got != tt.want {
+				t.Errorf("RemoteName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateNamingPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *configv2.NamingPolicy
+		wantErr bool
+	}{
+		{name: "no policy"},
+		{name: "no pattern", policy: &configv2.NamingPolicy{Kinds: []string{"Foo"}}},
+		{name: "valid", policy: &configv2.NamingPolicy{Pattern: "{namespace}-{name}", Kinds: []string{"Index", "IngestPipeline"}}},
+		{name: "pattern without name", policy: &configv2.NamingPolicy{Pattern: "{namespace}"}, wantErr: true},
+		{name: "unsupported kind", policy: &configv2.NamingPolicy{Pattern: "{namespace}-{name}", Kinds: []string{"Dashboard"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateNamingPolicy(tt.policy); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateNamingPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
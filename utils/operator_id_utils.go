@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// OperatorIDLabel is set to the ID of the operator installation which reconciles a custom resource, and on the
+// Secrets it creates for them
+const OperatorIDLabel = "eck.github.com/operator-id"
+
+// OperatorConflict condition, set while the reconcile of a resource would write an object which belongs to another
+// operator installation
+const (
+	OperatorConflictConditionType = "OperatorConflict"
+	OperatorConflictReason        = "OperatorConflict"
+)
+
+// OperatorID identifies the operator installation, see --operator-id. An installation with an ID reconciles the
+// resources labeled with it and claims unlabeled ones, an installation without ID only reconciles unlabeled
+// resources.
+var OperatorID string
+
+// ValidateOperatorID returns an error unless id can be used as label value and as part of the leader election lease
+func ValidateOperatorID(id string) error {
+	if id == "" {
+		return nil
+	}
+	if errs := validation.IsDNS1123Label(id); len(errs) > 0 {
+		return fmt.Errorf("invalid operator ID %q: %s", id, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// LeaderElectionID returns the leader election lease of the operator installation. The replicas of an installation
+// share the lease, installations with different IDs elect their leaders independently.
+func LeaderElectionID(base string) string {
+	if OperatorID == "" {
+		return base
+	}
+	return OperatorID + "." + base
+}
+
+// OwnedBy returns the ID of the operator installation obj is labeled with, empty if it is unlabeled
+func OwnedBy(obj metav1.Object) string {
+	return obj.GetLabels()[OperatorIDLabel]
+}
+
+// ReconciledByOperator reports whether this operator installation reconciles obj: it is labeled with OperatorID, or
+// unlabeled
+func ReconciledByOperator(obj metav1.Object) bool {
+	owner := OwnedBy(obj)
+	return owner == "" || owner == OperatorID
+}
+
+// OperatorLabels returns the labels which mark objects as created by this operator installation, none without
+// OperatorID
+func OperatorLabels() map[string]string {
+	if OperatorID == "" {
+		return nil
+	}
+	return map[string]string{OperatorIDLabel: OperatorID}
+}
+
+// OperatorConflictError reports an object which belongs to another operator installation
+type OperatorConflictError struct {
+	// Object describes the object, e.g. Secret default/logs-apikey
+	Object string
+	Owner  string
+}
+
+func (e *OperatorConflictError) Error() string {
+	return fmt.Sprintf("%s belongs to the operator %q", e.Object, e.Owner)
+}
+
+// CheckOperatorOwnership returns an OperatorConflictError if obj is labeled for another operator installation.
+// Unlabeled objects, e.g. created by earlier versions of the operator, belong to any installation.
+func CheckOperatorOwnership(kind string, obj metav1.Object) error {
+	if owner := OwnedBy(obj); owner != "" && owner != OperatorID {
+		return &OperatorConflictError{Object: fmt.Sprintf("%s %s/%s", kind, obj.GetNamespace(), obj.GetName()), Owner: owner}
+	}
+	return nil
+}
+
+// OperatorOwnership restricts the reconciles of the resources of a kind to the ones owned by this operator
+// installation, so that two installations, e.g. during a migration, never reconcile the same resources
+type OperatorOwnership struct {
+	object   client.Object
+	reader   client.Reader
+	recorder record.EventRecorder
+}
+
+// NewOperatorOwnership creates an OperatorOwnership for resources of the kind of object
+func NewOperatorOwnership(mgr ctrl.Manager, object client.Object, recorder record.EventRecorder) *OperatorOwnership {
+	return &OperatorOwnership{object: object, reader: mgr.GetAPIReader(), recorder: recorder}
+}
+
+// Reconciler wraps the reconciler, skipping the resources labeled for other operator installations. An installation
+// with an ID claims unlabeled resources before their first reconcile; the claim fails if another installation claimed
+// the resource in the meantime. An OperatorConflictError of the reconcile is reported in the OperatorConflict
+// condition and retried at the resync only, as retrying does not help until the ownership is resolved.
+func (o *OperatorOwnership) Reconciler(cli client.Client, reconciler reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+		obj := o.object.DeepCopyObject().(client.Object)
+		if err := cli.Get(ctx, req.NamespacedName, obj); err != nil {
+			return reconciler.Reconcile(ctx, req)
+		}
+
+		if !ReconciledByOperator(obj) {
+			log.FromContext(ctx).V(1).Info("Resource belongs to another operator, skipping reconcile", "operatorID", OwnedBy(obj))
+			return ctrl.Result{}, nil
+		}
+		if OwnedBy(obj) == "" && OperatorID != "" {
+			if err := o.claim(ctx, cli, obj); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		res, reconcileErr := reconciler.Reconcile(ctx, req)
+		var conflict *OperatorConflictError
+		if reconcileErr != nil && !errors.As(reconcileErr, &conflict) {
+			return res, reconcileErr
+		}
+		message := ""
+		if conflict != nil {
+			message = reconcileErr.Error()
+		}
+		if err := o.setConflictCondition(ctx, cli, req, message); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to update the OperatorConflict condition")
+		}
+		if conflict == nil {
+			return res, nil
+		}
+		log.FromContext(ctx).Info("Resource conflicts with another operator, retrying at the resync", "error", message)
+		return ctrl.Result{RequeueAfter: ResyncAfter(req.String())}, nil
+	})
+}
+
+// claim labels obj for this operator installation. The patch fails if obj changed since it was read, e.g. because
+// another installation claimed it.
+func (o *OperatorOwnership) claim(ctx context.Context, cli client.Client, obj client.Object) error {
+	patch := client.MergeFromWithOptions(obj.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string, 1)
+	}
+	labels[OperatorIDLabel] = OperatorID
+	obj.SetLabels(labels)
+	if err := cli.Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("failed to claim the resource for the operator %q: %w", OperatorID, err)
+	}
+	log.FromContext(ctx).Info("Claimed resource", "operatorID", OperatorID)
+	return nil
+}
+
+// setConflictCondition sets the OperatorConflict condition of the resource to message, or removes it if message is
+// empty. An event is recorded when the conflict is first reported.
+func (o *OperatorOwnership) setConflictCondition(ctx context.Context, cli client.Client, req ctrl.Request, message string) error {
+	obj := o.object.DeepCopyObject().(client.Object)
+	if err := cli.Get(ctx, req.NamespacedName, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if _, changed, err := conflictConditions(obj, message); err != nil || !changed {
+		return err
+	}
+	// The reconcile usually just updated the status, which the cache may not have seen yet
+	if err := o.reader.Get(ctx, req.NamespacedName, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	content, changed, err := conflictConditions(obj, message)
+	if err != nil || !changed {
+		return err
+	}
+	if message != "" {
+		o.recorder.Event(obj, "Warning", OperatorConflictReason, message)
+	}
+	patch := client.MergeFromWithOptions(obj.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, obj); err != nil {
+		return err
+	}
+	return cli.Status().Patch(ctx, obj, patch)
+}
+
+// conflictConditions returns the unstructured content of obj with the OperatorConflict condition set to message, or
+// removed if message is empty, and whether the condition changed
+func conflictConditions(obj client.Object, message string) (map[string]interface{}, bool, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, false, err
+	}
+	conditions := conditionsOf(content)
+	previous := meta.FindStatusCondition(conditions, OperatorConflictConditionType)
+	if message == "" {
+		if previous == nil {
+			return content, false, nil
+		}
+		meta.RemoveStatusCondition(&conditions, OperatorConflictConditionType)
+	} else {
+		if previous != nil && previous.Message == message {
+			return content, false, nil
+		}
+		meta.SetStatusCondition(&conditions, metav1.Condition{
+			Type:               OperatorConflictConditionType,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: obj.GetGeneration(),
+			Reason:             OperatorConflictReason,
+			Message:            message,
+		})
+	}
+	return content, true, setConditionsOf(content, conditions)
+}
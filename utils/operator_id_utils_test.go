@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestOperatorOwnership_Reconciler(t *testing.T) {
+	defer func(id string) { OperatorID = id }(OperatorID)
+
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+
+	unlabeled := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "default"}}
+	foreign := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "metrics", Namespace: "default",
+		Labels: map[string]string{OperatorIDLabel: "green"}}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(unlabeled, foreign).
+		WithStatusSubresource(unlabeled, foreign).Build()
+	recorder := record.NewFakeRecorder(10)
+	ownership := &OperatorOwnership{object: &eseckv1alpha1.Index{}, reader: fakeClient, recorder: recorder}
+
+	reconciled := map[string]int{}
+	var reconcileErr error
+	reconciler := ownership.Reconciler(fakeClient, reconcile.Func(func(_ context.Context, req ctrl.Request) (ctrl.Result, error) {
+		reconciled[req.Name]++
+		return ctrl.Result{}, reconcileErr
+	}))
+	ctx := context.Background()
+	reconcileIndex := func(index *eseckv1alpha1.Index) (ctrl.Result, error) {
+		return reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(index)})
+	}
+
+	// Without ID only unlabeled resources are reconciled, and they are not claimed
+	OperatorID = ""
+	if _, err := reconcileIndex(unlabeled); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if _, err := reconcileIndex(foreign); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if reconciled["logs"] != 1 || reconciled["metrics"] != 0 {
+		t.Errorf("Expected only the unlabeled index to be reconciled, got %v", reconciled)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(unlabeled), unlabeled); err != nil {
+		t.Fatal(err)
+	}
+	if OwnedBy(unlabeled) != "" {
+		t.Errorf("Expected the index not to be claimed without ID, got %q", OwnedBy(unlabeled))
+	}
+
+	// With an ID unlabeled resources are claimed
+	OperatorID = "blue"
+	if _, err := reconcileIndex(unlabeled); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if _, err := reconcileIndex(foreign); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if reconciled["logs"] != 2 || reconciled["metrics"] != 0 {
+		t.Errorf("Expected the index of the other operator to be skipped, got %v", reconciled)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(unlabeled), unlabeled); err != nil {
+		t.Fatal(err)
+	}
+	if OwnedBy(unlabeled) != "blue" {
+		t.Errorf("Expected the index to be claimed, got %q", OwnedBy(unlabeled))
+	}
+
+	// Conflicts are reported in the condition instead of being retried
+	reconcileErr = fmt.Errorf("failed to generate Secret: %w", &OperatorConflictError{Object: "Secret default/logs", Owner: "green"})
+	res, err := reconcileIndex(unlabeled)
+	if err != nil {
+		t.Fatalf("Expected the conflict not to be returned as error, got %v", err)
+	}
+	if res.Requeue {
+		t.Errorf("Expected no immediate requeue, got %v", res)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(unlabeled), unlabeled); err != nil {
+		t.Fatal(err)
+	}
+	conflict := meta.FindStatusCondition(unlabeled.Status.Conditions, OperatorConflictConditionType)
+	if conflict == nil || conflict.Status != metav1.ConditionTrue ||
+		conflict.Message != `failed to generate Secret: Secret default/logs belongs to the operator "green"` {
+		t.Errorf("Expected the OperatorConflict condition, got %v", conflict)
+	}
+	if _, err := reconcileIndex(unlabeled); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Expected a single event for the conflict, got %d", len(recorder.Events))
+	}
+
+	reconcileErr = nil
+	if _, err := reconcileIndex(unlabeled); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(unlabeled), unlabeled); err != nil {
+		t.Fatal(err)
+	}
+	if meta.FindStatusCondition(unlabeled.Status.Conditions, OperatorConflictConditionType) != nil {
+		t.Errorf("Expected the condition to be removed after a successful reconcile, got %v", unlabeled.Status.Conditions)
+	}
+}
+
+func TestValidateOperatorID(t *testing.T) {
+	for id, wantErr := range map[string]bool{"": false, "blue": false, "team-a-2": false, "Blue": true, "a.b": true} {
+		if err := ValidateOperatorID(id); (err != nil) != wantErr {
+			t.Errorf("ValidateOperatorID(%q) error = %v, wantErr %v", id, err, wantErr)
+		}
+	}
+}
+
+func TestLeaderElectionID(t *testing.T) {
+	defer func(id string) { OperatorID = id }(OperatorID)
+
+	OperatorID = ""
+	if got := LeaderElectionID("5da2fcc2.github.com"); got != "5da2fcc2.github.com" {
+		t.Errorf("LeaderElectionID() = %q without ID", got)
+	}
+	OperatorID = "blue"
+	if got := LeaderElectionID("5da2fcc2.github.com"); got != "blue.5da2fcc2.github.com" {
+		t.Errorf("LeaderElectionID() = %q, want blue.5da2fcc2.github.com", got)
+	}
+}
@@ -0,0 +1,132 @@
+package overlay
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SupportedKinds are the kinds whose bodies are patched by EnvironmentOverlays
+var SupportedKinds = []schema.GroupVersionKind{
+	eseckv1alpha1.GroupVersion.WithKind("ComponentTemplate"),
+	eseckv1alpha1.GroupVersion.WithKind("ElasticsearchRole"),
+	eseckv1alpha1.GroupVersion.WithKind("Index"),
+	eseckv1alpha1.GroupVersion.WithKind("IndexLifecyclePolicy"),
+	eseckv1alpha1.GroupVersion.WithKind("IndexTemplate"),
+	eseckv1alpha1.GroupVersion.WithKind("IngestPipeline"),
+	eseckv1alpha1.GroupVersion.WithKind("QueryRuleset"),
+	eseckv1alpha1.GroupVersion.WithKind("SnapshotLifecyclePolicy"),
+	eseckv1alpha1.GroupVersion.WithKind("SnapshotRepository"),
+	eseckv1alpha1.GroupVersion.WithKind("SynonymSet"),
+	kibanaeckv1alpha1.GroupVersion.WithKind("AdvancedSettings"),
+	kibanaeckv1alpha1.GroupVersion.WithKind("AgentPolicy"),
+	kibanaeckv1alpha1.GroupVersion.WithKind("CanvasWorkpad"),
+	kibanaeckv1alpha1.GroupVersion.WithKind("Dashboard"),
+	kibanaeckv1alpha1.GroupVersion.WithKind("DataView"),
+	kibanaeckv1alpha1.GroupVersion.WithKind("IndexPattern"),
+	kibanaeckv1alpha1.GroupVersion.WithKind("Lens"),
+	kibanaeckv1alpha1.GroupVersion.WithKind("MaintenanceWindow"),
+	kibanaeckv1alpha1.GroupVersion.WithKind("PackagePolicy"),
+	kibanaeckv1alpha1.GroupVersion.WithKind("SavedSearch"),
+	kibanaeckv1alpha1.GroupVersion.WithKind("Space"),
+	kibanaeckv1alpha1.GroupVersion.WithKind("Visualization"),
+}
+
+// Apply patches the body of obj with all EnvironmentOverlays in the namespace of obj selecting it, in the order of
+// their names. The body stored in the resource is left untouched, only the body sent to the target is patched.
+func Apply(cli client.Client, ctx context.Context, obj client.Object, body string) (string, error) {
+	var overlays eseckv1alpha1.EnvironmentOverlayList
+	if err := cli.List(ctx, &overlays, client.InNamespace(obj.GetNamespace())); err != nil {
+		return "", err
+	}
+	sort.Slice(overlays.Items, func(i, j int) bool { return overlays.Items[i].Name < overlays.Items[j].Name })
+
+	kind := reflect.TypeOf(obj).Elem().Name()
+	for _, environmentOverlay := range overlays.Items {
+		if !environmentOverlay.DeletionTimestamp.IsZero() {
+			continue
+		}
+		selected, err := Selects(environmentOverlay, kind, obj.GetLabels())
+		if err != nil {
+			return "", fmt.Errorf("EnvironmentOverlay %s: %w", environmentOverlay.Name, err)
+		}
+		if !selected {
+			continue
+		}
+		if body, err = Patch(environmentOverlay.Spec, body); err != nil {
+			return "", fmt.Errorf("EnvironmentOverlay %s: %w", environmentOverlay.Name, err)
+		}
+	}
+	return body, nil
+}
+
+// Selects returns whether the overlay applies to resources of the kind with the labels
+func Selects(environmentOverlay eseckv1alpha1.EnvironmentOverlay, kind string, labels map[string]string) (bool, error) {
+	if len(environmentOverlay.Spec.Kinds) > 0 && !slices.Contains(environmentOverlay.Spec.Kinds, kind) {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&environmentOverlay.Spec.Selector)
+	if err != nil {
+		return false, fmt.Errorf("invalid spec.selector: %w", err)
+	}
+	return selector.Matches(k8slabels.Set(labels)), nil
+}
+
+// Patch applies the merge patch and then the JSON patch of the overlay to body, an empty body is patched as an
+// empty object
+func Patch(spec eseckv1alpha1.EnvironmentOverlaySpec, body string) (string, error) {
+	patched := []byte(body)
+	if body == "" {
+		patched = []byte("{}")
+	}
+	if spec.MergePatch != "" {
+		var err error
+		if patched, err = jsonpatch.MergePatch(patched, []byte(spec.MergePatch)); err != nil {
+			return "", fmt.Errorf("failed to apply spec.mergePatch: %w", err)
+		}
+	}
+	if spec.JSONPatch != "" {
+		patch, err := jsonpatch.DecodePatch([]byte(spec.JSONPatch))
+		if err != nil {
+			return "", fmt.Errorf("invalid spec.jsonPatch: %w", err)
+		}
+		if patched, err = patch.Apply(patched); err != nil {
+			return "", fmt.Errorf("failed to apply spec.jsonPatch: %w", err)
+		}
+	}
+	return string(patched), nil
+}
+
+// Validate checks that the selector and patches of the overlay can be parsed
+func Validate(spec eseckv1alpha1.EnvironmentOverlaySpec) error {
+	if _, err := metav1.LabelSelectorAsSelector(&spec.Selector); err != nil {
+		return fmt.Errorf("invalid spec.selector: %w", err)
+	}
+	if spec.MergePatch != "" {
+		if _, err := jsonpatch.MergePatch([]byte("{}"), []byte(spec.MergePatch)); err != nil {
+			return fmt.Errorf("invalid spec.mergePatch: %w", err)
+		}
+	}
+	if spec.JSONPatch != "" {
+		if _, err := jsonpatch.DecodePatch([]byte(spec.JSONPatch)); err != nil {
+			return fmt.Errorf("invalid spec.jsonPatch: %w", err)
+		}
+	}
+	for _, kind := range spec.Kinds {
+		if !slices.ContainsFunc(SupportedKinds, func(gvk schema.GroupVersionKind) bool { return gvk.Kind == kind }) {
+			return fmt.Errorf("kind %s in spec.kinds is not supported", kind)
+		}
+	}
+	return nil
+}
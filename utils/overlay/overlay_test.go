@@ -0,0 +1,162 @@
+package overlay
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newOverlay(name string, spec eseckv1alpha1.EnvironmentOverlaySpec) *eseckv1alpha1.EnvironmentOverlay {
+	return &eseckv1alpha1.EnvironmentOverlay{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       spec,
+	}
+}
+
+func assertJSONEqual(t *testing.T, got string, want string) {
+	t.Helper()
+	var gotValue, wantValue any
+	if err := json.Unmarshal([]byte(got), &gotValue); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("invalid JSON %s: %v", want, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    eseckv1alpha1.EnvironmentOverlaySpec
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "merge patch",
+			spec: eseckv1alpha1.EnvironmentOverlaySpec{MergePatch: `{"settings": {"number_of_replicas": 0, "refresh_interval": null}}`},
+			body: `{"settings": {"number_of_shards": 3, "number_of_replicas": 2, "refresh_interval": "5s"}}`,
+			want: `{"settings": {"number_of_shards": 3, "number_of_replicas": 0}}`,
+		},
+		{
+			name: "JSON patch after merge patch",
+			spec: eseckv1alpha1.EnvironmentOverlaySpec{
+				MergePatch: `{"settings": {"number_of_replicas": 0}}`,
+				JSONPatch:  `[{"op": "replace", "path": "/settings/number_of_replicas", "value": 1}]`,
+			},
+			body: `{"settings": {"number_of_replicas": 2}}`,
+			want: `{"settings": {"number_of_replicas": 1}}`,
+		},
+		{
+			name: "empty body",
+			spec: eseckv1alpha1.EnvironmentOverlaySpec{MergePatch: `{"color": "#00bfb3"}`},
+			body: "",
+			want: `{"color": "#00bfb3"}`,
+		},
+		{
+			name:    "JSON patch of a missing path",
+			spec:    eseckv1alpha1.EnvironmentOverlaySpec{JSONPatch: `[{"op": "replace", "path": "/template/settings/number_of_shards", "value": 1}]`},
+			body:    `{"settings": {}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Patch(tt.spec, tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Patch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				assertJSONEqual(t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    eseckv1alpha1.EnvironmentOverlaySpec
+		wantErr bool
+	}{
+		{name: "valid", spec: eseckv1alpha1.EnvironmentOverlaySpec{Kinds: []string{"Index", "Dashboard"}, MergePatch: `{}`, JSONPatch: `[]`}},
+		{name: "invalid merge patch", spec: eseckv1alpha1.EnvironmentOverlaySpec{MergePatch: `{`}, wantErr: true},
+		{name: "invalid JSON patch", spec: eseckv1alpha1.EnvironmentOverlaySpec{JSONPatch: `{"op": "add"}`}, wantErr: true},
+		{name: "unsupported kind", spec: eseckv1alpha1.EnvironmentOverlaySpec{Kinds: []string{"ElasticsearchUser"}}, wantErr: true},
+		{
+			name: "invalid selector",
+			spec: eseckv1alpha1.EnvironmentOverlaySpec{Selector: metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "env", Operator: "Near"}},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.spec); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	_ = kibanaeckv1alpha1.AddToScheme(scheme)
+
+	selector := metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/part-of": "logging"}}
+	objects := []client.Object{
+		newOverlay("20-replicas", eseckv1alpha1.EnvironmentOverlaySpec{
+			Selector:   selector,
+			Kinds:      []string{"Index"},
+			MergePatch: `{"settings": {"number_of_replicas": 1}}`,
+		}),
+		newOverlay("10-replicas", eseckv1alpha1.EnvironmentOverlaySpec{
+			Selector:   selector,
+			MergePatch: `{"settings": {"number_of_replicas": 0, "number_of_shards": 1}}`,
+		}),
+		newOverlay("30-templates", eseckv1alpha1.EnvironmentOverlaySpec{
+			Selector:  selector,
+			Kinds:     []string{"IndexTemplate"},
+			JSONPatch: `[{"op": "add", "path": "/template", "value": {}}]`,
+		}),
+		&eseckv1alpha1.EnvironmentOverlay{
+			ObjectMeta: metav1.ObjectMeta{Name: "40-other-namespace", Namespace: "other"},
+			Spec:       eseckv1alpha1.EnvironmentOverlaySpec{MergePatch: `{"settings": null}`},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+
+	index := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{
+		Name: "logs", Namespace: "default", Labels: map[string]string{"app.kubernetes.io/part-of": "logging"},
+	}}
+	body, err := Apply(fakeClient, context.Background(), index, `{"settings": {"number_of_replicas": 2, "number_of_shards": 3}}`)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	assertJSONEqual(t, body, `{"settings": {"number_of_replicas": 1, "number_of_shards": 1}}`)
+
+	unlabeled := &kibanaeckv1alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "overview", Namespace: "default"}}
+	body, err = Apply(fakeClient, context.Background(), unlabeled, `{"title": "Overview"}`)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if body != `{"title": "Overview"}` {
+		t.Errorf("Expected the body of an unselected resource to be unchanged, got %s", body)
+	}
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// EnsureControllerReference makes owner the controller of obj and adds the labels to it, so that obj is garbage
+// collected with owner. obj is only patched if anything changed, the returned bool reports whether it was.
+func EnsureControllerReference(cli client.Client, ctx context.Context, owner client.Object, obj client.Object, labels map[string]string) (bool, error) {
+	original := obj.DeepCopyObject().(client.Object)
+
+	changed := false
+	if controller := metav1.GetControllerOf(obj); controller == nil || controller.UID != owner.GetUID() {
+		if err := controllerutil.SetControllerReference(owner, obj, cli.Scheme()); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	objLabels := obj.GetLabels()
+	for key, value := range labels {
+		if objLabels[key] != value {
+			if objLabels == nil {
+				objLabels = make(map[string]string, len(labels))
+			}
+			objLabels[key] = value
+			changed = true
+		}
+	}
+	obj.SetLabels(objLabels)
+
+	if !changed {
+		return false, nil
+	}
+	return true, cli.Patch(ctx, obj, client.MergeFrom(original))
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEnsureControllerReference(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	_ = k8sv1.AddToScheme(scheme)
+
+	owner := &eseckv1alpha1.ElasticsearchApikey{ObjectMeta: metav1.ObjectMeta{Name: "ingest", Namespace: "default", UID: "ingest-uid"}}
+	secret := &k8sv1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "ingest", Namespace: "default"}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(owner, secret).Build()
+	labels := map[string]string{"es.eck.github.com/elasticsearchapikey": "ingest"}
+
+	changed, err := EnsureControllerReference(cli, context.Background(), owner, secret, labels)
+	if err != nil || !changed {
+		t.Fatalf("EnsureControllerReference() = %v, %v, want a patch", changed, err)
+	}
+
+	var patched k8sv1.Secret
+	if err := cli.Get(context.Background(), client.ObjectKeyFromObject(secret), &patched); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if controller := metav1.GetControllerOf(&patched); controller == nil || controller.UID != "ingest-uid" || controller.Kind != "ElasticsearchApikey" {
+		t.Errorf("Expected the Secret to be controlled by the owner, got %v", controller)
+	}
+	if patched.Labels["es.eck.github.com/elasticsearchapikey"] != "ingest" {
+		t.Errorf("Expected the label to be set, got %v", patched.Labels)
+	}
+
+	if changed, err := EnsureControllerReference(cli, context.Background(), owner, &patched, labels); err != nil || changed {
+		t.Errorf("EnsureControllerReference() = %v, %v, want no patch once owned", changed, err)
+	}
+}
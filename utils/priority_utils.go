@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// PriorityAnnotation overrides the default reconcile priority of a resource kind, one of critical, high, normal or low
+const PriorityAnnotation = "eck.github.com/priority"
+
+// Priority orders the first reconcile of resources after an operator restart
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+	PriorityCritical
+)
+
+var priorityNames = map[string]Priority{
+	"low":      PriorityLow,
+	"normal":   PriorityNormal,
+	"high":     PriorityHigh,
+	"critical": PriorityCritical,
+}
+
+// StartupPriorityWindow is how long after the operator start the priorities are enforced. Afterwards, or once all
+// resources of higher priority were reconciled, resources are reconciled as soon as they are queued.
+const StartupPriorityWindow = 5 * time.Minute
+
+// startupPriorityRecheckInterval is how long a held back resource waits before its first recheck whether it may be
+// reconciled, the interval doubles with every recheck up to startupPriorityMaxRecheckInterval
+const (
+	startupPriorityRecheckInterval    = 2 * time.Second
+	startupPriorityMaxRecheckInterval = 30 * time.Second
+)
+
+// GetPriority returns the priority from the PriorityAnnotation of the object or defaultPriority if it is not set
+func GetPriority(obj client.Object, defaultPriority Priority) Priority {
+	if priority, ok := priorityNames[obj.GetAnnotations()[PriorityAnnotation]]; ok {
+		return priority
+	}
+	return defaultPriority
+}
+
+// startupScheduler tracks the resources of the initial list which were not reconciled yet
+type startupScheduler struct {
+	mu       sync.Mutex
+	pending  map[string]Priority
+	rechecks map[string]int
+	deadline time.Time
+	now      func() time.Time
+}
+
+var defaultStartupScheduler = newStartupScheduler(time.Now, StartupPriorityWindow)
+
+func newStartupScheduler(now func() time.Time, window time.Duration) *startupScheduler {
+	return &startupScheduler{
+		pending:  make(map[string]Priority),
+		rechecks: make(map[string]int),
+		deadline: now().Add(window),
+		now:      now,
+	}
+}
+
+func (s *startupScheduler) track(key string, priority Priority) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.now().Before(s.deadline) {
+		s.pending[key] = priority
+	}
+}
+
+func (s *startupScheduler) done(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, key)
+	delete(s.rechecks, key)
+}
+
+// priority returns the priority of the resource if it is pending
+func (s *startupScheduler) priority(key string) (Priority, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	priority, ok := s.pending[key]
+	return priority, ok
+}
+
+// holdBack returns how long the resource is held back until its next recheck, doubling with every recheck and never
+// past the end of the startup window
+func (s *startupScheduler) holdBack(key string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delay := startupPriorityRecheckInterval
+	for i := 0; i < s.rechecks[key] && delay < startupPriorityMaxRecheckInterval; i++ {
+		delay *= 2
+	}
+	s.rechecks[key]++
+	delay = min(delay, startupPriorityMaxRecheckInterval)
+	if remaining := s.deadline.Sub(s.now()); remaining > 0 && remaining < delay {
+		delay = remaining
+	}
+	return delay
+}
+
+// admit reports whether a resource of the given priority may be reconciled, which is the case when no resource
+// of higher priority is pending
+func (s *startupScheduler) admit(priority Priority) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.now().Before(s.deadline) {
+		s.pending = map[string]Priority{}
+		s.rechecks = map[string]int{}
+		return true
+	}
+	for _, pendingPriority := range s.pending {
+		if pendingPriority > priority {
+			return false
+		}
+	}
+	return true
+}
+
+// StartupPriority holds back the first reconcile of resources after an operator restart until all resources
+// of higher priority, across all controllers, were reconciled once.
+type StartupPriority struct {
+	object          client.Object
+	defaultPriority Priority
+	scheduler       *startupScheduler
+}
+
+// NewStartupPriority creates a StartupPriority for resources of the kind of object
+func NewStartupPriority(object client.Object, defaultPriority Priority) *StartupPriority {
+	return &StartupPriority{
+		object:          object,
+		defaultPriority: defaultPriority,
+		scheduler:       defaultStartupScheduler,
+	}
+}
+
+func (p *StartupPriority) key(name types.NamespacedName) string {
+	return fmt.Sprintf("%T/%s", p.object, name)
+}
+
+// Filter returns a predicate registering the resources of the initial list which this operator installation
+// reconciles. It never filters out events.
+func (p *StartupPriority) Filter() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			if e.IsInInitialList && ReconciledByOperator(e.Object) {
+				p.scheduler.track(p.key(client.ObjectKeyFromObject(e.Object)), GetPriority(e.Object, p.defaultPriority))
+			}
+			return true
+		},
+	}
+}
+
+// Reconciler wraps the reconciler, requeueing resources of the initial list while resources of higher priority are
+// pending, with a growing recheck interval. The priority is the one seen by the Filter, held back resources are not
+// read again. A resource counts as done once the wrapped reconciler returned, also when a SyncWave held it back, so
+// that a resource waiting for a lower wave does not hold back the resources of that wave.
+func (p *StartupPriority) Reconciler(_ client.Client, reconciler reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+		key := p.key(req.NamespacedName)
+		priority, pending := p.scheduler.priority(key)
+		if !pending {
+			return reconciler.Reconcile(ctx, req)
+		}
+
+		if !p.scheduler.admit(priority) {
+			log.FromContext(ctx).V(1).Info("Resources of higher priority are pending, holding back reconcile")
+			return ctrl.Result{RequeueAfter: p.scheduler.holdBack(key)}, nil
+		}
+		defer p.scheduler.done(key)
+		return reconciler.Reconcile(ctx, req)
+	})
+}
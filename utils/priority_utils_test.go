@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestGetPriority(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        Priority
+	}{
+		{name: "no annotation", annotations: nil, want: PriorityNormal},
+		{name: "critical", annotations: map[string]string{PriorityAnnotation: "critical"}, want: PriorityCritical},
+		{name: "low", annotations: map[string]string{PriorityAnnotation: "low"}, want: PriorityLow},
+		{name: "unknown value", annotations: map[string]string{PriorityAnnotation: "urgent"}, want: PriorityNormal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got := GetPriority(index, PriorityNormal); got != tt.want {
+				t.Errorf("GetPriority() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartupScheduler(t *testing.T) {
+	now := time.Now()
+	scheduler := newStartupScheduler(func() time.Time { return now }, time.Minute)
+
+	scheduler.track("role", PriorityCritical)
+	scheduler.track("dashboard", PriorityLow)
+
+	if !scheduler.admit(PriorityCritical) {
+		t.Error("Expected critical resources to be admitted")
+	}
+	if scheduler.admit(PriorityLow) {
+		t.Error("Expected low priority resources to be held back while critical ones are pending")
+	}
+
+	scheduler.done("role")
+	if !scheduler.admit(PriorityLow) {
+		t.Error("Expected low priority resources to be admitted once critical ones were reconciled")
+	}
+
+	scheduler.track("policy", PriorityCritical)
+	now = now.Add(time.Minute)
+	if !scheduler.admit(PriorityLow) {
+		t.Error("Expected all resources to be admitted after the startup window")
+	}
+	scheduler.track("late-policy", PriorityCritical)
+	if !scheduler.admit(PriorityLow) {
+		t.Error("Expected resources tracked after the startup window to be ignored")
+	}
+}
+
+func TestStartupPriority_Reconciler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+
+	index := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "my-index", Namespace: "default"}}
+	policy := &eseckv1alpha1.IndexLifecyclePolicy{ObjectMeta: metav1.ObjectMeta{Name: "my-policy", Namespace: "default"}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(index, policy).Build()
+
+	now := time.Now()
+	scheduler := newStartupScheduler(func() time.Time { return now }, time.Minute)
+	indexPriority := &StartupPriority{object: &eseckv1alpha1.Index{}, defaultPriority: PriorityNormal, scheduler: scheduler}
+	policyPriority := &StartupPriority{object: &eseckv1alpha1.IndexLifecyclePolicy{}, defaultPriority: PriorityCritical, scheduler: scheduler}
+
+	indexPriority.Filter().Create(event.CreateEvent{Object: index, IsInInitialList: true})
+	policyPriority.Filter().Create(event.CreateEvent{Object: policy, IsInInitialList: true})
+
+	reconciled := []string{}
+	inner := reconcile.Func(func(_ context.Context, req ctrl.Request) (ctrl.Result, error) {
+		reconciled = append(reconciled, req.Name)
+		return ctrl.Result{}, nil
+	})
+	indexReconciler := indexPriority.Reconciler(fakeClient, inner)
+	policyReconciler := policyPriority.Reconciler(fakeClient, inner)
+
+	indexReq := ctrl.Request{NamespacedName: types.NamespacedName{Name: "my-index", Namespace: "default"}}
+	policyReq := ctrl.Request{NamespacedName: types.NamespacedName{Name: "my-policy", Namespace: "default"}}
+
+	res, err := indexReconciler.Reconcile(context.Background(), indexReq)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if res.RequeueAfter != startupPriorityRecheckInterval || len(reconciled) != 0 {
+		t.Fatalf("Expected index to be requeued while the policy is pending, got %v and reconciled %v", res, reconciled)
+	}
+
+	if res, _ := indexReconciler.Reconcile(context.Background(), indexReq); res.RequeueAfter != 2*startupPriorityRecheckInterval {
+		t.Errorf("Expected the recheck interval to grow, got %v", res)
+	}
+
+	// Resources not in the initial list are not held back
+	lateReq := ctrl.Request{NamespacedName: types.NamespacedName{Name: "late-index", Namespace: "default"}}
+	if res, err := indexReconciler.Reconcile(context.Background(), lateReq); err != nil || res.RequeueAfter != 0 {
+		t.Fatalf("Expected a resource created later to be reconciled right away, got %v, %v", res, err)
+	}
+	reconciled = reconciled[:0]
+
+	if _, err := policyReconciler.Reconcile(context.Background(), policyReq); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if _, err := indexReconciler.Reconcile(context.Background(), indexReq); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if len(reconciled) != 2 || reconciled[0] != "my-policy" || reconciled[1] != "my-index" {
+		t.Errorf("Expected policy to be reconciled before index, got %v", reconciled)
+	}
+}
+
+func TestStartupScheduler_HoldBack(t *testing.T) {
+	now := time.Now()
+	scheduler := newStartupScheduler(func() time.Time { return now }, time.Minute)
+
+	var delays []time.Duration
+	for i := 0; i < 6; i++ {
+		delays = append(delays, scheduler.holdBack("dashboard"))
+	}
+	want := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second}
+	if !reflect.DeepEqual(delays, want) {
+		t.Errorf("holdBack() = %v, want %v", delays, want)
+	}
+
+	now = now.Add(50 * time.Second)
+	if got := scheduler.holdBack("dashboard"); got != 10*time.Second {
+		t.Errorf("Expected the recheck at the end of the startup window, got %s", got)
+	}
+	scheduler.done("dashboard")
+	if got := scheduler.holdBack("dashboard"); got != startupPriorityRecheckInterval {
+		t.Errorf("Expected the interval to start over once the resource was reconciled, got %s", got)
+	}
+}
+
+func TestStartupPriority_SyncWaveDoesNotHoldBack(t *testing.T) {
+	now := time.Now()
+	scheduler := newStartupScheduler(func() time.Time { return now }, time.Minute)
+	policyPriority := &StartupPriority{object: &eseckv1alpha1.IndexLifecyclePolicy{}, defaultPriority: PriorityCritical, scheduler: scheduler}
+	indexPriority := &StartupPriority{object: &eseckv1alpha1.Index{}, defaultPriority: PriorityNormal, scheduler: scheduler}
+	policy := &eseckv1alpha1.IndexLifecyclePolicy{ObjectMeta: metav1.ObjectMeta{Name: "my-policy", Namespace: "default",
+		Annotations: map[string]string{SyncWaveAnnotation: "1"}}}
+	index := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "my-index", Namespace: "default"}}
+	policyPriority.Filter().Create(event.CreateEvent{Object: policy, IsInInitialList: true})
+	indexPriority.Filter().Create(event.CreateEvent{Object: index, IsInInitialList: true})
+
+	// The policy waits for the index of a lower wave, which has a lower priority
+	waiting := reconcile.Func(func(context.Context, ctrl.Request) (ctrl.Result, error) {
+		return ctrl.Result{RequeueAfter: syncWaveRecheckInterval}, nil
+	})
+	if _, err := policyPriority.Reconciler(nil, waiting).Reconcile(context.Background(),
+		ctrl.Request{NamespacedName: client.ObjectKeyFromObject(policy)}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	reconciled := false
+	inner := reconcile.Func(func(context.Context, ctrl.Request) (ctrl.Result, error) {
+		reconciled = true
+		return ctrl.Result{}, nil
+	})
+	res, err := indexPriority.Reconciler(nil, inner).Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(index)})
+	if err != nil || !reconciled || res.RequeueAfter != 0 {
+		t.Errorf("Expected the index not to be held back by the policy waiting for its wave, got %v, %v", res, err)
+	}
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	configv2 "eck-custom-resources/api/config/v2"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+var supportedProxySchemes = map[string]bool{"http": true, "https": true, "socks5": true, "socks5h": true}
+
+// ProxyFunc returns the http.Transport proxy function for a target instance. Without proxy configuration the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used. Requests to localhost are never proxied.
+func ProxyFunc(proxy *configv2.ProxyConfig) (func(*http.Request) (*url.URL, error), error) {
+	if proxy == nil {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	for _, proxyUrl := range []string{proxy.HttpProxy, proxy.HttpsProxy} {
+		if proxyUrl == "" {
+			continue
+		}
+		parsed, err := url.Parse(proxyUrl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url %q: %w", proxyUrl, err)
+		}
+		if !supportedProxySchemes[parsed.Scheme] || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid proxy url %q, expected http://, https://, socks5:// or socks5h:// followed by a host", proxyUrl)
+		}
+	}
+
+	proxyForUrl := (&httpproxy.Config{
+		HTTPProxy:  proxy.HttpProxy,
+		HTTPSProxy: proxy.HttpsProxy,
+		NoProxy:    strings.Join(proxy.NoProxy, ","),
+	}).ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyForUrl(req.URL)
+	}, nil
+}
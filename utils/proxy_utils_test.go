@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+
+	configv2 "eck-custom-resources/api/config/v2"
+)
+
+func TestProxyFunc(t *testing.T) {
+	proxy := &configv2.ProxyConfig{
+		HttpProxy:  "http://proxy.internal:3128",
+		HttpsProxy: "socks5://socks.internal:1080",
+		NoProxy:    []string{".svc.cluster.local", "10.0.0.0/8"},
+	}
+
+	tests := []struct {
+		name      string
+		targetUrl string
+		want      string
+	}{
+		{name: "http target uses the http proxy", targetUrl: "http://es.example.com:9200", want: "http://proxy.internal:3128"},
+		{name: "https target uses the https proxy", targetUrl: "https://es.example.com:9200", want: "socks5://socks.internal:1080"},
+		{name: "domain in noProxy", targetUrl: "https://quickstart-es-http.elastic.svc.cluster.local:9200", want: ""},
+		{name: "ip in noProxy range", targetUrl: "https://10.1.2.3:9200", want: ""},
+		{name: "localhost is never proxied", targetUrl: "http://localhost:9200", want: ""},
+	}
+
+	proxyFunc, err := ProxyFunc(proxy)
+	if err != nil {
+		t.Fatalf("ProxyFunc() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.targetUrl, nil)
+			got, err := proxyFunc(req)
+			if err != nil {
+				t.Fatalf("proxy function error = %v", err)
+			}
+			gotUrl := ""
+			if got != nil {
+				gotUrl = got.String()
+			}
+			if gotUrl != tt.want {
+				t.Errorf("proxy for %s = %q, want %q", tt.targetUrl, gotUrl, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxyFunc_InvalidUrl(t *testing.T) {
+	for _, proxyUrl := range []string{"ftp://proxy.internal", "proxy.internal:3128", "http://"} {
+		if _, err := ProxyFunc(&configv2.ProxyConfig{HttpsProxy: proxyUrl}); err == nil {
+			t.Errorf("Expected an error for proxy url %q", proxyUrl)
+		}
+	}
+}
+
+func TestProxyFunc_FromEnvironment(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://env-proxy.internal:3128")
+
+	proxyFunc, err := ProxyFunc(nil)
+	if err != nil {
+		t.Fatalf("ProxyFunc() error = %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://kibana.example.com", nil)
+	got, err := proxyFunc(req)
+	if err != nil {
+		t.Fatalf("proxy function error = %v", err)
+	}
+	if got == nil || got.Host != "env-proxy.internal:3128" {
+		t.Errorf("Expected the proxy from the environment, got %v", got)
+	}
+}
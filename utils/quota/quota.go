@@ -0,0 +1,171 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+	"eck-custom-resources/utils"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Resource is a class of resources limited by the EckResourceQuotas of their namespace
+type Resource string
+
+const (
+	Indices         Resource = "indices"
+	IngestPipelines Resource = "ingest pipelines"
+	APIKeys         Resource = "API keys"
+	SavedObjects    Resource = "saved objects"
+)
+
+// Resources are all resources limited by quotas
+var Resources = []Resource{Indices, IngestPipelines, APIKeys, SavedObjects}
+
+// Kinds lists the kinds counted for each resource
+var Kinds = map[Resource][]schema.GroupVersionKind{
+	Indices:         {eseckv1alpha1.GroupVersion.WithKind("Index")},
+	IngestPipelines: {eseckv1alpha1.GroupVersion.WithKind("IngestPipeline")},
+	APIKeys:         {eseckv1alpha1.GroupVersion.WithKind("ElasticsearchApikey")},
+	SavedObjects: {
+		kibanaeckv1alpha1.GroupVersion.WithKind("CanvasWorkpad"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("Dashboard"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("DataView"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("IndexPattern"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("Lens"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("SavedSearch"),
+		kibanaeckv1alpha1.GroupVersion.WithKind("Visualization"),
+	},
+}
+
+// ResourceOfKind returns the resource counting the kind, false if the kind is not limited
+func ResourceOfKind(kind schema.GroupKind) (Resource, bool) {
+	for resource, gvks := range Kinds {
+		for _, gvk := range gvks {
+			if gvk.GroupKind() == kind {
+				return resource, true
+			}
+		}
+	}
+	return "", false
+}
+
+// MaxOf returns the limit of the resource set by the quota, nil if it does not limit the resource
+func MaxOf(spec eseckv1alpha1.EckResourceQuotaSpec, resource Resource) *int32 {
+	switch resource {
+	case Indices:
+		return spec.MaxIndices
+	case IngestPipelines:
+		return spec.MaxIngestPipelines
+	case APIKeys:
+		return spec.MaxAPIKeys
+	case SavedObjects:
+		return spec.MaxSavedObjects
+	}
+	return nil
+}
+
+// Limit returns the lowest limit of the resource set by the EckResourceQuotas of the namespace, nil if it is not
+// limited
+func Limit(ctx context.Context, cli client.Client, namespace string, resource Resource) (*int32, error) {
+	var quotas eseckv1alpha1.EckResourceQuotaList
+	if err := cli.List(ctx, &quotas, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	var limit *int32
+	for _, quota := range quotas.Items {
+		if !quota.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if max := MaxOf(quota.Spec, resource); max != nil && (limit == nil || *max < *limit) {
+			limit = max
+		}
+	}
+	return limit, nil
+}
+
+// List returns the resources of the namespace counted for the resource which are not being deleted, those created
+// in the remote cluster first, then by their age
+func List(ctx context.Context, cli client.Client, namespace string, resource Resource) ([]unstructured.Unstructured, error) {
+	var resources []unstructured.Unstructured
+	for _, gvk := range Kinds[resource] {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := utils.ListPaged(ctx, cli, list, client.InNamespace(namespace)); meta.IsNoMatchError(err) {
+			// The CRD of the kind is not installed
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			if item.GetDeletionTimestamp().IsZero() {
+				item.SetGroupVersionKind(gvk)
+				resources = append(resources, item)
+			}
+		}
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		if deployedI, deployedJ := deployed(&resources[i]), deployed(&resources[j]); deployedI != deployedJ {
+			return deployedI
+		}
+		createdI, createdJ := resources[i].GetCreationTimestamp(), resources[j].GetCreationTimestamp()
+		if !createdI.Equal(&createdJ) {
+			return createdI.Before(&createdJ)
+		}
+		return resources[i].GetKind()+"/"+resources[i].GetName() < resources[j].GetKind()+"/"+resources[j].GetName()
+	})
+	return resources, nil
+}
+
+// deployed reports whether the resource holds the finalizer of the operator, which is added once the object was
+// created in the remote cluster
+func deployed(obj client.Object) bool {
+	for _, finalizer := range obj.GetFinalizers() {
+		if strings.HasSuffix(finalizer, ".eck.github.com/finalizer") {
+			return true
+		}
+	}
+	return false
+}
+
+// Admit returns why the object of obj may not be created in the remote cluster, empty if it may. Resources already
+// created there are always admitted, so lowering a quota does not stop updates. Others are admitted in the order of
+// their age as long as the limit of their namespace is not reached.
+func Admit(ctx context.Context, cli client.Client, obj client.Object) (string, error) {
+	if deployed(obj) {
+		return "", nil
+	}
+	gvk, err := apiutil.GVKForObject(obj, cli.Scheme())
+	if err != nil {
+		return "", err
+	}
+	resource, ok := ResourceOfKind(gvk.GroupKind())
+	if !ok {
+		return "", nil
+	}
+	limit, err := Limit(ctx, cli, obj.GetNamespace(), resource)
+	if err != nil || limit == nil {
+		return "", err
+	}
+	resources, err := List(ctx, cli, obj.GetNamespace(), resource)
+	if err != nil {
+		return "", err
+	}
+	for i, item := range resources {
+		if item.GetKind() == gvk.Kind && item.GetName() == obj.GetName() {
+			if i < int(*limit) {
+				return "", nil
+			}
+			break
+		}
+	}
+	return fmt.Sprintf("the EckResourceQuota of namespace %s allows %d %s, %d exist", obj.GetNamespace(), *limit, resource, len(resources)), nil
+}
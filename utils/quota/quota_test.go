@@ -0,0 +1,149 @@
+package quota
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	kibanaeckv1alpha1 "eck-custom-resources/api/kibana.eck/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func newClient(objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	_ = kibanaeckv1alpha1.AddToScheme(scheme)
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func index(name string, age time.Duration, finalizers ...string) *eseckv1alpha1.Index {
+	return &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{
+		Name:              name,
+		Namespace:         "team-a",
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-age).Truncate(time.Second)),
+		Finalizers:        finalizers,
+	}}
+}
+
+func TestLimit(t *testing.T) {
+	cli := newClient(
+		&eseckv1alpha1.EckResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "loose", Namespace: "team-a"},
+			Spec:       eseckv1alpha1.EckResourceQuotaSpec{MaxIndices: int32Ptr(10), MaxSavedObjects: int32Ptr(5)},
+		},
+		&eseckv1alpha1.EckResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "strict", Namespace: "team-a"},
+			Spec:       eseckv1alpha1.EckResourceQuotaSpec{MaxIndices: int32Ptr(3)},
+		},
+		&eseckv1alpha1.EckResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "team-b"},
+			Spec:       eseckv1alpha1.EckResourceQuotaSpec{MaxIndices: int32Ptr(1)},
+		},
+	)
+
+	tests := []struct {
+		resource Resource
+		want     *int32
+	}{
+		{resource: Indices, want: int32Ptr(3)},
+		{resource: SavedObjects, want: int32Ptr(5)},
+		{resource: APIKeys, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.resource), func(t *testing.T) {
+			got, err := Limit(context.Background(), cli, "team-a", tt.resource)
+			if err != nil {
+				t.Fatalf("Limit() error = %v", err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("Limit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	cli := newClient(
+		index("new", time.Minute),
+		index("old", time.Hour),
+		index("deployed", time.Second, "index.eck.github.com/finalizer"),
+		&kibanaeckv1alpha1.Dashboard{ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "team-a"}},
+	)
+
+	resources, err := List(context.Background(), cli, "team-a", Indices)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var names []string
+	for _, resource := range resources {
+		names = append(names, resource.GetName())
+	}
+	if got := strings.Join(names, ","); got != "deployed,old,new" {
+		t.Errorf("List() = %s, want the deployed index first, then the oldest", got)
+	}
+}
+
+func TestList_CRDNotInstalled(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	resources, err := List(context.Background(), cli, "team-a", SavedObjects)
+	if err != nil {
+		t.Fatalf("List() error = %v, want the kinds without CRD to be skipped", err)
+	}
+	if len(resources) != 0 {
+		t.Errorf("List() = %d resources, want none", len(resources))
+	}
+}
+
+func TestAdmit(t *testing.T) {
+	deployed := index("deployed", time.Second, "index.eck.github.com/finalizer")
+	old := index("old", time.Hour)
+	young := index("new", time.Minute)
+	quota := &eseckv1alpha1.EckResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "team-a"},
+		Spec:       eseckv1alpha1.EckResourceQuotaSpec{MaxIndices: int32Ptr(2)},
+	}
+	cli := newClient(quota, deployed, old, young)
+
+	tests := []struct {
+		name    string
+		obj     *eseckv1alpha1.Index
+		wantErr bool
+	}{
+		{name: "deployed", obj: deployed},
+		{name: "within the limit", obj: old},
+		{name: "over the limit", obj: young, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, err := Admit(context.Background(), cli, tt.obj)
+			if err != nil {
+				t.Fatalf("Admit() error = %v", err)
+			}
+			if (reason != "") != tt.wantErr {
+				t.Errorf("Admit() = %q, wantErr %v", reason, tt.wantErr)
+			}
+		})
+	}
+
+	lowered := quota.DeepCopy()
+	lowered.Spec.MaxIndices = int32Ptr(0)
+	if err := cli.Update(context.Background(), lowered); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if reason, err := Admit(context.Background(), cli, deployed); err != nil || reason != "" {
+		t.Errorf("Admit() = %q, %v, want deployed resources admitted after lowering the quota", reason, err)
+	}
+}
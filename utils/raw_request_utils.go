@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	configv2 "eck-custom-resources/api/config/v2"
+)
+
+// RawRequestNotAllowedReason is the reason of the events of raw requests whose path is not allow-listed
+const RawRequestNotAllowedReason = "PathNotAllowed"
+
+// ValidateRawRequestPolicy returns an error if a path prefix of the policy is not absolute
+func ValidateRawRequestPolicy(policy *configv2.RawRequestPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	for _, prefix := range policy.Elasticsearch {
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("rawRequests.elasticsearch: prefix %q has to start with /", prefix)
+		}
+	}
+	for _, prefix := range policy.Kibana {
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("rawRequests.kibana: prefix %q has to start with /", prefix)
+		}
+	}
+	return nil
+}
+
+// CheckRawRequestPath returns an error unless the path, without its query string, starts with one of the prefixes at
+// a segment boundary: /_security/role allows /_security/role and /_security/role/admin, but not
+// /_security/role_mapping. Paths leaving their prefix with . or .. segments, also if encoded, and paths naming a host
+// are rejected.
+func CheckRawRequestPath(prefixes []string, requestPath string) error {
+	parsed, err := url.Parse(requestPath)
+	if err != nil {
+		return fmt.Errorf("invalid path %q: %w", requestPath, err)
+	}
+	if parsed.Scheme != "" || parsed.Host != "" || !strings.HasPrefix(parsed.Path, "/") {
+		return fmt.Errorf("path %q has to be absolute and must not name a host", requestPath)
+	}
+	if cleaned := path.Clean(parsed.Path); cleaned != strings.TrimSuffix(parsed.Path, "/") && cleaned != parsed.Path {
+		return fmt.Errorf("path %q has to be normalized, found %q", requestPath, cleaned)
+	}
+	for _, prefix := range prefixes {
+		if prefix != "" && hasPathPrefix(parsed.Path, prefix) {
+			return nil
+		}
+	}
+	if len(prefixes) == 0 {
+		return fmt.Errorf("path %q is not allow-listed, no path prefixes are allowed by the operator configuration", requestPath)
+	}
+	return fmt.Errorf("path %q is not allow-listed, allowed are paths starting with %s", requestPath, strings.Join(prefixes, ", "))
+}
+
+// hasPathPrefix reports whether the path starts with the prefix and continues with a new segment, if at all
+func hasPathPrefix(requestPath string, prefix string) bool {
+	if !strings.HasPrefix(requestPath, prefix) {
+		return false
+	}
+	return len(requestPath) == len(prefix) || strings.HasSuffix(prefix, "/") || requestPath[len(prefix)] == '/'
+}
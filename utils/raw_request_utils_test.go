@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestCheckRawRequestPath(t *testing.T) {
+	prefixes := []string{"/_security/role_mapping/", "/_connector/"}
+
+	tests := []struct {
+		name     string
+		prefixes []string
+		path     string
+		wantErr  bool
+	}{
+		{name: "allowed", prefixes: prefixes, path: "/_security/role_mapping/admins"},
+		{name: "allowed with query", prefixes: prefixes, path: "/_connector/github?refresh=true"},
+		{name: "other prefix", prefixes: prefixes, path: "/_cluster/settings", wantErr: true},
+		{name: "no prefixes", path: "/_security/role_mapping/admins", wantErr: true},
+		{name: "leaving the prefix", prefixes: prefixes, path: "/_security/role_mapping/../user/elastic", wantErr: true},
+		{name: "leaving the prefix encoded", prefixes: prefixes, path: "/_security/role_mapping/%2e%2e/user/elastic", wantErr: true},
+		{name: "relative", prefixes: []string{""}, path: "_security/role_mapping/admins", wantErr: true},
+		{name: "host", prefixes: prefixes, path: "//evil.example.com/_security/role_mapping/admins", wantErr: true},
+		{name: "prefix without slash", prefixes: []string{"/_security/role"}, path: "/_security/role/admins"},
+		{name: "prefix itself", prefixes: []string{"/_security/role"}, path: "/_security/role"},
+		{name: "prefix itself with query", prefixes: []string{"/_ingest/pipeline"}, path: "/_ingest/pipeline?pretty"},
+		{name: "sibling segment", prefixes: []string{"/_security/role"}, path: "/_security/role_mapping/admins", wantErr: true},
+		{name: "sibling name", prefixes: []string{"/_ingest/pipeline"}, path: "/_ingest/pipelinefoo", wantErr: true},
+		{name: "sibling of prefix with slash", prefixes: prefixes, path: "/_connector_other/github", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckRawRequestPath(tt.prefixes, tt.path); (err != nil) != tt.wantErr {
+				t.Errorf("CheckRawRequestPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -86,7 +86,7 @@ func ListResourcesReferencingResourceTemplateData(
 	})
 
 	// List all resources of this type across all namespaces
-	if err := cli.List(ctx, list); err != nil {
+	if err := ListPaged(ctx, cli, list); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand/v2"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RequeueJitterFactor is the maximum fraction by which requeue intervals are extended, so that resources failing
+// or waiting at the same time are not requeued all at once
+var RequeueJitterFactor = 0.2
+
+// ResyncPeriod is the interval in which converged resources are reconciled again. Every resource is resynced at
+// its own offset within the second half of the period, which spreads the resyncs across the sync window instead
+// of reconciling all resources at once. 0 disables the resync.
+var ResyncPeriod time.Duration
+
+// ListPageSize is the number of items requested per page by ListPaged
+var ListPageSize int64 = 500
+
+// Jitter extends d by a random fraction of up to RequeueJitterFactor
+func Jitter(d time.Duration) time.Duration {
+	if RequeueJitterFactor <= 0 {
+		return d
+	}
+	return d + time.Duration(rand.Float64()*RequeueJitterFactor*float64(d))
+}
+
+// JitterFor extends d by a fraction of up to RequeueJitterFactor which is derived from key, so the same resource
+// is always requeued at the same offset while different resources are spread
+func JitterFor(key string, d time.Duration) time.Duration {
+	if RequeueJitterFactor <= 0 {
+		return d
+	}
+	return d + time.Duration(keyFraction(key)*RequeueJitterFactor*float64(d))
+}
+
+// ResyncAfter returns when the resource with the given key is resynced after it converged, 0 if the resync
+// is disabled
+func ResyncAfter(key string) time.Duration {
+	if ResyncPeriod <= 0 {
+		return 0
+	}
+	half := ResyncPeriod / 2
+	return half + time.Duration(keyFraction(key)*float64(half))
+}
+
+// keyFraction maps key to a stable fraction in [0, 1)
+func keyFraction(key string) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return float64(h.Sum64()>>11) / float64(1<<53)
+}
+
+// ListPaged lists the items into list in pages of ListPageSize items, keeping single responses of the API server
+// small with thousands of resources. cli has to read from the API server, e.g. for unstructured lists or through
+// the API reader of the manager, as the cache does not support continuing a list.
+func ListPaged(ctx context.Context, cli client.Reader, list client.ObjectList, opts ...client.ListOption) error {
+	var items []runtime.Object
+	continueToken := ""
+	for {
+		pageOpts := append(append([]client.ListOption{}, opts...), client.Limit(ListPageSize), client.Continue(continueToken))
+		if err := cli.List(ctx, list, pageOpts...); err != nil {
+			if apierrors.IsResourceExpired(err) && continueToken != "" {
+				// The continue token expired while paging, start over to get a consistent list
+				items, continueToken = nil, ""
+				continue
+			}
+			return err
+		}
+		pageItems, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range pageItems {
+			// The next page is decoded into the same list, keep copies of the items
+			items = append(items, item.DeepCopyObject())
+		}
+
+		continueToken = list.GetContinue()
+		if continueToken == "" {
+			return meta.SetList(list, items)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestJitterFor(t *testing.T) {
+	maxDuration := time.Minute + time.Duration(RequeueJitterFactor*float64(time.Minute))
+	offsets := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("Index/team-a/logs-%d", i)
+		d := JitterFor(key, time.Minute)
+		if d < time.Minute || d > maxDuration {
+			t.Fatalf("JitterFor(%s) = %v, want between %v and %v", key, d, time.Minute, maxDuration)
+		}
+		if again := JitterFor(key, time.Minute); again != d {
+			t.Errorf("JitterFor(%s) is not stable, got %v and %v", key, d, again)
+		}
+		offsets[d] = true
+	}
+	if len(offsets) < 90 {
+		t.Errorf("Expected the resources to be spread, got %d distinct offsets for 100 resources", len(offsets))
+	}
+}
+
+func TestResyncAfter(t *testing.T) {
+	defer func(period time.Duration) { ResyncPeriod = period }(ResyncPeriod)
+
+	ResyncPeriod = 0
+	if got := ResyncAfter("Index/team-a/logs"); got != 0 {
+		t.Errorf("Expected no resync when disabled, got %v", got)
+	}
+
+	ResyncPeriod = 10 * time.Hour
+	var early, late int
+	for i := 0; i < 1000; i++ {
+		got := ResyncAfter(fmt.Sprintf("Index/team-a/logs-%d", i))
+		if got < 5*time.Hour || got >= 10*time.Hour {
+			t.Fatalf("ResyncAfter() = %v, want within the second half of the sync period", got)
+		}
+		if got < 7*time.Hour+30*time.Minute {
+			early++
+		} else {
+			late++
+		}
+	}
+	if early < 400 || late < 400 {
+		t.Errorf("Expected the resyncs to be spread across the window, got %d early and %d late", early, late)
+	}
+}
+
+// pagedReader serves the items in pages, expiring the continue token once if expireOnce is set
+type pagedReader struct {
+	client.Reader
+	items      []string
+	requests   int
+	expireOnce bool
+}
+
+func (r *pagedReader) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	r.requests++
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.Continue != "" && r.expireOnce {
+		r.expireOnce = false
+		return apierrors.NewResourceExpired("continue token expired")
+	}
+	start := 0
+	if listOpts.Continue != "" {
+		start, _ = strconv.Atoi(listOpts.Continue)
+	}
+	end := min(start+int(listOpts.Limit), len(r.items))
+
+	u := list.(*unstructured.UnstructuredList)
+	u.Items = nil
+	for _, name := range r.items[start:end] {
+		item := unstructured.Unstructured{}
+		item.SetName(name)
+		u.Items = append(u.Items, item)
+	}
+	u.SetContinue("")
+	if end < len(r.items) {
+		u.SetContinue(strconv.Itoa(end))
+	}
+	return nil
+}
+
+func TestListPaged(t *testing.T) {
+	defer func(size int64) { ListPageSize = size }(ListPageSize)
+	ListPageSize = 2
+
+	tests := []struct {
+		name         string
+		expireOnce   bool
+		wantRequests int
+	}{
+		{name: "pages", wantRequests: 3},
+		{name: "expired continue token", expireOnce: true, wantRequests: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &pagedReader{items: []string{"a", "b", "c", "d", "e"}, expireOnce: tt.expireOnce}
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(schema.GroupVersionKind{Group: "es.eck.github.com", Version: "v1alpha1", Kind: "IndexList"})
+
+			if err := ListPaged(context.Background(), reader, list); err != nil {
+				t.Fatalf("ListPaged() error = %v", err)
+			}
+			var names []string
+			for _, item := range list.Items {
+				names = append(names, item.GetName())
+			}
+			if fmt.Sprint(names) != "[a b c d e]" {
+				t.Errorf("ListPaged() items = %v, want [a b c d e]", names)
+			}
+			if reader.requests != tt.wantRequests {
+				t.Errorf("Expected %d list requests, got %d", tt.wantRequests, reader.requests)
+			}
+		})
+	}
+}
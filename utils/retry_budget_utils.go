@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Degraded condition, set once the reconciles of a resource failed PersistentFailureThreshold times in a row
+const (
+	DegradedConditionType    = "Degraded"
+	DegradedReasonPersistent = "Persistent"
+)
+
+// PersistentFailureThreshold is the number of consecutive failed reconciles after which a resource is Degraded and
+// only retried at its resync. 0 disables the retry budget.
+var PersistentFailureThreshold = 20
+
+// persistentFailureRequeueAfter is when Degraded resources are retried if the resync is disabled
+const persistentFailureRequeueAfter = time.Hour
+
+// RetryBudget counts the consecutive failed reconciles of the resources of a kind in status.consecutiveFailures.
+// Resources exceeding PersistentFailureThreshold get the Degraded condition and are retried at their resync instead
+// of with the backoff of the work queue.
+type RetryBudget struct {
+	object    client.Object
+	gvk       schema.GroupVersionKind
+	reader    client.Reader
+	recorder  record.EventRecorder
+	collector *reconcileCollector
+}
+
+// NewRetryBudget creates a RetryBudget for resources of the kind of object
+func NewRetryBudget(mgr ctrl.Manager, object client.Object, recorder record.EventRecorder) *RetryBudget {
+	gvk, _ := apiutil.GVKForObject(object, mgr.GetScheme())
+	return &RetryBudget{
+		object:    object,
+		gvk:       gvk,
+		reader:    mgr.GetAPIReader(),
+		recorder:  recorder,
+		collector: defaultReconcileCollector,
+	}
+}
+
+// Reconciler wraps the reconciler, recording the outcome of every reconcile in the status of the resource. Once a
+// resource is Degraded, its failed reconciles are requeued for the resync and no longer returned as errors.
+func (b *RetryBudget) Reconciler(cli client.Client, reconciler reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+		res, reconcileErr := reconciler.Reconcile(ctx, req)
+		if PersistentFailureThreshold <= 0 {
+			return res, reconcileErr
+		}
+
+		obj := b.object.DeepCopyObject().(client.Object)
+		if err := cli.Get(ctx, req.NamespacedName, obj); err != nil || !obj.GetDeletionTimestamp().IsZero() {
+			b.collector.observeDegraded(b.gvk.Kind, req, false)
+			return res, reconcileErr
+		}
+		if reconcileErr == nil && !hasFailures(obj) {
+			return res, reconcileErr
+		}
+		// The reconcile usually just updated the status, which the cache may not have seen yet
+		if err := b.reader.Get(ctx, req.NamespacedName, obj); err != nil {
+			return res, reconcileErr
+		}
+		degraded, err := b.record(ctx, cli, obj, reconcileErr)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to update consecutive failures")
+		}
+		b.collector.observeDegraded(b.gvk.Kind, req, degraded)
+		if !degraded {
+			return res, reconcileErr
+		}
+
+		requeueAfter := ResyncAfter(b.gvk.Kind + "/" + req.String())
+		if requeueAfter == 0 {
+			requeueAfter = Jitter(persistentFailureRequeueAfter)
+		}
+		log.FromContext(ctx).Info("Reconcile failed persistently, retrying at the resync",
+			"error", reconcileErr.Error(), "requeueAfter", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	})
+}
+
+// record updates status.consecutiveFailures and the Degraded condition of obj after a reconcile, and returns
+// whether the resource is Degraded
+func (b *RetryBudget) record(ctx context.Context, cli client.Client, obj client.Object, reconcileErr error) (bool, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return false, err
+	}
+	failures, _, _ := unstructured.NestedInt64(content, "status", "consecutiveFailures")
+	conditions := conditionsOf(content)
+
+	changed := false
+	degraded := false
+	if reconcileErr == nil {
+		changed = failures != 0 || meta.FindStatusCondition(conditions, DegradedConditionType) != nil
+		failures = 0
+		meta.RemoveStatusCondition(&conditions, DegradedConditionType)
+	} else {
+		failures++
+		changed = true
+		if failures >= int64(PersistentFailureThreshold) {
+			degraded = true
+			if !meta.IsStatusConditionTrue(conditions, DegradedConditionType) {
+				// A single summary event instead of one per failed reconcile
+				b.recorder.Event(obj, "Warning", DegradedReasonPersistent,
+					fmt.Sprintf("Reconcile failed %d times in a row, retrying at the resync: %s", failures, reconcileErr.Error()))
+			}
+			meta.SetStatusCondition(&conditions, metav1.Condition{
+				Type:               DegradedConditionType,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: obj.GetGeneration(),
+				Reason:             DegradedReasonPersistent,
+				Message:            fmt.Sprintf("Reconcile failed %d times in a row: %s", failures, reconcileErr.Error()),
+			})
+		}
+	}
+	if !changed {
+		return degraded, nil
+	}
+
+	patch := client.MergeFromWithOptions(obj.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+	if err := unstructured.SetNestedField(content, failures, "status", "consecutiveFailures"); err != nil {
+		return degraded, err
+	}
+	if err := setConditionsOf(content, conditions); err != nil {
+		return degraded, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, obj); err != nil {
+		return degraded, err
+	}
+	return degraded, cli.Status().Patch(ctx, obj, patch)
+}
+
+// hasFailures reports whether consecutive failures or the Degraded condition are recorded on obj
+func hasFailures(obj client.Object) bool {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return false
+	}
+	failures, _, _ := unstructured.NestedInt64(content, "status", "consecutiveFailures")
+	return failures != 0 || meta.FindStatusCondition(conditionsOf(content), DegradedConditionType) != nil
+}
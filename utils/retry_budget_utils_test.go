@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestRetryBudget_Reconciler(t *testing.T) {
+	defer func(threshold int) { PersistentFailureThreshold = threshold }(PersistentFailureThreshold)
+	PersistentFailureThreshold = 3
+
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+
+	index := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "default", Generation: 1}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(index).WithStatusSubresource(index).Build()
+	recorder := record.NewFakeRecorder(10)
+	collector := newReconcileCollector(time.Now)
+	budget := &RetryBudget{object: &eseckv1alpha1.Index{}, gvk: eseckv1alpha1.GroupVersion.WithKind("Index"),
+		reader: fakeClient, recorder: recorder, collector: collector}
+
+	var reconcileErr error
+	reconciler := budget.Reconciler(fakeClient, reconcile.Func(func(_ context.Context, _ ctrl.Request) (ctrl.Result, error) {
+		return ctrl.Result{}, reconcileErr
+	}))
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(index)}
+
+	reconcileErr = errors.New("connection refused")
+	for i := 1; i <= 4; i++ {
+		res, err := reconciler.Reconcile(ctx, req)
+		if i < 3 && err == nil {
+			t.Fatalf("Reconcile %d: expected the error to be returned below the threshold", i)
+		}
+		if i >= 3 && (err != nil || res.RequeueAfter == 0) {
+			t.Fatalf("Reconcile %d: expected a requeue at the resync without an error, got %v and %v", i, res, err)
+		}
+	}
+
+	if err := fakeClient.Get(ctx, req.NamespacedName, index); err != nil {
+		t.Fatal(err)
+	}
+	if index.Status.ConsecutiveFailures != 4 {
+		t.Errorf("Expected 4 consecutive failures, got %d", index.Status.ConsecutiveFailures)
+	}
+	degraded := meta.FindStatusCondition(index.Status.Conditions, DegradedConditionType)
+	if degraded == nil || degraded.Status != metav1.ConditionTrue || degraded.Reason != DegradedReasonPersistent {
+		t.Errorf("Expected the Degraded condition with reason Persistent, got %v", degraded)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Expected a single summary event, got %d", len(recorder.Events))
+	}
+	if !collector.degraded["Index"][req] {
+		t.Error("Expected the resource to be reported as degraded")
+	}
+
+	reconcileErr = nil
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := fakeClient.Get(ctx, req.NamespacedName, index); err != nil {
+		t.Fatal(err)
+	}
+	if index.Status.ConsecutiveFailures != 0 || meta.FindStatusCondition(index.Status.Conditions, DegradedConditionType) != nil {
+		t.Errorf("Expected the failures to be reset after a successful reconcile, got %d and %v",
+			index.Status.ConsecutiveFailures, index.Status.Conditions)
+	}
+	if collector.degraded["Index"][req] {
+		t.Error("Expected the resource no longer to be reported as degraded")
+	}
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ShutdownGracePeriod is how long reconciles in flight when the operator is stopped may continue, so that remote
+// calls are not cancelled halfway through a multi-step operation. 0 cancels them right away.
+var ShutdownGracePeriod = 20 * time.Second
+
+// ErrShutdownGracePeriodExceeded is the cause of the cancellation of a reconcile still running once the
+// ShutdownGracePeriod is over
+var ErrShutdownGracePeriodExceeded = errors.New("shutdown grace period exceeded")
+
+// DrainContext returns a context which, unlike ctx, is only cancelled ShutdownGracePeriod after ctx is done, or once
+// the returned cancel function is called. Its values are those of ctx.
+func DrainContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ShutdownGracePeriod <= 0 {
+		return context.WithCancel(ctx)
+	}
+	drainCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	gracePeriod := ShutdownGracePeriod
+	stop := context.AfterFunc(ctx, func() {
+		timer := time.NewTimer(gracePeriod)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			cancel(ErrShutdownGracePeriodExceeded)
+		case <-drainCtx.Done():
+		}
+	})
+	return drainCtx, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDrainContext(t *testing.T) {
+	defer func(gracePeriod time.Duration) { ShutdownGracePeriod = gracePeriod }(ShutdownGracePeriod)
+	ShutdownGracePeriod = 50 * time.Millisecond
+
+	ctx, stop := context.WithCancel(context.Background())
+	drainCtx, cancel := DrainContext(ctx)
+	defer cancel()
+
+	stop()
+	select {
+	case <-drainCtx.Done():
+		t.Fatal("Expected the drain context to outlive its parent during the grace period")
+	case <-time.After(10 * time.Millisecond):
+	}
+	select {
+	case <-drainCtx.Done():
+		if cause := context.Cause(drainCtx); !errors.Is(cause, ErrShutdownGracePeriodExceeded) {
+			t.Errorf("Expected the grace period to be the cause, got %v", cause)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the drain context to be cancelled after the grace period")
+	}
+
+	drainCtx, cancel = DrainContext(context.Background())
+	cancel()
+	if drainCtx.Err() == nil {
+		t.Error("Expected cancel to cancel the drain context")
+	}
+
+	ShutdownGracePeriod = 0
+	ctx, stop = context.WithCancel(context.Background())
+	drainCtx, cancel = DrainContext(ctx)
+	defer cancel()
+	stop()
+	if drainCtx.Err() == nil {
+		t.Error("Expected the drain context to be cancelled with its parent without grace period")
+	}
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// LastAppliedHashAnnotation holds the hash of the spec that was last successfully applied
+	LastAppliedHashAnnotation = "eck.github.com/last-applied-hash"
+	// LastSyncTimeAnnotation holds the RFC3339 timestamp of the last successful reconcile
+	LastSyncTimeAnnotation = "eck.github.com/last-sync-time"
+)
+
+// Ready condition, following the kstatus conventions understood by Flux and the Argo CD health check in docs/sync_status.md
+const (
+	ReadyConditionType          = "Ready"
+	ReadyReasonReconciled       = "ReconcileSucceeded"
+	ReadyReasonReconcileFailure = "ReconcileFailed"
+)
+
+// SpecHash returns a stable sha256 hash of the JSON representation of spec.
+func SpecHash(spec any) (string, error) {
+	marshalledSpec, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(marshalledSpec)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// SetReadyCondition sets the Ready condition for the given generation based on the reconcile error. The
+// PolicyViolation condition is set while the error is a *PolicyViolationError.
+func SetReadyCondition(conditions *[]metav1.Condition, generation int64, reconcileErr error) {
+	condition := metav1.Condition{
+		Type:               ReadyConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             ReadyReasonReconciled,
+		Message:            "Resource is in sync with the target instance",
+	}
+	if reconcileErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReadyReasonReconcileFailure
+		condition.Message = reconcileErr.Error()
+	}
+	if IsPolicyViolation(reconcileErr) {
+		condition.Reason = PolicyViolationReason
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               PolicyViolationConditionType,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: generation,
+			Reason:             PolicyViolationReason,
+			Message:            reconcileErr.Error(),
+		})
+	} else {
+		meta.RemoveStatusCondition(conditions, PolicyViolationConditionType)
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
+
+// UpdateSyncStatus records the outcome of a reconcile on the object. The Ready condition and
+// observedGeneration are written to the status subresource; after a successful reconcile the
+// last-applied-hash and last-sync-time annotations are patched as well.
+// conditions and observedGeneration must point into the status of obj.
+func UpdateSyncStatus(cli client.Client, ctx context.Context, obj client.Object, spec any,
+	conditions *[]metav1.Condition, observedGeneration *int64, reconcileErr error) (err error) {
+	ctx, span := StartSpan(ctx, "UpdateSyncStatus")
+	defer func() { EndSpan(span, err) }()
+
+	SetReadyCondition(conditions, obj.GetGeneration(), reconcileErr)
+	*observedGeneration = obj.GetGeneration()
+	if err := cli.Status().Update(ctx, obj); err != nil {
+		return err
+	}
+
+	if reconcileErr != nil {
+		return nil
+	}
+	return UpdateSyncAnnotations(cli, ctx, obj, spec)
+}
+
+// UpdateSyncAnnotations patches the last-applied-hash and last-sync-time annotations on the object.
+func UpdateSyncAnnotations(cli client.Client, ctx context.Context, obj client.Object, spec any) error {
+	hash, err := SpecHash(spec)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[LastAppliedHashAnnotation] = hash
+	annotations[LastSyncTimeAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+
+	return cli.Patch(ctx, obj, patch)
+}
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSpecHash(t *testing.T) {
+	first, err := SpecHash(eseckv1alpha1.IndexSpec{Body: `{"settings": {}}`})
+	if err != nil {
+		t.Fatalf("SpecHash() error = %v", err)
+	}
+	same, _ := SpecHash(eseckv1alpha1.IndexSpec{Body: `{"settings": {}}`})
+	other, _ := SpecHash(eseckv1alpha1.IndexSpec{Body: `{"mappings": {}}`})
+
+	if first != same {
+		t.Errorf("Expected equal specs to produce the same hash, got %s and %s", first, same)
+	}
+	if first == other {
+		t.Error("Expected different specs to produce different hashes")
+	}
+}
+
+func TestSetReadyCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "success",
+			err:        nil,
+			wantStatus: metav1.ConditionTrue,
+			wantReason: ReadyReasonReconciled,
+		},
+		{
+			name:       "failure",
+			err:        errors.New("connection refused"),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: ReadyReasonReconcileFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var conditions []metav1.Condition
+			SetReadyCondition(&conditions, 3, tt.err)
+
+			condition := meta.FindStatusCondition(conditions, ReadyConditionType)
+			if condition == nil {
+				t.Fatal("Expected Ready condition to be set")
+			}
+			if condition.Status != tt.wantStatus {
+				t.Errorf("Status = %s, want %s", condition.Status, tt.wantStatus)
+			}
+			if condition.Reason != tt.wantReason {
+				t.Errorf("Reason = %s, want %s", condition.Reason, tt.wantReason)
+			}
+			if condition.ObservedGeneration != 3 {
+				t.Errorf("ObservedGeneration = %d, want 3", condition.ObservedGeneration)
+			}
+		})
+	}
+}
+
+func TestUpdateSyncStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+
+	tests := []struct {
+		name            string
+		reconcileErr    error
+		wantReady       metav1.ConditionStatus
+		wantAnnotations bool
+	}{
+		{
+			name:            "successful reconcile",
+			reconcileErr:    nil,
+			wantReady:       metav1.ConditionTrue,
+			wantAnnotations: true,
+		},
+		{
+			name:            "failed reconcile",
+			reconcileErr:    errors.New("boom"),
+			wantReady:       metav1.ConditionFalse,
+			wantAnnotations: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := &eseckv1alpha1.Index{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-index", Namespace: "default", Generation: 2},
+				Spec:       eseckv1alpha1.IndexSpec{Body: `{}`},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(index).
+				WithStatusSubresource(index).
+				Build()
+
+			if err := UpdateSyncStatus(fakeClient, context.Background(), index, index.Spec,
+				&index.Status.Conditions, &index.Status.ObservedGeneration, tt.reconcileErr); err != nil {
+				t.Fatalf("UpdateSyncStatus() error = %v", err)
+			}
+
+			var got eseckv1alpha1.Index
+			if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(index), &got); err != nil {
+				t.Fatalf("Failed to get index: %v", err)
+			}
+
+			if got.Status.ObservedGeneration != got.Generation {
+				t.Errorf("ObservedGeneration = %d, want %d", got.Status.ObservedGeneration, got.Generation)
+			}
+			if !meta.IsStatusConditionPresentAndEqual(got.Status.Conditions, ReadyConditionType, tt.wantReady) {
+				t.Errorf("Expected Ready condition %s, got %v", tt.wantReady, got.Status.Conditions)
+			}
+
+			hash, hasHash := got.Annotations[LastAppliedHashAnnotation]
+			syncTime, hasSyncTime := got.Annotations[LastSyncTimeAnnotation]
+			if hasHash != tt.wantAnnotations || hasSyncTime != tt.wantAnnotations {
+				t.Fatalf("Expected sync annotations present = %v, got %v", tt.wantAnnotations, got.Annotations)
+			}
+			if !tt.wantAnnotations {
+				return
+			}
+
+			wantHash, _ := SpecHash(index.Spec)
+			if hash != wantHash {
+				t.Errorf("%s = %s, want %s", LastAppliedHashAnnotation, hash, wantHash)
+			}
+			if _, err := time.Parse(time.RFC3339, syncTime); err != nil {
+				t.Errorf("%s is not RFC3339: %v", LastSyncTimeAnnotation, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// SyncWaveAnnotation orders the reconciles of resources targeting the same instance: a resource is only reconciled
+// once all resources of lower waves are Ready. Waves are integers, resources without the annotation are in wave 0.
+const SyncWaveAnnotation = "eck.github.com/sync-wave"
+
+// syncWaveRecheckInterval is how often resources waiting for lower waves check whether they may be reconciled
+const syncWaveRecheckInterval = 5 * time.Second
+
+// GetSyncWave returns the wave from the SyncWaveAnnotation of the object, 0 if it is not set or not an integer
+func GetSyncWave(obj client.Object) int {
+	wave, err := strconv.Atoi(strings.TrimSpace(obj.GetAnnotations()[SyncWaveAnnotation]))
+	if err != nil {
+		return 0
+	}
+	return wave
+}
+
+// syncWaveMember is a resource known to the syncWaves
+type syncWaveMember struct {
+	gvk    schema.GroupVersionKind
+	key    types.NamespacedName
+	wave   int
+	target string
+}
+
+// syncWaves tracks the waves and target instances of the resources of all kinds
+type syncWaves struct {
+	mu      sync.Mutex
+	members map[string]syncWaveMember
+	waiting map[string]bool
+}
+
+var defaultSyncWaves = &syncWaves{members: make(map[string]syncWaveMember), waiting: make(map[string]bool)}
+
+func (w *syncWaves) track(member syncWaveMember) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.members[member.gvk.Kind+"/"+member.key.String()] = member
+}
+
+func (w *syncWaves) forget(gvk schema.GroupVersionKind, key types.NamespacedName) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.members, gvk.Kind+"/"+key.String())
+	delete(w.waiting, gvk.Kind+"/"+key.String())
+}
+
+// lowerWaves returns the resources targeting the target in waves below the wave
+func (w *syncWaves) lowerWaves(target string, wave int) []syncWaveMember {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var lower []syncWaveMember
+	for _, member := range w.members {
+		if member.wave < wave && member.target == target {
+			lower = append(lower, member)
+		}
+	}
+	return lower
+}
+
+// startWaiting marks the resource as waiting and reports whether it was not waiting before
+func (w *syncWaves) startWaiting(key string, waiting bool) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	started := waiting && !w.waiting[key]
+	if waiting {
+		w.waiting[key] = true
+	} else {
+		delete(w.waiting, key)
+	}
+	return started
+}
+
+// SyncWave holds back the reconciles of resources of a kind until the resources of lower sync waves targeting the
+// same instance, of any kind, are Ready.
+type SyncWave struct {
+	object   client.Object
+	gvk      schema.GroupVersionKind
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	waves    *syncWaves
+}
+
+// NewSyncWave creates a SyncWave for resources of the kind of object
+func NewSyncWave(mgr ctrl.Manager, object client.Object, recorder record.EventRecorder) *SyncWave {
+	gvk, _ := apiutil.GVKForObject(object, mgr.GetScheme())
+	return &SyncWave{
+		object:   object,
+		gvk:      gvk,
+		scheme:   mgr.GetScheme(),
+		recorder: recorder,
+		waves:    defaultSyncWaves,
+	}
+}
+
+// Filter returns a predicate tracking the waves and target instances of the resources of the kind. It never filters
+// out events and has to come before filters which do.
+func (s *SyncWave) Filter() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			s.track(e.Object)
+			return true
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			s.track(e.ObjectNew)
+			return true
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			if reflect.TypeOf(e.Object) == reflect.TypeOf(s.object) {
+				s.waves.forget(s.gvk, client.ObjectKeyFromObject(e.Object))
+			}
+			return true
+		},
+	}
+}
+
+func (s *SyncWave) track(obj client.Object) {
+	// The filter also sees the events of owned and watched resources of other kinds
+	if reflect.TypeOf(obj) != reflect.TypeOf(s.object) {
+		return
+	}
+	s.waves.track(syncWaveMember{gvk: s.gvk, key: client.ObjectKeyFromObject(obj), wave: GetSyncWave(obj), target: s.target(obj)})
+}
+
+// target identifies the target instance as declared in spec.targetInstance, the instance of the operator
+// configuration if no name is set
+func (s *SyncWave) target(obj client.Object) string {
+	namespace := obj.GetNamespace()
+	name := ""
+	if content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err == nil {
+		name, _, _ = unstructured.NestedString(content, "spec", "targetInstance", "name")
+		if targetNamespace, _, _ := unstructured.NestedString(content, "spec", "targetInstance", "namespace"); targetNamespace != "" {
+			namespace = targetNamespace
+		}
+	}
+	return fmt.Sprintf("%s/%s/%s", s.gvk.Group, namespace, name)
+}
+
+// Reconciler wraps the reconciler, requeueing resources while resources of lower waves targeting the same instance
+// are not Ready. Deletions are not held back by waves, but in a terminating namespace they wait for the resources of
+// earlier NamespaceCleanupStages.
+func (s *SyncWave) Reconciler(cli client.Client, reconciler reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+		obj := s.object.DeepCopyObject().(client.Object)
+		if err := cli.Get(ctx, req.NamespacedName, obj); err != nil {
+			return reconciler.Reconcile(ctx, req)
+		}
+		if !obj.GetDeletionTimestamp().IsZero() {
+			return s.reconcileDeletion(ctx, cli, reconciler, req, obj)
+		}
+
+		pending, err := s.pendingLowerWaves(ctx, cli, obj)
+		if err != nil {
+			return GetRequeueResult(), err
+		}
+		key := s.gvk.Kind + "/" + req.String()
+		if s.waves.startWaiting(key, len(pending) > 0) {
+			s.recorder.Event(obj, "Normal", "WaitingForSyncWave",
+				fmt.Sprintf("Waiting for resources of lower sync waves to become Ready: %s", strings.Join(pending, ", ")))
+		}
+		if len(pending) > 0 {
+			log.FromContext(ctx).V(1).Info("Resources of lower sync waves are not Ready, holding back reconcile", "pending", pending)
+			return ctrl.Result{RequeueAfter: syncWaveRecheckInterval}, nil
+		}
+		return reconciler.Reconcile(ctx, req)
+	})
+}
+
+// reconcileDeletion holds back the deletion of obj while resources of earlier cleanup stages are left in its
+// terminating namespace
+func (s *SyncWave) reconcileDeletion(ctx context.Context, cli client.Client, reconciler reconcile.Reconciler, req ctrl.Request,
+	obj client.Object) (ctrl.Result, error) {
+	pending, err := PendingNamespaceCleanup(ctx, cli, req.Namespace, s.gvk.GroupKind())
+	if err != nil {
+		return GetRequeueResult(), err
+	}
+	key := "cleanup/" + s.gvk.Kind + "/" + req.String()
+	if s.waves.startWaiting(key, len(pending) > 0) {
+		s.recorder.Event(obj, "Normal", "WaitingForNamespaceCleanup",
+			fmt.Sprintf("Waiting for resources of earlier cleanup stages to be deleted: %s", strings.Join(pending, ", ")))
+	}
+	if len(pending) > 0 {
+		log.FromContext(ctx).V(1).Info("Resources of earlier cleanup stages are left, holding back deletion", "pending", pending)
+		return ctrl.Result{RequeueAfter: NamespaceCleanupInterval}, nil
+	}
+	return reconciler.Reconcile(ctx, req)
+}
+
+// pendingLowerWaves returns the resources of lower waves targeting the instance of obj which are not Ready
+func (s *SyncWave) pendingLowerWaves(ctx context.Context, cli client.Client, obj client.Object) ([]string, error) {
+	var pending []string
+	for _, member := range s.waves.lowerWaves(s.target(obj), GetSyncWave(obj)) {
+		runtimeObj, err := s.scheme.New(member.gvk)
+		if err != nil {
+			return nil, err
+		}
+		lower := runtimeObj.(client.Object)
+		if err := cli.Get(ctx, member.key, lower); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return nil, err
+			}
+			s.waves.forget(member.gvk, member.key)
+			continue
+		}
+		if !lower.GetDeletionTimestamp().IsZero() || isReady(lower) {
+			continue
+		}
+		pending = append(pending, fmt.Sprintf("%s %s", member.gvk.Kind, member.key))
+	}
+	sort.Strings(pending)
+	return pending, nil
+}
+
+// isReady reports whether the Ready condition of the object is True for its current generation
+func isReady(obj client.Object) bool {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return false
+	}
+	ready := meta.FindStatusCondition(conditionsOf(content), ReadyConditionType)
+	return ready != nil && ready.Status == metav1.ConditionTrue && ready.ObservedGeneration == obj.GetGeneration()
+}
+
+// conditionsOf returns status.conditions of the unstructured content of a resource
+func conditionsOf(content map[string]interface{}) []metav1.Condition {
+	rawConditions, _, _ := unstructured.NestedSlice(content, "status", "conditions")
+	var conditions []metav1.Condition
+	for _, rawCondition := range rawConditions {
+		var condition metav1.Condition
+		if conditionContent, ok := rawCondition.(map[string]interface{}); ok &&
+			runtime.DefaultUnstructuredConverter.FromUnstructured(conditionContent, &condition) == nil {
+			conditions = append(conditions, condition)
+		}
+	}
+	return conditions
+}
+
+// setConditionsOf writes the conditions to status.conditions of the unstructured content of a resource
+func setConditionsOf(content map[string]interface{}, conditions []metav1.Condition) error {
+	rawConditions := make([]interface{}, 0, len(conditions))
+	for i := range conditions {
+		rawCondition, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&conditions[i])
+		if err != nil {
+			return err
+		}
+		rawConditions = append(rawConditions, rawCondition)
+	}
+	return unstructured.SetNestedSlice(content, rawConditions, "status", "conditions")
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestGetSyncWave(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        int
+	}{
+		{name: "no annotation", want: 0},
+		{name: "positive", annotations: map[string]string{SyncWaveAnnotation: "2"}, want: 2},
+		{name: "negative", annotations: map[string]string{SyncWaveAnnotation: "-1"}, want: -1},
+		{name: "not an integer", annotations: map[string]string{SyncWaveAnnotation: "late"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got := GetSyncWave(index); got != tt.want {
+				t.Errorf("GetSyncWave() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncWave_Reconciler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = eseckv1alpha1.AddToScheme(scheme)
+
+	indexTemplate := &eseckv1alpha1.IndexTemplate{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "default", Generation: 1}}
+	index := &eseckv1alpha1.Index{ObjectMeta: metav1.ObjectMeta{Name: "logs-000001", Namespace: "default",
+		Annotations: map[string]string{SyncWaveAnnotation: "1"}}}
+	otherIndex := &eseckv1alpha1.Index{
+		ObjectMeta: metav1.ObjectMeta{Name: "audit", Namespace: "default", Annotations: map[string]string{SyncWaveAnnotation: "1"}},
+		Spec:       eseckv1alpha1.IndexSpec{TargetConfig: eseckv1alpha1.CommonElasticsearchConfig{ElasticsearchInstance: "other"}},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(indexTemplate, index, otherIndex).
+		WithStatusSubresource(indexTemplate).Build()
+
+	waves := &syncWaves{members: make(map[string]syncWaveMember), waiting: make(map[string]bool)}
+	recorder := record.NewFakeRecorder(10)
+	templateWave := &SyncWave{object: &eseckv1alpha1.IndexTemplate{}, gvk: eseckv1alpha1.GroupVersion.WithKind("IndexTemplate"), scheme: scheme, recorder: recorder, waves: waves}
+	indexWave := &SyncWave{object: &eseckv1alpha1.Index{}, gvk: eseckv1alpha1.GroupVersion.WithKind("Index"), scheme: scheme, recorder: recorder, waves: waves}
+	templateWave.Filter().Create(event.CreateEvent{Object: indexTemplate})
+	indexWave.Filter().Create(event.CreateEvent{Object: index})
+	indexWave.Filter().Create(event.CreateEvent{Object: otherIndex})
+	// Events of other kinds seen by the filter are ignored
+	indexWave.Filter().Create(event.CreateEvent{Object: indexTemplate})
+
+	reconciled := []string{}
+	reconciler := indexWave.Reconciler(fakeClient, reconcile.Func(func(_ context.Context, req ctrl.Request) (ctrl.Result, error) {
+		reconciled = append(reconciled, req.Name)
+		return ctrl.Result{}, nil
+	}))
+	ctx := context.Background()
+
+	res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(index)})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if res.RequeueAfter != syncWaveRecheckInterval || len(reconciled) != 0 {
+		t.Fatalf("Expected the index to be held back while the index template is not Ready, got %v and reconciled %v", res, reconciled)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Expected one WaitingForSyncWave event, got %d", len(recorder.Events))
+	}
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(index)}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Expected the event to be recorded once while waiting, got %d", len(recorder.Events))
+	}
+
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(otherIndex)}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if len(reconciled) != 1 || reconciled[0] != "audit" {
+		t.Errorf("Expected the index targeting another instance not to wait, got %v", reconciled)
+	}
+
+	SetReadyCondition(&indexTemplate.Status.Conditions, indexTemplate.Generation, nil)
+	if err := fakeClient.Status().Update(ctx, indexTemplate); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(index)}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if len(reconciled) != 2 || reconciled[1] != "logs-000001" {
+		t.Errorf("Expected the index to be reconciled once the index template is Ready, got %v", reconciled)
+	}
+}
@@ -0,0 +1,147 @@
+package template
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+)
+
+// DefaultRenderCacheSize is the number of rendered bodies kept by the package level render cache.
+const DefaultRenderCacheSize = 512
+
+// renderCache is a small, concurrency-safe LRU cache for rendered template bodies.
+// Entries are keyed by a hash of the template body, the resolved values, the builtins and the
+// resourceVersions of the ResourceTemplateData objects the values were taken from.
+type renderCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type renderCacheEntry struct {
+	key      string
+	rendered string
+}
+
+var defaultRenderCache = newRenderCache(DefaultRenderCacheSize)
+
+func newRenderCache(capacity int) *renderCache {
+	return &renderCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached rendered body for key and marks it as recently used.
+func (c *renderCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		return el.Value.(*renderCacheEntry).rendered, true
+	}
+	return "", false
+}
+
+// Add stores the rendered body for key, evicting the least recently used entry if the cache is full.
+func (c *renderCache) Add(key string, rendered string) {
+	if c.capacity <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*renderCacheEntry).rendered = rendered
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&renderCacheEntry{key: key, rendered: rendered})
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*renderCacheEntry).key)
+	}
+}
+
+// Len returns the number of cached entries.
+func (c *renderCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// Purge removes all cached entries.
+func (c *renderCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+// PurgeRenderCache drops all rendered bodies from the package level render cache.
+func PurgeRenderCache() {
+	defaultRenderCache.Purge()
+}
+
+// uncacheableFunctions are the template functions whose output does not only depend on their arguments: the Helm
+// "lookup" function reads live cluster state, the others depend on the current time, randomness or DNS.
+var uncacheableFunctions = []string{
+	"lookup", "now", "ago", "getHostByName",
+	"randAlphaNum", "randAlpha", "randAscii", "randNumeric", "randBytes", "randInt", "uuidv4", "shuffle",
+	"genPrivateKey", "genCA", "genCAWithKey", "genSelfSignedCert", "genSelfSignedCertWithKey", "genSignedCert",
+	"genSignedCertWithKey", "bcrypt", "htpasswd", "encryptAES",
+}
+
+var (
+	templateActionRegex      = regexp.MustCompile(`(?s){{.*?}}`)
+	uncacheableFunctionRegex = regexp.MustCompile(`(?:^|[^.\w$])(?:` + strings.Join(uncacheableFunctions, "|") + `)\b`)
+)
+
+// isCacheable reports whether the rendered output of body only depends on its values. Bodies calling one of the
+// uncacheableFunctions in a template action are never cached, e.g. "now-1d" outside of an action is date math of
+// Elasticsearch and does not prevent caching.
+func isCacheable(body string) bool {
+	for _, action := range templateActionRegex.FindAllString(body, -1) {
+		if uncacheableFunctionRegex.MatchString(action[2:]) {
+			return false
+		}
+	}
+	return true
+}
+
+// renderCacheKey builds the cache key from the body, the resolved values, the builtins and the
+// resourceVersions of the ResourceTemplateData objects the values were taken from.
+func renderCacheKey(body string, values map[string]interface{}, builtins Builtins, resourceTemplateDataList []eseckv1alpha1.ResourceTemplateData) (string, error) {
+	bodyHash := sha256.Sum256([]byte(body))
+
+	// encoding/json sorts map keys, so the marshalled values are stable
+	marshalledValues, err := json.Marshal(map[string]interface{}{"Values": values, "Builtins": builtins})
+	if err != nil {
+		return "", err
+	}
+	valuesHash := sha256.Sum256(marshalledValues)
+
+	versions := make([]string, 0, len(resourceTemplateDataList))
+	for _, rtd := range resourceTemplateDataList {
+		versions = append(versions, rtd.Namespace+"/"+rtd.Name+"@"+rtd.ResourceVersion)
+	}
+	sort.Strings(versions)
+	versionsHash := sha256.Sum256([]byte(strings.Join(versions, ",")))
+
+	return hex.EncodeToString(bodyHash[:]) + ":" +
+		hex.EncodeToString(valuesHash[:]) + ":" +
+		hex.EncodeToString(versionsHash[:]), nil
+}
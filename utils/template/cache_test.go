@@ -0,0 +1,207 @@
+package template
+
+import (
+	"testing"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRenderCache_GetAdd(t *testing.T) {
+	cache := newRenderCache(2)
+
+	if _, ok := cache.Get("missing"); ok {
+		t.Error("Expected cache miss for unknown key")
+	}
+
+	cache.Add("a", "rendered-a")
+	cache.Add("b", "rendered-b")
+
+	if got, ok := cache.Get("a"); !ok || got != "rendered-a" {
+		t.Errorf("Get(a) = %q, %v; want %q, true", got, ok, "rendered-a")
+	}
+
+	// "b" is now least recently used and must be evicted
+	cache.Add("c", "rendered-c")
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Expected least recently used entry b to be evicted")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", cache.Len())
+	}
+
+	cache.Add("a", "rendered-a2")
+	if got, _ := cache.Get("a"); got != "rendered-a2" {
+		t.Errorf("Get(a) after overwrite = %q, want %q", got, "rendered-a2")
+	}
+
+	cache.Purge()
+	if cache.Len() != 0 {
+		t.Errorf("Len() after Purge = %d, want 0", cache.Len())
+	}
+}
+
+func TestRenderCache_ZeroCapacity(t *testing.T) {
+	cache := newRenderCache(0)
+	cache.Add("a", "rendered-a")
+
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Expected zero capacity cache to never store entries")
+	}
+}
+
+func TestRenderCacheKey(t *testing.T) {
+	rtd := func(resourceVersion string) []eseckv1alpha1.ResourceTemplateData {
+		return []eseckv1alpha1.ResourceTemplateData{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default", ResourceVersion: resourceVersion},
+			},
+		}
+	}
+	values := map[string]interface{}{"default": map[string]interface{}{"data": map[string]interface{}{"key": "value"}}}
+
+	base, err := renderCacheKey("body", values, Builtins{}, rtd("1"))
+	if err != nil {
+		t.Fatalf("renderCacheKey() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		values   map[string]interface{}
+		builtins Builtins
+		rtdList  []eseckv1alpha1.ResourceTemplateData
+		wantSame bool
+	}{
+		{
+			name:     "identical inputs",
+			body:     "body",
+			values:   values,
+			rtdList:  rtd("1"),
+			wantSame: true,
+		},
+		{
+			name:     "different body",
+			body:     "other-body",
+			values:   values,
+			rtdList:  rtd("1"),
+			wantSame: false,
+		},
+		{
+			name:     "different values",
+			body:     "body",
+			values:   map[string]interface{}{"other": "value"},
+			rtdList:  rtd("1"),
+			wantSame: false,
+		},
+		{
+			name:     "different builtins",
+			body:     "body",
+			values:   values,
+			builtins: Builtins{Namespace: "other"},
+			rtdList:  rtd("1"),
+			wantSame: false,
+		},
+		{
+			name:     "different target version",
+			body:     "body",
+			values:   values,
+			builtins: Builtins{Target: Target{Version: "7.17.0"}},
+			rtdList:  rtd("1"),
+			wantSame: false,
+		},
+		{
+			name:     "different resourceVersion",
+			body:     "body",
+			values:   values,
+			rtdList:  rtd("2"),
+			wantSame: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := renderCacheKey(tt.body, tt.values, tt.builtins, tt.rtdList)
+			if err != nil {
+				t.Fatalf("renderCacheKey() error = %v", err)
+			}
+			if (key == base) != tt.wantSame {
+				t.Errorf("renderCacheKey() same = %v, Builtins{}, want %v", key == base, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestRenderBody_UsesCache(t *testing.T) {
+	PurgeRenderCache()
+	defer PurgeRenderCache()
+
+	rtdList := []eseckv1alpha1.ResourceTemplateData{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default", ResourceVersion: "7"},
+			Spec: eseckv1alpha1.ResourceTemplateDataSpec{
+				Values: map[string]apiextensionsv1.JSON{"shards": jsonValue(3)},
+			},
+		},
+	}
+	body := `{"number_of_shards": {{ .Values.default.config.shards }}}`
+
+	first, err := RenderBody(body, rtdList, Builtins{}, nil)
+	if err != nil {
+		t.Fatalf("RenderBody() error = %v", err)
+	}
+	if defaultRenderCache.Len() != 1 {
+		t.Fatalf("Expected one cached entry, got %d", defaultRenderCache.Len())
+	}
+
+	second, err := RenderBody(body, rtdList, Builtins{}, nil)
+	if err != nil {
+		t.Fatalf("RenderBody() error = %v", err)
+	}
+	if first != second {
+		t.Errorf("Cached render = %q, want %q", second, first)
+	}
+	if defaultRenderCache.Len() != 1 {
+		t.Errorf("Expected cache hit to not add entries, got %d", defaultRenderCache.Len())
+	}
+}
+
+func TestRenderBody_LookupNotCached(t *testing.T) {
+	PurgeRenderCache()
+	defer PurgeRenderCache()
+
+	if isCacheable(`{{ lookup "v1" "Secret" "default" "x" }}`) {
+		t.Error("Expected bodies using lookup to not be cacheable")
+	}
+	if !isCacheable(`{"a": "{{ .Values.a }}"}`) {
+		t.Error("Expected plain bodies to be cacheable")
+	}
+}
+
+func TestIsCacheable(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{name: "values", body: `{"a": "{{ .Values.a }}"}`, want: true},
+		{name: "now", body: `{"created": "{{ now | date "2006-01-02" }}"}`, want: false},
+		{name: "random string", body: `{"password": "{{ randAlphaNum 16 }}"}`, want: false},
+		{name: "uuid", body: `{"id": "{{- uuidv4 -}}"}`, want: false},
+		{name: "private key", body: `{"key": {{ genPrivateKey "rsa" | quote }}}`, want: false},
+		{name: "piped", body: `{"a": "{{ .Values.a | default (randAlpha 8) }}"}`, want: false},
+		{name: "date math outside of actions", body: `{"range": {"@timestamp": {"gte": "now-1d"}}, "a": "{{ .Values.a }}"}`, want: true},
+		{name: "value named like a function", body: `{"a": "{{ .Values.now }}"}`, want: true},
+		{name: "function name as prefix", body: `{"a": "{{ .Values.a | nowhere }}"}`, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCacheable(tt.body); got != tt.want {
+				t.Errorf("isCacheable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
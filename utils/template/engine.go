@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 
 	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
 
@@ -16,7 +18,43 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const templateName = "body.tpl"
+const (
+	templateName = "body.tpl"
+	// bodyTemplateName is the partial a body using the builtins is stored as, so it is parsed as a template of its
+	// own and its defines stay at the top level
+	bodyTemplateName = "_" + templateName
+)
+
+// Builtins are the variables available in every templated body next to .Values, as .Namespace, .Name,
+// .TargetInstance and .Target. They are also available as .Release.Namespace, .Release.Name, .Release.TargetInstance
+// and .Release.Target.
+type Builtins struct {
+	// Namespace of the templated resource
+	Namespace string
+	// Name of the templated resource
+	Name string
+	// TargetInstance is the name of the instance the resource is deployed to, empty for the default instance
+	TargetInstance string
+	// Target describes the instance the body is applied to, as resolved for this reconcile
+	Target Target
+}
+
+// Target is the resolved instance a templated body is applied to, available as .Target.Name, .Target.Namespace,
+// .Target.URL and .Target.Version, so bodies can adapt to the cluster they are applied to
+type Target struct {
+	// Name of the instance, empty for the default instance of the operator
+	Name string
+	// Namespace of the instance, empty for the default instance and cluster scoped instances
+	Namespace string
+	// URL the instance is reached at, the selected one if the target fails over
+	URL string
+	// Version of the instance, e.g. 8.15.0, empty if it could not be determined
+	Version string
+}
+
+// builtinsEntry renders the body partial with the builtins added to the top level objects of Helm. Helm builds the
+// top level objects itself and drops any other key of the values passed to the engine.
+const builtinsEntry = `{{ template "body-template/templates/` + bodyTemplateName + `" (merge (dict "Namespace" .Release.Namespace "Name" .Release.Name "TargetInstance" .Release.TargetInstance "Target" .Release.Target) .) }}`
 
 // FetchResourceTemplateData fetches all ResourceTemplateData objects referenced in the template spec.
 // It handles both direct name references and label selector references.
@@ -87,24 +125,53 @@ func FetchResourceTemplateData(
 	return result, nil
 }
 
-// IsTemplate checks if the template spec is enabled and has any references defined.
-// Returns false if Enabled is false. Returns true if Enabled is true (or not set) and has references.
+// IsTemplate checks if the template spec is enabled and has any references defined or builtins requested.
+// Returns false if Enabled is false. Returns true if Enabled is true (or not set) and has references or Builtins set.
 func IsTemplate(templateSpec eseckv1alpha1.CommonTemplatingSpec) bool {
 	// Check if templating is enabled first
 	if !templateSpec.IsEnabled() {
 		return false
 	}
-	// Then check if there are any references
-	return len(templateSpec.References) > 0
+	// Then check if there are any references or the body only uses the builtins
+	return len(templateSpec.References) > 0 || templateSpec.Builtins
 }
 
 // RenderBody renders the given body template using data from ResourceTemplateData objects.
 // It uses the Helm template engine for rendering.
 // The data from all ResourceTemplateData objects is merged into a single map,
 // where each ResourceTemplateData's data is accessible via .Values.<namespace>.<name>.<key>
-func RenderBody(body string, resourceTemplateDataList []eseckv1alpha1.ResourceTemplateData, config *rest.Config) (string, error) {
-	// Build the template data map
-	// Structure: { "namespace": { "resourceName": { "key1": value1, "key2": value2 }, ... }, ... }
+// The builtins are accessible via .Namespace, .Name, .TargetInstance and .Target.
+// Rendered bodies are cached by body, values and ResourceTemplateData resourceVersions,
+// so periodic resyncs of unchanged resources skip the Helm rendering.
+func RenderBody(body string, resourceTemplateDataList []eseckv1alpha1.ResourceTemplateData, builtins Builtins, config *rest.Config) (string, error) {
+	data, err := buildValues(resourceTemplateDataList)
+	if err != nil {
+		return "", err
+	}
+
+	if !isCacheable(body) {
+		return renderBodyWithBuiltins(body, data, builtins, config)
+	}
+
+	cacheKey, err := renderCacheKey(body, data, builtins, resourceTemplateDataList)
+	if err != nil {
+		return renderBodyWithBuiltins(body, data, builtins, config)
+	}
+	if rendered, ok := defaultRenderCache.Get(cacheKey); ok {
+		return rendered, nil
+	}
+
+	rendered, err := renderBodyWithBuiltins(body, data, builtins, config)
+	if err != nil {
+		return "", err
+	}
+	defaultRenderCache.Add(cacheKey, rendered)
+	return rendered, nil
+}
+
+// buildValues merges the data of all ResourceTemplateData objects into a single values map.
+// Structure: { "namespace": { "resourceName": { "key1": value1, "key2": value2 }, ... }, ... }
+func buildValues(resourceTemplateDataList []eseckv1alpha1.ResourceTemplateData) (map[string]interface{}, error) {
 	data := make(map[string]interface{})
 
 	for _, rtd := range resourceTemplateDataList {
@@ -114,7 +181,7 @@ func RenderBody(body string, resourceTemplateDataList []eseckv1alpha1.ResourceTe
 			// Unmarshal the JSON value to interface{}
 			var value interface{}
 			if err := json.Unmarshal(v.Raw, &value); err != nil {
-				return "", fmt.Errorf("failed to unmarshal value %q from ResourceTemplateData %s/%s: %w", k, rtd.Namespace, rtd.Name, err)
+				return nil, fmt.Errorf("failed to unmarshal value %q from ResourceTemplateData %s/%s: %w", k, rtd.Namespace, rtd.Name, err)
 			}
 			rtdData[k] = value
 		}
@@ -131,35 +198,58 @@ func RenderBody(body string, resourceTemplateDataList []eseckv1alpha1.ResourceTe
 		nsMap[rtd.Name] = rtdData
 	}
 
-	return RenderBodyWithValues(body, data, config)
+	return data, nil
 }
 
 // RenderBodyWithValues renders the given body template using a pre-built values map.
 // This is useful when you want more control over the template data structure.
 // Values are accessible in templates via .Values.key syntax (Helm convention).
 func RenderBodyWithValues(body string, values map[string]interface{}, config *rest.Config) (string, error) {
-	// Create a minimal chart with just our template
+	return renderChart(map[string]string{templateName: body}, map[string]interface{}{
+		"Values": values,
+	}, config)
+}
+
+// renderBodyWithBuiltins renders the body with the builtins in scope next to .Values and the other Helm objects.
+// Errors name the line and column of the body, show an excerpt of it and list the available values.
+func renderBodyWithBuiltins(body string, values map[string]interface{}, builtins Builtins, config *rest.Config) (string, error) {
+	rendered, err := renderChart(map[string]string{templateName: builtinsEntry, bodyTemplateName: body}, map[string]interface{}{
+		"Values": values,
+		"Release": map[string]interface{}{
+			"Namespace":      builtins.Namespace,
+			"Name":           builtins.Name,
+			"TargetInstance": builtins.TargetInstance,
+			"Target": map[string]interface{}{
+				"Name":      builtins.Target.Name,
+				"Namespace": builtins.Target.Namespace,
+				"URL":       builtins.Target.URL,
+				"Version":   builtins.Target.Version,
+			},
+		},
+	}, config)
+	if err != nil {
+		return "", describeRenderError(err, body, values)
+	}
+	return rendered, nil
+}
+
+// renderChart renders the templates, keyed by their file name, as the templates of a chart and returns the output of
+// templateName. The top level values contain "Values" and optionally "Release".
+func renderChart(templates map[string]string, topLevelValues map[string]interface{}, config *rest.Config) (string, error) {
+	// Create a minimal chart with just our templates
 	chrt := &v2.Chart{
 		Metadata: &v2.Metadata{
 			Name:       "body-template",
 			Version:    "0.0.0",
 			APIVersion: v2.APIVersionV2,
 		},
-		Templates: []*common.File{
-			{
-				Name: "templates/" + templateName,
-				Data: []byte(body),
-			},
-		},
 	}
-
-	// Wrap values under "Values" key as Helm expects
-	wrappedValues := map[string]interface{}{
-		"Values": values,
+	for _, name := range slices.Sorted(maps.Keys(templates)) {
+		chrt.Templates = append(chrt.Templates, &common.File{Name: "templates/" + name, Data: []byte(templates[name])})
 	}
 
 	// Render the chart using RenderWithClient to enable client-aware template functions (e.g., lookup)
-	rendered, err := engine.RenderWithClient(chrt, wrappedValues, config)
+	rendered, err := engine.RenderWithClient(chrt, topLevelValues, config)
 	if err != nil {
 		return "", fmt.Errorf("failed to render template: %w", err)
 	}
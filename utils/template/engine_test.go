@@ -112,6 +112,21 @@ func TestIsTemplate(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "builtins without references",
+			templateSpec: eseckv1alpha1.CommonTemplatingSpec{
+				Builtins: true,
+			},
+			want: true,
+		},
+		{
+			name: "builtins with enabled explicitly false",
+			templateSpec: eseckv1alpha1.CommonTemplatingSpec{
+				Enabled:  boolPtr(false),
+				Builtins: true,
+			},
+			want: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -365,7 +380,7 @@ func TestRenderBody(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Pass nil for rest.Config since we're not using lookup functions in these tests
-			got, err := RenderBody(tt.body, tt.resourceTemplateDataList, nil)
+			got, err := RenderBody(tt.body, tt.resourceTemplateDataList, Builtins{}, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("RenderBody() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -377,6 +392,113 @@ func TestRenderBody(t *testing.T) {
 	}
 }
 
+func TestRenderBody_Builtins(t *testing.T) {
+	PurgeRenderCache()
+	defer PurgeRenderCache()
+
+	builtins := Builtins{
+		Namespace:      "team-a",
+		Name:           "team-a-logs",
+		TargetInstance: "elasticsearch-quickstart",
+		Target: Target{
+			Name:      "elasticsearch-quickstart",
+			Namespace: "elastic",
+			URL:       "https://quickstart-es-http.elastic:9200",
+			Version:   "8.15.0",
+		},
+	}
+	rtdList := []eseckv1alpha1.ResourceTemplateData{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "team-a"},
+			Spec: eseckv1alpha1.ResourceTemplateDataSpec{
+				Values: map[string]apiextensionsv1.JSON{"privilege": jsonValue("read")},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		rtdList  []eseckv1alpha1.ResourceTemplateData
+		builtins Builtins
+		want     string
+	}{
+		{
+			name:     "builtins without values",
+			body:     `{"indices": [{"names": ["logs-{{ .Namespace }}-*"]}], "metadata": {"role": "{{ .Name }}", "cluster": "{{ .TargetInstance }}"}}`,
+			builtins: builtins,
+			want:     `{"indices": [{"names": ["logs-team-a-*"]}], "metadata": {"role": "team-a-logs", "cluster": "elasticsearch-quickstart"}}`,
+		},
+		{
+			name:     "builtins as release",
+			body:     `{{ .Release.Namespace }}/{{ .Release.Name }}`,
+			builtins: builtins,
+			want:     `team-a/team-a-logs`,
+		},
+		{
+			name:     "builtins next to values",
+			body:     `{"privileges": ["{{ index .Values .Namespace "config" "privilege" }}"]}`,
+			rtdList:  rtdList,
+			builtins: builtins,
+			want:     `{"privileges": ["read"]}`,
+		},
+		{
+			name:     "root scope keeps values",
+			body:     `{{ index $.Values "team-a" "config" "privilege" }}`,
+			rtdList:  rtdList,
+			builtins: builtins,
+			want:     `read`,
+		},
+		{
+			name:     "target",
+			body:     `{{ .Target.Namespace }}/{{ .Target.Name }} {{ .Target.URL }} {{ .Release.Target.Version }}`,
+			builtins: builtins,
+			want:     `elastic/elasticsearch-quickstart https://quickstart-es-http.elastic:9200 8.15.0`,
+		},
+		{
+			name:     "target version comparison",
+			body:     `{"lazy": {{ semverCompare ">=8.10.0" .Target.Version }}}`,
+			builtins: builtins,
+			want:     `{"lazy": true}`,
+		},
+		{
+			name: "default instance",
+			body: `"{{ .TargetInstance }}"`,
+			want: `""`,
+		},
+		{
+			name:     "define",
+			body:     `{{ define "names" }}["logs-{{ .Namespace }}-*"]{{ end }}{"names": {{ template "names" . }}}`,
+			builtins: builtins,
+			want:     `{"names": ["logs-team-a-*"]}`,
+		},
+		{
+			name:     "block",
+			body:     `{"names": {{ block "names" . }}["{{ .Name }}"]{{ end }}}`,
+			builtins: builtins,
+			want:     `{"names": ["team-a-logs"]}`,
+		},
+		{
+			name:     "helm objects",
+			body:     `{{ .Chart.Name }} {{ .Template.BasePath }} {{ .Files | len }}`,
+			builtins: builtins,
+			want:     `body-template body-template/templates 0`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderBody(tt.body, tt.rtdList, tt.builtins, nil)
+			if err != nil {
+				t.Fatalf("RenderBody() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RenderBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRenderBodyWithValues(t *testing.T) {
 	tests := []struct {
 		name    string
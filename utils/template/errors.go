@@ -0,0 +1,86 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// errorPosition matches the position of a template error in the body, e.g. _body.tpl:2:17 or _body.tpl:2
+var errorPosition = regexp.MustCompile(regexp.QuoteMeta(bodyTemplateName) + `:(\d+)(?::(\d+))?`)
+
+const (
+	// excerptContext is the number of lines shown before and after the line of a template error
+	excerptContext = 1
+	// excerptLineLength is the length lines of the excerpt are cut to
+	excerptLineLength = 120
+)
+
+// describeRenderError adds the line and column of the error, an excerpt of the body around it and the available value
+// keys to an error of rendering body
+func describeRenderError(err error, body string, values map[string]interface{}) error {
+	var details strings.Builder
+	if line, column, ok := renderErrorPosition(err); ok {
+		if column > 0 {
+			fmt.Fprintf(&details, "\nat line %d, column %d:", line, column)
+		} else {
+			fmt.Fprintf(&details, "\nat line %d:", line)
+		}
+		details.WriteString(bodyExcerpt(body, line))
+	}
+	if keys := valueKeys(values); len(keys) > 0 {
+		fmt.Fprintf(&details, "\navailable values: %s", strings.Join(keys, ", "))
+	} else {
+		details.WriteString("\navailable values: none, no ResourceTemplateData is referenced")
+	}
+	return fmt.Errorf("%w%s", err, details.String())
+}
+
+// renderErrorPosition returns the line and column of the body reported by the template error, column is 0 if only
+// the line is reported
+func renderErrorPosition(err error) (int, int, bool) {
+	match := errorPosition.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0, 0, false
+	}
+	line, _ := strconv.Atoi(match[1])
+	column, _ := strconv.Atoi(match[2])
+	return line, column, true
+}
+
+// bodyExcerpt returns the lines of body around line, numbered and the line itself marked
+func bodyExcerpt(body string, line int) string {
+	lines := strings.Split(body, "\n")
+	var excerpt strings.Builder
+	for i := max(line-excerptContext, 1); i <= min(line+excerptContext, len(lines)); i++ {
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+		text := lines[i-1]
+		if len(text) > excerptLineLength {
+			text = text[:excerptLineLength] + "..."
+		}
+		fmt.Fprintf(&excerpt, "\n%s %4d | %s", marker, i, text)
+	}
+	return excerpt.String()
+}
+
+// valueKeys returns the sorted .Values.<namespace>.<name> keys of the values built from ResourceTemplateData objects
+func valueKeys(values map[string]interface{}) []string {
+	var keys []string
+	for namespace, names := range values {
+		nsMap, ok := names.(map[string]interface{})
+		if !ok {
+			keys = append(keys, ".Values."+namespace)
+			continue
+		}
+		for name := range nsMap {
+			keys = append(keys, fmt.Sprintf(".Values.%s.%s", namespace, name))
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,91 @@
+package template
+
+import (
+	"strings"
+	"testing"
+
+	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRenderBody_ErrorContext(t *testing.T) {
+	resourceTemplateDataList := []eseckv1alpha1.ResourceTemplateData{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "limits", Namespace: "prod"},
+			Spec: eseckv1alpha1.ResourceTemplateDataSpec{
+				Values: map[string]apiextensionsv1.JSON{"replicas": jsonValue(2)},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"},
+			Spec: eseckv1alpha1.ResourceTemplateDataSpec{
+				Values: map[string]apiextensionsv1.JSON{"name": jsonValue("logs")},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		body         string
+		templateData []eseckv1alpha1.ResourceTemplateData
+		wantContains []string
+	}{
+		{
+			name:         "execution error",
+			body:         "{\n  \"replicas\": {{ .Values.prod.missing.replicas }}\n}",
+			templateData: resourceTemplateDataList,
+			wantContains: []string{
+				"at line 2, column 24:",
+				"     1 | {",
+				">    2 |   \"replicas\": {{ .Values.prod.missing.replicas }}",
+				"     3 | }",
+				"available values: .Values.default.settings, .Values.prod.limits",
+			},
+		},
+		{
+			name:         "execution error on the first line",
+			body:         `{"replicas": {{ .Values.prod.missing.replicas }}}`,
+			templateData: resourceTemplateDataList,
+			wantContains: []string{"at line 1, column 23:", `>    1 | {"replicas"`},
+		},
+		{
+			name: "parse error",
+			body: "{\n  \"name\": {{ .Values.default.settings.name | nosuchfunc }}\n}",
+			wantContains: []string{
+				`function "nosuchfunc" not defined`,
+				"at line 2:",
+				"available values: none",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RenderBody(tt.body, tt.templateData, Builtins{}, nil)
+			if err == nil {
+				t.Fatal("RenderBody() expected an error")
+			}
+			for _, want := range tt.wantContains {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("RenderBody() error = %q, want it to contain %q", err.Error(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestBodyExcerpt(t *testing.T) {
+	body := "line 1\n" + strings.Repeat("x", excerptLineLength+10) + "\nline 3\nline 4"
+
+	got := bodyExcerpt(body, 2)
+	want := "\n     1 | line 1\n>    2 | " + strings.Repeat("x", excerptLineLength) + "...\n     3 | line 3"
+	if got != want {
+		t.Errorf("bodyExcerpt() = %q, want %q", got, want)
+	}
+
+	if got := bodyExcerpt(body, 4); !strings.HasSuffix(got, ">    4 | line 4") || strings.Contains(got, "line 1") {
+		t.Errorf("bodyExcerpt() of the last line = %q", got)
+	}
+}
@@ -4,38 +4,47 @@ import (
 	"context"
 
 	eseckv1alpha1 "eck-custom-resources/api/es.eck/v1alpha1"
+	"eck-custom-resources/utils"
 
+	"go.opentelemetry.io/otel/attribute"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // FetchAndRenderTemplate fetches all referenced ResourceTemplateData objects and renders the body template.
-// If the template spec has no references, it returns the original body unchanged.
+// If the template spec has no references and does not request the builtins, it returns the original body unchanged.
+// The builtins.Namespace is used as the default namespace of the references.
 // This function combines FetchResourceTemplateData and RenderBody for convenience.
 func FetchAndRenderTemplate(
 	cli client.Client,
 	ctx context.Context,
 	templateSpec eseckv1alpha1.CommonTemplatingSpec,
 	body string,
-	defaultNamespace string,
+	builtins Builtins,
 	restConfig *rest.Config,
 ) (string, error) {
-	// If templating is not enabled or no references, return the original body
+	// If templating is not enabled or there is nothing to render, return the original body
 	if !IsTemplate(templateSpec) {
 		return body, nil
 	}
 
 	// Fetch all referenced ResourceTemplateData objects
+	fetchCtx, span := utils.StartSpan(ctx, "FetchResourceTemplateData")
 	resourceTemplateDataList, err := FetchResourceTemplateData(
 		cli,
-		ctx,
+		fetchCtx,
 		templateSpec,
-		defaultNamespace,
+		builtins.Namespace,
 	)
+	span.SetAttributes(attribute.Int("eck.template.references", len(resourceTemplateDataList)))
+	utils.EndSpan(span, err)
 	if err != nil {
 		return "", err
 	}
 
 	// Render the body template with the fetched data
-	return RenderBody(body, resourceTemplateDataList, restConfig)
+	_, span = utils.StartSpan(ctx, "RenderBody")
+	rendered, err := RenderBody(body, resourceTemplateDataList, builtins, restConfig)
+	utils.EndSpan(span, err)
+	return rendered, err
 }
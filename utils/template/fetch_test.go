@@ -257,7 +257,7 @@ func TestFetchAndRenderTemplate(t *testing.T) {
 				context.Background(),
 				tt.templateSpec,
 				tt.body,
-				tt.defaultNamespace,
+				Builtins{Namespace: tt.defaultNamespace},
 				nil, // rest.Config can be nil for basic templates
 			)
 
@@ -332,7 +332,7 @@ func TestFetchAndRenderTemplate_Integration(t *testing.T) {
 		context.Background(),
 		templateSpec,
 		body,
-		"default",
+		Builtins{Namespace: "default"},
 		nil,
 	)
 
@@ -419,7 +419,7 @@ func TestFetchAndRenderTemplate_IngestPipelineScenario(t *testing.T) {
 		context.Background(),
 		templateSpec,
 		body,
-		"default",
+		Builtins{Namespace: "default"},
 		nil,
 	)
 
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingServiceName is the service.name of the spans of the operator, unless OTEL_SERVICE_NAME overrides it
+const TracingServiceName = "eck-custom-resources"
+
+const tracerName = "eck-custom-resources"
+
+// TracingEnabled reports whether the standard OTEL environment variables ask for traces to be exported: an OTLP
+// endpoint is set or OTEL_TRACES_EXPORTER is otlp, and neither OTEL_SDK_DISABLED nor OTEL_TRACES_EXPORTER=none turn
+// the export off
+func TracingEnabled() bool {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+	switch strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")) {
+	case "none":
+		return false
+	case "otlp":
+		return true
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// SetupTracing exports the spans of reconciles and of the requests to Elasticsearch and Kibana with OTLP over gRPC
+// if TracingEnabled. The exporter, sampler and resource are configured by the standard OTEL environment variables,
+// e.g. OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_TRACES_SAMPLER and OTEL_RESOURCE_ATTRIBUTES. The returned function flushes
+// the pending spans, it has to be called before the operator exits.
+func SetupTracing(ctx context.Context) (func(context.Context) error, error) {
+	if !TracingEnabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := otlptracegrpc.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", TracingServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// StartSpan starts a span of the operator as child of the span in ctx. Without SetupTracing it does not record
+// anything.
+func StartSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// EndSpan ends the span, marking it as failed if err is set
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TracingTransport records a span for each request sent through next. Requests sent without a span in their
+// context, like most requests of the Elasticsearch and Kibana clients, become children of the span in ctx, which is
+// the reconcile the client was created for.
+func TracingTransport(ctx context.Context, next http.RoundTripper) http.RoundTripper {
+	return &tracingTransport{
+		parent: trace.SpanFromContext(ctx),
+		next: otelhttp.NewTransport(next, otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + r.URL.Path
+		})),
+	}
+}
+
+type tracingTransport struct {
+	// parent is the span itself, not just its context, otelhttp takes the tracer of the span it finds in the request
+	parent trace.Span
+	next   http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.parent.SpanContext().IsValid() && !trace.SpanContextFromContext(req.Context()).IsValid() {
+		req = req.WithContext(trace.ContextWithSpan(req.Context(), t.parent))
+	}
+	return t.next.RoundTrip(req)
+}